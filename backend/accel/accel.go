@@ -0,0 +1,176 @@
+// Package accel defines the devices accelerating the provers, and a registry of their
+// providers.
+//
+// The GPU provers (groth16 on BN254 and BLS12-377) only reach the device through the Device
+// interface, so that a provider for another runtime (ROCm/HIP, Metal, sppark...) plugs in
+// without changes to the provers. The icicle CUDA provider is in the icicle sub-package, built
+// with the icicle build tag, and the CPU emulation, always built, in the cpu sub-package. The
+// remote sub-package ships the kernels to the devices of another machine.
+//
+// A Device works on the scalar field and the groups of one curve. The device memory is
+// addressed by opaque pointers, and the host buffers use the layout of gnark-crypto: the
+// scalars are fr.Element in Montgomery form, the points curve.G1Affine and curve.G2Affine, and
+// the results of the multi-scalar multiplications curve.G1Jac and curve.G2Jac.
+package accel
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// DefaultBucketFactor is the bucket factor of the multi-scalar multiplications if the
+// MsmConfig doesn't set one.
+const DefaultBucketFactor = 10
+
+// ErrUnsupported is returned by the devices for the configurations their provider doesn't
+// support.
+var ErrUnsupported = errors.New("unsupported by the device")
+
+// ErrDevice is wrapped by the errors of the device itself, such as the failures of its runtime
+// calls and kernels, as opposed to the errors of the callers.
+var ErrDevice = errors.New("device error")
+
+// PointRepresentation is the representation of the points on the device.
+type PointRepresentation uint8
+
+const (
+	// Affine points, the smallest, which all the providers support.
+	Affine PointRepresentation = iota
+
+	// Projective points, in the Jacobian coordinates of curve.G1Jac and curve.G2Jac: half as
+	// large again as the affine points, but the devices add them without conversion.
+	Projective
+)
+
+// String implements fmt.Stringer.
+func (r PointRepresentation) String() string {
+	switch r {
+	case Affine:
+		return "affine"
+	case Projective:
+		return "projective"
+	default:
+		return "unknown"
+	}
+}
+
+// PointsConfig is the layout of a vector of points on the device. The zero value, affine points
+// without the points at infinity, is supported by all the providers.
+type PointsConfig struct {
+	Representation PointRepresentation
+
+	// WithInfinity keeps the points at infinity in the vector, so that the scalars needn't be
+	// filtered on the host, at the cost of the memory of the points. Otherwise (the NoInfinity
+	// layout), the points at infinity are removed by the caller.
+	WithInfinity bool
+}
+
+// MsmConfig configures a multi-scalar multiplication on the device.
+type MsmConfig struct {
+	// BucketFactor trades the memory of the bucket method for its speed; 0 selects
+	// DefaultBucketFactor. Providers without this parameter ignore it.
+	BucketFactor int
+
+	// Points is the layout of the points, as copied by PointsG1ToDevice or PointsG2ToDevice.
+	Points PointsConfig
+
+	// NbTasks is the number of CPU tasks of the multi-scalar multiplication, or 0 for the
+	// default of the provider. Only the providers computing it on the CPU support it: the
+	// others return ErrUnsupported if it isn't 0, whatever the number of scalars.
+	NbTasks int
+}
+
+// Device computes the kernels of a prover on an accelerator, for one curve. Its methods may be
+// called concurrently.
+type Device interface {
+	// Name returns the name of the provider and of the device, for the logs.
+	Name() string
+
+	// Curve returns the curve of the scalars and points.
+	Curve() ecc.ID
+
+	// Malloc allocates size bytes of device memory, and Free releases them.
+	Malloc(size int) (unsafe.Pointer, error)
+	Free(p unsafe.Pointer) error
+
+	// CopyToDevice and CopyToHost copy size bytes between the host and the device.
+	CopyToDevice(dst, src unsafe.Pointer, size int) error
+	CopyToHost(dst, src unsafe.Pointer, size int) error
+
+	// FromMontgomery converts the n scalars copied from the host out of the Montgomery form,
+	// as the other kernels expect.
+	FromMontgomery(scalars unsafe.Pointer, n int) error
+
+	// ReverseScalars permutes the n scalars in bit-reversed order.
+	ReverseScalars(scalars unsafe.Pointer, n int) error
+
+	// VecMul sets a to the element-wise product a·b, and VecSub to the difference a-b, of
+	// vectors of n scalars.
+	VecMul(a, b unsafe.Pointer, n int) error
+	VecSub(a, b unsafe.Pointer, n int) error
+
+	// Twiddles returns the (inverse) twiddle factors of the domain of size n, a power of 2.
+	Twiddles(n int, inverse bool) (unsafe.Pointer, error)
+
+	// Ntt sets out to the evaluations, in natural order, of the polynomial in of n
+	// coefficients on the domain, or on its coset if cosetPowers (the powers of the coset
+	// generator) isn't nil.
+	Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error
+
+	// Intt returns the n coefficients of the polynomial with the evaluations in on the domain,
+	// or on its coset if cosetPowers (the inverse powers of the coset generator) isn't nil,
+	// in a new device buffer. It may permute in.
+	Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error)
+
+	// PointsG1ToDevice and PointsG2ToDevice convert the n affine points at the host address
+	// to the layout of the device, and copy them to a new device buffer. The points may only
+	// be at infinity if cfg.WithInfinity is set. They return ErrUnsupported if the provider
+	// doesn't support the layout.
+	PointsG1ToDevice(points unsafe.Pointer, n int, cfg PointsConfig) (unsafe.Pointer, error)
+	PointsG2ToDevice(points unsafe.Pointer, n int, cfg PointsConfig) (unsafe.Pointer, error)
+
+	// Msm sets the host Jacobian point res to the multi-scalar multiplication of the n
+	// device points and scalars, in G1, and MsmG2 in G2.
+	Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg MsmConfig) error
+	MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg MsmConfig) error
+}
+
+// QuotientDomain holds the device buffers of the domain of a Quotient: the (inverse) twiddles
+// of the domain, the (inverse) powers of the coset generator, and the inverses of the vanishing
+// polynomial on the coset.
+type QuotientDomain struct {
+	Twiddles, TwiddlesInv       unsafe.Pointer
+	CosetPowers, CosetPowersInv unsafe.Pointer
+	Den                         unsafe.Pointer
+}
+
+// QuotientDevice is a Device computing the quotient polynomial of the Groth16 prover in one
+// fused pipeline: its kernels are enqueued back to back, without synchronizing with the host
+// between them. The provers fall back to the Device kernels for the other devices.
+type QuotientDevice interface {
+	Device
+
+	// Quotient returns, in a new device buffer, the n coefficients in bit-reversed order of
+	// h = (a·b-c)/Z, where a, b and c are the evaluations on the domain of n scalars and Z
+	// the vanishing polynomial of the domain. It is the sequence of the Intt, Ntt on the coset,
+	// VecMul, VecSub, VecMul by the Den, Intt on the coset and ReverseScalars kernels, and may
+	// overwrite a, b and c. It returns ErrUnsupported if the device doesn't support it.
+	Quotient(a, b, c unsafe.Pointer, domain QuotientDomain, n int) (unsafe.Pointer, error)
+}
+
+// ScalarMulDevice is a Device multiplying vectors of points by vectors of scalars, element-wise:
+// the kernel of the contributions to the MPC ceremonies of the mpcsetup packages, which multiply
+// each point of the parameters by a secret or a power of a secret. The contributions fall back
+// to the CPU for the other devices.
+type ScalarMulDevice interface {
+	Device
+
+	// ScalarMulG1 sets each of the n affine points at the host address points, which may be at
+	// infinity, to its product by the scalar of the same index at the host address scalars, in
+	// Montgomery form, and ScalarMulG2 in G2. They return ErrUnsupported, before modifying the
+	// points, if the device doesn't support it.
+	ScalarMulG1(points, scalars unsafe.Pointer, n int) error
+	ScalarMulG2(points, scalars unsafe.Pointer, n int) error
+}
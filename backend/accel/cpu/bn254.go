@@ -0,0 +1,329 @@
+package cpu
+
+import (
+	"math/big"
+	"sync"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// deviceBN254 is an emulated device for BN254.
+type deviceBN254 struct {
+	memory
+	domains sync.Map // size → *fft.Domain
+}
+
+// rSquareBN254 has the raw limbs of R² mod r: the Montgomery product of a scalar in regular
+// form with it is its Montgomery form.
+var rSquareBN254 = func() fr.Element {
+	var r fr.Element
+	r.SetBigInt(new(big.Int).Lsh(big.NewInt(1), fr.Limbs*64))
+	return r
+}()
+
+func scalarsBN254(p unsafe.Pointer, n int) []fr.Element {
+	if n == 0 {
+		return nil
+	}
+	return unsafe.Slice((*fr.Element)(p), n)
+}
+
+// montgomeryBN254 returns the Montgomery form of the scalars in regular form.
+func montgomeryBN254(p unsafe.Pointer, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	for i, s := range scalarsBN254(p, n) {
+		res[i].Mul(&s, &rSquareBN254)
+	}
+	return res
+}
+
+// regularBN254 sets the buffer to the regular form of the scalars in Montgomery form.
+func regularBN254(p unsafe.Pointer, scalars []fr.Element) {
+	one := fr.Element{1}
+	dst := scalarsBN254(p, len(scalars))
+	for i := range scalars {
+		dst[i].Mul(&scalars[i], &one)
+	}
+}
+
+func (d *deviceBN254) domain(n int) *fft.Domain {
+	if domain, ok := d.domains.Load(n); ok {
+		return domain.(*fft.Domain)
+	}
+	domain, _ := d.domains.LoadOrStore(n, fft.NewDomain(uint64(n)))
+	return domain.(*fft.Domain)
+}
+
+func (d *deviceBN254) Name() string {
+	return Name
+}
+
+func (d *deviceBN254) Curve() ecc.ID {
+	return ecc.BN254
+}
+
+func (d *deviceBN254) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	one := fr.Element{1}
+	s := scalarsBN254(scalars, n)
+	for i := range s {
+		s[i].Mul(&s[i], &one)
+	}
+	return nil
+}
+
+func (d *deviceBN254) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	fft.BitReverse(scalarsBN254(scalars, n))
+	return nil
+}
+
+func (d *deviceBN254) VecMul(a, b unsafe.Pointer, n int) error {
+	// the Montgomery product of a regular form and a Montgomery form is a regular form
+	sa, sb := scalarsBN254(a, n), montgomeryBN254(b, n)
+	for i := range sa {
+		sa[i].Mul(&sa[i], &sb[i])
+	}
+	return nil
+}
+
+func (d *deviceBN254) VecSub(a, b unsafe.Pointer, n int) error {
+	sa, sb := scalarsBN254(a, n), scalarsBN254(b, n)
+	for i := range sa {
+		sa[i].Sub(&sa[i], &sb[i])
+	}
+	return nil
+}
+
+// Twiddles returns the twiddles as the GPUs do, in regular form; the kernels use the ones of
+// the domains of gnark-crypto.
+func (d *deviceBN254) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	domain := d.domain(n)
+	w := domain.Generator
+	if inverse {
+		w = domain.GeneratorInv
+	}
+	twiddles := make([]fr.Element, (n+1)/2)
+	twiddles[0].SetOne()
+	for i := 1; i < len(twiddles); i++ {
+		twiddles[i].Mul(&twiddles[i-1], &w)
+	}
+	p, err := d.Malloc(len(twiddles) * fr.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	regularBN254(p, twiddles)
+	return p, nil
+}
+
+func (d *deviceBN254) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	values := montgomeryBN254(in, n)
+	if cosetPowers != nil {
+		coset := montgomeryBN254(cosetPowers, n)
+		for i := range values {
+			values[i].Mul(&values[i], &coset[i])
+		}
+	}
+	d.domain(n).FFT(values, fft.DIF)
+	fft.BitReverse(values)
+	regularBN254(out, values)
+	return nil
+}
+
+func (d *deviceBN254) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	values := montgomeryBN254(in, n)
+	d.domain(n).FFTInverse(values, fft.DIF)
+	fft.BitReverse(values)
+	if cosetPowers != nil {
+		coset := montgomeryBN254(cosetPowers, n)
+		for i := range values {
+			values[i].Mul(&values[i], &coset[i])
+		}
+	}
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	regularBN254(out, values)
+	return out, nil
+}
+
+// Quotient runs the kernels of the quotient in Montgomery form, converting the scalars once.
+func (d *deviceBN254) Quotient(a, b, c unsafe.Pointer, domain accel.QuotientDomain, n int) (unsafe.Pointer, error) {
+	fftDomain := d.domain(n)
+	coset, cosetInv := montgomeryBN254(domain.CosetPowers, n), montgomeryBN254(domain.CosetPowersInv, n)
+
+	var evaluations [3][]fr.Element
+	for i, p := range []unsafe.Pointer{a, b, c} {
+		values := montgomeryBN254(p, n)
+		fftDomain.FFTInverse(values, fft.DIF)
+		fft.BitReverse(values)
+		for j := range values {
+			values[j].Mul(&values[j], &coset[j])
+		}
+		fftDomain.FFT(values, fft.DIF)
+		fft.BitReverse(values)
+		evaluations[i] = values
+	}
+
+	h, den := evaluations[0], montgomeryBN254(domain.Den, n)
+	for i := range h {
+		h[i].Mul(&h[i], &evaluations[1][i]).
+			Sub(&h[i], &evaluations[2][i]).
+			Mul(&h[i], &den[i])
+	}
+	fftDomain.FFTInverse(h, fft.DIF)
+	fft.BitReverse(h)
+	for i := range h {
+		h[i].Mul(&h[i], &cosetInv[i])
+	}
+	fft.BitReverse(h)
+
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	regularBN254(out, h)
+	return out, nil
+}
+
+func (d *deviceBN254) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	switch cfg.Representation {
+	case accel.Affine:
+		return d.copyNew(points, n*int(unsafe.Sizeof(curve.G1Affine{})))
+	case accel.Projective:
+		jac := make([]curve.G1Jac, n+1) // not empty
+		for i, p := range unsafe.Slice((*curve.G1Affine)(points), n) {
+			jac[i].FromAffine(&p)
+		}
+		return d.copyNew(unsafe.Pointer(&jac[0]), n*int(unsafe.Sizeof(curve.G1Jac{})))
+	default:
+		return nil, accel.ErrUnsupported
+	}
+}
+
+func (d *deviceBN254) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	switch cfg.Representation {
+	case accel.Affine:
+		return d.copyNew(points, n*int(unsafe.Sizeof(curve.G2Affine{})))
+	case accel.Projective:
+		jac := make([]curve.G2Jac, n+1) // not empty
+		for i, p := range unsafe.Slice((*curve.G2Affine)(points), n) {
+			jac[i].FromAffine(&p)
+		}
+		return d.copyNew(unsafe.Pointer(&jac[0]), n*int(unsafe.Sizeof(curve.G2Jac{})))
+	default:
+		return nil, accel.ErrUnsupported
+	}
+}
+
+// msmInputsG1BN254 returns the affine points and the scalars in Montgomery form of a
+// multi-scalar multiplication, without the points at infinity.
+func msmInputsG1BN254(scalars, points unsafe.Pointer, n int, cfg accel.PointsConfig) ([]curve.G1Affine, []fr.Element) {
+	var affine []curve.G1Affine
+	if cfg.Representation == accel.Projective {
+		affine = make([]curve.G1Affine, n)
+		for i, p := range unsafe.Slice((*curve.G1Jac)(points), n) {
+			affine[i].FromJacobian(&p)
+		}
+	} else {
+		affine = unsafe.Slice((*curve.G1Affine)(points), n)
+	}
+	s := montgomeryBN254(scalars, n)
+	if !cfg.WithInfinity {
+		return affine, s
+	}
+	var filteredPoints []curve.G1Affine
+	var filteredScalars []fr.Element
+	for i := range affine {
+		if !affine[i].IsInfinity() {
+			filteredPoints = append(filteredPoints, affine[i])
+			filteredScalars = append(filteredScalars, s[i])
+		}
+	}
+	return filteredPoints, filteredScalars
+}
+
+// msmInputsG2BN254 is msmInputsG1BN254 in G2.
+func msmInputsG2BN254(scalars, points unsafe.Pointer, n int, cfg accel.PointsConfig) ([]curve.G2Affine, []fr.Element) {
+	var affine []curve.G2Affine
+	if cfg.Representation == accel.Projective {
+		affine = make([]curve.G2Affine, n)
+		for i, p := range unsafe.Slice((*curve.G2Jac)(points), n) {
+			affine[i].FromJacobian(&p)
+		}
+	} else {
+		affine = unsafe.Slice((*curve.G2Affine)(points), n)
+	}
+	s := montgomeryBN254(scalars, n)
+	if !cfg.WithInfinity {
+		return affine, s
+	}
+	var filteredPoints []curve.G2Affine
+	var filteredScalars []fr.Element
+	for i := range affine {
+		if !affine[i].IsInfinity() {
+			filteredPoints = append(filteredPoints, affine[i])
+			filteredScalars = append(filteredScalars, s[i])
+		}
+	}
+	return filteredPoints, filteredScalars
+}
+
+func (d *deviceBN254) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	r := (*curve.G1Jac)(res)
+	r.X.SetOne()
+	r.Y.SetOne()
+	r.Z.SetZero()
+	if n == 0 {
+		return nil
+	}
+	affine, s := msmInputsG1BN254(scalars, points, n, cfg.Points)
+	if len(affine) == 0 {
+		return nil
+	}
+	_, err := r.MultiExp(affine, s, ecc.MultiExpConfig{NbTasks: cfg.NbTasks})
+	return err
+}
+
+func (d *deviceBN254) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	r := (*curve.G2Jac)(res)
+	r.X.SetOne()
+	r.Y.SetOne()
+	r.Z.SetZero()
+	if n == 0 {
+		return nil
+	}
+	affine, s := msmInputsG2BN254(scalars, points, n, cfg.Points)
+	if len(affine) == 0 {
+		return nil
+	}
+	_, err := r.MultiExp(affine, s, ecc.MultiExpConfig{NbTasks: cfg.NbTasks})
+	return err
+}
+
+func (d *deviceBN254) ScalarMulG1(points, scalars unsafe.Pointer, n int) error {
+	p, s := unsafe.Slice((*curve.G1Affine)(points), n), unsafe.Slice((*fr.Element)(scalars), n)
+	utils.Parallelize(n, func(start, end int) {
+		var b big.Int
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], s[i].BigInt(&b))
+		}
+	})
+	return nil
+}
+
+func (d *deviceBN254) ScalarMulG2(points, scalars unsafe.Pointer, n int) error {
+	p, s := unsafe.Slice((*curve.G2Affine)(points), n), unsafe.Slice((*fr.Element)(scalars), n)
+	utils.Parallelize(n, func(start, end int) {
+		var b big.Int
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], s[i].BigInt(&b))
+		}
+	})
+	return nil
+}
@@ -0,0 +1,101 @@
+// Package cpu registers a provider of accelerators running on the CPU, for the BN254 and
+// BLS12-377 curves.
+//
+// It emulates a device with host memory and the CPU kernels of gnark-crypto, so that the GPU
+// provers (their buffer management, chunking and conversions) run on machines without a GPU,
+// for instance on development laptops. It isn't faster than the CPU provers, and holds a second
+// copy of the proving keys, so that the groth16 provers only select it by name, with the
+// environment variable GNARK_ACCEL=cpu or backend.WithAccelerator("cpu"): in the builds without
+// the icicle or rocm build tags, they prove on the CPU by default. The provider is a fallback
+// of accel.Open, which returns it if no other provider supports the curve.
+//
+// The device buffers follow the layouts of the accel package: the scalars are in regular form
+// after FromMontgomery, the kernels convert them to and from the Montgomery form.
+package cpu
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+// Name is the name of the provider in the accel registry.
+const Name = "cpu"
+
+func init() {
+	accel.Register(accel.Provider{
+		Name:   Name,
+		Curves: []ecc.ID{ecc.BN254, ecc.BLS12_377},
+		Open: func(curve ecc.ID) (accel.Device, error) {
+			switch curve {
+			case ecc.BN254:
+				return &deviceBN254{}, nil
+			case ecc.BLS12_377:
+				return &deviceBLS12377{}, nil
+			default:
+				return nil, fmt.Errorf("the cpu provider doesn't support %s", curve)
+			}
+		},
+		Fallback: true,
+	})
+}
+
+var errInvalidPointer = fmt.Errorf("%w: invalid device pointer", accel.ErrDevice)
+
+// memory is the memory of an emulated device: host buffers, kept alive until they are freed.
+type memory struct {
+	lock    sync.Mutex
+	buffers map[unsafe.Pointer][]uint64
+}
+
+func (m *memory) Malloc(size int) (unsafe.Pointer, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid allocation size %d", size)
+	}
+	// 64-bit words, for the alignment of the field elements
+	buffer := make([]uint64, (size+7)/8+1)
+	p := unsafe.Pointer(&buffer[0])
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.buffers == nil {
+		m.buffers = make(map[unsafe.Pointer][]uint64)
+	}
+	m.buffers[p] = buffer
+	return p, nil
+}
+
+func (m *memory) Free(p unsafe.Pointer) error {
+	if p == nil {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.buffers[p]; !ok {
+		return errInvalidPointer
+	}
+	delete(m.buffers, p)
+	return nil
+}
+
+func (m *memory) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+	return nil
+}
+
+func (m *memory) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+	return nil
+}
+
+// copyNew copies size bytes from the host to a new buffer.
+func (m *memory) copyNew(src unsafe.Pointer, size int) (unsafe.Pointer, error) {
+	p, err := m.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	return p, m.CopyToDevice(p, src, size)
+}
@@ -0,0 +1,175 @@
+//go:build icicle
+
+package icicle
+
+// #include "gnark_icicle.h"
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/internal/nvtx"
+	"github.com/ingonyama-zk/icicle/goicicle"
+	icicle "github.com/ingonyama-zk/icicle/goicicle/curves/bls12377"
+	"github.com/ingonyama-zk/iciclegnark/curves/bls12377"
+)
+
+// deviceBLS12377 is the current CUDA device, for BLS12-377.
+type deviceBLS12377 struct{}
+
+func (deviceBLS12377) Name() string {
+	return "icicle/cuda"
+}
+
+func (deviceBLS12377) Curve() ecc.ID {
+	return ecc.BLS12_377
+}
+
+func (deviceBLS12377) Malloc(size int) (unsafe.Pointer, error) {
+	return goicicle.CudaMalloc(size)
+}
+
+func (deviceBLS12377) Free(p unsafe.Pointer) error {
+	goicicle.CudaFree(p)
+	return nil
+}
+
+func (deviceBLS12377) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	defer nvtx.Range("CopyToDevice")()
+	goicicle.CudaMemCpyHtoD[byte](dst, unsafe.Slice((*byte)(src), size), size)
+	return nil
+}
+
+func (deviceBLS12377) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	goicicle.CudaMemCpyDtoH[byte](unsafe.Slice((*byte)(dst), size), src, size)
+	return nil
+}
+
+func (deviceBLS12377) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	defer nvtx.Range("MontConv")()
+	icicle.FromMontgomery(scalars, n)
+	return nil
+}
+
+func (deviceBLS12377) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	icicle.ReverseScalars(scalars, n)
+	return nil
+}
+
+func (deviceBLS12377) VecMul(a, b unsafe.Pointer, n int) error {
+	defer nvtx.Range("PolyOps")()
+	return checkCode("VecScalarMulMod", icicle.VecScalarMulMod(a, b, n))
+}
+
+func (deviceBLS12377) VecSub(a, b unsafe.Pointer, n int) error {
+	defer nvtx.Range("PolyOps")()
+	return checkCode("VecScalarSub", icicle.VecScalarSub(a, b, n))
+}
+
+func (deviceBLS12377) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	return icicle.GenerateTwiddles(n, log2(n), inverse)
+}
+
+func (deviceBLS12377) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	defer nvtx.Range(nttRangeName("NTT", cosetPowers))()
+	if err := checkCode("Evaluate", icicle.Evaluate(out, in, twiddles, cosetPowers, n, n, cosetPowers != nil)); err != nil {
+		return err
+	}
+	icicle.ReverseScalars(out, n)
+	return nil
+}
+
+func (deviceBLS12377) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range(nttRangeName("INTT", cosetPowers))()
+	icicle.ReverseScalars(in, n)
+	return icicle.Interpolate(in, twiddles, cosetPowers, n, cosetPowers != nil), nil
+}
+
+// Quotient runs the kernels of gnark_icicle.h, which are enqueued on one stream, ordered with
+// events around the kernels of icicle, without synchronizing with the host.
+func (deviceBLS12377) Quotient(a, b, c unsafe.Pointer, domain accel.QuotientDomain, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range("ComputeH")()
+	var nInv fr.Element
+	nInv.SetUint64(uint64(n)).Inverse(&nInv)
+	var h unsafe.Pointer
+	code := C.bls12377_quotient(&h, a, b, c, domain.Twiddles, domain.TwiddlesInv, domain.CosetPowers, domain.CosetPowersInv, domain.Den, C.size_t(n), (*C.uint64_t)(unsafe.Pointer(&nInv[0])))
+	if err := checkCode("bls12377_quotient", code); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (deviceBLS12377) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if err := checkPoints(cfg); err != nil {
+		return nil, err
+	}
+	size := n * fp.Bytes * 2
+	p_d, err := goicicle.CudaMalloc(size)
+	if err != nil {
+		return nil, err
+	}
+	iciclePoints := bls12377.BatchConvertFromG1Affine(unsafe.Slice((*curve.G1Affine)(points), n))
+	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](p_d, iciclePoints, size)
+	return p_d, nil
+}
+
+func (deviceBLS12377) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if err := checkPoints(cfg); err != nil {
+		return nil, err
+	}
+	size := n * fp.Bytes * 4
+	p_d, err := goicicle.CudaMalloc(size)
+	if err != nil {
+		return nil, err
+	}
+	iciclePoints := bls12377.BatchConvertFromG2Affine(unsafe.Slice((*curve.G2Affine)(points), n))
+	goicicle.CudaMemCpyHtoD[icicle.G2PointAffine](p_d, iciclePoints, size)
+	return p_d, nil
+}
+
+func (deviceBLS12377) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G1")()
+	if err := checkMsm(cfg); err != nil {
+		return err
+	}
+
+	g1ProjPointBytes := fp.Bytes * 3
+	out_d, err := goicicle.CudaMalloc(g1ProjPointBytes)
+	if err != nil {
+		return err
+	}
+	defer goicicle.CudaFree(out_d)
+
+	icicle.Commit(out_d, scalars, points, n, bucketFactor(cfg))
+
+	outHost := make([]icicle.G1ProjectivePoint, 1)
+	goicicle.CudaMemCpyDtoH[icicle.G1ProjectivePoint](outHost, out_d, g1ProjPointBytes)
+	*(*curve.G1Jac)(res) = *bls12377.G1ProjectivePointToGnarkJac(&outHost[0])
+	return nil
+}
+
+func (deviceBLS12377) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G2")()
+	if err := checkMsm(cfg); err != nil {
+		return err
+	}
+
+	g2ProjPointBytes := fp.Bytes * 6
+	out_d, err := goicicle.CudaMalloc(g2ProjPointBytes)
+	if err != nil {
+		return err
+	}
+	defer goicicle.CudaFree(out_d)
+
+	icicle.CommitG2(out_d, scalars, points, n, bucketFactor(cfg))
+
+	outHost := make([]icicle.G2Point, 1)
+	goicicle.CudaMemCpyDtoH[icicle.G2Point](outHost, out_d, g2ProjPointBytes)
+	*(*curve.G2Jac)(res) = *bls12377.G2PointToGnarkJac(&outHost[0])
+	return nil
+}
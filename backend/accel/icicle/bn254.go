@@ -0,0 +1,218 @@
+//go:build icicle
+
+package icicle
+
+// #include "gnark_icicle.h"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/internal/nvtx"
+	"github.com/ingonyama-zk/icicle/goicicle"
+	icicle "github.com/ingonyama-zk/icicle/goicicle/curves/bn254"
+	"github.com/ingonyama-zk/iciclegnark/curves/bn254"
+)
+
+// deviceBN254 is the current CUDA device, for BN254.
+type deviceBN254 struct{}
+
+func (deviceBN254) Name() string {
+	return "icicle/cuda"
+}
+
+func (deviceBN254) Curve() ecc.ID {
+	return ecc.BN254
+}
+
+func (deviceBN254) Malloc(size int) (unsafe.Pointer, error) {
+	return goicicle.CudaMalloc(size)
+}
+
+func (deviceBN254) Free(p unsafe.Pointer) error {
+	goicicle.CudaFree(p)
+	return nil
+}
+
+func (deviceBN254) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	defer nvtx.Range("CopyToDevice")()
+	goicicle.CudaMemCpyHtoD[byte](dst, unsafe.Slice((*byte)(src), size), size)
+	return nil
+}
+
+func (deviceBN254) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	goicicle.CudaMemCpyDtoH[byte](unsafe.Slice((*byte)(dst), size), src, size)
+	return nil
+}
+
+func (deviceBN254) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	defer nvtx.Range("MontConv")()
+	icicle.FromMontgomery(scalars, n)
+	return nil
+}
+
+func (deviceBN254) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	icicle.ReverseScalars(scalars, n)
+	return nil
+}
+
+func (deviceBN254) VecMul(a, b unsafe.Pointer, n int) error {
+	defer nvtx.Range("PolyOps")()
+	return checkCode("VecScalarMulMod", icicle.VecScalarMulMod(a, b, n))
+}
+
+func (deviceBN254) VecSub(a, b unsafe.Pointer, n int) error {
+	defer nvtx.Range("PolyOps")()
+	return checkCode("VecScalarSub", icicle.VecScalarSub(a, b, n))
+}
+
+func (deviceBN254) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	return icicle.GenerateTwiddles(n, log2(n), inverse)
+}
+
+func (deviceBN254) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	defer nvtx.Range(nttRangeName("NTT", cosetPowers))()
+	if err := checkCode("Evaluate", icicle.Evaluate(out, in, twiddles, cosetPowers, n, n, cosetPowers != nil)); err != nil {
+		return err
+	}
+	icicle.ReverseScalars(out, n)
+	return nil
+}
+
+func (deviceBN254) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range(nttRangeName("INTT", cosetPowers))()
+	icicle.ReverseScalars(in, n)
+	return icicle.Interpolate(in, twiddles, cosetPowers, n, cosetPowers != nil), nil
+}
+
+// Quotient runs the kernels of gnark_icicle.h, which are enqueued on one stream, ordered with
+// events around the kernels of icicle, without synchronizing with the host.
+func (deviceBN254) Quotient(a, b, c unsafe.Pointer, domain accel.QuotientDomain, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range("ComputeH")()
+	var nInv fr.Element
+	nInv.SetUint64(uint64(n)).Inverse(&nInv)
+	var h unsafe.Pointer
+	code := C.bn254_quotient(&h, a, b, c, domain.Twiddles, domain.TwiddlesInv, domain.CosetPowers, domain.CosetPowersInv, domain.Den, C.size_t(n), (*C.uint64_t)(unsafe.Pointer(&nInv[0])))
+	if err := checkCode("bn254_quotient", code); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (deviceBN254) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if err := checkPoints(cfg); err != nil {
+		return nil, err
+	}
+	size := n * fp.Bytes * 2
+	p_d, err := goicicle.CudaMalloc(size)
+	if err != nil {
+		return nil, err
+	}
+	iciclePoints := bn254.BatchConvertFromG1Affine(unsafe.Slice((*curve.G1Affine)(points), n))
+	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](p_d, iciclePoints, size)
+	return p_d, nil
+}
+
+func (deviceBN254) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if err := checkPoints(cfg); err != nil {
+		return nil, err
+	}
+	size := n * fp.Bytes * 4
+	p_d, err := goicicle.CudaMalloc(size)
+	if err != nil {
+		return nil, err
+	}
+	iciclePoints := bn254.BatchConvertFromG2Affine(unsafe.Slice((*curve.G2Affine)(points), n))
+	goicicle.CudaMemCpyHtoD[icicle.G2PointAffine](p_d, iciclePoints, size)
+	return p_d, nil
+}
+
+func (deviceBN254) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G1")()
+	if err := checkMsm(cfg); err != nil {
+		return err
+	}
+
+	g1ProjPointBytes := fp.Bytes * 3
+	out_d, err := goicicle.CudaMalloc(g1ProjPointBytes)
+	if err != nil {
+		return err
+	}
+	defer goicicle.CudaFree(out_d)
+
+	icicle.Commit(out_d, scalars, points, n, bucketFactor(cfg))
+
+	outHost := make([]icicle.G1ProjectivePoint, 1)
+	goicicle.CudaMemCpyDtoH[icicle.G1ProjectivePoint](outHost, out_d, g1ProjPointBytes)
+	*(*curve.G1Jac)(res) = *bn254.G1ProjectivePointToGnarkJac(&outHost[0])
+	return nil
+}
+
+func (deviceBN254) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G2")()
+	if err := checkMsm(cfg); err != nil {
+		return err
+	}
+
+	g2ProjPointBytes := fp.Bytes * 6
+	out_d, err := goicicle.CudaMalloc(g2ProjPointBytes)
+	if err != nil {
+		return err
+	}
+	defer goicicle.CudaFree(out_d)
+
+	icicle.CommitG2(out_d, scalars, points, n, bucketFactor(cfg))
+
+	outHost := make([]icicle.G2Point, 1)
+	goicicle.CudaMemCpyDtoH[icicle.G2Point](outHost, out_d, g2ProjPointBytes)
+	*(*curve.G2Jac)(res) = *bn254.G2PointToGnarkJac(&outHost[0])
+	return nil
+}
+
+// ScalarMulG1 runs the vec_mod_mult_point kernel, which multiplies projective points on the
+// host, so that the points are converted on the host around it.
+func (deviceBN254) ScalarMulG1(points, scalars unsafe.Pointer, n int) error {
+	defer nvtx.Range("ScalarMul G1")()
+	if n == 0 {
+		return nil
+	}
+	p := unsafe.Slice((*curve.G1Affine)(points), n)
+	projective := make([]icicle.G1ProjectivePoint, n)
+	parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			if p[i].IsInfinity() {
+				projective[i].Y.SetOne()
+				continue
+			}
+			bn254.FromG1AffineGnark(&p[i], &projective[i])
+		}
+	})
+	s := bn254.BatchConvertFromFrGnark[icicle.G1ScalarField](unsafe.Slice((*fr.Element)(scalars), n))
+
+	icicle.MultiplyVec(projective, s, 0)
+
+	// the Z coordinates of the points at infinity are 0, which BatchInvert leaves, giving (0, 0)
+	z := make([]fp.Element, n)
+	for i := range projective {
+		z[i] = *bn254.BaseFieldToGnarkFp(&projective[i].Z)
+	}
+	zInv := fp.BatchInvert(z)
+	parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			p[i].X.Mul(bn254.BaseFieldToGnarkFp(&projective[i].X), &zInv[i])
+			p[i].Y.Mul(bn254.BaseFieldToGnarkFp(&projective[i].Y), &zInv[i])
+		}
+	})
+	return nil
+}
+
+// ScalarMulG2 isn't supported: icicle has no G2 kernel.
+func (deviceBN254) ScalarMulG2(points, scalars unsafe.Pointer, n int) error {
+	return fmt.Errorf("%w: icicle has no G2 scalar multiplication", accel.ErrUnsupported)
+}
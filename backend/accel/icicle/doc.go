@@ -0,0 +1,28 @@
+// Package icicle registers the icicle CUDA provider of accelerators, for the BN254 and
+// BLS12-377 curves.
+//
+// The provider is built with the icicle build tag, which requires the CUDA toolkit and links
+// the icicle libraries, and the CUDA kernels of the kernels directory, which are built first
+// with nvcc:
+//
+//	make -C backend/accel/icicle/kernels CUDA_ARCH=sm_80
+//	go build -tags icicle ./...
+//
+// The kernels add the fused quotient of accel.QuotientDevice to the ones of icicle: the NTTs
+// and the vector operations of the quotient are enqueued on one stream, ordered with events
+// after and before the kernels of icicle on the legacy default stream, without synchronizing
+// with the host. The tests of the package, built with the tag too, fail without a CUDA device:
+//
+//	go test -tags icicle ./backend/accel/icicle
+//
+// Without the tag, the package is empty and the GPU provers of gnark run on the other
+// registered providers, such as the CPU emulation of the cpu package, so that the same code
+// builds CPU-only and GPU binaries. See accel.Open for the selection of the provider at run
+// time.
+//
+// The provider Name runs on the current CUDA device. A provider is also registered for each
+// CUDA device, named by GPUName, so that the provers of one process use several GPUs.
+package icicle
+
+// Name is the name of the provider in the accel registry.
+const Name = "icicle"
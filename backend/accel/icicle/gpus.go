@@ -0,0 +1,177 @@
+//go:build icicle
+
+package icicle
+
+// #cgo CFLAGS: -I /usr/local/cuda/include
+// #cgo LDFLAGS: -L/usr/local/cuda/lib64 -lcudart
+// #include <cuda_runtime.h>
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+func init() {
+	var n C.int
+	if C.cudaGetDeviceCount(&n) != C.cudaSuccess {
+		// no GPU, or no driver: the provider of the current device reports it when opened
+		return
+	}
+	for i := 0; i < int(n); i++ {
+		index := i
+		accel.Register(accel.Provider{
+			Name:   GPUName(index),
+			Curves: []ecc.ID{ecc.BN254, ecc.BLS12_377},
+			Open: func(curve ecc.ID) (accel.Device, error) {
+				var d accel.Device
+				switch curve {
+				case ecc.BN254:
+					d = deviceBN254{}
+				case ecc.BLS12_377:
+					d = deviceBLS12377{}
+				default:
+					return nil, fmt.Errorf("icicle doesn't support %s", curve)
+				}
+				return &gpu{device: d, index: index}, nil
+			},
+		})
+	}
+}
+
+// GPUName returns the name of the provider of the CUDA device of the index, such as
+// "icicle/1", registered for each device: its kernels run on that device whatever the current
+// device of the calling thread, so that several GPUs serve the provers of one process.
+func GPUName(index int) string {
+	return Name + "/" + strconv.Itoa(index)
+}
+
+// gpu is a device of the icicle provider bound to a CUDA device: its methods select the CUDA
+// device on a locked OS thread, the current device being a state of the thread.
+type gpu struct {
+	device accel.Device
+	index  int
+}
+
+// pin locks the goroutine to its OS thread and selects the CUDA device, until the returned
+// function is called.
+func (g *gpu) pin() func() {
+	runtime.LockOSThread()
+	C.cudaSetDevice(C.int(g.index))
+	return runtime.UnlockOSThread
+}
+
+func (g *gpu) Name() string {
+	return "icicle/cuda:" + strconv.Itoa(g.index)
+}
+
+func (g *gpu) Curve() ecc.ID {
+	return g.device.Curve()
+}
+
+func (g *gpu) Malloc(size int) (unsafe.Pointer, error) {
+	defer g.pin()()
+	return g.device.Malloc(size)
+}
+
+func (g *gpu) Free(p unsafe.Pointer) error {
+	defer g.pin()()
+	return g.device.Free(p)
+}
+
+func (g *gpu) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	defer g.pin()()
+	return g.device.CopyToDevice(dst, src, size)
+}
+
+func (g *gpu) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	defer g.pin()()
+	return g.device.CopyToHost(dst, src, size)
+}
+
+func (g *gpu) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	defer g.pin()()
+	return g.device.FromMontgomery(scalars, n)
+}
+
+func (g *gpu) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	defer g.pin()()
+	return g.device.ReverseScalars(scalars, n)
+}
+
+func (g *gpu) VecMul(a, b unsafe.Pointer, n int) error {
+	defer g.pin()()
+	return g.device.VecMul(a, b, n)
+}
+
+func (g *gpu) VecSub(a, b unsafe.Pointer, n int) error {
+	defer g.pin()()
+	return g.device.VecSub(a, b, n)
+}
+
+func (g *gpu) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	defer g.pin()()
+	return g.device.Twiddles(n, inverse)
+}
+
+func (g *gpu) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	defer g.pin()()
+	return g.device.Ntt(out, in, twiddles, cosetPowers, n)
+}
+
+func (g *gpu) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	defer g.pin()()
+	return g.device.Intt(in, twiddles, cosetPowers, n)
+}
+
+func (g *gpu) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	defer g.pin()()
+	return g.device.PointsG1ToDevice(points, n, cfg)
+}
+
+func (g *gpu) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	defer g.pin()()
+	return g.device.PointsG2ToDevice(points, n, cfg)
+}
+
+func (g *gpu) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer g.pin()()
+	return g.device.Msm(res, scalars, points, n, cfg)
+}
+
+func (g *gpu) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer g.pin()()
+	return g.device.MsmG2(res, scalars, points, n, cfg)
+}
+
+func (g *gpu) Quotient(a, b, c unsafe.Pointer, domain accel.QuotientDomain, n int) (unsafe.Pointer, error) {
+	q, ok := g.device.(accel.QuotientDevice)
+	if !ok {
+		return nil, accel.ErrUnsupported
+	}
+	defer g.pin()()
+	return q.Quotient(a, b, c, domain, n)
+}
+
+func (g *gpu) ScalarMulG1(points, scalars unsafe.Pointer, n int) error {
+	s, ok := g.device.(accel.ScalarMulDevice)
+	if !ok {
+		return accel.ErrUnsupported
+	}
+	defer g.pin()()
+	return s.ScalarMulG1(points, scalars, n)
+}
+
+func (g *gpu) ScalarMulG2(points, scalars unsafe.Pointer, n int) error {
+	s, ok := g.device.(accel.ScalarMulDevice)
+	if !ok {
+		return accel.ErrUnsupported
+	}
+	defer g.pin()()
+	return s.ScalarMulG2(points, scalars, n)
+}
@@ -0,0 +1,101 @@
+//go:build icicle
+
+package icicle
+
+// #cgo CFLAGS: -I${SRCDIR}/kernels
+// #cgo LDFLAGS: -L${SRCDIR}/kernels -lgnark_icicle -Wl,-rpath,${SRCDIR}/kernels
+import "C"
+
+import (
+	"fmt"
+	"math/bits"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+func init() {
+	accel.Register(accel.Provider{
+		Name:   Name,
+		Curves: []ecc.ID{ecc.BN254, ecc.BLS12_377},
+		Open: func(curve ecc.ID) (accel.Device, error) {
+			switch curve {
+			case ecc.BN254:
+				return deviceBN254{}, nil
+			case ecc.BLS12_377:
+				return deviceBLS12377{}, nil
+			default:
+				return nil, fmt.Errorf("icicle doesn't support %s", curve)
+			}
+		},
+	})
+}
+
+func bucketFactor(cfg accel.MsmConfig) int {
+	if cfg.BucketFactor == 0 {
+		return accel.DefaultBucketFactor
+	}
+	return cfg.BucketFactor
+}
+
+// checkPoints returns an error if the layout of the points isn't the NoInfinity affine one,
+// which is the only one the MSMs of icicle support.
+func checkPoints(cfg accel.PointsConfig) error {
+	if cfg != (accel.PointsConfig{}) {
+		return fmt.Errorf("%w: icicle only supports affine points without infinity, not %s points with infinity=%t", accel.ErrUnsupported, cfg.Representation, cfg.WithInfinity)
+	}
+	return nil
+}
+
+// checkMsm returns an error if the multi-scalar multiplication sets a number of CPU tasks, the
+// MSMs of icicle running on the GPU, or if checkPoints does.
+func checkMsm(cfg accel.MsmConfig) error {
+	if cfg.NbTasks != 0 {
+		return fmt.Errorf("%w: icicle computes the MSMs on the GPU, not on %d CPU tasks", accel.ErrUnsupported, cfg.NbTasks)
+	}
+	return checkPoints(cfg.Points)
+}
+
+// parallelize splits the n iterations in chunks run concurrently on the CPUs, like
+// utils.Parallelize: the package can't import internal/utils, which imports the gnark package
+// and so the providers its Capabilities reports.
+func parallelize(n int, work func(start, end int)) {
+	nbTasks := runtime.NumCPU()
+	if nbTasks > n {
+		nbTasks = n
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < nbTasks; i++ {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			work(start, end)
+		}(n*i/nbTasks, n*(i+1)/nbTasks)
+	}
+	wg.Wait()
+}
+
+// log2 returns the base 2 logarithm of n, a power of 2.
+func log2(n int) int {
+	return bits.TrailingZeros(uint(n))
+}
+
+// checkCode returns an error if the return code of the kernel isn't 0.
+func checkCode[T comparable](kernel string, code T) error {
+	var success T
+	if code != success {
+		return fmt.Errorf("%w: icicle %s failed with code %v", accel.ErrDevice, kernel, code)
+	}
+	return nil
+}
+
+// nttRangeName returns the name of the NVTX range of a (inverse) NTT.
+func nttRangeName(name string, cosetPowers unsafe.Pointer) string {
+	if cosetPowers != nil {
+		return name + " coset"
+	}
+	return name
+}
@@ -0,0 +1,148 @@
+//go:build icicle
+
+package icicle
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	fft_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fft_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+// TestQuotient compares the fused quotient with the icicle kernels run one by one, which also
+// checks that the kernels read the twiddles of icicle in their layout. It fails without a CUDA
+// device, so that it can't pass without running the kernels.
+func TestQuotient(t *testing.T) {
+	const n = 1 << 10
+	t.Run("bn254", func(t *testing.T) {
+		random := func() []fr_bn254.Element {
+			v := make([]fr_bn254.Element, n)
+			for i := range v {
+				v[i].SetRandom()
+			}
+			return v
+		}
+		domain := fft_bn254.NewDomain(n)
+		testQuotient(t, ecc.BN254, n, random, domain.CosetTable, domain.CosetTableInv)
+	})
+	t.Run("bls12-377", func(t *testing.T) {
+		random := func() []fr_bls12377.Element {
+			v := make([]fr_bls12377.Element, n)
+			for i := range v {
+				v[i].SetRandom()
+			}
+			return v
+		}
+		domain := fft_bls12377.NewDomain(n)
+		testQuotient(t, ecc.BLS12_377, n, random, domain.CosetTable, domain.CosetTableInv)
+	})
+}
+
+func testQuotient[E any](t *testing.T, curve ecc.ID, n int, random func() []E, cosetTable, cosetTableInv []E) {
+	d, err := accel.OpenProvider(Name, curve)
+	if err != nil {
+		t.Fatal("no CUDA device:", err)
+	}
+	q, ok := d.(accel.QuotientDevice)
+	if !ok {
+		t.Fatal("the device has no fused quotient")
+	}
+	size := n * int(unsafe.Sizeof(cosetTable[0]))
+
+	// toDevice copies the scalars to the device in regular form.
+	toDevice := func(scalars []E) unsafe.Pointer {
+		p, err := d.Malloc(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.CopyToDevice(p, unsafe.Pointer(&scalars[0]), size); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.FromMontgomery(p, n); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	toHost := func(p unsafe.Pointer) []byte {
+		res := make([]byte, size)
+		if err := d.CopyToHost(unsafe.Pointer(&res[0]), p, size); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	vectors := [3][]E{random(), random(), random()}
+	twiddles, err := d.Twiddles(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddles)
+	twiddlesInv, err := d.Twiddles(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddlesInv)
+	domain := accel.QuotientDomain{
+		Twiddles:       twiddles,
+		TwiddlesInv:    twiddlesInv,
+		CosetPowers:    toDevice(cosetTable),
+		CosetPowersInv: toDevice(cosetTableInv),
+		Den:            toDevice(random()),
+	}
+	defer d.Free(domain.CosetPowers)
+	defer d.Free(domain.CosetPowersInv)
+	defer d.Free(domain.Den)
+
+	// the kernels one by one
+	var v [3]unsafe.Pointer
+	for i := range v {
+		v[i] = toDevice(vectors[i])
+		coefficients, err := d.Intt(v[i], twiddlesInv, nil, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = d.Ntt(v[i], coefficients, twiddles, domain.CosetPowers, n); err != nil {
+			t.Fatal(err)
+		}
+		_ = d.Free(coefficients)
+	}
+	if err = d.VecMul(v[0], v[1], n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.VecSub(v[0], v[2], n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.VecMul(v[0], domain.Den, n); err != nil {
+		t.Fatal(err)
+	}
+	expected_d, err := d.Intt(v[0], twiddlesInv, domain.CosetPowersInv, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(expected_d)
+	if err = d.ReverseScalars(expected_d, n); err != nil {
+		t.Fatal(err)
+	}
+	expected := toHost(expected_d)
+
+	// and fused
+	for i := range v {
+		_ = d.Free(v[i])
+		v[i] = toDevice(vectors[i])
+		defer d.Free(v[i])
+	}
+	h_d, err := q.Quotient(v[0], v[1], v[2], domain, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(h_d)
+	if !bytes.Equal(toHost(h_d), expected) {
+		t.Fatal("the fused quotient differs from the kernels run one by one")
+	}
+}
@@ -0,0 +1,135 @@
+// Arithmetic of the scalar fields of BN254 and BLS12-377, for the kernels of gnark_icicle.h.
+//
+// The elements are 4 little-endian 64-bit limbs, in the layout of gnark-crypto, and are in
+// regular form on the device, as the scalars of icicle (see FromMontgomery in the provider).
+#pragma once
+
+#include <stdint.h>
+
+#define GNARK_FN __host__ __device__ __forceinline__
+
+typedef unsigned __int128 u128;
+
+struct bn254_fr_params {
+  GNARK_FN static uint64_t mod(int i) {
+    const uint64_t v[4] = {0x43e1f593f0000001ULL, 0x2833e84879b97091ULL, 0xb85045b68181585dULL, 0x30644e72e131a029ULL};
+    return v[i];
+  }
+  static constexpr uint64_t inv = 0xc2e1f593efffffffULL; // -mod⁻¹ mod 2⁶⁴
+  GNARK_FN static uint64_t r2(int i) {
+    const uint64_t v[4] = {0x1bb8e645ae216da7ULL, 0x53fe3ab1e35c59e3ULL, 0x8c49833d53bb8085ULL, 0x0216d0b17f4e44a5ULL};
+    return v[i];
+  }
+};
+
+struct bls12377_fr_params {
+  GNARK_FN static uint64_t mod(int i) {
+    const uint64_t v[4] = {0x0a11800000000001ULL, 0x59aa76fed0000001ULL, 0x60b44d1e5c37b001ULL, 0x12ab655e9a2ca556ULL};
+    return v[i];
+  }
+  static constexpr uint64_t inv = 0x0a117fffffffffffULL;
+  GNARK_FN static uint64_t r2(int i) {
+    const uint64_t v[4] = {0x25d577bab861857bULL, 0xcc2c27b58860591fULL, 0xa7cc008fe5dc8593ULL, 0x011fdae7eff1c939ULL};
+    return v[i];
+  }
+};
+
+// field is an element of the prime field of P, a modulus below 2²⁵⁵.
+template <typename P>
+struct field {
+  uint64_t l[4];
+
+  GNARK_FN static field r2() { return field{{P::r2(0), P::r2(1), P::r2(2), P::r2(3)}}; }
+
+  // reduce subtracts the modulus once if z ≥ modulus.
+  GNARK_FN static field reduce(field z) {
+    field t;
+    u128 borrow = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 d = (u128)z.l[i] - P::mod(i) - borrow;
+      t.l[i] = (uint64_t)d;
+      borrow = (d >> 64) & 1;
+    }
+    return borrow ? z : t;
+  }
+
+  GNARK_FN field operator+(const field &b) const {
+    field z;
+    u128 carry = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 s = (u128)l[i] + b.l[i] + carry;
+      z.l[i] = (uint64_t)s;
+      carry = s >> 64;
+    }
+    return reduce(z); // no carry out, the modulus is below 2²⁵⁵
+  }
+
+  GNARK_FN field operator-(const field &b) const {
+    field z;
+    u128 borrow = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 d = (u128)l[i] - b.l[i] - borrow;
+      z.l[i] = (uint64_t)d;
+      borrow = (d >> 64) & 1;
+    }
+    if (borrow) {
+      u128 carry = 0;
+      for (int i = 0; i < 4; i++) {
+        u128 s = (u128)z.l[i] + P::mod(i) + carry;
+        z.l[i] = (uint64_t)s;
+        carry = s >> 64;
+      }
+    }
+    return z;
+  }
+
+  // operator* is the Montgomery product a·b·2⁻²⁵⁶ (CIOS).
+  GNARK_FN field operator*(const field &b) const {
+    uint64_t t[6] = {0, 0, 0, 0, 0, 0};
+    for (int i = 0; i < 4; i++) {
+      u128 c = 0;
+      for (int j = 0; j < 4; j++) {
+        c = (u128)l[j] * b.l[i] + t[j] + (c >> 64);
+        t[j] = (uint64_t)c;
+      }
+      c = (u128)t[4] + (c >> 64);
+      t[4] = (uint64_t)c;
+      t[5] = (uint64_t)(c >> 64);
+
+      uint64_t m = t[0] * P::inv;
+      c = (u128)m * P::mod(0) + t[0];
+      for (int j = 1; j < 4; j++) {
+        c = (u128)m * P::mod(j) + t[j] + (c >> 64);
+        t[j - 1] = (uint64_t)c;
+      }
+      c = (u128)t[4] + (c >> 64);
+      t[3] = (uint64_t)c;
+      t[4] = t[5] + (uint64_t)(c >> 64);
+    }
+    return reduce(field{{t[0], t[1], t[2], t[3]}});
+  }
+
+  // mul_regular returns the product of elements in regular form.
+  GNARK_FN field mul_regular(const field &b) const { return (*this * b) * r2(); }
+};
+
+// bit_reverse returns the reversal of the log_n lower bits of i.
+GNARK_FN uint64_t bit_reverse(uint64_t i, int log_n) {
+  uint64_t r = 0;
+  for (int k = 0; k < log_n; k++, i >>= 1)
+    r = (r << 1) | (i & 1);
+  return r;
+}
+
+// ntt_butterfly runs the butterfly t < n/2 of the stage of half-size m of a decimation in
+// time NTT of size n, on scalars in regular form in bit-reversed order. The twiddles are the
+// powers of the root of unity of order n in natural order, as icicle generates them; the n/2
+// first ones are read.
+template <typename F>
+GNARK_FN void ntt_butterfly(F *a, const F *twiddles, uint64_t t, uint64_t m, uint64_t n) {
+  uint64_t j = t % m;
+  uint64_t i0 = (t / m) * 2 * m + j, i1 = i0 + m;
+  F u = a[i0], v = a[i1].mul_regular(twiddles[j * (n / (2 * m))]);
+  a[i0] = u + v;
+  a[i1] = u - v;
+}
@@ -0,0 +1,36 @@
+// C interface of the CUDA kernels the icicle provider adds to the ones of icicle, see
+// backend/accel/icicle.
+//
+// The functions return a cudaError_t, 0 on success. The pointers are device pointers unless
+// noted otherwise, and the sizes are numbers of elements.
+#pragma once
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// bn254_quotient sets *h to a new buffer of the n coefficients, in bit-reversed order, of
+// (a·b-c)/Z, where a, b and c are evaluations on the domain and Z its vanishing polynomial. It
+// is the sequence of the inverse NTTs of a, b and c, their NTTs on the coset, the products and
+// the difference of the vectors, the product by den (the inverses of Z on the coset), the
+// inverse NTT on the coset and the bit-reversal permutation. n_inv is the inverse of n in
+// Montgomery form, on the host. It overwrites a, b and c.
+//
+// The kernels are enqueued on one stream, ordered with events after the work of the legacy
+// default stream, on which icicle runs, and before its next work: the function returns
+// without synchronizing with the host, and the kernels of icicle then read h once the
+// quotient is computed.
+int bn254_quotient(void **h, void *a, void *b, void *c, const void *twiddles, const void *twiddles_inv,
+                   const void *coset, const void *coset_inv, const void *den, size_t n, const uint64_t n_inv[4]);
+
+// bls12377_quotient is bn254_quotient on the scalars of BLS12-377.
+int bls12377_quotient(void **h, void *a, void *b, void *c, const void *twiddles, const void *twiddles_inv,
+                      const void *coset, const void *coset_inv, const void *den, size_t n,
+                      const uint64_t n_inv[4]);
+
+#ifdef __cplusplus
+}
+#endif
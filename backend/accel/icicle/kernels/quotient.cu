@@ -0,0 +1,166 @@
+// CUDA kernels of the fused quotient of the groth16 provers, see gnark_icicle.h.
+#include <cuda_runtime.h>
+
+#include "fr.cuh"
+#include "gnark_icicle.h"
+
+#define BLOCK_SIZE 256
+
+static unsigned nb_blocks(size_t n) { return (unsigned)((n + BLOCK_SIZE - 1) / BLOCK_SIZE); }
+
+static int log2_size(size_t n) {
+  int log_n = 0;
+  while (((size_t)1 << log_n) < n)
+    log_n++;
+  return log_n;
+}
+
+// k_bit_reverse sets out to the bit-reversal permutation of in; coset, if not NULL, multiplies
+// the scalars first.
+template <typename F>
+__global__ void k_bit_reverse(F *out, const F *in, const F *coset, size_t n, int log_n) {
+  size_t i = blockIdx.x * (size_t)blockDim.x + threadIdx.x;
+  if (i < n) {
+    F x = in[i];
+    if (coset != nullptr)
+      x = x.mul_regular(coset[i]);
+    out[bit_reverse(i, log_n)] = x;
+  }
+}
+
+template <typename F>
+__global__ void k_vec_mul(F *a, const F *b, size_t n) {
+  size_t i = blockIdx.x * (size_t)blockDim.x + threadIdx.x;
+  if (i < n)
+    a[i] = a[i].mul_regular(b[i]);
+}
+
+template <typename F>
+__global__ void k_vec_sub(F *a, const F *b, size_t n) {
+  size_t i = blockIdx.x * (size_t)blockDim.x + threadIdx.x;
+  if (i < n)
+    a[i] = a[i] - b[i];
+}
+
+template <typename F>
+__global__ void k_ntt_stage(F *a, const F *twiddles, size_t m, size_t n) {
+  size_t t = blockIdx.x * (size_t)blockDim.x + threadIdx.x;
+  if (t < n / 2)
+    ntt_butterfly(a, twiddles, t, m, n);
+}
+
+// k_ntt_scale multiplies the scalars by n_inv, in Montgomery form, and then by the coset
+// powers if not NULL.
+template <typename F>
+__global__ void k_ntt_scale(F *a, F n_inv, const F *coset, size_t n) {
+  size_t i = blockIdx.x * (size_t)blockDim.x + threadIdx.x;
+  if (i < n) {
+    F x = a[i] * n_inv;
+    if (coset != nullptr)
+      x = x.mul_regular(coset[i]);
+    a[i] = x;
+  }
+}
+
+// enqueue_ntt enqueues on the stream the kernels setting out to the NTT of in, in natural
+// order. The forward NTT multiplies in by the coset powers first, and the inverse NTT
+// multiplies its result by n_inv and then by the inverse coset powers; coset may be NULL.
+template <typename F>
+static void enqueue_ntt(cudaStream_t stream, F *out, const F *in, const F *twiddles, const F *coset, size_t n,
+                        bool inverse, F n_inv) {
+  k_bit_reverse<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(out, in, inverse ? nullptr : coset, n, log2_size(n));
+  for (size_t m = 1; m < n; m *= 2)
+    k_ntt_stage<F><<<nb_blocks(n / 2), BLOCK_SIZE, 0, stream>>>(out, twiddles, m, n);
+  if (inverse)
+    k_ntt_scale<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(out, n_inv, coset, n);
+}
+
+// enqueue_quotient enqueues the kernels of the quotient on the stream, with tmp a buffer of n
+// scalars.
+template <typename F>
+static void enqueue_quotient(cudaStream_t stream, F *h, F *a, F *b, F *c, F *tmp, const F *twiddles,
+                             const F *twiddles_inv, const F *coset, const F *coset_inv, const F *den, size_t n,
+                             F n_inv) {
+  // the evaluations on the coset, through the coefficients in tmp
+  F *vectors[3] = {a, b, c};
+  for (F *v : vectors) {
+    enqueue_ntt<F>(stream, tmp, v, twiddles_inv, nullptr, n, true, n_inv);
+    enqueue_ntt<F>(stream, v, tmp, twiddles, coset, n, false, n_inv);
+  }
+  k_vec_mul<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(a, b, n);
+  k_vec_sub<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(a, c, n);
+  k_vec_mul<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(a, den, n);
+  enqueue_ntt<F>(stream, tmp, a, twiddles_inv, coset_inv, n, true, n_inv);
+  k_bit_reverse<F><<<nb_blocks(n), BLOCK_SIZE, 0, stream>>>(h, tmp, nullptr, n, log2_size(n));
+}
+
+template <typename F>
+static int quotient(void **h, void *a, void *b, void *c, const void *twiddles, const void *twiddles_inv,
+                    const void *coset, const void *coset_inv, const void *den, size_t n, const uint64_t n_inv[4]) {
+  *h = nullptr;
+  if (n == 0)
+    return 0;
+  F scale = {{n_inv[0], n_inv[1], n_inv[2], n_inv[3]}};
+
+  cudaStream_t stream;
+  int err = (int)cudaStreamCreateWithFlags(&stream, cudaStreamNonBlocking);
+  if (err != 0)
+    return err;
+  cudaEvent_t ready = nullptr, done = nullptr;
+  F *tmp = nullptr, *out = nullptr;
+  err = (int)cudaEventCreateWithFlags(&ready, cudaEventDisableTiming);
+  if (err == 0)
+    err = (int)cudaEventCreateWithFlags(&done, cudaEventDisableTiming);
+
+  // a, b and c are copied to the device on the legacy default stream
+  if (err == 0)
+    err = (int)cudaEventRecord(ready, cudaStreamLegacy);
+  if (err == 0)
+    err = (int)cudaStreamWaitEvent(stream, ready, 0);
+
+  if (err == 0)
+    err = (int)cudaMallocAsync((void **)&tmp, n * sizeof(F), stream);
+  if (err == 0)
+    err = (int)cudaMallocAsync((void **)&out, n * sizeof(F), stream);
+  if (err == 0) {
+    enqueue_quotient<F>(stream, out, (F *)a, (F *)b, (F *)c, tmp, (const F *)twiddles, (const F *)twiddles_inv,
+                        (const F *)coset, (const F *)coset_inv, (const F *)den, n, scale);
+    err = (int)cudaGetLastError();
+  }
+  if (tmp != nullptr)
+    cudaFreeAsync(tmp, stream);
+
+  // the kernels of icicle, on the legacy default stream, read h after the quotient
+  if (err == 0)
+    err = (int)cudaEventRecord(done, stream);
+  if (err == 0)
+    err = (int)cudaStreamWaitEvent(cudaStreamLegacy, done, 0);
+
+  if (err == 0)
+    *h = out;
+  else if (out != nullptr)
+    cudaFreeAsync(out, stream);
+
+  // the stream and the events are released once their work completes
+  if (ready != nullptr)
+    cudaEventDestroy(ready);
+  if (done != nullptr)
+    cudaEventDestroy(done);
+  cudaStreamDestroy(stream);
+  return err;
+}
+
+typedef field<bn254_fr_params> bn254_fr;
+typedef field<bls12377_fr_params> bls12377_fr;
+
+extern "C" int bn254_quotient(void **h, void *a, void *b, void *c, const void *twiddles, const void *twiddles_inv,
+                              const void *coset, const void *coset_inv, const void *den, size_t n,
+                              const uint64_t n_inv[4]) {
+  return quotient<bn254_fr>(h, a, b, c, twiddles, twiddles_inv, coset, coset_inv, den, n, n_inv);
+}
+
+extern "C" int bls12377_quotient(void **h, void *a, void *b, void *c, const void *twiddles,
+                                 const void *twiddles_inv, const void *coset, const void *coset_inv, const void *den,
+                                 size_t n, const uint64_t n_inv[4]) {
+  return quotient<bls12377_fr>(h, a, b, c, twiddles, twiddles_inv, coset, coset_inv, den, n, n_inv);
+}
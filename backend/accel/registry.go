@@ -0,0 +1,111 @@
+package accel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// EnvVar is the environment variable selecting the provider by name, when several are
+// registered for a curve.
+const EnvVar = "GNARK_ACCEL"
+
+// ErrNoDevice is returned by Open when no provider is registered for the curve.
+var ErrNoDevice = errors.New("no accelerator provider for the curve")
+
+// Provider opens the devices of an accelerator runtime.
+type Provider struct {
+	// Name identifies the provider, for instance "icicle".
+	Name string
+
+	// Curves lists the curves the provider supports.
+	Curves []ecc.ID
+
+	// Open returns the device of the provider for the curve, one of Curves.
+	Open func(curve ecc.ID) (Device, error)
+
+	// Fallback providers, such as the CPU emulation, are only opened by default if no other
+	// provider supports the curve.
+	Fallback bool
+}
+
+var (
+	providers     []Provider
+	providersLock sync.RWMutex
+)
+
+// Register registers a provider, usually from the init function of its package. The providers
+// are tried in the order of registration.
+func Register(p Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	for i := range providers {
+		if providers[i].Name == p.Name {
+			providers[i] = p
+			return
+		}
+	}
+	providers = append(providers, p)
+}
+
+// Providers returns the names of the providers registered for the curve.
+func Providers(curve ecc.ID) []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	var names []string
+	for _, p := range providers {
+		if p.supports(curve) {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// Open returns a device for the curve, of the provider named by the environment variable
+// GNARK_ACCEL if set, or else of the first registered provider supporting the curve, the
+// fallback providers last.
+func Open(curve ecc.ID) (Device, error) {
+	return OpenProvider(os.Getenv(EnvVar), curve)
+}
+
+// OpenProvider returns a device of the named provider for the curve, or of the first registered
+// provider supporting the curve if name is empty, the fallback providers last.
+func OpenProvider(name string, curve ecc.ID) (Device, error) {
+	p, err := Lookup(name, curve)
+	if err != nil {
+		return nil, err
+	}
+	return p.Open(curve)
+}
+
+// Lookup returns the provider OpenProvider opens for the name and the curve.
+func Lookup(name string, curve ecc.ID) (Provider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	for _, fallback := range []bool{false, true} {
+		for _, p := range providers {
+			if name == "" && p.Fallback != fallback {
+				continue
+			}
+			if (name == "" || p.Name == name) && p.supports(curve) {
+				return p, nil
+			}
+		}
+	}
+	if name != "" {
+		return Provider{}, fmt.Errorf("%w: %s on %s", ErrNoDevice, name, curve)
+	}
+	return Provider{}, fmt.Errorf("%w: %s", ErrNoDevice, curve)
+}
+
+func (p *Provider) supports(curve ecc.ID) bool {
+	for _, c := range p.Curves {
+		if c == curve {
+			return true
+		}
+	}
+	return false
+}
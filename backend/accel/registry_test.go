@@ -0,0 +1,86 @@
+package accel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// namedDevice is a Device which only reports its name and curve.
+type namedDevice struct {
+	Device
+	name  string
+	curve ecc.ID
+}
+
+func (d namedDevice) Name() string  { return d.name }
+func (d namedDevice) Curve() ecc.ID { return d.curve }
+
+func testProvider(name string, curves ...ecc.ID) Provider {
+	return Provider{
+		Name:   name,
+		Curves: curves,
+		Open: func(curve ecc.ID) (Device, error) {
+			return namedDevice{name: name, curve: curve}, nil
+		},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	saved := providers
+	defer func() { providers = saved }()
+	providers = nil
+
+	if _, err := Open(ecc.BN254); !errors.Is(err, ErrNoDevice) {
+		t.Fatalf("expected ErrNoDevice, got %v", err)
+	}
+
+	Register(testProvider("first", ecc.BN254))
+	Register(testProvider("second", ecc.BN254, ecc.BLS12_377))
+
+	if names := Providers(ecc.BN254); len(names) != 2 || names[0] != "first" || names[1] != "second" {
+		t.Fatalf("unexpected providers %v", names)
+	}
+
+	// the fallback providers come last
+	fallback := testProvider("fallback", ecc.BN254, ecc.BW6_761)
+	fallback.Fallback = true
+	providers = append([]Provider{fallback}, providers...)
+
+	t.Setenv(EnvVar, "")
+	d, err := Open(ecc.BN254)
+	if err != nil || d.Name() != "first" {
+		t.Fatalf("expected the first provider, got %v, %v", d, err)
+	}
+	if d, err = Open(ecc.BLS12_377); err != nil || d.Name() != "second" {
+		t.Fatalf("expected the provider supporting the curve, got %v, %v", d, err)
+	}
+
+	t.Setenv(EnvVar, "second")
+	if d, err = Open(ecc.BN254); err != nil || d.Name() != "second" || d.Curve() != ecc.BN254 {
+		t.Fatalf("expected the provider of the environment variable, got %v, %v", d, err)
+	}
+	if _, err = OpenProvider("first", ecc.BLS12_377); !errors.Is(err, ErrNoDevice) {
+		t.Fatalf("expected ErrNoDevice, got %v", err)
+	}
+
+	t.Setenv(EnvVar, "")
+	if d, err = Open(ecc.BW6_761); err != nil || d.Name() != "fallback" {
+		t.Fatalf("expected the fallback provider, got %v, %v", d, err)
+	}
+	if p, err := Lookup("", ecc.BW6_761); err != nil || p.Name != "fallback" {
+		t.Fatalf("expected to look up the fallback provider, got %v, %v", p.Name, err)
+	}
+
+	// registering a provider with the same name replaces it
+	replaced := testProvider("first", ecc.BW6_761)
+	replaced.Open = func(ecc.ID) (Device, error) { return nil, errors.New("unavailable") }
+	Register(replaced)
+	if names := Providers(ecc.BN254); len(names) != 2 || names[0] != "fallback" || names[1] != "second" {
+		t.Fatalf("unexpected providers %v", names)
+	}
+	if _, err = OpenProvider("first", ecc.BW6_761); err == nil || errors.Is(err, ErrNoDevice) {
+		t.Fatalf("expected the error of the provider, got %v", err)
+	}
+}
@@ -0,0 +1,564 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/backend/accel/remote/internal/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Option configures the devices of Dial and Register.
+type Option func(*config)
+
+type config struct {
+	tls            *tls.Config
+	compression    bool
+	timeout        time.Duration
+	token          []byte
+	maxMessageSize int
+}
+
+// WithTLS connects to the server with TLS.
+func WithTLS(cfg *tls.Config) Option {
+	return func(c *config) {
+		c.tls = cfg
+	}
+}
+
+// WithToken authenticates the client to the servers requiring the token, see RequireToken.
+func WithToken(token []byte) Option {
+	return func(c *config) {
+		c.token = token
+	}
+}
+
+// WithMaxMessageSize bounds the messages of the connections, DefaultMaxMessageSize by default,
+// and at least MinMaxMessageSize. The messages are also bounded by the server.
+func WithMaxMessageSize(size int) Option {
+	return func(c *config) {
+		c.maxMessageSize = size
+	}
+}
+
+// WithoutCompression sends the scalars uncompressed, for the servers on a fast network.
+func WithoutCompression() Option {
+	return func(c *config) {
+		c.compression = false
+	}
+}
+
+// WithDialTimeout bounds the time to connect to the server and open the device, 10 seconds by
+// default.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// Register registers the provider Name in the accel registry, opening the devices of the server
+// at the address for the curves with a GPU prover. A device is opened, with its connection, by
+// each SetAccelerator of a proving key.
+func Register(address string, opts ...Option) {
+	accel.Register(accel.Provider{
+		Name:   Name,
+		Curves: curves(),
+		Open: func(curve ecc.ID) (accel.Device, error) {
+			return Dial(context.Background(), address, curve, opts...)
+		},
+	})
+}
+
+// Device is a device of a remote server, see Dial. Its buffers are on the host, and its kernels
+// run on the CPU but for the multi-scalar multiplications and the NTTs.
+type Device struct {
+	local   accel.Device
+	cfg     config
+	layout  layout
+	name    string
+	address string
+	chunk   int // the size of the chunks of the vectors, see chunkSize
+
+	conn   *grpc.ClientConn
+	rpc    pb.AcceleratorClient
+	ctx    context.Context // of the calls of the session, done when the device is closed
+	cancel context.CancelFunc
+
+	lock    sync.Mutex
+	sizes   map[unsafe.Pointer]int    // the sizes of the buffers
+	points  map[unsafe.Pointer]uint64 // the handles of the points on the server
+	mirrors map[unsafe.Pointer]mirror // the coset powers on the server
+}
+
+// mirror is a copy of a vector of the host on the server.
+type mirror struct {
+	handle uint64
+	size   int
+}
+
+var _ accel.Device = (*Device)(nil)
+
+// Dial connects to the server at the TCP address and opens a device for the curve, BN254 or
+// BLS12-377.
+func Dial(ctx context.Context, address string, curve ecc.ID, opts ...Option) (*Device, error) {
+	cfg := config{compression: true, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	l, ok := layouts[curve]
+	if !ok {
+		return nil, fmt.Errorf("the remote provider doesn't support %s", curve)
+	}
+	local, err := accel.OpenProvider(cpu.Name, curve)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.tls != nil {
+		creds = credentials.NewTLS(cfg.tls)
+	}
+	maxMessageSize := messageSize(cfg.maxMessageSize)
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize), grpc.MaxCallSendMsgSize(maxMessageSize)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", accel.ErrDevice, err)
+	}
+	d := &Device{
+		local:   local,
+		cfg:     cfg,
+		layout:  l,
+		address: address,
+		conn:    conn,
+		rpc:     pb.NewAcceleratorClient(conn),
+		sizes:   make(map[unsafe.Pointer]int),
+		points:  make(map[unsafe.Pointer]uint64),
+		mirrors: make(map[unsafe.Pointer]mirror),
+	}
+	// the session lasts as long as the Open stream
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	handshake := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(cfg.timeout)
+		defer timer.Stop()
+		select {
+		case <-handshake:
+			return
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		d.cancel()
+	}()
+	err = d.open(curve, maxMessageSize)
+	close(handshake)
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// open opens the session of the device on the server.
+func (d *Device) open(curve ecc.ID, maxMessageSize int) error {
+	stream, err := d.rpc.Open(d.ctx)
+	if err != nil {
+		return errorOf(err)
+	}
+	hello, err := stream.Recv()
+	if err != nil {
+		return errorOf(err)
+	}
+	if len(hello.Challenge) != challengeSize {
+		return fmt.Errorf("%w: invalid challenge", accel.ErrDevice)
+	}
+	if serverSize := hello.MaxMessageSize; serverSize < uint64(maxMessageSize) {
+		if serverSize < MinMaxMessageSize {
+			return fmt.Errorf("%w: the server messages of %d bytes are too small", accel.ErrDevice, serverSize)
+		}
+		maxMessageSize = int(serverSize)
+	}
+	d.chunk = chunkSize(maxMessageSize, d.layout.scalar)
+	if err = stream.Send(&pb.OpenRequest{Mac: tokenMAC(d.cfg.token, hello.Challenge), Curve: curve.String()}); err != nil {
+		return errorOf(err)
+	}
+	opened, err := stream.Recv()
+	if err != nil {
+		return errorOf(err)
+	}
+	if len(opened.Session) != challengeSize {
+		return fmt.Errorf("%w: invalid session", accel.ErrDevice)
+	}
+	d.name = opened.Device
+	d.ctx = metadata.AppendToOutgoingContext(d.ctx, sessionKey, string(opened.Session))
+	return nil
+}
+
+// Close closes the session and the connection to the server, which frees the buffers of the
+// device.
+func (d *Device) Close() error {
+	d.cancel()
+	return d.conn.Close()
+}
+
+func (d *Device) Name() string {
+	return fmt.Sprintf("%s (%s at %s)", Name, d.name, d.address)
+}
+
+func (d *Device) Curve() ecc.ID {
+	return d.local.Curve()
+}
+
+func (d *Device) Malloc(size int) (unsafe.Pointer, error) {
+	p, err := d.local.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	d.lock.Lock()
+	d.sizes[p] = size
+	d.lock.Unlock()
+	return p, nil
+}
+
+func (d *Device) Free(p unsafe.Pointer) error {
+	if p == nil {
+		return nil
+	}
+	d.lock.Lock()
+	size := d.sizes[p]
+	delete(d.sizes, p)
+	handle, isPoints := d.points[p]
+	delete(d.points, p)
+	d.lock.Unlock()
+	d.invalidate(p, size)
+	if isPoints {
+		d.free(handle)
+	}
+	return d.local.Free(p)
+}
+
+func (d *Device) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	d.invalidate(dst, size)
+	return d.local.CopyToDevice(dst, src, size)
+}
+
+func (d *Device) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	return d.local.CopyToHost(dst, src, size)
+}
+
+func (d *Device) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	d.invalidate(scalars, n*d.layout.scalar)
+	return d.local.FromMontgomery(scalars, n)
+}
+
+func (d *Device) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	d.invalidate(scalars, n*d.layout.scalar)
+	return d.local.ReverseScalars(scalars, n)
+}
+
+func (d *Device) VecMul(a, b unsafe.Pointer, n int) error {
+	d.invalidate(a, n*d.layout.scalar)
+	return d.local.VecMul(a, b, n)
+}
+
+func (d *Device) VecSub(a, b unsafe.Pointer, n int) error {
+	d.invalidate(a, n*d.layout.scalar)
+	return d.local.VecSub(a, b, n)
+}
+
+// Twiddles returns the twiddles of the CPU device; the server computes its own.
+func (d *Device) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	p, err := d.local.Twiddles(n, inverse)
+	if err != nil {
+		return nil, err
+	}
+	d.lock.Lock()
+	d.sizes[p] = (n + 1) / 2 * d.layout.scalar
+	d.lock.Unlock()
+	return p, nil
+}
+
+// Ntt sends the coefficients to the server, and copies the evaluations back.
+func (d *Device) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	size := n * d.layout.scalar
+	hIn, err := d.upload(in, size, true)
+	if err != nil {
+		return err
+	}
+	defer d.free(hIn)
+	hCoset, err := d.mirror(cosetPowers, size)
+	if err != nil {
+		return err
+	}
+	hOut, err := d.malloc(size, false)
+	if err != nil {
+		return err
+	}
+	defer d.free(hOut)
+	if _, err = d.rpc.Ntt(d.ctx, &pb.NttRequest{Out: hOut, In: hIn, CosetPowers: hCoset, N: uint64(n)}); err != nil {
+		return errorOf(err)
+	}
+	d.invalidate(out, size)
+	return d.download(out, hOut, size)
+}
+
+// Intt sends the evaluations to the server, and copies the coefficients back.
+func (d *Device) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	size := n * d.layout.scalar
+	hIn, err := d.upload(in, size, true)
+	if err != nil {
+		return nil, err
+	}
+	defer d.free(hIn)
+	hCoset, err := d.mirror(cosetPowers, size)
+	if err != nil {
+		return nil, err
+	}
+	res, err := d.rpc.Intt(d.ctx, &pb.NttRequest{In: hIn, CosetPowers: hCoset, N: uint64(n)})
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	hOut := res.Handle
+	defer d.free(hOut)
+
+	out, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.download(out, hOut, size); err != nil {
+		_ = d.Free(out)
+		return nil, err
+	}
+	return out, nil
+}
+
+// PointsG1ToDevice streams the points to the server. The returned pointer stands for the
+// points on the server: it can only be passed to Msm, and freed.
+func (d *Device) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	return d.pointsToDevice(points, n, false, cfg)
+}
+
+// PointsG2ToDevice is PointsG1ToDevice in G2, for MsmG2.
+func (d *Device) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	return d.pointsToDevice(points, n, true, cfg)
+}
+
+func (d *Device) pointsToDevice(points unsafe.Pointer, n int, g2 bool, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	size := n * d.layout.g1Affine
+	if g2 {
+		size = n * d.layout.g2Affine
+	}
+	hHost, err := d.malloc(size, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.write(hHost, points, size, false); err != nil {
+		d.free(hHost)
+		return nil, err
+	}
+	// the server frees the host buffer
+	res, err := d.rpc.Points(d.ctx, &pb.PointsRequest{Host: hHost, N: uint64(n), G2: g2, Representation: uint32(cfg.Representation), Infinity: cfg.WithInfinity})
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	handle := res.Handle
+
+	// a host buffer stands for the points
+	p, err := d.local.Malloc(1)
+	if err != nil {
+		d.free(handle)
+		return nil, err
+	}
+	d.lock.Lock()
+	d.points[p] = handle
+	d.lock.Unlock()
+	return p, nil
+}
+
+// Msm sends the scalars to the server, and receives the result.
+func (d *Device) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	return d.msm(res, scalars, points, n, false, cfg)
+}
+
+// MsmG2 is Msm in G2.
+func (d *Device) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	return d.msm(res, scalars, points, n, true, cfg)
+}
+
+func (d *Device) msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, g2 bool, cfg accel.MsmConfig) error {
+	if cfg.NbTasks != 0 {
+		return fmt.Errorf("%w: the MSMs run on the server, not on %d CPU tasks", accel.ErrUnsupported, cfg.NbTasks)
+	}
+	d.lock.Lock()
+	hPoints, ok := d.points[points]
+	d.lock.Unlock()
+	if !ok {
+		return errors.New("the points aren't on the remote device")
+	}
+	hScalars, err := d.upload(scalars, n*d.layout.scalar, true)
+	if err != nil {
+		return err
+	}
+	defer d.free(hScalars)
+	result, err := d.rpc.Msm(d.ctx, &pb.MsmRequest{Scalars: hScalars, Points: hPoints, N: uint64(n), BucketFactor: uint64(cfg.BucketFactor), G2: g2})
+	if err != nil {
+		return errorOf(err)
+	}
+	size := d.layout.g1Jac
+	if g2 {
+		size = d.layout.g2Jac
+	}
+	if len(result.Data) != size {
+		return fmt.Errorf("%w: result of %d bytes, expected %d", accel.ErrDevice, len(result.Data), size)
+	}
+	copy(bytesOf(res, size), result.Data)
+	return nil
+}
+
+// malloc allocates a buffer on the server.
+func (d *Device) malloc(size int, host bool) (uint64, error) {
+	res, err := d.rpc.Malloc(d.ctx, &pb.MallocRequest{Size: uint64(size), Host: host})
+	if err != nil {
+		return 0, errorOf(err)
+	}
+	return res.Handle, nil
+}
+
+// free frees the buffer of the server, without waiting: the failures are the ones of the
+// connection, which frees the buffers of the session when it closes.
+func (d *Device) free(h uint64) {
+	go func() {
+		_, _ = d.rpc.Free(d.ctx, &pb.Buffer{Handle: h})
+	}()
+}
+
+// upload copies the size bytes at p to a new buffer of the server.
+func (d *Device) upload(p unsafe.Pointer, size int, scalars bool) (uint64, error) {
+	h, err := d.malloc(size, false)
+	if err != nil {
+		return 0, err
+	}
+	if err = d.write(h, p, size, scalars); err != nil {
+		d.free(h)
+		return 0, err
+	}
+	return h, nil
+}
+
+// write streams the size bytes at p to the buffer of the server, in chunks. The scalars are
+// compressed.
+func (d *Device) write(h uint64, p unsafe.Pointer, size int, scalars bool) error {
+	data := bytesOf(p, size)
+	stream, err := d.rpc.Write(d.ctx)
+	if err != nil {
+		return errorOf(err)
+	}
+	for offset := 0; offset < size; offset += d.chunk {
+		req := &pb.WriteRequest{Handle: h, Offset: uint64(offset), Data: data[offset : offset+d.chunkLen(offset, size)]}
+		if scalars && d.cfg.compression {
+			req.Encoding, req.Data = pb.Encoding_COMPACT, compactScalars(nil, req.Data, d.layout.scalar)
+		}
+		if err = stream.Send(req); err != nil {
+			// the error is the one of the stream, returned by CloseAndRecv
+			break
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	return errorOf(err)
+}
+
+// download copies the buffer of the server to the size bytes at p.
+func (d *Device) download(p unsafe.Pointer, h uint64, size int) error {
+	data := bytesOf(p, size)
+	stream, err := d.rpc.Read(d.ctx, &pb.ReadRequest{Handle: h, Size: uint64(size), Chunk: uint64(d.chunk)})
+	if err != nil {
+		return errorOf(err)
+	}
+	offset := 0
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errorOf(err)
+		}
+		if len(chunk.Data) > size-offset {
+			return fmt.Errorf("%w: read beyond the buffer", accel.ErrDevice)
+		}
+		offset += copy(data[offset:], chunk.Data)
+	}
+	if offset != size {
+		return fmt.Errorf("%w: truncated read", accel.ErrDevice)
+	}
+	return nil
+}
+
+// chunkLen returns the size of the chunk at the offset of a vector of size bytes.
+func (d *Device) chunkLen(offset, size int) int {
+	if size-offset < d.chunk {
+		return size - offset
+	}
+	return d.chunk
+}
+
+// mirror returns the handle of the copy on the server of the size bytes at p, copied once until
+// they are written, or 0 if p is nil.
+func (d *Device) mirror(p unsafe.Pointer, size int) (uint64, error) {
+	if p == nil {
+		return 0, nil
+	}
+	d.lock.Lock()
+	m, ok := d.mirrors[p]
+	d.lock.Unlock()
+	if ok && m.size == size {
+		return m.handle, nil
+	}
+	h, err := d.upload(p, size, true)
+	if err != nil {
+		return 0, err
+	}
+	// the concurrent calls may have copied the vector meanwhile
+	d.lock.Lock()
+	if m, ok := d.mirrors[p]; ok && m.size == size {
+		d.lock.Unlock()
+		d.free(h)
+		return m.handle, nil
+	}
+	previous, ok := d.mirrors[p]
+	d.mirrors[p] = mirror{handle: h, size: size}
+	d.lock.Unlock()
+	if ok {
+		d.free(previous.handle)
+	}
+	return h, nil
+}
+
+// invalidate drops the mirrors of the vectors overlapping the size bytes at p, before they are
+// written.
+func (d *Device) invalidate(p unsafe.Pointer, size int) {
+	start, end := uintptr(p), uintptr(p)+uintptr(size)
+	d.lock.Lock()
+	var handles []uint64
+	for q, m := range d.mirrors {
+		if uintptr(q) < end && start < uintptr(q)+uintptr(m.size) {
+			handles = append(handles, m.handle)
+			delete(d.mirrors, q)
+		}
+	}
+	d.lock.Unlock()
+	for _, h := range handles {
+		d.free(h)
+	}
+}
@@ -0,0 +1,37 @@
+module github.com/consensys/gnark/backend/accel/remote
+
+go 1.19
+
+require (
+	github.com/consensys/gnark v0.8.1
+	github.com/consensys/gnark-crypto v0.11.0
+	google.golang.org/grpc v1.58.3
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/bits-and-blooms/bitset v1.5.0 // indirect
+	github.com/blang/semver/v4 v4.0.0 // indirect
+	github.com/consensys/bavard v0.1.13 // indirect
+	github.com/fxamacker/cbor/v2 v2.4.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/pprof v0.0.0-20230309165930-d61513b1440d // indirect
+	github.com/ingonyama-zk/icicle v0.0.0-20230831061944-5667f32bfedd // indirect
+	github.com/ingonyama-zk/iciclegnark v0.0.0-20230901124553-e5b9a843dede // indirect
+	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/mattn/go-isatty v0.0.14 // indirect
+	github.com/mmcloughlin/addchain v0.4.0 // indirect
+	github.com/rs/zerolog v1.29.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+	rsc.io/tmplfunc v0.0.3 // indirect
+)
+
+// the package is developed with the gnark module of the repository
+replace github.com/consensys/gnark => ../../..
+
+// the gnark module builds on the gnark-crypto fork
+replace github.com/consensys/gnark-crypto => github.com/celer-network/gnark-crypto v0.0.0-20230423085214-c00cabca6125
@@ -0,0 +1,528 @@
+// The remote accelerator protocol of the backend/accel/remote package.
+//
+// The scalars and points are in the layout of the host memory of gnark-crypto, see the accel
+// package.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: remote.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Accelerator_Open_FullMethodName   = "/gnark.accel.remote.Accelerator/Open"
+	Accelerator_Malloc_FullMethodName = "/gnark.accel.remote.Accelerator/Malloc"
+	Accelerator_Free_FullMethodName   = "/gnark.accel.remote.Accelerator/Free"
+	Accelerator_Write_FullMethodName  = "/gnark.accel.remote.Accelerator/Write"
+	Accelerator_Read_FullMethodName   = "/gnark.accel.remote.Accelerator/Read"
+	Accelerator_Points_FullMethodName = "/gnark.accel.remote.Accelerator/Points"
+	Accelerator_Msm_FullMethodName    = "/gnark.accel.remote.Accelerator/Msm"
+	Accelerator_Ntt_FullMethodName    = "/gnark.accel.remote.Accelerator/Ntt"
+	Accelerator_Intt_FullMethodName   = "/gnark.accel.remote.Accelerator/Intt"
+)
+
+// AcceleratorClient is the client API for Accelerator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AcceleratorClient interface {
+	// Open opens a device of the server. The server sends a challenge, the client the HMAC of the
+	// challenge with its token and the curve, and the server the ID of the session of the device.
+	// The session lasts as long as the stream: its buffers are freed when the stream ends. The
+	// other calls carry the ID of the session in the "session" metadata.
+	Open(ctx context.Context, opts ...grpc.CallOption) (Accelerator_OpenClient, error)
+	// Malloc allocates a buffer on the device, or on the host of the server for the points
+	// copied to the device by Points.
+	Malloc(ctx context.Context, in *MallocRequest, opts ...grpc.CallOption) (*Buffer, error)
+	Free(ctx context.Context, in *Buffer, opts ...grpc.CallOption) (*Empty, error)
+	// Write streams data to a buffer, in chunks. Read streams a range of a buffer back.
+	Write(ctx context.Context, opts ...grpc.CallOption) (Accelerator_WriteClient, error)
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (Accelerator_ReadClient, error)
+	// Points copies the points of a host buffer to the device, and frees the host buffer.
+	Points(ctx context.Context, in *PointsRequest, opts ...grpc.CallOption) (*Buffer, error)
+	// Msm returns the multi-scalar multiplication of the scalars and points of device buffers,
+	// as a Jacobian point.
+	Msm(ctx context.Context, in *MsmRequest, opts ...grpc.CallOption) (*Chunk, error)
+	// Ntt sets out to the evaluations of the coefficients of in, and Intt returns a new buffer
+	// of the coefficients of the evaluations of in.
+	Ntt(ctx context.Context, in *NttRequest, opts ...grpc.CallOption) (*Empty, error)
+	Intt(ctx context.Context, in *NttRequest, opts ...grpc.CallOption) (*Buffer, error)
+}
+
+type acceleratorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAcceleratorClient(cc grpc.ClientConnInterface) AcceleratorClient {
+	return &acceleratorClient{cc}
+}
+
+func (c *acceleratorClient) Open(ctx context.Context, opts ...grpc.CallOption) (Accelerator_OpenClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Accelerator_ServiceDesc.Streams[0], Accelerator_Open_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &acceleratorOpenClient{stream}
+	return x, nil
+}
+
+type Accelerator_OpenClient interface {
+	Send(*OpenRequest) error
+	Recv() (*OpenResponse, error)
+	grpc.ClientStream
+}
+
+type acceleratorOpenClient struct {
+	grpc.ClientStream
+}
+
+func (x *acceleratorOpenClient) Send(m *OpenRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *acceleratorOpenClient) Recv() (*OpenResponse, error) {
+	m := new(OpenResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *acceleratorClient) Malloc(ctx context.Context, in *MallocRequest, opts ...grpc.CallOption) (*Buffer, error) {
+	out := new(Buffer)
+	err := c.cc.Invoke(ctx, Accelerator_Malloc_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *acceleratorClient) Free(ctx context.Context, in *Buffer, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Accelerator_Free_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *acceleratorClient) Write(ctx context.Context, opts ...grpc.CallOption) (Accelerator_WriteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Accelerator_ServiceDesc.Streams[1], Accelerator_Write_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &acceleratorWriteClient{stream}
+	return x, nil
+}
+
+type Accelerator_WriteClient interface {
+	Send(*WriteRequest) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type acceleratorWriteClient struct {
+	grpc.ClientStream
+}
+
+func (x *acceleratorWriteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *acceleratorWriteClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *acceleratorClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (Accelerator_ReadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Accelerator_ServiceDesc.Streams[2], Accelerator_Read_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &acceleratorReadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Accelerator_ReadClient interface {
+	Recv() (*Chunk, error)
+	grpc.ClientStream
+}
+
+type acceleratorReadClient struct {
+	grpc.ClientStream
+}
+
+func (x *acceleratorReadClient) Recv() (*Chunk, error) {
+	m := new(Chunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *acceleratorClient) Points(ctx context.Context, in *PointsRequest, opts ...grpc.CallOption) (*Buffer, error) {
+	out := new(Buffer)
+	err := c.cc.Invoke(ctx, Accelerator_Points_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *acceleratorClient) Msm(ctx context.Context, in *MsmRequest, opts ...grpc.CallOption) (*Chunk, error) {
+	out := new(Chunk)
+	err := c.cc.Invoke(ctx, Accelerator_Msm_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *acceleratorClient) Ntt(ctx context.Context, in *NttRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Accelerator_Ntt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *acceleratorClient) Intt(ctx context.Context, in *NttRequest, opts ...grpc.CallOption) (*Buffer, error) {
+	out := new(Buffer)
+	err := c.cc.Invoke(ctx, Accelerator_Intt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AcceleratorServer is the server API for Accelerator service.
+// All implementations must embed UnimplementedAcceleratorServer
+// for forward compatibility
+type AcceleratorServer interface {
+	// Open opens a device of the server. The server sends a challenge, the client the HMAC of the
+	// challenge with its token and the curve, and the server the ID of the session of the device.
+	// The session lasts as long as the stream: its buffers are freed when the stream ends. The
+	// other calls carry the ID of the session in the "session" metadata.
+	Open(Accelerator_OpenServer) error
+	// Malloc allocates a buffer on the device, or on the host of the server for the points
+	// copied to the device by Points.
+	Malloc(context.Context, *MallocRequest) (*Buffer, error)
+	Free(context.Context, *Buffer) (*Empty, error)
+	// Write streams data to a buffer, in chunks. Read streams a range of a buffer back.
+	Write(Accelerator_WriteServer) error
+	Read(*ReadRequest, Accelerator_ReadServer) error
+	// Points copies the points of a host buffer to the device, and frees the host buffer.
+	Points(context.Context, *PointsRequest) (*Buffer, error)
+	// Msm returns the multi-scalar multiplication of the scalars and points of device buffers,
+	// as a Jacobian point.
+	Msm(context.Context, *MsmRequest) (*Chunk, error)
+	// Ntt sets out to the evaluations of the coefficients of in, and Intt returns a new buffer
+	// of the coefficients of the evaluations of in.
+	Ntt(context.Context, *NttRequest) (*Empty, error)
+	Intt(context.Context, *NttRequest) (*Buffer, error)
+	mustEmbedUnimplementedAcceleratorServer()
+}
+
+// UnimplementedAcceleratorServer must be embedded to have forward compatible implementations.
+type UnimplementedAcceleratorServer struct {
+}
+
+func (UnimplementedAcceleratorServer) Open(Accelerator_OpenServer) error {
+	return status.Errorf(codes.Unimplemented, "method Open not implemented")
+}
+func (UnimplementedAcceleratorServer) Malloc(context.Context, *MallocRequest) (*Buffer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Malloc not implemented")
+}
+func (UnimplementedAcceleratorServer) Free(context.Context, *Buffer) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Free not implemented")
+}
+func (UnimplementedAcceleratorServer) Write(Accelerator_WriteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+func (UnimplementedAcceleratorServer) Read(*ReadRequest, Accelerator_ReadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedAcceleratorServer) Points(context.Context, *PointsRequest) (*Buffer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Points not implemented")
+}
+func (UnimplementedAcceleratorServer) Msm(context.Context, *MsmRequest) (*Chunk, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Msm not implemented")
+}
+func (UnimplementedAcceleratorServer) Ntt(context.Context, *NttRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ntt not implemented")
+}
+func (UnimplementedAcceleratorServer) Intt(context.Context, *NttRequest) (*Buffer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Intt not implemented")
+}
+func (UnimplementedAcceleratorServer) mustEmbedUnimplementedAcceleratorServer() {}
+
+// UnsafeAcceleratorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AcceleratorServer will
+// result in compilation errors.
+type UnsafeAcceleratorServer interface {
+	mustEmbedUnimplementedAcceleratorServer()
+}
+
+func RegisterAcceleratorServer(s grpc.ServiceRegistrar, srv AcceleratorServer) {
+	s.RegisterService(&Accelerator_ServiceDesc, srv)
+}
+
+func _Accelerator_Open_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AcceleratorServer).Open(&acceleratorOpenServer{stream})
+}
+
+type Accelerator_OpenServer interface {
+	Send(*OpenResponse) error
+	Recv() (*OpenRequest, error)
+	grpc.ServerStream
+}
+
+type acceleratorOpenServer struct {
+	grpc.ServerStream
+}
+
+func (x *acceleratorOpenServer) Send(m *OpenResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *acceleratorOpenServer) Recv() (*OpenRequest, error) {
+	m := new(OpenRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Accelerator_Malloc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MallocRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Malloc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Malloc_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Malloc(ctx, req.(*MallocRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accelerator_Free_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Buffer)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Free(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Free_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Free(ctx, req.(*Buffer))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accelerator_Write_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AcceleratorServer).Write(&acceleratorWriteServer{stream})
+}
+
+type Accelerator_WriteServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type acceleratorWriteServer struct {
+	grpc.ServerStream
+}
+
+func (x *acceleratorWriteServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *acceleratorWriteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Accelerator_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AcceleratorServer).Read(m, &acceleratorReadServer{stream})
+}
+
+type Accelerator_ReadServer interface {
+	Send(*Chunk) error
+	grpc.ServerStream
+}
+
+type acceleratorReadServer struct {
+	grpc.ServerStream
+}
+
+func (x *acceleratorReadServer) Send(m *Chunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Accelerator_Points_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Points(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Points_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Points(ctx, req.(*PointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accelerator_Msm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Msm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Msm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Msm(ctx, req.(*MsmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accelerator_Ntt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NttRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Ntt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Ntt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Ntt(ctx, req.(*NttRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accelerator_Intt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NttRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AcceleratorServer).Intt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Accelerator_Intt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AcceleratorServer).Intt(ctx, req.(*NttRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Accelerator_ServiceDesc is the grpc.ServiceDesc for Accelerator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Accelerator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gnark.accel.remote.Accelerator",
+	HandlerType: (*AcceleratorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Malloc",
+			Handler:    _Accelerator_Malloc_Handler,
+		},
+		{
+			MethodName: "Free",
+			Handler:    _Accelerator_Free_Handler,
+		},
+		{
+			MethodName: "Points",
+			Handler:    _Accelerator_Points_Handler,
+		},
+		{
+			MethodName: "Msm",
+			Handler:    _Accelerator_Msm_Handler,
+		},
+		{
+			MethodName: "Ntt",
+			Handler:    _Accelerator_Ntt_Handler,
+		},
+		{
+			MethodName: "Intt",
+			Handler:    _Accelerator_Intt_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Open",
+			Handler:       _Accelerator_Open_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Write",
+			Handler:       _Accelerator_Write_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Read",
+			Handler:       _Accelerator_Read_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "remote.proto",
+}
@@ -0,0 +1,243 @@
+// Package remote offloads the multi-scalar multiplications and the NTTs of the provers to the
+// accelerators of another machine, so that the nodes without a GPU, which solve the witnesses,
+// borrow the devices of a shared GPU pool.
+//
+// A Server serves the devices of a provider of the accel registry over gRPC. Dial returns a
+// device of a server: an accel.Device keeping its buffers in the memory of the host, running
+// the cheap kernels (FromMontgomery, VecMul, VecSub...) on the CPU, and shipping the scalars of
+// the Msm, MsmG2, Ntt and Intt calls to the server, which returns their results. The points of
+// the proving keys are streamed once, by PointsG1ToDevice and PointsG2ToDevice, and stay on the
+// server until they are freed; so do the coset powers of the NTTs, until they are written.
+// Register adds the devices of a server to the accel registry, so that the GPU provers use them
+// with backend.WithAccelerator(remote.Name) or GNARK_ACCEL=remote.
+//
+// The protocol is the Accelerator service of internal/pb/remote.proto. A device is a session
+// of the server, opened by the Open stream: the server sends a random challenge and its maximum
+// message size, the client proves that it knows the token of the server (see RequireToken and
+// WithToken) with the HMAC-SHA256 of the challenge, and the server returns the random ID of the
+// session, which the other calls carry in their metadata. The client then allocates buffers on
+// the server, streams the vectors to them in chunks, runs the kernels on them and streams their
+// results back. The buffers of a session are freed when its Open stream ends, by Close or when
+// the connection drops.
+//
+// The messages are bounded by the smallest of the maximum message sizes of the client and of
+// the server (see WithMaxMessageSize), 1 MiB by default, so that a peer can't make the other
+// allocate large buffers. The token authenticates the clients but doesn't encrypt the
+// connection: the servers outside of a trusted network are served over TLS (see WithServerTLS
+// and WithTLS), and may require client certificates as well.
+//
+// The chunks of a vector are sent on one stream without waiting for each other, which gRPC
+// batches on the connection, and the chunks of scalars are compressed: the witnesses have many
+// zero or small values, sent with their significant bytes only.
+//
+// The scalars and points are sent in the layout of the host memory (see the accel package), so
+// that the clients and the servers must have the same byte order.
+//
+// The package is a module of its own, so that the gnark module doesn't depend on gRPC.
+package remote
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	curve_bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Name is the name of the provider in the accel registry, see Register.
+const Name = "remote"
+
+const (
+	// DefaultMaxMessageSize is the default bound of the messages, see WithMaxMessageSize and
+	// WithServerMaxMessageSize.
+	DefaultMaxMessageSize = 1 << 20
+
+	// MinMaxMessageSize is the smallest bound of the messages: the smaller ones are raised to
+	// it.
+	MinMaxMessageSize = 64 << 10
+
+	// chunkOverhead bounds the size of the fields of a WriteRequest or a Chunk but their data.
+	chunkOverhead = 64
+
+	// challengeSize is the size of the challenge of Open, and of the IDs of the sessions.
+	challengeSize = 32
+
+	// handshakeTimeout bounds the time of a client to authenticate.
+	handshakeTimeout = 10 * time.Second
+
+	// maxHostSize bounds the buffers of the points allocated on the host of the server.
+	maxHostSize = 1 << 36
+
+	// maxPending bounds the calls of a connection the server handles concurrently.
+	maxPending = 16
+
+	// sessionKey is the metadata of the calls carrying the ID of their session.
+	sessionKey = "gnark-session-bin"
+)
+
+// ErrUnauthenticated is returned by Dial when the server rejects the token of the client.
+var ErrUnauthenticated = errors.New("remote accelerator authentication failed")
+
+// messageSize returns the bound of the messages of a peer configured with size, see
+// WithMaxMessageSize.
+func messageSize(size int) int {
+	switch {
+	case size == 0:
+		return DefaultMaxMessageSize
+	case size < MinMaxMessageSize:
+		return MinMaxMessageSize
+	case int64(size) > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return size
+}
+
+// chunkSize returns the size of the data of the WriteRequest and Chunk messages, before
+// compression, for messages of messageSize bytes: the largest multiple of the size of the
+// scalars whose compact encoding, of one more byte per scalar, fits in a message with the
+// other fields.
+func chunkSize(messageSize, scalarSize int) int {
+	return (messageSize - chunkOverhead) / (scalarSize + 1) * scalarSize
+}
+
+// tokenMAC returns the proof of the knowledge of the token for the challenge.
+func tokenMAC(token, challenge []byte) []byte {
+	mac := hmac.New(sha256.New, token)
+	mac.Write(challenge)
+	return mac.Sum(nil)
+}
+
+// statusOf returns the gRPC status of an error of the server.
+func statusOf(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, accel.ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, accel.ErrDevice):
+		return status.Error(codes.Internal, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// errorOf returns the error of a gRPC status returned to the client: ErrUnauthenticated,
+// accel.ErrUnsupported, or else accel.ErrDevice.
+func errorOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, _ := status.FromError(err)
+	switch st.Code() {
+	case codes.Unimplemented:
+		return fmt.Errorf("%w: %s", accel.ErrUnsupported, st.Message())
+	case codes.Unauthenticated:
+		return fmt.Errorf("%w: %s", ErrUnauthenticated, st.Message())
+	default:
+		return fmt.Errorf("%w: %s", accel.ErrDevice, st.Message())
+	}
+}
+
+// layout is the size in bytes of the scalars and points of a curve, in the host memory.
+type layout struct {
+	scalar             int
+	g1Affine, g2Affine int
+	g1Jac, g2Jac       int
+}
+
+// layouts are the ones of the curves with a GPU prover.
+var layouts = map[ecc.ID]layout{
+	ecc.BN254: {
+		scalar:   fr_bn254.Bytes,
+		g1Affine: int(unsafe.Sizeof(curve_bn254.G1Affine{})),
+		g2Affine: int(unsafe.Sizeof(curve_bn254.G2Affine{})),
+		g1Jac:    int(unsafe.Sizeof(curve_bn254.G1Jac{})),
+		g2Jac:    int(unsafe.Sizeof(curve_bn254.G2Jac{})),
+	},
+	ecc.BLS12_377: {
+		scalar:   fr_bls12377.Bytes,
+		g1Affine: int(unsafe.Sizeof(curve_bls12377.G1Affine{})),
+		g2Affine: int(unsafe.Sizeof(curve_bls12377.G2Affine{})),
+		g1Jac:    int(unsafe.Sizeof(curve_bls12377.G1Jac{})),
+		g2Jac:    int(unsafe.Sizeof(curve_bls12377.G2Jac{})),
+	},
+}
+
+// curves returns the curves of the layouts.
+func curves() []ecc.ID {
+	return []ecc.ID{ecc.BN254, ecc.BLS12_377}
+}
+
+func parseCurve(name string) (ecc.ID, error) {
+	for _, c := range curves() {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unsupported curve %q", name)
+}
+
+// compactScalars appends to dst the scalars of data, each of size bytes in little-endian
+// words, as the number of their bytes below the high zero bytes, followed by these bytes.
+func compactScalars(dst, data []byte, size int) []byte {
+	for i := 0; i < len(data); i += size {
+		s := data[i : i+size]
+		l := size
+		for l > 0 && s[l-1] == 0 {
+			l--
+		}
+		dst = append(dst, byte(l))
+		dst = append(dst, s[:l]...)
+	}
+	return dst
+}
+
+// expandScalars decodes the scalars of size bytes encoded by compactScalars.
+func expandScalars(data []byte, size int) ([]byte, error) {
+	var res []byte
+	for len(data) > 0 {
+		l := int(data[0])
+		if l > size || len(data) < 1+l {
+			return nil, errors.New("invalid compact scalars")
+		}
+		res = append(res, data[1:1+l]...)
+		res = append(res, make([]byte, size-l)...)
+		data = data[1+l:]
+	}
+	return res, nil
+}
+
+// bytesOf returns the size bytes at p.
+func bytesOf(p unsafe.Pointer, size int) []byte {
+	if size == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(p), size)
+}
+
+// alignedBytes returns a buffer of size bytes aligned for the field elements, of non-zero
+// capacity.
+func alignedBytes(size int) []byte {
+	words := make([]uint64, size/8+1)
+	return unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(words)*8)[:size]
+}
+
+// pointerOf returns the address of the buffer, of non-zero capacity.
+func pointerOf(b []byte) unsafe.Pointer {
+	return unsafe.Pointer(&b[:1][0])
+}
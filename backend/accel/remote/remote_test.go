@@ -0,0 +1,397 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/backend/accel/remote/internal/pb"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serve serves the CPU devices on a loopback listener, and returns its address.
+func serve(t *testing.T, opts ...ServerOption) (*Server, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(cpu.Name, opts...)
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return s, l.Addr().String()
+}
+
+func dial(t *testing.T, address string, opts ...Option) *Device {
+	d, err := Dial(context.Background(), address, ecc.BN254, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+	return d
+}
+
+// scalarsToDevice copies the scalars to the device in regular form.
+func scalarsToDevice(t *testing.T, d accel.Device, scalars []fr.Element) unsafe.Pointer {
+	size := len(scalars) * fr.Bytes
+	p, err := d.Malloc(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = d.CopyToDevice(p, unsafe.Pointer(&scalars[0]), size); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.FromMontgomery(p, len(scalars)); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMsm(t *testing.T) {
+	_, address := serve(t)
+	for _, opts := range [][]Option{nil, {WithoutCompression()}} {
+		d := dial(t, address, opts...)
+		const n = 1000
+
+		// small scalars, compressed
+		scalars := make([]fr.Element, n)
+		for i := range scalars {
+			if i%2 == 0 {
+				scalars[i].SetRandom()
+			} else {
+				scalars[i].SetUint64(uint64(i % 3))
+			}
+		}
+		_, _, g1, g2 := curve.Generators()
+		pointsG1 := curve.BatchScalarMultiplicationG1(&g1, scalars)
+		pointsG2 := curve.BatchScalarMultiplicationG2(&g2, scalars)
+
+		scalars_d := scalarsToDevice(t, d, scalars)
+		defer d.Free(scalars_d)
+		cfg := accel.PointsConfig{Representation: accel.Projective}
+		pointsG1_d, err := d.PointsG1ToDevice(unsafe.Pointer(&pointsG1[0]), n, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Free(pointsG1_d)
+		pointsG2_d, err := d.PointsG2ToDevice(unsafe.Pointer(&pointsG2[0]), n, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Free(pointsG2_d)
+
+		var gotG1, expectedG1 curve.G1Jac
+		if err = d.Msm(unsafe.Pointer(&gotG1), scalars_d, pointsG1_d, n, accel.MsmConfig{Points: cfg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = expectedG1.MultiExp(pointsG1, scalars, ecc.MultiExpConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		if !gotG1.Equal(&expectedG1) {
+			t.Fatal("wrong MSM in G1")
+		}
+		var gotG2, expectedG2 curve.G2Jac
+		if err = d.MsmG2(unsafe.Pointer(&gotG2), scalars_d, pointsG2_d, n, accel.MsmConfig{Points: cfg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = expectedG2.MultiExp(pointsG2, scalars, ecc.MultiExpConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		if !gotG2.Equal(&expectedG2) {
+			t.Fatal("wrong MSM in G2")
+		}
+
+		// the points of G1 aren't in G2
+		if err = d.MsmG2(unsafe.Pointer(&gotG2), scalars_d, pointsG1_d, n, accel.MsmConfig{Points: cfg}); err == nil {
+			t.Fatal("MSM in G2 of points in G1")
+		}
+		if err = d.Msm(unsafe.Pointer(&gotG1), scalars_d, scalars_d, n, accel.MsmConfig{Points: cfg}); err == nil {
+			t.Fatal("MSM of points not on the remote device")
+		}
+	}
+}
+
+func TestNtt(t *testing.T) {
+	_, address := serve(t)
+	d := dial(t, address)
+	const n = 1 << 10
+	domain := fft.NewDomain(n)
+
+	coefficients := make([]fr.Element, n)
+	for i := range coefficients {
+		coefficients[i].SetRandom()
+	}
+	twiddles, err := d.Twiddles(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddles)
+	twiddlesInv, err := d.Twiddles(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddlesInv)
+	coset := scalarsToDevice(t, d, domain.CosetTable)
+	defer d.Free(coset)
+	cosetInv := scalarsToDevice(t, d, domain.CosetTableInv)
+	defer d.Free(cosetInv)
+	in := scalarsToDevice(t, d, coefficients)
+	defer d.Free(in)
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(out)
+	got := make([]fr.Element, n)
+
+	// twice, with the coset powers on the server the second time
+	for i := 0; i < 2; i++ {
+		expected := append([]fr.Element(nil), coefficients...)
+		domain.FFT(expected, fft.DIF, fft.OnCoset())
+		fft.BitReverse(expected)
+		if err = d.Ntt(out, in, twiddles, coset, n); err != nil {
+			t.Fatal(err)
+		}
+		if err = d.CopyToHost(unsafe.Pointer(&got[0]), out, n*fr.Bytes); err != nil {
+			t.Fatal(err)
+		}
+		for i := range expected {
+			if got[i] != fr.Element(expected[i].Bits()) {
+				t.Fatalf("wrong evaluation %d", i)
+			}
+		}
+	}
+	if len(d.mirrors) != 1 {
+		t.Fatalf("%d mirrors of the coset powers", len(d.mirrors))
+	}
+
+	// and back to the coefficients
+	back, err := d.Intt(out, twiddlesInv, cosetInv, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(back)
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), back, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range coefficients {
+		if got[i] != fr.Element(coefficients[i].Bits()) {
+			t.Fatalf("wrong coefficient %d", i)
+		}
+	}
+
+	// the mirrors of the coset powers are dropped when they are written
+	ones := make([]fr.Element, n)
+	for i := range ones {
+		ones[i].SetOne()
+	}
+	if err = d.CopyToDevice(coset, unsafe.Pointer(&ones[0]), n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.FromMontgomery(coset, n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.Ntt(out, in, twiddles, coset, n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), out, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	expected := append([]fr.Element(nil), coefficients...)
+	domain.FFT(expected, fft.DIF)
+	fft.BitReverse(expected)
+	for i := range expected {
+		if got[i] != fr.Element(expected[i].Bits()) {
+			t.Fatalf("wrong evaluation %d on the domain", i)
+		}
+	}
+}
+
+func TestCompactScalars(t *testing.T) {
+	scalars := make([]fr.Element, 5)
+	scalars[1].SetUint64(1)
+	scalars[2].SetUint64(1 << 40)
+	scalars[3].SetRandom()
+	data := bytesOf(unsafe.Pointer(&scalars[0]), len(scalars)*fr.Bytes)
+	compact := compactScalars(nil, data, fr.Bytes)
+	got, err := expandScalars(compact, fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("wrong expansion")
+	}
+	if _, err = expandScalars([]byte{fr.Bytes + 1}, fr.Bytes); err == nil {
+		t.Fatal("invalid length accepted")
+	}
+	if _, err = expandScalars([]byte{2, 1}, fr.Bytes); err == nil {
+		t.Fatal("truncated scalar accepted")
+	}
+}
+
+type circuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *circuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func TestProve(t *testing.T) {
+	_, address := serve(t)
+	Register(address)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pk.(groth16.DeviceProvingKey).SetAccelerator(Name); err != nil {
+		t.Fatal(err)
+	}
+	w, err := frontend.NewWitness(&circuit{X: 3, Y: 27}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, w, backend.WithAccelerator(Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := w.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = groth16.Verify(proof, vk, public); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	s, address := serve(t)
+	d := dial(t, address)
+	p, err := d.Malloc(fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(p)
+	if _, err = d.Intt(p, nil, nil, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.Intt(p, nil, nil, 1); !errors.Is(err, accel.ErrDevice) {
+		t.Fatalf("expected a device error, got %v", err)
+	}
+	if _, err = Dial(context.Background(), address, ecc.BN254); err == nil {
+		t.Fatal("dialed a closed server")
+	}
+}
+
+func TestToken(t *testing.T) {
+	token := []byte("0123456789abcdef0123456789abcdef")
+	_, address := serve(t, RequireToken(token))
+	for _, opts := range [][]Option{nil, {WithToken([]byte("0123456789abcdef0123456789abcdeF"))}} {
+		if _, err := Dial(context.Background(), address, ecc.BN254, opts...); !errors.Is(err, ErrUnauthenticated) {
+			t.Fatalf("expected an authentication error, got %v", err)
+		}
+	}
+	d := dial(t, address, WithToken(token))
+	p, err := d.Malloc(fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(p)
+	if _, err = d.Intt(p, nil, nil, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	_, address := serve(t, WithServerMaxMessageSize(MinMaxMessageSize))
+
+	// the client uses the bound of the server, and the vectors span several chunks
+	d := dial(t, address)
+	if d.chunk != chunkSize(MinMaxMessageSize, fr.Bytes) {
+		t.Fatalf("chunks of %d bytes", d.chunk)
+	}
+	const n = 1 << 12
+	coefficients := make([]fr.Element, n)
+	for i := range coefficients {
+		coefficients[i].SetRandom()
+	}
+	twiddles, err := d.Twiddles(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddles)
+	twiddlesInv, err := d.Twiddles(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddlesInv)
+	in := scalarsToDevice(t, d, coefficients)
+	defer d.Free(in)
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(out)
+	if err = d.Ntt(out, in, twiddles, nil, n); err != nil {
+		t.Fatal(err)
+	}
+	back, err := d.Intt(out, twiddlesInv, nil, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(back)
+	got := make([]fr.Element, n)
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), back, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range coefficients {
+		if got[i] != fr.Element(coefficients[i].Bits()) {
+			t.Fatalf("wrong coefficient %d", i)
+		}
+	}
+
+	// the messages larger than the bound of the server are rejected
+	stream, err := d.rpc.Write(d.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Send(&pb.WriteRequest{Handle: 1, Data: make([]byte, MinMaxMessageSize)})
+	if _, err = stream.CloseAndRecv(); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected an oversized message error, got %v", err)
+	}
+
+	// and so are the calls without a session
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = pb.NewAcceleratorClient(conn).Malloc(context.Background(), &pb.MallocRequest{Size: 1}); !errors.Is(errorOf(err), ErrUnauthenticated) {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
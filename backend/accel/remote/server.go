@@ -0,0 +1,667 @@
+package remote
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/remote/internal/pb"
+	"github.com/consensys/gnark/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("server closed")
+
+// Server serves the devices of an accel provider to the clients of Dial. Each session opens a
+// device of the provider for the curve of the client; its buffers are freed when the session
+// ends.
+type Server struct {
+	pb.UnimplementedAcceleratorServer
+
+	provider       string
+	token          []byte
+	maxMessageSize int
+	tls            *tls.Config
+	grpc           *grpc.Server
+
+	lock     sync.Mutex
+	sessions map[string]*session
+	closed   bool
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// RequireToken makes the server reject the clients without the token, see WithToken. The token
+// is a shared secret, of at least 32 random bytes.
+func RequireToken(token []byte) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithServerMaxMessageSize bounds the messages of the connections, DefaultMaxMessageSize by
+// default, and at least MinMaxMessageSize. The clients use the smallest of their bound and this
+// one.
+func WithServerMaxMessageSize(size int) ServerOption {
+	return func(s *Server) {
+		s.maxMessageSize = size
+	}
+}
+
+// WithServerTLS serves the clients over TLS, whose configuration may require and verify the
+// certificates of the clients.
+func WithServerTLS(cfg *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tls = cfg
+	}
+}
+
+// NewServer returns a server of the devices of the named provider of the accel registry, or of
+// the one accel.Open selects if provider is empty.
+func NewServer(provider string, opts ...ServerOption) *Server {
+	s := &Server{provider: provider, sessions: make(map[string]*session)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.maxMessageSize = messageSize(s.maxMessageSize)
+	grpcOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(s.maxMessageSize),
+		// the Open stream of the session, and its calls
+		grpc.MaxConcurrentStreams(maxPending + 1),
+	}
+	if s.tls != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(s.tls)))
+	}
+	s.grpc = grpc.NewServer(grpcOpts...)
+	pb.RegisterAcceleratorServer(s.grpc, s)
+	return s
+}
+
+// Serve accepts the connections of the listener until it fails or the server is closed, in
+// which case it returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.lock.Lock()
+	closed := s.closed
+	s.lock.Unlock()
+	if closed {
+		_ = l.Close()
+		return ErrServerClosed
+	}
+	err := s.grpc.Serve(l)
+	if err == nil || errors.Is(err, grpc.ErrServerStopped) {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// Close closes the listeners and the connections, which ends the sessions.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	s.lock.Unlock()
+	s.grpc.Stop()
+	return nil
+}
+
+// session is the device of a session, and its buffers.
+type session struct {
+	device accel.Device
+	layout layout
+
+	// calls counts the calls in progress, which the session waits for before freeing its
+	// buffers
+	calls sync.WaitGroup
+
+	lock       sync.Mutex
+	lastHandle uint64
+	buffers    map[uint64]*buffer
+	twiddles   map[twiddlesKey]unsafe.Pointer
+}
+
+// buffer is a buffer of the device, or of the host for the points to copy to the device.
+type buffer struct {
+	p    unsafe.Pointer
+	size int
+	host []byte // the buffer on the host, or nil
+
+	// the points copied to the device
+	points bool
+	n      int
+	g2     bool
+	cfg    accel.PointsConfig
+
+	// users counts the requests using the buffer: it is freed by the last one after Free.
+	users int
+	freed bool
+}
+
+type twiddlesKey struct {
+	n       int
+	inverse bool
+}
+
+// Open sends the challenge of the server, then authenticates the client and opens the device
+// of its curve, and keeps the session until the stream ends.
+func (s *Server) Open(stream pb.Accelerator_OpenServer) error {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return statusOf(err)
+	}
+	if err := stream.Send(&pb.OpenResponse{Challenge: challenge, MaxMessageSize: uint64(s.maxMessageSize)}); err != nil {
+		return err
+	}
+
+	type received struct {
+		req *pb.OpenRequest
+		err error
+	}
+	recv := make(chan received, 1)
+	go func() {
+		req, err := stream.Recv()
+		recv <- received{req, err}
+	}()
+	var req *pb.OpenRequest
+	select {
+	case r := <-recv:
+		if r.err != nil {
+			return r.err
+		}
+		req = r.req
+	case <-time.After(handshakeTimeout):
+		return status.Error(codes.DeadlineExceeded, "authentication timeout")
+	}
+	if len(s.token) != 0 && !hmac.Equal(req.Mac, tokenMAC(s.token, challenge)) {
+		return statusOf(ErrUnauthenticated)
+	}
+	curve, err := parseCurve(req.Curve)
+	if err != nil {
+		return statusOf(err)
+	}
+	device, err := accel.OpenProvider(s.provider, curve)
+	if err != nil {
+		return statusOf(err)
+	}
+	ss := &session{device: device, layout: layouts[curve], buffers: make(map[uint64]*buffer), twiddles: make(map[twiddlesKey]unsafe.Pointer)}
+	id := make([]byte, challengeSize)
+	if _, err := rand.Read(id); err != nil {
+		return statusOf(err)
+	}
+	s.lock.Lock()
+	s.sessions[string(id)] = ss
+	s.lock.Unlock()
+	defer func() {
+		// the next calls are rejected, then the ones in progress complete
+		s.lock.Lock()
+		delete(s.sessions, string(id))
+		s.lock.Unlock()
+		ss.calls.Wait()
+		ss.free()
+	}()
+
+	if err := stream.Send(&pb.OpenResponse{Session: id, Device: device.Name()}); err != nil {
+		return err
+	}
+	// the client doesn't send anything else: the stream ends when it is closed
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err != io.EOF && status.Code(err) != codes.Canceled {
+				log := logger.Logger()
+				client := "unknown"
+				if p, ok := peer.FromContext(stream.Context()); ok {
+					client = p.Addr.String()
+				}
+				log.Debug().Err(err).Str("client", client).Msg("remote accelerator session closed")
+			}
+			return nil
+		}
+	}
+}
+
+// session returns the session of the call, with its counter of calls incremented, which the
+// caller decrements.
+func (s *Server) session(ctx context.Context) (*session, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(sessionKey)
+	if len(ids) != 1 {
+		return nil, statusOf(fmt.Errorf("%w: no session", ErrUnauthenticated))
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ss, ok := s.sessions[ids[0]]
+	if !ok {
+		return nil, statusOf(fmt.Errorf("%w: unknown session", ErrUnauthenticated))
+	}
+	ss.calls.Add(1)
+	return ss, nil
+}
+
+func (s *Server) Malloc(ctx context.Context, req *pb.MallocRequest) (*pb.Buffer, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	res, err := ss.malloc(req.Size, req.Host)
+	return res, statusOf(err)
+}
+
+func (s *Server) Free(ctx context.Context, req *pb.Buffer) (*pb.Empty, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	return &pb.Empty{}, statusOf(ss.freeHandle(req.Handle))
+}
+
+func (s *Server) Write(stream pb.Accelerator_WriteServer) error {
+	ss, err := s.session(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer ss.calls.Done()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.Empty{})
+		}
+		if err != nil {
+			return err
+		}
+		if err = ss.write(req.Handle, req.Offset, req.Encoding, req.Data); err != nil {
+			return statusOf(err)
+		}
+	}
+}
+
+func (s *Server) Read(req *pb.ReadRequest, stream pb.Accelerator_ReadServer) error {
+	ss, err := s.session(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer ss.calls.Done()
+	chunk := s.maxMessageSize - chunkOverhead
+	if req.Chunk != 0 && req.Chunk < uint64(chunk) {
+		chunk = int(req.Chunk)
+	}
+	return statusOf(ss.read(req.Handle, req.Offset, req.Size, chunk, func(data []byte) error {
+		return stream.Send(&pb.Chunk{Data: data})
+	}))
+}
+
+func (s *Server) Points(ctx context.Context, req *pb.PointsRequest) (*pb.Buffer, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	cfg := accel.PointsConfig{Representation: accel.PointRepresentation(req.Representation), WithInfinity: req.Infinity}
+	res, err := ss.pointsToDevice(req.Host, req.N, req.G2, cfg)
+	return res, statusOf(err)
+}
+
+func (s *Server) Msm(ctx context.Context, req *pb.MsmRequest) (*pb.Chunk, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	res, err := ss.msm(req.Scalars, req.Points, req.N, req.BucketFactor, req.G2)
+	return res, statusOf(err)
+}
+
+func (s *Server) Ntt(ctx context.Context, req *pb.NttRequest) (*pb.Empty, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	return &pb.Empty{}, statusOf(ss.ntt(req.Out, req.In, req.CosetPowers, req.N))
+}
+
+func (s *Server) Intt(ctx context.Context, req *pb.NttRequest) (*pb.Buffer, error) {
+	ss, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.calls.Done()
+	res, err := ss.intt(req.In, req.CosetPowers, req.N)
+	return res, statusOf(err)
+}
+
+// maxInt bounds the integers of the requests, to keep the products of sizes in range.
+const maxInt = 1 << 48
+
+// toInt returns the integers of a request as ints, or an error if they exceed maxInt.
+func toInt(values ...uint64) ([]int, error) {
+	res := make([]int, len(values))
+	for i, v := range values {
+		if v > maxInt {
+			return nil, fmt.Errorf("integer %d out of range", v)
+		}
+		res[i] = int(v)
+	}
+	return res, nil
+}
+
+// add registers the buffer and returns its handle.
+func (ss *session) add(b *buffer) *pb.Buffer {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.lastHandle++
+	ss.buffers[ss.lastHandle] = b
+	return &pb.Buffer{Handle: ss.lastHandle}
+}
+
+// acquire returns the buffer of the handle, which the caller releases. It returns nil for the
+// handle 0 if optional.
+func (ss *session) acquire(h uint64, optional bool) (*buffer, error) {
+	if h == 0 && optional {
+		return nil, nil
+	}
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	b, ok := ss.buffers[h]
+	if !ok {
+		return nil, fmt.Errorf("unknown buffer %d", h)
+	}
+	b.users++
+	return b, nil
+}
+
+func (ss *session) release(b *buffer) {
+	if b == nil {
+		return
+	}
+	ss.lock.Lock()
+	b.users--
+	free := b.freed && b.users == 0
+	ss.lock.Unlock()
+	if free && b.host == nil {
+		_ = ss.device.Free(b.p)
+	}
+}
+
+func (ss *session) malloc(size64 uint64, host bool) (*pb.Buffer, error) {
+	v, err := toInt(size64)
+	if err != nil {
+		return nil, err
+	}
+	size := v[0]
+	if host {
+		if size > maxHostSize {
+			return nil, fmt.Errorf("host buffer of %d bytes exceeds the maximum", size)
+		}
+		data := alignedBytes(size)
+		return ss.add(&buffer{p: pointerOf(data), size: size, host: data}), nil
+	}
+	p, err := ss.device.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	return ss.add(&buffer{p: p, size: size}), nil
+}
+
+func (ss *session) freeHandle(h uint64) error {
+	b, err := ss.acquire(h, false)
+	if err != nil {
+		return err
+	}
+	ss.lock.Lock()
+	delete(ss.buffers, h)
+	b.freed = true
+	ss.lock.Unlock()
+	ss.release(b)
+	return nil
+}
+
+// free frees the buffers of the session, when it ends.
+func (ss *session) free() {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	for h, b := range ss.buffers {
+		b.freed = true
+		if b.users == 0 && b.host == nil {
+			_ = ss.device.Free(b.p)
+		}
+		delete(ss.buffers, h)
+	}
+	for k, p := range ss.twiddles {
+		_ = ss.device.Free(p)
+		delete(ss.twiddles, k)
+	}
+}
+
+func (ss *session) write(h, offset64 uint64, encoding pb.Encoding, data []byte) error {
+	v, err := toInt(offset64)
+	if err != nil {
+		return err
+	}
+	offset := v[0]
+	b, err := ss.acquire(h, false)
+	if err != nil {
+		return err
+	}
+	defer ss.release(b)
+	switch encoding {
+	case pb.Encoding_RAW:
+	case pb.Encoding_COMPACT:
+		if data, err = expandScalars(data, ss.layout.scalar); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown encoding %d", encoding)
+	}
+	if b.points || offset+len(data) > b.size {
+		return fmt.Errorf("write of %d bytes at %d out of the buffer %d", len(data), offset, h)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if b.host != nil {
+		copy(b.host[offset:], data)
+		return nil
+	}
+	return ss.device.CopyToDevice(unsafe.Add(b.p, offset), pointerOf(data), len(data))
+}
+
+// read sends the size bytes at the offset of the buffer, in chunks of chunk bytes.
+func (ss *session) read(h, offset64, size64 uint64, chunk int, send func([]byte) error) error {
+	v, err := toInt(offset64, size64)
+	if err != nil {
+		return err
+	}
+	offset, size := v[0], v[1]
+	b, err := ss.acquire(h, false)
+	if err != nil {
+		return err
+	}
+	defer ss.release(b)
+	if b.points || b.host != nil || offset+size > b.size || chunk <= 0 {
+		return fmt.Errorf("read of %d bytes at %d out of the buffer %d", size, offset, h)
+	}
+	res := alignedBytes(chunk)
+	for start := offset; start < offset+size; start += chunk {
+		l := chunk
+		if offset+size-start < l {
+			l = offset + size - start
+		}
+		if err := ss.device.CopyToHost(pointerOf(res), unsafe.Add(b.p, start), l); err != nil {
+			return err
+		}
+		if err := send(res[:l]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointsToDevice copies the points of the host buffer to the device, and frees the host buffer.
+func (ss *session) pointsToDevice(h, n64 uint64, g2 bool, cfg accel.PointsConfig) (*pb.Buffer, error) {
+	v, err := toInt(n64)
+	if err != nil {
+		return nil, err
+	}
+	n := v[0]
+	b, err := ss.acquire(h, false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		ss.release(b)
+		_ = ss.freeHandle(h)
+	}()
+	size := ss.layout.g1Affine
+	if g2 {
+		size = ss.layout.g2Affine
+	}
+	if b.host == nil || n*size > b.size {
+		return nil, fmt.Errorf("no %d points in the buffer %d", n, h)
+	}
+	var p unsafe.Pointer
+	if g2 {
+		p, err = ss.device.PointsG2ToDevice(b.p, n, cfg)
+	} else {
+		p, err = ss.device.PointsG1ToDevice(b.p, n, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ss.add(&buffer{p: p, points: true, n: n, g2: g2, cfg: cfg}), nil
+}
+
+func (ss *session) msm(hScalars, hPoints, n64, bucketFactor64 uint64, g2 bool) (*pb.Chunk, error) {
+	v, err := toInt(n64, bucketFactor64)
+	if err != nil {
+		return nil, err
+	}
+	n, bucketFactor := v[0], v[1]
+	scalars, err := ss.acquire(hScalars, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.release(scalars)
+	points, err := ss.acquire(hPoints, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.release(points)
+	if scalars.points || scalars.host != nil || n*ss.layout.scalar > scalars.size {
+		return nil, fmt.Errorf("no %d scalars in the buffer %d", n, hScalars)
+	}
+	if !points.points || points.g2 != g2 || n > points.n {
+		return nil, fmt.Errorf("no %d points in the buffer %d", n, hPoints)
+	}
+
+	// the points keep the layout of their copy
+	cfg := accel.MsmConfig{BucketFactor: bucketFactor, Points: points.cfg}
+	if g2 {
+		res := alignedBytes(ss.layout.g2Jac)
+		return &pb.Chunk{Data: res}, ss.device.MsmG2(pointerOf(res), scalars.p, points.p, n, cfg)
+	}
+	res := alignedBytes(ss.layout.g1Jac)
+	return &pb.Chunk{Data: res}, ss.device.Msm(pointerOf(res), scalars.p, points.p, n, cfg)
+}
+
+// vector returns the buffer of the handle, of n scalars at least.
+func (ss *session) vector(h uint64, n int, optional bool) (*buffer, error) {
+	b, err := ss.acquire(h, optional)
+	if b == nil || err != nil {
+		return nil, err
+	}
+	if b.points || b.host != nil || n*ss.layout.scalar > b.size {
+		ss.release(b)
+		return nil, fmt.Errorf("no %d scalars in the buffer %d", n, h)
+	}
+	return b, nil
+}
+
+// devicePointer returns the device pointer of the buffer, or nil.
+func (b *buffer) devicePointer() unsafe.Pointer {
+	if b == nil {
+		return nil
+	}
+	return b.p
+}
+
+// twiddlesOf returns the twiddles of the domain of size n, computed once per session.
+func (ss *session) twiddlesOf(n int, inverse bool) (unsafe.Pointer, error) {
+	if n <= 0 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("invalid domain size %d", n)
+	}
+	key := twiddlesKey{n: n, inverse: inverse}
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	if p, ok := ss.twiddles[key]; ok {
+		return p, nil
+	}
+	p, err := ss.device.Twiddles(n, inverse)
+	if err != nil {
+		return nil, err
+	}
+	ss.twiddles[key] = p
+	return p, nil
+}
+
+func (ss *session) ntt(hOut, hIn, hCoset, n64 uint64) error {
+	v, err := toInt(n64)
+	if err != nil {
+		return err
+	}
+	n := v[0]
+	twiddles, err := ss.twiddlesOf(n, false)
+	if err != nil {
+		return err
+	}
+	var b [3]*buffer
+	for i, h := range []uint64{hOut, hIn, hCoset} {
+		if b[i], err = ss.vector(h, n, i == 2); err != nil {
+			return err
+		}
+		defer ss.release(b[i])
+	}
+	return ss.device.Ntt(b[0].p, b[1].p, twiddles, b[2].devicePointer(), n)
+}
+
+func (ss *session) intt(hIn, hCoset, n64 uint64) (*pb.Buffer, error) {
+	v, err := toInt(n64)
+	if err != nil {
+		return nil, err
+	}
+	n := v[0]
+	twiddles, err := ss.twiddlesOf(n, true)
+	if err != nil {
+		return nil, err
+	}
+	in, err := ss.vector(hIn, n, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.release(in)
+	coset, err := ss.vector(hCoset, n, true)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.release(coset)
+	p, err := ss.device.Intt(in.p, twiddles, coset.devicePointer(), n)
+	if err != nil {
+		return nil, err
+	}
+	return ss.add(&buffer{p: p, size: n * ss.layout.scalar}), nil
+}
@@ -0,0 +1,24 @@
+// Package rocm registers the ROCm provider of accelerators, for the AMD GPUs, on the BN254
+// curve.
+//
+// The provider is built with the rocm build tag, and links the HIP kernels of the kernels
+// directory, which are built first with hipcc:
+//
+//	make -C backend/accel/rocm/kernels GPU_TARGETS=gfx90a
+//	go build -tags rocm ./...
+//
+// The tests of the package, built with the tag too, check the kernels against the cpu provider
+// and fail without an AMD GPU:
+//
+//	go test -tags rocm ./backend/accel/rocm
+//
+// If the icicle provider is built too, with the icicle build tag, it is registered first, so
+// the ROCm provider is selected with the environment variable GNARK_ACCEL=rocm, see accel.Open.
+//
+// The kernels are plain: a radix 2 NTT, and multi-scalar multiplications with the bucket
+// method on chunks of the points, one per thread, summed on the host. The bucket factor of
+// accel.MsmConfig is ignored.
+package rocm
+
+// Name is the name of the provider in the accel registry.
+const Name = "rocm"
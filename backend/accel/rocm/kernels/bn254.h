@@ -0,0 +1,307 @@
+// Arithmetic of the BN254 fields and groups, shared by the kernels and the host.
+//
+// The elements are 4 little-endian 64-bit limbs, in the layout of gnark-crypto. The base field
+// elements are in Montgomery form, as in the gnark-crypto points. The scalars are in Montgomery
+// form on the host and in regular form on the device, see bn254_from_montgomery.
+#pragma once
+
+#include <stdint.h>
+
+#ifdef __HIPCC__
+#define GNARK_FN __host__ __device__ __forceinline__
+#else
+#define GNARK_FN inline
+#endif
+
+typedef unsigned __int128 u128;
+
+struct fp_params {
+  GNARK_FN static uint64_t mod(int i) {
+    const uint64_t v[4] = {0x3c208c16d87cfd47ULL, 0x97816a916871ca8dULL, 0xb85045b68181585dULL, 0x30644e72e131a029ULL};
+    return v[i];
+  }
+  static constexpr uint64_t inv = 0x87d20782e4866389ULL; // -mod⁻¹ mod 2⁶⁴
+  GNARK_FN static uint64_t one(int i) {
+    const uint64_t v[4] = {0xd35d438dc58f0d9dULL, 0x0a78eb28f5c70b3dULL, 0x666ea36f7879462cULL, 0x0e0a77c19a07df2fULL};
+    return v[i];
+  }
+  GNARK_FN static uint64_t r2(int i) {
+    const uint64_t v[4] = {0xf32cfc5b538afa89ULL, 0xb5e71911d44501fbULL, 0x47ab1eff0a417ff6ULL, 0x06d89f71cab8351fULL};
+    return v[i];
+  }
+};
+
+struct fr_params {
+  GNARK_FN static uint64_t mod(int i) {
+    const uint64_t v[4] = {0x43e1f593f0000001ULL, 0x2833e84879b97091ULL, 0xb85045b68181585dULL, 0x30644e72e131a029ULL};
+    return v[i];
+  }
+  static constexpr uint64_t inv = 0xc2e1f593efffffffULL;
+  GNARK_FN static uint64_t one(int i) {
+    const uint64_t v[4] = {0xac96341c4ffffffbULL, 0x36fc76959f60cd29ULL, 0x666ea36f7879462eULL, 0x0e0a77c19a07df2fULL};
+    return v[i];
+  }
+  GNARK_FN static uint64_t r2(int i) {
+    const uint64_t v[4] = {0x1bb8e645ae216da7ULL, 0x53fe3ab1e35c59e3ULL, 0x8c49833d53bb8085ULL, 0x0216d0b17f4e44a5ULL};
+    return v[i];
+  }
+};
+
+// field is an element of the prime field of P, a modulus below 2²⁵⁵.
+template <typename P>
+struct field {
+  uint64_t l[4];
+
+  GNARK_FN static field zero() { return field{{0, 0, 0, 0}}; }
+  GNARK_FN static field one() { return field{{P::one(0), P::one(1), P::one(2), P::one(3)}}; }
+  GNARK_FN static field r2() { return field{{P::r2(0), P::r2(1), P::r2(2), P::r2(3)}}; }
+
+  GNARK_FN bool is_zero() const { return (l[0] | l[1] | l[2] | l[3]) == 0; }
+
+  GNARK_FN bool operator==(const field &b) const {
+    return l[0] == b.l[0] && l[1] == b.l[1] && l[2] == b.l[2] && l[3] == b.l[3];
+  }
+
+  // reduce subtracts the modulus once if z ≥ modulus.
+  GNARK_FN static field reduce(field z) {
+    field t;
+    u128 borrow = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 d = (u128)z.l[i] - P::mod(i) - borrow;
+      t.l[i] = (uint64_t)d;
+      borrow = (d >> 64) & 1;
+    }
+    return borrow ? z : t;
+  }
+
+  GNARK_FN field operator+(const field &b) const {
+    field z;
+    u128 carry = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 s = (u128)l[i] + b.l[i] + carry;
+      z.l[i] = (uint64_t)s;
+      carry = s >> 64;
+    }
+    return reduce(z); // no carry out, the modulus is below 2²⁵⁵
+  }
+
+  GNARK_FN field operator-(const field &b) const {
+    field z;
+    u128 borrow = 0;
+    for (int i = 0; i < 4; i++) {
+      u128 d = (u128)l[i] - b.l[i] - borrow;
+      z.l[i] = (uint64_t)d;
+      borrow = (d >> 64) & 1;
+    }
+    if (borrow) {
+      u128 carry = 0;
+      for (int i = 0; i < 4; i++) {
+        u128 s = (u128)z.l[i] + P::mod(i) + carry;
+        z.l[i] = (uint64_t)s;
+        carry = s >> 64;
+      }
+    }
+    return z;
+  }
+
+  GNARK_FN field dbl() const { return *this + *this; }
+
+  // operator* is the Montgomery product a·b·2⁻²⁵⁶ (CIOS).
+  GNARK_FN field operator*(const field &b) const {
+    uint64_t t[6] = {0, 0, 0, 0, 0, 0};
+    for (int i = 0; i < 4; i++) {
+      u128 c = 0;
+      for (int j = 0; j < 4; j++) {
+        c = (u128)l[j] * b.l[i] + t[j] + (c >> 64);
+        t[j] = (uint64_t)c;
+      }
+      c = (u128)t[4] + (c >> 64);
+      t[4] = (uint64_t)c;
+      t[5] = (uint64_t)(c >> 64);
+
+      uint64_t m = t[0] * P::inv;
+      c = (u128)m * P::mod(0) + t[0];
+      for (int j = 1; j < 4; j++) {
+        c = (u128)m * P::mod(j) + t[j] + (c >> 64);
+        t[j - 1] = (uint64_t)c;
+      }
+      c = (u128)t[4] + (c >> 64);
+      t[3] = (uint64_t)c;
+      t[4] = t[5] + (uint64_t)(c >> 64);
+    }
+    return reduce(field{{t[0], t[1], t[2], t[3]}});
+  }
+
+  GNARK_FN field sqr() const { return *this * *this; }
+
+  // from_montgomery returns the regular form of the Montgomery form z.
+  GNARK_FN field from_montgomery() const { return *this * field{{1, 0, 0, 0}}; }
+
+  // mul_regular returns the product of elements in regular form.
+  GNARK_FN field mul_regular(const field &b) const { return (*this * b) * r2(); }
+};
+
+typedef field<fp_params> fp;
+typedef field<fr_params> fr;
+
+// fp2 is an element of Fp[u]/(u²+1), in the layout of gnark-crypto's E2.
+struct fp2 {
+  fp a0, a1;
+
+  GNARK_FN static fp2 zero() { return fp2{fp::zero(), fp::zero()}; }
+  GNARK_FN static fp2 one() { return fp2{fp::one(), fp::zero()}; }
+  GNARK_FN bool is_zero() const { return a0.is_zero() && a1.is_zero(); }
+  GNARK_FN bool operator==(const fp2 &b) const { return a0 == b.a0 && a1 == b.a1; }
+  GNARK_FN fp2 operator+(const fp2 &b) const { return fp2{a0 + b.a0, a1 + b.a1}; }
+  GNARK_FN fp2 operator-(const fp2 &b) const { return fp2{a0 - b.a0, a1 - b.a1}; }
+  GNARK_FN fp2 dbl() const { return *this + *this; }
+
+  GNARK_FN fp2 operator*(const fp2 &b) const {
+    fp v0 = a0 * b.a0, v1 = a1 * b.a1;
+    return fp2{v0 - v1, (a0 + a1) * (b.a0 + b.a1) - v0 - v1};
+  }
+
+  GNARK_FN fp2 sqr() const { return *this * *this; }
+};
+
+// affine is a point (x, y) of the curve y² = x³ + b, at infinity if (x, y) = (0, 0) as in
+// gnark-crypto.
+template <typename F>
+struct affine {
+  F x, y;
+
+  GNARK_FN bool is_infinity() const { return x.is_zero() && y.is_zero(); }
+};
+
+// jacobian is a point (X/Z², Y/Z³) of the curve, at infinity if Z = 0, in the layout of
+// gnark-crypto's G1Jac and G2Jac.
+template <typename F>
+struct jacobian {
+  F x, y, z;
+
+  GNARK_FN static jacobian infinity() { return jacobian{F::one(), F::one(), F::zero()}; }
+  GNARK_FN bool is_infinity() const { return z.is_zero(); }
+
+  // dbl-2009-l
+  GNARK_FN jacobian dbl() const {
+    if (is_infinity())
+      return *this;
+    F a = x.sqr(), b = y.sqr(), c = b.sqr();
+    F d = ((x + b).sqr() - a - c).dbl();
+    F e = a.dbl() + a;
+    F f = e.sqr();
+    jacobian r;
+    r.x = f - d.dbl();
+    r.y = e * (d - r.x) - c.dbl().dbl().dbl();
+    r.z = (y * z).dbl();
+    return r;
+  }
+
+  // madd-2007-bl
+  GNARK_FN jacobian add(const affine<F> &q) const {
+    if (q.is_infinity())
+      return *this;
+    if (is_infinity())
+      return jacobian{q.x, q.y, F::one()};
+    F z1z1 = z.sqr();
+    F u2 = q.x * z1z1;
+    F s2 = q.y * z * z1z1;
+    F h = u2 - x;
+    F r = (s2 - y).dbl();
+    if (h.is_zero())
+      return r.is_zero() ? dbl() : infinity();
+    F hh = h.sqr();
+    F i = hh.dbl().dbl();
+    F j = h * i;
+    F v = x * i;
+    jacobian res;
+    res.x = r.sqr() - j - v.dbl();
+    res.y = r * (v - res.x) - (y * j).dbl();
+    res.z = (z + h).sqr() - z1z1 - hh;
+    return res;
+  }
+
+  // add-2007-bl
+  GNARK_FN jacobian add(const jacobian &q) const {
+    if (is_infinity())
+      return q;
+    if (q.is_infinity())
+      return *this;
+    F z1z1 = z.sqr(), z2z2 = q.z.sqr();
+    F u1 = x * z2z2, u2 = q.x * z1z1;
+    F s1 = y * q.z * z2z2, s2 = q.y * z * z1z1;
+    F h = u2 - u1;
+    F r = (s2 - s1).dbl();
+    if (h.is_zero())
+      return r.is_zero() ? dbl() : infinity();
+    F i = h.dbl().sqr();
+    F j = h * i;
+    F v = u1 * i;
+    jacobian res;
+    res.x = r.sqr() - j - v.dbl();
+    res.y = r * (v - res.x) - (s1 * j).dbl();
+    res.z = ((z + q.z).sqr() - z1z1 - z2z2) * h;
+    return res;
+  }
+};
+
+typedef affine<fp> g1_affine;
+typedef jacobian<fp> g1_jacobian;
+typedef affine<fp2> g2_affine;
+typedef jacobian<fp2> g2_jacobian;
+
+// MSM_WINDOW is the window of the bucket method of the multi-scalar multiplications, in bits.
+#define MSM_WINDOW 4
+#define MSM_BUCKETS ((1 << MSM_WINDOW) - 1)
+
+// window_digit returns the digit of the window w of a scalar in regular form.
+GNARK_FN unsigned window_digit(const fr &s, int w) {
+  int bit = w * MSM_WINDOW; // the windows don't straddle limbs, 64 is a multiple of the window
+  return (unsigned)(s.l[bit / 64] >> (bit % 64)) & MSM_BUCKETS;
+}
+
+// msm_chunk returns the multi-scalar multiplication of n points (affine or jacobian) and
+// scalars with the bucket method, with a single thread.
+template <typename F, typename P>
+GNARK_FN jacobian<F> msm_chunk(const fr *scalars, const P *points, uint64_t n) {
+  jacobian<F> acc = jacobian<F>::infinity();
+  jacobian<F> buckets[MSM_BUCKETS];
+  for (int w = 256 / MSM_WINDOW - 1; w >= 0; w--) {
+    for (int k = 0; k < MSM_WINDOW; k++)
+      acc = acc.dbl();
+    for (int b = 0; b < MSM_BUCKETS; b++)
+      buckets[b] = jacobian<F>::infinity();
+    for (uint64_t i = 0; i < n; i++) {
+      unsigned d = window_digit(scalars[i], w);
+      if (d != 0)
+        buckets[d - 1] = buckets[d - 1].add(points[i]);
+    }
+    // Σ b·buckets[b-1] as a sum of running sums
+    jacobian<F> running = jacobian<F>::infinity(), sum = jacobian<F>::infinity();
+    for (int b = MSM_BUCKETS - 1; b >= 0; b--) {
+      running = running.add(buckets[b]);
+      sum = sum.add(running);
+    }
+    acc = acc.add(sum);
+  }
+  return acc;
+}
+
+// bit_reverse returns the reversal of the log_n lower bits of i.
+GNARK_FN uint64_t bit_reverse(uint64_t i, int log_n) {
+  uint64_t r = 0;
+  for (int k = 0; k < log_n; k++, i >>= 1)
+    r = (r << 1) | (i & 1);
+  return r;
+}
+
+// ntt_butterfly runs the butterfly t < n/2 of the stage of half-size m of a decimation in
+// time NTT of size n, on scalars in regular form in bit-reversed order. The twiddles are the
+// n/2 first powers of the root of unity of order n.
+GNARK_FN void ntt_butterfly(fr *a, const fr *twiddles, uint64_t t, uint64_t m, uint64_t n) {
+  uint64_t j = t % m;
+  uint64_t i0 = (t / m) * 2 * m + j, i1 = i0 + m;
+  fr u = a[i0], v = a[i1].mul_regular(twiddles[j * (n / (2 * m))]);
+  a[i0] = u + v;
+  a[i1] = u - v;
+}
@@ -0,0 +1,56 @@
+// C interface of the ROCm kernels of the gnark provers, see backend/accel/rocm.
+//
+// The functions return a hipError_t, 0 on success. The pointers are device pointers unless
+// noted otherwise, and the sizes are numbers of elements unless noted otherwise.
+#pragma once
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+int gnark_rocm_malloc(void **p, size_t bytes);
+int gnark_rocm_free(void *p);
+int gnark_rocm_copy_to_device(void *dst, const void *src_host, size_t bytes);
+int gnark_rocm_copy_to_host(void *dst_host, const void *src, size_t bytes);
+int gnark_rocm_device_name(char *name_host, size_t bytes);
+
+// bn254_from_montgomery converts n scalars to the regular form.
+int bn254_from_montgomery(void *scalars, size_t n);
+
+// bn254_reverse_scalars permutes n scalars, a power of 2, in bit-reversed order.
+int bn254_reverse_scalars(void *scalars, size_t n);
+
+// bn254_vec_mul sets a to a·b, and bn254_vec_sub to a-b, element-wise.
+int bn254_vec_mul(void *a, const void *b, size_t n);
+int bn254_vec_sub(void *a, const void *b, size_t n);
+
+// bn254_ntt sets out to the NTT of in, of size n a power of 2, in natural order. The twiddles
+// are the n/2 first powers of the (inverse) root of unity. The forward NTT multiplies in by the
+// coset powers first, and the inverse NTT multiplies its result by n_inv (a scalar in
+// Montgomery form, on the host) and then by the inverse coset powers; coset may be NULL.
+int bn254_ntt(void *out, const void *in, const void *twiddles, const void *coset, size_t n, int inverse,
+              const uint64_t n_inv[4]);
+
+// bn254_quotient sets h to the n coefficients, in bit-reversed order, of (a·b-c)/Z, where a, b
+// and c are evaluations on the domain and Z its vanishing polynomial. It is the sequence of
+// bn254_ntt (inverse, and forward on the coset) on a, b and c, bn254_vec_mul, bn254_vec_sub,
+// bn254_vec_mul by den (the inverses of Z on the coset), bn254_ntt (inverse on the coset) and
+// bn254_reverse_scalars, with the kernels enqueued on one stream and a single synchronization
+// with the host at the end. It overwrites a, b and c.
+int bn254_quotient(void *h, void *a, void *b, void *c, const void *twiddles, const void *twiddles_inv,
+                   const void *coset, const void *coset_inv, const void *den, size_t n, const uint64_t n_inv[4]);
+
+// bn254_msm_g1 and bn254_msm_g2 split the multi-scalar multiplication of n scalars and points
+// in nb_partials chunks, and set partials (on the host) to the Jacobian results of the chunks.
+// The points are Jacobian if projective isn't 0, and affine otherwise; they may be at infinity.
+int bn254_msm_g1(void *partials_host, const void *scalars, const void *points, size_t n, size_t nb_partials,
+                 int projective);
+int bn254_msm_g2(void *partials_host, const void *scalars, const void *points, size_t n, size_t nb_partials,
+                 int projective);
+
+#ifdef __cplusplus
+}
+#endif
@@ -0,0 +1,287 @@
+//go:build rocm
+
+package rocm
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/kernels
+#cgo LDFLAGS: -L${SRCDIR}/kernels -lgnark_rocm -Wl,-rpath,${SRCDIR}/kernels
+#include "gnark_rocm.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/internal/nvtx"
+)
+
+func init() {
+	accel.Register(accel.Provider{
+		Name:   Name,
+		Curves: []ecc.ID{ecc.BN254},
+		Open: func(c ecc.ID) (accel.Device, error) {
+			if c != ecc.BN254 {
+				return nil, fmt.Errorf("rocm doesn't support %s", c)
+			}
+			var name [256]C.char
+			if err := check("hipGetDeviceProperties", C.gnark_rocm_device_name(&name[0], C.size_t(len(name)))); err != nil {
+				return nil, err
+			}
+			return &deviceBN254{name: C.GoString(&name[0])}, nil
+		},
+	})
+}
+
+// check returns an error if the hipError_t code isn't hipSuccess.
+func check(call string, code C.int) error {
+	if code != 0 {
+		return fmt.Errorf("%w: rocm %s failed with code %d", accel.ErrDevice, call, int(code))
+	}
+	return nil
+}
+
+// maxPartials is the maximal number of chunks of the multi-scalar multiplications, and
+// minChunk their minimal size.
+const (
+	maxPartials = 1 << 15
+	minChunk    = 32
+)
+
+func nbPartials(n int) int {
+	p := n / minChunk
+	if p > maxPartials {
+		return maxPartials
+	}
+	if p < 1 {
+		return 1
+	}
+	return p
+}
+
+// deviceBN254 is the current HIP device, for BN254.
+type deviceBN254 struct {
+	name string
+}
+
+func (d *deviceBN254) Name() string {
+	return "rocm/" + d.name
+}
+
+func (d *deviceBN254) Curve() ecc.ID {
+	return ecc.BN254
+}
+
+func (d *deviceBN254) Malloc(size int) (unsafe.Pointer, error) {
+	var p unsafe.Pointer
+	err := check("hipMalloc", C.gnark_rocm_malloc(&p, C.size_t(size)))
+	return p, err
+}
+
+func (d *deviceBN254) Free(p unsafe.Pointer) error {
+	return check("hipFree", C.gnark_rocm_free(p))
+}
+
+func (d *deviceBN254) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	defer nvtx.Range("CopyToDevice")()
+	return check("hipMemcpy", C.gnark_rocm_copy_to_device(dst, src, C.size_t(size)))
+}
+
+func (d *deviceBN254) CopyToHost(dst, src unsafe.Pointer, size int) error {
+	return check("hipMemcpy", C.gnark_rocm_copy_to_host(dst, src, C.size_t(size)))
+}
+
+func (d *deviceBN254) FromMontgomery(scalars unsafe.Pointer, n int) error {
+	return check("from_montgomery", C.bn254_from_montgomery(scalars, C.size_t(n)))
+}
+
+func (d *deviceBN254) ReverseScalars(scalars unsafe.Pointer, n int) error {
+	return check("reverse_scalars", C.bn254_reverse_scalars(scalars, C.size_t(n)))
+}
+
+func (d *deviceBN254) VecMul(a, b unsafe.Pointer, n int) error {
+	return check("vec_mul", C.bn254_vec_mul(a, b, C.size_t(n)))
+}
+
+func (d *deviceBN254) VecSub(a, b unsafe.Pointer, n int) error {
+	return check("vec_sub", C.bn254_vec_sub(a, b, C.size_t(n)))
+}
+
+// Twiddles computes the twiddles on the host, as they are computed once per proving key.
+func (d *deviceBN254) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	domain := fft.NewDomain(uint64(n))
+	w := domain.Generator
+	if inverse {
+		w = domain.GeneratorInv
+	}
+	nbTwiddles := n / 2
+	if nbTwiddles == 0 {
+		nbTwiddles = 1
+	}
+	twiddles := make([]fr.Element, nbTwiddles)
+	twiddles[0].SetOne()
+	for i := 1; i < len(twiddles); i++ {
+		twiddles[i].Mul(&twiddles[i-1], &w)
+	}
+
+	size := len(twiddles) * fr.Bytes
+	twiddles_d, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.CopyToDevice(twiddles_d, unsafe.Pointer(&twiddles[0]), size); err == nil {
+		err = d.FromMontgomery(twiddles_d, len(twiddles))
+	}
+	if err != nil {
+		_ = d.Free(twiddles_d)
+		return nil, err
+	}
+	return twiddles_d, nil
+}
+
+func (d *deviceBN254) Ntt(out, in, twiddles, cosetPowers unsafe.Pointer, n int) error {
+	defer nvtx.Range("NTT")()
+	return check("ntt", C.bn254_ntt(out, in, twiddles, cosetPowers, C.size_t(n), 0, nil))
+}
+
+func (d *deviceBN254) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range("INTT")()
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	var nInv fr.Element
+	nInv.SetUint64(uint64(n)).Inverse(&nInv)
+	if err := check("ntt", C.bn254_ntt(out, in, twiddles, cosetPowers, C.size_t(n), 1, (*C.uint64_t)(unsafe.Pointer(&nInv[0])))); err != nil {
+		_ = d.Free(out)
+		return nil, err
+	}
+	return out, nil
+}
+
+// Quotient enqueues the kernels on one stream, synchronizing once with the host.
+func (d *deviceBN254) Quotient(a, b, c unsafe.Pointer, domain accel.QuotientDomain, n int) (unsafe.Pointer, error) {
+	defer nvtx.Range("ComputeH")()
+	h, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	var nInv fr.Element
+	nInv.SetUint64(uint64(n)).Inverse(&nInv)
+	if err := check("quotient", C.bn254_quotient(h, a, b, c, domain.Twiddles, domain.TwiddlesInv, domain.CosetPowers, domain.CosetPowersInv, domain.Den, C.size_t(n), (*C.uint64_t)(unsafe.Pointer(&nInv[0])))); err != nil {
+		_ = d.Free(h)
+		return nil, err
+	}
+	return h, nil
+}
+
+// PointsG1ToDevice copies the affine points as they are, the kernels using the layout of
+// gnark-crypto, or converts them to curve.G1Jac on the host for the Projective representation.
+// The kernels skip the points at infinity in both.
+func (d *deviceBN254) PointsG1ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if cfg.Representation == accel.Affine {
+		return d.copyNew(points, n*int(unsafe.Sizeof(curve.G1Affine{})))
+	}
+	if n == 0 {
+		return d.Malloc(0)
+	}
+	affine := unsafe.Slice((*curve.G1Affine)(points), n)
+	jacobian := make([]curve.G1Jac, n)
+	for i := range affine {
+		jacobian[i].FromAffine(&affine[i])
+	}
+	return d.copyNew(unsafe.Pointer(&jacobian[0]), n*int(unsafe.Sizeof(curve.G1Jac{})))
+}
+
+func (d *deviceBN254) PointsG2ToDevice(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if cfg.Representation == accel.Affine {
+		return d.copyNew(points, n*int(unsafe.Sizeof(curve.G2Affine{})))
+	}
+	if n == 0 {
+		return d.Malloc(0)
+	}
+	affine := unsafe.Slice((*curve.G2Affine)(points), n)
+	jacobian := make([]curve.G2Jac, n)
+	for i := range affine {
+		jacobian[i].FromAffine(&affine[i])
+	}
+	return d.copyNew(unsafe.Pointer(&jacobian[0]), n*int(unsafe.Sizeof(curve.G2Jac{})))
+}
+
+func (d *deviceBN254) copyNew(src unsafe.Pointer, size int) (unsafe.Pointer, error) {
+	p, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.CopyToDevice(p, src, size); err != nil {
+		_ = d.Free(p)
+		return nil, err
+	}
+	return p, nil
+}
+
+func (d *deviceBN254) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G1")()
+	if err := checkNbTasks(cfg); err != nil {
+		return err
+	}
+	r := (*curve.G1Jac)(res)
+	r.X.SetOne()
+	r.Y.SetOne()
+	r.Z.SetZero()
+	if n == 0 {
+		return nil
+	}
+	partials := make([]curve.G1Jac, nbPartials(n))
+	if err := check("msm_g1", C.bn254_msm_g1(unsafe.Pointer(&partials[0]), scalars, points, C.size_t(n), C.size_t(len(partials)), projective(cfg))); err != nil {
+		return err
+	}
+	for i := range partials {
+		r.AddAssign(&partials[i])
+	}
+	return nil
+}
+
+func (d *deviceBN254) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	defer nvtx.Range("MSM G2")()
+	if err := checkNbTasks(cfg); err != nil {
+		return err
+	}
+	r := (*curve.G2Jac)(res)
+	r.X.SetOne()
+	r.Y.SetOne()
+	r.Z.SetZero()
+	if n == 0 {
+		return nil
+	}
+	partials := make([]curve.G2Jac, nbPartials(n))
+	if err := check("msm_g2", C.bn254_msm_g2(unsafe.Pointer(&partials[0]), scalars, points, C.size_t(n), C.size_t(len(partials)), projective(cfg))); err != nil {
+		return err
+	}
+	for i := range partials {
+		r.AddAssign(&partials[i])
+	}
+	return nil
+}
+
+// checkNbTasks returns an error if the multi-scalar multiplication sets a number of CPU tasks,
+// the MSMs of rocm running on the GPU.
+func checkNbTasks(cfg accel.MsmConfig) error {
+	if cfg.NbTasks != 0 {
+		return fmt.Errorf("%w: rocm computes the MSMs on the GPU, not on %d CPU tasks", accel.ErrUnsupported, cfg.NbTasks)
+	}
+	return nil
+}
+
+// projective returns the flag of the MSM kernels selecting the Jacobian points.
+func projective(cfg accel.MsmConfig) C.int {
+	if cfg.Points.Representation == accel.Projective {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,408 @@
+//go:build rocm
+
+package rocm
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+)
+
+// openDevice opens the ROCm device: the tests built with the rocm tag fail without one, so
+// that they can't pass without running the kernels.
+func openDevice(t *testing.T) accel.Device {
+	d, err := accel.OpenProvider(Name, ecc.BN254)
+	if err != nil {
+		t.Fatal("no ROCm device:", err)
+	}
+	return d
+}
+
+func openCPU(t *testing.T) accel.Device {
+	d, err := accel.OpenProvider(cpu.Name, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// scalarsToDevice copies the scalars to the device in regular form.
+func scalarsToDevice(t *testing.T, d accel.Device, scalars []fr.Element) unsafe.Pointer {
+	size := len(scalars) * fr.Bytes
+	p, err := d.Malloc(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = d.CopyToDevice(p, unsafe.Pointer(&scalars[0]), size); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.FromMontgomery(p, len(scalars)); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMsm(t *testing.T) {
+	for _, cfg := range []accel.PointsConfig{
+		{},
+		{Representation: accel.Projective},
+		{WithInfinity: true},
+		{Representation: accel.Projective, WithInfinity: true},
+	} {
+		t.Run(fmt.Sprintf("%s/infinity=%t", cfg.Representation, cfg.WithInfinity), func(t *testing.T) {
+			testMsm(t, cfg)
+		})
+	}
+}
+
+func testMsm(t *testing.T, cfg accel.PointsConfig) {
+	d := openDevice(t)
+	const n = 1000
+
+	scalars := make([]fr.Element, n)
+	for i := range scalars {
+		scalars[i].SetRandom()
+	}
+	_, _, g1, g2 := curve.Generators()
+	pointsG1 := curve.BatchScalarMultiplicationG1(&g1, scalars)
+	pointsG2 := curve.BatchScalarMultiplicationG2(&g2, scalars)
+	scalars[0].SetZero()
+	pointsG1[2], pointsG2[2] = pointsG1[1], pointsG2[1]
+	if cfg.WithInfinity {
+		pointsG1[3].X.SetZero()
+		pointsG1[3].Y.SetZero()
+		pointsG2[3].X.SetZero()
+		pointsG2[3].Y.SetZero()
+	}
+
+	scalars_d := scalarsToDevice(t, d, scalars)
+	defer d.Free(scalars_d)
+	msmCfg := accel.MsmConfig{Points: cfg}
+
+	pointsG1_d, err := d.PointsG1ToDevice(unsafe.Pointer(&pointsG1[0]), n, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(pointsG1_d)
+	var gotG1 curve.G1Jac
+	if err = d.Msm(unsafe.Pointer(&gotG1), scalars_d, pointsG1_d, n, msmCfg); err != nil {
+		t.Fatal(err)
+	}
+	var expectedG1 curve.G1Jac
+	if _, err = expectedG1.MultiExp(pointsG1, scalars, ecc.MultiExpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotG1.Equal(&expectedG1) {
+		t.Fatal("wrong MSM in G1")
+	}
+
+	pointsG2_d, err := d.PointsG2ToDevice(unsafe.Pointer(&pointsG2[0]), n, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(pointsG2_d)
+	var gotG2 curve.G2Jac
+	if err = d.MsmG2(unsafe.Pointer(&gotG2), scalars_d, pointsG2_d, n, msmCfg); err != nil {
+		t.Fatal(err)
+	}
+	var expectedG2 curve.G2Jac
+	if _, err = expectedG2.MultiExp(pointsG2, scalars, ecc.MultiExpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotG2.Equal(&expectedG2) {
+		t.Fatal("wrong MSM in G2")
+	}
+}
+
+func TestNtt(t *testing.T) {
+	d := openDevice(t)
+	const n = 1 << 10
+	domain := fft.NewDomain(n)
+
+	coefficients := make([]fr.Element, n)
+	for i := range coefficients {
+		coefficients[i].SetRandom()
+	}
+	expected := append([]fr.Element(nil), coefficients...)
+	domain.FFT(expected, fft.DIF, fft.OnCoset())
+	fft.BitReverse(expected)
+
+	twiddles, err := d.Twiddles(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddles)
+	twiddlesInv, err := d.Twiddles(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddlesInv)
+	coset := scalarsToDevice(t, d, domain.CosetTable)
+	defer d.Free(coset)
+	cosetInv := scalarsToDevice(t, d, domain.CosetTableInv)
+	defer d.Free(cosetInv)
+	in := scalarsToDevice(t, d, coefficients)
+	defer d.Free(in)
+	out, err := d.Malloc(n * fr.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(out)
+
+	// the evaluations on the coset, in regular form
+	if err = d.Ntt(out, in, twiddles, coset, n); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]fr.Element, n)
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), out, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if got[i] != fr.Element(expected[i].Bits()) {
+			t.Fatalf("wrong evaluation %d", i)
+		}
+	}
+
+	// and back to the coefficients
+	back, err := d.Intt(out, twiddlesInv, cosetInv, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(back)
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), back, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range coefficients {
+		if got[i] != fr.Element(coefficients[i].Bits()) {
+			t.Fatalf("wrong coefficient %d", i)
+		}
+	}
+}
+
+func TestQuotient(t *testing.T) {
+	d := openDevice(t)
+	q, ok := d.(accel.QuotientDevice)
+	if !ok {
+		t.Fatal("the device has no fused quotient")
+	}
+	const n = 1 << 10
+	domain := fft.NewDomain(n)
+
+	var vectors [3][]fr.Element
+	for i := range vectors {
+		vectors[i] = make([]fr.Element, n)
+		for j := range vectors[i] {
+			vectors[i][j].SetRandom()
+		}
+	}
+	den := make([]fr.Element, n)
+	for i := range den {
+		den[i].SetRandom()
+	}
+
+	twiddles, err := d.Twiddles(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddles)
+	twiddlesInv, err := d.Twiddles(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(twiddlesInv)
+	quotientDomain := accel.QuotientDomain{
+		Twiddles:       twiddles,
+		TwiddlesInv:    twiddlesInv,
+		CosetPowers:    scalarsToDevice(t, d, domain.CosetTable),
+		CosetPowersInv: scalarsToDevice(t, d, domain.CosetTableInv),
+		Den:            scalarsToDevice(t, d, den),
+	}
+	defer d.Free(quotientDomain.CosetPowers)
+	defer d.Free(quotientDomain.CosetPowersInv)
+	defer d.Free(quotientDomain.Den)
+
+	// the kernels one by one
+	var v [3]unsafe.Pointer
+	for i := range v {
+		v[i] = scalarsToDevice(t, d, vectors[i])
+		coefficients, err := d.Intt(v[i], twiddlesInv, nil, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = d.Ntt(v[i], coefficients, twiddles, quotientDomain.CosetPowers, n); err != nil {
+			t.Fatal(err)
+		}
+		_ = d.Free(coefficients)
+	}
+	if err = d.VecMul(v[0], v[1], n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.VecSub(v[0], v[2], n); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.VecMul(v[0], quotientDomain.Den, n); err != nil {
+		t.Fatal(err)
+	}
+	expected_d, err := d.Intt(v[0], twiddlesInv, quotientDomain.CosetPowersInv, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(expected_d)
+	if err = d.ReverseScalars(expected_d, n); err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]fr.Element, n)
+	if err = d.CopyToHost(unsafe.Pointer(&expected[0]), expected_d, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+
+	// and fused
+	for i := range v {
+		_ = d.Free(v[i])
+		v[i] = scalarsToDevice(t, d, vectors[i])
+		defer d.Free(v[i])
+	}
+	h_d, err := q.Quotient(v[0], v[1], v[2], quotientDomain, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Free(h_d)
+	got := make([]fr.Element, n)
+	if err = d.CopyToHost(unsafe.Pointer(&got[0]), h_d, n*fr.Bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("wrong coefficient %d", i)
+		}
+	}
+}
+
+// TestMsmAgainstCPU compares the multi-scalar multiplications of the kernels with the ones of
+// the cpu provider, on sizes below a chunk, around the chunk boundaries and over several
+// partial sums.
+func TestMsmAgainstCPU(t *testing.T) {
+	devices := []accel.Device{openDevice(t), openCPU(t)}
+	_, _, g1, g2 := curve.Generators()
+	for _, n := range []int{1, minChunk - 1, minChunk, minChunk + 1, 3*minChunk + 5, 1 << 12} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			scalars := make([]fr.Element, n)
+			for i := range scalars {
+				scalars[i].SetRandom()
+			}
+			pointsG1 := curve.BatchScalarMultiplicationG1(&g1, scalars)
+			pointsG2 := curve.BatchScalarMultiplicationG2(&g2, scalars)
+			for i := range scalars {
+				scalars[i].SetRandom()
+			}
+			cfg := accel.MsmConfig{Points: accel.PointsConfig{WithInfinity: true}}
+
+			var resG1 [2]curve.G1Jac
+			var resG2 [2]curve.G2Jac
+			for k, d := range devices {
+				scalars_d := scalarsToDevice(t, d, scalars)
+				pointsG1_d, err := d.PointsG1ToDevice(unsafe.Pointer(&pointsG1[0]), n, cfg.Points)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pointsG2_d, err := d.PointsG2ToDevice(unsafe.Pointer(&pointsG2[0]), n, cfg.Points)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err = d.Msm(unsafe.Pointer(&resG1[k]), scalars_d, pointsG1_d, n, cfg); err != nil {
+					t.Fatal(err)
+				}
+				if err = d.MsmG2(unsafe.Pointer(&resG2[k]), scalars_d, pointsG2_d, n, cfg); err != nil {
+					t.Fatal(err)
+				}
+				_ = d.Free(scalars_d)
+				_ = d.Free(pointsG1_d)
+				_ = d.Free(pointsG2_d)
+			}
+			if !resG1[0].Equal(&resG1[1]) {
+				t.Fatal("the MSM in G1 differs from the CPU")
+			}
+			if !resG2[0].Equal(&resG2[1]) {
+				t.Fatal("the MSM in G2 differs from the CPU")
+			}
+		})
+	}
+}
+
+// TestNttAgainstCPU compares the transforms of the kernels with the ones of the cpu provider,
+// on the domains of several sizes, with and without coset.
+func TestNttAgainstCPU(t *testing.T) {
+	devices := []accel.Device{openDevice(t), openCPU(t)}
+	for _, logN := range []int{1, 2, 5, 10, 14} {
+		n := 1 << logN
+		domain := fft.NewDomain(uint64(n))
+		coefficients := make([]fr.Element, n)
+		for i := range coefficients {
+			coefficients[i].SetRandom()
+		}
+		for _, onCoset := range []bool{false, true} {
+			t.Run(fmt.Sprintf("n=%d/coset=%t", n, onCoset), func(t *testing.T) {
+				var evaluations, back [2][]fr.Element
+				for k, d := range devices {
+					twiddles, err := d.Twiddles(n, false)
+					if err != nil {
+						t.Fatal(err)
+					}
+					twiddlesInv, err := d.Twiddles(n, true)
+					if err != nil {
+						t.Fatal(err)
+					}
+					var coset, cosetInv unsafe.Pointer
+					if onCoset {
+						coset = scalarsToDevice(t, d, domain.CosetTable)
+						cosetInv = scalarsToDevice(t, d, domain.CosetTableInv)
+					}
+					in := scalarsToDevice(t, d, coefficients)
+					out, err := d.Malloc(n * fr.Bytes)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if err = d.Ntt(out, in, twiddles, coset, n); err != nil {
+						t.Fatal(err)
+					}
+					evaluations[k] = make([]fr.Element, n)
+					if err = d.CopyToHost(unsafe.Pointer(&evaluations[k][0]), out, n*fr.Bytes); err != nil {
+						t.Fatal(err)
+					}
+					coefficients_d, err := d.Intt(out, twiddlesInv, cosetInv, n)
+					if err != nil {
+						t.Fatal(err)
+					}
+					back[k] = make([]fr.Element, n)
+					if err = d.CopyToHost(unsafe.Pointer(&back[k][0]), coefficients_d, n*fr.Bytes); err != nil {
+						t.Fatal(err)
+					}
+
+					for _, p := range []unsafe.Pointer{twiddles, twiddlesInv, coset, cosetInv, in, out, coefficients_d} {
+						if p != nil {
+							_ = d.Free(p)
+						}
+					}
+				}
+				for i := range evaluations[0] {
+					if evaluations[0][i] != evaluations[1][i] {
+						t.Fatalf("the evaluation %d differs from the CPU", i)
+					}
+				}
+				for i := range back[0] {
+					if back[0][i] != back[1][i] || back[0][i] != fr.Element(coefficients[i].Bits()) {
+						t.Fatalf("the coefficient %d differs from the CPU", i)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package accel
+
+import (
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+// Allocation is a live buffer of a device, see Track.
+type Allocation struct {
+	// Pointer is the address of the buffer on the device.
+	Pointer uintptr `json:"pointer"`
+
+	// Op is the method of the Device which allocated the buffer, for instance "Malloc" or
+	// "PointsG1ToDevice".
+	Op string `json:"op"`
+
+	// Size is the size of the buffer: a number of bytes for Malloc, of scalars or points for
+	// the other methods.
+	Size int `json:"size"`
+}
+
+// trackedDevice is a Device recording its live buffers.
+type trackedDevice struct {
+	Device
+
+	lock sync.Mutex
+	live map[unsafe.Pointer]Allocation
+}
+
+// Track returns the device, recording the buffers it allocates until they are freed, so that
+// Allocations lists them, for instance in the diagnostics of a failed proof. The overhead is a
+// map update per allocation.
+func Track(d Device) Device {
+	if _, ok := d.(*trackedDevice); ok {
+		return d
+	}
+	return &trackedDevice{Device: d, live: make(map[unsafe.Pointer]Allocation)}
+}
+
+// Allocations returns the live buffers of a device returned by Track, by address, or nil if the
+// device isn't tracked.
+func Allocations(d Device) []Allocation {
+	t, ok := d.(*trackedDevice)
+	if !ok {
+		return nil
+	}
+	t.lock.Lock()
+	res := make([]Allocation, 0, len(t.live))
+	for _, a := range t.live {
+		res = append(res, a)
+	}
+	t.lock.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Pointer < res[j].Pointer })
+	return res
+}
+
+// record adds the buffer p, if the allocation succeeded.
+func (t *trackedDevice) record(p unsafe.Pointer, err error, op string, size int) (unsafe.Pointer, error) {
+	if err == nil && p != nil {
+		t.lock.Lock()
+		t.live[p] = Allocation{Pointer: uintptr(p), Op: op, Size: size}
+		t.lock.Unlock()
+	}
+	return p, err
+}
+
+func (t *trackedDevice) Malloc(size int) (unsafe.Pointer, error) {
+	p, err := t.Device.Malloc(size)
+	return t.record(p, err, "Malloc", size)
+}
+
+func (t *trackedDevice) Free(p unsafe.Pointer) error {
+	t.lock.Lock()
+	delete(t.live, p)
+	t.lock.Unlock()
+	return t.Device.Free(p)
+}
+
+func (t *trackedDevice) Twiddles(n int, inverse bool) (unsafe.Pointer, error) {
+	p, err := t.Device.Twiddles(n, inverse)
+	return t.record(p, err, "Twiddles", n)
+}
+
+func (t *trackedDevice) Intt(in, twiddles, cosetPowers unsafe.Pointer, n int) (unsafe.Pointer, error) {
+	p, err := t.Device.Intt(in, twiddles, cosetPowers, n)
+	return t.record(p, err, "Intt", n)
+}
+
+func (t *trackedDevice) PointsG1ToDevice(points unsafe.Pointer, n int, cfg PointsConfig) (unsafe.Pointer, error) {
+	p, err := t.Device.PointsG1ToDevice(points, n, cfg)
+	return t.record(p, err, "PointsG1ToDevice", n)
+}
+
+func (t *trackedDevice) PointsG2ToDevice(points unsafe.Pointer, n int, cfg PointsConfig) (unsafe.Pointer, error) {
+	p, err := t.Device.PointsG2ToDevice(points, n, cfg)
+	return t.record(p, err, "PointsG2ToDevice", n)
+}
+
+// Quotient forwards to the device if it is a QuotientDevice.
+func (t *trackedDevice) Quotient(a, b, c unsafe.Pointer, domain QuotientDomain, n int) (unsafe.Pointer, error) {
+	q, ok := t.Device.(QuotientDevice)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	p, err := q.Quotient(a, b, c, domain, n)
+	return t.record(p, err, "Quotient", n)
+}
+
+// ScalarMulG1 forwards to the device if it is a ScalarMulDevice.
+func (t *trackedDevice) ScalarMulG1(points, scalars unsafe.Pointer, n int) error {
+	s, ok := t.Device.(ScalarMulDevice)
+	if !ok {
+		return ErrUnsupported
+	}
+	return s.ScalarMulG1(points, scalars, n)
+}
+
+// ScalarMulG2 forwards to the device if it is a ScalarMulDevice.
+func (t *trackedDevice) ScalarMulG2(points, scalars unsafe.Pointer, n int) error {
+	s, ok := t.Device.(ScalarMulDevice)
+	if !ok {
+		return ErrUnsupported
+	}
+	return s.ScalarMulG2(points, scalars, n)
+}
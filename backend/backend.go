@@ -15,7 +15,15 @@
 // Package backend implements Zero Knowledge Proof systems: it consumes circuit compiled with gnark/frontend.
 package backend
 
-import "github.com/consensys/gnark/constraint/solver"
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/consensys/gnark/constraint/solver"
+)
 
 // ID represent a unique ID for a proving scheme
 type ID uint16
@@ -54,6 +62,37 @@ type ProverOption func(*ProverConfig) error
 // ProverConfig is the configuration for the prover with the options applied.
 type ProverConfig struct {
 	SolverOpts []solver.Option
+
+	// Commitment is a curve-specific encoding of a commitment (and its proof of
+	// knowledge) computed outside of the prover. See WithCommitment.
+	Commitment []byte
+
+	// MultiExpNbTasks is the number of tasks of the CPU multi-exponentiation in G2. If
+	// zero, it is sized on the CPUs idle when it starts, or on all the CPUs on a device.
+	// See WithMultiExpNbTasks.
+	MultiExpNbTasks int
+
+	// Tracer receives the stages of the prover. See WithTracer.
+	Tracer Tracer
+
+	// Accelerator is the name of the accelerator provider the proof must run on, or the
+	// empty string for any. See WithAccelerator.
+	Accelerator string
+
+	// SparseMSM drops the zero scalars of the multi-scalar multiplications of the wire values,
+	// and GroupDuplicateScalars also sums the points of equal scalars. See WithSparseMSM.
+	SparseMSM, GroupDuplicateScalars bool
+
+	// UnsafeNoZK disables the blinding of the proofs. See WithUnsafeNoZK.
+	UnsafeNoZK bool
+
+	// DeviceMemoryLimit is the device memory a proof may need, in bytes, or zero for no limit.
+	// See WithDeviceMemoryLimit.
+	DeviceMemoryLimit uint64
+
+	// CheckpointDir is the directory of the intermediate results of the proof, or the empty
+	// string for none. See WithCheckpoint.
+	CheckpointDir string
 }
 
 // NewProverConfig returns a default ProverConfig with given prover options opts
@@ -75,3 +114,293 @@ func WithSolverOptions(solverOpts ...solver.Option) ProverOption {
 		return nil
 	}
 }
+
+// WithCommitment makes the prover use a commitment to the private committed wires
+// which was computed outside of Prove (for example by a witness generator holding
+// the commitment key), instead of computing it itself. The encoding is curve
+// specific; use the helpers of the curve-typed backend packages to build it.
+//
+// The prover does not re-open the commitment: if it doesn't match the witness, the
+// resulting proof will not verify. Only the groth16 prover of BN254 supports it: the
+// other provers return ErrNotSupported.
+func WithCommitment(commitment []byte) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Commitment = commitment
+		return nil
+	}
+}
+
+// maxMultiExpNbTasks is the largest number of tasks of the multi-exponentiations of
+// gnark-crypto.
+const maxMultiExpNbTasks = 1024
+
+// WithMultiExpNbTasks sets the number of tasks of the CPU multi-exponentiation
+// in G2 of the groth16 prover, which runs alongside the ones in G1.
+//
+// By default (nbTasks == 0), the number of tasks is sized on the CPUs left idle
+// by the rest of the prover (including GPU work) when the multi-exponentiation
+// of the first proof of the process starts. nbTasks can't exceed the 1024 tasks
+// of the multi-exponentiations of gnark-crypto, whichever the accelerator.
+//
+// The provers of BN254 and BLS12-377 run the multi-exponentiations on the device
+// of the proving key, one after the other, so that the one in G2 runs on all the
+// CPUs by default. On the CPU emulation, the option sets its number of tasks; the
+// devices running it on a GPU don't support the option, and Prove returns an
+// error wrapping accel.ErrUnsupported before solving the constraint system.
+func WithMultiExpNbTasks(nbTasks int) ProverOption {
+	return func(opt *ProverConfig) error {
+		if nbTasks < 0 || nbTasks > maxMultiExpNbTasks {
+			return fmt.Errorf("invalid number of tasks %d, not in [0, %d]", nbTasks, maxMultiExpNbTasks)
+		}
+		opt.MultiExpNbTasks = nbTasks
+		return nil
+	}
+}
+
+// WithAccelerator requires the proof to run on the named accelerator provider of the
+// backend/accel registry, for instance "icicle" for the CUDA GPUs or "cpu" for the CPU
+// emulation. The providers are built with their build tags (icicle, rocm), so that the same
+// code yields CPU-only and GPU binaries, and selected at run time.
+//
+// The device copies of the proving keys of the GPU provers are made when the key is set up or
+// read, on the provider of the GNARK_ACCEL environment variable, or the first one registered
+// other than the CPU emulation, which is only selected by name; without either, the keys have
+// no device copies and are proved on the CPU, unless this option names a provider. The prover
+// returns an error if the key is on another provider (see the SetAccelerator method of the
+// proving keys). The BLS24-315 prover, which has no device copies, opens the named provider at
+// each proof. Provers without accelerator ignore this option.
+func WithAccelerator(name string) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Accelerator = name
+		return nil
+	}
+}
+
+// WithSparseMSM makes the GPU provers remove the zero wire values, and their points, from
+// the multi-scalar multiplications of the wire values, which often have long runs of zeros.
+// If groupDuplicates is set, the points of the small wire values (less than 2^64) that are
+// equal, typically the booleans and the bytes, are also summed, so that each of these values
+// is multiplied once.
+//
+// The points are then selected on the host and copied to the device at each proof, instead of
+// using the copies made with the proving key: this pays off when the multi-scalar
+// multiplications are much smaller. Provers without accelerator ignore this option.
+func WithSparseMSM(groupDuplicates bool) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.SparseMSM = true
+		opt.GroupDuplicateScalars = groupDuplicates
+		return nil
+	}
+}
+
+// WithUnsafeNoZK makes the groth16 GPU provers use r = s = 0 instead of random blinding
+// factors, so that the proofs of a witness are deterministic, for benchmarking and for comparing
+// the proofs of different provers or accelerators byte for byte.
+//
+// UNSAFE: the proofs are then not zero-knowledge, they leak information about the secret
+// witness. Never use this option in production. Provers without accelerator ignore it, and
+// blind the proofs.
+func WithUnsafeNoZK() ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.UnsafeNoZK = true
+		return nil
+	}
+}
+
+// WithDeviceMemoryLimit makes the GPU provers return an error, before the proof starts, if the
+// proof needs more than limit bytes of device memory, the device copies of the proving key
+// included (see the DeviceMemoryEstimate methods of the proving keys). It shares a device
+// between processes, or a budget between keys, without running out of memory in the middle of
+// a proof. Provers without accelerator ignore this option.
+func WithDeviceMemoryLimit(limit uint64) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.DeviceMemoryLimit = limit
+		return nil
+	}
+}
+
+// WithCheckpoint makes the groth16 GPU provers save the intermediate results of the proof to
+// dir: the solution of the constraint system, with the commitments, and the results of the
+// multi-scalar multiplications before the blinding. A proof of the same witness, circuit and
+// proving key with the same dir, for instance after the prover process was killed, resumes from
+// them instead of solving and multiplying again; the results of another proof are removed, and
+// so are all the results once the proof is computed. The blinding factors are sampled again,
+// so that a resumed proof is as zero-knowledge as the others.
+//
+// The results are saved once per multi-scalar multiplication, not per chunk of one: a proof
+// killed in the middle of a multiplication computes it again from the start on resume. The
+// quotient, which is computed from the solution, isn't saved either: it is computed again
+// unless its multiplication was saved.
+//
+// The solution holds the secret witness and is about as large as the proving key: dir must be
+// on a disk with enough room, protected like the witnesses, and used by one proof at a time.
+// The failures to save the results are logged, and the proof goes on without them. Provers
+// without accelerator ignore this option.
+func WithCheckpoint(dir string) ProverOption {
+	return func(opt *ProverConfig) error {
+		if dir == "" {
+			return errors.New("empty checkpoint directory")
+		}
+		opt.CheckpointDir = dir
+		return nil
+	}
+}
+
+// VerifierOption defines option for altering the behavior of the verifiers. See the descriptions
+// of functions returning instances of this type for implemented options.
+type VerifierOption func(*VerifierConfig) error
+
+// VerifierConfig is the configuration for the verifiers with the options applied.
+type VerifierConfig struct {
+	Strict bool
+}
+
+// NewVerifierConfig returns a default VerifierConfig with given verifier options opts applied.
+func NewVerifierConfig(opts ...VerifierOption) (VerifierConfig, error) {
+	opt := VerifierConfig{}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return VerifierConfig{}, err
+		}
+	}
+	return opt, nil
+}
+
+// WithStrictVerification makes the groth16 BN254 verifier reject the proofs whose points aren't
+// canonical, on top of the usual checks: the coordinates must be reduced modulo the base field
+// modulus, and the points other than the proofs of knowledge of the commitments must not be
+// the point at infinity. The proofs of Prove, and the proofs decoded by ReadFrom, always pass
+// unless a point is at infinity, which only happens with a negligible probability; the check
+// is meant for the proofs built from raw memory or by other provers. The other verifiers
+// return ErrNotSupported.
+func WithStrictVerification() VerifierOption {
+	return func(opt *VerifierConfig) error {
+		opt.Strict = true
+		return nil
+	}
+}
+
+// Sampler is a source of randomness for the secrets sampled by a trusted setup (the
+// "toxic waste"). Implementations may for example be backed by a HSM or derive the
+// randomness from a MPC ceremony.
+type Sampler interface {
+	io.Reader
+
+	// Name identifies the source of randomness in the setup log.
+	Name() string
+}
+
+// SetupOption defines option for altering the behavior of the setup. See the
+// descriptions of functions returning instances of this type for implemented
+// options.
+type SetupOption func(*SetupConfig) error
+
+// SetupConfig is the configuration for the setup with the options applied.
+type SetupConfig struct {
+	Sampler      Sampler
+	Reproducible bool
+	RecordTime   bool
+
+	// Accelerator is the name of the accelerator provider of the device copies of the proving
+	// key, or the empty string for the default one. See WithSetupAccelerator.
+	Accelerator string
+}
+
+// NewSetupConfig returns a default SetupConfig with given setup options opts
+// applied. By default, the secrets are sampled from crypto/rand.
+func NewSetupConfig(opts ...SetupOption) (SetupConfig, error) {
+	opt := SetupConfig{Sampler: DefaultSampler()}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupConfig{}, err
+		}
+	}
+	if _, ok := opt.Sampler.(cryptoSampler); ok && opt.Reproducible {
+		return SetupConfig{}, errors.New("a reproducible setup needs a deterministic sampler")
+	}
+	if opt.Reproducible && opt.RecordTime {
+		return SetupConfig{}, errors.New("a reproducible setup can't record its time")
+	}
+	return opt, nil
+}
+
+// WithSampler specifies the source of randomness of the setup secrets.
+func WithSampler(sampler Sampler) SetupOption {
+	return func(opt *SetupConfig) error {
+		opt.Sampler = sampler
+		return nil
+	}
+}
+
+// WithSetupAccelerator makes the device copies of the proving key on the named accelerator
+// provider of the backend/accel registry, instead of the provider of the GNARK_ACCEL
+// environment variable or the first one registered other than the CPU emulation, so that
+// WithAccelerator can require it.
+func WithSetupAccelerator(name string) SetupOption {
+	return func(opt *SetupConfig) error {
+		opt.Accelerator = name
+		return nil
+	}
+}
+
+// WithReproducible makes the setup output byte-identical across runs and machines, given
+// the same constraint system and the same randomness, and records it in the setup log. The
+// sampler must be deterministic (for instance derived from a ceremony transcript), so this
+// option can't be used with the default sampler.
+func WithReproducible() SetupOption {
+	return func(opt *SetupConfig) error {
+		opt.Reproducible = true
+		return nil
+	}
+}
+
+// WithSetupTime records the time of the setup in the setup log. The time is serialized with
+// the verifying key, so that two setups with the same randomness then give different keys;
+// it can't be used with WithReproducible.
+func WithSetupTime() SetupOption {
+	return func(opt *SetupConfig) error {
+		opt.RecordTime = true
+		return nil
+	}
+}
+
+// Log returns the audit record of a setup performed with this configuration.
+func (cfg *SetupConfig) Log() SetupLog {
+	log := SetupLog{
+		Sampler:      cfg.Sampler.Name(),
+		Reproducible: cfg.Reproducible,
+	}
+	if cfg.RecordTime {
+		log.Time = time.Now().UTC()
+	}
+	return log
+}
+
+// SetupLog is the audit record of a setup, stored in the verifying key metadata. It is
+// informative only and not used by the verifier.
+type SetupLog struct {
+	// Sampler is the name of the source of randomness of the setup secrets.
+	Sampler string
+
+	// Time at which the setup was performed, zero unless the setup was performed with
+	// WithSetupTime.
+	Time time.Time
+
+	// Reproducible is true if the setup was performed with WithReproducible.
+	Reproducible bool
+}
+
+// DefaultSampler returns a Sampler reading from crypto/rand.
+func DefaultSampler() Sampler {
+	return cryptoSampler{}
+}
+
+type cryptoSampler struct{}
+
+func (cryptoSampler) Read(p []byte) (int, error) {
+	return rand.Read(p)
+}
+
+func (cryptoSampler) Name() string {
+	return "crypto/rand"
+}
@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"errors"
+	"hash"
+)
+
+// The options of this file mirror the options of the later versions of upstream gnark, so that
+// the code written for them builds against this fork. They map to the options of this fork, or
+// return an error when the setting isn't supported, rather than ignoring it.
+
+// ErrNotSupported is returned by the options of upstream gnark this fork doesn't implement.
+var ErrNotSupported = errors.New("option not supported by this version of gnark")
+
+// WithIcicleAcceleration requires the proof to run on the CUDA GPUs, like the option of upstream
+// gnark. It is WithAccelerator("icicle"): the binary must be built with the icicle build tag.
+//
+// Deprecated: use WithAccelerator, which also selects the other providers.
+func WithIcicleAcceleration() ProverOption {
+	return WithAccelerator("icicle")
+}
+
+// WithProverHashToFieldFunction is the option of upstream gnark setting the hash of the
+// commitments to the field. The commitment wires of this fork are derived from the commitments
+// by a fixed hash, which the verifiers and the Solidity contracts rely on, so the option returns
+// ErrNotSupported.
+func WithProverHashToFieldFunction(hFunc hash.Hash) ProverOption {
+	return func(*ProverConfig) error {
+		return ErrNotSupported
+	}
+}
+
+// WithVerifierHashToFieldFunction is the verifier counterpart of WithProverHashToFieldFunction,
+// and returns ErrNotSupported too.
+func WithVerifierHashToFieldFunction(hFunc hash.Hash) VerifierOption {
+	return func(*VerifierConfig) error {
+		return ErrNotSupported
+	}
+}
@@ -0,0 +1,183 @@
+// Package envelope wraps serialized proofs in a signed envelope attributing them to a prover.
+//
+// In a decentralized prover market, the aggregators receive proofs from many provers. The
+// envelope carries the serialized proof with metadata on how it was produced (the device, the
+// software versions, the timings of the prover stages) and the signature of the prover's
+// identity key over both, so that an aggregator can attribute each proof and audit the
+// claims of its prover. The envelope doesn't change the proof: a verifier ignoring it verifies
+// the proof as usual.
+//
+// The metadata may also route the proof to its verifying key, with the ID of its circuit, the
+// hash of the key and the public witness, see groth16.EnvelopeMetadata and
+// groth16.VerifyEnvelope.
+package envelope
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/consensys/gnark"
+)
+
+// signatureDomain separates the envelope signatures from other uses of the identity key.
+const signatureDomain = "gnark proof envelope v1\x00"
+
+// Metadata describes how a proof was produced, as claimed by its prover.
+type Metadata struct {
+	// Backend and Curve identify the proof system, for instance "groth16" and "bn254".
+	Backend string `json:"backend"`
+	Curve   string `json:"curve"`
+
+	// Device is the model of the accelerator which computed the proof, empty for the CPU.
+	Device string `json:"device,omitempty"`
+
+	// Versions are the versions of the software stack, by component. NewMetadata sets the
+	// gnark version.
+	Versions map[string]string `json:"versions,omitempty"`
+
+	// Timings are the durations of the prover stages, see Timings.
+	Timings []StageTiming `json:"timings,omitempty"`
+
+	// CircuitID identifies the circuit of the proof, for the verifiers holding the keys of
+	// several circuits.
+	CircuitID string `json:"circuitId,omitempty"`
+
+	// VerifyingKeyHash is the hash of the verifying key of the proof, so that a proof for
+	// another version of the key is detected before the pairing check.
+	VerifyingKeyHash []byte `json:"verifyingKeyHash,omitempty"`
+
+	// PublicWitness is the public witness of the proof, encoded with witness.MarshalBinary.
+	PublicWitness []byte `json:"publicWitness,omitempty"`
+
+	// Created is the time the proof was sealed.
+	Created time.Time `json:"created"`
+}
+
+// NewMetadata returns the metadata of a proof of the backend on the curve, with the gnark
+// version.
+func NewMetadata(backend, curve string) Metadata {
+	return Metadata{
+		Backend:  backend,
+		Curve:    curve,
+		Versions: map[string]string{"gnark": gnark.Version.String()},
+	}
+}
+
+// Envelope is a serialized proof signed by its prover.
+type Envelope struct {
+	Proof     []byte            `json:"proof"`
+	Metadata  Metadata          `json:"metadata"`
+	ProverKey ed25519.PublicKey `json:"proverKey"`
+	Signature []byte            `json:"signature"`
+}
+
+// Seal serializes the proof and signs it with the metadata using the identity key of the
+// prover. The creation time of the metadata is set to now if it is zero.
+func Seal(proof io.WriterTo, meta Metadata, key ed25519.PrivateKey) (*Envelope, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid prover key")
+	}
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("serialize proof: %w", err)
+	}
+	if meta.Created.IsZero() {
+		meta.Created = time.Now().UTC()
+	}
+
+	e := &Envelope{
+		Proof:     buf.Bytes(),
+		Metadata:  meta,
+		ProverKey: key.Public().(ed25519.PublicKey),
+	}
+	msg, err := e.signedMessage()
+	if err != nil {
+		return nil, err
+	}
+	e.Signature = ed25519.Sign(key, msg)
+	return e, nil
+}
+
+// Verify checks the signature of the envelope. It doesn't verify the proof, nor that the
+// prover key is one the caller trusts.
+func (e *Envelope) Verify() error {
+	if len(e.ProverKey) != ed25519.PublicKeySize {
+		return errors.New("invalid prover key")
+	}
+	msg, err := e.signedMessage()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(e.ProverKey, msg, e.Signature) {
+		return errors.New("invalid envelope signature")
+	}
+	return nil
+}
+
+// Open checks the signature of the envelope and deserializes the proof into proof.
+func (e *Envelope) Open(proof io.ReaderFrom) error {
+	if err := e.Verify(); err != nil {
+		return err
+	}
+	if _, err := proof.ReadFrom(bytes.NewReader(e.Proof)); err != nil {
+		return fmt.Errorf("deserialize proof: %w", err)
+	}
+	return nil
+}
+
+// signedMessage returns the message signed by the prover: the proof, the metadata and the
+// prover key, JSON encoded after the signature domain. The JSON encoding of the envelope is
+// deterministic, as the fields are encoded in order and the maps sorted by key.
+func (e *Envelope) signedMessage() ([]byte, error) {
+	unsigned := *e
+	unsigned.Signature = nil
+	b, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(signatureDomain), b...), nil
+}
+
+// WriteTo writes the envelope as JSON.
+func (e *Envelope) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom reads an envelope written by WriteTo. It doesn't check the signature, see Verify.
+func (e *Envelope) ReadFrom(r io.Reader) (int64, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(b)), err
+	}
+	return int64(len(b)), json.Unmarshal(b, e)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler: the encoding is the one of WriteTo.
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The encoding is canonical: it
+// rejects the encodings which MarshalBinary wouldn't return, so that an envelope has a single
+// encoding. It doesn't check the signature, see Verify.
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	var res Envelope
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if canonical, err := res.MarshalBinary(); err != nil || !bytes.Equal(canonical, data) {
+		return errors.New("non canonical envelope")
+	}
+	*e = res
+	return nil
+}
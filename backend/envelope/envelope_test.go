@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/backend"
+)
+
+// rawProof is a proof serialized as its bytes.
+type rawProof []byte
+
+func (p rawProof) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(p)
+	return int64(n), err
+}
+
+func (p *rawProof) ReadFrom(r io.Reader) (int64, error) {
+	b, err := io.ReadAll(r)
+	*p = b
+	return int64(len(b)), err
+}
+
+func TestEnvelope(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var timings Timings
+	_, end := backend.TraceStage(context.Background(), &timings, backend.StageMSMG1, backend.StageMetadata{Label: "KRS", Size: 8})
+	end()
+
+	meta := NewMetadata("groth16", "bn254")
+	meta.Device = "test device"
+	meta.Timings = timings.Stages()
+	if len(meta.Timings) != 1 || meta.Timings[0].Stage != "MSM G1" || meta.Timings[0].Label != "KRS" {
+		t.Fatalf("unexpected timings %v", meta.Timings)
+	}
+
+	e, err := Seal(rawProof("proof"), meta, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip
+	var buf bytes.Buffer
+	if _, err := e.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var read Envelope
+	if _, err := read.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var proof rawProof
+	if err := read.Open(&proof); err != nil {
+		t.Fatal(err)
+	}
+	if string(proof) != "proof" || read.Metadata.Device != "test device" || read.Metadata.Versions["gnark"] == "" {
+		t.Fatalf("unexpected envelope content %+v", read)
+	}
+
+	// tampering with the proof or the metadata invalidates the signature
+	tampered := read
+	tampered.Proof = []byte("other proof")
+	if err := tampered.Verify(); err == nil {
+		t.Fatal("expected an invalid signature on the proof")
+	}
+	tampered = read
+	tampered.Metadata.Timings = []StageTiming{{Stage: "MSM G1", Duration: time.Nanosecond}}
+	if err := tampered.Verify(); err == nil {
+		t.Fatal("expected an invalid signature on the metadata")
+	}
+}
@@ -0,0 +1,53 @@
+package envelope
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark/backend"
+)
+
+// StageTiming is the duration of a prover stage.
+type StageTiming struct {
+	Stage    string        `json:"stage"`
+	Label    string        `json:"label,omitempty"`
+	Size     int           `json:"size,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Timings is a backend.Tracer recording the durations of the prover stages, for the metadata
+// of an envelope. It is safe for concurrent use.
+//
+//	var timings envelope.Timings
+//	proof, err := groth16.Prove(ccs, pk, w, backend.WithTracer(&timings))
+//	meta := envelope.NewMetadata("groth16", "bn254")
+//	meta.Timings = timings.Stages()
+type Timings struct {
+	lock   sync.Mutex
+	stages []StageTiming
+}
+
+// OnStageStart implements backend.Tracer.
+func (t *Timings) OnStageStart(ctx context.Context, _ backend.Stage, _ backend.StageMetadata) context.Context {
+	return ctx
+}
+
+// OnStageEnd implements backend.Tracer.
+func (t *Timings) OnStageEnd(_ context.Context, stage backend.Stage, meta backend.StageMetadata, elapsed time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.stages = append(t.stages, StageTiming{
+		Stage:    stage.String(),
+		Label:    meta.Label,
+		Size:     meta.Size,
+		Duration: elapsed,
+	})
+}
+
+// Stages returns the recorded timings, in the order the stages ended.
+func (t *Timings) Stages() []StageTiming {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]StageTiming(nil), t.stages...)
+}
@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
@@ -237,6 +235,9 @@ func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 // ReadFrom attempts to decode a ProvingKey from reader
 // ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 // note that we don't check that the points are on the curve or in the correct subgroup at this point
+//
+// The key is copied to the device at its first proof, or by SetAccelerator, and its previous
+// device copies, if any, are released.
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 	return pk.readFrom(r)
 }
@@ -248,6 +249,8 @@ func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
 }
 
 func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	pk.releaseDevice()
+
 	n, err := pk.Domain.ReadFrom(r)
 	if err != nil {
 		return n, err
@@ -290,7 +293,5 @@ func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder))
 
 	size := n + dec.BytesRead()
 
-	pk.setupDevicePointers()
-
 	return size, nil
 }
@@ -0,0 +1,118 @@
+package groth16
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+// sparseScalars are the scalars of a multi-scalar multiplication without the zeros, and with
+// the duplicates grouped: the points of the scalars of a group are summed, so that the
+// multi-scalar multiplication of the groups is the one of the scalars, see backend.WithSparseMSM.
+type sparseScalars struct {
+	// scalars has the scalar of each group
+	scalars []fr.Element
+
+	// group is the group of each scalar, or -1 if it is dropped
+	group []int32
+}
+
+// newSparseScalars groups the scalars. A zero scalar is dropped, and if groupDuplicates is set,
+// the scalars less than 2^64, much more often equal than the others, are grouped by value.
+func newSparseScalars(values []fr.Element, groupDuplicates bool) *sparseScalars {
+	s := &sparseScalars{group: make([]int32, len(values))}
+	var groups map[uint64]int32
+	if groupDuplicates {
+		groups = make(map[uint64]int32)
+	}
+	for i := range values {
+		if values[i].IsZero() {
+			s.group[i] = -1
+			continue
+		}
+		if groups != nil && values[i].IsUint64() {
+			v := values[i].Uint64()
+			if g, ok := groups[v]; ok {
+				s.group[i] = g
+				continue
+			}
+			groups[v] = int32(len(s.scalars))
+		}
+		s.group[i] = int32(len(s.scalars))
+		s.scalars = append(s.scalars, values[i])
+	}
+	return s
+}
+
+// pointsG1 returns the point of each group, the sum of the points of its scalars.
+func (s *sparseScalars) pointsG1(points []curve.G1Affine) []curve.G1Affine {
+	res := make([]curve.G1Affine, len(s.scalars))
+	seen := make([]bool, len(s.scalars))
+	sums := make(map[int32]*curve.G1Jac)
+	for i, g := range s.group {
+		switch {
+		case g < 0:
+		case !seen[g]:
+			res[g], seen[g] = points[i], true
+		case sums[g] == nil:
+			sums[g] = new(curve.G1Jac).FromAffine(&res[g])
+			sums[g].AddMixed(&points[i])
+		default:
+			sums[g].AddMixed(&points[i])
+		}
+	}
+	for g, sum := range sums {
+		res[g].FromJacobian(sum)
+	}
+	return res
+}
+
+// pointsG2 is pointsG1 in G2.
+func (s *sparseScalars) pointsG2(points []curve.G2Affine) []curve.G2Affine {
+	res := make([]curve.G2Affine, len(s.scalars))
+	seen := make([]bool, len(s.scalars))
+	sums := make(map[int32]*curve.G2Jac)
+	for i, g := range s.group {
+		switch {
+		case g < 0:
+		case !seen[g]:
+			res[g], seen[g] = points[i], true
+		case sums[g] == nil:
+			sums[g] = new(curve.G2Jac).FromAffine(&res[g])
+			sums[g].AddMixed(&points[i])
+		default:
+			sums[g].AddMixed(&points[i])
+		}
+	}
+	for g, sum := range sums {
+		res[g].FromJacobian(sum)
+	}
+	return res
+}
+
+// removeInfinity drops the groups whose point is at infinity, as the devices expect, and
+// returns the remaining points. The points at infinity are the ones of the proving key, and
+// the sums which cancel out. The points of B in G1 and G2 being the same multiples of the
+// generators, the groups at infinity are the same in G2, whose points are taken afterwards.
+func (s *sparseScalars) removeInfinity(points []curve.G1Affine) []curve.G1Affine {
+	index := make([]int32, len(points))
+	n := 0
+	for g := range points {
+		if points[g].IsInfinity() {
+			index[g] = -1
+			continue
+		}
+		index[g] = int32(n)
+		s.scalars[n], points[n] = s.scalars[g], points[g]
+		n++
+	}
+	if n == len(points) {
+		return points
+	}
+	for i, g := range s.group {
+		if g >= 0 {
+			s.group[i] = index[g]
+		}
+	}
+	s.scalars = s.scalars[:n]
+	return points[:n]
+}
@@ -12,24 +12,23 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint/bls12-377"
 	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/logger"
-	"github.com/ingonyama-zk/icicle/goicicle"
-	icicle "github.com/ingonyama-zk/icicle/goicicle/curves/bls12377"
 	"math/big"
-	"time"
+	"sync"
 	"unsafe"
 )
 
@@ -52,29 +51,71 @@ func (proof *Proof) CurveID() ecc.ID {
 	return curve.ID
 }
 
-const BUCKET_FACTOR int = 10
-
 // Prove generates the proof of knowledge of a r1cs with full witness (secret + public part).
 func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (*Proof, error) {
 	opt, err := backend.NewProverConfig(opts...)
 	if err != nil {
 		return nil, err
 	}
+	if opt.Commitment != nil {
+		return nil, fmt.Errorf("%w: precomputed commitment on %s", backend.ErrNotSupported, curve.ID)
+	}
+	if opt.DeviceMemoryLimit != 0 {
+		if resident, proof := pk.DeviceMemoryEstimate(r1cs); resident+proof > opt.DeviceMemoryLimit {
+			return nil, fmt.Errorf("the proof needs %d bytes of device memory, more than the limit of %d: see DeviceMemoryEstimate", resident+proof, opt.DeviceMemoryLimit)
+		}
+	}
+	// a key read by ReadFrom is copied to the device at its first proof
+	if err := pk.ensureDevice(opt.Accelerator); err != nil {
+		return nil, err
+	}
+	if opt.Accelerator != "" && opt.Accelerator != pk.accelerator {
+		return nil, fmt.Errorf("the proving key is on the %s accelerator, not %s: see SetAccelerator", pk.accelerator, opt.Accelerator)
+	}
+	if err := checkMultiExpNbTasks(pk.device, opt.MultiExpNbTasks); err != nil {
+		return nil, err
+	}
+	commitmentInfo, err := r1cs.CommitmentInfo.Single()
+	if err != nil {
+		return nil, err
+	}
 
 	log := logger.Logger().With().Str("curve", r1cs.CurveID().String()).Int("nbConstraints", r1cs.GetNbConstraints()).Str("backend", "groth16").Logger()
 
+	tracer := opt.Tracer
+	if tracer == nil {
+		tracer = backend.LogTracer(log)
+	}
+	ctx, endProve := backend.TraceStage(context.Background(), tracer, backend.StageProve, backend.StageMetadata{Size: r1cs.GetNbConstraints()})
+	defer endProve()
+
+	// trace reports the start of a stage of the proof, and returns the function reporting its end
+	trace := func(stage backend.Stage, label string, size int) func() {
+		_, end := backend.TraceStage(ctx, tracer, stage, backend.StageMetadata{Label: label, Size: size})
+		return end
+	}
+
 	proof := &Proof{}
 
+	var ckpt *proofCheckpoint
+	if opt.CheckpointDir != "" {
+		if ckpt, err = openCheckpoint(opt.CheckpointDir, r1cs, pk, fullWitness, log); err != nil {
+			return nil, err
+		}
+	}
+
 	solverOpts := opt.SolverOpts[:len(opt.SolverOpts):len(opt.SolverOpts)]
 
-	if r1cs.CommitmentInfo.Is() {
-		solverOpts = append(solverOpts, solver.OverrideHint(r1cs.CommitmentInfo.HintID, func(_ *big.Int, in []*big.Int, out []*big.Int) error {
+	if commitmentInfo.Is() {
+		solverOpts = append(solverOpts, solver.OverrideHint(commitmentInfo.HintID, func(_ *big.Int, in []*big.Int, out []*big.Int) error {
 			// Perf-TODO: Converting these values to big.Int and back may be a performance bottleneck.
 			// If that is the case, figure out a way to feed the solution vector into this function
-			if len(in) != r1cs.CommitmentInfo.NbCommitted() { // TODO: Remove
+			// the first input is the index of the commitment
+			in = in[1:]
+			if len(in) != commitmentInfo.NbCommitted() { // TODO: Remove
 				return fmt.Errorf("unexpected number of committed variables")
 			}
-			values := make([]fr.Element, r1cs.CommitmentInfo.NbPrivateCommitted)
+			values := make([]fr.Element, commitmentInfo.NbPrivateCommitted)
 			nbPublicCommitted := len(in) - len(values)
 			inPrivate := in[nbPublicCommitted:]
 			for i, inI := range inPrivate {
@@ -88,85 +129,151 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 			}
 
 			var res fr.Element
-			res, err = solveCommitmentWire(&r1cs.CommitmentInfo, &proof.Commitment, in[:r1cs.CommitmentInfo.NbPublicCommitted()])
+			res, err = solveCommitmentWire(&commitmentInfo, &proof.Commitment, in[:commitmentInfo.NbPublicCommitted()])
 			res.BigInt(out[0])
 			return err
 		}))
 	}
 
-	_solution, err := r1cs.Solve(fullWitness, solverOpts...)
-	if err != nil {
-		return nil, err
+	solution := &cs.R1CSSolution{}
+	if !ckpt.loadSolution(solution, proof) {
+		endSolve := trace(backend.StageSolve, "", r1cs.GetNbConstraints())
+		_solution, err := r1cs.Solve(fullWitness, solverOpts...)
+		endSolve()
+		if err != nil {
+			return nil, err
+		}
+		solution = _solution.(*cs.R1CSSolution)
+		ckpt.saveSolution(solution, proof)
 	}
-
-	solution := _solution.(*cs.R1CSSolution)
+	// the wire values, which may be spilled to disk, are released on return, once the
+	// goroutines of wireReaders are done reading them
+	var wireReaders sync.WaitGroup
+	defer func() {
+		wireReaders.Wait()
+		_ = solution.Release()
+	}()
 	wireValues := []fr.Element(solution.W)
 
-	start := time.Now()
+	device := pk.device
+	if device == nil {
+		// no accelerator was selected for the key: see setupDevicePointers
+		if err := proveCPU(r1cs, &commitmentInfo, pk, solution, proof, &wireReaders, opt, log, trace); err != nil {
+			return nil, err
+		}
+		ckpt.remove()
+		return proof, nil
+	}
 
-	// H (witness reduction / FFT part)
+	// H (witness reduction / FFT part), only needed by the multi exp of KRS2
 	var h unsafe.Pointer
+	var errH error
 	chHDone := make(chan struct{}, 1)
-	go func() {
-		h = computeH(solution.A, solution.B, solution.C, pk)
-		solution.A = nil
-		solution.B = nil
-		solution.C = nil
+	var krs2 curve.G1Jac
+	krs2Done := ckpt.loadG1("krs2", &krs2)
+	if krs2Done {
 		chHDone <- struct{}{}
-	}()
+	} else {
+		go func() {
+			h, errH = computeH(ctx, tracer, solution, pk)
+			chHDone <- struct{}{}
+		}()
+	}
 
 	// we need to copy and filter the wireValues for each multi exp
-	// as pk.G1.A, pk.G1.B and pk.G2.B may have (a significant) number of point at infinity
+	// as pk.G1.A, pk.G1.B and pk.G2.B may have (a significant) number of point at infinity,
+	// unless the device layout keeps them
 	var wireValuesADevice, wireValuesBDevice OnDeviceData
+	var errWireValuesA, errWireValuesB error
 	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
 
+	// the points of the multi exps of the wire values, replaced by the points of the sparse
+	// wire values with backend.WithSparseMSM
+	pointsA, layoutA := pk.G1Device.A, pk.deviceLayout.A
+	pointsB, layoutB := pk.G1Device.B, pk.deviceLayout.B
+	pointsG2B, layoutG2B := pk.G2Device.B, pk.deviceLayout.G2B
+	if opt.SparseMSM {
+		layoutA = accel.PointsConfig{Representation: layoutA.Representation}
+		layoutB = accel.PointsConfig{Representation: layoutB.Representation}
+		layoutG2B = accel.PointsConfig{Representation: layoutG2B.Representation}
+	}
+
+	wireReaders.Add(2)
 	go func() {
-		wireValuesA := make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
-		for i, j := 0, 0; j < len(wireValuesA); i++ {
-			if pk.InfinityA[i] {
-				continue
+		defer wireReaders.Done()
+		wireValuesA := wireValues
+		if opt.SparseMSM || !pk.deviceLayout.A.WithInfinity {
+			wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+			for i, j := 0, 0; j < len(wireValuesA); i++ {
+				if pk.InfinityA[i] {
+					continue
+				}
+				wireValuesA[j] = wireValues[i]
+				j++
 			}
-			wireValuesA[j] = wireValues[i]
-			j++
 		}
+		if opt.SparseMSM {
+			sparse := newSparseScalars(wireValuesA, opt.GroupDuplicateScalars)
+			points := sparse.removeInfinity(sparse.pointsG1(pk.G1.A))
+			wireValuesA = sparse.scalars
+			if pointsA, errWireValuesA = g1AffineToDevice(device, points, layoutA); errWireValuesA != nil {
+				close(chWireValuesA)
+				return
+			}
+		}
+		defer trace(backend.StageCopyToDevice, "wire values A", len(wireValuesA))()
 
-		wireValuesASize := len(wireValuesA)
-		scalarBytes := wireValuesASize * fr.Bytes
-		wireValuesADevicePtr, _ := goicicle.CudaMalloc(scalarBytes)
-		goicicle.CudaMemCpyHtoD[fr.Element](wireValuesADevicePtr, wireValuesA, scalarBytes)
-		MontConvOnDevice(wireValuesADevicePtr, wireValuesASize, false)
-		wireValuesADevice = OnDeviceData{wireValuesADevicePtr, wireValuesASize}
+		wireValuesADevice.p, errWireValuesA = scalarsToDevice(device, wireValuesA)
+		wireValuesADevice.size = len(wireValuesA)
 
 		close(chWireValuesA)
 	}()
 	go func() {
-		wireValuesB := make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
-		for i, j := 0, 0; j < len(wireValuesB); i++ {
-			if pk.InfinityB[i] {
-				continue
+		defer wireReaders.Done()
+		wireValuesB := wireValues
+		if opt.SparseMSM || !pk.deviceLayout.B.WithInfinity {
+			wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+			for i, j := 0, 0; j < len(wireValuesB); i++ {
+				if pk.InfinityB[i] {
+					continue
+				}
+				wireValuesB[j] = wireValues[i]
+				j++
+			}
+		}
+		if opt.SparseMSM {
+			sparse := newSparseScalars(wireValuesB, opt.GroupDuplicateScalars)
+			points := sparse.removeInfinity(sparse.pointsG1(pk.G1.B))
+			wireValuesB = sparse.scalars
+			if pointsB, errWireValuesB = g1AffineToDevice(device, points, layoutB); errWireValuesB != nil {
+				close(chWireValuesB)
+				return
+			}
+			if pointsG2B, errWireValuesB = g2AffineToDevice(device, sparse.pointsG2(pk.G2.B), layoutG2B); errWireValuesB != nil {
+				close(chWireValuesB)
+				return
 			}
-			wireValuesB[j] = wireValues[i]
-			j++
 		}
+		defer trace(backend.StageCopyToDevice, "wire values B", len(wireValuesB))()
 
-		wireValuesBSize := len(wireValuesB)
-		scalarBytes := wireValuesBSize * fr.Bytes
-		wireValuesBDevicePtr, _ := goicicle.CudaMalloc(scalarBytes)
-		goicicle.CudaMemCpyHtoD[fr.Element](wireValuesBDevicePtr, wireValuesB, scalarBytes)
-		MontConvOnDevice(wireValuesBDevicePtr, wireValuesBSize, false)
-		wireValuesBDevice = OnDeviceData{wireValuesBDevicePtr, wireValuesBSize}
+		wireValuesBDevice.p, errWireValuesB = scalarsToDevice(device, wireValuesB)
+		wireValuesBDevice.size = len(wireValuesB)
 
 		close(chWireValuesB)
 	}()
 
-	// sample random r and s
+	// sample random r and s, zero if the blinding is disabled
 	var r, s big.Int
 	var _r, _s, _kr fr.Element
-	if _, err := _r.SetRandom(); err != nil {
-		return nil, err
-	}
-	if _, err := _s.SetRandom(); err != nil {
-		return nil, err
+	if opt.UnsafeNoZK {
+		log.Warn().Msg("proving without blinding (unsafe): the proof is not zero-knowledge")
+	} else {
+		if _, err := _r.SetRandom(); err != nil {
+			return nil, err
+		}
+		if _, err := _s.SetRandom(); err != nil {
+			return nil, err
+		}
 	}
 	_kr.Mul(&_r, &_s).Neg(&_kr)
 
@@ -178,61 +285,105 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 
 	var bs1, ar curve.G1Jac
 
-	computeBS1 := func() {
-		<-chWireValuesB
-
-		icicleRes, _, _, time := MsmOnDevice(wireValuesBDevice.p, pk.G1Device.B, wireValuesBDevice.size, BUCKET_FACTOR, true)
-		log.Debug().Dur("took", time).Msg("Icicle API: MSM BS1 MSM")
+	computeBS1 := func() error {
+		if !ckpt.loadG1("bs1", &bs1) {
+			<-chWireValuesB
+			if errWireValuesB != nil {
+				return errWireValuesB
+			}
+			endMSM := trace(backend.StageMSMG1, "BS1", wireValuesBDevice.size)
+			var err error
+			bs1, err = msmG1(device, wireValuesBDevice.p, pointsB, wireValuesBDevice.size, layoutB)
+			endMSM()
+			if err != nil {
+				return err
+			}
+			ckpt.saveG1("bs1", &bs1)
+		}
 
-		bs1 = icicleRes
 		bs1.AddMixed(&pk.G1.Beta)
 		bs1.AddMixed(&deltas[1])
+		return nil
 	}
 
-	computeAR1 := func() {
-		<-chWireValuesA
-
-		icicleRes, _, _, timing := MsmOnDevice(wireValuesADevice.p, pk.G1Device.A, wireValuesADevice.size, BUCKET_FACTOR, true)
-		log.Debug().Dur("took", timing).Msg("Icicle API: MSM AR1 MSM")
+	computeAR1 := func() error {
+		if !ckpt.loadG1("ar", &ar) {
+			<-chWireValuesA
+			if errWireValuesA != nil {
+				return errWireValuesA
+			}
+			endMSM := trace(backend.StageMSMG1, "AR1", wireValuesADevice.size)
+			var err error
+			ar, err = msmG1(device, wireValuesADevice.p, pointsA, wireValuesADevice.size, layoutA)
+			endMSM()
+			if err != nil {
+				return err
+			}
+			ckpt.saveG1("ar", &ar)
+		}
 
-		ar = icicleRes
 		ar.AddMixed(&pk.G1.Alpha)
 		ar.AddMixed(&deltas[0])
 		proof.Ar.FromJacobian(&ar)
+		return nil
 	}
 
-	computeKRS := func() {
+	computeKRS := func() error {
 		// we could NOT split the Krs multiExp in 2, and just append pk.G1.K and pk.G1.Z
 		// however, having similar lengths for our tasks helps with parallelism
 
-		var krs, krs2, p1 curve.G1Jac
+		var krs, p1 curve.G1Jac
 		sizeH := int(pk.Domain.Cardinality - 1) // comes from the fact the deg(H)=(n-1)+(n-1)-n=n-2
 
-		icicleRes, _, _, timing := MsmOnDevice(h, pk.G1Device.Z, sizeH, BUCKET_FACTOR, true)
-		log.Debug().Dur("took", timing).Msg("Icicle API: MSM KRS2 MSM")
-
-		krs2 = icicleRes
-		// filter the wire values if needed;
-		_wireValues := filter(wireValues, r1cs.CommitmentInfo.PrivateToPublic())
-
-		scals := _wireValues[r1cs.GetNbPublicVariables():]
-
-		// Filter scalars matching infinity point indices
-		for _, indexToRemove := range pk.G1InfPointIndices.K {
-			scals = append(scals[:indexToRemove], scals[indexToRemove+1:]...)
+		var err error
+		if !krs2Done {
+			endMSM := trace(backend.StageMSMG1, "KRS2", sizeH)
+			krs2, err = msmG1(device, h, pk.G1Device.Z, sizeH, pk.deviceLayout.Z)
+			endMSM()
+			if err != nil {
+				return err
+			}
+			ckpt.saveG1("krs2", &krs2)
 		}
 
-		scalarBytes := len(scals) * fr.Bytes
-		scalars_d, _ := goicicle.CudaMalloc(scalarBytes)
-		goicicle.CudaMemCpyHtoD[fr.Element](scalars_d, scals, scalarBytes)
-		MontConvOnDevice(scalars_d, len(scals), false)
+		if !ckpt.loadG1("krs", &krs) {
+			// filter the wire values if needed;
+			_wireValues := filter(wireValues, commitmentInfo.PrivateToPublic())
+
+			var scals []fr.Element
+			pointsK, layoutK := pk.G1Device.K, pk.deviceLayout.K
+			if opt.SparseMSM {
+				sparse := newSparseScalars(_wireValues[r1cs.GetNbPublicVariables():], opt.GroupDuplicateScalars)
+				points := sparse.removeInfinity(sparse.pointsG1(pk.G1.K))
+				scals = sparse.scalars
+				layoutK = accel.PointsConfig{Representation: layoutK.Representation}
+				if pointsK, err = g1AffineToDevice(device, points, layoutK); err != nil {
+					return err
+				}
+				if pointsK != nil {
+					defer device.Free(pointsK)
+				}
+			} else {
+				// Filter scalars matching infinity point indices
+				scals = filter(_wireValues[r1cs.GetNbPublicVariables():], pk.G1InfPointIndices.K)
+			}
 
-		icicleRes, _, _, timing = MsmOnDevice(scalars_d, pk.G1Device.K, len(scals), BUCKET_FACTOR, true)
-		log.Debug().Dur("took", timing).Msg("Icicle API: MSM KRS MSM")
+			scalars_d, err := scalarsToDevice(device, scals)
+			if err != nil {
+				return err
+			}
 
-		goicicle.CudaFree(scalars_d)
+			endMSM := trace(backend.StageMSMG1, "KRS", len(scals))
+			krs, err = msmG1(device, scalars_d, pointsK, len(scals), layoutK)
+			endMSM()
+
+			_ = device.Free(scalars_d)
+			if err != nil {
+				return err
+			}
+			ckpt.saveG1("krs", &krs)
+		}
 
-		krs = icicleRes
 		krs.AddMixed(&deltas[2])
 
 		krs.AddAssign(&krs2)
@@ -244,18 +395,28 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		krs.AddAssign(&p1)
 
 		proof.Krs.FromJacobian(&krs)
+		return nil
 	}
 
 	computeBS2 := func() error {
 		// Bs2 (1 multi exp G2 - size = len(wires))
 		var Bs, deltaS curve.G2Jac
 
-		<-chWireValuesB
-
-		icicleG2Res, _, _, timing := MsmG2OnDevice(wireValuesBDevice.p, pk.G2Device.B, wireValuesBDevice.size, BUCKET_FACTOR, true)
-		log.Debug().Dur("took", timing).Msg("Icicle API: MSM G2 BS")
+		if !ckpt.loadG2("bs2", &Bs) {
+			<-chWireValuesB
+			if errWireValuesB != nil {
+				return errWireValuesB
+			}
+			endMSM := trace(backend.StageMSMG2, "BS2", wireValuesBDevice.size)
+			var err error
+			Bs, err = msmG2(device, wireValuesBDevice.p, pointsG2B, wireValuesBDevice.size, layoutG2B, opt.MultiExpNbTasks)
+			endMSM()
+			if err != nil {
+				return err
+			}
+			ckpt.saveG2("bs2", &Bs)
+		}
 
-		Bs = icicleG2Res
 		deltaS.FromAffine(&pk.G2.Delta)
 		deltaS.ScalarMultiplication(&deltaS, &s)
 		Bs.AddAssign(&deltaS)
@@ -265,26 +426,40 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		return nil
 	}
 
+	// the device buffers are released once the proof is computed, or failed
+	defer func() {
+		<-chWireValuesA
+		<-chWireValuesB
+		go func() {
+			_ = device.Free(wireValuesADevice.p)
+			_ = device.Free(wireValuesBDevice.p)
+			if opt.SparseMSM {
+				for _, p := range []unsafe.Pointer{pointsA, pointsB, pointsG2B} {
+					if p != nil {
+						_ = device.Free(p)
+					}
+				}
+			}
+			if h != nil {
+				_ = device.Free(h)
+			}
+		}()
+	}()
+
 	// wait for FFT to end, as it uses all our CPUs
 	<-chHDone
+	if errH != nil {
+		return nil, errH
+	}
 
 	// schedule our proof part computations
-	startMSM := time.Now()
-	computeBS1()
-	computeAR1()
-	computeKRS()
-	if err := computeBS2(); err != nil {
-		return nil, err
+	for _, compute := range []func() error{computeBS1, computeAR1, computeKRS, computeBS2} {
+		if err := compute(); err != nil {
+			return nil, err
+		}
 	}
-	log.Debug().Dur("took", time.Since(startMSM)).Msg("Total MSM time")
-
-	log.Debug().Dur("took", time.Since(start)).Msg("prover done; TOTAL PROVE TIME")
 
-	go func() {
-		goicicle.CudaFree(wireValuesADevice.p)
-		goicicle.CudaFree(wireValuesBDevice.p)
-		goicicle.CudaFree(h)
-	}()
+	ckpt.remove()
 
 	return proof, nil
 }
@@ -312,83 +487,129 @@ func filter(slice []fr.Element, toRemove []int) (r []fr.Element) {
 	return r
 }
 
-func computeH(a, b, c []fr.Element, pk *ProvingKey) unsafe.Pointer {
+// computeH releases the vectors a, b and c of the solution as soon as they are copied to the
+// device.
+func computeH(ctx context.Context, tracer backend.Tracer, solution *cs.R1CSSolution, pk *ProvingKey) (unsafe.Pointer, error) {
 	// H part of Krs
 	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
 	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
 	// 	2 - ca = fft_coset(_a), ba = fft_coset(_b), cc = fft_coset(_c)
 	// 	3 - h = ifft_coset(ca o cb - cc)
 
-	n := len(a)
+	d := pk.device
+	n := int(pk.Domain.Cardinality)
 
-	// add padding to ensure input length is domain cardinality
-	padding := make([]fr.Element, int(pk.Domain.Cardinality)-n)
-	a = append(a, padding...)
-	b = append(b, padding...)
-	c = append(c, padding...)
-	n = len(a)
+	ctx, endComputeH := backend.TraceStage(ctx, tracer, backend.StageComputeH, backend.StageMetadata{Size: n})
+	defer endComputeH()
 
-	sizeBytes := n * fr.Bytes
-
-	log := logger.Logger()
+	trace := func(stage backend.Stage, label string) func() {
+		_, end := backend.TraceStage(ctx, tracer, stage, backend.StageMetadata{Label: label, Size: n})
+		return end
+	}
 
 	/*********** Copy a,b,c to Device Start ************/
-	computeHTime := time.Now()
-	copyADone := make(chan unsafe.Pointer, 1)
-	copyBDone := make(chan unsafe.Pointer, 1)
-	copyCDone := make(chan unsafe.Pointer, 1)
-
-	convTime := time.Now()
-	go CopyToDevice(a, sizeBytes, copyADone)
-	go CopyToDevice(b, sizeBytes, copyBDone)
-	go CopyToDevice(c, sizeBytes, copyCDone)
-
-	a_device := <-copyADone
-	b_device := <-copyBDone
-	c_device := <-copyCDone
+	var vectors [3]unsafe.Pointer
+	defer func() {
+		go func() {
+			for _, v := range vectors {
+				if v != nil {
+					_ = d.Free(v)
+				}
+			}
+		}()
+	}()
 
-	log.Debug().Dur("took", time.Since(convTime)).Msg("Icicle API: Conv and Copy a,b,c")
+	// a, b and c are padded to the domain cardinality in a single staging buffer, instead of
+	// three padded copies
+	endCopy := trace(backend.StageCopyToDevice, "a, b, c")
+	staging := make([]fr.Element, n)
+	for i, v := range []*fr.Vector{&solution.A, &solution.B, &solution.C} {
+		m := copy(staging, *v)
+		for j := m; j < n; j++ {
+			staging[j].SetZero()
+		}
+		*v = nil
+		var err error
+		if vectors[i], err = scalarsToDevice(d, staging); err != nil {
+			endCopy()
+			return nil, err
+		}
+	}
+	endCopy()
+	a_device, b_device, c_device := vectors[0], vectors[1], vectors[2]
 	/*********** Copy a,b,c to Device End ************/
 
-	computeInttNttDone := make(chan error, 1)
-	computeInttNttOnDevice := func(devicePointer unsafe.Pointer) {
-		a_intt_d, timings_a := INttOnDevice(devicePointer, pk.DomainDevice.TwiddlesInv, nil, n, sizeBytes, false)
-		log.Debug().Dur("took", timings_a[0]).Msg("Icicle API: INTT Reverse")
-		log.Debug().Dur("took", timings_a[1]).Msg("Icicle API: INTT Interp")
+	// the devices with a fused pipeline run the kernels below without host synchronizations
+	if q, ok := d.(accel.QuotientDevice); ok {
+		h, err := q.Quotient(a_device, b_device, c_device, accel.QuotientDomain{
+			Twiddles:       pk.DomainDevice.Twiddles,
+			TwiddlesInv:    pk.DomainDevice.TwiddlesInv,
+			CosetPowers:    pk.DomainDevice.CosetTable,
+			CosetPowersInv: pk.DomainDevice.CosetTableInv,
+			Den:            pk.DenDevice,
+		}, n)
+		if !errors.Is(err, accel.ErrUnsupported) {
+			return h, err
+		}
+	}
+
+	computeInttNttDone := make(chan error, 3)
+	computeInttNttOnDevice := func(label string, devicePointer unsafe.Pointer) {
+		endINTT := trace(backend.StageINTT, label)
+		a_intt_d, err := d.Intt(devicePointer, pk.DomainDevice.TwiddlesInv, nil, n)
+		endINTT()
+		if err != nil {
+			computeInttNttDone <- err
+			return
+		}
 
-		timing_a2 := NttOnDevice(devicePointer, a_intt_d, pk.DomainDevice.Twiddles, pk.DomainDevice.CosetTable, n, n, sizeBytes, true)
-		log.Debug().Dur("took", timing_a2[1]).Msg("Icicle API: NTT Coset Reverse")
-		log.Debug().Dur("took", timing_a2[0]).Msg("Icicle API: NTT Coset Eval")
+		endNTT := trace(backend.StageNTT, label+" coset")
+		err = d.Ntt(devicePointer, a_intt_d, pk.DomainDevice.Twiddles, pk.DomainDevice.CosetTable, n)
+		endNTT()
 
-		computeInttNttDone <- nil
+		computeInttNttDone <- err
 
-		goicicle.CudaFree(a_intt_d)
+		_ = d.Free(a_intt_d)
 	}
 
-	computeInttNttTime := time.Now()
-	go computeInttNttOnDevice(a_device)
-	go computeInttNttOnDevice(b_device)
-	go computeInttNttOnDevice(c_device)
-	_, _, _ = <-computeInttNttDone, <-computeInttNttDone, <-computeInttNttDone
-	log.Debug().Dur("took", time.Since(computeInttNttTime)).Msg("Icicle API: INTT and NTT")
-
-	poltime := PolyOps(a_device, b_device, c_device, pk.DenDevice, n)
-	log.Debug().Dur("took", poltime[0]).Msg("Icicle API: PolyOps Mul a b")
-	log.Debug().Dur("took", poltime[1]).Msg("Icicle API: PolyOps Sub a c")
-	log.Debug().Dur("took", poltime[2]).Msg("Icicle API: PolyOps Mul a den")
+	go computeInttNttOnDevice("a", a_device)
+	go computeInttNttOnDevice("b", b_device)
+	go computeInttNttOnDevice("c", c_device)
+	var err error
+	for i := 0; i < 3; i++ {
+		if errNtt := <-computeInttNttDone; errNtt != nil {
+			err = errNtt
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	h, timings_final := INttOnDevice(a_device, pk.DomainDevice.TwiddlesInv, pk.DomainDevice.CosetTableInv, n, sizeBytes, true)
-	log.Debug().Dur("took", timings_final[0]).Msg("Icicle API: INTT Coset Reverse")
-	log.Debug().Dur("took", timings_final[1]).Msg("Icicle API: INTT Coset Interp")
+	// a·b - c, divided by the vanishing polynomial on the coset
+	endPolyOps := trace(backend.StagePolyOps, "")
+	if err := d.VecMul(a_device, b_device, n); err != nil {
+		return nil, err
+	}
+	if err := d.VecSub(a_device, c_device, n); err != nil {
+		return nil, err
+	}
+	if err := d.VecMul(a_device, pk.DenDevice, n); err != nil {
+		return nil, err
+	}
+	endPolyOps()
 
-	go func() {
-		goicicle.CudaFree(a_device)
-		goicicle.CudaFree(b_device)
-		goicicle.CudaFree(c_device)
-	}()
+	endINTT := trace(backend.StageINTT, "h coset")
+	h, err := d.Intt(a_device, pk.DomainDevice.TwiddlesInv, pk.DomainDevice.CosetTableInv, n)
+	endINTT()
+	if err != nil {
+		return nil, err
+	}
 
-	icicle.ReverseScalars(h, n)
-	log.Debug().Dur("took", time.Since(computeHTime)).Msg("Icicle API: computeH")
+	// pk.G1.Z is in bit-reversed order
+	if err := d.ReverseScalars(h, n); err != nil {
+		_ = d.Free(h)
+		return nil, err
+	}
 
-	return h
+	return h, nil
 }
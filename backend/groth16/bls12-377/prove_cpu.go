@@ -0,0 +1,281 @@
+package groth16
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/bls12-377"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+// proveCPU completes the proof of the solution with the multi-exponentiations and the FFTs of
+// gnark-crypto, for the proving keys without device copies: see setupDevicePointers. The
+// options of the device copies (sparse multi-exponentiations, checkpoints of the
+// multi-exponentiations) don't apply. wireReaders is done once the wire values are read.
+func proveCPU(r1cs *cs.R1CS, commitmentInfo *constraint.Commitment, pk *ProvingKey, solution *cs.R1CSSolution, proof *Proof, wireReaders *sync.WaitGroup, opt backend.ProverConfig, log zerolog.Logger, trace func(backend.Stage, string, int) func()) error {
+	wireValues := []fr.Element(solution.W)
+
+	// H (witness reduction / FFT part)
+	var h []fr.Element
+	chHDone := make(chan struct{}, 1)
+	go func() {
+		endH := trace(backend.StageComputeH, "", int(pk.Domain.Cardinality))
+		h = computeHCPU(solution.A, solution.B, solution.C, &pk.Domain)
+		endH()
+		solution.A = nil
+		solution.B = nil
+		solution.C = nil
+		chHDone <- struct{}{}
+	}()
+
+	// we need to copy and filter the wireValues for each multi exp
+	// as pk.G1.A, pk.G1.B and pk.G2.B may have (a significant) number of point at infinity
+	var wireValuesA, wireValuesB []fr.Element
+	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
+
+	wireReaders.Add(2)
+	go func() {
+		defer wireReaders.Done()
+		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+		for i, j := 0, 0; j < len(wireValuesA); i++ {
+			if pk.InfinityA[i] {
+				continue
+			}
+			wireValuesA[j] = wireValues[i]
+			j++
+		}
+		close(chWireValuesA)
+	}()
+	go func() {
+		defer wireReaders.Done()
+		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+		for i, j := 0, 0; j < len(wireValuesB); i++ {
+			if pk.InfinityB[i] {
+				continue
+			}
+			wireValuesB[j] = wireValues[i]
+			j++
+		}
+		close(chWireValuesB)
+	}()
+
+	// sample random r and s, zero if the blinding is disabled
+	var r, s big.Int
+	var _r, _s, _kr fr.Element
+	if opt.UnsafeNoZK {
+		log.Warn().Msg("proving without blinding (unsafe): the proof is not zero-knowledge")
+	} else {
+		if _, err := _r.SetRandom(); err != nil {
+			return err
+		}
+		if _, err := _s.SetRandom(); err != nil {
+			return err
+		}
+	}
+	_kr.Mul(&_r, &_s).Neg(&_kr)
+
+	_r.BigInt(&r)
+	_s.BigInt(&s)
+
+	// computes r[δ], s[δ], kr[δ]
+	deltas := curve.BatchScalarMultiplicationG1(&pk.G1.Delta, []fr.Element{_r, _s, _kr})
+
+	var bs1, ar curve.G1Jac
+
+	n := runtime.NumCPU()
+
+	chBs1Done := make(chan error, 1)
+	computeBS1 := func() {
+		<-chWireValuesB
+		endMSM := trace(backend.StageMSMG1, "BS1", len(wireValuesB))
+		_, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2})
+		endMSM()
+		if err != nil {
+			chBs1Done <- err
+			close(chBs1Done)
+			return
+		}
+		bs1.AddMixed(&pk.G1.Beta)
+		bs1.AddMixed(&deltas[1])
+		chBs1Done <- nil
+	}
+
+	chArDone := make(chan error, 1)
+	computeAR1 := func() {
+		<-chWireValuesA
+		endMSM := trace(backend.StageMSMG1, "AR", len(wireValuesA))
+		_, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2})
+		endMSM()
+		if err != nil {
+			chArDone <- err
+			close(chArDone)
+			return
+		}
+		ar.AddMixed(&pk.G1.Alpha)
+		ar.AddMixed(&deltas[0])
+		proof.Ar.FromJacobian(&ar)
+		chArDone <- nil
+	}
+
+	chKrsDone := make(chan error, 1)
+	computeKRS := func() {
+		// we could NOT split the Krs multiExp in 2, and just append pk.G1.K and pk.G1.Z
+		// however, having similar lengths for our tasks helps with parallelism
+
+		var krs, krs2, p1 curve.G1Jac
+		chKrs2Done := make(chan error, 1)
+		sizeH := int(pk.Domain.Cardinality - 1) // comes from the fact the deg(H)=(n-1)+(n-1)-n=n-2
+		go func() {
+			endMSM := trace(backend.StageMSMG1, "KRS2", sizeH)
+			_, err := krs2.MultiExp(pk.G1.Z, h[:sizeH], ecc.MultiExpConfig{NbTasks: n / 2})
+			endMSM()
+			chKrs2Done <- err
+		}()
+
+		// filter the wire values if needed;
+		_wireValues := filter(wireValues, commitmentInfo.PrivateToPublic())
+		scalars := _wireValues[r1cs.GetNbPublicVariables():]
+
+		endMSM := trace(backend.StageMSMG1, "KRS", len(scalars))
+		_, err := krs.MultiExp(pk.G1.K, scalars, ecc.MultiExpConfig{NbTasks: n / 2})
+		endMSM()
+		if err != nil {
+			chKrsDone <- err
+			return
+		}
+		krs.AddMixed(&deltas[2])
+		n := 3
+		for n != 0 {
+			select {
+			case err := <-chKrs2Done:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				krs.AddAssign(&krs2)
+			case err := <-chArDone:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				p1.ScalarMultiplication(&ar, &s)
+				krs.AddAssign(&p1)
+			case err := <-chBs1Done:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				p1.ScalarMultiplication(&bs1, &r)
+				krs.AddAssign(&p1)
+			}
+			n--
+		}
+
+		proof.Krs.FromJacobian(&krs)
+		chKrsDone <- nil
+	}
+
+	computeBS2 := func() error {
+		// Bs2 (1 multi exp G2 - size = len(wires))
+		var Bs, deltaS curve.G2Jac
+
+		<-chWireValuesB
+
+		nbTasks := opt.MultiExpNbTasks
+		if nbTasks == 0 {
+			// the G1 multi-exponentiations may still be running: use the CPUs they left
+			// idle in the first proof
+			nbTasks = utils.IdleNbTasks()
+			if nbTasks <= 16 {
+				// if we don't have a lot of CPUs, this may artificially split the MSM
+				nbTasks *= 2
+			}
+		}
+		endMSM := trace(backend.StageMSMG2, "BS2", len(wireValuesB))
+		_, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks})
+		endMSM()
+		if err != nil {
+			return err
+		}
+
+		deltaS.FromAffine(&pk.G2.Delta)
+		deltaS.ScalarMultiplication(&deltaS, &s)
+		Bs.AddAssign(&deltaS)
+		Bs.AddMixed(&pk.G2.Beta)
+
+		proof.Bs.FromJacobian(&Bs)
+		return nil
+	}
+
+	// wait for FFT to end, as it uses all our CPUs
+	<-chHDone
+
+	// schedule our proof part computations; computeKRS reads the wire values
+	wireReaders.Add(1)
+	go func() {
+		defer wireReaders.Done()
+		computeKRS()
+	}()
+	go computeAR1()
+	go computeBS1()
+	if err := computeBS2(); err != nil {
+		return err
+	}
+
+	// wait for all parts of the proof to be computed.
+	return <-chKrsDone
+}
+
+// computeHCPU returns the coefficients of h = (a·b-c)/Z on the CPU, see computeH.
+func computeHCPU(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
+	// H part of Krs
+	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
+	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
+	// 	2 - ca = fft_coset(_a), ba = fft_coset(_b), cc = fft_coset(_c)
+	// 	3 - h = ifft_coset(ca o cb - cc)
+
+	n := len(a)
+
+	// add padding to ensure input length is domain cardinality
+	padding := make([]fr.Element, int(domain.Cardinality)-n)
+	a = append(a, padding...)
+	b = append(b, padding...)
+	c = append(c, padding...)
+	n = len(a)
+
+	domain.FFTInverse(a, fft.DIF)
+	domain.FFTInverse(b, fft.DIF)
+	domain.FFTInverse(c, fft.DIF)
+
+	domain.FFT(a, fft.DIT, fft.OnCoset())
+	domain.FFT(b, fft.DIT, fft.OnCoset())
+	domain.FFT(c, fft.DIT, fft.OnCoset())
+
+	var den, one fr.Element
+	one.SetOne()
+	den.Exp(domain.FrMultiplicativeGen, big.NewInt(int64(domain.Cardinality)))
+	den.Sub(&den, &one).Inverse(&den)
+
+	// h = ifft_coset(ca o cb - cc)
+	// reusing a to avoid unnecessary memory allocation
+	utils.Parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			a[i].Mul(&a[i], &b[i]).
+				Sub(&a[i], &c[i]).
+				Mul(&a[i], &den)
+		}
+	})
+
+	// ifft_coset
+	domain.FFTInverse(a, fft.DIF, fft.OnCoset())
+
+	return a
+}
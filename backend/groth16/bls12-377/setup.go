@@ -12,26 +12,27 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
+	"bytes"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
-	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/pedersen"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/constraint/bls12-377"
-	"github.com/ingonyama-zk/icicle/goicicle"
-	icicle "github.com/ingonyama-zk/icicle/goicicle/curves/bls12377"
-	"github.com/ingonyama-zk/iciclegnark/curves/bls12377"
-	"math"
+	"github.com/consensys/gnark/internal/utils"
+	"io"
 	"math/big"
 	"math/bits"
+	"os"
 	"unsafe"
 )
 
@@ -79,6 +80,11 @@ type ProvingKey struct {
 	NbInfinityA, NbInfinityB uint64
 
 	CommitmentKey pedersen.ProvingKey
+
+	// device holding the device copies, of the accelerator provider, and their layout
+	device       accel.Device
+	accelerator  string
+	deviceLayout DeviceLayout
 }
 
 // VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
@@ -105,8 +111,9 @@ type VerifyingKey struct {
 	CommitmentInfo constraint.Commitment // since the verifier doesn't input a constraint system, this needs to be provided here
 }
 
-// Setup constructs the SRS
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+// Setup constructs the SRS, with the secrets read from the sampler of the options
+// (backend.WithSampler).
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey, opts ...backend.SetupOption) error {
 	/*
 		Setup
 		-----
@@ -117,13 +124,18 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
 	*/
 
+	commitmentInfo, err := r1cs.CommitmentInfo.Single()
+	if err != nil {
+		return err
+	}
+
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.GetNbPublicVariables() + r1cs.GetNbSecretVariables()
-	nbPrivateCommittedWires := r1cs.CommitmentInfo.NbPrivateCommitted
+	nbPrivateCommittedWires := commitmentInfo.NbPrivateCommitted
 	nbPublicWires := r1cs.GetNbPublicVariables()
 	nbPrivateWires := r1cs.GetNbSecretVariables() + r1cs.NbInternalVariables - nbPrivateCommittedWires
 
-	if r1cs.CommitmentInfo.Is() { // the commitment itself is defined by a hint so the prover considers it private
+	if commitmentInfo.Is() { // the commitment itself is defined by a hint so the prover considers it private
 		nbPublicWires++  // but the verifier will need to inject the value itself so on the groth16
 		nbPrivateWires-- // level it must be considered public
 	}
@@ -131,8 +143,13 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(r1cs.GetNbConstraints()))
 
+	cfg, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return err
+	}
+
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(cfg.Sampler)
 	if err != nil {
 		return err
 	}
@@ -176,11 +193,11 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	}
 
 	vI, cI := 0, 0
-	privateCommitted := r1cs.CommitmentInfo.PrivateCommitted()
+	privateCommitted := commitmentInfo.PrivateCommitted()
 
 	for i := range A {
 		isCommittedPrivate := cI < len(privateCommitted) && i == privateCommitted[cI]
-		isCommitment := r1cs.CommitmentInfo.Is() && i == r1cs.CommitmentInfo.CommitmentIndex
+		isCommitment := commitmentInfo.Is() && i == commitmentInfo.CommitmentIndex
 		isPublic := i < r1cs.GetNbPublicVariables()
 
 		if isPublic || isCommittedPrivate || isCommitment {
@@ -282,13 +299,13 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	if nbPrivateCommittedWires != 0 {
 		commitmentBasis := g1PointsAff[offset:]
 
-		pk.CommitmentKey, vk.CommitmentKey, err = pedersen.Setup(commitmentBasis)
+		pk.CommitmentKey, vk.CommitmentKey, err = setupCommitmentKey(commitmentBasis, cfg.Sampler)
 		if err != nil {
 			return err
 		}
 	}
 
-	vk.CommitmentInfo = r1cs.CommitmentInfo // unfortunate but necessary
+	vk.CommitmentInfo = commitmentInfo // unfortunate but necessary
 
 	// ---------------------------------------------------------------------------------------------
 	// G2 scalars
@@ -329,124 +346,74 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// set domain
 	pk.Domain = *domain
 
-	pk.setupDevicePointers()
-
-	return nil
+	pk.accelerator = cfg.Accelerator
+	return pk.setupDevicePointers()
 }
 
-func (pk *ProvingKey) setupDevicePointers() {
+// setupDevicePointers copies the proving key to a device of the provider named by
+// pk.accelerator, or by the environment variable GNARK_ACCEL if empty, see accel.Open.
+//
+// Without a name, the fallback providers aren't selected: the key then has no device copies,
+// and is proved on the CPU, so that the CPU-only builds don't hold the points twice. The CPU
+// emulation is selected by its name, with GNARK_ACCEL=cpu or backend.WithAccelerator("cpu").
+func (pk *ProvingKey) setupDevicePointers() error {
+	name := pk.accelerator
+	if name == "" {
+		name = os.Getenv(accel.EnvVar)
+	}
+	provider, err := accel.Lookup(name, curve.ID)
+	if name == "" && (errors.Is(err, accel.ErrNoDevice) || err == nil && provider.Fallback) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	device, err := provider.Open(curve.ID)
+	if err != nil {
+		return err
+	}
+	// the live buffers are tracked for the crash dumps, see groth16.EnableCrashDumps
+	pk.device, pk.accelerator = accel.Track(device), provider.Name
+
 	n := int(pk.Domain.Cardinality)
-	sizeBytes := n * fr.Bytes
 
 	/*************************  Start Domain Device Setup  ***************************/
 
 	/*************************     CosetTableInv      ***************************/
-	cosetPowersInv_d, _ := goicicle.CudaMalloc(sizeBytes)
-	goicicle.CudaMemCpyHtoD[fr.Element](cosetPowersInv_d, pk.Domain.CosetTableInv, sizeBytes)
-	MontConvOnDevice(cosetPowersInv_d, len(pk.Domain.CosetTable), false)
-
-	pk.DomainDevice.CosetTableInv = cosetPowersInv_d
+	if pk.DomainDevice.CosetTableInv, err = scalarsToDevice(device, pk.Domain.CosetTableInv); err != nil {
+		return err
+	}
 
 	/*************************     CosetTable      ***************************/
-	cosetPowers_d, _ := goicicle.CudaMalloc(sizeBytes)
-	goicicle.CudaMemCpyHtoD[fr.Element](cosetPowers_d, pk.Domain.CosetTable, sizeBytes)
-	MontConvOnDevice(cosetPowers_d, len(pk.Domain.CosetTable), false)
-
-	pk.DomainDevice.CosetTable = cosetPowers_d
+	if pk.DomainDevice.CosetTable, err = scalarsToDevice(device, pk.Domain.CosetTable); err != nil {
+		return err
+	}
 
 	/*************************     Twiddles and Twiddles Inv    ***************************/
-	om_selector := int(math.Log(float64(n)) / math.Log(2))
-	twiddlesInv_d_gen, twddles_err := icicle.GenerateTwiddles(n, om_selector, true)
-
-	if twddles_err != nil {
-		fmt.Print(twiddlesInv_d_gen)
+	if pk.DomainDevice.TwiddlesInv, err = device.Twiddles(n, true); err != nil {
+		return err
 	}
-
-	twiddles_d_gen, twddles_err := icicle.GenerateTwiddles(n, om_selector, false)
-	if twddles_err != nil {
-		fmt.Print(twiddles_d_gen)
+	if pk.DomainDevice.Twiddles, err = device.Twiddles(n, false); err != nil {
+		return err
 	}
 
-	pk.DomainDevice.Twiddles = twiddles_d_gen
-	pk.DomainDevice.TwiddlesInv = twiddlesInv_d_gen
-
 	/*************************     Den      ***************************/
 	var denI, oneI fr.Element
 	oneI.SetOne()
 	denI.Exp(pk.Domain.FrMultiplicativeGen, big.NewInt(int64(pk.Domain.Cardinality)))
 	denI.Sub(&denI, &oneI).Inverse(&denI)
 
-	den_d, _ := goicicle.CudaMalloc(sizeBytes)
-	log2Size := int(math.Floor(math.Log2(float64(n))))
-	denIcicle := *bls12377.NewFieldFromFrGnark(denI)
-	denIcicleArr := []icicle.G1ScalarField{denIcicle}
-	for i := 0; i < log2Size; i++ {
-		denIcicleArr = append(denIcicleArr, denIcicleArr...)
+	den := make([]fr.Element, n)
+	for i := range den {
+		den[i] = denI
 	}
-	for i := 0; i < (n - int(math.Pow(2, float64(log2Size)))); i++ {
-		denIcicleArr = append(denIcicleArr, denIcicle)
+	if pk.DenDevice, err = scalarsToDevice(device, den); err != nil {
+		return err
 	}
 
-	goicicle.CudaMemCpyHtoD[icicle.G1ScalarField](den_d, denIcicleArr, sizeBytes)
-
-	pk.DenDevice = den_d
-
 	/*************************  End Domain Device Setup  ***************************/
 
-	/*************************  Start G1 Device Setup  ***************************/
-	/*************************     A      ***************************/
-	pointsBytesA := len(pk.G1.A) * fp.Bytes * 2
-	a_d, _ := goicicle.CudaMalloc(pointsBytesA)
-	iciclePointsA := bls12377.BatchConvertFromG1Affine(pk.G1.A)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](a_d, iciclePointsA, pointsBytesA)
-
-	pk.G1Device.A = a_d
-
-	/*************************     B      ***************************/
-	pointsBytesB := len(pk.G1.B) * fp.Bytes * 2
-	b_d, _ := goicicle.CudaMalloc(pointsBytesB)
-	iciclePointsB := bls12377.BatchConvertFromG1Affine(pk.G1.B)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](b_d, iciclePointsB, pointsBytesB)
-
-	pk.G1Device.B = b_d
-
-	/*************************     K      ***************************/
-	//remove infinity points and save indices for removing scalars later
-	// TODO, find better way to save mem
-	var pointsNoInfinity []curve.G1Affine
-	for i, gnarkPoint := range pk.G1.K {
-		if gnarkPoint.IsInfinity() {
-			pk.G1InfPointIndices.K = append(pk.G1InfPointIndices.K, i)
-		} else {
-			pointsNoInfinity = append(pointsNoInfinity, gnarkPoint)
-		}
-	}
-
-	pointsBytesK := len(pointsNoInfinity) * fp.Bytes * 2
-
-	k_d, _ := goicicle.CudaMalloc(pointsBytesK)
-	iciclePointsK := bls12377.BatchConvertFromG1Affine(pointsNoInfinity)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](k_d, iciclePointsK, pointsBytesK)
-
-	pk.G1Device.K = k_d
-
-	/*************************     Z      ***************************/
-	pointsBytesZ := len(pk.G1.Z) * fp.Bytes * 2
-	z_d, _ := goicicle.CudaMalloc(pointsBytesZ)
-	iciclePointsZ := bls12377.BatchConvertFromG1Affine(pk.G1.Z)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](z_d, iciclePointsZ, pointsBytesZ)
-
-	pk.G1Device.Z = z_d
-	/*************************  End G1 Device Setup  ***************************/
-
-	/*************************  Start G2 Device Setup  ***************************/
-	pointsBytesB2 := len(pk.G2.B) * fp.Bytes * 4
-	b2_d, _ := goicicle.CudaMalloc(pointsBytesB2)
-	iciclePointsB2 := bls12377.BatchConvertFromG2Affine(pk.G2.B)
-	goicicle.CudaMemCpyHtoD[icicle.G2PointAffine](b2_d, iciclePointsB2, pointsBytesB2)
-	pk.G2Device.B = b2_d
-	/*************************  End G2 Device Setup  ***************************/
-
+	return pk.setupDevicePoints()
 }
 
 // Precompute sets e, -[δ]2, -[γ]2
@@ -554,40 +521,77 @@ type toxicWaste struct {
 	gammaInv, deltaInv           fr.Element
 }
 
-func sampleToxicWaste() (toxicWaste, error) {
+func sampleToxicWaste(sampler io.Reader) (toxicWaste, error) {
 
 	res := toxicWaste{}
 
-	for res.t.IsZero() {
-		if _, err := res.t.SetRandom(); err != nil {
+	for _, e := range []*fr.Element{&res.t, &res.alpha, &res.beta, &res.gamma, &res.delta} {
+		if err := sampleNonZero(sampler, e); err != nil {
 			return res, err
 		}
 	}
-	for res.alpha.IsZero() {
-		if _, err := res.alpha.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.beta.IsZero() {
-		if _, err := res.beta.SetRandom(); err != nil {
-			return res, err
+
+	res.gammaInv.Inverse(&res.gamma)
+	res.deltaInv.Inverse(&res.delta)
+
+	return res, nil
+}
+
+// sampleNonZero sets e to a non zero element read from sampler.
+func sampleNonZero(sampler io.Reader, e *fr.Element) error {
+	// read 16 more bytes than needed so that the reduction modulo r is statistically uniform
+	var buf [fr.Bytes + 16]byte
+	var v big.Int
+	for e.IsZero() {
+		if _, err := io.ReadFull(sampler, buf[:]); err != nil {
+			return fmt.Errorf("sampling setup secret: %w", err)
 		}
+		e.SetBigInt(v.SetBytes(buf[:]))
 	}
-	for res.gamma.IsZero() {
-		if _, err := res.gamma.SetRandom(); err != nil {
-			return res, err
-		}
+	return nil
+}
+
+// setupCommitmentKey is equivalent to pedersen.Setup, with the secrets read from sampler.
+func setupCommitmentKey(basis []curve.G1Affine, sampler io.Reader) (pk pedersen.ProvingKey, vk pedersen.VerifyingKey, err error) {
+	var g, sigma fr.Element
+	if err = sampleNonZero(sampler, &g); err != nil {
+		return
 	}
-	for res.delta.IsZero() {
-		if _, err := res.delta.SetRandom(); err != nil {
-			return res, err
-		}
+	if err = sampleNonZero(sampler, &sigma); err != nil {
+		return
 	}
 
-	res.gammaInv.Inverse(&res.gamma)
-	res.deltaInv.Inverse(&res.delta)
+	var b big.Int
+	var g2, gRootSigmaNeg curve.G2Affine
+	_, _, _, g2Gen := curve.Generators()
+	g2.ScalarMultiplication(&g2Gen, g.BigInt(&b))
+
+	// [-1/σ]G
+	var sigmaInvNeg fr.Element
+	sigmaInvNeg.Inverse(&sigma).Neg(&sigmaInvNeg)
+	gRootSigmaNeg.ScalarMultiplication(&g2, sigmaInvNeg.BigInt(&b))
+
+	basisExpSigma := make([]curve.G1Affine, len(basis))
+	sigma.BigInt(&b)
+	utils.Parallelize(len(basis), func(start, end int) {
+		for i := start; i < end; i++ {
+			basisExpSigma[i].ScalarMultiplication(&basis[i], &b)
+		}
+	})
 
-	return res, nil
+	// the fields of the keys are unexported: they are set through their encoding
+	var buf bytes.Buffer
+	enc := curve.NewEncoder(&buf)
+	for _, v := range []interface{}{basis, basisExpSigma, &g2, &gRootSigmaNeg} {
+		if err = enc.Encode(v); err != nil {
+			return
+		}
+	}
+	if _, err = pk.ReadFrom(&buf); err != nil {
+		return
+	}
+	_, err = vk.ReadFrom(&buf)
+	return
 }
 
 // DummySetup fills a random ProvingKey
@@ -624,7 +628,7 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	}
 
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(rand.Reader)
 	if err != nil {
 		return err
 	}
@@ -743,9 +747,14 @@ func (vk *VerifyingKey) CurveID() ecc.ID {
 	return curve.ID
 }
 
-// NbPublicWitness returns the number of elements in the expected public witness
+// NbPublicWitness returns the number of elements in the expected public witness. The
+// commitment wire follows the public wires in K, but isn't part of the public witness: the
+// verifier computes it from the commitment of the proof.
 func (vk *VerifyingKey) NbPublicWitness() int {
-	return (len(vk.G1.K) - 1)
+	if vk.CommitmentInfo.Is() {
+		return len(vk.G1.K) - 2
+	}
+	return len(vk.G1.K) - 1
 }
 
 // NbG1 returns the number of G1 elements in the VerifyingKey
@@ -0,0 +1,95 @@
+package groth16
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+// openDevice returns the device of the accel registry computing the multi-exponentiations of
+// Prove: the one of the named provider (see backend.WithAccelerator), or else the one of
+// accel.Open. The providers of gnark (icicle, rocm and the CPU emulation) have no BLS24-315
+// kernels, so that without a name it returns nil, for the CPU multi-exponentiations, if no
+// provider supports the curve.
+//
+// Unlike the BN254 and BLS12-377 provers, which keep device copies of the proving key, the
+// points are copied to the device at each multi-exponentiation.
+func openDevice(name string) (accel.Device, error) {
+	if name != "" {
+		return accel.OpenProvider(name, curve.ID)
+	}
+	device, err := accel.Open(curve.ID)
+	if errors.Is(err, accel.ErrNoDevice) {
+		return nil, nil
+	}
+	return device, err
+}
+
+// multiExpG1 sets res to the multi-exponentiation on the device if any, or else on the CPU.
+func multiExpG1(d accel.Device, res *curve.G1Jac, points []curve.G1Affine, scalars []fr.Element, config ecc.MultiExpConfig) error {
+	if d == nil {
+		_, err := res.MultiExp(points, scalars, config)
+		return err
+	}
+	res.FromAffine(&curve.G1Affine{})
+	return deviceMultiExp(d, unsafe.Pointer(res), points, scalars, (*curve.G1Affine).IsInfinity, d.PointsG1ToDevice, d.Msm)
+}
+
+// multiExpG2 sets res to the multi-exponentiation on the device if any, or else on the CPU.
+func multiExpG2(d accel.Device, res *curve.G2Jac, points []curve.G2Affine, scalars []fr.Element, config ecc.MultiExpConfig) error {
+	if d == nil {
+		_, err := res.MultiExp(points, scalars, config)
+		return err
+	}
+	res.FromAffine(&curve.G2Affine{})
+	return deviceMultiExp(d, unsafe.Pointer(res), points, scalars, (*curve.G2Affine).IsInfinity, d.PointsG2ToDevice, d.MsmG2)
+}
+
+// deviceMultiExp sets the Jacobian point res to the multi-exponentiation of the points and
+// scalars with the kernels of the device, copying them to the device in the default layout:
+// the points at infinity, which the proving keys may have, are removed with their scalars.
+func deviceMultiExp[P any](d accel.Device, res unsafe.Pointer, points []P, scalars []fr.Element,
+	isInfinity func(*P) bool,
+	toDevice func(points unsafe.Pointer, n int, cfg accel.PointsConfig) (unsafe.Pointer, error),
+	msm func(res, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error) error {
+
+	if len(scalars) < len(points) {
+		points = points[:len(scalars)]
+	}
+	finitePoints := make([]P, 0, len(points))
+	finiteScalars := make([]fr.Element, 0, len(points))
+	for i := range points {
+		if !isInfinity(&points[i]) {
+			finitePoints = append(finitePoints, points[i])
+			finiteScalars = append(finiteScalars, scalars[i])
+		}
+	}
+	n := len(finitePoints)
+	if n == 0 {
+		return nil
+	}
+
+	points_d, err := toDevice(unsafe.Pointer(&finitePoints[0]), n, accel.PointsConfig{})
+	if err != nil {
+		return err
+	}
+	defer d.Free(points_d)
+
+	size := n * fr.Bytes
+	scalars_d, err := d.Malloc(size)
+	if err != nil {
+		return err
+	}
+	defer d.Free(scalars_d)
+	if err := d.CopyToDevice(scalars_d, unsafe.Pointer(&finiteScalars[0]), size); err != nil {
+		return err
+	}
+	if err := d.FromMontgomery(scalars_d, n); err != nil {
+		return err
+	}
+	return msm(res, scalars_d, points_d, n, accel.MsmConfig{})
+}
@@ -0,0 +1,126 @@
+package groth16_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// hostDevice computes the multi-exponentiations on the CPU, in host buffers, and counts them.
+// It only implements the methods of accel.Device the BLS24-315 prover calls, and keeps the
+// scalars in Montgomery form, as its multi-exponentiations expect.
+type hostDevice struct {
+	accel.Device
+
+	lock       sync.Mutex
+	buffers    map[unsafe.Pointer][]byte
+	nbG1, nbG2 atomic.Int32
+}
+
+func (d *hostDevice) Name() string { return "test/bls24-315" }
+
+func (d *hostDevice) Curve() ecc.ID { return ecc.BLS24_315 }
+
+func (d *hostDevice) Malloc(size int) (unsafe.Pointer, error) {
+	// 64-bit words, for the alignment of the field elements
+	buffer := make([]uint64, size/8+1)
+	p := unsafe.Pointer(&buffer[0])
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.buffers[p] = unsafe.Slice((*byte)(p), size)
+	return p, nil
+}
+
+func (d *hostDevice) Free(p unsafe.Pointer) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.buffers, p)
+	return nil
+}
+
+func (d *hostDevice) CopyToDevice(dst, src unsafe.Pointer, size int) error {
+	copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+	return nil
+}
+
+func (d *hostDevice) FromMontgomery(unsafe.Pointer, int) error { return nil }
+
+func (d *hostDevice) PointsG1ToDevice(points unsafe.Pointer, n int, _ accel.PointsConfig) (unsafe.Pointer, error) {
+	size := n * int(unsafe.Sizeof(curve.G1Affine{}))
+	p, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	return p, d.CopyToDevice(p, points, size)
+}
+
+func (d *hostDevice) PointsG2ToDevice(points unsafe.Pointer, n int, _ accel.PointsConfig) (unsafe.Pointer, error) {
+	size := n * int(unsafe.Sizeof(curve.G2Affine{}))
+	p, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	return p, d.CopyToDevice(p, points, size)
+}
+
+func (d *hostDevice) Msm(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, _ accel.MsmConfig) error {
+	d.nbG1.Add(1)
+	_, err := (*curve.G1Jac)(res).MultiExp(unsafe.Slice((*curve.G1Affine)(points), n), unsafe.Slice((*fr.Element)(scalars), n), ecc.MultiExpConfig{})
+	return err
+}
+
+func (d *hostDevice) MsmG2(res unsafe.Pointer, scalars, points unsafe.Pointer, n int, _ accel.MsmConfig) error {
+	d.nbG2.Add(1)
+	_, err := (*curve.G2Jac)(res).MultiExp(unsafe.Slice((*curve.G2Affine)(points), n), unsafe.Slice((*fr.Element)(scalars), n), ecc.MultiExpConfig{})
+	return err
+}
+
+type deviceCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *deviceCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func TestDevice(t *testing.T) {
+	ccs, pk, vk := setup(t, &deviceCircuit{})
+	w, err := frontend.NewWitness(&deviceCircuit{X: 3, Y: 27}, ecc.BLS24_315.ScalarField())
+	assert.NoError(t, err)
+	public, err := w.Public()
+	assert.NoError(t, err)
+
+	// no provider supports the curve: the prover runs on the CPU, unless one is required
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+	_, err = groth16.Prove(ccs, pk, w, backend.WithAccelerator("test/bls24-315"))
+	assert.ErrorIs(t, err, accel.ErrNoDevice)
+
+	d := &hostDevice{buffers: make(map[unsafe.Pointer][]byte)}
+	accel.Register(accel.Provider{
+		Name:   "test/bls24-315",
+		Curves: []ecc.ID{ecc.BLS24_315},
+		Open:   func(ecc.ID) (accel.Device, error) { return d, nil },
+	})
+
+	proof, err = groth16.Prove(ccs, pk, w)
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+
+	assert.Equal(t, int32(4), d.nbG1.Load(), "BS1, AR, KRS and KRS2 run on the device")
+	assert.Equal(t, int32(1), d.nbG2.Load(), "BS2 runs on the device")
+	assert.Empty(t, d.buffers, "the device buffers are freed")
+}
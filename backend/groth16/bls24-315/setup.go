@@ -17,13 +17,19 @@
 package groth16
 
 import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/pedersen"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/constraint/bls24-315"
+	"github.com/consensys/gnark/internal/utils"
+	"io"
 	"math/big"
 	"math/bits"
 )
@@ -79,8 +85,9 @@ type VerifyingKey struct {
 	CommitmentInfo constraint.Commitment // since the verifier doesn't input a constraint system, this needs to be provided here
 }
 
-// Setup constructs the SRS
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+// Setup constructs the SRS, with the secrets read from the sampler of the options
+// (backend.WithSampler).
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey, opts ...backend.SetupOption) error {
 	/*
 		Setup
 		-----
@@ -91,13 +98,18 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
 	*/
 
+	commitmentInfo, err := r1cs.CommitmentInfo.Single()
+	if err != nil {
+		return err
+	}
+
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.GetNbPublicVariables() + r1cs.GetNbSecretVariables()
-	nbPrivateCommittedWires := r1cs.CommitmentInfo.NbPrivateCommitted
+	nbPrivateCommittedWires := commitmentInfo.NbPrivateCommitted
 	nbPublicWires := r1cs.GetNbPublicVariables()
 	nbPrivateWires := r1cs.GetNbSecretVariables() + r1cs.NbInternalVariables - nbPrivateCommittedWires
 
-	if r1cs.CommitmentInfo.Is() { // the commitment itself is defined by a hint so the prover considers it private
+	if commitmentInfo.Is() { // the commitment itself is defined by a hint so the prover considers it private
 		nbPublicWires++  // but the verifier will need to inject the value itself so on the groth16
 		nbPrivateWires-- // level it must be considered public
 	}
@@ -105,8 +117,16 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(r1cs.GetNbConstraints()))
 
+	cfg, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if cfg.Accelerator != "" {
+		return fmt.Errorf("%w: setup accelerator on %s", backend.ErrNotSupported, curve.ID)
+	}
+
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(cfg.Sampler)
 	if err != nil {
 		return err
 	}
@@ -150,11 +170,11 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	}
 
 	vI, cI := 0, 0
-	privateCommitted := r1cs.CommitmentInfo.PrivateCommitted()
+	privateCommitted := commitmentInfo.PrivateCommitted()
 
 	for i := range A {
 		isCommittedPrivate := cI < len(privateCommitted) && i == privateCommitted[cI]
-		isCommitment := r1cs.CommitmentInfo.Is() && i == r1cs.CommitmentInfo.CommitmentIndex
+		isCommitment := commitmentInfo.Is() && i == commitmentInfo.CommitmentIndex
 		isPublic := i < r1cs.GetNbPublicVariables()
 
 		if isPublic || isCommittedPrivate || isCommitment {
@@ -256,13 +276,13 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	if nbPrivateCommittedWires != 0 {
 		commitmentBasis := g1PointsAff[offset:]
 
-		pk.CommitmentKey, vk.CommitmentKey, err = pedersen.Setup(commitmentBasis)
+		pk.CommitmentKey, vk.CommitmentKey, err = setupCommitmentKey(commitmentBasis, cfg.Sampler)
 		if err != nil {
 			return err
 		}
 	}
 
-	vk.CommitmentInfo = r1cs.CommitmentInfo // unfortunate but necessary
+	vk.CommitmentInfo = commitmentInfo // unfortunate but necessary
 
 	// ---------------------------------------------------------------------------------------------
 	// G2 scalars
@@ -411,40 +431,77 @@ type toxicWaste struct {
 	gammaInv, deltaInv           fr.Element
 }
 
-func sampleToxicWaste() (toxicWaste, error) {
+func sampleToxicWaste(sampler io.Reader) (toxicWaste, error) {
 
 	res := toxicWaste{}
 
-	for res.t.IsZero() {
-		if _, err := res.t.SetRandom(); err != nil {
+	for _, e := range []*fr.Element{&res.t, &res.alpha, &res.beta, &res.gamma, &res.delta} {
+		if err := sampleNonZero(sampler, e); err != nil {
 			return res, err
 		}
 	}
-	for res.alpha.IsZero() {
-		if _, err := res.alpha.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.beta.IsZero() {
-		if _, err := res.beta.SetRandom(); err != nil {
-			return res, err
+
+	res.gammaInv.Inverse(&res.gamma)
+	res.deltaInv.Inverse(&res.delta)
+
+	return res, nil
+}
+
+// sampleNonZero sets e to a non zero element read from sampler.
+func sampleNonZero(sampler io.Reader, e *fr.Element) error {
+	// read 16 more bytes than needed so that the reduction modulo r is statistically uniform
+	var buf [fr.Bytes + 16]byte
+	var v big.Int
+	for e.IsZero() {
+		if _, err := io.ReadFull(sampler, buf[:]); err != nil {
+			return fmt.Errorf("sampling setup secret: %w", err)
 		}
+		e.SetBigInt(v.SetBytes(buf[:]))
 	}
-	for res.gamma.IsZero() {
-		if _, err := res.gamma.SetRandom(); err != nil {
-			return res, err
-		}
+	return nil
+}
+
+// setupCommitmentKey is equivalent to pedersen.Setup, with the secrets read from sampler.
+func setupCommitmentKey(basis []curve.G1Affine, sampler io.Reader) (pk pedersen.ProvingKey, vk pedersen.VerifyingKey, err error) {
+	var g, sigma fr.Element
+	if err = sampleNonZero(sampler, &g); err != nil {
+		return
 	}
-	for res.delta.IsZero() {
-		if _, err := res.delta.SetRandom(); err != nil {
-			return res, err
-		}
+	if err = sampleNonZero(sampler, &sigma); err != nil {
+		return
 	}
 
-	res.gammaInv.Inverse(&res.gamma)
-	res.deltaInv.Inverse(&res.delta)
+	var b big.Int
+	var g2, gRootSigmaNeg curve.G2Affine
+	_, _, _, g2Gen := curve.Generators()
+	g2.ScalarMultiplication(&g2Gen, g.BigInt(&b))
+
+	// [-1/σ]G
+	var sigmaInvNeg fr.Element
+	sigmaInvNeg.Inverse(&sigma).Neg(&sigmaInvNeg)
+	gRootSigmaNeg.ScalarMultiplication(&g2, sigmaInvNeg.BigInt(&b))
+
+	basisExpSigma := make([]curve.G1Affine, len(basis))
+	sigma.BigInt(&b)
+	utils.Parallelize(len(basis), func(start, end int) {
+		for i := start; i < end; i++ {
+			basisExpSigma[i].ScalarMultiplication(&basis[i], &b)
+		}
+	})
 
-	return res, nil
+	// the fields of the keys are unexported: they are set through their encoding
+	var buf bytes.Buffer
+	enc := curve.NewEncoder(&buf)
+	for _, v := range []interface{}{basis, basisExpSigma, &g2, &gRootSigmaNeg} {
+		if err = enc.Encode(v); err != nil {
+			return
+		}
+	}
+	if _, err = pk.ReadFrom(&buf); err != nil {
+		return
+	}
+	_, err = vk.ReadFrom(&buf)
+	return
 }
 
 // DummySetup fills a random ProvingKey
@@ -481,7 +538,7 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	}
 
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(rand.Reader)
 	if err != nil {
 		return err
 	}
@@ -600,9 +657,14 @@ func (vk *VerifyingKey) CurveID() ecc.ID {
 	return curve.ID
 }
 
-// NbPublicWitness returns the number of elements in the expected public witness
+// NbPublicWitness returns the number of elements in the expected public witness. The
+// commitment wire follows the public wires in K, but isn't part of the public witness: the
+// verifier computes it from the commitment of the proof.
 func (vk *VerifyingKey) NbPublicWitness() int {
-	return (len(vk.G1.K) - 1)
+	if vk.CommitmentInfo.Is() {
+		return len(vk.G1.K) - 2
+	}
+	return len(vk.G1.K) - 1
 }
 
 // NbG1 returns the number of G1 elements in the VerifyingKey
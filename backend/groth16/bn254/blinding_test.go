@@ -0,0 +1,55 @@
+package groth16_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsafeNoZK(t *testing.T) {
+	assert := require.New(t)
+	t.Setenv(accel.EnvVar, "cpu")
+
+	// the options other than WithUnsafeNoZK never disable the blinding
+	cfg, err := backend.NewProverConfig(backend.WithSparseMSM(true), backend.WithAccelerator("cpu"), backend.WithMultiExpNbTasks(2))
+	assert.NoError(err)
+	assert.False(cfg.UnsafeNoZK)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sparseCircuit{}, frontend.IgnoreUnconstrainedInputs())
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&sparseCircuit{X: 0xff00ff, Y: 0xf0f0f0, Unused: 5, Sum: 8}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	prove := func(opts ...backend.ProverOption) []byte {
+		proof, err := groth16.Prove(ccs, pk, w, opts...)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, public))
+		var buf bytes.Buffer
+		_, err = proof.WriteRawTo(&buf)
+		assert.NoError(err)
+		return buf.Bytes()
+	}
+
+	// the default proofs are blinded, so that two proofs of a witness differ
+	blinded := prove()
+	assert.NotEqual(blinded, prove())
+	assert.NotEqual(prove(backend.WithSparseMSM(true)), prove(backend.WithSparseMSM(true)))
+
+	// without blinding, the proofs of all the paths of the prover are identical
+	unblinded := prove(backend.WithUnsafeNoZK())
+	assert.NotEqual(blinded, unblinded)
+	assert.Equal(unblinded, prove(backend.WithUnsafeNoZK()))
+	assert.Equal(unblinded, prove(backend.WithUnsafeNoZK(), backend.WithSparseMSM(false)))
+	assert.Equal(unblinded, prove(backend.WithUnsafeNoZK(), backend.WithSparseMSM(true)))
+}
@@ -0,0 +1,179 @@
+package groth16
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/internal/checkpoint"
+	"github.com/rs/zerolog"
+)
+
+// proofCheckpoint holds the results of a proof saved with backend.WithCheckpoint: the solution
+// with the commitments, and the multi-scalar multiplications before the blinding, which only
+// depend on the witness, so that a resumed proof is blinded with fresh randomness. A nil
+// proofCheckpoint saves and loads nothing.
+type proofCheckpoint struct {
+	dir *checkpoint.Dir
+	log zerolog.Logger
+}
+
+// openCheckpoint opens the checkpoint of the proof of the witness in the directory, removing
+// the results of another proof.
+func openCheckpoint(path string, r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, log zerolog.Logger) (*proofCheckpoint, error) {
+	h := sha256.New()
+	h.Write([]byte("gnark groth16 checkpoint v1 " + curve.ID.String()))
+	nbInternal, nbSecret, nbPublic := r1cs.GetNbVariables()
+	for _, v := range []uint64{uint64(r1cs.GetNbConstraints()), uint64(nbInternal), uint64(nbSecret), uint64(nbPublic), pk.Domain.Cardinality} {
+		_ = binary.Write(h, binary.BigEndian, v)
+	}
+	for _, p := range []*curve.G1Affine{&pk.G1.Alpha, &pk.G1.Beta, &pk.G1.Delta} {
+		b := p.Bytes()
+		h.Write(b[:])
+	}
+	if _, err := fullWitness.WriteTo(h); err != nil {
+		return nil, err
+	}
+	dir, err := checkpoint.Open(path, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &proofCheckpoint{dir: dir, log: log.With().Str("checkpoint", path).Logger()}, nil
+}
+
+// load reads the named result into the values, or returns false.
+func (c *proofCheckpoint) load(name string, r io.ReaderFrom) bool {
+	if c == nil {
+		return false
+	}
+	ok, err := c.dir.Load(name, r)
+	if err != nil {
+		c.log.Warn().Err(err).Str("result", name).Msg("loading a checkpointed result")
+		return false
+	}
+	if ok {
+		c.log.Info().Str("result", name).Msg("resuming from a checkpointed result")
+	}
+	return ok
+}
+
+// save stores the named result; the failures are logged, as the proof goes on without.
+func (c *proofCheckpoint) save(name string, w io.WriterTo) {
+	if c == nil {
+		return
+	}
+	if err := c.dir.Save(name, w); err != nil {
+		c.log.Warn().Err(err).Str("result", name).Msg("checkpointing a result")
+	}
+}
+
+func (c *proofCheckpoint) loadSolution(solution *cs.R1CSSolution, proof *Proof) bool {
+	return c.load("solution", &checkpointSolution{solution: solution, proof: proof})
+}
+
+func (c *proofCheckpoint) saveSolution(solution *cs.R1CSSolution, proof *Proof) {
+	c.save("solution", &checkpointSolution{solution: solution, proof: proof})
+}
+
+func (c *proofCheckpoint) loadG1(name string, p *curve.G1Jac) bool {
+	var a curve.G1Affine
+	if !c.load(name, checkpointPoint{&a}) {
+		return false
+	}
+	p.FromAffine(&a)
+	return true
+}
+
+func (c *proofCheckpoint) saveG1(name string, p *curve.G1Jac) {
+	if c == nil {
+		return
+	}
+	var a curve.G1Affine
+	a.FromJacobian(p)
+	c.save(name, checkpointPoint{&a})
+}
+
+func (c *proofCheckpoint) loadG2(name string, p *curve.G2Jac) bool {
+	var a curve.G2Affine
+	if !c.load(name, checkpointPoint{&a}) {
+		return false
+	}
+	p.FromAffine(&a)
+	return true
+}
+
+func (c *proofCheckpoint) saveG2(name string, p *curve.G2Jac) {
+	if c == nil {
+		return
+	}
+	var a curve.G2Affine
+	a.FromJacobian(p)
+	c.save(name, checkpointPoint{&a})
+}
+
+// remove removes the results, once the proof is computed.
+func (c *proofCheckpoint) remove() {
+	if c == nil {
+		return
+	}
+	if err := c.dir.Remove(); err != nil {
+		c.log.Warn().Err(err).Msg("removing the checkpoint")
+	}
+}
+
+// checkpointPoint is an affine point, in the raw encoding.
+type checkpointPoint struct {
+	p interface{}
+}
+
+func (c checkpointPoint) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	err := enc.Encode(c.p)
+	return enc.BytesWritten(), err
+}
+
+func (c checkpointPoint) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	err := dec.Decode(c.p)
+	return dec.BytesRead(), err
+}
+
+// checkpointSolution is the solution of a proof with its commitments.
+type checkpointSolution struct {
+	solution *cs.R1CSSolution
+	proof    *Proof
+}
+
+func (c *checkpointSolution) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	if err := enc.Encode(c.proof.Commitments); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(c.proof.CommitmentPoks); err != nil {
+		return enc.BytesWritten(), err
+	}
+	n, err := c.solution.WriteTo(w)
+	return enc.BytesWritten() + n, err
+}
+
+func (c *checkpointSolution) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	var commitments, poks []curve.G1Affine
+	if err := dec.Decode(&commitments); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&poks); err != nil {
+		return dec.BytesRead(), err
+	}
+	n, err := c.solution.ReadFrom(r)
+	if err != nil {
+		return dec.BytesRead() + n, err
+	}
+	if len(commitments) != 0 {
+		c.proof.Commitments, c.proof.CommitmentPoks = commitments, poks
+	}
+	return dec.BytesRead() + n, nil
+}
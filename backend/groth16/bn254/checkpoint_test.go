@@ -0,0 +1,116 @@
+package groth16_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+// stageRecorder records the stages of a proof, and panics at the start of the stage with the
+// label failAt, as if the process was killed.
+type stageRecorder struct {
+	failAt string
+
+	lock   sync.Mutex
+	stages []string
+}
+
+func (r *stageRecorder) OnStageStart(ctx context.Context, stage backend.Stage, meta backend.StageMetadata) context.Context {
+	if meta.Label != "" && meta.Label == r.failAt {
+		panic("killed")
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stages = append(r.stages, stage.String()+" "+meta.Label)
+	return ctx
+}
+
+func (r *stageRecorder) OnStageEnd(context.Context, backend.Stage, backend.StageMetadata, time.Duration) {
+}
+
+func (r *stageRecorder) has(stage backend.Stage, label string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, s := range r.stages {
+		if s == stage.String()+" "+label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckpoint(t *testing.T) {
+	assert := require.New(t)
+	t.Setenv(accel.EnvVar, "cpu")
+	dir := t.TempDir()
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	// the prover is killed at the last multi exp in G1
+	killed := &stageRecorder{failAt: "KRS"}
+	assert.Panics(func() {
+		_, _ = groth16.Prove(ccs, pk, w, backend.WithCheckpoint(dir), backend.WithTracer(killed))
+	})
+	assert.True(killed.has(backend.StageSolve, ""))
+
+	// and resumes after the multi exps it computed
+	resumed := &stageRecorder{}
+	proof, err := groth16.Prove(ccs, pk, w, backend.WithCheckpoint(dir), backend.WithTracer(resumed))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+	assert.False(resumed.has(backend.StageSolve, ""), "the solution is checkpointed")
+	assert.False(resumed.has(backend.StageComputeH, ""), "KRS2 is checkpointed")
+	assert.False(resumed.has(backend.StageMSMG1, "AR1"), "AR1 is checkpointed")
+	assert.True(resumed.has(backend.StageMSMG1, "KRS"))
+
+	// the results are removed once the proof is computed
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(err)
+	assert.Empty(files)
+
+	// the results of another witness are not reused
+	killed = &stageRecorder{failAt: "KRS"}
+	assert.Panics(func() {
+		_, _ = groth16.Prove(ccs, pk, w, backend.WithCheckpoint(dir), backend.WithTracer(killed))
+	})
+	other, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 3}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	resumed = &stageRecorder{}
+	_, err = groth16.Prove(ccs, pk, other, backend.WithCheckpoint(dir), backend.WithTracer(resumed))
+	assert.Error(err, "the witness doesn't solve the circuit")
+	assert.True(resumed.has(backend.StageSolve, ""))
+
+	// nor the corrupted results
+	killed = &stageRecorder{failAt: "KRS"}
+	assert.Panics(func() {
+		_, _ = groth16.Prove(ccs, pk, w, backend.WithCheckpoint(dir), backend.WithTracer(killed))
+	})
+	solution := filepath.Join(dir, "solution.ckpt")
+	data, err := os.ReadFile(solution)
+	assert.NoError(err)
+	data[len(data)/2] ^= 1
+	assert.NoError(os.WriteFile(solution, data, 0o600))
+	resumed = &stageRecorder{}
+	proof, err = groth16.Prove(ccs, pk, w, backend.WithCheckpoint(dir), backend.WithTracer(resumed))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+	assert.True(resumed.has(backend.StageSolve, ""))
+}
@@ -0,0 +1,178 @@
+package groth16
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend"
+)
+
+// CommitmentKey is the Pedersen commitment key of a commitment, embedded in a ProvingKey. It
+// can be distributed on its own to parties which need to commit to the private committed wires
+// of a circuit (e.g. witness generators) without holding the full proving key.
+//
+// Values committed with the key of the i-th commitment must be ordered as
+// r1cs.CommitmentInfo[i].PrivateCommitted().
+type CommitmentKey struct {
+	Basis         []curve.G1Affine
+	BasisExpSigma []curve.G1Affine
+}
+
+// ExportCommitmentKey returns the commitment key of the i-th commitment of the ProvingKey. The
+// returned key shares memory with pk.
+func (pk *ProvingKey) ExportCommitmentKey(i int) *CommitmentKey {
+	return &CommitmentKey{
+		Basis:         pk.CommitmentKeys[i].Basis,
+		BasisExpSigma: pk.CommitmentKeys[i].BasisExpSigma,
+	}
+}
+
+// Commit computes the commitment to values and its proof of knowledge. The result is
+// identical to the one computed by Prove for the same values.
+func (ck *CommitmentKey) Commit(values []fr.Element) (commitment, pok curve.G1Affine, err error) {
+	if len(values) != len(ck.Basis) {
+		err = fmt.Errorf("expected %d values to commit to, got %d", len(ck.Basis), len(values))
+		return
+	}
+	key := pedersen.ProvingKey{Basis: ck.Basis, BasisExpSigma: ck.BasisExpSigma}
+	return key.Commit(values)
+}
+
+// Validate checks that the commitment key is consistent with the i-th commitment of the
+// VerifyingKey: its size matches the number of private committed wires and a random linear
+// combination of the basis passes the proof of knowledge check.
+func (ck *CommitmentKey) Validate(vk *VerifyingKey, i int) error {
+	if i < 0 || i >= len(vk.CommitmentInfo) || i >= len(vk.CommitmentKeys) {
+		return fmt.Errorf("verifying key has no commitment %d", i)
+	}
+	if len(ck.Basis) != len(ck.BasisExpSigma) {
+		return errors.New("commitment key basis length mismatch")
+	}
+	if len(ck.Basis) != vk.CommitmentInfo[i].NbPrivateCommitted {
+		return fmt.Errorf("commitment key has %d elements, verifying key expects %d", len(ck.Basis), vk.CommitmentInfo[i].NbPrivateCommitted)
+	}
+	if len(ck.Basis) == 0 {
+		return nil
+	}
+
+	r := make([]fr.Element, len(ck.Basis))
+	for i := range r {
+		if _, err := r[i].SetRandom(); err != nil {
+			return err
+		}
+	}
+	var basis, basisExpSigma curve.G1Affine
+	if _, err := basis.MultiExp(ck.Basis, r, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err := basisExpSigma.MultiExp(ck.BasisExpSigma, r, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if err := vk.CommitmentKeys[i].Verify(basis, basisExpSigma); err != nil {
+		return fmt.Errorf("commitment key doesn't match verifying key: %w", err)
+	}
+	return nil
+}
+
+// WriteTo writes binary encoding of the commitment key to writer
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
+func (ck *CommitmentKey) WriteTo(w io.Writer) (int64, error) {
+	return ck.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the commitment key to writer
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (ck *CommitmentKey) WriteRawTo(w io.Writer) (int64, error) {
+	return ck.writeTo(w, true)
+}
+
+func (ck *CommitmentKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	if err := enc.Encode(ck.Basis); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(ck.BasisExpSigma); err != nil {
+		return enc.BytesWritten(), err
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom attempts to decode a commitment key from reader
+// the key must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+func (ck *CommitmentKey) ReadFrom(r io.Reader) (int64, error) {
+	return ck.readFrom(r)
+}
+
+// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
+// or in the correct subgroup
+func (ck *CommitmentKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return ck.readFrom(r, curve.NoSubgroupChecks())
+}
+
+func (ck *CommitmentKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	dec := curve.NewDecoder(r, decOptions...)
+
+	if err := dec.Decode(&ck.Basis); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&ck.BasisExpSigma); err != nil {
+		return dec.BytesRead(), err
+	}
+	return dec.BytesRead(), nil
+}
+
+// WithCommitment returns a prover option making Prove use a commitment computed
+// beforehand with CommitmentKey.Commit instead of committing to the private committed
+// wires itself. It is WithCommitments for circuits with a single commitment.
+func WithCommitment(commitment, pok curve.G1Affine) backend.ProverOption {
+	return WithCommitments([]curve.G1Affine{commitment}, []curve.G1Affine{pok})
+}
+
+// WithCommitments returns a prover option making Prove use the commitments computed
+// beforehand with CommitmentKey.Commit, and their proofs of knowledge, one per commitment of
+// the circuit.
+func WithCommitments(commitments, poks []curve.G1Affine) backend.ProverOption {
+	if len(commitments) != len(poks) {
+		return func(*backend.ProverConfig) error {
+			return fmt.Errorf("got %d commitments and %d proofs of knowledge", len(commitments), len(poks))
+		}
+	}
+	b := make([]byte, 0, 2*len(commitments)*curve.SizeOfG1AffineUncompressed)
+	for i := range commitments {
+		b = append(b, commitments[i].Marshal()...)
+		b = append(b, poks[i].Marshal()...)
+	}
+	return backend.WithCommitment(b)
+}
+
+// decodeCommitments decodes the commitments and their proofs of knowledge encoded by
+// WithCommitments.
+func decodeCommitments(b []byte) ([][2]curve.G1Affine, error) {
+	const pairSize = 2 * curve.SizeOfG1AffineUncompressed
+	if len(b)%pairSize != 0 {
+		return nil, fmt.Errorf("invalid commitment encoding length %d", len(b))
+	}
+	res := make([][2]curve.G1Affine, len(b)/pairSize)
+	for i := range res {
+		for j := range res[i] {
+			offset := i*pairSize + j*curve.SizeOfG1AffineUncompressed
+			if _, err := res[i][j].SetBytes(b[offset : offset+curve.SizeOfG1AffineUncompressed]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
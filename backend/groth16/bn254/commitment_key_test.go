@@ -0,0 +1,52 @@
+package groth16_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitmentKeyOutsideProver(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+
+	// serialization round trip
+	ck := pk.(*groth16_bn254.ProvingKey).ExportCommitmentKey(0)
+	var buf bytes.Buffer
+	written, err := ck.WriteTo(&buf)
+	assert.NoError(err)
+
+	var ckRead groth16_bn254.CommitmentKey
+	read, err := ckRead.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(written, read)
+	assert.NoError(ckRead.Validate(vk.(*groth16_bn254.VerifyingKey), 0))
+
+	// commit locally to the private committed wire (One) and prove with it
+	values := make([]fr.Element, 1)
+	values[0].SetOne()
+	commitment, pok, err := ckRead.Commit(values)
+	assert.NoError(err)
+
+	assignment := &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	proof, err := groth16.Prove(_r1cs, pk, fullWitness, groth16_bn254.WithCommitment(commitment, pok))
+	assert.NoError(err)
+	assert.True(commitment.Equal(&proof.(*groth16_bn254.Proof).Commitments[0]))
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	// a key of the wrong size is rejected
+	ckRead.Basis = ckRead.Basis[:0]
+	assert.Error(ckRead.Validate(vk.(*groth16_bn254.VerifyingKey), 0))
+}
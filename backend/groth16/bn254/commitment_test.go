@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16_test
 
 import (
@@ -22,6 +20,7 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
@@ -148,3 +147,52 @@ func TestOneSecretOnePublicCommitted(t *testing.T) {
 		Two: 2,
 	})
 }
+
+type twoCommitmentsCircuit struct {
+	One, Two frontend.Variable
+	Three    frontend.Variable `gnark:",public"`
+	shared   bool              // commit to One twice
+}
+
+func (c *twoCommitmentsCircuit) Define(api frontend.API) error {
+	commitCompiler, ok := api.Compiler().(frontend.Committer)
+	if !ok {
+		return fmt.Errorf("compiler does not commit")
+	}
+	commit1, err := commitCompiler.Commit(c.One, c.Three)
+	if err != nil {
+		return err
+	}
+	second := c.Two
+	if c.shared {
+		second = c.One
+	}
+	commit2, err := commitCompiler.Commit(second, c.Three)
+	if err != nil {
+		return err
+	}
+
+	api.AssertIsDifferent(commit1, commit2)
+	api.AssertIsEqual(c.One, 1)
+	api.AssertIsEqual(c.Two, 2)
+	api.AssertIsEqual(c.Three, 3)
+	return nil
+}
+
+func TestTwoCommitments(t *testing.T) {
+	assignment := &twoCommitmentsCircuit{One: 1, Two: 2, Three: 3}
+	_r1cs, pk, vk := setup(t, &twoCommitmentsCircuit{})
+	public, proof := prove(t, assignment, _r1cs, pk)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+
+	// each commitment is bound to its own key
+	p := proof.(*groth16_bn254.Proof)
+	assert.Len(t, p.Commitments, 2)
+	p.Commitments[0], p.Commitments[1] = p.Commitments[1], p.Commitments[0]
+	p.CommitmentPoks[0], p.CommitmentPoks[1] = p.CommitmentPoks[1], p.CommitmentPoks[0]
+	assert.Error(t, groth16.Verify(proof, vk, public))
+
+	// secret variables can't be committed to twice
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{shared: true})
+	assert.Error(t, err)
+}
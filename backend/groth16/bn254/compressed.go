@@ -0,0 +1,20 @@
+package groth16
+
+import (
+	"io"
+
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// WriteCompressed writes the proving key in chunks compressed, in parallel, with the codec
+// registered under its name (see gnark/io.RegisterCodec), gnark/io.CodecFlate by default. The
+// points aren't compressed, like WriteRawTo, so that ReadCompressed, which decompresses the
+// chunks in parallel, doesn't compute square roots.
+func (pk *ProvingKey) WriteCompressed(w io.Writer, codec string) (int64, error) {
+	return gnarkio.WriteCompressed(w, pk, codec)
+}
+
+// ReadCompressed reads a proving key written by WriteCompressed, like ReadFrom.
+func (pk *ProvingKey) ReadCompressed(r io.Reader) (int64, error) {
+	return gnarkio.ReadCompressed(r, pk)
+}
@@ -0,0 +1,49 @@
+package groth16_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCompressed(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &twoCommitmentsCircuit{})
+	_pk := pk.(*groth16_bn254.ProvingKey)
+
+	var buf bytes.Buffer
+	written, err := _pk.WriteCompressed(&buf, gnarkio.CodecFlate)
+	assert.NoError(err)
+	assert.Equal(int64(buf.Len()), written)
+
+	var read groth16_bn254.ProvingKey
+	n, err := read.ReadCompressed(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(written, n)
+
+	w, err := frontend.NewWitness(&twoCommitmentsCircuit{One: 1, Two: 2, Three: 3}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+	proof, err := groth16.Prove(_r1cs, &read, w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+
+	// the key read is the key written
+	var expected, rewritten bytes.Buffer
+	_, err = _pk.WriteRawTo(&expected)
+	assert.NoError(err)
+	_, err = read.WriteRawTo(&rewritten)
+	assert.NoError(err)
+	assert.Equal(expected.Bytes(), rewritten.Bytes())
+
+	_, err = _pk.WriteCompressed(&buf, "unknown")
+	assert.Error(err)
+}
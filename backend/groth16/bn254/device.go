@@ -0,0 +1,344 @@
+package groth16
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+	_ "github.com/consensys/gnark/backend/accel/cpu"    // registers the CPU emulation, selected by name
+	_ "github.com/consensys/gnark/backend/accel/icicle" // registers the CUDA provider, with the icicle build tag
+	_ "github.com/consensys/gnark/backend/accel/rocm"   // registers the AMD provider, with the rocm build tag
+)
+
+// BUCKET_FACTOR is the bucket factor of the multi-scalar multiplications on the device.
+const BUCKET_FACTOR int = accel.DefaultBucketFactor
+
+// OnDeviceData is a vector of size elements on the device.
+type OnDeviceData struct {
+	p    unsafe.Pointer
+	size int
+}
+
+// scalarsToDevice copies the scalars to the device, out of the Montgomery form.
+func scalarsToDevice(d accel.Device, scalars []fr.Element) (unsafe.Pointer, error) {
+	size := len(scalars) * fr.Bytes
+	scalars_d, err := d.Malloc(size)
+	if err != nil {
+		return nil, err
+	}
+	if len(scalars) != 0 {
+		if err := d.CopyToDevice(scalars_d, unsafe.Pointer(&scalars[0]), size); err != nil {
+			_ = d.Free(scalars_d)
+			return nil, err
+		}
+	}
+	if err := d.FromMontgomery(scalars_d, len(scalars)); err != nil {
+		_ = d.Free(scalars_d)
+		return nil, err
+	}
+	return scalars_d, nil
+}
+
+// g1AffineToDevice copies the points to the device in the layout, or returns nil if there are
+// none.
+func g1AffineToDevice(d accel.Device, points []curve.G1Affine, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+	return d.PointsG1ToDevice(unsafe.Pointer(&points[0]), len(points), cfg)
+}
+
+// g2AffineToDevice copies the points to the device in the layout, or returns nil if there are
+// none.
+func g2AffineToDevice(d accel.Device, points []curve.G2Affine, cfg accel.PointsConfig) (unsafe.Pointer, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+	return d.PointsG2ToDevice(unsafe.Pointer(&points[0]), len(points), cfg)
+}
+
+// msmG1 returns the multi-scalar multiplication of the n scalars and points on the device.
+func msmG1(d accel.Device, scalars_d, points_d unsafe.Pointer, n int, cfg accel.PointsConfig) (curve.G1Jac, error) {
+	var res curve.G1Jac
+	if n == 0 {
+		res.FromAffine(&curve.G1Affine{})
+		return res, nil
+	}
+	err := d.Msm(unsafe.Pointer(&res), scalars_d, points_d, n, accel.MsmConfig{BucketFactor: BUCKET_FACTOR, Points: cfg})
+	return res, err
+}
+
+// msmG2 returns the multi-scalar multiplication of the n scalars and points on the device.
+func msmG2(d accel.Device, scalars_d, points_d unsafe.Pointer, n int, cfg accel.PointsConfig, nbTasks int) (curve.G2Jac, error) {
+	var res curve.G2Jac
+	if n == 0 {
+		res.FromAffine(&curve.G2Affine{})
+		return res, nil
+	}
+	err := d.MsmG2(unsafe.Pointer(&res), scalars_d, points_d, n, accel.MsmConfig{BucketFactor: BUCKET_FACTOR, Points: cfg, NbTasks: nbTasks})
+	return res, err
+}
+
+// checkMultiExpNbTasks returns an error if the device doesn't run the multi-exponentiation in G2
+// on nbTasks CPU tasks, as the CPU emulation does: see backend.WithMultiExpNbTasks.
+func checkMultiExpNbTasks(d accel.Device, nbTasks int) error {
+	if nbTasks == 0 || d == nil {
+		return nil
+	}
+	// the providers return ErrUnsupported whatever the number of scalars
+	var res curve.G2Jac
+	if err := d.MsmG2(unsafe.Pointer(&res), nil, nil, 0, accel.MsmConfig{NbTasks: nbTasks}); err != nil {
+		return fmt.Errorf("multi-exponentiation on %d tasks on %s: %w", nbTasks, d.Name(), err)
+	}
+	return nil
+}
+
+// Accelerator returns the name of the accelerator provider of the device copies of the proving
+// key, see backend.WithAccelerator. It is empty for a key read by ReadFrom until its copies are
+// made, at its first proof.
+func (pk *ProvingKey) Accelerator() string {
+	return pk.accelerator
+}
+
+// Device returns the device holding the device copies of the proving key, or nil if it has
+// none. Its live buffers are listed by accel.Allocations.
+func (pk *ProvingKey) Device() accel.Device {
+	return pk.device
+}
+
+// SetAccelerator copies the proving key to a device of the named accelerator provider, and
+// releases the copies on the previous device. If an error is returned, the proving key has no
+// device copies until SetAccelerator succeeds. It must not be called during a proof, or while
+// a Pipeline of the proving key is open.
+func (pk *ProvingKey) SetAccelerator(name string) error {
+	if err := pk.waitG2(); err != nil {
+		return err
+	}
+	if pk.device != nil {
+		pk.freeDevice()
+	}
+	pk.device, pk.accelerator = nil, name
+	return pk.setupDevicePointers()
+}
+
+// deviceLock is held while the device copies of a proving key are made by ensureDevice, so that
+// the concurrent first proofs of a key copy it once. It is global as the keys are copied by
+// value, by mpcsetup.ExtractKeys for instance.
+var deviceLock sync.Mutex
+
+// ensureDevice copies the proving key to a device if it has no device copies, as after
+// ReadFrom: of its accelerator provider if it has one, or else of the named one, or else of the
+// one of the environment variable GNARK_ACCEL, see setupDevicePointers: without a name, a key
+// of a CPU-only build keeps no device copies. It is safe for concurrent use.
+func (pk *ProvingKey) ensureDevice(name string) error {
+	deviceLock.Lock()
+	defer deviceLock.Unlock()
+	if pk.device != nil {
+		return nil
+	}
+	previous := pk.accelerator
+	if previous == "" {
+		pk.accelerator = name
+	}
+	if err := pk.setupDevicePointers(); err != nil {
+		if pk.device != nil {
+			pk.freeDevice()
+		}
+		pk.device, pk.accelerator = nil, previous
+		return err
+	}
+	return nil
+}
+
+// releaseDevice releases the device copies of the proving key, if any, before it is read
+// again. The accelerator provider is kept for the next copies.
+func (pk *ProvingKey) releaseDevice() {
+	if pk.device != nil {
+		pk.freeDevice()
+		pk.device = nil
+	}
+}
+
+// freeDevice releases the device copies of the proving key.
+func (pk *ProvingKey) freeDevice() {
+	pk.freeDevicePoints()
+	pk.G1Device.A, pk.G1Device.B, pk.G1Device.K, pk.G1Device.Z, pk.G2Device.B = nil, nil, nil, nil, nil
+	ptrs := []unsafe.Pointer{pk.DomainDevice.Twiddles, pk.DomainDevice.TwiddlesInv, pk.DomainDevice.CosetTable, pk.DomainDevice.CosetTableInv, pk.DenDevice}
+	pk.DomainDevice.Twiddles, pk.DomainDevice.TwiddlesInv, pk.DomainDevice.CosetTable, pk.DomainDevice.CosetTableInv, pk.DenDevice = nil, nil, nil, nil, nil
+	for i := range pk.CommitmentKeysDevice {
+		ptrs = append(ptrs, pk.CommitmentKeysDevice[i].Basis, pk.CommitmentKeysDevice[i].BasisExpSigma)
+	}
+	pk.CommitmentKeysDevice = nil
+	for _, p := range ptrs {
+		if p != nil {
+			_ = pk.device.Free(p)
+		}
+	}
+}
+
+// DeviceLayout is the layout on the device of the points of each MSM of the prover. The zero
+// value, affine points without the points at infinity, is supported by all the providers.
+//
+// Keeping the points at infinity of A, B or K saves the filtering of their scalars on the host
+// at each proof, and the projective points save the conversions of some devices, both at the
+// cost of device memory.
+type DeviceLayout struct {
+	A, B, K, Z accel.PointsConfig
+
+	// G2B is the layout of the points of B in G2. Its MSM shares the scalars of B in G1, so that
+	// both must keep, or both remove, the points at infinity.
+	G2B accel.PointsConfig
+}
+
+// DeviceLayout returns the layout of the device copies of the points.
+func (pk *ProvingKey) DeviceLayout() DeviceLayout {
+	return pk.deviceLayout
+}
+
+// SetDeviceLayout copies the points of the proving key to the device again, in the layout, and
+// releases the previous copies. If an error is returned, for instance accel.ErrUnsupported by
+// the provider, the previous copies are kept. A key without device copies, as after ReadFrom,
+// is copied to the device in the layout. It must not be called during a proof.
+func (pk *ProvingKey) SetDeviceLayout(layout DeviceLayout) error {
+	if layout.B.WithInfinity != layout.G2B.WithInfinity {
+		return errors.New("the points of B in G1 and G2 must both keep or both remove the points at infinity")
+	}
+	if pk.device == nil {
+		// the copies of a key read by ReadFrom are made in the layout
+		previous := pk.deviceLayout
+		pk.deviceLayout = layout
+		if err := pk.ensureDevice(""); err != nil {
+			pk.deviceLayout = previous
+			return err
+		}
+		return nil
+	}
+	if err := pk.waitG2(); err != nil {
+		return err
+	}
+
+	previous, previousG2, previousInfK, previousLayout := pk.G1Device, pk.G2Device, pk.G1InfPointIndices.K, pk.deviceLayout
+	pk.deviceLayout = layout
+	err := pk.setupDevicePoints()
+	if err != nil {
+		pk.freeDevicePoints()
+		pk.G1Device, pk.G2Device, pk.G1InfPointIndices.K, pk.deviceLayout = previous, previousG2, previousInfK, previousLayout
+		return err
+	}
+	for _, p := range []unsafe.Pointer{previous.A, previous.B, previous.K, previous.Z, previousG2.B} {
+		if p != nil {
+			_ = pk.device.Free(p)
+		}
+	}
+	return nil
+}
+
+// setupDevicePoints copies the points of A, B, K and Z in G1, and of B in G2, to the device in
+// the layout of the proving key.
+func (pk *ProvingKey) setupDevicePoints() error {
+	device, layout := pk.device, pk.deviceLayout
+	pk.G1Device.A, pk.G1Device.B, pk.G1Device.K, pk.G1Device.Z, pk.G2Device.B = nil, nil, nil, nil, nil
+	pk.G1InfPointIndices.K = nil
+	var err error
+
+	/*************************  Start G1 Device Setup  ***************************/
+	/*************************     A      ***************************/
+	pointsA := pk.G1.A
+	if layout.A.WithInfinity {
+		pointsA = withInfinityG1(pk.G1.A, pk.InfinityA)
+	}
+	if pk.G1Device.A, err = g1AffineToDevice(device, pointsA, layout.A); err != nil {
+		return err
+	}
+
+	/*************************     B      ***************************/
+	pointsB := pk.G1.B
+	if layout.B.WithInfinity {
+		pointsB = withInfinityG1(pk.G1.B, pk.InfinityB)
+	}
+	if pk.G1Device.B, err = g1AffineToDevice(device, pointsB, layout.B); err != nil {
+		return err
+	}
+
+	/*************************     K      ***************************/
+	pointsK := pk.G1.K
+	if !layout.K.WithInfinity {
+		//remove infinity points and save indices for removing scalars later
+		// TODO, find better way to save mem
+		pointsK = nil
+		for i, gnarkPoint := range pk.G1.K {
+			if gnarkPoint.IsInfinity() {
+				pk.G1InfPointIndices.K = append(pk.G1InfPointIndices.K, i)
+			} else {
+				pointsK = append(pointsK, gnarkPoint)
+			}
+		}
+	}
+	if pk.G1Device.K, err = g1AffineToDevice(device, pointsK, layout.K); err != nil {
+		return err
+	}
+
+	/*************************     Z      ***************************/
+	if pk.G1Device.Z, err = g1AffineToDevice(device, pk.G1.Z, layout.Z); err != nil {
+		return err
+	}
+	/*************************  End G1 Device Setup  ***************************/
+
+	// the points of B in G2 being read are copied once read, see ReadSegmented
+	if pk.loadingG2() {
+		return nil
+	}
+	return pk.setupDeviceG2()
+}
+
+// setupDeviceG2 copies the points of B in G2 to the device in the layout of the proving key.
+func (pk *ProvingKey) setupDeviceG2() error {
+	if pk.device == nil {
+		// the key is proved on the CPU, see setupDevicePointers
+		return nil
+	}
+	pointsG2B := pk.G2.B
+	if pk.deviceLayout.G2B.WithInfinity {
+		pointsG2B = withInfinityG2(pk.G2.B, pk.InfinityB)
+	}
+	var err error
+	pk.G2Device.B, err = g2AffineToDevice(pk.device, pointsG2B, pk.deviceLayout.G2B)
+	return err
+}
+
+// freeDevicePoints releases the device copies set by setupDevicePoints.
+func (pk *ProvingKey) freeDevicePoints() {
+	for _, p := range []unsafe.Pointer{pk.G1Device.A, pk.G1Device.B, pk.G1Device.K, pk.G1Device.Z, pk.G2Device.B} {
+		if p != nil {
+			_ = pk.device.Free(p)
+		}
+	}
+}
+
+// withInfinityG1 returns the points, without the points at infinity, with the points at
+// infinity inserted back at their indices.
+func withInfinityG1(points []curve.G1Affine, infinity []bool) []curve.G1Affine {
+	res := make([]curve.G1Affine, len(infinity))
+	for i, j := 0, 0; i < len(res); i++ {
+		if !infinity[i] {
+			res[i] = points[j]
+			j++
+		}
+	}
+	return res
+}
+
+func withInfinityG2(points []curve.G2Affine, infinity []bool) []curve.G2Affine {
+	res := make([]curve.G2Affine, len(infinity))
+	for i, j := 0, 0; i < len(res); i++ {
+		if !infinity[i] {
+			res[i] = points[j]
+			j++
+		}
+	}
+	return res
+}
@@ -0,0 +1,160 @@
+package groth16
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+// CheckDeviceConversion checks that the device of the proving key converts its points and the
+// scalars the way the prover expects, to catch the layout or endianness changes of an
+// accelerator library upgrade before a proof is attempted. It is meant to be called once at startup.
+//
+// A random sample of nbSamples points of each vector of the key is converted and copied to the
+// device as by the setup, in the layout of the key, and the multi-exponentiations of the sample
+// computed on the device are converted back and compared to the ones on the CPU: first with
+// unit scalars, which checks the points, then with random scalars, which checks the scalars.
+func (pk *ProvingKey) CheckDeviceConversion(nbSamples int) error {
+	if err := pk.waitG2(); err != nil {
+		return err
+	}
+	if err := pk.ensureDevice(""); err != nil {
+		return err
+	}
+	d := pk.device
+	if d == nil {
+		// the key is proved on the CPU, see setupDevicePointers
+		return nil
+	}
+	rng := rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- the sample needn't be secret
+
+	for _, v := range []struct {
+		name   string
+		points []curve.G1Affine
+		cfg    accel.PointsConfig
+	}{{"A", pk.G1.A, pk.deviceLayout.A}, {"B", pk.G1.B, pk.deviceLayout.B}, {"K", pk.G1.K, pk.deviceLayout.K}, {"Z", pk.G1.Z, pk.deviceLayout.Z}} {
+		sample := sampleG1(rng, v.points, nbSamples)
+		if err := checkDeviceMsmG1(d, sample, unitScalars(len(sample)), v.cfg); err != nil {
+			return fmt.Errorf("G1 points of %s: %w", v.name, err)
+		}
+		if err := checkDeviceMsmG1(d, sample, randomScalars(rng, len(sample)), v.cfg); err != nil {
+			return fmt.Errorf("scalars of the MSM on %s: %w", v.name, err)
+		}
+	}
+
+	sample := sampleG2(rng, pk.G2.B, nbSamples)
+	if err := checkDeviceMsmG2(d, sample, unitScalars(len(sample)), pk.deviceLayout.G2B); err != nil {
+		return fmt.Errorf("G2 points of B: %w", err)
+	}
+	if err := checkDeviceMsmG2(d, sample, randomScalars(rng, len(sample)), pk.deviceLayout.G2B); err != nil {
+		return fmt.Errorf("scalars of the MSM on B in G2: %w", err)
+	}
+	return nil
+}
+
+func checkDeviceMsmG1(d accel.Device, points []curve.G1Affine, scalars []fr.Element, cfg accel.PointsConfig) error {
+	if len(points) == 0 {
+		return nil
+	}
+	points_d, err := g1AffineToDevice(d, points, cfg)
+	if err != nil {
+		return err
+	}
+	defer d.Free(points_d)
+	scalars_d, err := scalarsToDevice(d, scalars)
+	if err != nil {
+		return err
+	}
+	defer d.Free(scalars_d)
+
+	got, err := msmG1(d, scalars_d, points_d, len(points), cfg)
+	if err != nil {
+		return err
+	}
+	var expected curve.G1Affine
+	if _, err := expected.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	var gotAffine curve.G1Affine
+	gotAffine.FromJacobian(&got)
+	if !gotAffine.Equal(&expected) {
+		return fmt.Errorf("the device converts them differently: got %s, expected %s", gotAffine.String(), expected.String())
+	}
+	return nil
+}
+
+func checkDeviceMsmG2(d accel.Device, points []curve.G2Affine, scalars []fr.Element, cfg accel.PointsConfig) error {
+	if len(points) == 0 {
+		return nil
+	}
+	points_d, err := g2AffineToDevice(d, points, cfg)
+	if err != nil {
+		return err
+	}
+	defer d.Free(points_d)
+	scalars_d, err := scalarsToDevice(d, scalars)
+	if err != nil {
+		return err
+	}
+	defer d.Free(scalars_d)
+
+	got, err := msmG2(d, scalars_d, points_d, len(points), cfg, 0)
+	if err != nil {
+		return err
+	}
+	var expected curve.G2Affine
+	if _, err := expected.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	var gotAffine curve.G2Affine
+	gotAffine.FromJacobian(&got)
+	if !gotAffine.Equal(&expected) {
+		return fmt.Errorf("the device converts them differently: got %s, expected %s", gotAffine.String(), expected.String())
+	}
+	return nil
+}
+
+// sampleG1 returns up to n points drawn from the points which aren't at infinity, as the
+// device never receives those.
+func sampleG1(rng *rand.Rand, points []curve.G1Affine, n int) []curve.G1Affine {
+	var sample []curve.G1Affine
+	for tries := 0; len(sample) < n && tries < 2*n && len(points) != 0; tries++ {
+		p := points[rng.Intn(len(points))]
+		if !p.IsInfinity() {
+			sample = append(sample, p)
+		}
+	}
+	return sample
+}
+
+func sampleG2(rng *rand.Rand, points []curve.G2Affine, n int) []curve.G2Affine {
+	var sample []curve.G2Affine
+	for tries := 0; len(sample) < n && tries < 2*n && len(points) != 0; tries++ {
+		p := points[rng.Intn(len(points))]
+		if !p.IsInfinity() {
+			sample = append(sample, p)
+		}
+	}
+	return sample
+}
+
+func unitScalars(n int) []fr.Element {
+	scalars := make([]fr.Element, n)
+	for i := range scalars {
+		scalars[i].SetOne()
+	}
+	return scalars
+}
+
+func randomScalars(rng *rand.Rand, n int) []fr.Element {
+	scalars := make([]fr.Element, n)
+	for i := range scalars {
+		scalars[i].SetUint64(rng.Uint64())
+		scalars[i].Square(&scalars[i]) // spans the limbs
+	}
+	return scalars
+}
@@ -0,0 +1,239 @@
+package groth16
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	cs "github.com/consensys/gnark/constraint/bn254"
+)
+
+// ErrCircuitMismatch is returned by Prove when the proving key was set up for another circuit.
+var ErrCircuitMismatch = errors.New("the proving key wasn't set up for this constraint system")
+
+// ErrKeyCorrupted is returned when a key read doesn't have the hash it was written with.
+var ErrKeyCorrupted = errors.New("the key doesn't match its hash, it is corrupted")
+
+// hashesMagic starts the hashes at the end of a serialized proving key.
+const hashesMagic = "gnarkh\x00\x01"
+
+// KeyHashes are the integrity hashes of a key, set by Setup. The hash of the circuit is
+// checked by Prove, and the hash of the key when the key is read: a corrupted key or a key of
+// another circuit fails early instead of producing proofs which don't verify.
+//
+// The keys of older versions, or created otherwise (DummySetup, ...), have no hashes and aren't
+// checked, unless given with SetHashes. A key modified after Setup must have its hashes
+// updated, or cleared.
+type KeyHashes struct {
+	// Circuit is the hash of the constraint system, see cs.R1CS.Hash.
+	Circuit []byte `json:",omitempty"`
+
+	// Key is the hash of the key, see ProvingKey.Hash and VerifyingKey.Hash.
+	Key []byte `json:",omitempty"`
+}
+
+func (h *KeyHashes) isSet() bool {
+	return len(h.Circuit) != 0
+}
+
+// SetHashes sets the hashes of keys set up for the constraint system otherwise than by Setup,
+// for instance by the MPC ceremony of the mpcsetup package.
+func SetHashes(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+	return setHashes(r1cs, pk, vk)
+}
+
+// setHashes sets the hashes of the keys set up for the constraint system.
+func setHashes(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+	circuit := r1cs.Hash()
+	key, err := pk.Hash()
+	if err != nil {
+		return err
+	}
+	pk.Hashes = KeyHashes{Circuit: circuit, Key: key}
+	vk.Hashes = KeyHashes{Circuit: circuit, Key: vk.Hash()}
+	return nil
+}
+
+// checkCircuit returns ErrCircuitMismatch if the key has the hash of another constraint system.
+func (pk *ProvingKey) checkCircuit(r1cs *cs.R1CS) error {
+	if !pk.Hashes.isSet() {
+		return nil
+	}
+	if h := r1cs.Hash(); !bytes.Equal(h, pk.Hashes.Circuit) {
+		return fmt.Errorf("%w: the hash of the constraint system is %s, the key is for %s", ErrCircuitMismatch, shortHash(h), shortHash(pk.Hashes.Circuit))
+	}
+	return nil
+}
+
+// Hash returns the SHA-256 hash of the key, independent of its encoding: the hash of the
+// domain, the points and the commitment keys, hashed by sections in parallel.
+func (pk *ProvingKey) Hash() ([]byte, error) {
+	if err := pk.waitG2(); err != nil {
+		return nil, err
+	}
+	return pk.hash()
+}
+
+// hash is Hash, without waiting for the points of B in G2 read by ReadSegmented.
+func (pk *ProvingKey) hash() ([]byte, error) {
+	nbWires := uint64(len(pk.InfinityA))
+	header := []interface{}{
+		&pk.G1.Alpha, &pk.G1.Beta, &pk.G1.Delta,
+		&pk.G2.Beta, &pk.G2.Delta,
+		nbWires, pk.NbInfinityA, pk.NbInfinityB, pk.InfinityA, pk.InfinityB,
+		uint64(len(pk.CommitmentKeys)),
+	}
+	for i := range pk.CommitmentKeys {
+		header = append(header, pk.CommitmentKeys[i].Basis, pk.CommitmentKeys[i].BasisExpSigma)
+	}
+	sections := [][]interface{}{header, {pk.G1.A}, {pk.G1.B}, {pk.G1.Z}, {pk.G1.K}, {pk.G2.B}}
+
+	digests := make([][]byte, len(sections))
+	errs := make([]error, len(sections))
+	var wg sync.WaitGroup
+	for i := range sections {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := sha256.New()
+			if i == 0 {
+				if _, err := pk.Domain.WriteTo(h); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+			enc := curve.NewEncoder(h, curve.RawEncoding())
+			for _, v := range sections[i] {
+				if err := enc.Encode(v); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+			digests[i] = h.Sum(nil)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte("gnark groth16 bn254 proving key v1\x00"))
+	for _, d := range digests {
+		h.Write(d)
+	}
+	return h.Sum(nil), nil
+}
+
+// Hash returns the SHA-256 hash of the key, independent of its encoding: the hash of the
+// points, the commitment keys, the commitments and the features. The setup log and the hashes
+// of the key aren't hashed.
+func (vk *VerifyingKey) Hash() []byte {
+	h := sha256.New()
+	h.Write([]byte("gnark groth16 bn254 verifying key v1\x00"))
+	enc := curve.NewEncoder(h, curve.RawEncoding())
+	toEncode := []interface{}{
+		&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta,
+		vk.G1.K,
+		uint64(len(vk.CommitmentKeys)),
+	}
+	for i := range vk.CommitmentKeys {
+		toEncode = append(toEncode, &vk.CommitmentKeys[i].G, &vk.CommitmentKeys[i].GRootSigmaNeg)
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			panic(err) // the hash doesn't fail
+		}
+	}
+	for _, v := range []interface{}{vk.CommitmentInfo, vk.Features} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			panic(err) // plain values
+		}
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// checkHash returns ErrKeyCorrupted if the verifying key read doesn't have its hash.
+func (vk *VerifyingKey) checkHash() error {
+	if len(vk.Hashes.Key) == 0 {
+		return nil
+	}
+	if h := vk.Hash(); subtle.ConstantTimeCompare(h, vk.Hashes.Key) != 1 {
+		return fmt.Errorf("verifying key: %w: its hash is %s, not %s", ErrKeyCorrupted, shortHash(h), shortHash(vk.Hashes.Key))
+	}
+	return nil
+}
+
+// checkHash returns ErrKeyCorrupted if the proving key read doesn't have its hash.
+func (pk *ProvingKey) checkHash() error {
+	if len(pk.Hashes.Key) == 0 {
+		return nil
+	}
+	h, err := pk.hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h, pk.Hashes.Key) {
+		return fmt.Errorf("proving key: %w: its hash is %s, not %s", ErrKeyCorrupted, shortHash(h), shortHash(pk.Hashes.Key))
+	}
+	return nil
+}
+
+// writeHashes writes the hashes of the proving key at the end of its encoding, if it has any:
+// hashesMagic, then the hashes of the circuit and of the key.
+func (pk *ProvingKey) writeHashes(w io.Writer) (int64, error) {
+	if !pk.Hashes.isSet() {
+		return 0, nil
+	}
+	if len(pk.Hashes.Circuit) != sha256.Size || len(pk.Hashes.Key) != sha256.Size {
+		return 0, errors.New("invalid proving key hashes")
+	}
+	b := make([]byte, 0, len(hashesMagic)+2*sha256.Size)
+	b = append(b, hashesMagic...)
+	b = append(b, pk.Hashes.Circuit...)
+	b = append(b, pk.Hashes.Key...)
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// readHashes reads the hashes at the end of the encoding of a proving key, if any: the keys
+// without hashes end before.
+func (pk *ProvingKey) readHashes(r io.Reader) (int64, error) {
+	pk.Hashes = KeyHashes{}
+	b := make([]byte, len(hashesMagic)+2*sha256.Size)
+	n, err := io.ReadFull(r, b[:len(hashesMagic)])
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return int64(n), err
+	}
+	if string(b[:len(hashesMagic)]) != hashesMagic {
+		return int64(n), errors.New("invalid data at the end of the proving key")
+	}
+	m, err := io.ReadFull(r, b[len(hashesMagic):])
+	if err != nil {
+		return int64(n + m), err
+	}
+	b = b[len(hashesMagic):]
+	pk.Hashes = KeyHashes{Circuit: b[:sha256.Size], Key: b[sha256.Size:]}
+	return int64(n + m), nil
+}
+
+func shortHash(h []byte) string {
+	if len(h) > 8 {
+		h = h[:8]
+	}
+	return hex.EncodeToString(h)
+}
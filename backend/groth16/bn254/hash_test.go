@@ -0,0 +1,93 @@
+package groth16_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashes(t *testing.T) {
+	assert := require.New(t)
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_pk, _vk := pk.(*groth16_bn254.ProvingKey), vk.(*groth16_bn254.VerifyingKey)
+
+	// the hash of a system only depends on its constraints
+	again, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+	assert.Equal(_r1cs.(*cs.R1CS).Hash(), again.(*cs.R1CS).Hash())
+	assert.Equal(_r1cs.(*cs.R1CS).Hash(), _pk.Hashes.Circuit)
+	assert.Equal(_r1cs.(*cs.R1CS).Hash(), _vk.Hashes.Circuit)
+	other, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{})
+	assert.NoError(err)
+	assert.NotEqual(_r1cs.(*cs.R1CS).Hash(), other.(*cs.R1CS).Hash())
+
+	// the hashes don't depend on the encoding
+	for _, compressed := range []bool{true, false} {
+		var bufPk, bufVk bytes.Buffer
+		var readPk groth16_bn254.ProvingKey
+		var readVk groth16_bn254.VerifyingKey
+		if compressed {
+			_, err = _pk.WriteTo(&bufPk)
+			assert.NoError(err)
+			_, err = _vk.WriteTo(&bufVk)
+		} else {
+			_, err = _pk.WriteRawTo(&bufPk)
+			assert.NoError(err)
+			_, err = _vk.WriteRawTo(&bufVk)
+		}
+		assert.NoError(err)
+		_, err = readPk.ReadFrom(&bufPk)
+		assert.NoError(err)
+		_, err = readVk.ReadFrom(&bufVk)
+		assert.NoError(err)
+		assert.Equal(_pk.Hashes, readPk.Hashes)
+		assert.Equal(_vk.Hashes, readVk.Hashes)
+	}
+
+	// a key for another circuit doesn't prove
+	w, err := frontend.NewWitness(&twoCommitmentsCircuit{One: 1, Two: 2, Three: 3}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = groth16.Prove(other, pk, w)
+	assert.ErrorIs(err, groth16_bn254.ErrCircuitMismatch)
+
+	// the corrupted keys aren't read
+	var buf bytes.Buffer
+	_, err = _pk.WriteRawTo(&buf)
+	assert.NoError(err)
+	corrupted := buf.Bytes()
+	corrupted[len(corrupted)-1] ^= 1
+	_, err = new(groth16_bn254.ProvingKey).ReadFrom(bytes.NewReader(corrupted))
+	assert.ErrorIs(err, groth16_bn254.ErrKeyCorrupted)
+
+	_pk.G1.A[0], _pk.G1.A[1] = _pk.G1.A[1], _pk.G1.A[0]
+	buf.Reset()
+	_, err = _pk.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = new(groth16_bn254.ProvingKey).ReadFrom(&buf)
+	assert.ErrorIs(err, groth16_bn254.ErrKeyCorrupted)
+
+	_vk.G1.K[0] = _vk.G1.K[1]
+	buf.Reset()
+	_, err = _vk.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = new(groth16_bn254.VerifyingKey).ReadFrom(&buf)
+	assert.ErrorIs(err, groth16_bn254.ErrKeyCorrupted)
+
+	// the keys without hashes are read as before
+	_pk.Hashes = groth16_bn254.KeyHashes{}
+	buf.Reset()
+	_, err = _pk.WriteTo(&buf)
+	assert.NoError(err)
+	var legacy groth16_bn254.ProvingKey
+	_, err = legacy.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Empty(legacy.Hashes.Key)
+}
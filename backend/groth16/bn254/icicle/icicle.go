@@ -0,0 +1,39 @@
+// Package icicle_bn254 mirrors the package of the CUDA prover of upstream gnark, so that the code
+// written for it builds against this fork by changing nothing but the module version.
+//
+// The BN254 proving keys of this fork are the device ones: ProvingKey is an alias of the proving
+// key of backend/groth16/bn254, and the functions run its prover, on the accelerator selected as
+// described by backend.WithAccelerator.
+//
+// Deprecated: use backend/groth16/bn254, or the curve-erased backend/groth16 API.
+package icicle_bn254
+
+import (
+	"github.com/consensys/gnark/backend"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	cs "github.com/consensys/gnark/constraint/bn254"
+)
+
+// ProvingKey is the proving key of backend/groth16/bn254.
+type ProvingKey = groth16_bn254.ProvingKey
+
+// NewProvingKey returns an empty proving key, to read or to set up.
+func NewProvingKey() *ProvingKey {
+	return new(ProvingKey)
+}
+
+// Setup is groth16_bn254.Setup.
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *groth16_bn254.VerifyingKey, opts ...backend.SetupOption) error {
+	return groth16_bn254.Setup(r1cs, pk, vk, opts...)
+}
+
+// DummySetup is groth16_bn254.DummySetup.
+func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
+	return groth16_bn254.DummySetup(r1cs, pk)
+}
+
+// Prove is groth16_bn254.Prove.
+func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (*groth16_bn254.Proof, error) {
+	return groth16_bn254.Prove(r1cs, pk, fullWitness, opts...)
+}
@@ -12,15 +12,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend"
 )
 
 // WriteTo writes binary encoding of the Proof elements to writer
@@ -54,7 +57,10 @@ func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
 	if err := enc.Encode(&proof.Krs); err != nil {
 		return enc.BytesWritten(), err
 	}
-	if err := enc.Encode(&proof.Commitment); err != nil {
+	if err := enc.Encode(proof.Commitments); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(proof.CommitmentPoks); err != nil {
 		return enc.BytesWritten(), err
 	}
 	return enc.BytesWritten(), nil
@@ -75,10 +81,46 @@ func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
 	if err := dec.Decode(&proof.Krs); err != nil {
 		return dec.BytesRead(), err
 	}
+	if err := dec.Decode(&proof.Commitments); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&proof.CommitmentPoks); err != nil {
+		return dec.BytesRead(), err
+	}
+	if len(proof.Commitments) != len(proof.CommitmentPoks) {
+		return dec.BytesRead(), errors.New("invalid number of commitment proofs of knowledge")
+	}
+	// a proof without commitments has nil slices, as returned by Prove
+	if len(proof.Commitments) == 0 {
+		proof.Commitments, proof.CommitmentPoks = nil, nil
+	}
 
 	return dec.BytesRead(), nil
 }
 
+// ReadCanonicalFrom decodes a Proof like ReadFrom, but returns an error wrapping ErrNonCanonical
+// for the encodings neither WriteTo nor WriteRawTo return, which ReadFrom accepts: a proof
+// mixing compressed and uncompressed points. A proof then has exactly two encodings, for the
+// services keying the proofs by their bytes, for instance to reject the replayed proofs.
+func (proof *Proof) ReadCanonicalFrom(r io.Reader) (int64, error) {
+	var read bytes.Buffer
+	n, err := proof.ReadFrom(io.TeeReader(r, &read))
+	if err != nil {
+		return n, err
+	}
+	// the encoding of the first point tells the form of the others: its 2 most significant bits
+	// are 0 if it is uncompressed
+	raw := read.Bytes()[0]>>6 == 0
+	var canonical bytes.Buffer
+	if _, err := proof.writeTo(&canonical, raw); err != nil {
+		return n, err
+	}
+	if !bytes.Equal(canonical.Bytes(), read.Bytes()) {
+		return n, fmt.Errorf("%w: the points of the proof aren't all compressed or all uncompressed", ErrNonCanonical)
+	}
+	return n, nil
+}
+
 // WriteTo writes binary encoding of the key elements to writer
 // points are compressed
 // use WriteRawTo(...) to encode the key without point compression
@@ -130,10 +172,17 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 		return enc.BytesWritten(), err
 	}
 
-	if err := enc.Encode(&vk.CommitmentKey.G); err != nil {
+	// uint32(len(CommitmentKeys)),[G]2, uint32(len(CommitmentKeys)),[GRootSigmaNeg]2
+	g := make([]curve.G2Affine, len(vk.CommitmentKeys))
+	gRootSigmaNeg := make([]curve.G2Affine, len(vk.CommitmentKeys))
+	for i := range vk.CommitmentKeys {
+		g[i] = vk.CommitmentKeys[i].G
+		gRootSigmaNeg[i] = vk.CommitmentKeys[i].GRootSigmaNeg
+	}
+	if err := enc.Encode(g); err != nil {
 		return enc.BytesWritten(), err
 	}
-	if err := enc.Encode(&vk.CommitmentKey.GRootSigmaNeg); err != nil {
+	if err := enc.Encode(gRootSigmaNeg); err != nil {
 		return enc.BytesWritten(), err
 	}
 
@@ -146,6 +195,38 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 		return enc.BytesWritten(), err
 	}
 
+	// the setup log follows the commitment info as a second json value
+	b, err = json.Marshal(vk.SetupLog)
+	if err != nil {
+		return enc.BytesWritten(), err
+	}
+	_, err = w.Write(b)
+	if err != nil {
+		return enc.BytesWritten(), err
+	}
+
+	// and the features of the constraint system as a third one
+	b, err = json.Marshal(vk.Features)
+	if err != nil {
+		return enc.BytesWritten(), err
+	}
+	_, err = w.Write(b)
+	if err != nil {
+		return enc.BytesWritten(), err
+	}
+
+	// and the hashes, if any, as a fourth one
+	if vk.Hashes.isSet() {
+		b, err = json.Marshal(vk.Hashes)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+		_, err = w.Write(b)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
 	return enc.BytesWritten(), nil // TODO: Note, the commitmentinfo length is not in
 }
 
@@ -192,19 +273,52 @@ func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)
 		return dec.BytesRead(), err
 	}
 
-	if err := dec.Decode(&vk.CommitmentKey.G); err != nil {
+	var g, gRootSigmaNeg []curve.G2Affine
+	if err := dec.Decode(&g); err != nil {
 		return dec.BytesRead(), err
 	}
-	if err := dec.Decode(&vk.CommitmentKey.GRootSigmaNeg); err != nil {
+	if err := dec.Decode(&gRootSigmaNeg); err != nil {
 		return dec.BytesRead(), err
 	}
+	if len(g) != len(gRootSigmaNeg) {
+		return dec.BytesRead(), errors.New("invalid commitment keys")
+	}
+	vk.CommitmentKeys = nil
+	for i := range g {
+		vk.CommitmentKeys = append(vk.CommitmentKeys, pedersen.VerifyingKey{G: g[i], GRootSigmaNeg: gRootSigmaNeg[i]})
+	}
 
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return dec.BytesRead(), err
 	}
-	err = json.Unmarshal(b, &vk.CommitmentInfo)
-	if err != nil {
+	jsonDec := json.NewDecoder(bytes.NewReader(b))
+	if err = jsonDec.Decode(&vk.CommitmentInfo); err != nil {
+		return dec.BytesRead(), err
+	}
+	// keys serialized before the setup log was introduced end with the commitment info
+	vk.SetupLog = backend.SetupLog{}
+	if jsonDec.More() {
+		if err = jsonDec.Decode(&vk.SetupLog); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	vk.Features = nil
+	if jsonDec.More() {
+		if err = jsonDec.Decode(&vk.Features); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	if err = vk.Features.Check(); err != nil {
+		return dec.BytesRead(), fmt.Errorf("verifying key: %w", err)
+	}
+	vk.Hashes = KeyHashes{}
+	if jsonDec.More() {
+		if err = jsonDec.Decode(&vk.Hashes); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	if err = vk.checkHash(); err != nil {
 		return dec.BytesRead(), err
 	}
 
@@ -231,6 +345,9 @@ func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
 }
 
 func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	if err := pk.waitG2(); err != nil {
+		return 0, err
+	}
 	n, err := pk.Domain.WriteTo(w)
 	if err != nil {
 		return n, err
@@ -260,8 +377,10 @@ func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 		pk.NbInfinityB,
 		pk.InfinityA,
 		pk.InfinityB,
-		pk.CommitmentKey.Basis,
-		pk.CommitmentKey.BasisExpSigma,
+		uint64(len(pk.CommitmentKeys)),
+	}
+	for i := range pk.CommitmentKeys {
+		toEncode = append(toEncode, pk.CommitmentKeys[i].Basis, pk.CommitmentKeys[i].BasisExpSigma)
 	}
 
 	for _, v := range toEncode {
@@ -269,14 +388,18 @@ func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 			return n + enc.BytesWritten(), err
 		}
 	}
+	n += enc.BytesWritten()
 
-	return n + enc.BytesWritten(), nil
-
+	m, err := pk.writeHashes(w)
+	return n + m, err
 }
 
 // ReadFrom attempts to decode a ProvingKey from reader
 // ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 // note that we don't check that the points are on the curve or in the correct subgroup at this point
+//
+// The key is copied to the device at its first proof, or by SetAccelerator, and its previous
+// device copies, if any, are released.
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 	return pk.readFrom(r)
 }
@@ -288,6 +411,11 @@ func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
 }
 
 func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	// the points of B in G2 of a previous ReadSegmented must not be set concurrently
+	_ = pk.waitG2()
+	pk.g2 = nil
+	pk.releaseDevice()
+
 	n, err := pk.Domain.ReadFrom(r)
 	if err != nil {
 		return n, err
@@ -295,8 +423,6 @@ func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder))
 
 	dec := curve.NewDecoder(r, decOptions...)
 
-	var nbWires uint64
-
 	toDecode := []interface{}{
 		&pk.G1.Alpha,
 		&pk.G1.Beta,
@@ -308,9 +434,6 @@ func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder))
 		&pk.G2.Beta,
 		&pk.G2.Delta,
 		&pk.G2.B,
-		&nbWires,
-		&pk.NbInfinityA,
-		&pk.NbInfinityB,
 	}
 
 	for _, v := range toDecode {
@@ -318,26 +441,56 @@ func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder))
 			return n + dec.BytesRead(), err
 		}
 	}
+	if err := pk.decodeWires(dec); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	n += dec.BytesRead()
+
+	m, err := pk.readHashes(r)
+	if err != nil {
+		return n + m, err
+	}
+	if err := pk.checkHash(); err != nil {
+		return n + m, err
+	}
+
+	return n + m, nil
+}
+
+// decodeWires decodes the part of the proving key following the points of B in G2: the points
+// at infinity of the wires and the commitment keys.
+func (pk *ProvingKey) decodeWires(dec *curve.Decoder) error {
+	var nbWires uint64
+	for _, v := range []interface{}{&nbWires, &pk.NbInfinityA, &pk.NbInfinityB} {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
 	pk.InfinityA = make([]bool, nbWires)
 	pk.InfinityB = make([]bool, nbWires)
 
 	if err := dec.Decode(&pk.InfinityA); err != nil {
-		return n + dec.BytesRead(), err
+		return err
 	}
 	if err := dec.Decode(&pk.InfinityB); err != nil {
-		return n + dec.BytesRead(), err
+		return err
 	}
 
-	if err := dec.Decode(&pk.CommitmentKey.Basis); err != nil {
-		return dec.BytesRead(), err
+	var nbCommitmentKeys uint64
+	if err := dec.Decode(&nbCommitmentKeys); err != nil {
+		return err
 	}
-	if err := dec.Decode(&pk.CommitmentKey.BasisExpSigma); err != nil {
-		return dec.BytesRead(), err
+	pk.CommitmentKeys = nil
+	if nbCommitmentKeys != 0 {
+		pk.CommitmentKeys = make([]pedersen.ProvingKey, nbCommitmentKeys)
 	}
-
-	size := n + dec.BytesRead()
-
-	pk.setupDevicePointers()
-
-	return size, nil
+	for i := range pk.CommitmentKeys {
+		if err := dec.Decode(&pk.CommitmentKeys[i].Basis); err != nil {
+			return err
+		}
+		if err := dec.Decode(&pk.CommitmentKeys[i].BasisExpSigma); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,88 @@
+package groth16
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	cs "github.com/consensys/gnark/constraint/bn254"
+)
+
+// msmWindowBits is the window size assumed for the workspace of the device MSMs.
+const msmWindowBits = 16
+
+// DeviceMemoryEstimate returns the device memory needed to prove r1cs with pk, in bytes:
+// resident is the memory of the device copies of pk, allocated once when pk is set up or read,
+// and proof the peak memory allocated by Prove on top of it.
+//
+// The proof memory is the maximum of the quotient computation (the wire values for A and B,
+// and 6 vectors of the domain size) and of the MSMs (the wire values, h, the scalars of K and
+// the workspace of the largest MSM). The MSM workspace is estimated from the allocations of
+// the bucket method, and is the least accurate part of the estimate.
+//
+// The points of B in G2 of a key read by ReadSegmented are counted once read; the error is the
+// one of their background read.
+func (pk *ProvingKey) DeviceMemoryEstimate(r1cs *cs.R1CS) (resident, proof uint64, err error) {
+	const (
+		g1Size = 2 * fp.Bytes // affine
+		g2Size = 4 * fp.Bytes
+	)
+	n := pk.Domain.Cardinality
+	nbWires := uint64(r1cs.GetNbInternalVariables() + r1cs.GetNbSecretVariables() + r1cs.GetNbPublicVariables())
+	nbK := uint64(countNonInfinity(pk.G1.K))
+	if err := pk.waitG2(); err != nil {
+		return 0, 0, err
+	}
+
+	// twiddles, inverse twiddles, coset tables and den
+	resident = 5 * n * fr.Bytes
+	resident += uint64(len(pk.G1.A)+len(pk.G1.B)+len(pk.G1.Z))*g1Size + nbK*g1Size
+	resident += uint64(len(pk.G2.B)) * g2Size
+	var commit uint64
+	for i := range pk.CommitmentKeys {
+		m := uint64(countNonInfinity(pk.CommitmentKeys[i].Basis))
+		resident += 2 * m * g1Size
+		if m >= commitOnDeviceMinSize {
+			commit = maxUint64(commit, m*fr.Bytes+msmWorkspace(m, 3*fp.Bytes))
+		}
+	}
+
+	wireValues := (nbWires-pk.NbInfinityA)*fr.Bytes + (nbWires-pk.NbInfinityB)*fr.Bytes
+	quotient := wireValues + 6*n*fr.Bytes
+	msm := maxUint64(
+		msmWorkspace(nbWires-pk.NbInfinityA, 3*fp.Bytes),
+		msmWorkspace(n-1, 3*fp.Bytes),
+		msmWorkspace(nbK, 3*fp.Bytes),
+		msmWorkspace(nbWires-pk.NbInfinityB, 6*fp.Bytes),
+	)
+	msm += wireValues + n*fr.Bytes + nbK*fr.Bytes
+
+	return resident, maxUint64(commit, quotient, msm), nil
+}
+
+// msmWorkspace returns the device memory allocated by a multi-scalar multiplication of size
+// points, whose projective coordinates take projectiveSize bytes: the bucket and point
+// indices of each window, before and after sorting, and the buckets.
+func msmWorkspace(size uint64, projectiveSize int) uint64 {
+	nbWindows := uint64((fr.Bits + msmWindowBits - 1) / msmWindowBits)
+	return 16*size*nbWindows + nbWindows<<msmWindowBits*uint64(projectiveSize)
+}
+
+func countNonInfinity(points []curve.G1Affine) int {
+	res := 0
+	for i := range points {
+		if !points[i].IsInfinity() {
+			res++
+		}
+	}
+	return res
+}
+
+// maxUint64 returns the largest of a and b.
+func maxUint64(a uint64, b ...uint64) uint64 {
+	for _, v := range b {
+		if v > a {
+			a = v
+		}
+	}
+	return a
+}
@@ -0,0 +1,90 @@
+package mpcsetup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// BeaconIterations is the number of iterations of SHA-256 deriving the secrets of the beacon
+// contribution from the beacon. It delays the computation of the secrets, so that whoever
+// chooses the beacon (for instance the miner of a block whose hash is the beacon) can't try
+// many beacons.
+const BeaconIterations = 1 << 20
+
+// ContributeBeacon makes the last contribution of a ceremony, with secrets derived from a
+// public random value, the beacon, announced before it is known (e.g. the hash of a future
+// block): the keys then don't only depend on the contributions of the participants, the last
+// of which could otherwise choose its contribution after seeing the ones before it.
+//
+// The contribution is deterministic: anyone can check it with VerifyPhase2Beacon.
+func (c *Phase2) ContributeBeacon(beacon []byte, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	return c.contribute(newBeaconSampler(beacon, c.Hash), cfg.device)
+}
+
+// VerifyPhase2Beacon checks that contribution is the beacon contribution to current, see
+// ContributeBeacon.
+func VerifyPhase2Beacon(current, contribution *Phase2, beacon []byte, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if err := verifyPhase2(current, contribution, cfg); err != nil {
+		return err
+	}
+
+	// the parameters are updated with the secrets of the public keys of contribution, so that
+	// it is enough to check these
+	secrets, err := sampleSecrets(newBeaconSampler(beacon, current.Hash), len(current.Parameters.G2.Sigma))
+	if err != nil {
+		return err
+	}
+	if publicKey(secrets.delta, secrets.s, current.Hash, deltaDST) != contribution.PublicKey {
+		return errors.New("δ isn't derived from the beacon")
+	}
+	for i := range contribution.SigmaPublicKeys {
+		if publicKey(secrets.sigma[i], secrets.sSigma[i], current.Hash, sigmaDST+byte(i)) != contribution.SigmaPublicKeys[i] {
+			return errors.New("σ isn't derived from the beacon")
+		}
+	}
+	return nil
+}
+
+// beaconSampler reads the secrets of a beacon contribution, SHA-256 in counter mode from a
+// seed derived from the beacon and the hash of the contribution it is based on.
+type beaconSampler struct {
+	seed    [sha256.Size]byte
+	counter uint64
+	buf     []byte
+}
+
+func newBeaconSampler(beacon, challenge []byte) io.Reader {
+	h := sha256.Sum256(beacon)
+	for i := 1; i < BeaconIterations; i++ {
+		h = sha256.Sum256(h[:])
+	}
+	return &beaconSampler{seed: sha256.Sum256(append(h[:], challenge...))}
+}
+
+func (s *beaconSampler) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			var block [sha256.Size + 8]byte
+			copy(block[:], s.seed[:])
+			binary.BigEndian.PutUint64(block[sha256.Size:], s.counter)
+			s.counter++
+			h := sha256.Sum256(block[:])
+			s.buf = h[:]
+		}
+		m := copy(p[n:], s.buf)
+		s.buf = s.buf[m:]
+		n += m
+	}
+	return n, nil
+}
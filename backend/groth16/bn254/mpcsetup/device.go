@@ -0,0 +1,126 @@
+package mpcsetup
+
+import (
+	"errors"
+	"unsafe"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+	_ "github.com/consensys/gnark/backend/accel/cpu"    // registers the CPU emulation, selected by name
+	_ "github.com/consensys/gnark/backend/accel/icicle" // registers the CUDA provider, with the icicle build tag
+	_ "github.com/consensys/gnark/backend/accel/rocm"   // registers the AMD provider, with the rocm build tag
+)
+
+// Option configures the contributions, their verification and the extraction of the keys.
+type Option func(*config) error
+
+type config struct {
+	// accelerator is the provider of the device copies of the proving key
+	accelerator string
+
+	// device computes the scalar multiplications of the contributions and the multi-scalar
+	// multiplications of the verifications, nil on the CPU
+	device accel.Device
+}
+
+func newConfig(opts ...Option) (*config, error) {
+	var cfg config
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+// WithAccelerator computes the scalar multiplications of the contributions and the multi-scalar
+// multiplications of the verifications on a device of the named accelerator provider (see
+// accel.OpenProvider, the empty name selecting the default provider), instead of the CPU. The
+// contributions fall back to the CPU if the device doesn't multiply vectors of points (see
+// accel.ScalarMulDevice). The proving key returned by Seal is copied to a device of the
+// provider, as by backend.WithAccelerator.
+func WithAccelerator(name string) Option {
+	return func(cfg *config) error {
+		device, err := accel.OpenProvider(name, curve.ID)
+		if err != nil {
+			return err
+		}
+		cfg.accelerator, cfg.device = name, device
+		return nil
+	}
+}
+
+// msmOnDevice returns ∑ scalars[i]·points[i], computed on the device. The points at infinity
+// are filtered on the host, as the devices expect.
+func msmOnDevice(device accel.Device, points []curve.G1Affine, scalars []fr.Element) (res curve.G1Affine, err error) {
+	var p []curve.G1Affine
+	var s []fr.Element
+	for i := range points {
+		if !points[i].IsInfinity() {
+			p = append(p, points[i])
+			s = append(s, scalars[i])
+		}
+	}
+	if len(p) == 0 {
+		return
+	}
+
+	size := len(s) * fr.Bytes
+	scalars_d, err := device.Malloc(size)
+	if err != nil {
+		return
+	}
+	defer device.Free(scalars_d)
+	if err = device.CopyToDevice(scalars_d, unsafe.Pointer(&s[0]), size); err != nil {
+		return
+	}
+	if err = device.FromMontgomery(scalars_d, len(s)); err != nil {
+		return
+	}
+	points_d, err := device.PointsG1ToDevice(unsafe.Pointer(&p[0]), len(p), accel.PointsConfig{})
+	if err != nil {
+		return
+	}
+	defer device.Free(points_d)
+
+	var jac curve.G1Jac
+	if err = device.Msm(unsafe.Pointer(&jac), scalars_d, points_d, len(p), accel.MsmConfig{}); err != nil {
+		return
+	}
+	res.FromJacobian(&jac)
+	return
+}
+
+// scalarMulChunk is the number of points multiplied by a call to the device, bounding the
+// memory of the conversions of the providers around their kernels.
+const scalarMulChunk = 1 << 20
+
+// scalarMulOnDevice sets each of the points to its product by the scalar of the same index
+// with mul, chunk by chunk, if the device is an accel.ScalarMulDevice. The scalars are either
+// as many as the points or, for a single scalar, as many as the points of a chunk. It returns
+// false if the device doesn't support it, and the points are then to be multiplied on the CPU.
+func scalarMulOnDevice[T curve.G1Affine | curve.G2Affine](device accel.Device, points []T, scalars []fr.Element, mul func(accel.ScalarMulDevice, unsafe.Pointer, unsafe.Pointer, int) error) (bool, error) {
+	d, ok := device.(accel.ScalarMulDevice)
+	if !ok {
+		return false, nil
+	}
+	for start := 0; start < len(points); start += scalarMulChunk {
+		end := start + scalarMulChunk
+		if end > len(points) {
+			end = len(points)
+		}
+		s := scalars[:end-start]
+		if len(scalars) == len(points) {
+			s = scalars[start:end]
+		}
+		if err := mul(d, unsafe.Pointer(&points[start]), unsafe.Pointer(&s[0]), end-start); err != nil {
+			// the devices return ErrUnsupported before modifying the points
+			if start == 0 && errors.Is(err, accel.ErrUnsupported) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -12,13 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
-	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"errors"
 	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 )
 
 // WriteTo implements io.WriterTo
@@ -100,6 +100,7 @@ func (phase2 *Phase2) WriteTo(writer io.Writer) (int64, error) {
 
 func (c *Phase2) writeTo(writer io.Writer) (int64, error) {
 	enc := curve.NewEncoder(writer)
+	sg, sxg, xr := splitPublicKeys(c.SigmaPublicKeys)
 	toEncode := []interface{}{
 		&c.PublicKey.SG,
 		&c.PublicKey.SXG,
@@ -108,6 +109,12 @@ func (c *Phase2) writeTo(writer io.Writer) (int64, error) {
 		c.Parameters.G1.L,
 		c.Parameters.G1.Z,
 		&c.Parameters.G2.Delta,
+		lengths(c.Parameters.G1.SigmaCKK),
+		flatten(c.Parameters.G1.SigmaCKK),
+		c.Parameters.G2.Sigma,
+		sg,
+		sxg,
+		xr,
 	}
 
 	for _, v := range toEncode {
@@ -122,6 +129,13 @@ func (c *Phase2) writeTo(writer io.Writer) (int64, error) {
 // ReadFrom implements io.ReaderFrom
 func (c *Phase2) ReadFrom(reader io.Reader) (int64, error) {
 	dec := curve.NewDecoder(reader)
+	var (
+		sigmaLengths []uint64
+		sigmaCKK     []curve.G1Affine
+		sg, sxg      []curve.G1Affine
+		xr           []curve.G2Affine
+	)
+	c.Parameters.G2.Sigma = []curve.G2Affine{} // not nil without commitments, as in InitPhase2
 	toEncode := []interface{}{
 		&c.PublicKey.SG,
 		&c.PublicKey.SXG,
@@ -130,6 +144,12 @@ func (c *Phase2) ReadFrom(reader io.Reader) (int64, error) {
 		&c.Parameters.G1.L,
 		&c.Parameters.G1.Z,
 		&c.Parameters.G2.Delta,
+		&sigmaLengths,
+		&sigmaCKK,
+		&c.Parameters.G2.Sigma,
+		&sg,
+		&sxg,
+		&xr,
 	}
 
 	for _, v := range toEncode {
@@ -138,8 +158,20 @@ func (c *Phase2) ReadFrom(reader io.Reader) (int64, error) {
 		}
 	}
 
+	var err error
+	if c.Parameters.G1.SigmaCKK, err = unflatten(sigmaCKK, sigmaLengths); err != nil {
+		return dec.BytesRead(), err
+	}
+	if len(sg) != len(sxg) || len(sg) != len(xr) {
+		return dec.BytesRead(), errors.New("invalid public keys")
+	}
+	c.SigmaPublicKeys = make([]PublicKey, len(sg))
+	for i := range sg {
+		c.SigmaPublicKeys[i] = PublicKey{SG: sg[i], SXG: sxg[i], XR: xr[i]}
+	}
+
 	c.Hash = make([]byte, 32)
-	n, err := reader.Read(c.Hash)
+	n, err := io.ReadFull(reader, c.Hash)
 	return int64(n) + dec.BytesRead(), err
 
 }
@@ -151,6 +183,9 @@ func (c *Phase2Evaluations) WriteTo(writer io.Writer) (int64, error) {
 		c.G1.A,
 		c.G1.B,
 		c.G2.B,
+		c.G1.VKK,
+		lengths(c.G1.CKK),
+		flatten(c.G1.CKK),
 	}
 
 	for _, v := range toEncode {
@@ -165,10 +200,17 @@ func (c *Phase2Evaluations) WriteTo(writer io.Writer) (int64, error) {
 // ReadFrom implements io.ReaderFrom
 func (c *Phase2Evaluations) ReadFrom(reader io.Reader) (int64, error) {
 	dec := curve.NewDecoder(reader)
+	var (
+		ckkLengths []uint64
+		ckk        []curve.G1Affine
+	)
 	toEncode := []interface{}{
 		&c.G1.A,
 		&c.G1.B,
 		&c.G2.B,
+		&c.G1.VKK,
+		&ckkLengths,
+		&ckk,
 	}
 
 	for _, v := range toEncode {
@@ -177,5 +219,49 @@ func (c *Phase2Evaluations) ReadFrom(reader io.Reader) (int64, error) {
 		}
 	}
 
-	return dec.BytesRead(), nil
+	var err error
+	c.G1.CKK, err = unflatten(ckk, ckkLengths)
+	return dec.BytesRead(), err
+}
+
+// lengths returns the lengths of the slices of s.
+func lengths(s [][]curve.G1Affine) []uint64 {
+	res := make([]uint64, len(s))
+	for i := range s {
+		res[i] = uint64(len(s[i]))
+	}
+	return res
+}
+
+// flatten returns the concatenation of the slices of s.
+func flatten(s [][]curve.G1Affine) []curve.G1Affine {
+	var res []curve.G1Affine
+	for i := range s {
+		res = append(res, s[i]...)
+	}
+	return res
+}
+
+// unflatten splits flat in slices of the lengths, the inverse of flatten.
+func unflatten(flat []curve.G1Affine, lengths []uint64) ([][]curve.G1Affine, error) {
+	res := make([][]curve.G1Affine, len(lengths))
+	for i, l := range lengths {
+		if l > uint64(len(flat)) {
+			return nil, errors.New("invalid lengths")
+		}
+		res[i], flat = flat[:l:l], flat[l:]
+	}
+	if len(flat) != 0 {
+		return nil, errors.New("invalid lengths")
+	}
+	return res, nil
+}
+
+// splitPublicKeys returns the points of the public keys, by coordinates.
+func splitPublicKeys(pks []PublicKey) (sg, sxg []curve.G1Affine, xr []curve.G2Affine) {
+	sg, sxg, xr = make([]curve.G1Affine, len(pks)), make([]curve.G1Affine, len(pks)), make([]curve.G2Affine, len(pks))
+	for i := range pks {
+		sg[i], sxg[i], xr[i] = pks[i].SG, pks[i].SXG, pks[i].XR
+	}
+	return
 }
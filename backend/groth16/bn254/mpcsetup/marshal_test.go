@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
@@ -46,13 +44,37 @@ func TestContributionSerialization(t *testing.T) {
 	r1cs := ccs.(*cs.R1CS)
 
 	// Phase 2
-	srs2, _ := InitPhase2(r1cs, &srs1)
-	srs2.Contribute()
+	srs2, evals := InitPhase2(r1cs, &srs1)
+	assert.NoError(srs2.Contribute())
+
+	{
+		var reconstructed Phase2
+		roundTripCheck(t, &srs2, &reconstructed)
+	}
+	{
+		var reconstructed Phase2Evaluations
+		roundTripCheck(t, &evals, &reconstructed)
+	}
+}
+
+func TestCommitmentsSerialization(t *testing.T) {
+	assert := require.New(t)
+
+	srs1 := InitPhase1(6)
+	srs1.Contribute()
 
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &commitmentsCircuit{})
+	assert.NoError(err)
+	srs2, evals := InitPhase2(ccs.(*cs.R1CS), &srs1)
+	assert.NoError(srs2.Contribute())
 	{
 		var reconstructed Phase2
 		roundTripCheck(t, &srs2, &reconstructed)
 	}
+	{
+		var reconstructed Phase2Evaluations
+		roundTripCheck(t, &evals, &reconstructed)
+	}
 }
 
 func roundTripCheck(t *testing.T, from io.WriterTo, reconstructed io.ReaderFrom) {
@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
@@ -85,8 +83,13 @@ func InitPhase1(power int) (phase1 Phase1) {
 	return
 }
 
-// Contribute contributes randomness to the phase1 object. This mutates phase1.
-func (phase1 *Phase1) Contribute() {
+// Contribute contributes randomness to the phase1 object. This mutates phase1. The powers of
+// the parameters are updated on the device given WithAccelerator.
+func (phase1 *Phase1) Contribute(opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
 	N := len(phase1.Parameters.G2.Tau)
 
 	// Generate key pairs
@@ -109,16 +112,25 @@ func (phase1 *Phase1) Contribute() {
 
 	// Update using previous parameters
 	// TODO @gbotrel working with jacobian points here will help with perf.
-	scaleG1InPlace(phase1.Parameters.G1.Tau, taus)
-	scaleG2InPlace(phase1.Parameters.G2.Tau, taus[0:N])
-	scaleG1InPlace(phase1.Parameters.G1.AlphaTau, alphaTau)
-	scaleG1InPlace(phase1.Parameters.G1.BetaTau, betaTau)
+	if err = scaleG1InPlace(phase1.Parameters.G1.Tau, taus, cfg.device); err != nil {
+		return err
+	}
+	if err = scaleG2InPlace(phase1.Parameters.G2.Tau, taus[0:N], cfg.device); err != nil {
+		return err
+	}
+	if err = scaleG1InPlace(phase1.Parameters.G1.AlphaTau, alphaTau, cfg.device); err != nil {
+		return err
+	}
+	if err = scaleG1InPlace(phase1.Parameters.G1.BetaTau, betaTau, cfg.device); err != nil {
+		return err
+	}
 	var betaBI big.Int
 	beta.BigInt(&betaBI)
 	phase1.Parameters.G2.Beta.ScalarMultiplication(&phase1.Parameters.G2.Beta, &betaBI)
 
 	// Compute hash of Contribution
 	phase1.Hash = phase1.hash()
+	return nil
 }
 
 func VerifyPhase1(c0, c1 *Phase1, c ...*Phase1) error {
@@ -12,17 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
+	"bytes"
+	"crypto/rand"
 	"crypto/sha256"
 	"errors"
+	"fmt"
+	"io"
 	"math/big"
 
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/constraint/bn254"
 )
@@ -30,32 +34,51 @@ import (
 type Phase2Evaluations struct {
 	G1 struct {
 		A, B, VKK []curve.G1Affine
+		CKK       [][]curve.G1Affine // the bases of the commitment keys, one per commitment
 	}
 	G2 struct {
 		B []curve.G2Affine
 	}
 }
 
+// Phase2 is the circuit specific phase of the MPC described in
+// https://eprint.iacr.org/2017/1050.pdf
+//
+// Each contribution updates δ, and the secret σᵢ of the commitment key of each commitment of
+// the circuit, so that the keys of this fork, with their Pedersen commitment keys, come out of
+// the ceremony (see Seal).
 type Phase2 struct {
 	Parameters struct {
 		G1 struct {
-			Delta curve.G1Affine
-			L, Z  []curve.G1Affine
+			Delta    curve.G1Affine
+			L, Z     []curve.G1Affine
+			SigmaCKK [][]curve.G1Affine // [σᵢCᵢⱼ]₁, the bases of the commitment keys times their secret
 		}
 		G2 struct {
 			Delta curve.G2Affine
+			Sigma []curve.G2Affine // [σᵢ]₂, the secrets of the commitment keys
 		}
 	}
-	PublicKey PublicKey
-	Hash      []byte
+	PublicKey       PublicKey
+	SigmaPublicKeys []PublicKey // one per commitment
+	Hash            []byte
 }
 
+// InitPhase2 initializes the phase 2 of the MPC for the circuit, from the result of phase 1.
+// The phase 1 may be for a larger circuit than r1cs: only the powers of τ of the domain of
+// r1cs are used.
+//
+// The initial state only depends on r1cs and srs1, so that VerifyPhase2Chain can check that a
+// ceremony started from it.
 func InitPhase2(r1cs *cs.R1CS, srs1 *Phase1) (Phase2, Phase2Evaluations) {
 	srs := srs1.Parameters
-	size := len(srs.G1.AlphaTau)
-	if size < r1cs.GetNbConstraints() {
+	size := int(fft.NewDomain(uint64(r1cs.GetNbConstraints())).Cardinality)
+	if len(srs.G1.AlphaTau) < size {
 		panic("Number of constraints is larger than expected")
 	}
+	if len(r1cs.CommitmentInfo) > 255-sigmaDST {
+		panic("too many commitments")
+	}
 
 	c2 := Phase2{}
 
@@ -145,7 +168,7 @@ func InitPhase2(r1cs *cs.R1CS, srs1 *Phase1) (Phase2, Phase2Evaluations) {
 
 	// Build Z in PK as τⁱ(τⁿ - 1)  = τ⁽ⁱ⁺ⁿ⁾ - τⁱ  for i ∈ [0, n-2]
 	// τⁱ(τⁿ - 1)  = τ⁽ⁱ⁺ⁿ⁾ - τⁱ  for i ∈ [0, n-2]
-	n := len(srs.G1.AlphaTau)
+	n := size
 	c2.Parameters.G1.Z = make([]curve.G1Affine, n)
 	for i := 0; i < n-1; i++ {
 		c2.Parameters.G1.Z[i].Sub(&srs.G1.Tau[i+n], &srs.G1.Tau[i])
@@ -153,75 +176,204 @@ func InitPhase2(r1cs *cs.R1CS, srs1 *Phase1) (Phase2, Phase2Evaluations) {
 	bitReverse(c2.Parameters.G1.Z)
 	c2.Parameters.G1.Z = c2.Parameters.G1.Z[:n-1]
 
+	// As in groth16.Setup, the commitment wires follow the public wires in VKK, and the
+	// private committed wires are the bases of the commitment keys, with γ = 1 as the other
+	// public wires: they are not divided by δ.
+	nbCommitments := len(r1cs.CommitmentInfo)
+	vkIndex := make(map[int]int, nbCommitments)
+	ckIndex := make(map[int][2]int, r1cs.CommitmentInfo.NbPrivateCommitted())
+	evals.G1.CKK = make([][]curve.G1Affine, nbCommitments)
+	for j := range r1cs.CommitmentInfo {
+		for k, wire := range r1cs.CommitmentInfo[j].PrivateCommitted() {
+			ckIndex[wire] = [2]int{j, k}
+		}
+		evals.G1.CKK[j] = make([]curve.G1Affine, r1cs.CommitmentInfo[j].NbPrivateCommitted)
+		vkIndex[r1cs.CommitmentInfo[j].CommitmentIndex] = public + j
+	}
+
 	// Evaluate L
-	nPrivate := internal + secret
-	c2.Parameters.G1.L = make([]curve.G1Affine, nPrivate)
-	evals.G1.VKK = make([]curve.G1Affine, public)
-	offset := public
+	nPrivate := internal + secret - nbCommitments - r1cs.CommitmentInfo.NbPrivateCommitted()
+	c2.Parameters.G1.L = make([]curve.G1Affine, 0, nPrivate)
+	evals.G1.VKK = make([]curve.G1Affine, public+nbCommitments)
 	for i := 0; i < nWires; i++ {
 		var tmp curve.G1Affine
 		tmp.Add(&bA[i], &aB[i])
 		tmp.Add(&tmp, &C[i])
 		if i < public {
 			evals.G1.VKK[i].Set(&tmp)
+		} else if vI, isCommitment := vkIndex[i]; isCommitment {
+			evals.G1.VKK[vI].Set(&tmp)
+		} else if cI, isCommittedPrivate := ckIndex[i]; isCommittedPrivate {
+			evals.G1.CKK[cI[0]][cI[1]].Set(&tmp)
 		} else {
-			c2.Parameters.G1.L[i-offset].Set(&tmp)
+			c2.Parameters.G1.L = append(c2.Parameters.G1.L, tmp)
 		}
 	}
-	// Set δ public key
-	var delta fr.Element
-	delta.SetOne()
-	c2.PublicKey = newPublicKey(delta, nil, 1)
+
+	// σᵢ = 1
+	c2.Parameters.G1.SigmaCKK = make([][]curve.G1Affine, nbCommitments)
+	c2.Parameters.G2.Sigma = make([]curve.G2Affine, nbCommitments)
+	for j := range evals.G1.CKK {
+		c2.Parameters.G1.SigmaCKK[j] = make([]curve.G1Affine, len(evals.G1.CKK[j]))
+		copy(c2.Parameters.G1.SigmaCKK[j], evals.G1.CKK[j])
+		c2.Parameters.G2.Sigma[j] = g2
+	}
+
+	// Set the public keys of δ = 1 and σᵢ = 1, with a known secret so that the initial state
+	// is deterministic
+	one := fr.One()
+	c2.PublicKey = publicKey(one, one, nil, deltaDST)
+	c2.SigmaPublicKeys = make([]PublicKey, nbCommitments)
+	for j := range c2.SigmaPublicKeys {
+		c2.SigmaPublicKeys[j] = publicKey(one, one, nil, sigmaDST+byte(j))
+	}
 
 	// Hash initial contribution
 	c2.Hash = c2.hash()
 	return c2, evals
 }
 
-func (c *Phase2) Contribute() {
-	// Sample toxic δ
-	var delta, deltaInv fr.Element
-	var deltaBI, deltaInvBI big.Int
-	delta.SetRandom()
-	deltaInv.Inverse(&delta)
+// domain separation tags of the public keys of δ, and of σᵢ from sigmaDST + i
+const (
+	deltaDST = 1
+	sigmaDST = 2
+)
 
-	delta.BigInt(&deltaBI)
-	deltaInv.BigInt(&deltaInvBI)
+// contributionSecrets are the secrets of a contribution: δ, the σᵢ, and the secrets of their
+// proofs of knowledge.
+type contributionSecrets struct {
+	delta, s      fr.Element
+	sigma, sSigma []fr.Element
+}
 
-	// Set δ public key
-	c.PublicKey = newPublicKey(delta, c.Hash, 1)
+// sampleSecrets reads the secrets of a contribution to a ceremony with nbCommitments
+// commitments from sampler.
+func sampleSecrets(sampler io.Reader, nbCommitments int) (secrets contributionSecrets, err error) {
+	secrets.sigma = make([]fr.Element, nbCommitments)
+	secrets.sSigma = make([]fr.Element, nbCommitments)
+	toSample := []*fr.Element{&secrets.delta, &secrets.s}
+	for i := range secrets.sigma {
+		toSample = append(toSample, &secrets.sigma[i], &secrets.sSigma[i])
+	}
+	for _, e := range toSample {
+		if err = sampleNonZero(sampler, e); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Contribute contributes randomness to the phase2 object, updating δ and the secrets of the
+// commitment keys. This mutates phase2. The parameters are updated on the device given
+// WithAccelerator.
+func (c *Phase2) Contribute(opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	return c.contribute(rand.Reader, cfg.device)
+}
+
+// contribute makes the contribution with the secrets read from sampler, updating the
+// parameters on the device if not nil.
+func (c *Phase2) contribute(sampler io.Reader, device accel.Device) error {
+	// Sample toxic δ and σᵢ
+	secrets, err := sampleSecrets(sampler, len(c.Parameters.G2.Sigma))
+	if err != nil {
+		return err
+	}
+	var deltaInv fr.Element
+	deltaInv.Inverse(&secrets.delta)
+
+	// Set the public keys
+	c.PublicKey = publicKey(secrets.delta, secrets.s, c.Hash, deltaDST)
+	for i := range c.SigmaPublicKeys {
+		c.SigmaPublicKeys[i] = publicKey(secrets.sigma[i], secrets.sSigma[i], c.Hash, sigmaDST+byte(i))
+	}
 
 	// Update δ
+	var deltaBI big.Int
+	secrets.delta.BigInt(&deltaBI)
 	c.Parameters.G1.Delta.ScalarMultiplication(&c.Parameters.G1.Delta, &deltaBI)
 	c.Parameters.G2.Delta.ScalarMultiplication(&c.Parameters.G2.Delta, &deltaBI)
 
-	// Update Z using δ⁻¹
-	for i := 0; i < len(c.Parameters.G1.Z); i++ {
-		c.Parameters.G1.Z[i].ScalarMultiplication(&c.Parameters.G1.Z[i], &deltaInvBI)
+	// Update Z and L using δ⁻¹
+	if err = scaleG1(c.Parameters.G1.Z, deltaInv, device); err != nil {
+		return err
+	}
+	if err = scaleG1(c.Parameters.G1.L, deltaInv, device); err != nil {
+		return err
 	}
 
-	// Update L using δ⁻¹
-	for i := 0; i < len(c.Parameters.G1.L); i++ {
-		c.Parameters.G1.L[i].ScalarMultiplication(&c.Parameters.G1.L[i], &deltaInvBI)
+	// Update the commitment keys using σᵢ
+	for i := range c.Parameters.G2.Sigma {
+		var sigmaBI big.Int
+		secrets.sigma[i].BigInt(&sigmaBI)
+		c.Parameters.G2.Sigma[i].ScalarMultiplication(&c.Parameters.G2.Sigma[i], &sigmaBI)
+		if err = scaleG1(c.Parameters.G1.SigmaCKK[i], secrets.sigma[i], device); err != nil {
+			return err
+		}
 	}
 
 	// 4. Hash contribution
 	c.Hash = c.hash()
+	return nil
 }
 
+// VerifyPhase2 checks that each contribution is based on the previous one: c1 on c0, and
+// each of c on the one before it.
 func VerifyPhase2(c0, c1 *Phase2, c ...*Phase2) error {
-	contribs := append([]*Phase2{c0, c1}, c...)
+	return verifyPhase2Chain(append([]*Phase2{c0, c1}, c...), &config{})
+}
+
+// VerifyPhase2Chain checks the transcript of a ceremony: that contributions[0] is the
+// initial state of the phase 2 of the circuit (see InitPhase2), and that each contribution is
+// based on the previous one. The last contribution may be the beacon contribution of Seal,
+// checked with VerifyPhase2Beacon.
+func VerifyPhase2Chain(r1cs *cs.R1CS, srs1 *Phase1, contributions []*Phase2, opts ...Option) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if len(contributions) == 0 {
+		return errors.New("no contribution")
+	}
+	init, _ := InitPhase2(r1cs, srs1)
+	if !bytes.Equal(init.Hash, contributions[0].Hash) || !bytes.Equal(contributions[0].hash(), init.Hash) {
+		return errors.New("the ceremony doesn't start from the initial state of the circuit")
+	}
+	return verifyPhase2Chain(contributions, cfg)
+}
+
+func verifyPhase2Chain(contribs []*Phase2, cfg *config) error {
 	for i := 0; i < len(contribs)-1; i++ {
-		if err := verifyPhase2(contribs[i], contribs[i+1]); err != nil {
-			return err
+		if err := verifyPhase2(contribs[i], contribs[i+1], cfg); err != nil {
+			return fmt.Errorf("contribution %d: %w", i+1, err)
 		}
 	}
 	return nil
 }
 
-func verifyPhase2(current, contribution *Phase2) error {
+func verifyPhase2(current, contribution *Phase2, cfg *config) error {
+	// Check the sizes, so that the ratios are checked on all the parameters
+	if len(contribution.Parameters.G1.L) != len(current.Parameters.G1.L) ||
+		len(contribution.Parameters.G1.Z) != len(current.Parameters.G1.Z) ||
+		len(contribution.Parameters.G2.Sigma) != len(current.Parameters.G2.Sigma) ||
+		len(contribution.Parameters.G1.SigmaCKK) != len(current.Parameters.G2.Sigma) ||
+		len(contribution.SigmaPublicKeys) != len(current.Parameters.G2.Sigma) {
+		return errors.New("the contribution doesn't have the size of the previous one")
+	}
+	for i := range contribution.Parameters.G1.SigmaCKK {
+		if len(contribution.Parameters.G1.SigmaCKK[i]) != len(current.Parameters.G1.SigmaCKK[i]) {
+			return errors.New("the contribution doesn't have the size of the previous one")
+		}
+	}
+	if contribution.Parameters.G2.Delta.IsInfinity() {
+		return errors.New("[δ]₂ is the point at infinity")
+	}
+
 	// Compute R for δ
-	deltaR := genR(contribution.PublicKey.SG, contribution.PublicKey.SXG, current.Hash[:], 1)
+	deltaR := genR(contribution.PublicKey.SG, contribution.PublicKey.SXG, current.Hash[:], deltaDST)
 
 	// Check for knowledge of δ
 	if !sameRatio(contribution.PublicKey.SG, contribution.PublicKey.SXG, contribution.PublicKey.XR, deltaR) {
@@ -237,21 +389,47 @@ func verifyPhase2(current, contribution *Phase2) error {
 	}
 
 	// Check for valid updates of L and Z using
-	L, prevL := merge(contribution.Parameters.G1.L, current.Parameters.G1.L)
+	L, prevL, err := merge(contribution.Parameters.G1.L, current.Parameters.G1.L, cfg.device)
+	if err != nil {
+		return err
+	}
 	if !sameRatio(L, prevL, contribution.Parameters.G2.Delta, current.Parameters.G2.Delta) {
 		return errors.New("couldn't verify valid updates of L using δ⁻¹")
 	}
-	Z, prevZ := merge(contribution.Parameters.G1.Z, current.Parameters.G1.Z)
+	Z, prevZ, err := merge(contribution.Parameters.G1.Z, current.Parameters.G1.Z, cfg.device)
+	if err != nil {
+		return err
+	}
 	if !sameRatio(Z, prevZ, contribution.Parameters.G2.Delta, current.Parameters.G2.Delta) {
-		return errors.New("couldn't verify valid updates of L using δ⁻¹")
+		return errors.New("couldn't verify valid updates of Z using δ⁻¹")
 	}
 
-	// Check hash of the contribution
-	h := contribution.hash()
-	for i := 0; i < len(h); i++ {
-		if h[i] != contribution.Hash[i] {
-			return errors.New("couldn't verify hash of contribution")
+	// Check for knowledge of σᵢ and valid updates of the commitment keys
+	for i := range contribution.Parameters.G2.Sigma {
+		pk := &contribution.SigmaPublicKeys[i]
+		sigma, prevSigma := contribution.Parameters.G2.Sigma[i], current.Parameters.G2.Sigma[i]
+		if sigma.IsInfinity() {
+			return fmt.Errorf("[σ%d]₂ is the point at infinity", i)
+		}
+		sigmaR := genR(pk.SG, pk.SXG, current.Hash[:], sigmaDST+byte(i))
+		if !sameRatio(pk.SG, pk.SXG, pk.XR, sigmaR) {
+			return fmt.Errorf("couldn't verify knowledge of σ%d", i)
+		}
+		if !sameRatio(pk.SG, pk.SXG, sigma, prevSigma) {
+			return fmt.Errorf("couldn't verify that [σ%d]₂ is based on previous contribution", i)
 		}
+		ck, prevCk, err := merge(contribution.Parameters.G1.SigmaCKK[i], current.Parameters.G1.SigmaCKK[i], cfg.device)
+		if err != nil {
+			return err
+		}
+		if !sameRatio(ck, prevCk, prevSigma, sigma) {
+			return fmt.Errorf("couldn't verify valid updates of the commitment key %d using σ%d", i, i)
+		}
+	}
+
+	// Check hash of the contribution
+	if !bytes.Equal(contribution.hash(), contribution.Hash) {
+		return errors.New("couldn't verify hash of contribution")
 	}
 
 	return nil
@@ -12,16 +12,19 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend"
 	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint/bn254"
 )
 
+// ExtractKeys returns the keys of the ceremony. They don't have the commitment information,
+// the features and the hashes of the circuit, nor device copies: Seal returns complete keys.
 func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstraints int) (pk groth16.ProvingKey, vk groth16.VerifyingKey) {
 	_, _, _, g2 := curve.Generators()
 
@@ -88,6 +91,19 @@ func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstrai
 	vk.G2.Gamma.Set(&g2)
 	vk.G1.K = evals.G1.VKK
 
+	// Commitment keys: [σᵢ]₂ takes the place of G, and -[1]₂ of -G/σᵢ, in the proof of
+	// knowledge check e(C, G)·e(σᵢC, -G/σᵢ) = 1
+	var g2Neg curve.G2Affine
+	g2Neg.Neg(&g2)
+	pk.CommitmentKeys = make([]pedersen.ProvingKey, len(evals.G1.CKK))
+	vk.CommitmentKeys = make([]pedersen.VerifyingKey, len(evals.G1.CKK))
+	for i := range evals.G1.CKK {
+		pk.CommitmentKeys[i].Basis = evals.G1.CKK[i]
+		pk.CommitmentKeys[i].BasisExpSigma = srs2.Parameters.G1.SigmaCKK[i]
+		vk.CommitmentKeys[i].G = srs2.Parameters.G2.Sigma[i]
+		vk.CommitmentKeys[i].GRootSigmaNeg = g2Neg
+	}
+
 	// sets e, -[δ]2, -[γ]2
 	if err := vk.Precompute(); err != nil {
 		panic(err)
@@ -95,3 +111,28 @@ func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstrai
 
 	return pk, vk
 }
+
+// Seal finalizes the ceremony of the circuit: it makes the beacon contribution to srs2 (see
+// ContributeBeacon), and returns the keys, as returned by groth16.Setup. The beacon
+// contribution is computed on the device set with WithAccelerator, and the proving key is
+// copied to a device of its provider, or of the default provider. The keys are given the hashes
+// of groth16.KeyHashes.
+func Seal(r1cs *cs.R1CS, srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, beacon []byte, opts ...Option) (pk groth16.ProvingKey, vk groth16.VerifyingKey, err error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return
+	}
+	if err = srs2.contribute(newBeaconSampler(beacon, srs2.Hash), cfg.device); err != nil {
+		return
+	}
+
+	pk, vk = ExtractKeys(srs1, srs2, evals, r1cs.GetNbConstraints())
+	vk.CommitmentInfo = r1cs.CommitmentInfo
+	vk.Features = r1cs.GetFeatures()
+	vk.SetupLog = backend.SetupLog{Sampler: "mpcsetup", Reproducible: true}
+	if err = groth16.SetHashes(r1cs, &pk, &vk); err != nil {
+		return
+	}
+	err = pk.SetAccelerator(cfg.accelerator)
+	return
+}
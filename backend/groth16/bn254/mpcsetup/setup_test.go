@@ -12,8 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
@@ -22,6 +20,7 @@ import (
 	"github.com/consensys/gnark/constraint/bn254"
 	"testing"
 
+	"github.com/consensys/gnark/backend/accel/cpu"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
@@ -62,6 +61,7 @@ func TestSetupCircuit(t *testing.T) {
 
 	// Prepare for phase-2
 	srs2, evals := InitPhase2(r1cs, &srs1)
+	transcript := []*Phase2{clonePhase2(&srs2)}
 
 	// Make and verify contributions for phase1
 	for i := 1; i < nContributionsPhase2; i++ {
@@ -69,12 +69,18 @@ func TestSetupCircuit(t *testing.T) {
 		// add his contribution and send back to coordinator.
 		prev := srs2.clone()
 
-		srs2.Contribute()
+		assert.NoError(srs2.Contribute())
 		assert.NoError(VerifyPhase2(&prev, &srs2))
+		transcript = append(transcript, clonePhase2(&srs2))
 	}
+	assert.NoError(VerifyPhase2Chain(r1cs, &srs1, transcript))
 
 	// Extract the proving and verifying keys
-	pk, vk := ExtractKeys(&srs1, &srs2, &evals, ccs.GetNbConstraints())
+	beacon := []byte("beacon")
+	pk, vk, err := Seal(r1cs, &srs1, &srs2, &evals, beacon)
+	assert.NoError(err)
+	assert.NoError(VerifyPhase2Beacon(transcript[len(transcript)-1], &srs2, beacon))
+	assert.NoError(VerifyPhase2Chain(r1cs, &srs1, append(transcript, &srs2)))
 
 	// Build the witness
 	var preImage, hash fr.Element
@@ -98,6 +104,82 @@ func TestSetupCircuit(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestSetupCommitments(t *testing.T) {
+	assert := require.New(t)
+
+	// the contributions on a device are the same as on the CPU
+	srs1 := InitPhase1(6)
+	assert.NoError(srs1.Contribute())
+	prev := srs1.clone()
+	assert.NoError(srs1.Contribute(WithAccelerator(cpu.Name)))
+	assert.NoError(VerifyPhase1(&prev, &srs1))
+
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &commitmentsCircuit{})
+	assert.NoError(err)
+	r1cs := ccs.(*cs.R1CS)
+
+	srs2, evals := InitPhase2(r1cs, &srs1)
+	assert.Len(srs2.Parameters.G2.Sigma, 2)
+	transcript := []*Phase2{clonePhase2(&srs2)}
+	assert.NoError(srs2.Contribute())
+	transcript = append(transcript, clonePhase2(&srs2))
+	assert.NoError(srs2.Contribute(WithAccelerator(cpu.Name)))
+	transcript = append(transcript, clonePhase2(&srs2))
+	assert.NoError(VerifyPhase2Chain(r1cs, &srs1, transcript))
+	assert.NoError(VerifyPhase2Chain(r1cs, &srs1, transcript, WithAccelerator(cpu.Name)))
+
+	// the commitment keys are checked
+	tampered := clonePhase2(&srs2)
+	tampered.Parameters.G1.SigmaCKK[1][0] = tampered.Parameters.G1.SigmaCKK[0][0]
+	tampered.Hash = tampered.hash()
+	assert.Error(VerifyPhase2(transcript[1], tampered))
+
+	// the chain must start from the initial state of the circuit
+	assert.Error(VerifyPhase2Chain(r1cs, &srs1, transcript[1:]))
+
+	// the beacon contribution is deterministic
+	last := clonePhase2(&srs2)
+	pk, vk, err := Seal(r1cs, &srs1, &srs2, &evals, []byte("beacon"), WithAccelerator(cpu.Name))
+	assert.NoError(err)
+	assert.NoError(VerifyPhase2Beacon(last, &srs2, []byte("beacon")))
+	assert.Error(VerifyPhase2Beacon(last, &srs2, []byte("another beacon")))
+	assert.NotEmpty(vk.Hashes.Key)
+
+	w, err := frontend.NewWitness(&commitmentsCircuit{One: 1, Two: 2, Three: 3}, curve.ID.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, &pk, w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, &vk, public))
+
+	// the commitment keys are the ones of the verifying key
+	for i := range vk.CommitmentKeys {
+		assert.NoError(pk.ExportCommitmentKey(i).Validate(&vk, i))
+	}
+}
+
+// commitmentsCircuit commits to its inputs twice
+type commitmentsCircuit struct {
+	One, Two frontend.Variable
+	Three    frontend.Variable `gnark:",public"`
+}
+
+func (c *commitmentsCircuit) Define(api frontend.API) error {
+	committer := api.Compiler().(frontend.Committer)
+	commit1, err := committer.Commit(c.One, c.Three)
+	if err != nil {
+		return err
+	}
+	commit2, err := committer.Commit(c.Two, c.Three)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commit1, commit2)
+	api.AssertIsEqual(api.Add(c.One, c.Two), c.Three)
+	return nil
+}
+
 func BenchmarkPhase1(b *testing.B) {
 	const power = 14
 
@@ -189,8 +271,18 @@ func (phase2 *Phase2) clone() Phase2 {
 	r.Parameters.G1.L = append(r.Parameters.G1.L, phase2.Parameters.G1.L...)
 	r.Parameters.G1.Z = append(r.Parameters.G1.Z, phase2.Parameters.G1.Z...)
 	r.Parameters.G2.Delta = phase2.Parameters.G2.Delta
+	for i := range phase2.Parameters.G1.SigmaCKK {
+		r.Parameters.G1.SigmaCKK = append(r.Parameters.G1.SigmaCKK, append([]curve.G1Affine(nil), phase2.Parameters.G1.SigmaCKK[i]...))
+	}
+	r.Parameters.G2.Sigma = append(r.Parameters.G2.Sigma, phase2.Parameters.G2.Sigma...)
 	r.PublicKey = phase2.PublicKey
+	r.SigmaPublicKeys = append(r.SigmaPublicKeys, phase2.SigmaPublicKeys...)
 	r.Hash = append(r.Hash, phase2.Hash...)
 
 	return r
 }
+
+func clonePhase2(phase2 *Phase2) *Phase2 {
+	r := phase2.clone()
+	return &r
+}
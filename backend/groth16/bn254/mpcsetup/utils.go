@@ -12,12 +12,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package mpcsetup
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"math/big"
 	"math/bits"
 	"runtime"
@@ -25,6 +25,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/internal/utils"
 )
 
@@ -35,12 +36,17 @@ type PublicKey struct {
 }
 
 func newPublicKey(x fr.Element, challenge []byte, dst byte) PublicKey {
+	var s fr.Element
+	s.SetRandom()
+	return publicKey(x, s, challenge, dst)
+}
+
+// publicKey returns the public key of x, proving the knowledge of x with the secret s.
+func publicKey(x, s fr.Element, challenge []byte, dst byte) PublicKey {
 	var pk PublicKey
 	_, _, g1, _ := curve.Generators()
 
-	var s fr.Element
 	var sBi big.Int
-	s.SetRandom()
 	s.BigInt(&sBi)
 	pk.SG.ScalarMultiplication(&g1, &sBi)
 
@@ -57,6 +63,20 @@ func newPublicKey(x fr.Element, challenge []byte, dst byte) PublicKey {
 	return pk
 }
 
+// sampleNonZero sets e to a non zero element read from sampler.
+func sampleNonZero(sampler io.Reader, e *fr.Element) error {
+	// read 16 more bytes than needed so that the reduction modulo r is statistically uniform
+	var buf [fr.Bytes + 16]byte
+	var v big.Int
+	for e.SetZero(); e.IsZero(); {
+		if _, err := io.ReadFull(sampler, buf[:]); err != nil {
+			return fmt.Errorf("sampling contribution secret: %w", err)
+		}
+		e.SetBigInt(v.SetBytes(buf[:]))
+	}
+	return nil
+}
+
 func bitReverse[T any](a []T) {
 	n := uint64(len(a))
 	nn := uint64(64 - bits.TrailingZeros64(n))
@@ -79,8 +99,11 @@ func powers(a fr.Element, n int) []fr.Element {
 	return result
 }
 
-// Returns [aᵢAᵢ, ...] in G1
-func scaleG1InPlace(A []curve.G1Affine, a []fr.Element) {
+// Returns [aᵢAᵢ, ...] in G1, on the device if it is an accel.ScalarMulDevice
+func scaleG1InPlace(A []curve.G1Affine, a []fr.Element, device accel.Device) error {
+	if done, err := scalarMulOnDevice(device, A, a, accel.ScalarMulDevice.ScalarMulG1); done || err != nil {
+		return err
+	}
 	utils.Parallelize(len(A), func(start, end int) {
 		var tmp big.Int
 		for i := start; i < end; i++ {
@@ -88,10 +111,14 @@ func scaleG1InPlace(A []curve.G1Affine, a []fr.Element) {
 			A[i].ScalarMultiplication(&A[i], &tmp)
 		}
 	})
+	return nil
 }
 
-// Returns [aᵢAᵢ, ...] in G2
-func scaleG2InPlace(A []curve.G2Affine, a []fr.Element) {
+// Returns [aᵢAᵢ, ...] in G2, on the device if it is an accel.ScalarMulDevice
+func scaleG2InPlace(A []curve.G2Affine, a []fr.Element, device accel.Device) error {
+	if done, err := scalarMulOnDevice(device, A, a, accel.ScalarMulDevice.ScalarMulG2); done || err != nil {
+		return err
+	}
 	utils.Parallelize(len(A), func(start, end int) {
 		var tmp big.Int
 		for i := start; i < end; i++ {
@@ -99,6 +126,33 @@ func scaleG2InPlace(A []curve.G2Affine, a []fr.Element) {
 			A[i].ScalarMultiplication(&A[i], &tmp)
 		}
 	})
+	return nil
+}
+
+// Sets Aᵢ to aAᵢ in G1, on the device if it is an accel.ScalarMulDevice
+func scaleG1(A []curve.G1Affine, a fr.Element, device accel.Device) error {
+	if _, ok := device.(accel.ScalarMulDevice); ok && len(A) > 0 {
+		n := len(A)
+		if n > scalarMulChunk {
+			n = scalarMulChunk
+		}
+		scalars := make([]fr.Element, n)
+		for i := range scalars {
+			scalars[i] = a
+		}
+		done, err := scalarMulOnDevice(device, A, scalars, accel.ScalarMulDevice.ScalarMulG1)
+		if done || err != nil {
+			return err
+		}
+	}
+	var b big.Int
+	a.BigInt(&b)
+	utils.Parallelize(len(A), func(start, end int) {
+		for i := start; i < end; i++ {
+			A[i].ScalarMultiplication(&A[i], &b)
+		}
+	})
+	return nil
 }
 
 // Check e(a₁, a₂) = e(b₁, b₂)
@@ -117,15 +171,24 @@ func sameRatio(a1, b1 curve.G1Affine, a2, b2 curve.G2Affine) bool {
 	return res
 }
 
-// returns a = ∑ rᵢAᵢ, b = ∑ rᵢBᵢ
-func merge(A, B []curve.G1Affine) (a, b curve.G1Affine) {
-	nc := runtime.NumCPU()
+// returns a = ∑ rᵢAᵢ, b = ∑ rᵢBᵢ, on the device if not nil
+func merge(A, B []curve.G1Affine, device accel.Device) (a, b curve.G1Affine, err error) {
 	r := make([]fr.Element, len(A))
 	for i := 0; i < len(A); i++ {
 		r[i].SetRandom()
 	}
-	a.MultiExp(A, r, ecc.MultiExpConfig{NbTasks: nc / 2})
-	b.MultiExp(B, r, ecc.MultiExpConfig{NbTasks: nc / 2})
+	if device != nil {
+		if a, err = msmOnDevice(device, A, r); err != nil {
+			return
+		}
+		b, err = msmOnDevice(device, B, r)
+		return
+	}
+	nc := runtime.NumCPU()
+	if _, err = a.MultiExp(A, r, ecc.MultiExpConfig{NbTasks: nc / 2}); err != nil {
+		return
+	}
+	_, err = b.MultiExp(B, r, ecc.MultiExpConfig{NbTasks: nc / 2})
 	return
 }
 
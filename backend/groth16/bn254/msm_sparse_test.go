@@ -0,0 +1,96 @@
+package groth16_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+// sparseCircuit has many zero and duplicate wire values: the bits of X and of Y, whose
+// high bits are zero, and an unconstrained input, whose point of K is at infinity.
+type sparseCircuit struct {
+	X, Y   frontend.Variable
+	Unused frontend.Variable
+	Sum    frontend.Variable `gnark:",public"`
+}
+
+func (c *sparseCircuit) Define(api frontend.API) error {
+	x := api.ToBinary(c.X, 64)
+	y := api.ToBinary(c.Y, 64)
+	sum := frontend.Variable(0)
+	for i := range x {
+		sum = api.Add(sum, api.Mul(x[i], y[i]))
+	}
+	api.AssertIsEqual(sum, c.Sum)
+	return nil
+}
+
+// msmSizes records the sizes of the multi-exponentiations of a proof.
+type msmSizes struct {
+	lock  sync.Mutex
+	sizes map[string]int
+}
+
+func (t *msmSizes) OnStageStart(ctx context.Context, stage backend.Stage, meta backend.StageMetadata) context.Context {
+	if stage == backend.StageMSMG1 || stage == backend.StageMSMG2 {
+		t.lock.Lock()
+		t.sizes[meta.Label] = meta.Size
+		t.lock.Unlock()
+	}
+	return ctx
+}
+
+func (t *msmSizes) OnStageEnd(context.Context, backend.Stage, backend.StageMetadata, time.Duration) {}
+
+func TestSparseMSM(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(accel.EnvVar, "cpu") // the CPU emulation supports all the layouts
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sparseCircuit{}, frontend.IgnoreUnconstrainedInputs())
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(_r1cs)
+	assert.NoError(err)
+	_pk := pk.(*groth16_bn254.ProvingKey)
+
+	w, err := frontend.NewWitness(&sparseCircuit{X: 0xff00ff, Y: 0xf0f0f0, Unused: 5, Sum: 8}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	prove := func(opts ...backend.ProverOption) map[string]int {
+		sizes := &msmSizes{sizes: make(map[string]int)}
+		proof, err := groth16.Prove(_r1cs, pk, w, append(opts, backend.WithTracer(sizes))...)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, public))
+		return sizes.sizes
+	}
+
+	withInfinity := accel.PointsConfig{WithInfinity: true}
+	projective := accel.PointsConfig{Representation: accel.Projective}
+	for _, layout := range []groth16_bn254.DeviceLayout{
+		{},
+		{A: withInfinity, B: withInfinity, K: withInfinity, G2B: withInfinity},
+		{A: projective, B: projective, K: projective, G2B: projective},
+	} {
+		assert.NoError(_pk.SetDeviceLayout(layout))
+		dense := prove()
+		sparse := prove(backend.WithSparseMSM(false))
+		grouped := prove(backend.WithSparseMSM(true))
+		for _, label := range []string{"AR1", "BS1", "KRS", "BS2"} {
+			assert.Less(sparse[label], dense[label], "%s %+v", label, layout)
+			// the bits are 0 or 1, and the sum and the inputs distinct
+			assert.LessOrEqual(grouped[label], 5, "%s %+v", label, layout)
+		}
+		assert.Equal(dense["KRS2"], sparse["KRS2"])
+	}
+}
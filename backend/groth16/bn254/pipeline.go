@@ -0,0 +1,127 @@
+package groth16
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint/bn254"
+)
+
+// pipelineSegmentSize is the number of wire values compared and copied at once by a Pipeline.
+const pipelineSegmentSize = 1 << 12
+
+// Pipeline proves consecutive witnesses of a circuit, such as the windows of a stream of
+// inputs, keeping the wire values of the last proof on the device: a proof only copies to the
+// device the segments of the wire values which changed since the previous one.
+//
+// The saving depends on the layout of the circuit: the wire values of a circuit which keeps its
+// inputs in fixed slots (a ring buffer) mostly don't move when one input changes, while the ones
+// of a circuit shifting its inputs all change. See groth16.SlidingWindow.
+//
+// The proofs of a Pipeline are sequential; a Pipeline is safe for concurrent use.
+type Pipeline struct {
+	r1cs *cs.R1CS
+	pk   *ProvingKey
+	opts []backend.ProverOption
+
+	lock  sync.Mutex
+	cache wireValuesCache
+}
+
+// NewPipeline returns a Pipeline proving witnesses of r1cs with pk and the prover options.
+func NewPipeline(r1cs *cs.R1CS, pk *ProvingKey, opts ...backend.ProverOption) *Pipeline {
+	return &Pipeline{r1cs: r1cs, pk: pk, opts: opts}
+}
+
+// Prove is Prove, reusing the wire values of the previous proof on the device.
+func (p *Pipeline) Prove(fullWitness witness.Witness) (*Proof, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return prove(p.r1cs, p.pk, fullWitness, &p.cache, p.opts...)
+}
+
+// Close releases the device copies of the wire values. The Pipeline may be used again.
+func (p *Pipeline) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.pk.device == nil {
+		return nil
+	}
+	errA := p.cache.a.release(p.pk.device)
+	if errB := p.cache.b.release(p.pk.device); errB != nil {
+		return errB
+	}
+	return errA
+}
+
+// wireValuesCache holds the device copies of the wire values of the last proof.
+type wireValuesCache struct {
+	a, b deviceScalars
+}
+
+// deviceScalars is a vector of scalars on the device, with its host copy.
+type deviceScalars struct {
+	host   []fr.Element
+	device unsafe.Pointer
+}
+
+// update sets the device vector to values, copying the segments which differ from the host
+// copy, and returns it.
+func (s *deviceScalars) update(d accel.Device, values []fr.Element) (unsafe.Pointer, error) {
+	if s.device == nil || len(s.host) != len(values) {
+		if err := s.release(d); err != nil {
+			return nil, err
+		}
+		p, err := scalarsToDevice(d, values)
+		if err != nil {
+			return nil, err
+		}
+		s.host, s.device = append([]fr.Element(nil), values...), p
+		return p, nil
+	}
+
+	for start := 0; start < len(values); start += pipelineSegmentSize {
+		end := start + pipelineSegmentSize
+		if end > len(values) {
+			end = len(values)
+		}
+		if equalScalars(s.host[start:end], values[start:end]) {
+			continue
+		}
+		segment := unsafe.Add(s.device, start*fr.Bytes)
+		err := d.CopyToDevice(segment, unsafe.Pointer(&values[start]), (end-start)*fr.Bytes)
+		if err == nil {
+			err = d.FromMontgomery(segment, end-start)
+		}
+		if err != nil {
+			// the device copy is partially updated
+			_ = s.release(d)
+			return nil, err
+		}
+		copy(s.host[start:end], values[start:end])
+	}
+	return s.device, nil
+}
+
+// release frees the device vector.
+func (s *deviceScalars) release(d accel.Device) error {
+	if s.device == nil {
+		return nil
+	}
+	err := d.Free(s.device)
+	s.host, s.device = nil, nil
+	return err
+}
+
+func equalScalars(a, b []fr.Element) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,371 @@
+package groth16
+
+import (
+	"bufio"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// DefaultFileCacheSize is the number of files, constraint systems and proving keys, cached by
+// ProveFromFiles unless SetFileCacheSize changes it.
+const DefaultFileCacheSize = 8
+
+// fileCache holds the constraint systems and proving keys loaded by ProveFromFiles, keyed by
+// the SHA-256 of their file. Cached proving keys keep their device (GPU) buffers, so that
+// proving repeatedly with the same key doesn't upload it again.
+//
+// The files are loaded without holding the lock: the concurrent calls loading the same file
+// wait for the first one. The least recently used files beyond the capacity are evicted, and
+// released (the mappings closed, the device buffers freed) once the proofs using them end.
+var fileCache = struct {
+	sync.Mutex
+	capacity int
+	digests  map[string]fileDigestEntry // by absolute path
+	entries  map[fileKey]*list.Element  // of *cachedFile
+	lru      list.List                  // the most recently used first
+}{
+	capacity: DefaultFileCacheSize,
+	digests:  make(map[string]fileDigestEntry),
+	entries:  make(map[fileKey]*list.Element),
+}
+
+// fileStamp identifies a version of a file, to avoid hashing it again when it didn't change.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// fileDigestEntry is the digest of the last version of a file hashed.
+type fileDigestEntry struct {
+	stamp  fileStamp
+	digest [sha256.Size]byte
+}
+
+// fileKey identifies a cached file: the same file may be cached as a constraint system and as a
+// proving key, when the first call fails to decode it.
+type fileKey struct {
+	digest [sha256.Size]byte
+	pk     bool
+}
+
+// cachedFile is a constraint system or a proving key of the cache, loaded when done is closed.
+type cachedFile struct {
+	key  fileKey
+	done chan struct{}
+	err  error
+
+	r1cs   *cs.R1CS
+	closer io.Closer // of the mapping of the constraint system, if mapped
+	pk     *ProvingKey
+
+	// users is the number of calls using the file, and evicted is set once the file is
+	// removed from the cache: the last user releases it.
+	users   int
+	evicted bool
+}
+
+// SetFileCacheSize sets the number of files, constraint systems and proving keys, cached by
+// ProveFromFiles (DefaultFileCacheSize by default), and evicts the least recently used files
+// beyond it. With 0, the files are loaded at each call.
+func SetFileCacheSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	fileCache.Lock()
+	fileCache.capacity = size
+	released := evictFiles()
+	fileCache.Unlock()
+	for _, f := range released {
+		f.free()
+	}
+}
+
+// ProveFromFiles generates a proof from a constraint system, a proving key and a full witness
+// stored in files, and writes the proof (see Proof.WriteTo) to proofPath if it isn't empty.
+//
+// The constraint system file may be written either with WriteTo or with WriteMappableTo, in
+// which case it is mapped in memory instead of being decoded. The witness must be in the
+// binary format of witness.Witness.WriteTo. The proving key file may be written with WriteTo,
+// WriteRawTo or WriteCompressed.
+//
+// The constraint systems and proving keys are cached, keyed by the hash of their file: calling
+// ProveFromFiles again with the same files reuses the keys already loaded on the device. The
+// cache keeps the most recently used files, see SetFileCacheSize.
+func ProveFromFiles(r1csPath, pkPath, witnessPath, proofPath string, opts ...backend.ProverOption) (*Proof, error) {
+	r1cs, err := acquireFile(r1csPath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer r1cs.release()
+	pk, err := acquireFile(pkPath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer pk.release()
+
+	fullWitness, err := witness.New(fr.Modulus())
+	if err != nil {
+		return nil, err
+	}
+	if err := readFile(witnessPath, fullWitness); err != nil {
+		return nil, err
+	}
+
+	proof, err := Prove(r1cs.r1cs, pk.pk, fullWitness, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if proofPath != "" {
+		if err := writeFileAtomic(proofPath, proof); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// acquireFile returns the cached constraint system, or proving key if pk, of the file, loading
+// it if it isn't cached. The file must be released once it is no longer used.
+func acquireFile(path string, pk bool) (*cachedFile, error) {
+	digest, err := fileDigest(path)
+	if err != nil {
+		return nil, err
+	}
+	key := fileKey{digest: digest, pk: pk}
+
+	fileCache.Lock()
+	if e, ok := fileCache.entries[key]; ok {
+		fileCache.lru.MoveToFront(e)
+		f := e.Value.(*cachedFile)
+		f.users++
+		fileCache.Unlock()
+		<-f.done
+		if f.err != nil {
+			f.release()
+			return nil, f.err
+		}
+		return f, nil
+	}
+	f := &cachedFile{key: key, done: make(chan struct{}), users: 1}
+	fileCache.entries[key] = fileCache.lru.PushFront(f)
+	released := evictFiles()
+	fileCache.Unlock()
+	for _, e := range released {
+		e.free()
+	}
+
+	if pk {
+		f.err = f.loadProvingKey(path)
+	} else {
+		f.err = f.loadR1CS(path)
+	}
+	close(f.done)
+	if f.err != nil {
+		// the next call loads the file again
+		f.remove()
+		f.release()
+		return nil, f.err
+	}
+	return f, nil
+}
+
+// release ends a use of the file, and releases it if it was the last one of an evicted file.
+func (f *cachedFile) release() {
+	fileCache.Lock()
+	f.users--
+	last := f.evicted && f.users == 0
+	fileCache.Unlock()
+	if last {
+		f.free()
+	}
+}
+
+// remove removes the file from the cache, if it is still cached, and releases it if nothing
+// uses it. Otherwise, the last user releases it.
+func (f *cachedFile) remove() {
+	fileCache.Lock()
+	removed := false
+	if e, ok := fileCache.entries[f.key]; ok && e.Value == f {
+		fileCache.lru.Remove(e)
+		delete(fileCache.entries, f.key)
+		f.evicted, removed = true, true
+	}
+	unused := removed && f.users == 0
+	fileCache.Unlock()
+	if unused {
+		f.free()
+	}
+}
+
+// free closes the mapping of the constraint system, or frees the device buffers of the proving
+// key, once nothing uses them.
+func (f *cachedFile) free() {
+	if f.closer != nil {
+		_ = f.closer.Close()
+	}
+	if f.pk != nil {
+		_ = f.pk.waitG2()
+		deviceLock.Lock()
+		f.pk.releaseDevice()
+		deviceLock.Unlock()
+	}
+}
+
+// evictFiles removes the least recently used files beyond the capacity from the cache, with the
+// lock held, and returns the ones to release, which nothing uses. The files in use are
+// released by their last user.
+func evictFiles() []*cachedFile {
+	var released []*cachedFile
+	for fileCache.lru.Len() > fileCache.capacity {
+		e := fileCache.lru.Back()
+		f := e.Value.(*cachedFile)
+		fileCache.lru.Remove(e)
+		delete(fileCache.entries, f.key)
+		f.evicted = true
+		if f.users == 0 {
+			released = append(released, f)
+		}
+		for path, d := range fileCache.digests {
+			if d.digest == f.key.digest {
+				delete(fileCache.digests, path)
+			}
+		}
+	}
+	return released
+}
+
+func (f *cachedFile) loadR1CS(path string) error {
+	mappable, err := cs.IsMappableFile(path)
+	if err != nil {
+		return err
+	}
+	if mappable {
+		// the mapping is kept open as long as the system is cached.
+		f.r1cs, f.closer, err = cs.OpenMapped(path)
+		return err
+	}
+	r1cs := new(cs.R1CS)
+	if err := readFile(path, r1cs); err != nil {
+		return err
+	}
+	f.r1cs = r1cs
+	return nil
+}
+
+func (f *cachedFile) loadProvingKey(path string) error {
+	// the points of B in G2 are read while the first proof starts, see ReadSegmented
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	pk := new(ProvingKey)
+
+	// the compressed keys are decompressed in parallel instead, see WriteCompressed
+	var header [8]byte
+	if _, err := file.ReadAt(header[:], 0); err == nil && gnarkio.IsCompressed(header[:]) {
+		_, err := pk.ReadCompressed(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		f.pk = pk
+		return nil
+	}
+
+	if _, err := pk.ReadSegmented(file); err != nil {
+		_ = pk.waitG2()
+		file.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	go func() {
+		err := pk.waitG2()
+		file.Close()
+		if err != nil {
+			// the next call reads the key again
+			f.remove()
+		}
+	}()
+	f.pk = pk
+	return nil
+}
+
+// fileDigest returns the SHA-256 of the file, hashing it only if it changed since the last call.
+func fileDigest(path string) (digest [sha256.Size]byte, err error) {
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+
+	fileCache.Lock()
+	d, ok := fileCache.digests[path]
+	fileCache.Unlock()
+	if ok && d.stamp == stamp {
+		return d.digest, nil
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return
+	}
+	copy(digest[:], h.Sum(nil))
+
+	fileCache.Lock()
+	fileCache.digests[path] = fileDigestEntry{stamp: stamp, digest: digest}
+	fileCache.Unlock()
+	return
+}
+
+func readFile(path string, r io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := r.ReadFrom(bufio.NewReaderSize(f, 1<<20)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file renamed to path once complete, so that readers
+// never observe a partially written file.
+func writeFileAtomic(path string, w io.WriterTo) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+
+	if _, err := w.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,176 @@
+package groth16_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProveFromFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+
+	writeFile := func(name string, write func(io.Writer) (int64, error)) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		assert.NoError(err)
+		_, err = write(f)
+		assert.NoError(err)
+		assert.NoError(f.Close())
+		return path
+	}
+
+	fullWitness, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	r1csPath := writeFile("circuit.r1cs", _r1cs.WriteTo)
+	mappedPath := writeFile("circuit.mapped", _r1cs.(*cs.R1CS).WriteMappableTo)
+	pkPath := writeFile("circuit.pk", pk.WriteTo)
+	witnessPath := writeFile("circuit.wtns", fullWitness.WriteTo)
+	proofPath := filepath.Join(dir, "proof")
+
+	for _, path := range []string{r1csPath, mappedPath, mappedPath} {
+		proof, err := groth16.ProveFromFiles(path, pkPath, witnessPath, proofPath)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+		// the written proof is the returned one
+		f, err := os.Open(proofPath)
+		assert.NoError(err)
+		var written groth16_bn254.Proof
+		_, err = written.ReadFrom(f)
+		assert.NoError(err)
+		assert.NoError(f.Close())
+		assert.NoError(groth16.Verify(&written, vk, publicWitness))
+		assert.NoError(os.Remove(proofPath))
+	}
+
+	// the compressed keys are detected
+	compressedPath := writeFile("circuit.pkz", func(w io.Writer) (int64, error) {
+		return pk.(*groth16_bn254.ProvingKey).WriteCompressed(w, gnarkio.CodecFlate)
+	})
+	proof, err := groth16.ProveFromFiles(r1csPath, compressedPath, witnessPath, "")
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	_, err = groth16.ProveFromFiles(filepath.Join(dir, "missing"), pkPath, witnessPath, "")
+	assert.Error(err)
+}
+
+// trackedDevices are the devices opened by the "tracked" provider, the CPU emulation recording
+// its live buffers.
+var trackedDevices struct {
+	sync.Mutex
+	devices []accel.Device
+}
+
+func init() {
+	accel.Register(accel.Provider{
+		Name:   "tracked",
+		Curves: []ecc.ID{ecc.BN254},
+		Open: func(curve ecc.ID) (accel.Device, error) {
+			d, err := accel.OpenProvider("cpu", curve)
+			if err != nil {
+				return nil, err
+			}
+			d = accel.Track(d)
+			trackedDevices.Lock()
+			trackedDevices.devices = append(trackedDevices.devices, d)
+			trackedDevices.Unlock()
+			return d, nil
+		},
+		Fallback: true, // only opened by name
+	})
+}
+
+// trackedBuffers returns the number of devices opened by the "tracked" provider, and of their
+// live buffers.
+func trackedBuffers() (nbDevices, nbBuffers int) {
+	trackedDevices.Lock()
+	defer trackedDevices.Unlock()
+	for _, d := range trackedDevices.devices {
+		nbBuffers += len(accel.Allocations(d))
+	}
+	return len(trackedDevices.devices), nbBuffers
+}
+
+func TestProveFromFilesCache(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	groth16.SetFileCacheSize(0)
+	defer groth16.SetFileCacheSize(groth16_bn254.DefaultFileCacheSize)
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	fullWitness, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+	writeFile := func(name string, w io.WriterTo) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		assert.NoError(err)
+		_, err = w.WriteTo(f)
+		assert.NoError(err)
+		assert.NoError(f.Close())
+		return path
+	}
+	r1csPath := writeFile("circuit.r1cs", _r1cs)
+	pkPath := writeFile("circuit.pk", pk)
+	witnessPath := writeFile("circuit.wtns", fullWitness)
+
+	// the keys are copied to the device as they are read, see ReadSegmented
+	t.Setenv(accel.EnvVar, "tracked")
+	prove := func() {
+		proof, err := groth16.ProveFromFiles(r1csPath, pkPath, witnessPath, "", backend.WithAccelerator("tracked"))
+		if assert.NoError(err) {
+			assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		}
+	}
+
+	// the concurrent calls load the key once, without waiting for each other's proofs
+	groth16.SetFileCacheSize(groth16_bn254.DefaultFileCacheSize)
+	nbDevices, _ := trackedBuffers()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prove()
+		}()
+	}
+	wg.Wait()
+	n, nbBuffers := trackedBuffers()
+	assert.Equal(nbDevices+1, n, "the proving key must be loaded once")
+	assert.NotZero(nbBuffers, "the cached proving key keeps its device buffers")
+
+	// the evicted keys free their device buffers
+	groth16.SetFileCacheSize(0)
+	_, nbBuffers = trackedBuffers()
+	assert.Zero(nbBuffers)
+
+	// with one file, the constraint system is evicted by the key, then the key by the system
+	groth16.SetFileCacheSize(1)
+	prove()
+	prove()
+	n, _ = trackedBuffers()
+	assert.Equal(nbDevices+3, n)
+	groth16.SetFileCacheSize(0)
+	_, nbBuffers = trackedBuffers()
+	assert.Zero(nbBuffers)
+}
@@ -0,0 +1,90 @@
+package groth16_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+// tasksDevice is the CPU emulation, recording the number of tasks of its last multi-exponentiation
+// in G2, or rejecting a number of tasks as the GPU providers do if gpu.
+type tasksDevice struct {
+	accel.Device
+	gpu bool
+}
+
+// lastNbTasks is the number of tasks of the last multi-exponentiation in G2 of a tasksDevice.
+var lastNbTasks atomic.Int64
+
+func (d tasksDevice) MsmG2(res, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	if d.gpu && cfg.NbTasks != 0 {
+		return fmt.Errorf("%w: no CPU tasks on a GPU", accel.ErrUnsupported)
+	}
+	if n > 0 {
+		lastNbTasks.Store(int64(cfg.NbTasks))
+	}
+	return d.Device.MsmG2(res, scalars, points, n, cfg)
+}
+
+func init() {
+	for _, gpu := range []bool{false, true} {
+		gpu := gpu
+		name := "tasks"
+		if gpu {
+			name = "tasks-gpu"
+		}
+		accel.Register(accel.Provider{
+			Name:   name,
+			Curves: []ecc.ID{ecc.BN254},
+			Open: func(curve ecc.ID) (accel.Device, error) {
+				d, err := accel.OpenProvider("cpu", curve)
+				return tasksDevice{Device: d, gpu: gpu}, err
+			},
+			Fallback: true, // only opened by name
+		})
+	}
+}
+
+func TestMultiExpNbTasks(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sparseCircuit{}, frontend.IgnoreUnconstrainedInputs())
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&sparseCircuit{X: 0xff00ff, Y: 0xf0f0f0, Unused: 5, Sum: 8}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	// the CPU emulation runs the multi-exponentiation in G2 on the tasks of the option
+	dpk := pk.(groth16.DeviceProvingKey)
+	assert.NoError(dpk.SetAccelerator("tasks"))
+	for _, nbTasks := range []int{3, 0} {
+		proof, err := groth16.Prove(ccs, pk, w, backend.WithMultiExpNbTasks(nbTasks))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, public))
+		assert.EqualValues(nbTasks, lastNbTasks.Load())
+	}
+
+	// the GPUs reject the option
+	assert.NoError(dpk.SetAccelerator("tasks-gpu"))
+	_, err = groth16.Prove(ccs, pk, w, backend.WithMultiExpNbTasks(3))
+	assert.ErrorIs(err, accel.ErrUnsupported)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+
+	// gnark-crypto bounds the number of tasks, whichever the accelerator
+	_, err = groth16.Prove(ccs, pk, w, backend.WithMultiExpNbTasks(1025))
+	assert.Error(err)
+}
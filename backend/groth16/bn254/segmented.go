@@ -0,0 +1,128 @@
+package groth16
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+// lazyG2 is the reading of the points of B in G2 in the background, see ReadSegmented.
+type lazyG2 struct {
+	done chan struct{}
+	err  error
+}
+
+// ReadSegmented reads a proving key encoded with WriteTo or WriteRawTo like ReadFrom, except the
+// points of B in G2, which are read and copied to the device in the background. It returns once
+// the rest of the key is read and on the device, so that the proofs start while the points of B
+// in G2, used by the last multi-scalar multiplication only, are read: the prover waits for them
+// after its multi-scalar multiplications in G1 start.
+//
+// r, for instance an *os.File, must stay readable and unchanged until the points are read. The
+// error reading them, if any, is returned by the proofs and the methods of the key needing them,
+// as is the mismatch of the hash of the key, checked once they are read.
+func (pk *ProvingKey) ReadSegmented(r io.ReaderAt) (int64, error) {
+	_ = pk.waitG2()
+	pk.g2 = nil
+
+	// the offsets in r are the numbers of bytes decoded, the readers being buffered
+	head := bufio.NewReaderSize(io.NewSectionReader(r, 0, math.MaxInt64), 1<<20)
+	n, err := pk.Domain.ReadFrom(head)
+	if err != nil {
+		return n, err
+	}
+	dec := curve.NewDecoder(head)
+	for _, v := range []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G1.A,
+		&pk.G1.B,
+		&pk.G1.Z,
+		&pk.G1.K,
+		&pk.G2.Beta,
+	} {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	// the points of B in G2 have the size of [δ]2, compressed or not
+	start := dec.BytesRead()
+	if err := dec.Decode(&pk.G2.Delta); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	pointSize := dec.BytesRead() - start
+
+	// the points of B in G2 are a slice, prefixed with its length
+	offsetG2 := n + dec.BytesRead()
+	var length [4]byte
+	if _, err := io.ReadFull(head, length[:]); err != nil {
+		return offsetG2, err
+	}
+	sizeG2 := int64(len(length)) + int64(binary.BigEndian.Uint32(length[:]))*pointSize
+
+	tail := bufio.NewReaderSize(io.NewSectionReader(r, offsetG2+sizeG2, math.MaxInt64-offsetG2-sizeG2), 1<<20)
+	dec = curve.NewDecoder(tail)
+	if err := pk.decodeWires(dec); err != nil {
+		return offsetG2 + sizeG2 + dec.BytesRead(), err
+	}
+	n = offsetG2 + sizeG2 + dec.BytesRead()
+	m, err := pk.readHashes(tail)
+	if err != nil {
+		return n + m, err
+	}
+	n += m
+
+	g2 := &lazyG2{done: make(chan struct{})}
+	pk.G2.B, pk.g2 = nil, g2
+	devicePointers := make(chan error, 1)
+	go func() {
+		defer close(g2.done)
+		var points []curve.G2Affine
+		err := curve.NewDecoder(bufio.NewReaderSize(io.NewSectionReader(r, offsetG2, sizeG2), 1<<20)).Decode(&points)
+		if errDevice := <-devicePointers; err == nil {
+			err = errDevice
+		}
+		if err != nil {
+			g2.err = err
+			return
+		}
+		pk.G2.B = points
+		// the hash needs all the points, it is checked before the first proof ends
+		if err := pk.checkHash(); err != nil {
+			g2.err = err
+			return
+		}
+		g2.err = pk.setupDeviceG2()
+	}()
+
+	err = pk.setupDevicePointers()
+	devicePointers <- err
+	return n, err
+}
+
+// waitG2 waits for the points of B in G2 being read by ReadSegmented, if any, and returns the
+// error reading them or copying them to the device.
+func (pk *ProvingKey) waitG2() error {
+	if pk.g2 == nil {
+		return nil
+	}
+	<-pk.g2.done
+	return pk.g2.err
+}
+
+// loadingG2 returns true if the points of B in G2 are being read by ReadSegmented.
+func (pk *ProvingKey) loadingG2() bool {
+	if pk.g2 == nil {
+		return false
+	}
+	select {
+	case <-pk.g2.done:
+		return false
+	default:
+		return true
+	}
+}
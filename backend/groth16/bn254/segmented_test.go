@@ -0,0 +1,89 @@
+package groth16_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSegmented(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &twoCommitmentsCircuit{})
+	_pk := pk.(*groth16_bn254.ProvingKey)
+	w, err := frontend.NewWitness(&twoCommitmentsCircuit{One: 1, Two: 2, Three: 3}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	for _, write := range []func(io.Writer) (int64, error){_pk.WriteTo, _pk.WriteRawTo} {
+		var buf bytes.Buffer
+		written, err := write(&buf)
+		assert.NoError(err)
+
+		var read groth16_bn254.ProvingKey
+		n, err := read.ReadSegmented(bytes.NewReader(buf.Bytes()))
+		assert.NoError(err)
+		assert.Equal(written, n)
+
+		// the proofs start while the points of B in G2 are read
+		for _, opts := range [][]backend.ProverOption{nil, {backend.WithSparseMSM(true)}} {
+			proof, err := groth16.Prove(_r1cs, &read, w, opts...)
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, public))
+		}
+
+		// the key read is the key written
+		var rewritten bytes.Buffer
+		_, err = read.WriteRawTo(&rewritten)
+		assert.NoError(err)
+		var expected bytes.Buffer
+		_, err = _pk.WriteRawTo(&expected)
+		assert.NoError(err)
+		assert.Equal(expected.Bytes(), rewritten.Bytes())
+	}
+
+	// the error reading the points of B in G2 is returned by the prover
+	var buf bytes.Buffer
+	_, err = _pk.WriteRawTo(&buf)
+	assert.NoError(err)
+	data := buf.Bytes()
+	var read groth16_bn254.ProvingKey
+	_, err = read.ReadSegmented(&corruptG2{data: data, g2: g2Offset(t, _pk, data)})
+	assert.NoError(err)
+	_, err = groth16.Prove(_r1cs, &read, w)
+	assert.Error(err)
+}
+
+// corruptG2 is the encoding of a proving key, whose first point of B in G2, at g2, is invalid.
+type corruptG2 struct {
+	data []byte
+	g2   int64
+}
+
+func (r *corruptG2) ReadAt(p []byte, off int64) (int, error) {
+	n, err := bytes.NewReader(r.data).ReadAt(p, off)
+	for i := range p[:n] {
+		if off+int64(i) == r.g2+4+8 { // in X of the first point, after the length
+			p[i] ^= 0xff
+		}
+	}
+	return n, err
+}
+
+// g2Offset returns the offset of the points of B in G2 in the raw encoding of the key.
+func g2Offset(t *testing.T, pk *groth16_bn254.ProvingKey, data []byte) int64 {
+	delta := pk.G2.Delta.Marshal()
+	i := bytes.Index(data, delta)
+	if i < 0 {
+		t.Fatal("[δ]2 not found in the encoding of the key")
+	}
+	return int64(i + len(delta))
+}
@@ -12,28 +12,27 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"math/big"
 	"math/bits"
+	"os"
 	"unsafe"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
-	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/constraint/bn254"
-	"github.com/ingonyama-zk/icicle/goicicle"
-	icicle "github.com/ingonyama-zk/icicle/goicicle/curves/bn254"
-	"github.com/ingonyama-zk/iciclegnark/curves/bn254"
+	"github.com/consensys/gnark/internal/utils"
 )
 
 // ProvingKey is used by a Groth16 prover to encode a proof of a statement
@@ -79,7 +78,24 @@ type ProvingKey struct {
 	InfinityA, InfinityB     []bool
 	NbInfinityA, NbInfinityB uint64
 
-	CommitmentKey pedersen.ProvingKey
+	CommitmentKeys []pedersen.ProvingKey // one per commitment of the circuit
+
+	// device copies of the commitment keys, without their points at infinity
+	CommitmentKeysDevice []struct {
+		Basis, BasisExpSigma unsafe.Pointer
+		InfPointIndices      []int
+	}
+
+	// device holding the device copies, of the accelerator provider, and their layout
+	device       accel.Device
+	accelerator  string
+	deviceLayout DeviceLayout
+
+	// the points of B in G2 being read in the background, see ReadSegmented
+	g2 *lazyG2
+
+	// integrity hashes, written at the end of the key
+	Hashes KeyHashes
 }
 
 // VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
@@ -102,12 +118,31 @@ type VerifyingKey struct {
 	// e(α, β)
 	e curve.GT // not serialized
 
-	CommitmentKey  pedersen.VerifyingKey
-	CommitmentInfo constraint.Commitment // since the verifier doesn't input a constraint system, this needs to be provided here
+	CommitmentKeys []pedersen.VerifyingKey // one per commitment of the circuit
+	CommitmentInfo constraint.Commitments  // since the verifier doesn't input a constraint system, this needs to be provided here
+
+	SetupLog backend.SetupLog // audit record of the setup, not used by the verifier
+
+	// Features are the optional features of the constraint system, checked when the key is
+	// deserialized, see constraint.Feature.
+	Features constraint.Features
+
+	// integrity hashes, checked when the key is deserialized
+	Hashes KeyHashes
 }
 
 // Setup constructs the SRS
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+//
+// The secrets of the setup (including the commitment key trapdoor) are read from the
+// sampler set with backend.WithSampler, crypto/rand by default. The sampler name is
+// recorded in vk.SetupLog.
+//
+// Setup is otherwise deterministic: with a deterministic sampler, the serialized keys are
+// byte-identical across runs and machines, unless the time of the setup is recorded with
+// backend.WithSetupTime. backend.WithReproducible checks the sampler and records it.
+//
+// The keys are given the hash of the constraint system and their own hash, see KeyHashes.
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey, opts ...backend.SetupOption) error {
 	/*
 		Setup
 		-----
@@ -120,20 +155,27 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.GetNbPublicVariables() + r1cs.GetNbSecretVariables()
-	nbPrivateCommittedWires := r1cs.CommitmentInfo.NbPrivateCommitted
+	nbCommitments := len(r1cs.CommitmentInfo)
+	nbPrivateCommittedWires := r1cs.CommitmentInfo.NbPrivateCommitted()
 	nbPublicWires := r1cs.GetNbPublicVariables()
 	nbPrivateWires := r1cs.GetNbSecretVariables() + r1cs.NbInternalVariables - nbPrivateCommittedWires
 
-	if r1cs.CommitmentInfo.Is() { // the commitment itself is defined by a hint so the prover considers it private
-		nbPublicWires++  // but the verifier will need to inject the value itself so on the groth16
-		nbPrivateWires-- // level it must be considered public
-	}
+	// the commitments themselves are defined by hints so the prover considers them private
+	// but the verifier will need to inject the values itself so on the groth16 level they
+	// must be considered public
+	nbPublicWires += nbCommitments
+	nbPrivateWires -= nbCommitments
 
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(r1cs.GetNbConstraints()))
 
+	cfg, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return err
+	}
+
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(cfg.Sampler)
 	if err != nil {
 		return err
 	}
@@ -161,7 +203,7 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// len(vk.K) == nbPublicWires
 	// len(Z) == domain.Cardinality
 
-	// compute scalars for pkK, vkK and ckK
+	// compute scalars for pkK, vkK and ckK (the commitment bases, concatenated)
 	pkK := make([]fr.Element, nbPrivateWires)
 	vkK := make([]fr.Element, nbPublicWires)
 	ckK := make([]fr.Element, nbPrivateCommittedWires)
@@ -176,27 +218,34 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 			Mul(&t1, coeff)
 	}
 
-	vI, cI := 0, 0
-	privateCommitted := r1cs.CommitmentInfo.PrivateCommitted()
+	// position in ckK of the private committed wires, and in vkK of the commitment wires:
+	// the commitments follow the public wires, in the order of the commitments
+	ckIndex := make(map[int]int, nbPrivateCommittedWires)
+	vkIndex := make(map[int]int, nbCommitments)
+	commitmentOffsets := make([]int, nbCommitments+1)
+	for j := range r1cs.CommitmentInfo {
+		for k, wire := range r1cs.CommitmentInfo[j].PrivateCommitted() {
+			ckIndex[wire] = commitmentOffsets[j] + k
+		}
+		commitmentOffsets[j+1] = commitmentOffsets[j] + r1cs.CommitmentInfo[j].NbPrivateCommitted
+		vkIndex[r1cs.CommitmentInfo[j].CommitmentIndex] = r1cs.GetNbPublicVariables() + j
+	}
 
+	pI := 0
 	for i := range A {
-		isCommittedPrivate := cI < len(privateCommitted) && i == privateCommitted[cI]
-		isCommitment := r1cs.CommitmentInfo.Is() && i == r1cs.CommitmentInfo.CommitmentIndex
-		isPublic := i < r1cs.GetNbPublicVariables()
-
-		if isPublic || isCommittedPrivate || isCommitment {
+		if i < r1cs.GetNbPublicVariables() {
 			computeK(i, &toxicWaste.gammaInv)
-
-			if isCommittedPrivate {
-				ckK[cI] = t1
-				cI++
-			} else {
-				vkK[vI] = t1
-				vI++
-			}
+			vkK[i] = t1
+		} else if vI, isCommitment := vkIndex[i]; isCommitment {
+			computeK(i, &toxicWaste.gammaInv)
+			vkK[vI] = t1
+		} else if cI, isCommittedPrivate := ckIndex[i]; isCommittedPrivate {
+			computeK(i, &toxicWaste.gammaInv)
+			ckK[cI] = t1
 		} else {
 			computeK(i, &toxicWaste.deltaInv)
-			pkK[i-vI-cI] = t1
+			pkK[pI] = t1
+			pI++
 		}
 	}
 
@@ -280,16 +329,22 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// ---------------------------------------------------------------------------------------------
 	// Commitment setup
 
-	if nbPrivateCommittedWires != 0 {
-		commitmentBasis := g1PointsAff[offset:]
-
-		pk.CommitmentKey, vk.CommitmentKey, err = pedersen.Setup(commitmentBasis)
+	// each commitment has its own key, so that its proof of knowledge only holds for the
+	// wires it commits to
+	commitmentBases := g1PointsAff[offset:]
+	pk.CommitmentKeys = make([]pedersen.ProvingKey, nbCommitments)
+	vk.CommitmentKeys = make([]pedersen.VerifyingKey, nbCommitments)
+	for j := range r1cs.CommitmentInfo {
+		basis := commitmentBases[commitmentOffsets[j]:commitmentOffsets[j+1]]
+		pk.CommitmentKeys[j], vk.CommitmentKeys[j], err = setupCommitmentKey(basis, cfg.Sampler)
 		if err != nil {
 			return err
 		}
 	}
 
 	vk.CommitmentInfo = r1cs.CommitmentInfo // unfortunate but necessary
+	vk.SetupLog = cfg.Log()
+	vk.Features = r1cs.GetFeatures()
 
 	// ---------------------------------------------------------------------------------------------
 	// G2 scalars
@@ -330,123 +385,110 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// set domain
 	pk.Domain = *domain
 
-	pk.setupDevicePointers()
+	if err := setHashes(r1cs, pk, vk); err != nil {
+		return err
+	}
 
-	return nil
+	if cfg.Accelerator != "" {
+		pk.accelerator = cfg.Accelerator
+	}
+	return pk.setupDevicePointers()
 }
 
-func (pk *ProvingKey) setupDevicePointers() {
+// setupDevicePointers copies the proving key to a device of the provider named by
+// pk.accelerator, or by the environment variable GNARK_ACCEL if empty, see accel.Open.
+//
+// Without a name, the fallback providers aren't selected: the key then has no device copies,
+// and is proved on the CPU, so that the CPU-only builds don't hold the points twice. The CPU
+// emulation is selected by its name, with GNARK_ACCEL=cpu or backend.WithAccelerator("cpu").
+func (pk *ProvingKey) setupDevicePointers() error {
+	name := pk.accelerator
+	if name == "" {
+		name = os.Getenv(accel.EnvVar)
+	}
+	provider, err := accel.Lookup(name, curve.ID)
+	if name == "" && (errors.Is(err, accel.ErrNoDevice) || err == nil && provider.Fallback) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	device, err := provider.Open(curve.ID)
+	if err != nil {
+		return err
+	}
+	// the live buffers are tracked for the crash dumps, see groth16.EnableCrashDumps
+	pk.device, pk.accelerator = accel.Track(device), provider.Name
+
 	n := int(pk.Domain.Cardinality)
-	sizeBytes := n * fr.Bytes
 
 	/*************************  Start Domain Device Setup  ***************************/
 
 	/*************************     CosetTableInv      ***************************/
-	cosetPowersInv_d, _ := goicicle.CudaMalloc(sizeBytes)
-	goicicle.CudaMemCpyHtoD[fr.Element](cosetPowersInv_d, pk.Domain.CosetTableInv, sizeBytes)
-	MontConvOnDevice(cosetPowersInv_d, len(pk.Domain.CosetTable), false)
-
-	pk.DomainDevice.CosetTableInv = cosetPowersInv_d
+	if pk.DomainDevice.CosetTableInv, err = scalarsToDevice(device, pk.Domain.CosetTableInv); err != nil {
+		return err
+	}
 
 	/*************************     CosetTable      ***************************/
-	cosetPowers_d, _ := goicicle.CudaMalloc(sizeBytes)
-	goicicle.CudaMemCpyHtoD[fr.Element](cosetPowers_d, pk.Domain.CosetTable, sizeBytes)
-	MontConvOnDevice(cosetPowers_d, len(pk.Domain.CosetTable), false)
-
-	pk.DomainDevice.CosetTable = cosetPowers_d
+	if pk.DomainDevice.CosetTable, err = scalarsToDevice(device, pk.Domain.CosetTable); err != nil {
+		return err
+	}
 
 	/*************************     Twiddles and Twiddles Inv    ***************************/
-	om_selector := int(math.Log(float64(n)) / math.Log(2))
-	twiddlesInv_d_gen, twddles_err := icicle.GenerateTwiddles(n, om_selector, true)
-
-	if twddles_err != nil {
-		fmt.Print(twiddlesInv_d_gen)
+	if pk.DomainDevice.TwiddlesInv, err = device.Twiddles(n, true); err != nil {
+		return err
 	}
-
-	twiddles_d_gen, twddles_err := icicle.GenerateTwiddles(n, om_selector, false)
-	if twddles_err != nil {
-		fmt.Print(twiddles_d_gen)
+	if pk.DomainDevice.Twiddles, err = device.Twiddles(n, false); err != nil {
+		return err
 	}
 
-	pk.DomainDevice.Twiddles = twiddles_d_gen
-	pk.DomainDevice.TwiddlesInv = twiddlesInv_d_gen
-
 	/*************************     Den      ***************************/
 	var denI, oneI fr.Element
 	oneI.SetOne()
 	denI.Exp(pk.Domain.FrMultiplicativeGen, big.NewInt(int64(pk.Domain.Cardinality)))
 	denI.Sub(&denI, &oneI).Inverse(&denI)
 
-	den_d, _ := goicicle.CudaMalloc(sizeBytes)
-	log2Size := int(math.Floor(math.Log2(float64(n))))
-	denIcicle := *bn254.NewFieldFromFrGnark[icicle.G1ScalarField](denI)
-	denIcicleArr := []icicle.G1ScalarField{denIcicle}
-	for i := 0; i < log2Size; i++ {
-		denIcicleArr = append(denIcicleArr, denIcicleArr...)
+	den := make([]fr.Element, n)
+	for i := range den {
+		den[i] = denI
 	}
-	for i := 0; i < (n - int(math.Pow(2, float64(log2Size)))); i++ {
-		denIcicleArr = append(denIcicleArr, denIcicle)
+	if pk.DenDevice, err = scalarsToDevice(device, den); err != nil {
+		return err
 	}
 
-	goicicle.CudaMemCpyHtoD[icicle.G1ScalarField](den_d, denIcicleArr, sizeBytes)
-
-	pk.DenDevice = den_d
-
 	/*************************  End Domain Device Setup  ***************************/
 
-	/*************************  Start G1 Device Setup  ***************************/
-	/*************************     A      ***************************/
-	pointsBytesA := len(pk.G1.A) * fp.Bytes * 2
-	a_d, _ := goicicle.CudaMalloc(pointsBytesA)
-	iciclePointsA := bn254.BatchConvertFromG1Affine(pk.G1.A)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](a_d, iciclePointsA, pointsBytesA)
-
-	pk.G1Device.A = a_d
-
-	/*************************     B      ***************************/
-	pointsBytesB := len(pk.G1.B) * fp.Bytes * 2
-	b_d, _ := goicicle.CudaMalloc(pointsBytesB)
-	iciclePointsB := bn254.BatchConvertFromG1Affine(pk.G1.B)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](b_d, iciclePointsB, pointsBytesB)
-
-	pk.G1Device.B = b_d
-
-	/*************************     K      ***************************/
-	//remove infinity points and save indices for removing scalars later
-	// TODO, find better way to save mem
-	var pointsNoInfinity []curve.G1Affine
-	for i, gnarkPoint := range pk.G1.K {
-		if gnarkPoint.IsInfinity() {
-			pk.G1InfPointIndices.K = append(pk.G1InfPointIndices.K, i)
-		} else {
-			pointsNoInfinity = append(pointsNoInfinity, gnarkPoint)
-		}
+	if err := pk.setupDevicePoints(); err != nil {
+		return err
 	}
 
-	pointsBytesK := len(pointsNoInfinity) * fp.Bytes * 2
-	k_d, _ := goicicle.CudaMalloc(pointsBytesK)
-	iciclePointsK := bn254.BatchConvertFromG1Affine(pointsNoInfinity)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](k_d, iciclePointsK, pointsBytesK)
-
-	pk.G1Device.K = k_d
-
-	/*************************     Z      ***************************/
-	pointsBytesZ := len(pk.G1.Z) * fp.Bytes * 2
-	z_d, _ := goicicle.CudaMalloc(pointsBytesZ)
-	iciclePointsZ := bn254.BatchConvertFromG1Affine(pk.G1.Z)
-	goicicle.CudaMemCpyHtoD[icicle.G1PointAffine](z_d, iciclePointsZ, pointsBytesZ)
-
-	pk.G1Device.Z = z_d
-	/*************************  End G1 Device Setup  ***************************/
-
-	/*************************  Start G2 Device Setup  ***************************/
-	pointsBytesB2 := len(pk.G2.B) * fp.Bytes * 4
-	b2_d, _ := goicicle.CudaMalloc(pointsBytesB2)
-	iciclePointsB2 := bn254.BatchConvertFromG2Affine(pk.G2.B)
-	goicicle.CudaMemCpyHtoD[icicle.G2PointAffine](b2_d, iciclePointsB2, pointsBytesB2)
-	pk.G2Device.B = b2_d
-	/*************************  End G2 Device Setup  ***************************/
+	/*************************  Start Commitment Keys Device Setup  ***************************/
+	pk.CommitmentKeysDevice = make([]struct {
+		Basis, BasisExpSigma unsafe.Pointer
+		InfPointIndices      []int
+	}, len(pk.CommitmentKeys))
+	for i := range pk.CommitmentKeys {
+		ck := &pk.CommitmentKeys[i]
+		// the points at infinity of the basis are the ones of basisExpSigma
+		var basis, basisExpSigma []curve.G1Affine
+		for j := range ck.Basis {
+			if ck.Basis[j].IsInfinity() {
+				pk.CommitmentKeysDevice[i].InfPointIndices = append(pk.CommitmentKeysDevice[i].InfPointIndices, j)
+			} else {
+				basis = append(basis, ck.Basis[j])
+				basisExpSigma = append(basisExpSigma, ck.BasisExpSigma[j])
+			}
+		}
+		if pk.CommitmentKeysDevice[i].Basis, err = g1AffineToDevice(device, basis, accel.PointsConfig{}); err != nil {
+			return err
+		}
+		if pk.CommitmentKeysDevice[i].BasisExpSigma, err = g1AffineToDevice(device, basisExpSigma, accel.PointsConfig{}); err != nil {
+			return err
+		}
+	}
+	/*************************  End Commitment Keys Device Setup  ***************************/
 
+	return nil
 }
 
 // Precompute sets e, -[δ]2, -[γ]2
@@ -554,32 +596,12 @@ type toxicWaste struct {
 	gammaInv, deltaInv           fr.Element
 }
 
-func sampleToxicWaste() (toxicWaste, error) {
+func sampleToxicWaste(sampler io.Reader) (toxicWaste, error) {
 
 	res := toxicWaste{}
 
-	for res.t.IsZero() {
-		if _, err := res.t.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.alpha.IsZero() {
-		if _, err := res.alpha.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.beta.IsZero() {
-		if _, err := res.beta.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.gamma.IsZero() {
-		if _, err := res.gamma.SetRandom(); err != nil {
-			return res, err
-		}
-	}
-	for res.delta.IsZero() {
-		if _, err := res.delta.SetRandom(); err != nil {
+	for _, e := range []*fr.Element{&res.t, &res.alpha, &res.beta, &res.gamma, &res.delta} {
+		if err := sampleNonZero(sampler, e); err != nil {
 			return res, err
 		}
 	}
@@ -590,6 +612,50 @@ func sampleToxicWaste() (toxicWaste, error) {
 	return res, nil
 }
 
+// sampleNonZero sets e to a non zero element read from sampler.
+func sampleNonZero(sampler io.Reader, e *fr.Element) error {
+	// read 16 more bytes than needed so that the reduction modulo r is statistically uniform
+	var buf [fr.Bytes + 16]byte
+	var v big.Int
+	for e.IsZero() {
+		if _, err := io.ReadFull(sampler, buf[:]); err != nil {
+			return fmt.Errorf("sampling setup secret: %w", err)
+		}
+		e.SetBigInt(v.SetBytes(buf[:]))
+	}
+	return nil
+}
+
+// setupCommitmentKey is equivalent to pedersen.Setup, with the secrets read from sampler.
+func setupCommitmentKey(basis []curve.G1Affine, sampler io.Reader) (pk pedersen.ProvingKey, vk pedersen.VerifyingKey, err error) {
+	var g, sigma fr.Element
+	if err = sampleNonZero(sampler, &g); err != nil {
+		return
+	}
+	if err = sampleNonZero(sampler, &sigma); err != nil {
+		return
+	}
+
+	var b big.Int
+	_, _, _, g2 := curve.Generators()
+	vk.G.ScalarMultiplication(&g2, g.BigInt(&b))
+
+	// [-1/σ]G
+	var sigmaInvNeg fr.Element
+	sigmaInvNeg.Inverse(&sigma).Neg(&sigmaInvNeg)
+	vk.GRootSigmaNeg.ScalarMultiplication(&vk.G, sigmaInvNeg.BigInt(&b))
+
+	pk.Basis = basis
+	pk.BasisExpSigma = make([]curve.G1Affine, len(basis))
+	sigma.BigInt(&b)
+	utils.Parallelize(len(basis), func(start, end int) {
+		for i := start; i < end; i++ {
+			pk.BasisExpSigma[i].ScalarMultiplication(&basis[i], &b)
+		}
+	})
+	return
+}
+
 // DummySetup fills a random ProvingKey
 // used for test or benchmarking purposes
 func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
@@ -624,7 +690,7 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	}
 
 	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	toxicWaste, err := sampleToxicWaste(backend.DefaultSampler())
 	if err != nil {
 		return err
 	}
@@ -743,9 +809,11 @@ func (vk *VerifyingKey) CurveID() ecc.ID {
 	return curve.ID
 }
 
-// NbPublicWitness returns the number of elements in the expected public witness
+// NbPublicWitness returns the number of elements in the expected public witness. The
+// commitment wires follow the public wires in K, but aren't part of the public witness: the
+// verifier computes them from the commitments of the proof.
 func (vk *VerifyingKey) NbPublicWitness() int {
-	return (len(vk.G1.K) - 1)
+	return len(vk.G1.K) - len(vk.CommitmentInfo) - 1
 }
 
 // NbG1 returns the number of G1 elements in the VerifyingKey
@@ -765,6 +833,7 @@ func (pk *ProvingKey) NbG1() int {
 
 // NbG2 returns the number of G2 elements in the ProvingKey
 func (pk *ProvingKey) NbG2() int {
+	_ = pk.waitG2()
 	return 2 + len(pk.G2.B)
 }
 
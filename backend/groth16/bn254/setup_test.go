@@ -0,0 +1,205 @@
+package groth16_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+// seededSampler is a deterministic sampler, for tests only.
+type seededSampler struct {
+	*rand.Rand
+}
+
+func (seededSampler) Name() string {
+	return "test/seeded"
+}
+
+func TestSetupWithSampler(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+
+	pk, vk, err := groth16.Setup(_r1cs, backend.WithSampler(seededSampler{rand.New(rand.NewSource(1))})) //#nosec G404 -- test only
+	assert.NoError(err)
+	assert.Equal("test/seeded", vk.(*groth16_bn254.VerifyingKey).SetupLog.Sampler)
+
+	// the same randomness yields the same keys
+	_, vk2, err := groth16.Setup(_r1cs, backend.WithSampler(seededSampler{rand.New(rand.NewSource(1))})) //#nosec G404 -- test only
+	assert.NoError(err)
+	_vk, _vk2 := vk.(*groth16_bn254.VerifyingKey), vk2.(*groth16_bn254.VerifyingKey)
+	assert.Equal(_vk.G1, _vk2.G1)
+	assert.Equal(_vk.G2.Delta, _vk2.G2.Delta)
+	assert.Equal(_vk.CommitmentKeys, _vk2.CommitmentKeys)
+
+	var buf bytes.Buffer
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+
+	// the setup log survives serialization
+	var decoded groth16_bn254.VerifyingKey
+	_, err = decoded.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal("test/seeded", decoded.SetupLog.Sampler)
+	assert.True(decoded.SetupLog.Time.IsZero(), "the setup time is only recorded on request")
+
+	public, proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+	assert.NoError(groth16.Verify(proof, &decoded, public))
+}
+
+func TestSetupReproducible(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+
+	_, _, err = groth16.Setup(_r1cs, backend.WithReproducible())
+	assert.Error(err, "crypto/rand can't be reproduced")
+
+	// hashes the serialized keys of a reproducible setup
+	setupHash := func() []byte {
+		pk, vk, err := groth16.Setup(_r1cs, backend.WithSampler(seededSampler{rand.New(rand.NewSource(1))}), backend.WithReproducible()) //#nosec G404 -- test only
+		assert.NoError(err)
+		h := sha256.New()
+		_, err = pk.WriteRawTo(h)
+		assert.NoError(err)
+		_, err = vk.WriteTo(h)
+		assert.NoError(err)
+		assert.True(vk.(*groth16_bn254.VerifyingKey).SetupLog.Reproducible)
+		return h.Sum(nil)
+	}
+
+	expected := setupHash()
+	assert.Equal(expected, setupHash())
+
+	// the parallel computations don't depend on the number of threads
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	assert.Equal(expected, setupHash())
+
+	// the setup time is recorded on request, and breaks the reproducibility
+	_, _, err = groth16.Setup(_r1cs, backend.WithSampler(seededSampler{rand.New(rand.NewSource(1))}), backend.WithReproducible(), backend.WithSetupTime()) //#nosec G404 -- test only
+	assert.Error(err)
+	_, vk, err := groth16.Setup(_r1cs, backend.WithSetupTime())
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	var decoded groth16_bn254.VerifyingKey
+	_, err = decoded.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.False(decoded.SetupLog.Time.IsZero())
+	assert.True(vk.(*groth16_bn254.VerifyingKey).SetupLog.Time.Equal(decoded.SetupLog.Time))
+}
+
+func TestCheckDeviceConversion(t *testing.T) {
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(t, err)
+
+	pk, _, err := groth16.Setup(_r1cs)
+	assert.NoError(t, err)
+	assert.NoError(t, pk.(*groth16_bn254.ProvingKey).CheckDeviceConversion(8))
+}
+
+func TestDeviceLayout(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(accel.EnvVar, "cpu") // the CPU emulation supports all the layouts
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(_r1cs)
+	assert.NoError(err)
+	_pk := pk.(*groth16_bn254.ProvingKey)
+
+	projective := accel.PointsConfig{Representation: accel.Projective}
+	withInfinity := accel.PointsConfig{WithInfinity: true}
+	assert.Error(_pk.SetDeviceLayout(groth16_bn254.DeviceLayout{B: withInfinity}), "B in G1 and G2 must agree")
+
+	for _, layout := range []groth16_bn254.DeviceLayout{
+		{A: withInfinity, B: withInfinity, K: withInfinity, G2B: withInfinity},
+		{A: projective, B: projective, K: projective, Z: projective, G2B: projective},
+		{A: withInfinity, B: projective, K: withInfinity, Z: projective},
+		{},
+	} {
+		assert.NoError(_pk.SetDeviceLayout(layout))
+		assert.Equal(layout, _pk.DeviceLayout())
+		assert.NoError(_pk.CheckDeviceConversion(8))
+		public, proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+		assert.NoError(groth16.Verify(proof, vk, public), "layout %+v", layout)
+	}
+}
+
+func TestAccelerator(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(_r1cs)
+	assert.NoError(err)
+	_pk := pk.(*groth16_bn254.ProvingKey)
+
+	// no accelerator is built: the key has no device copies and is proved on the CPU
+	assert.Empty(_pk.Accelerator())
+	assert.Nil(_pk.Device())
+	w, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+	proof, err := groth16.Prove(_r1cs, pk, w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+
+	_, err = groth16.Prove(_r1cs, pk, w, backend.WithAccelerator("unknown"))
+	assert.Error(err, "the proving key isn't on the accelerator")
+
+	assert.Error(_pk.SetAccelerator("unknown"))
+	assert.NoError(_pk.SetAccelerator(cpu.Name))
+	assert.Equal(cpu.Name, _pk.Accelerator())
+	proof, err = groth16.Prove(_r1cs, pk, w, backend.WithAccelerator(cpu.Name))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+}
+
+func TestReadFromDevice(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &oneSecretOnePublicCommittedCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(_r1cs)
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = pk.WriteTo(&buf)
+	assert.NoError(err)
+
+	// the key read is copied to the device at its first proof, on its accelerator
+	var read groth16_bn254.ProvingKey
+	_, err = read.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Nil(read.Device())
+	assert.Empty(read.Accelerator())
+
+	w, err := frontend.NewWitness(&oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = groth16.Prove(_r1cs, &read, w, backend.WithAccelerator("unknown"))
+	assert.Error(err)
+	assert.Nil(read.Device())
+	proof, err := groth16.Prove(_r1cs, &read, w, backend.WithAccelerator(cpu.Name))
+	assert.NoError(err)
+	assert.NotNil(read.Device())
+	assert.Equal(cpu.Name, read.Accelerator())
+	public, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+}
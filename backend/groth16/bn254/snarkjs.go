@@ -0,0 +1,123 @@
+package groth16
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// The snarkjs JSON files hold the numbers as decimal strings, and the points in projective
+// coordinates: [x, y, "1"] in G1 and [[x.A0, x.A1], [y.A0, y.A1], ["1", "0"]] in G2, the point
+// at infinity being ["0", "1", "0"] and [["0", "0"], ["1", "0"], ["0", "0"]]. The curve is
+// named bn128 there.
+
+// ErrSnarkJSCommitments is returned when exporting for snarkjs the key or the proof of a
+// circuit with commitments, which snarkjs doesn't support.
+var ErrSnarkJSCommitments = errors.New("snarkjs doesn't support the commitments")
+
+type snarkJSVerifyingKey struct {
+	Protocol string       `json:"protocol"`
+	Curve    string       `json:"curve"`
+	NPublic  int          `json:"nPublic"`
+	Alpha1   [3]string    `json:"vk_alpha_1"`
+	Beta2    [3][2]string `json:"vk_beta_2"`
+	Gamma2   [3][2]string `json:"vk_gamma_2"`
+	Delta2   [3][2]string `json:"vk_delta_2"`
+	IC       [][3]string  `json:"IC"`
+}
+
+type snarkJSProof struct {
+	A        [3]string    `json:"pi_a"`
+	B        [3][2]string `json:"pi_b"`
+	C        [3]string    `json:"pi_c"`
+	Protocol string       `json:"protocol"`
+	Curve    string       `json:"curve"`
+}
+
+// ExportSnarkJS writes the key in the layout of the verification_key.json of snarkjs, so that
+// the proofs can be verified with snarkjs groth16 verify, or by the verifier contracts it
+// generates. It returns ErrSnarkJSCommitments if the circuit has commitments.
+func (vk *VerifyingKey) ExportSnarkJS(w io.Writer) error {
+	if len(vk.CommitmentInfo) != 0 {
+		return ErrSnarkJSCommitments
+	}
+	res := snarkJSVerifyingKey{
+		Protocol: "groth16",
+		Curve:    "bn128",
+		NPublic:  len(vk.G1.K) - 1,
+		Alpha1:   snarkJSG1(&vk.G1.Alpha),
+		Beta2:    snarkJSG2(&vk.G2.Beta),
+		Gamma2:   snarkJSG2(&vk.G2.Gamma),
+		Delta2:   snarkJSG2(&vk.G2.Delta),
+		IC:       make([][3]string, len(vk.G1.K)),
+	}
+	for i := range vk.G1.K {
+		res.IC[i] = snarkJSG1(&vk.G1.K[i])
+	}
+	return writeSnarkJS(w, &res)
+}
+
+// ExportSnarkJS writes the proof in the layout of the proof.json of snarkjs, see
+// VerifyingKey.ExportSnarkJS and ExportSnarkJSPublic. It returns ErrSnarkJSCommitments if the
+// proof has commitments.
+func (proof *Proof) ExportSnarkJS(w io.Writer) error {
+	if len(proof.Commitments) != 0 {
+		return ErrSnarkJSCommitments
+	}
+	return writeSnarkJS(w, &snarkJSProof{
+		A:        snarkJSG1(&proof.Ar),
+		B:        snarkJSG2(&proof.Bs),
+		C:        snarkJSG1(&proof.Krs),
+		Protocol: "groth16",
+		Curve:    "bn128",
+	})
+}
+
+// ExportSnarkJSPublic writes the public witness, without the constant wire, in the layout of
+// the public.json of snarkjs.
+func ExportSnarkJSPublic(w io.Writer, publicWitness fr.Vector) error {
+	res := make([]string, len(publicWitness))
+	for i := range publicWitness {
+		var b big.Int
+		res[i] = publicWitness[i].BigInt(&b).String()
+	}
+	return writeSnarkJS(w, res)
+}
+
+func snarkJSG1(p *curve.G1Affine) [3]string {
+	if p.IsInfinity() {
+		return [3]string{"0", "1", "0"}
+	}
+	return [3]string{fpString(&p.X), fpString(&p.Y), "1"}
+}
+
+func snarkJSG2(p *curve.G2Affine) [3][2]string {
+	if p.IsInfinity() {
+		return [3][2]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}
+	}
+	return [3][2]string{
+		{fpString(&p.X.A0), fpString(&p.X.A1)},
+		{fpString(&p.Y.A0), fpString(&p.Y.A1)},
+		{"1", "0"},
+	}
+}
+
+func fpString(x *fp.Element) string {
+	var b big.Int
+	return x.BigInt(&b).String()
+}
+
+// writeSnarkJS writes v indented as snarkjs does.
+func writeSnarkJS(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
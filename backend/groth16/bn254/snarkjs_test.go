@@ -0,0 +1,116 @@
+package groth16_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+type snarkJSCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *snarkJSCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.Z)
+	return nil
+}
+
+func TestExportSnarkJS(t *testing.T) {
+	assert := assert.New(t)
+	_r1cs, pk, vk := setup(t, &snarkJSCircuit{})
+	public, proof := prove(t, &snarkJSCircuit{X: 3, Y: 9, Z: 12}, _r1cs, pk)
+
+	var vkJSON, proofJSON, publicJSON bytes.Buffer
+	assert.NoError(vk.(*groth16_bn254.VerifyingKey).ExportSnarkJS(&vkJSON))
+	assert.NoError(proof.(*groth16_bn254.Proof).ExportSnarkJS(&proofJSON))
+	assert.NoError(groth16_bn254.ExportSnarkJSPublic(&publicJSON, public.Vector().(fr.Vector)))
+
+	var vkRead struct {
+		Protocol string     `json:"protocol"`
+		Curve    string     `json:"curve"`
+		NPublic  int        `json:"nPublic"`
+		Alpha1   []string   `json:"vk_alpha_1"`
+		Beta2    [][]string `json:"vk_beta_2"`
+		Gamma2   [][]string `json:"vk_gamma_2"`
+		Delta2   [][]string `json:"vk_delta_2"`
+		IC       [][]string `json:"IC"`
+	}
+	var proofRead struct {
+		A        []string   `json:"pi_a"`
+		B        [][]string `json:"pi_b"`
+		C        []string   `json:"pi_c"`
+		Protocol string     `json:"protocol"`
+	}
+	var publicRead []string
+	assert.NoError(json.Unmarshal(vkJSON.Bytes(), &vkRead))
+	assert.NoError(json.Unmarshal(proofJSON.Bytes(), &proofRead))
+	assert.NoError(json.Unmarshal(publicJSON.Bytes(), &publicRead))
+	assert.Equal("groth16", vkRead.Protocol)
+	assert.Equal("bn128", vkRead.Curve)
+	assert.Equal("groth16", proofRead.Protocol)
+	assert.Equal(2, vkRead.NPublic)
+	assert.Len(vkRead.IC, 3)
+	assert.Equal([]string{"9", "12"}, publicRead)
+
+	// the pairing check of snarkjs: e(-A, B)·e(α, β)·e(IC₀ + Σ xᵢ·ICᵢ, γ)·e(C, δ) == 1
+	var sum curve.G1Jac
+	ic0 := parseG1(t, vkRead.IC[0])
+	sum.FromAffine(&ic0)
+	for i, x := range publicRead {
+		var s big.Int
+		s.SetString(x, 10)
+		ic := parseG1(t, vkRead.IC[i+1])
+		ic.ScalarMultiplication(&ic, &s)
+		sum.AddMixed(&ic)
+	}
+	var vkX curve.G1Affine
+	vkX.FromJacobian(&sum)
+	var negA curve.G1Affine
+	a := parseG1(t, proofRead.A)
+	negA.Neg(&a)
+	ok, err := curve.PairingCheck(
+		[]curve.G1Affine{negA, parseG1(t, vkRead.Alpha1), vkX, parseG1(t, proofRead.C)},
+		[]curve.G2Affine{parseG2(t, proofRead.B), parseG2(t, vkRead.Beta2), parseG2(t, vkRead.Gamma2), parseG2(t, vkRead.Delta2)},
+	)
+	assert.NoError(err)
+	assert.True(ok, "the exported proof doesn't verify")
+}
+
+func TestExportSnarkJSCommitments(t *testing.T) {
+	assert := assert.New(t)
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_, proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+
+	var buf bytes.Buffer
+	assert.ErrorIs(vk.(*groth16_bn254.VerifyingKey).ExportSnarkJS(&buf), groth16_bn254.ErrSnarkJSCommitments)
+	assert.ErrorIs(proof.(*groth16_bn254.Proof).ExportSnarkJS(&buf), groth16_bn254.ErrSnarkJSCommitments)
+}
+
+// parseG1 and parseG2 parse the projective points of snarkjs, with z = 1.
+func parseG1(t *testing.T, p []string) curve.G1Affine {
+	assert.Equal(t, "1", p[2])
+	var res curve.G1Affine
+	res.X.SetString(p[0])
+	res.Y.SetString(p[1])
+	return res
+}
+
+func parseG2(t *testing.T, p [][]string) curve.G2Affine {
+	assert.Equal(t, []string{"1", "0"}, p[2])
+	var res curve.G2Affine
+	res.X.A0.SetString(p[0][0])
+	res.X.A1.SetString(p[0][1])
+	res.Y.A0.SetString(p[1][0])
+	res.Y.A1.SetString(p[1][1])
+	return res
+}
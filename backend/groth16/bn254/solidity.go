@@ -1,11 +1,22 @@
 package groth16
 
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+)
+
 // solidityTemplate based on an audited template https://github.com/appliedzkp/semaphore/blob/master/contracts/sol/verifier.sol
 // audit report https://github.com/appliedzkp/semaphore/blob/master/audit/Audit%20Report%20Summary%20for%20Semaphore%20and%20MicroMix.pdf
 // But some gas cost optimizations have been made.
 // this is an experimental feature and gnark solidity generator as not been thoroughly tested
 const solidityTemplate = `
 {{- $lenK := len .G1.K }}
+{{- $hasCommitment := hasCommitment }}
+{{- $nbInputs := sub $lenK 1 }}
+{{- if $hasCommitment }}{{ $nbInputs = sub $lenK 2 }}{{ end }}
 // SPDX-License-Identifier: AML
 //
 // Copyright 2017 Christian Reitwiessner
@@ -183,6 +194,36 @@ library Pairing {
 
         return out[0] != 0;
     }
+
+    /* @return The result of computing the pairing check
+     *         e(a1, a2) * e(b1, b2) == 1
+     */
+    function pairing2(
+        G1Point memory a1,
+        G2Point memory a2,
+        G1Point memory b1,
+        G2Point memory b2
+    ) internal view returns (bool) {
+
+        uint256[12] memory input = [
+            a1.X, a1.Y, a2.X[0], a2.X[1], a2.Y[0], a2.Y[1],
+            b1.X, b1.Y, b2.X[0], b2.X[1], b2.Y[0], b2.Y[1]
+        ];
+
+        uint256[1] memory out;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 8, input, 0x180, out, 0x20)
+            // Use "invalid" to make gas estimation work
+            switch success case 0 { invalid() }
+        }
+
+        require(success,"pairing-opcode-failed");
+
+        return out[0] != 0;
+    }
 }
 
 contract Verifier {
@@ -204,7 +245,10 @@ contract Verifier {
         Pairing.G1Point A;
         Pairing.G2Point B;
         Pairing.G1Point C;
+        {{- if $hasCommitment }}
         Pairing.G1Point Commit;
+        Pairing.G1Point CommitPok;
+        {{- end }}
     }
 
     function verifyingKey() internal pure returns (VerifyingKey memory vk) {
@@ -213,6 +257,27 @@ contract Verifier {
         vk.gamma2 = Pairing.G2Point([uint256({{.G2.Gamma.X.A1.String}}), uint256({{.G2.Gamma.X.A0.String}})], [uint256({{.G2.Gamma.Y.A1.String}}), uint256({{.G2.Gamma.Y.A0.String}})]);
         vk.delta2 = Pairing.G2Point([uint256({{.G2.Delta.X.A1.String}}), uint256({{.G2.Delta.X.A0.String}})], [uint256({{.G2.Delta.Y.A1.String}}), uint256({{.G2.Delta.Y.A0.String}})]);
     }
+    {{- if $hasCommitment }}
+
+    // Pedersen commitment verifying key, checks the proof of knowledge e(commit, g) * e(pok, gRootSigmaNeg) == 1
+    function commitmentKey() internal pure returns (Pairing.G2Point memory g, Pairing.G2Point memory gRootSigmaNeg) {
+        g = Pairing.G2Point([uint256({{(index .CommitmentKeys 0).G.X.A1.String}}), uint256({{(index .CommitmentKeys 0).G.X.A0.String}})], [uint256({{(index .CommitmentKeys 0).G.Y.A1.String}}), uint256({{(index .CommitmentKeys 0).G.Y.A0.String}})]);
+        gRootSigmaNeg = Pairing.G2Point([uint256({{(index .CommitmentKeys 0).GRootSigmaNeg.X.A1.String}}), uint256({{(index .CommitmentKeys 0).GRootSigmaNeg.X.A0.String}})], [uint256({{(index .CommitmentKeys 0).GRootSigmaNeg.Y.A1.String}}), uint256({{(index .CommitmentKeys 0).GRootSigmaNeg.Y.A0.String}})]);
+    }
+
+    // commitmentWire derives the value of the commitment wire from the commitment and the
+    // public committed inputs. It matches gnark's fr.Hash: hash_to_field with
+    // expand_message_xmd (SHA-256, RFC 9380), 48 bytes per element and dst "{{ commitmentDst }}".
+    function commitmentWire(uint256[2] memory commit, uint256[{{$nbInputs}}] calldata input) internal pure returns (uint256) {
+        bytes memory message = abi.encodePacked(commit[0], commit[1]{{ range publicCommitted }}, input[{{.}}]{{ end }});
+        bytes memory dst = "{{ commitmentDst }}";
+        bytes32 b0 = sha256(abi.encodePacked(bytes32(0), bytes32(0), message, uint16(48), uint8(0), dst, uint8(dst.length)));
+        bytes32 b1 = sha256(abi.encodePacked(b0, uint8(1), dst, uint8(dst.length)));
+        bytes32 b2 = sha256(abi.encodePacked(b0 ^ b1, uint8(2), dst, uint8(dst.length)));
+        // the 48 pseudo random bytes b1 ∥ b2[:16] are reduced modulo the scalar field
+        return addmod(mulmod(uint256(b1), 1 << 128, SNARK_SCALAR_FIELD), uint256(b2) >> 128, SNARK_SCALAR_FIELD);
+    }
+    {{- end }}
 
 
     // accumulate scalarMul(mul_input) into q
@@ -244,15 +309,21 @@ contract Verifier {
         uint256[2] memory a,
         uint256[2][2] memory b,
         uint256[2] memory c,
+        {{- if $hasCommitment }}
         uint256[2] memory commit,
-        uint256[{{sub $lenK 1}}] calldata input
+        uint256[2] memory commitPok,
+        {{- end }}
+        uint256[{{$nbInputs}}] calldata input
     ) public view returns (bool r) {
 
         Proof memory proof;
         proof.A = Pairing.G1Point(a[0], a[1]);
         proof.B = Pairing.G2Point([b[0][0], b[0][1]], [b[1][0], b[1][1]]);
         proof.C = Pairing.G1Point(c[0], c[1]);
+        {{- if $hasCommitment }}
         proof.Commit = Pairing.G1Point(commit[0], commit[1]);
+        proof.CommitPok = Pairing.G1Point(commitPok[0], commitPok[1]);
+        {{- end }}
 
         // Make sure that proof.A, B, and C are each less than the prime q
         require(proof.A.X < PRIME_Q, "verifier-aX-gte-prime-q");
@@ -266,6 +337,13 @@ contract Verifier {
 
         require(proof.C.X < PRIME_Q, "verifier-cX-gte-prime-q");
         require(proof.C.Y < PRIME_Q, "verifier-cY-gte-prime-q");
+        {{- if $hasCommitment }}
+
+        require(proof.Commit.X < PRIME_Q, "verifier-commitX-gte-prime-q");
+        require(proof.Commit.Y < PRIME_Q, "verifier-commitY-gte-prime-q");
+        require(proof.CommitPok.X < PRIME_Q, "verifier-commitPokX-gte-prime-q");
+        require(proof.CommitPok.Y < PRIME_Q, "verifier-commitPokY-gte-prime-q");
+        {{- end }}
 
         // Make sure that every input is less than the snark scalar field
         for (uint256 i = 0; i < input.length; i++) {
@@ -273,6 +351,14 @@ contract Verifier {
         }
 
         VerifyingKey memory vk = verifyingKey();
+        {{- if $hasCommitment }}
+
+        // check the proof of knowledge of the commitment opening
+        {
+            (Pairing.G2Point memory g, Pairing.G2Point memory gRootSigmaNeg) = commitmentKey();
+            require(Pairing.pairing2(proof.Commit, g, proof.CommitPok, gRootSigmaNeg), "verifier-commitment-pok-failed");
+        }
+        {{- end }}
 
         // Compute the linear combination vk_x
         Pairing.G1Point memory vk_x = Pairing.G1Point(0, 0);
@@ -297,7 +383,7 @@ contract Verifier {
             // no public input, vk_x == vk.K[0]
         {{- end}}
         {{- range $i, $ki := .G1.K }}
-            {{- if gt $i 0 -}}
+            {{- if and (gt $i 0) (le $i $nbInputs) -}}
                 {{- $j := sub $i 1 }}
         mul_input[0] = uint256({{$ki.X.String}}); // vk.K[{{$i}}].X
         mul_input[1] = uint256({{$ki.Y.String}}); // vk.K[{{$i}}].Y
@@ -305,9 +391,17 @@ contract Verifier {
         accumulate(mul_input, q, add_input, vk_x); // vk_x += vk.K[{{$i}}] * input[{{$j}}]
             {{- end -}}
         {{- end }}
-        if (commit[0] != 0 || commit[1] != 0) {
-            vk_x = Pairing.plus(vk_x, proof.Commit);
-        }
+        {{- if $hasCommitment }}
+        {{- $kc := index .G1.K (sub $lenK 1) }}
+
+        // the commitment wire is public to the verifier, but derived from the commitment
+        mul_input[0] = uint256({{$kc.X.String}}); // vk.K[{{sub $lenK 1}}].X
+        mul_input[1] = uint256({{$kc.Y.String}}); // vk.K[{{sub $lenK 1}}].Y
+        mul_input[2] = commitmentWire(commit, input);
+        accumulate(mul_input, q, add_input, vk_x); // vk_x += vk.K[{{sub $lenK 1}}] * commitmentWire
+
+        vk_x = Pairing.plus(vk_x, proof.Commit);
+        {{- end }}
 
         return Pairing.pairing(
             Pairing.negate(proof.A),
@@ -322,3 +416,77 @@ contract Verifier {
     }
 }
 `
+
+// MarshalEthereum returns the proof encoded as expected by the alt_bn128 precompiles
+// (EIP-196, EIP-197): 8 32 bytes big-endian words
+//
+//	Ar.X | Ar.Y | Bs.X.A1 | Bs.X.A0 | Bs.Y.A1 | Bs.Y.A0 | Krs.X | Krs.Y
+//
+// The point at infinity is encoded as (0, 0). The commitment, if any, is not part of the encoding.
+func (proof *Proof) MarshalEthereum() []byte {
+	return marshalWords(
+		proof.Ar.X, proof.Ar.Y,
+		proof.Bs.X.A1, proof.Bs.X.A0, proof.Bs.Y.A1, proof.Bs.Y.A0,
+		proof.Krs.X, proof.Krs.Y,
+	)
+}
+
+// UnmarshalEthereum decodes a proof encoded by MarshalEthereum. It returns an error if a
+// coordinate is not reduced modulo the base field modulus or if a point is not on the curve
+// or not in the correct subgroup.
+func (proof *Proof) UnmarshalEthereum(b []byte) error {
+	if len(b) != 8*fp.Bytes {
+		return fmt.Errorf("invalid proof encoding length %d, expected %d", len(b), 8*fp.Bytes)
+	}
+	words := make([]fp.Element, 8)
+	modulus := fp.Modulus()
+	var v big.Int
+	for i := range words {
+		v.SetBytes(b[i*fp.Bytes : (i+1)*fp.Bytes])
+		if v.Cmp(modulus) >= 0 {
+			return fmt.Errorf("word %d is not reduced modulo the base field modulus", i)
+		}
+		words[i].SetBigInt(&v)
+	}
+	var res Proof
+	res.Ar.X, res.Ar.Y = words[0], words[1]
+	res.Bs.X.A1, res.Bs.X.A0, res.Bs.Y.A1, res.Bs.Y.A0 = words[2], words[3], words[4], words[5]
+	res.Krs.X, res.Krs.Y = words[6], words[7]
+
+	if !res.Ar.IsOnCurve() || !res.Bs.IsOnCurve() || !res.Krs.IsOnCurve() {
+		return errors.New("proof points are not on the curve")
+	}
+	if err := res.checkSubgroups(); err != nil {
+		return err
+	}
+
+	proof.Ar, proof.Bs, proof.Krs = res.Ar, res.Bs, res.Krs
+	return nil
+}
+
+// MarshalSolidity returns the proof encoded as the leading arguments of the verifyProof function
+// of the contract generated by VerifyingKey.ExportSolidity: a sequence of 32 bytes big-endian words
+//
+//	a[2] | b[2][2] | c[2] | commit[2] | commitPok[2]
+//
+// where the first 8 words are MarshalEthereum. commit and commitPok are only present if
+// the proof has a commitment. The public inputs must be appended to obtain the full calldata.
+func (proof *Proof) MarshalSolidity() []byte {
+	res := proof.MarshalEthereum()
+	if len(proof.Commitments) != 0 {
+		res = append(res, marshalWords(
+			proof.Commitments[0].X, proof.Commitments[0].Y,
+			proof.CommitmentPoks[0].X, proof.CommitmentPoks[0].Y,
+		)...)
+	}
+	return res
+}
+
+func marshalWords(words ...fp.Element) []byte {
+	res := make([]byte, 0, len(words)*fp.Bytes)
+	for i := range words {
+		b := words[i].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
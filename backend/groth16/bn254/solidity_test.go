@@ -0,0 +1,119 @@
+package groth16_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportSolidityCommitment(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_, proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+
+	var buf bytes.Buffer
+	assert.NoError(vk.ExportSolidity(&buf))
+	contract := buf.String()
+
+	// the commitment wire is derived on chain, only the public input Two is provided
+	assert.Contains(contract, "uint256[1] calldata input")
+	assert.Contains(contract, "uint256[2] memory commitPok")
+	assert.Contains(contract, "abi.encodePacked(commit[0], commit[1], input[0])")
+	assert.Contains(contract, "commitmentWire(commit, input)")
+
+	p := proof.(*groth16_bn254.Proof)
+	calldata := p.MarshalSolidity()
+	assert.Equal(12*fp.Bytes, len(calldata))
+
+	var x fp.Element
+	x.SetBytes(calldata[8*fp.Bytes : 9*fp.Bytes])
+	assert.True(x.Equal(&p.Commitments[0].X))
+	x.SetBytes(calldata[2*fp.Bytes : 3*fp.Bytes])
+	assert.True(x.Equal(&p.Bs.X.A1), "b coordinates must be ordered (A1, A0)")
+}
+
+// TestSolidityCommitmentWire checks that the hash to field performed by the generated
+// contract computes the same commitment wire as the prover.
+func TestSolidityCommitmentWire(t *testing.T) {
+	assert := assert.New(t)
+
+	var commitX, commitY, public fr.Element
+	commitX.SetRandom()
+	commitY.SetRandom()
+	public.SetUint64(2)
+
+	var message []byte
+	for _, e := range []fr.Element{commitX, commitY, public} {
+		b := e.Bytes()
+		message = append(message, b[:]...)
+	}
+	expected, err := fr.Hash(message, []byte(constraint.CommitmentDst), 1)
+	assert.NoError(err)
+
+	// mirror of the contract's commitmentWire
+	dst := []byte(constraint.CommitmentDst)
+	dstPrime := append(dst, byte(len(dst)))
+	h := func(b ...[]byte) []byte {
+		s := sha256.New()
+		for i := range b {
+			s.Write(b[i])
+		}
+		return s.Sum(nil)
+	}
+	b0 := h(make([]byte, 64), message, []byte{0, 48, 0}, dstPrime)
+	b1 := h(b0, []byte{1}, dstPrime)
+	x := make([]byte, len(b0))
+	for i := range x {
+		x[i] = b0[i] ^ b1[i]
+	}
+	b2 := h(x, []byte{2}, dstPrime)
+
+	r := fr.Modulus()
+	v := new(big.Int).SetBytes(b1)
+	v.Lsh(v, 128).Mod(v, r)
+	v.Add(v, new(big.Int).SetBytes(b2[:16])).Mod(v, r)
+
+	var actual fr.Element
+	actual.SetBigInt(v)
+	assert.True(actual.Equal(&expected[0]))
+}
+
+func TestMarshalEthereum(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &noCommitmentCircuit{})
+	public, proof := prove(t, &noCommitmentCircuit{One: 1}, _r1cs, pk)
+
+	p := proof.(*groth16_bn254.Proof)
+	b := p.MarshalEthereum()
+	assert.Equal(8*fp.Bytes, len(b))
+
+	var decoded groth16_bn254.Proof
+	assert.NoError(decoded.UnmarshalEthereum(b))
+	assert.True(decoded.Ar.Equal(&p.Ar))
+	assert.True(decoded.Bs.Equal(&p.Bs))
+	assert.True(decoded.Krs.Equal(&p.Krs))
+	assert.NoError(groth16.Verify(&decoded, vk, public))
+
+	// non canonical coordinate
+	modulus := fp.Modulus().Bytes()
+	invalid := append([]byte{}, b...)
+	copy(invalid[fp.Bytes-len(modulus):fp.Bytes], modulus)
+	assert.Error(decoded.UnmarshalEthereum(invalid))
+
+	// point not on the curve
+	invalid = append([]byte{}, b...)
+	invalid[2*fp.Bytes-1] ^= 1
+	assert.Error(decoded.UnmarshalEthereum(invalid))
+
+	assert.Error(decoded.UnmarshalEthereum(b[1:]))
+}
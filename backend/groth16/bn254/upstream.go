@@ -0,0 +1,254 @@
+package groth16
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint"
+)
+
+// The upstream encodings are the ones of consensys/gnark v0.8, the version this fork is based
+// on, which supports at most one commitment per circuit: the keys and the proofs have a single
+// commitment key and commitment, set to zero if the circuit has none, and the verifying key
+// has the commitment info of a single constraint.Commitment. The other curves use the
+// upstream encodings as they are.
+
+// ErrUpstreamCommitments is returned when encoding for upstream gnark the key or the proof of a
+// circuit with several commitments.
+var ErrUpstreamCommitments = errors.New("upstream gnark supports at most one commitment per circuit")
+
+// WriteUpstreamTo writes the proof in the encoding of upstream gnark, with the points
+// compressed: Ar | Bs | Krs | Commitment | CommitmentPok.
+func (proof *Proof) WriteUpstreamTo(w io.Writer) (int64, error) {
+	return proof.writeUpstreamTo(w, false)
+}
+
+// WriteUpstreamRawTo writes the proof in the encoding of upstream gnark, with the points
+// uncompressed.
+func (proof *Proof) WriteUpstreamRawTo(w io.Writer) (int64, error) {
+	return proof.writeUpstreamTo(w, true)
+}
+
+func (proof *Proof) writeUpstreamTo(w io.Writer, raw bool) (int64, error) {
+	if len(proof.Commitments) > 1 {
+		return 0, ErrUpstreamCommitments
+	}
+	var commitment, commitmentPok curve.G1Affine
+	if len(proof.Commitments) == 1 {
+		commitment, commitmentPok = proof.Commitments[0], proof.CommitmentPoks[0]
+	}
+
+	enc := newEncoder(w, raw)
+	for _, v := range []interface{}{&proof.Ar, &proof.Bs, &proof.Krs, &commitment, &commitmentPok} {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadUpstreamFrom reads a proof written by upstream gnark, compressed or not. The proofs
+// written by the versions encoding the commitment but not its proof of knowledge are read
+// with a zero proof of knowledge.
+func (proof *Proof) ReadUpstreamFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	for _, v := range []interface{}{&proof.Ar, &proof.Bs, &proof.Krs} {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	var commitment, commitmentPok curve.G1Affine
+	if err := dec.Decode(&commitment); err != nil && !errors.Is(err, io.EOF) {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&commitmentPok); err != nil && !errors.Is(err, io.EOF) {
+		return dec.BytesRead(), err
+	}
+	proof.Commitments, proof.CommitmentPoks = nil, nil
+	if !commitment.IsInfinity() {
+		proof.Commitments = []curve.G1Affine{commitment}
+		proof.CommitmentPoks = []curve.G1Affine{commitmentPok}
+	}
+	return dec.BytesRead(), nil
+}
+
+// WriteUpstreamTo writes the key in the encoding of upstream gnark, with the points
+// compressed: [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1,[G]2,[GRootSigmaNeg]2
+// and the JSON of the commitment info. The setup log isn't written.
+func (vk *VerifyingKey) WriteUpstreamTo(w io.Writer) (int64, error) {
+	return vk.writeUpstreamTo(w, false)
+}
+
+// WriteUpstreamRawTo writes the key in the encoding of upstream gnark, with the points
+// uncompressed.
+func (vk *VerifyingKey) WriteUpstreamRawTo(w io.Writer) (int64, error) {
+	return vk.writeUpstreamTo(w, true)
+}
+
+func (vk *VerifyingKey) writeUpstreamTo(w io.Writer, raw bool) (int64, error) {
+	if len(vk.CommitmentKeys) > 1 || len(vk.CommitmentInfo) > 1 {
+		return 0, ErrUpstreamCommitments
+	}
+	var commitmentKey pedersen.VerifyingKey
+	if len(vk.CommitmentKeys) == 1 {
+		commitmentKey = vk.CommitmentKeys[0]
+	}
+	var commitmentInfo constraint.Commitment
+	if len(vk.CommitmentInfo) == 1 {
+		commitmentInfo = vk.CommitmentInfo[0]
+	}
+
+	enc := newEncoder(w, raw)
+	for _, v := range []interface{}{
+		&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta,
+		vk.G1.K,
+		&commitmentKey.G, &commitmentKey.GRootSigmaNeg,
+	} {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	b, err := json.Marshal(commitmentInfo)
+	if err != nil {
+		return enc.BytesWritten(), err
+	}
+	n, err := w.Write(b)
+	return enc.BytesWritten() + int64(n), err
+}
+
+// ReadUpstreamFrom reads a verifying key written by upstream gnark, compressed or not.
+func (vk *VerifyingKey) ReadUpstreamFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	var commitmentKey pedersen.VerifyingKey
+	for _, v := range []interface{}{
+		&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta,
+		&vk.G1.K,
+		&commitmentKey.G, &commitmentKey.GRootSigmaNeg,
+	} {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return dec.BytesRead(), err
+	}
+	var commitmentInfo constraint.Commitment
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&commitmentInfo); err != nil {
+		return dec.BytesRead() + int64(len(b)), err
+	}
+	vk.CommitmentKeys, vk.CommitmentInfo = nil, nil
+	if commitmentInfo.Is() {
+		vk.CommitmentKeys = []pedersen.VerifyingKey{commitmentKey}
+		vk.CommitmentInfo = constraint.Commitments{commitmentInfo}
+	}
+	vk.SetupLog = backend.SetupLog{}
+	vk.Features = nil
+	vk.Hashes = KeyHashes{}
+
+	if err := vk.Precompute(); err != nil {
+		return dec.BytesRead() + int64(len(b)), err
+	}
+	return dec.BytesRead() + int64(len(b)), nil
+}
+
+// WriteUpstreamTo writes the key in the encoding of upstream gnark, with the points
+// compressed: the domain, the points of the key and the infinity flags as WriteTo, then the
+// basis of the commitment key and its exponentiation by σ.
+func (pk *ProvingKey) WriteUpstreamTo(w io.Writer) (int64, error) {
+	return pk.writeUpstreamTo(w, false)
+}
+
+// WriteUpstreamRawTo writes the key in the encoding of upstream gnark, with the points
+// uncompressed.
+func (pk *ProvingKey) WriteUpstreamRawTo(w io.Writer) (int64, error) {
+	return pk.writeUpstreamTo(w, true)
+}
+
+func (pk *ProvingKey) writeUpstreamTo(w io.Writer, raw bool) (int64, error) {
+	if err := pk.waitG2(); err != nil {
+		return 0, err
+	}
+	if len(pk.CommitmentKeys) > 1 {
+		return 0, ErrUpstreamCommitments
+	}
+	var commitmentKey pedersen.ProvingKey
+	if len(pk.CommitmentKeys) == 1 {
+		commitmentKey = pk.CommitmentKeys[0]
+	}
+
+	n, err := pk.Domain.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+	enc := newEncoder(w, raw)
+	for _, v := range []interface{}{
+		&pk.G1.Alpha, &pk.G1.Beta, &pk.G1.Delta,
+		pk.G1.A, pk.G1.B, pk.G1.Z, pk.G1.K,
+		&pk.G2.Beta, &pk.G2.Delta, pk.G2.B,
+		uint64(len(pk.InfinityA)), pk.NbInfinityA, pk.NbInfinityB, pk.InfinityA, pk.InfinityB,
+		commitmentKey.Basis, commitmentKey.BasisExpSigma,
+	} {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+	return n + enc.BytesWritten(), nil
+}
+
+// ReadUpstreamFrom reads a proving key written by upstream gnark, compressed or not. As for
+// ReadFrom, the key is copied to the device at its first proof.
+func (pk *ProvingKey) ReadUpstreamFrom(r io.Reader) (int64, error) {
+	_ = pk.waitG2()
+	pk.g2 = nil
+	pk.releaseDevice()
+
+	n, err := pk.Domain.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	dec := curve.NewDecoder(r)
+	var nbWires uint64
+	for _, v := range []interface{}{
+		&pk.G1.Alpha, &pk.G1.Beta, &pk.G1.Delta,
+		&pk.G1.A, &pk.G1.B, &pk.G1.Z, &pk.G1.K,
+		&pk.G2.Beta, &pk.G2.Delta, &pk.G2.B,
+		&nbWires, &pk.NbInfinityA, &pk.NbInfinityB,
+	} {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+
+	var commitmentKey pedersen.ProvingKey
+	for _, v := range []interface{}{&pk.InfinityA, &pk.InfinityB, &commitmentKey.Basis, &commitmentKey.BasisExpSigma} {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	pk.CommitmentKeys = nil
+	if len(commitmentKey.Basis) != 0 {
+		pk.CommitmentKeys = []pedersen.ProvingKey{commitmentKey}
+	}
+	pk.Hashes = KeyHashes{}
+
+	return n + dec.BytesRead(), nil
+}
+
+func newEncoder(w io.Writer, raw bool) *curve.Encoder {
+	if raw {
+		return curve.NewEncoder(w, curve.RawEncoding())
+	}
+	return curve.NewEncoder(w)
+}
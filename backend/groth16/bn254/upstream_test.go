@@ -0,0 +1,100 @@
+package groth16_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamEncoding(t *testing.T) {
+	for name, circuits := range map[string][2]frontend.Circuit{
+		"commitment":    {&oneSecretOnePublicCommittedCircuit{}, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}},
+		"no commitment": {&noCommitmentCircuit{}, &noCommitmentCircuit{One: 1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			_r1cs, pk, vk := setup(t, circuits[0])
+			public, proof := prove(t, circuits[1], _r1cs, pk)
+
+			for _, raw := range []bool{false, true} {
+				// the keys and the proof read back from the upstream encoding prove and verify
+				var pkRead groth16_bn254.ProvingKey
+				var vkRead groth16_bn254.VerifyingKey
+				var proofRead groth16_bn254.Proof
+				roundTripUpstream(t, pk.(*groth16_bn254.ProvingKey), &pkRead, raw)
+				roundTripUpstream(t, vk.(*groth16_bn254.VerifyingKey), &vkRead, raw)
+				roundTripUpstream(t, proof.(*groth16_bn254.Proof), &proofRead, raw)
+
+				assert.Equal(proof, &proofRead)
+				assert.Equal(vk.(*groth16_bn254.VerifyingKey).CommitmentInfo, vkRead.CommitmentInfo)
+				assert.NoError(groth16.Verify(&proofRead, &vkRead, public))
+				_, proof2 := prove(t, circuits[1], _r1cs, &pkRead)
+				assert.NoError(groth16.Verify(proof2, vk, public))
+			}
+		})
+	}
+}
+
+func TestUpstreamProofLayout(t *testing.T) {
+	assert := assert.New(t)
+	_r1cs, pk, _ := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_, proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+	p := proof.(*groth16_bn254.Proof)
+
+	// Ar | Bs | Krs | Commitment | CommitmentPok, compressed
+	var buf bytes.Buffer
+	_, err := p.WriteUpstreamTo(&buf)
+	assert.NoError(err)
+	assert.Equal(4*curve.SizeOfG1AffineCompressed+curve.SizeOfG2AffineCompressed, buf.Len())
+
+	// the upstream versions which don't encode the proof of knowledge end with the commitment
+	truncated := buf.Bytes()[:buf.Len()-curve.SizeOfG1AffineCompressed]
+	var read groth16_bn254.Proof
+	_, err = read.ReadUpstreamFrom(bytes.NewReader(truncated))
+	assert.NoError(err)
+	assert.Equal(p.Commitments, read.Commitments)
+	assert.True(read.CommitmentPoks[0].IsInfinity())
+}
+
+func TestUpstreamSeveralCommitments(t *testing.T) {
+	assert := assert.New(t)
+	_r1cs, pk, vk := setup(t, &twoCommitmentsCircuit{})
+	_, proof := prove(t, &twoCommitmentsCircuit{One: 1, Two: 2, Three: 3}, _r1cs, pk)
+
+	var buf bytes.Buffer
+	_, err := pk.(*groth16_bn254.ProvingKey).WriteUpstreamTo(&buf)
+	assert.ErrorIs(err, groth16_bn254.ErrUpstreamCommitments)
+	_, err = vk.(*groth16_bn254.VerifyingKey).WriteUpstreamTo(&buf)
+	assert.ErrorIs(err, groth16_bn254.ErrUpstreamCommitments)
+	_, err = proof.(*groth16_bn254.Proof).WriteUpstreamTo(&buf)
+	assert.ErrorIs(err, groth16_bn254.ErrUpstreamCommitments)
+}
+
+// upstreamEncoder is implemented by the keys and the proof.
+type upstreamEncoder interface {
+	WriteUpstreamTo(w io.Writer) (int64, error)
+	WriteUpstreamRawTo(w io.Writer) (int64, error)
+	ReadUpstreamFrom(r io.Reader) (int64, error)
+}
+
+func roundTripUpstream(t *testing.T, from, to upstreamEncoder, raw bool) {
+	var buf bytes.Buffer
+	var written int64
+	var err error
+	if raw {
+		written, err = from.WriteUpstreamRawTo(&buf)
+	} else {
+		written, err = from.WriteUpstreamTo(&buf)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+	read, err := to.ReadUpstreamFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, written, read)
+}
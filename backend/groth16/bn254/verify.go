@@ -12,16 +12,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Code generated by gnark DO NOT EDIT
-
 package groth16
 
 import (
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/logger"
 	"io"
 	"math/big"
@@ -29,27 +31,53 @@ import (
 	"time"
 )
 
+// The errors of the verifiers, wrapped with the details of the failed check: errors.Is tells
+// which check failed.
 var (
-	errPairingCheckFailed         = errors.New("pairing doesn't match")
-	errCorrectSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
-)
+	// ErrSubgroupCheck is returned when a point of the proof isn't in the prime order subgroup
+	// of its group, which includes the points not on the curve.
+	ErrSubgroupCheck = errors.New("points in the proof are not in the correct subgroup")
 
-// Verify verifies a proof with given VerifyingKey and publicWitness
-func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector) error {
+	// ErrCommitmentPok is returned when the proof of knowledge of a commitment of the proof
+	// doesn't verify with the commitment key of the verifying key.
+	ErrCommitmentPok = errors.New("commitment proof of knowledge rejected")
 
-	nbPublicVars := len(vk.G1.K)
-	if vk.CommitmentInfo.Is() {
-		nbPublicVars--
+	// ErrPairingCheck is returned when the pairing equation of Groth16 doesn't hold: the proof
+	// isn't a proof of the statement for the verifying key.
+	ErrPairingCheck = errors.New("pairing doesn't match")
+
+	// ErrNonCanonical is returned in strict mode (see backend.WithStrictVerification) when a
+	// point of the proof isn't canonical, and by Proof.ReadCanonicalFrom.
+	ErrNonCanonical = errors.New("non-canonical proof")
+)
+
+// Verify verifies a proof with given VerifyingKey and publicWitness. The errors of the failed
+// checks wrap ErrSubgroupCheck, ErrCommitmentPok or ErrPairingCheck, and ErrNonCanonical with
+// backend.WithStrictVerification.
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...backend.VerifierOption) error {
+	cfg, err := backend.NewVerifierConfig(opts...)
+	if err != nil {
+		return err
 	}
+
+	nbPublicVars := len(vk.G1.K) - len(vk.CommitmentInfo)
 	if len(publicWitness) != nbPublicVars-1 {
-		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), len(vk.G1.K)-1)
+		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), nbPublicVars-1)
+	}
+	if len(proof.Commitments) != len(vk.CommitmentInfo) || len(proof.CommitmentPoks) != len(vk.CommitmentInfo) {
+		return fmt.Errorf("invalid number of commitments, got %d, expected %d", len(proof.Commitments), len(vk.CommitmentInfo))
 	}
 	log := logger.Logger().With().Str("curve", vk.CurveID().String()).Str("backend", "groth16").Logger()
 	start := time.Now()
 
+	if cfg.Strict {
+		if err := proof.checkCanonical(); err != nil {
+			return err
+		}
+	}
 	// check that the points in the proof are in the correct subgroup
-	if !proof.isValid() {
-		return errCorrectSubgroupCheckFailed
+	if err := proof.checkSubgroups(); err != nil {
+		return err
 	}
 
 	var doubleML curve.GT
@@ -63,22 +91,27 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector) error {
 		close(chDone)
 	}()
 
-	if vk.CommitmentInfo.Is() {
+	// the commitment wires follow the public wires in vk.G1.K
+	publicWitness = publicWitness[:len(publicWitness):len(publicWitness)]
+	for j := range vk.CommitmentInfo {
+		commitmentInfo := &vk.CommitmentInfo[j]
 
-		if err := vk.CommitmentKey.Verify(proof.Commitment, proof.CommitmentPok); err != nil {
-			return err
+		if err := vk.CommitmentKeys[j].Verify(proof.Commitments[j], proof.CommitmentPoks[j]); err != nil {
+			return fmt.Errorf("%w: commitment %d: %v", ErrCommitmentPok, j, err)
 		}
 
-		publicCommitted := make([]*big.Int, vk.CommitmentInfo.NbPublicCommitted())
+		publicCommitted := make([]*big.Int, commitmentInfo.NbPublicCommitted())
 		for i := range publicCommitted {
 			var b big.Int
-			publicWitness[vk.CommitmentInfo.Committed[i]-1].BigInt(&b)
+			publicWitness[commitmentInfo.Committed[i]-1].BigInt(&b)
 			publicCommitted[i] = &b
 		}
 
-		if res, err := solveCommitmentWire(&vk.CommitmentInfo, &proof.Commitment, publicCommitted); err == nil {
-			publicWitness = append(publicWitness, res)
+		res, err := solveCommitmentWire(commitmentInfo, &proof.Commitments[j], publicCommitted)
+		if err != nil {
+			return err
 		}
+		publicWitness = append(publicWitness, res)
 	}
 
 	// compute e(Σx.[Kvk(t)]1, -[γ]2)
@@ -88,8 +121,8 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector) error {
 	}
 	kSum.AddMixed(&vk.G1.K[0])
 
-	if vk.CommitmentInfo.Is() {
-		kSum.AddMixed(&proof.Commitment)
+	for j := range proof.Commitments {
+		kSum.AddMixed(&proof.Commitments[j])
 	}
 
 	var kSumAff curve.G1Affine
@@ -106,25 +139,128 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector) error {
 	}
 
 	right = curve.FinalExponentiation(&right, &doubleML)
-	if !vk.e.Equal(&right) {
-		return errPairingCheckFailed
+	if !equalGT(&vk.e, &right) {
+		return ErrPairingCheck
 	}
 
 	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
 	return nil
 }
 
+// checkSubgroups returns an error wrapping ErrSubgroupCheck, naming the first point of the proof
+// not in the correct subgroup, if any.
+func (proof *Proof) checkSubgroups() error {
+	switch {
+	case !proof.Ar.IsInSubGroup():
+		return fmt.Errorf("%w: Ar", ErrSubgroupCheck)
+	case !proof.Bs.IsInSubGroup():
+		return fmt.Errorf("%w: Bs", ErrSubgroupCheck)
+	case !proof.Krs.IsInSubGroup():
+		return fmt.Errorf("%w: Krs", ErrSubgroupCheck)
+	}
+	for i := range proof.Commitments {
+		if !proof.Commitments[i].IsInSubGroup() {
+			return fmt.Errorf("%w: commitment %d", ErrSubgroupCheck, i)
+		}
+	}
+	for i := range proof.CommitmentPoks {
+		if !proof.CommitmentPoks[i].IsInSubGroup() {
+			return fmt.Errorf("%w: proof of knowledge of commitment %d", ErrSubgroupCheck, i)
+		}
+	}
+	return nil
+}
+
+// checkCanonical returns an error wrapping ErrNonCanonical if a coordinate of a point of the
+// proof isn't reduced modulo the base field modulus, which the decoders never return but the
+// proofs built from raw memory may have, or if Ar, Bs, Krs or a commitment is the point at
+// infinity, which the provers only return with a negligible probability.
+func (proof *Proof) checkCanonical() error {
+	if err := checkCanonicalG1(&proof.Ar, "Ar"); err != nil {
+		return err
+	}
+	if err := checkCanonicalG1(&proof.Krs, "Krs"); err != nil {
+		return err
+	}
+	bs := &proof.Bs
+	if !isReduced(&bs.X.A0) || !isReduced(&bs.X.A1) || !isReduced(&bs.Y.A0) || !isReduced(&bs.Y.A1) {
+		return fmt.Errorf("%w: Bs has unreduced coordinates", ErrNonCanonical)
+	}
+	if bs.IsInfinity() {
+		return fmt.Errorf("%w: Bs is the point at infinity", ErrNonCanonical)
+	}
+	for i := range proof.Commitments {
+		if err := checkCanonicalG1(&proof.Commitments[i], fmt.Sprintf("commitment %d", i)); err != nil {
+			return err
+		}
+		pok := &proof.CommitmentPoks[i]
+		if !isReduced(&pok.X) || !isReduced(&pok.Y) {
+			return fmt.Errorf("%w: proof of knowledge of commitment %d has unreduced coordinates", ErrNonCanonical, i)
+		}
+	}
+	return nil
+}
+
+func checkCanonicalG1(p *curve.G1Affine, name string) error {
+	if !isReduced(&p.X) || !isReduced(&p.Y) {
+		return fmt.Errorf("%w: %s has unreduced coordinates", ErrNonCanonical, name)
+	}
+	if p.IsInfinity() {
+		return fmt.Errorf("%w: %s is the point at infinity", ErrNonCanonical, name)
+	}
+	return nil
+}
+
+// isReduced returns true if the Montgomery form of x is smaller than the modulus. The
+// Montgomery multiplication by 1 reduces any value smaller than 2²⁵⁶.
+func isReduced(x *fp.Element) bool {
+	var one, reduced fp.Element
+	one.SetOne()
+	reduced.Mul(x, &one)
+	return reduced == *x
+}
+
+// equalGT compares a and b in constant time.
+func equalGT(a, b *curve.GT) bool {
+	ab, bb := a.Bytes(), b.Bytes()
+	return subtle.ConstantTimeCompare(ab[:], bb[:]) == 1
+}
+
 // ExportSolidity writes a solidity Verifier contract on provided writer
 // while this uses an audited template https://github.com/appliedzkp/semaphore/blob/master/contracts/sol/verifier.sol
 // audit report https://github.com/appliedzkp/semaphore/blob/master/audit/Audit%20Report%20Summary%20for%20Semaphore%20and%20MicroMix.pdf
 // this is an experimental feature and gnark solidity generator as not been thoroughly tested.
 //
+// If the circuit has a commitment, verifyProof additionally takes the commitment and its
+// proof of knowledge; the contract checks the proof of knowledge and derives the commitment
+// wire from the commitment and the public committed inputs, so input only holds the public
+// inputs of the circuit. Use Proof.MarshalSolidity to produce the matching calldata. Circuits
+// with several commitments are not supported.
+//
 // See https://github.com/ConsenSys/gnark-tests for example usage.
 func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+	commitmentInfo, err := vk.CommitmentInfo.Single()
+	if err != nil {
+		return err
+	}
 	helpers := template.FuncMap{
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		"hasCommitment": func() bool {
+			return commitmentInfo.Is()
+		},
+		"commitmentDst": func() string {
+			return constraint.CommitmentDst
+		},
+		// indexes in the input array of the public wires the commitment is made to
+		"publicCommitted": func() []int {
+			res := make([]int, commitmentInfo.NbPublicCommitted())
+			for i := range res {
+				res[i] = commitmentInfo.Committed[i] - 1
+			}
+			return res
+		},
 	}
 
 	tmpl, err := template.New("").Funcs(helpers).Parse(solidityTemplate)
@@ -0,0 +1,116 @@
+package groth16
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// FixedInputsVerifier verifies the proofs for a VerifyingKey whose public inputs are partly
+// fixed, for instance a chain ID or the hash of a verifying key. The part of the public input
+// MSM of the fixed inputs is computed once, by FixPublicInputs, so that each verification only
+// multiplies the other inputs, and the commitment wires.
+type FixedInputsVerifier struct {
+	vk *VerifyingKey
+
+	// the indexes in the public witness of the fixed inputs, and their values
+	fixed  []int
+	values fr.Vector
+
+	// the indexes of the other inputs in the public witness augmented with the commitment
+	// wires, and their points
+	free  []int
+	freeK []curve.G1Affine
+
+	// [Kvk(0)]1 + Σ xᵢ[Kvk(i)]1 for the fixed inputs xᵢ
+	partial curve.G1Affine
+}
+
+// FixPublicInputs returns a verifier of the proofs whose public inputs of the indexes, in the
+// public witness, have the values.
+func (vk *VerifyingKey) FixPublicInputs(indexes []int, values fr.Vector) (*FixedInputsVerifier, error) {
+	if len(indexes) != len(values) {
+		return nil, fmt.Errorf("got %d indexes but %d values", len(indexes), len(values))
+	}
+	nbPublic := len(vk.G1.K) - len(vk.CommitmentInfo) - 1
+	isFixed := make([]bool, len(vk.G1.K)-1)
+	for _, i := range indexes {
+		if i < 0 || i >= nbPublic {
+			return nil, fmt.Errorf("invalid public input index %d, the witness has %d public inputs", i, nbPublic)
+		}
+		if isFixed[i] {
+			return nil, fmt.Errorf("public input %d fixed twice", i)
+		}
+		isFixed[i] = true
+	}
+
+	v := &FixedInputsVerifier{
+		vk:     vk,
+		fixed:  append([]int(nil), indexes...),
+		values: append(fr.Vector(nil), values...),
+	}
+	for i := range isFixed {
+		if !isFixed[i] {
+			v.free = append(v.free, i)
+			v.freeK = append(v.freeK, vk.G1.K[i+1])
+		}
+	}
+
+	var partial curve.G1Jac
+	if len(indexes) != 0 {
+		fixedK := make([]curve.G1Affine, len(indexes))
+		for j, i := range indexes {
+			fixedK[j] = vk.G1.K[i+1]
+		}
+		if _, err := partial.MultiExp(fixedK, v.values, ecc.MultiExpConfig{}); err != nil {
+			return nil, err
+		}
+	}
+	partial.AddMixed(&vk.G1.K[0])
+	v.partial.FromJacobian(&partial)
+	return v, nil
+}
+
+// Verify verifies the proof for the public witness like Verify. The public witness is complete:
+// its fixed inputs must have their fixed values.
+func (v *FixedInputsVerifier) Verify(proof *Proof, publicWitness fr.Vector) error {
+	vk := v.vk
+	public, err := vk.preparePublicWitness(proof, publicWitness)
+	if err != nil {
+		return err
+	}
+	for j, i := range v.fixed {
+		if !public[i].Equal(&v.values[j]) {
+			return fmt.Errorf("public input %d is %s, fixed to %s", i, public[i].String(), v.values[j].String())
+		}
+	}
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2), the fixed inputs being in the partial sum
+	scalars := make([]fr.Element, len(v.free))
+	for j, i := range v.free {
+		scalars[j] = public[i]
+	}
+	var kSum curve.G1Jac
+	if len(scalars) != 0 {
+		if _, err := kSum.MultiExp(v.freeK, scalars, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+	}
+	kSum.AddMixed(&v.partial)
+	for j := range proof.Commitments {
+		kSum.AddMixed(&proof.Commitments[j])
+	}
+	var kSumAff curve.G1Affine
+	kSumAff.FromJacobian(&kSum)
+
+	ml, err := curve.MillerLoop([]curve.G1Affine{proof.Krs, proof.Ar, kSumAff}, []curve.G2Affine{vk.G2.deltaNeg, proof.Bs, vk.G2.gammaNeg})
+	if err != nil {
+		return err
+	}
+	if res := curve.FinalExponentiation(&ml); !equalGT(&vk.e, &res) {
+		return ErrPairingCheck
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package groth16_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// chainCircuit has constant public inputs, the chain ID and the version, and a varying one
+// committed to with a secret.
+type chainCircuit struct {
+	ChainID frontend.Variable `gnark:",public"`
+	Nonce   frontend.Variable `gnark:",public"`
+	Version frontend.Variable `gnark:",public"`
+	Secret  frontend.Variable
+}
+
+func (c *chainCircuit) Define(api frontend.API) error {
+	commitment, err := api.(frontend.Committer).Commit(c.Nonce, c.Secret)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, 0)
+	api.AssertIsEqual(api.Mul(c.Secret, c.ChainID, c.Version), c.Nonce)
+	return nil
+}
+
+func TestFixPublicInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &chainCircuit{})
+	_vk := vk.(*groth16_bn254.VerifyingKey)
+
+	var chainID, version fr.Element
+	chainID.SetUint64(137)
+	version.SetUint64(2)
+	verifier, err := _vk.FixPublicInputs([]int{0, 2}, fr.Vector{chainID, version})
+	assert.NoError(err)
+	all, err := _vk.FixPublicInputs(nil, nil)
+	assert.NoError(err)
+
+	for _, secret := range []uint64{3, 5} {
+		public, proof := prove(t, &chainCircuit{ChainID: 137, Nonce: 137 * 2 * secret, Version: 2, Secret: secret}, _r1cs, pk)
+		p, w := proof.(*groth16_bn254.Proof), public.Vector().(fr.Vector)
+		assert.NoError(verifier.Verify(p, w))
+		assert.NoError(all.Verify(p, w))
+
+		// the varying input is checked by the pairing
+		w[1].SetUint64(7)
+		assert.Error(verifier.Verify(p, w))
+	}
+
+	// a proof for other fixed inputs is rejected
+	public, proof := prove(t, &chainCircuit{ChainID: 1, Nonce: 6, Version: 2, Secret: 3}, _r1cs, pk)
+	assert.NoError(groth16_bn254.Verify(proof.(*groth16_bn254.Proof), _vk, public.Vector().(fr.Vector)))
+	assert.Error(verifier.Verify(proof.(*groth16_bn254.Proof), public.Vector().(fr.Vector)))
+
+	_, err = _vk.FixPublicInputs([]int{3}, fr.Vector{chainID})
+	assert.Error(err, "the commitment wire isn't an input")
+	_, err = _vk.FixPublicInputs([]int{0, 0}, fr.Vector{chainID, chainID})
+	assert.Error(err)
+}
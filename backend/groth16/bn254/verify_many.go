@@ -0,0 +1,245 @@
+package groth16
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+)
+
+// preparedProof holds the proof elements and the public witness augmented with the
+// commitment wire, as expected by the pairing check.
+type preparedProof struct {
+	proof  *Proof
+	public fr.Vector
+}
+
+// VerifyMany verifies a batch of proofs against the same VerifyingKey.
+//
+// The pairing equations of all proofs are folded into a single multi-Miller loop using
+// random linear combinations: the [δ]2, [γ]2 and [β]2 pairings and the public input MSM
+// are computed once for the whole batch instead of once per proof. If the folded check
+// fails, the batch is bisected to isolate the invalid proofs.
+//
+// It returns a bitmap where bit i is set iff proofs[i] is valid for publicWitnesses[i],
+// and the aggregate time spent verifying; nil proofs are invalid. An error is returned only if
+// the inputs are malformed (e.g. len(proofs) != len(publicWitnesses)).
+func VerifyMany(vk *VerifyingKey, proofs []*Proof, publicWitnesses []fr.Vector) (*bitset.BitSet, time.Duration, error) {
+	if len(proofs) != len(publicWitnesses) {
+		return nil, 0, fmt.Errorf("got %d proofs but %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+	log := logger.Logger().With().Str("curve", vk.CurveID().String()).Str("backend", "groth16").Int("nbProofs", len(proofs)).Logger()
+	start := time.Now()
+
+	valid := bitset.New(uint(len(proofs)))
+
+	// per proof checks: subgroup membership, commitment proof of knowledge and
+	// computation of the commitment wire. proofs failing there are marked invalid
+	// and excluded from the batch.
+	prepared := make([]preparedProof, len(proofs))
+	ok := make([]bool, len(proofs))
+	utils.Parallelize(len(proofs), func(start, end int) {
+		for i := start; i < end; i++ {
+			if proofs[i] == nil {
+				continue
+			}
+			public, err := vk.preparePublicWitness(proofs[i], publicWitnesses[i])
+			if err != nil {
+				continue
+			}
+			prepared[i] = preparedProof{proof: proofs[i], public: public}
+			ok[i] = true
+		}
+	})
+
+	batch := make([]int, 0, len(proofs))
+	for i := range ok {
+		if ok[i] {
+			batch = append(batch, i)
+		}
+	}
+
+	if err := vk.verifyManyBisect(prepared, batch, valid); err != nil {
+		return nil, 0, err
+	}
+
+	took := time.Since(start)
+	log.Debug().Dur("took", took).Uint("nbValid", valid.Count()).Msg("batch verifier done")
+	return valid, took, nil
+}
+
+// verifyManyBisect checks the folded pairing equation for the proofs indexed by batch and
+// sets the corresponding bits in valid. On failure, the batch is split in two halves which
+// are checked independently.
+func (vk *VerifyingKey) verifyManyBisect(prepared []preparedProof, batch []int, valid *bitset.BitSet) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	ok, err := vk.verifyFolded(prepared, batch)
+	if err != nil {
+		return err
+	}
+	if ok {
+		for _, i := range batch {
+			valid.Set(uint(i))
+		}
+		return nil
+	}
+	if len(batch) == 1 {
+		return nil
+	}
+	mid := len(batch) / 2
+	if err := vk.verifyManyBisect(prepared, batch[:mid], valid); err != nil {
+		return err
+	}
+	return vk.verifyManyBisect(prepared, batch[mid:], valid)
+}
+
+// verifyFolded checks, for random ρᵢ
+//
+//	Π e(ρᵢ[Ar]ᵢ, [Bs]ᵢ) ⋅ e(Σρᵢ[Krs]ᵢ, -[δ]2) ⋅ e(Σρᵢ[Kvk(x)]ᵢ, -[γ]2) ⋅ e(-(Σρᵢ)[α]1, [β]2) == 1
+//
+// where the public input term Σρᵢ[Kvk(x)]ᵢ is computed with a single MSM over the
+// aggregated scalars Σρᵢxᵢ.
+func (vk *VerifyingKey) verifyFolded(prepared []preparedProof, batch []int) (bool, error) {
+	n := len(batch)
+	rho := make([]fr.Element, n)
+	for i := range rho {
+		for rho[i].IsZero() {
+			if _, err := rho[i].SetRandom(); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	var rhoSum fr.Element
+	for i := range rho {
+		rhoSum.Add(&rhoSum, &rho[i])
+	}
+
+	// aggregate public inputs: scalars[j] = Σ ρᵢ xᵢⱼ
+	nbPublic := len(vk.G1.K) - 1
+	scalars := make([]fr.Element, nbPublic)
+	var tmp fr.Element
+	for k, i := range batch {
+		for j := range prepared[i].public {
+			tmp.Mul(&rho[k], &prepared[i].public[j])
+			scalars[j].Add(&scalars[j], &tmp)
+		}
+	}
+
+	var kSum curve.G1Jac
+	if _, err := kSum.MultiExp(vk.G1.K[1:], scalars, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+	var b big.Int
+	var k0 curve.G1Jac
+	k0.FromAffine(&vk.G1.K[0])
+	k0.ScalarMultiplication(&k0, rhoSum.BigInt(&b))
+	kSum.AddAssign(&k0)
+
+	krs := make([]curve.G1Affine, n)
+	for k, i := range batch {
+		krs[k] = prepared[i].proof.Krs
+	}
+	var krsSum curve.G1Affine
+	if _, err := krsSum.MultiExp(krs, rho, ecc.MultiExpConfig{}); err != nil {
+		return false, err
+	}
+
+	if vk.CommitmentInfo.Is() {
+		commitments := make([]curve.G1Affine, 0, n*len(vk.CommitmentInfo))
+		rhos := make([]fr.Element, 0, n*len(vk.CommitmentInfo))
+		for k, i := range batch {
+			for j := range vk.CommitmentInfo {
+				commitments = append(commitments, prepared[i].proof.Commitments[j])
+				rhos = append(rhos, rho[k])
+			}
+		}
+		var commitmentSum curve.G1Jac
+		if _, err := commitmentSum.MultiExp(commitments, rhos, ecc.MultiExpConfig{}); err != nil {
+			return false, err
+		}
+		kSum.AddAssign(&commitmentSum)
+	}
+
+	var kSumAff, alphaAff curve.G1Affine
+	kSumAff.FromJacobian(&kSum)
+	rhoSum.Neg(&rhoSum)
+	alphaAff.ScalarMultiplication(&vk.G1.Alpha, rhoSum.BigInt(&b))
+
+	P := make([]curve.G1Affine, n, n+3)
+	Q := make([]curve.G2Affine, n, n+3)
+	utils.Parallelize(n, func(start, end int) {
+		var s big.Int
+		for k := start; k < end; k++ {
+			i := batch[k]
+			P[k].ScalarMultiplication(&prepared[i].proof.Ar, rho[k].BigInt(&s))
+			Q[k] = prepared[i].proof.Bs
+		}
+	})
+	P = append(P, krsSum, kSumAff, alphaAff)
+	Q = append(Q, vk.G2.deltaNeg, vk.G2.gammaNeg, vk.G2.Beta)
+
+	ml, err := curve.MillerLoop(P, Q)
+	if err != nil {
+		return false, err
+	}
+	res := curve.FinalExponentiation(&ml)
+
+	var one curve.GT
+	one.SetOne()
+	return res.Equal(&one), nil
+}
+
+// preparePublicWitness performs the per proof checks of Verify which do not involve
+// pairings, and returns the public witness augmented with the commitment wires if any.
+func (vk *VerifyingKey) preparePublicWitness(proof *Proof, publicWitness fr.Vector) (fr.Vector, error) {
+	nbPublicVars := len(vk.G1.K) - len(vk.CommitmentInfo)
+	if len(publicWitness) != nbPublicVars-1 {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), nbPublicVars-1)
+	}
+	if len(proof.Commitments) != len(vk.CommitmentInfo) || len(proof.CommitmentPoks) != len(vk.CommitmentInfo) {
+		return nil, fmt.Errorf("invalid number of commitments, got %d, expected %d", len(proof.Commitments), len(vk.CommitmentInfo))
+	}
+
+	if err := proof.checkSubgroups(); err != nil {
+		return nil, err
+	}
+
+	res := make(fr.Vector, len(publicWitness), len(vk.G1.K)-1)
+	copy(res, publicWitness)
+
+	for j := range vk.CommitmentInfo {
+		commitmentInfo := &vk.CommitmentInfo[j]
+		if err := vk.CommitmentKeys[j].Verify(proof.Commitments[j], proof.CommitmentPoks[j]); err != nil {
+			return nil, fmt.Errorf("%w: commitment %d: %v", ErrCommitmentPok, j, err)
+		}
+
+		publicCommitted := make([]*big.Int, commitmentInfo.NbPublicCommitted())
+		for i := range publicCommitted {
+			var b big.Int
+			publicWitness[commitmentInfo.Committed[i]-1].BigInt(&b)
+			publicCommitted[i] = &b
+		}
+
+		commitmentWire, err := solveCommitmentWire(commitmentInfo, &proof.Commitments[j], publicCommitted)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, commitmentWire)
+	}
+
+	if len(res) != len(vk.G1.K)-1 {
+		return nil, errors.New("public witness doesn't match verifying key")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package groth16_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyMany(t *testing.T) {
+	assert := assert.New(t)
+
+	_r1cs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+
+	const nbProofs = 5
+	proofs := make([]groth16.Proof, nbProofs)
+	publics := make([]witness.Witness, nbProofs)
+	for i := range proofs {
+		publics[i], proofs[i] = prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, _r1cs, pk)
+	}
+
+	valid, _, err := groth16.VerifyMany(vk, proofs, publics)
+	assert.NoError(err)
+	assert.Equal(uint(nbProofs), valid.Count())
+
+	// swapping two proofs of the batch with a proof for another statement must only
+	// invalidate these two.
+	otherR1cs, otherPk, _ := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_, proofs[1] = prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, otherR1cs, otherPk)
+	_, proofs[3] = prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, otherR1cs, otherPk)
+
+	valid, _, err = groth16.VerifyMany(vk, proofs, publics)
+	assert.NoError(err)
+	assert.Equal(uint(nbProofs-2), valid.Count())
+	assert.False(valid.Test(1))
+	assert.False(valid.Test(3))
+	assert.True(valid.Test(0))
+	assert.True(valid.Test(2))
+	assert.True(valid.Test(4))
+
+	_, _, err = groth16.VerifyMany(vk, proofs, publics[1:])
+	assert.Error(err)
+
+	// nil proofs and witnesses are invalid, typed or not
+	proofs[0] = nil
+	proofs[2] = (*groth16_bn254.Proof)(nil)
+	publics[4] = nil
+	valid, _, err = groth16.VerifyMany(vk, proofs, publics)
+	assert.NoError(err)
+	assert.Equal(uint(0), valid.Count())
+}
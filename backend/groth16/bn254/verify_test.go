@@ -0,0 +1,106 @@
+package groth16_test
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyErrors(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, pk, vk := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	w, _proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ccs, pk)
+	public, err := w.Public()
+	assert.NoError(err)
+	_vk := vk.(*groth16_bn254.VerifyingKey)
+	publicWitness := public.Vector().(fr.Vector)
+	valid := *_proof.(*groth16_bn254.Proof)
+
+	assert.NoError(groth16_bn254.Verify(&valid, _vk, publicWitness))
+	assert.NoError(groth16_bn254.Verify(&valid, _vk, publicWitness, backend.WithStrictVerification()))
+
+	// another statement
+	var three fr.Element
+	three.SetUint64(3)
+	assert.ErrorIs(groth16_bn254.Verify(&valid, _vk, fr.Vector{three}), groth16_bn254.ErrPairingCheck)
+
+	// a point off the curve
+	proof := clone(valid)
+	var one fp.Element
+	one.SetOne()
+	proof.Krs.X.Add(&proof.Krs.X, &one)
+	err = groth16_bn254.Verify(&proof, _vk, publicWitness)
+	assert.ErrorIs(err, groth16_bn254.ErrSubgroupCheck)
+	assert.Contains(err.Error(), "Krs")
+
+	// the proof of knowledge of another commitment
+	proof = clone(valid)
+	proof.CommitmentPoks[0] = proof.Commitments[0]
+	assert.ErrorIs(groth16_bn254.Verify(&proof, _vk, publicWitness), groth16_bn254.ErrCommitmentPok)
+
+	// an unreduced coordinate is only rejected in strict mode
+	proof = clone(valid)
+	addModulus(&proof.Ar.Y)
+	assert.NoError(groth16_bn254.Verify(&proof, _vk, publicWitness))
+	err = groth16_bn254.Verify(&proof, _vk, publicWitness, backend.WithStrictVerification())
+	assert.ErrorIs(err, groth16_bn254.ErrNonCanonical)
+	assert.Contains(err.Error(), "Ar")
+
+	proof = clone(valid)
+	proof.Ar.X.SetZero()
+	proof.Ar.Y.SetZero()
+	assert.ErrorIs(groth16_bn254.Verify(&proof, _vk, publicWitness, backend.WithStrictVerification()), groth16_bn254.ErrNonCanonical)
+}
+
+func TestReadCanonicalFrom(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, pk, _ := setup(t, &oneSecretOnePublicCommittedCircuit{})
+	_, _proof := prove(t, &oneSecretOnePublicCommittedCircuit{One: 1, Two: 2}, ccs, pk)
+	proof := _proof.(*groth16_bn254.Proof)
+
+	var compressed, raw bytes.Buffer
+	_, err := proof.WriteTo(&compressed)
+	assert.NoError(err)
+	_, err = proof.WriteRawTo(&raw)
+	assert.NoError(err)
+	for _, b := range [][]byte{compressed.Bytes(), raw.Bytes()} {
+		var read groth16_bn254.Proof
+		n, err := read.ReadCanonicalFrom(bytes.NewReader(b))
+		assert.NoError(err)
+		assert.Equal(int64(len(b)), n)
+		assert.Equal(*proof, read)
+	}
+
+	// Ar compressed, the other points uncompressed
+	arBytes := proof.Ar.Bytes()
+	mixed := append(arBytes[:], raw.Bytes()[len(proof.Ar.RawBytes()):]...)
+	var read groth16_bn254.Proof
+	_, err = read.ReadFrom(bytes.NewReader(mixed))
+	assert.NoError(err)
+	assert.Equal(*proof, read)
+	_, err = read.ReadCanonicalFrom(bytes.NewReader(mixed))
+	assert.ErrorIs(err, groth16_bn254.ErrNonCanonical)
+}
+
+func clone(proof groth16_bn254.Proof) groth16_bn254.Proof {
+	proof.Commitments = append(proof.Commitments[:0:0], proof.Commitments...)
+	proof.CommitmentPoks = append(proof.CommitmentPoks[:0:0], proof.CommitmentPoks...)
+	return proof
+}
+
+// addModulus adds the modulus to the Montgomery form of x, which stays smaller than 2²⁵⁶.
+func addModulus(x *fp.Element) {
+	q := fp.Modulus().Bits()
+	var carry uint64
+	for i := range x {
+		x[i], carry = bits.Add64(x[i], uint64(q[i]), carry)
+	}
+}
@@ -0,0 +1,102 @@
+package groth16_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	icicle_bn254 "github.com/consensys/gnark/backend/groth16/bn254/icicle"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// TestUpstreamCompatibility proves and verifies with the API of the later versions of upstream
+// gnark.
+func TestUpstreamCompatibility(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := w.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk := icicle_bn254.NewProvingKey()
+	var vk groth16_bn254.VerifyingKey
+	if err := icicle_bn254.Setup(ccs.(*cs_bn254.R1CS), pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := icicle_bn254.Prove(ccs.(*cs_bn254.R1CS), pk, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, &vk, public); err != nil {
+		t.Fatal(err)
+	}
+
+	// the keys of the icicle package are the ones of the curve-erased API
+	if _, err := groth16.Prove(ccs, pk, w); err != nil {
+		t.Fatal(err)
+	}
+
+	// the settings not supported are errors
+	if err := groth16.Verify(proof, &vk, public, backend.WithVerifierHashToFieldFunction(sha256.New())); !errors.Is(err, backend.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+	if _, err := groth16.Prove(ccs, pk, w, backend.WithProverHashToFieldFunction(sha256.New())); !errors.Is(err, backend.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+	if pk.Accelerator() != "icicle" {
+		if _, err := groth16.Prove(ccs, pk, w, backend.WithIcicleAcceleration()); err == nil {
+			t.Fatal("the proof must run on the icicle accelerator")
+		}
+	}
+}
+
+// TestCommitmentNotSupported checks that the provers of the curves other than BN254 reject a
+// precomputed commitment instead of ignoring it.
+func TestCommitmentNotSupported(t *testing.T) {
+	for _, curve := range gnark.Curves() {
+		if curve == ecc.BN254 {
+			continue
+		}
+		t.Run(curve.String(), func(t *testing.T) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, curve.ScalarField())
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, err := groth16.DummySetup(ccs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := groth16.Prove(ccs, pk, w, backend.WithCommitment([]byte{1})); !errors.Is(err, backend.ErrNotSupported) {
+				t.Fatalf("expected ErrNotSupported, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package groth16
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+)
+
+// crashDumpDir is the directory of the crash dumps, empty if disabled.
+var crashDumpDir atomic.Value
+
+// EnableCrashDumps makes Prove write a diagnostics bundle, a CrashDump in JSON, to a new file
+// of dir when the prover panics or fails on a device error (see accel.ErrDevice), before
+// re-panicking or returning the error. The bundle holds the context operators need to triage
+// GPU issues: the stacks, the device, the last stages of the prover, the live device buffers,
+// the prover options and a fingerprint of the circuit. An empty dir disables the crash dumps.
+//
+// Only the panics of the goroutine calling Prove are recovered: a panic in another goroutine of
+// the prover still ends the program without a bundle.
+func EnableCrashDumps(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+	}
+	crashDumpDir.Store(dir)
+	return nil
+}
+
+// CrashDump is the diagnostics bundle of a failed proof, see EnableCrashDumps.
+type CrashDump struct {
+	Time         time.Time `json:"time"`
+	GnarkVersion string    `json:"gnarkVersion"`
+	GoVersion    string    `json:"goVersion"`
+	NumCPU       int       `json:"numCPU"`
+
+	// Panic is set if the prover panicked, and Error is the panic value or the error.
+	Panic bool   `json:"panic"`
+	Error string `json:"error"`
+
+	// Stack holds the stacks of all the goroutines when the failure was caught.
+	Stack string `json:"stack"`
+
+	// LastStage is the last stage the prover started, and RunningStages the stages started
+	// but not ended, as reported to the backend.Tracer.
+	LastStage     string   `json:"lastStage"`
+	RunningStages []string `json:"runningStages"`
+
+	Device  *CrashDumpDevice `json:"device,omitempty"`
+	Config  CrashDumpConfig  `json:"config"`
+	Circuit CrashDumpCircuit `json:"circuit"`
+}
+
+// CrashDumpDevice describes the device of the proving key, for the GPU provers.
+type CrashDumpDevice struct {
+	Accelerator string             `json:"accelerator"`
+	Name        string             `json:"name"`
+	Allocations []accel.Allocation `json:"allocations"`
+}
+
+// CrashDumpConfig holds the prover options, see backend.ProverConfig.
+type CrashDumpConfig struct {
+	NbSolverOptions       int    `json:"nbSolverOptions"`
+	PrecomputedCommitment bool   `json:"precomputedCommitment"`
+	MultiExpNbTasks       int    `json:"multiExpNbTasks"`
+	Accelerator           string `json:"accelerator"`
+	SparseMSM             bool   `json:"sparseMSM"`
+	GroupDuplicateScalars bool   `json:"groupDuplicateScalars"`
+	UnsafeNoZK            bool   `json:"unsafeNoZK"`
+}
+
+// CrashDumpCircuit identifies the circuit. The fingerprint is the SHA-256 of the serialized
+// constraint system, so that the circuit of a bundle can be matched with a known one.
+type CrashDumpCircuit struct {
+	Curve               string `json:"curve"`
+	Fingerprint         string `json:"fingerprint"`
+	NbConstraints       int    `json:"nbConstraints"`
+	NbPublicVariables   int    `json:"nbPublicVariables"`
+	NbSecretVariables   int    `json:"nbSecretVariables"`
+	NbInternalVariables int    `json:"nbInternalVariables"`
+}
+
+// proveWithCrashDumps is Prove, writing a crash dump to dir on a panic or a device error.
+func proveWithCrashDumps(dir string, r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (proof Proof, err error) {
+	cfg, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	stages := &stageRecorder{next: cfg.Tracer, running: make(map[string]int)}
+	if stages.next == nil {
+		stages.next = backend.LogTracer(logger.Logger())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			writeCrashDump(dir, newCrashDump(fmt.Sprint(r), true, stages, r1cs, pk, cfg))
+			panic(r)
+		}
+	}()
+	proof, err = prove(r1cs, pk, fullWitness, append(opts, backend.WithTracer(stages))...)
+	if errors.Is(err, accel.ErrDevice) {
+		writeCrashDump(dir, newCrashDump(err.Error(), false, stages, r1cs, pk, cfg))
+	}
+	return proof, err
+}
+
+func newCrashDump(msg string, panicked bool, stages *stageRecorder, r1cs constraint.ConstraintSystem, pk ProvingKey, cfg backend.ProverConfig) *CrashDump {
+	stack := make([]byte, 1<<20)
+	stack = stack[:runtime.Stack(stack, true)]
+
+	dump := &CrashDump{
+		Time:         time.Now().UTC(),
+		GnarkVersion: gnark.Version.String(),
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		Panic:        panicked,
+		Error:        msg,
+		Stack:        string(stack),
+		Config: CrashDumpConfig{
+			NbSolverOptions:       len(cfg.SolverOpts),
+			PrecomputedCommitment: cfg.Commitment != nil,
+			MultiExpNbTasks:       cfg.MultiExpNbTasks,
+			Accelerator:           cfg.Accelerator,
+			SparseMSM:             cfg.SparseMSM,
+			GroupDuplicateScalars: cfg.GroupDuplicateScalars,
+			UnsafeNoZK:            cfg.UnsafeNoZK,
+		},
+		Circuit: CrashDumpCircuit{
+			Curve:               utils.FieldToCurve(r1cs.Field()).String(),
+			NbConstraints:       r1cs.GetNbConstraints(),
+			NbPublicVariables:   r1cs.GetNbPublicVariables(),
+			NbSecretVariables:   r1cs.GetNbSecretVariables(),
+			NbInternalVariables: r1cs.GetNbInternalVariables(),
+		},
+	}
+	dump.LastStage, dump.RunningStages = stages.state()
+
+	h := sha256.New()
+	if _, err := r1cs.WriteTo(h); err == nil {
+		dump.Circuit.Fingerprint = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if _pk, ok := pk.(DeviceProvingKey); ok && _pk.Device() != nil {
+		device := _pk.Device()
+		dump.Device = &CrashDumpDevice{
+			Accelerator: _pk.Accelerator(),
+			Name:        device.Name(),
+			Allocations: accel.Allocations(device),
+		}
+	}
+	return dump
+}
+
+// writeCrashDump writes the dump to a new file of dir. The failures are logged, as the error of
+// the prover matters more.
+func writeCrashDump(dir string, dump *CrashDump) {
+	log := logger.Logger()
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		log.Error().Err(err).Msg("encoding the prover crash dump")
+		return
+	}
+	f, err := os.CreateTemp(dir, "groth16-crash-*.json")
+	if err != nil {
+		log.Error().Err(err).Msg("creating the prover crash dump")
+		return
+	}
+	_, err = f.Write(data)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		log.Error().Err(err).Str("path", f.Name()).Msg("writing the prover crash dump")
+		return
+	}
+	log.Error().Str("path", f.Name()).Msg("prover failed, crash dump written")
+}
+
+// stageRecorder is a backend.Tracer recording the stages of a proof, and forwarding them to the
+// next tracer if not nil.
+type stageRecorder struct {
+	next backend.Tracer
+
+	lock    sync.Mutex
+	last    string
+	running map[string]int
+}
+
+func stageName(stage backend.Stage, meta backend.StageMetadata) string {
+	if meta.Label != "" {
+		return stage.String() + " " + meta.Label
+	}
+	return stage.String()
+}
+
+func (r *stageRecorder) OnStageStart(ctx context.Context, stage backend.Stage, meta backend.StageMetadata) context.Context {
+	name := stageName(stage, meta)
+	r.lock.Lock()
+	r.last = name
+	r.running[name]++
+	r.lock.Unlock()
+	if r.next != nil {
+		return r.next.OnStageStart(ctx, stage, meta)
+	}
+	return ctx
+}
+
+func (r *stageRecorder) OnStageEnd(ctx context.Context, stage backend.Stage, meta backend.StageMetadata, elapsed time.Duration) {
+	name := stageName(stage, meta)
+	r.lock.Lock()
+	if r.running[name]--; r.running[name] <= 0 {
+		delete(r.running, name)
+	}
+	r.lock.Unlock()
+	if r.next != nil {
+		r.next.OnStageEnd(ctx, stage, meta, elapsed)
+	}
+}
+
+// state returns the last stage started, and the stages running.
+func (r *stageRecorder) state() (last string, running []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for name := range r.running {
+		running = append(running, name)
+	}
+	sort.Strings(running)
+	return r.last, running
+}
@@ -0,0 +1,101 @@
+package groth16_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+// faultyDevice is the CPU emulation, whose G2 multi-exponentiations fail as a faulty GPU.
+type faultyDevice struct {
+	accel.Device
+}
+
+func (faultyDevice) MsmG2(res, scalars, points unsafe.Pointer, n int, cfg accel.MsmConfig) error {
+	return fmt.Errorf("%w: illegal memory access", accel.ErrDevice)
+}
+
+func init() {
+	accel.Register(accel.Provider{
+		Name:   "faulty",
+		Curves: []ecc.ID{ecc.BN254},
+		Open: func(curve ecc.ID) (accel.Device, error) {
+			d, err := accel.OpenProvider("cpu", curve)
+			return faultyDevice{d}, err
+		},
+		Fallback: true, // only opened by name
+	})
+}
+
+type crashCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *crashCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestCrashDump(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "dumps")
+	assert.NoError(groth16.EnableCrashDumps(dir))
+	defer groth16.EnableCrashDumps("")
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &crashCircuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&crashCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// no dump for the errors of the caller
+	_, err = groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	bad, err := frontend.NewWitness(&crashCircuit{X: 3, Y: 8}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, bad)
+	assert.Error(err)
+	files, err := os.ReadDir(dir)
+	assert.NoError(err)
+	assert.Empty(files)
+
+	_pk := pk.(*groth16_bn254.ProvingKey)
+	assert.NoError(_pk.SetAccelerator("faulty"))
+	_, err = groth16.Prove(ccs, pk, w)
+	assert.ErrorIs(err, accel.ErrDevice)
+
+	files, err = os.ReadDir(dir)
+	assert.NoError(err)
+	if !assert.Len(files, 1) {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(err)
+	var dump groth16.CrashDump
+	assert.NoError(json.Unmarshal(data, &dump))
+	assert.False(dump.Panic)
+	assert.Contains(dump.Error, "illegal memory access")
+	assert.Contains(dump.Stack, "goroutine")
+	assert.NotEmpty(dump.LastStage)
+	assert.Equal("bn254", dump.Circuit.Curve)
+	assert.Len(dump.Circuit.Fingerprint, 64)
+	assert.Equal(ccs.GetNbConstraints(), dump.Circuit.NbConstraints)
+	if assert.NotNil(dump.Device) {
+		assert.Equal("faulty", dump.Device.Accelerator)
+		assert.NotEmpty(dump.Device.Allocations, "the proving key is on the device")
+	}
+}
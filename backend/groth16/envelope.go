@@ -0,0 +1,95 @@
+package groth16
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/envelope"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// ErrVerifyingKeyMismatch is returned by VerifyEnvelope when the envelope is for another
+// verifying key.
+var ErrVerifyingKeyMismatch = errors.New("the proof envelope is for another verifying key")
+
+// VerifyingKeyHash returns the SHA-256 hash identifying vk in the envelopes: the one of
+// VerifyingKey.Hash on BN254, which doesn't depend on the encoding nor on the setup log, and
+// the one of the raw encoding of the key on the other curves.
+func VerifyingKeyHash(vk VerifyingKey) ([]byte, error) {
+	if vk, ok := vk.(*groth16_bn254.VerifyingKey); ok {
+		return vk.Hash(), nil
+	}
+	h := sha256.New()
+	h.Write([]byte("gnark groth16 " + vk.CurveID().String() + " verifying key v1\x00"))
+	if _, err := vk.WriteRawTo(h); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// EnvelopeMetadata returns the metadata of the envelope of a proof of the circuit with the ID
+// circuitID, verified with vk and the public witness: besides the ones of
+// envelope.NewMetadata, the ID, the hash of vk (see VerifyingKeyHash) and the public witness,
+// which route the proof to its key. The envelope is then sealed with envelope.Seal:
+//
+//	meta, err := groth16.EnvelopeMetadata("transfer", vk, publicWitness)
+//	e, err := envelope.Seal(proof, meta, proverKey)
+func EnvelopeMetadata(circuitID string, vk VerifyingKey, publicWitness witness.Witness) (envelope.Metadata, error) {
+	meta := envelope.NewMetadata("groth16", vk.CurveID().String())
+	hash, err := VerifyingKeyHash(vk)
+	if err != nil {
+		return envelope.Metadata{}, err
+	}
+	public, err := publicWitness.MarshalBinary()
+	if err != nil {
+		return envelope.Metadata{}, err
+	}
+	meta.CircuitID, meta.VerifyingKeyHash, meta.PublicWitness = circuitID, hash, public
+	return meta, nil
+}
+
+// VerifyEnvelope checks the signature of the envelope, returns an error wrapping
+// ErrVerifyingKeyMismatch if it isn't for vk, and verifies its proof with its public witness
+// otherwise. It doesn't check that the prover key is one the caller trusts.
+func VerifyEnvelope(e *envelope.Envelope, vk VerifyingKey, opts ...backend.VerifierOption) error {
+	if err := e.Verify(); err != nil {
+		return err
+	}
+	meta := &e.Metadata
+	if meta.Backend != "groth16" || meta.Curve != vk.CurveID().String() {
+		return fmt.Errorf("%w: the envelope is for a %s proof on %s", ErrVerifyingKeyMismatch, meta.Backend, meta.Curve)
+	}
+	hash, err := VerifyingKeyHash(vk)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, meta.VerifyingKeyHash) {
+		return fmt.Errorf("%w: the envelope is for the key %s, not %s", ErrVerifyingKeyMismatch, shortHash(meta.VerifyingKeyHash), shortHash(hash))
+	}
+
+	proof := NewProof(vk.CurveID())
+	if err := e.Open(proof); err != nil {
+		return err
+	}
+	publicWitness, err := witness.New(vk.CurveID().ScalarField())
+	if err != nil {
+		return err
+	}
+	if err := publicWitness.UnmarshalBinary(meta.PublicWitness); err != nil {
+		return fmt.Errorf("public witness: %w", err)
+	}
+	return Verify(proof, vk, publicWitness, opts...)
+}
+
+// shortHash returns the hex encoding of the first bytes of a hash, for the error messages.
+func shortHash(h []byte) string {
+	if len(h) > 8 {
+		h = h[:8]
+	}
+	return hex.EncodeToString(h)
+}
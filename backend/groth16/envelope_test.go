@@ -0,0 +1,68 @@
+package groth16_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/envelope"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			w, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, curve.ScalarField())
+			assert.NoError(err)
+			proof, err := groth16.Prove(ccs, pk, w)
+			assert.NoError(err)
+			public, err := w.Public()
+			assert.NoError(err)
+
+			meta, err := groth16.EnvelopeMetadata("square", vk, public)
+			assert.NoError(err)
+			e, err := envelope.Seal(proof, meta, key)
+			assert.NoError(err)
+
+			// round trip, with the single encoding of the envelope
+			b, err := e.MarshalBinary()
+			assert.NoError(err)
+			var read envelope.Envelope
+			assert.NoError(read.UnmarshalBinary(b))
+			assert.Equal("square", read.Metadata.CircuitID)
+			assert.NoError(groth16.VerifyEnvelope(&read, vk))
+			assert.Error(read.UnmarshalBinary(append(b, ' ')), "non canonical encoding")
+
+			// another key
+			_, other, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			assert.ErrorIs(groth16.VerifyEnvelope(&read, other), groth16.ErrVerifyingKeyMismatch)
+
+			// another statement, signed by the prover
+			w, err = frontend.NewWitness(&squareCircuit{Y: 10}, curve.ScalarField(), frontend.PublicOnly())
+			assert.NoError(err)
+			meta, err = groth16.EnvelopeMetadata("square", vk, w)
+			assert.NoError(err)
+			e, err = envelope.Seal(proof, meta, key)
+			assert.NoError(err)
+			assert.Error(groth16.VerifyEnvelope(e, vk))
+
+			// the routing fields are signed
+			e.Metadata.PublicWitness = read.Metadata.PublicWitness
+			assert.Error(groth16.VerifyEnvelope(e, vk))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package groth16
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	cs_bls12377 "github.com/consensys/gnark/constraint/bls12-377"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/internal/utils"
+
+	groth16_bls12377 "github.com/consensys/gnark/backend/groth16/bls12-377"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// DeviceProfile holds the throughputs of a proving device, as measured by benchmarks on
+// this device (for instance the MSM and NTT benchmarks of the curve, and BenchmarkProver, or
+// the cmd/gpubench command on the GPU provers).
+// Throughputs depend on the curve: a profile is only meaningful for the curve it was
+// measured on.
+type DeviceProfile struct {
+	// Name identifies the device, e.g. the GPU model.
+	Name string `json:"name"`
+
+	// MSMG1 and MSMG2 are the throughputs of the multi-scalar multiplications in G1 and G2,
+	// in points per second.
+	MSMG1 float64 `json:"msmG1"`
+	MSMG2 float64 `json:"msmG2"`
+
+	// NTT is the throughput of the number theoretic transforms, in butterflies per second.
+	// A transform of size n computes n/2·log₂(n) butterflies.
+	NTT float64 `json:"ntt"`
+
+	// Solver is the throughput of the constraint system solver, in instructions per second.
+	Solver float64 `json:"solver"`
+
+	// HostToDevice is the bandwidth of the copies to the device, in bytes per second. It is
+	// zero if the prover runs on the host.
+	HostToDevice float64 `json:"hostToDevice"`
+
+	// Overhead is the fixed cost of a proof, independent of the circuit size (device
+	// allocations, kernel launches, ...).
+	Overhead time.Duration `json:"overhead"`
+
+	// BucketFactor is the bucket factor of the fastest multi-scalar multiplications on the
+	// device, zero if unknown. It is not used by the estimates.
+	BucketFactor int `json:"bucketFactor,omitempty"`
+}
+
+// nbNTT is the number of transforms of size the domain cardinality in the computation of
+// the quotient: 3 inverse transforms and 3 transforms on the coset for a, b and c, and 1
+// inverse transform on the coset for h.
+const nbNTT = 7
+
+// EstimateProveTime returns the time groth16.Prove is expected to take to prove the
+// constraint system with pk on the device described by profile.
+//
+// The estimate is the sum of the solver, NTT, multi-scalar multiplication and copy times,
+// each derived from the size of the constraint system and the proving key. It is linear in
+// the number of MSM points, while the MSM throughput grows with their number; for circuits
+// much larger than the ones the profile was measured on, the estimate is an upper bound.
+// Hints are counted as one instruction; circuits dominated by expensive hints are
+// underestimated.
+func EstimateProveTime(r1cs constraint.ConstraintSystem, pk ProvingKey, profile DeviceProfile) (time.Duration, error) {
+	if curve := utils.FieldToCurve(r1cs.Field()); curve != pk.CurveID() {
+		return 0, fmt.Errorf("constraint system on %s but proving key on %s", curve, pk.CurveID())
+	}
+	if profile.MSMG1 <= 0 || profile.MSMG2 <= 0 || profile.NTT <= 0 || profile.Solver <= 0 {
+		return 0, errors.New("device profile: throughputs must be positive")
+	}
+	if profile.HostToDevice < 0 || profile.Overhead < 0 {
+		return 0, errors.New("device profile: negative bandwidth or overhead")
+	}
+
+	n := ecc.NextPowerOfTwo(uint64(r1cs.GetNbConstraints()))
+	logN := bits.TrailingZeros64(n)
+	nbWires := r1cs.GetNbInternalVariables() + r1cs.GetNbSecretVariables() + r1cs.GetNbPublicVariables()
+
+	seconds := float64(r1cs.GetNbInstructions()) / profile.Solver
+	seconds += nbNTT * float64(n/2) * float64(logN) / profile.NTT
+	seconds += float64(pk.NbG1()) / profile.MSMG1
+	seconds += float64(pk.NbG2()) / profile.MSMG2
+	if profile.HostToDevice > 0 {
+		// the wire values for A, B and K, and the evaluations of a, b and c
+		elementSize := (r1cs.FieldBitLen() + 63) / 64 * 8
+		seconds += float64((3*nbWires+3*int(n))*elementSize) / profile.HostToDevice
+	}
+
+	return profile.Overhead + time.Duration(seconds*float64(time.Second)), nil
+}
+
+// MemoryEstimate is the memory needed to prove a constraint system with a proving key, in
+// bytes.
+type MemoryEstimate struct {
+	// DeviceResident is the device memory of the proving key: its device copies, allocated
+	// when it is set up or read, and kept for all the proofs.
+	DeviceResident uint64 `json:"deviceResident"`
+
+	// DeviceProof is the peak device memory allocated by a proof on top of DeviceResident. It
+	// is the footprint to reserve for each concurrent proof, for instance in the scheduler of
+	// backend/scheduler.
+	DeviceProof uint64 `json:"deviceProof"`
+
+	// HostSolve is the host memory allocated by the solver: the values of the wires and the
+	// evaluations of a, b and c on the domain.
+	HostSolve uint64 `json:"hostSolve"`
+}
+
+// EstimateMemory returns the memory groth16.Prove is expected to need to prove the constraint
+// system with pk, so that proofs which can't fit are rejected before they start. The device
+// memory is zero on the curves without a GPU prover; see the DeviceMemoryEstimate methods of
+// their proving keys for the details of the estimate.
+func EstimateMemory(r1cs constraint.ConstraintSystem, pk ProvingKey) (MemoryEstimate, error) {
+	if curve := utils.FieldToCurve(r1cs.Field()); curve != pk.CurveID() {
+		return MemoryEstimate{}, fmt.Errorf("constraint system on %s but proving key on %s", curve, pk.CurveID())
+	}
+
+	var res MemoryEstimate
+	switch _r1cs := r1cs.(type) {
+	case *cs_bn254.R1CS:
+		var err error
+		res.DeviceResident, res.DeviceProof, err = pk.(*groth16_bn254.ProvingKey).DeviceMemoryEstimate(_r1cs)
+		if err != nil {
+			return MemoryEstimate{}, err
+		}
+	case *cs_bls12377.R1CS:
+		res.DeviceResident, res.DeviceProof = pk.(*groth16_bls12377.ProvingKey).DeviceMemoryEstimate(_r1cs)
+	}
+
+	n := ecc.NextPowerOfTwo(uint64(r1cs.GetNbConstraints()))
+	nbWires := uint64(r1cs.GetNbInternalVariables() + r1cs.GetNbSecretVariables() + r1cs.GetNbPublicVariables())
+	elementSize := uint64((r1cs.FieldBitLen() + 63) / 64 * 8)
+	// the values and their solved flags, and a, b and c allocated with the domain capacity
+	res.HostSolve = nbWires*(elementSize+1) + 3*n*elementSize
+
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package groth16_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateProveTime(t *testing.T) {
+	assert := require.New(t)
+
+	profile := groth16.DeviceProfile{
+		MSMG1:    1 << 20,
+		MSMG2:    1 << 18,
+		NTT:      1 << 24,
+		Solver:   1 << 22,
+		Overhead: 10 * time.Millisecond,
+	}
+
+	estimate := func(nbConstraints int) time.Duration {
+		t.Helper()
+		ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: nbConstraints})
+		assert.NoError(err)
+		pk, err := groth16.DummySetup(ccs)
+		assert.NoError(err)
+		d, err := groth16.EstimateProveTime(ccs, pk, profile)
+		assert.NoError(err)
+		return d
+	}
+
+	small, large := estimate(1000), estimate(10000)
+	assert.Greater(small, profile.Overhead)
+	assert.Greater(large, small)
+
+	// copies to the device add to the estimate
+	profile.HostToDevice = 1 << 30
+	assert.Greater(estimate(10000), large)
+
+	// the profile must be complete, and the key on the curve of the constraint system
+	ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: 10})
+	assert.NoError(err)
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+	_, err = groth16.EstimateProveTime(ccs, pk, groth16.DeviceProfile{MSMG1: 1, MSMG2: 1, NTT: 1})
+	assert.Error(err)
+
+	other, err := frontend.Compile(ecc.BLS12_377.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: 10})
+	assert.NoError(err)
+	_, err = groth16.EstimateProveTime(other, pk, profile)
+	assert.Error(err)
+}
+
+func TestEstimateMemory(t *testing.T) {
+	assert := require.New(t)
+
+	estimate := func(curve ecc.ID, nbConstraints int) groth16.MemoryEstimate {
+		t.Helper()
+		ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: nbConstraints})
+		assert.NoError(err)
+		pk, err := groth16.DummySetup(ccs)
+		assert.NoError(err)
+		m, err := groth16.EstimateMemory(ccs, pk)
+		assert.NoError(err)
+		return m
+	}
+
+	// no GPU prover on BLS12-381
+	small, large := estimate(ecc.BLS12_381, 1000), estimate(ecc.BLS12_381, 10000)
+	assert.Zero(small.DeviceResident)
+	assert.Zero(small.DeviceProof)
+	assert.Greater(small.HostSolve, uint64(3*1024*32))
+	assert.Greater(large.HostSolve, small.HostSolve)
+
+	ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: 10})
+	assert.NoError(err)
+	other, err := frontend.Compile(ecc.BLS12_377.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: 10})
+	assert.NoError(err)
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+	_, err = groth16.EstimateMemory(other, pk)
+	assert.Error(err)
+}
@@ -20,10 +20,14 @@
 package groth16
 
 import (
+	"fmt"
 	"io"
+	"time"
 
+	"github.com/bits-and-blooms/bitset"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	cs_bls12377 "github.com/consensys/gnark/constraint/bls12-377"
@@ -83,6 +87,37 @@ type ProvingKey interface {
 	IsDifferent(interface{}) bool
 }
 
+// DeviceProvingKey is a ProvingKey of a curve with a GPU prover, BN254 or BLS12-377, whose
+// device copies can be managed without knowing the curve:
+//
+//	if pk, ok := pk.(groth16.DeviceProvingKey); ok {
+//		err = pk.SetAccelerator("icicle")
+//	}
+//
+// The layout of the device copies is curve specific, see the DeviceLayout of the curve packages.
+type DeviceProvingKey interface {
+	ProvingKey
+
+	// Accelerator returns the name of the accelerator provider of the device copies.
+	Accelerator() string
+
+	// SetAccelerator copies the proving key to a device of the named accelerator provider,
+	// and releases the copies on the previous device.
+	SetAccelerator(name string) error
+
+	// Device returns the device holding the device copies, or nil if there are none.
+	Device() accel.Device
+
+	// CheckDeviceConversion checks that the device converts the points and the scalars the
+	// way the prover expects.
+	CheckDeviceConversion(nbSamples int) error
+}
+
+var (
+	_ DeviceProvingKey = (*groth16_bn254.ProvingKey)(nil)
+	_ DeviceProvingKey = (*groth16_bls12377.ProvingKey)(nil)
+)
+
 // VerifyingKey represents a Groth16 VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
@@ -109,7 +144,17 @@ type VerifyingKey interface {
 }
 
 // Verify runs the groth16.Verify algorithm on provided proof with given witness
-func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+//
+// backend.WithStrictVerification is only supported on BN254, whose verifier also reports
+// which check failed, see groth16_bn254.Verify.
+func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
+	cfg, err := backend.NewVerifierConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if _, ok := proof.(*groth16_bn254.Proof); !ok && cfg.Strict {
+		return fmt.Errorf("%w: strict verification on %s", backend.ErrNotSupported, proof.CurveID())
+	}
 
 	switch _proof := proof.(type) {
 	case *groth16_bls12377.Proof:
@@ -129,7 +174,7 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
 		if !ok {
 			return witness.ErrInvalidWitness
 		}
-		return groth16_bn254.Verify(_proof, vk.(*groth16_bn254.VerifyingKey), w)
+		return groth16_bn254.Verify(_proof, vk.(*groth16_bn254.VerifyingKey), w, opts...)
 	case *groth16_bw6761.Proof:
 		w, ok := publicWitness.Vector().(fr_bw6761.Vector)
 		if !ok {
@@ -159,6 +204,42 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
 	}
 }
 
+// VerifyMany verifies a batch of proofs against the same VerifyingKey, sharing the
+// fixed pairings and the public input MSM across the batch.
+//
+// It returns a bitmap where bit i is set iff proofs[i] is valid for publicWitnesses[i],
+// and the aggregate verification time; nil proofs and witnesses are invalid. This is
+// implemented for BN254 and will return an error with other curves.
+func VerifyMany(vk VerifyingKey, proofs []Proof, publicWitnesses []witness.Witness) (*bitset.BitSet, time.Duration, error) {
+	if len(proofs) != len(publicWitnesses) {
+		return nil, 0, fmt.Errorf("got %d proofs but %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+
+	switch _vk := vk.(type) {
+	case *groth16_bn254.VerifyingKey:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		ws := make([]fr_bn254.Vector, len(publicWitnesses))
+		for i := range proofs {
+			if proofs[i] == nil || publicWitnesses[i] == nil {
+				// left nil, the proof is invalid
+				continue
+			}
+			p, ok := proofs[i].(*groth16_bn254.Proof)
+			if !ok {
+				return nil, 0, fmt.Errorf("proof %d: curve mismatch", i)
+			}
+			w, ok := publicWitnesses[i].Vector().(fr_bn254.Vector)
+			if !ok {
+				return nil, 0, witness.ErrInvalidWitness
+			}
+			_proofs[i], ws[i] = p, w
+		}
+		return groth16_bn254.VerifyMany(_vk, _proofs, ws)
+	default:
+		return nil, 0, fmt.Errorf("batch verification not implemented for curve %s", vk.CurveID())
+	}
+}
+
 // Prove runs the groth16.Prove algorithm.
 //
 // if the force flag is set:
@@ -166,8 +247,16 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
 //		will execute all the prover computations, even if the witness is invalid
 //	 will produce an invalid proof
 //		internally, the solution vector to the R1CS will be filled with random values which may impact benchmarking
+//
+// See EnableCrashDumps for the diagnostics of the failed proofs.
 func Prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	if dir, _ := crashDumpDir.Load().(string); dir != "" {
+		return proveWithCrashDumps(dir, r1cs, pk, fullWitness, opts...)
+	}
+	return prove(r1cs, pk, fullWitness, opts...)
+}
 
+func prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
 	switch _r1cs := r1cs.(type) {
 	case *cs_bls12377.R1CS:
 		return groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), fullWitness, opts...)
@@ -195,6 +284,29 @@ func Prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.
 	}
 }
 
+// ProveFromFiles generates a proof from a constraint system, a proving key and a full witness
+// stored in files, and writes the proof to proofPath if it isn't empty.
+//
+// Constraint systems written with WriteMappableTo are mapped in memory instead of being
+// decoded. Loaded systems and proving keys (including their device buffers) are cached by
+// file hash and reused by subsequent calls, the least recently used being evicted first (see
+// SetFileCacheSize).
+//
+// Only BN254 is supported.
+func ProveFromFiles(r1csPath, pkPath, witnessPath, proofPath string, opts ...backend.ProverOption) (Proof, error) {
+	proof, err := groth16_bn254.ProveFromFiles(r1csPath, pkPath, witnessPath, proofPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// SetFileCacheSize sets the number of files, constraint systems and proving keys, cached by
+// ProveFromFiles. With 0, the files are loaded at each call.
+func SetFileCacheSize(size int) {
+	groth16_bn254.SetFileCacheSize(size)
+}
+
 // Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
 //
 // Note that careful consideration must be given to this step in production environment.
@@ -203,55 +315,57 @@ func Prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.
 //
 // Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
 // or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
-func Setup(r1cs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
-
+//
+// The setup options (e.g. backend.WithSampler to draw the secrets from an HSM) are supported on
+// all the curves, except backend.WithSetupAccelerator, only on the curves with a GPU prover.
+func Setup(r1cs constraint.ConstraintSystem, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
 	switch _r1cs := r1cs.(type) {
 	case *cs_bls12377.R1CS:
 		var pk groth16_bls12377.ProvingKey
 		var vk groth16_bls12377.VerifyingKey
-		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls12381.R1CS:
 		var pk groth16_bls12381.ProvingKey
 		var vk groth16_bls12381.VerifyingKey
-		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bn254.R1CS:
 		var pk groth16_bn254.ProvingKey
 		var vk groth16_bn254.VerifyingKey
-		if err := groth16_bn254.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bn254.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bw6761.R1CS:
 		var pk groth16_bw6761.ProvingKey
 		var vk groth16_bw6761.VerifyingKey
-		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls24317.R1CS:
 		var pk groth16_bls24317.ProvingKey
 		var vk groth16_bls24317.VerifyingKey
-		if err := groth16_bls24317.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls24317.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls24315.R1CS:
 		var pk groth16_bls24315.ProvingKey
 		var vk groth16_bls24315.VerifyingKey
-		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bw6633.R1CS:
 		var pk groth16_bw6633.ProvingKey
 		var vk groth16_bw6633.VerifyingKey
-		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
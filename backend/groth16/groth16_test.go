@@ -1,17 +1,71 @@
 package groth16_test
 
 import (
+	"bytes"
 	"math/big"
+	"math/rand"
+	"runtime"
 	"testing"
 
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
 )
 
+// seededSampler is a deterministic sampler, for tests only.
+type seededSampler struct {
+	*rand.Rand
+}
+
+func (seededSampler) Name() string {
+	return "test/seeded"
+}
+
+// TestSetupReproducible checks that the setup of each curve only depends on the sampler, up to
+// the byte, commitment key included.
+func TestSetupReproducible(t *testing.T) {
+	for _, curve := range gnark.Curves() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &committedCircuit{})
+			assert.NoError(err)
+
+			setup := func() []byte {
+				pk, vk, err := groth16.Setup(ccs, backend.WithSampler(seededSampler{rand.New(rand.NewSource(1))}), backend.WithReproducible()) //#nosec G404 -- test only
+				assert.NoError(err)
+				var buf bytes.Buffer
+				_, err = pk.WriteRawTo(&buf)
+				assert.NoError(err)
+				_, err = vk.WriteTo(&buf)
+				assert.NoError(err)
+
+				// the keys derived from the sampler are sound
+				w, err := frontend.NewWitness(&committedCircuit{X: 3, Y: 9}, curve.ScalarField())
+				assert.NoError(err)
+				public, err := w.Public()
+				assert.NoError(err)
+				proof, err := groth16.Prove(ccs, pk, w)
+				assert.NoError(err)
+				assert.NoError(groth16.Verify(proof, vk, public))
+				return buf.Bytes()
+			}
+
+			expected := setup()
+			assert.Equal(expected, setup())
+
+			// the parallel computations don't depend on the number of threads
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+			assert.Equal(expected, setup())
+		})
+	}
+}
+
 //--------------------//
 //     benches		  //
 //--------------------//
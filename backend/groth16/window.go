@@ -0,0 +1,100 @@
+package groth16
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/backend"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+)
+
+// SlidingWindow proves a statement on each window of the last inputs of a stream, for
+// instance a chain of rolling state transitions.
+//
+// The inputs are kept in a ring buffer of slots: the i-th oldest input of the window is
+// in slots[(oldest+i) % len(slots)]. A circuit reading its inputs by slot (and not by age) keeps most
+// of its wire values when the window slides, which the BN254 prover uses to only copy the
+// changed wire values to the device, see groth16_bn254.Pipeline. On the other curves, the
+// windows are proved independently.
+type SlidingWindow[T any] struct {
+	ccs    constraint.ConstraintSystem
+	assign func(slots []T, oldest int) (frontend.Circuit, error)
+	prove  func(fullWitness witness.Witness) (Proof, error)
+	close  func() error
+
+	slots  []T
+	oldest int
+	pushed int
+}
+
+// NewSlidingWindow returns a SlidingWindow of size inputs, proving the circuits of ccs with pk
+// and the prover options. assign returns the assignment of the circuit on a window.
+func NewSlidingWindow[T any](ccs constraint.ConstraintSystem, pk ProvingKey, size int, assign func(slots []T, oldest int) (frontend.Circuit, error), opts ...backend.ProverOption) (*SlidingWindow[T], error) {
+	if size <= 0 {
+		return nil, errors.New("the window must have at least one input")
+	}
+	w := &SlidingWindow[T]{
+		ccs:    ccs,
+		assign: assign,
+		slots:  make([]T, size),
+		close:  func() error { return nil },
+	}
+	if r1cs, ok := ccs.(*cs_bn254.R1CS); ok {
+		pipeline := groth16_bn254.NewPipeline(r1cs, pk.(*groth16_bn254.ProvingKey), opts...)
+		w.prove = func(fullWitness witness.Witness) (Proof, error) {
+			proof, err := pipeline.Prove(fullWitness)
+			if err != nil {
+				return nil, err
+			}
+			return proof, nil
+		}
+		w.close = pipeline.Close
+	} else {
+		w.prove = func(fullWitness witness.Witness) (Proof, error) {
+			return Prove(ccs, pk, fullWitness, opts...)
+		}
+	}
+	return w, nil
+}
+
+// Push slides the window by one input. Once the window is full, it returns the proof of the
+// window and its public witness; before, it returns a nil proof.
+func (w *SlidingWindow[T]) Push(input T) (Proof, witness.Witness, error) {
+	if w.pushed < len(w.slots) {
+		w.slots[w.pushed] = input
+		w.pushed++
+		if w.pushed < len(w.slots) {
+			return nil, nil, nil
+		}
+	} else {
+		// the oldest input is replaced
+		w.slots[w.oldest] = input
+		w.oldest = (w.oldest + 1) % len(w.slots)
+	}
+
+	assignment, err := w.assign(w.slots, w.oldest)
+	if err != nil {
+		return nil, nil, err
+	}
+	fullWitness, err := frontend.NewWitness(assignment, w.ccs.Field())
+	if err != nil {
+		return nil, nil, err
+	}
+	publicWitness, err := fullWitness.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := w.prove(fullWitness)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, publicWitness, nil
+}
+
+// Close releases the device buffers kept between the proofs.
+func (w *SlidingWindow[T]) Close() error {
+	return w.close()
+}
@@ -0,0 +1,64 @@
+package groth16_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// windowSumCircuit proves the sum of the inputs of a window, kept by slot.
+type windowSumCircuit struct {
+	Slots [3]frontend.Variable
+	Sum   frontend.Variable `gnark:",public"`
+}
+
+func (c *windowSumCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Add(c.Slots[0], c.Slots[1], c.Slots[2]), c.Sum)
+	return nil
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &windowSumCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	window, err := groth16.NewSlidingWindow(ccs, pk, 3, func(slots []int, oldest int) (frontend.Circuit, error) {
+		return &windowSumCircuit{
+			Slots: [3]frontend.Variable{slots[0], slots[1], slots[2]},
+			Sum:   slots[0] + slots[1] + slots[2],
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer window.Close()
+
+	nbProofs := 0
+	for i := 1; i <= 6; i++ {
+		proof, publicWitness, err := window.Push(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 3 {
+			if proof != nil {
+				t.Fatalf("unexpected proof of a partial window after %d inputs", i)
+			}
+			continue
+		}
+		if err = groth16.Verify(proof, vk, publicWitness); err != nil {
+			t.Fatal(err)
+		}
+		nbProofs++
+	}
+	if nbProofs != 4 {
+		t.Fatalf("expected 4 proofs, got %d", nbProofs)
+	}
+}
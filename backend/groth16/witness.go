@@ -0,0 +1,27 @@
+package groth16
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// PublicWitnessFromJSON reads the public witness of a proof verified with vk, encoded with
+// Witness.ToJSON for the circuit described by the schema s, so that the verification services
+// don't need the Go code of the circuit, only its schema (a schema.Schema encodes to JSON). The
+// input is validated as by witness.FromJSON; its secret values, if any, are dropped.
+//
+// The number of public values of the schema must be the one vk expects (see
+// VerifyingKey.NbPublicWitness): the commitment wires aren't part of the public witness, as
+// the verifier computes them from the proof.
+func PublicWitnessFromJSON(vk VerifyingKey, s *schema.Schema, data []byte) (witness.Witness, error) {
+	if s.NbPublic != vk.NbPublicWitness() {
+		return nil, fmt.Errorf("%w: the schema has %d public values, the verifying key expects %d", witness.ErrInvalidWitness, s.NbPublic, vk.NbPublicWitness())
+	}
+	w, err := witness.FromJSON(vk.CurveID().ScalarField(), s, data)
+	if err != nil {
+		return nil, err
+	}
+	return w.Public()
+}
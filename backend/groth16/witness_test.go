@@ -0,0 +1,82 @@
+package groth16_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// committedCircuit commits to a secret and a public variable
+type committedCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *committedCircuit) Define(api frontend.API) error {
+	commitment, err := api.Compiler().(frontend.Committer).Commit(c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, c.X)
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestPublicWitnessFromJSON(t *testing.T) {
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &committedCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			assert.Equal(1, vk.NbPublicWitness(), "the commitment wire isn't part of the public witness")
+
+			assignment := &committedCircuit{X: 3, Y: 9}
+			w, err := frontend.NewWitness(assignment, curve.ScalarField())
+			assert.NoError(err)
+			proof, err := groth16.Prove(ccs, pk, w)
+			assert.NoError(err)
+
+			// the verification service only has the schema, encoded in JSON
+			s, err := frontend.NewSchema(assignment)
+			assert.NoError(err)
+			b, err := json.Marshal(s)
+			assert.NoError(err)
+			var decoded schema.Schema
+			assert.NoError(json.Unmarshal(b, &decoded))
+
+			for _, publicOnly := range []bool{false, true} {
+				toEncode := w
+				if publicOnly {
+					toEncode, err = w.Public()
+					assert.NoError(err)
+				}
+				data, err := toEncode.ToJSON(s)
+				assert.NoError(err)
+				public, err := groth16.PublicWitnessFromJSON(vk, &decoded, data)
+				assert.NoError(err)
+				assert.NoError(groth16.Verify(proof, vk, public))
+			}
+
+			public, err := groth16.PublicWitnessFromJSON(vk, &decoded, []byte(`{"Y": 10}`))
+			assert.NoError(err)
+			assert.Error(groth16.Verify(proof, vk, public))
+
+			// a schema counting the commitment wire as a public value
+			withCommitment := decoded
+			withCommitment.Fields = append(withCommitment.Fields, schema.Field{Name: "Commitment", FullName: "Commitment", Visibility: schema.Public})
+			withCommitment.NbPublic++
+			_, err = groth16.PublicWitnessFromJSON(vk, &withCommitment, []byte(`{"Y": 9, "Commitment": 1}`))
+			assert.ErrorIs(err, witness.ErrInvalidWitness)
+		})
+	}
+}
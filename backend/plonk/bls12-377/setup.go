@@ -121,8 +121,12 @@ func Setup(spr *cs.SparseR1CS, kzgSrs kzg.SRS) (*ProvingKey, *VerifyingKey, erro
 	var pk ProvingKey
 	var vk VerifyingKey
 	pk.Vk = &vk
-	if spr.CommitmentInfo.Is() {
-		vk.CommitmentConstraintIndexes = []uint64{uint64(spr.CommitmentInfo.CommitmentIndex)}
+	commitmentInfo, err := spr.CommitmentInfo.Single()
+	if err != nil {
+		return nil, nil, err
+	}
+	if commitmentInfo.Is() {
+		vk.CommitmentConstraintIndexes = []uint64{uint64(commitmentInfo.CommitmentIndex)}
 	}
 	// nbConstraints := len(spr.Constraints)
 
@@ -158,7 +162,7 @@ func Setup(spr *cs.SparseR1CS, kzgSrs kzg.SRS) (*ProvingKey, *VerifyingKey, erro
 	// we save lqk before, because the prover needs to complete it in Lagrange form, and
 	// then express it on the Lagrange coset basis.
 	pk.lQk = pk.trace.Qk.Clone() // it will be completed by the prover, and the evaluated on the coset
-	err := commitTrace(&pk.trace, &pk)
+	err = commitTrace(&pk.trace, &pk)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -249,8 +253,10 @@ func BuildTrace(spr *cs.SparseR1CS, pt *Trace) {
 		j++
 	}
 
-	for _, committed := range spr.CommitmentInfo.Committed {
-		qcp[offset+committed].SetOne()
+	for i := range spr.CommitmentInfo {
+		for _, committed := range spr.CommitmentInfo[i].Committed {
+			qcp[offset+committed].SetOne()
+		}
 	}
 
 	lagReg := iop.Form{Basis: iop.Lagrange, Layout: iop.Regular}
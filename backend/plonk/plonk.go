@@ -151,7 +151,12 @@ func Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.W
 }
 
 // Verify verifies a PLONK proof, from the proof, preprocessed public data, and public witness.
-func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+//
+// The options only exist for the compatibility with upstream gnark, see backend.VerifierOption.
+func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
+	if _, err := backend.NewVerifierConfig(opts...); err != nil {
+		return err
+	}
 
 	switch _proof := proof.(type) {
 
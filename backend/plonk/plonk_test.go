@@ -8,6 +8,7 @@ import (
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
@@ -50,6 +51,10 @@ func TestProver(t *testing.T) {
 
 			assert.True(bytes.Equal(b1.Bytes(), b2.Bytes()), "plonk prover mutated the proving key")
 
+			// the precomputed commitments are only supported by the groth16 prover of BN254
+			_, err = plonk.Prove(ccs, pk, fullWitness, backend.WithCommitment([]byte{1}))
+			assert.ErrorIs(err, backend.ErrNotSupported)
+
 			err = plonk.Verify(proof, vk, publicWitness)
 			assert.NoError(err)
 
@@ -18,6 +18,7 @@ package plonkfri
 
 import (
 	"crypto/sha256"
+	"fmt"
 	"math/big"
 	"math/bits"
 	"runtime"
@@ -73,6 +74,9 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	if err != nil {
 		return nil, err
 	}
+	if opt.Commitment != nil {
+		return nil, fmt.Errorf("%w: precomputed commitment on %s", backend.ErrNotSupported, spr.CurveID())
+	}
 
 	var proof Proof
 
@@ -0,0 +1,167 @@
+// Package scheduler admits proving jobs on a device with a bounded memory.
+//
+// Concurrent proofs share the memory of the device; when their footprints exceed it, the
+// allocations fail in whichever proof happens to allocate last. A Scheduler admits a job only
+// when its footprint fits in the memory left by the running jobs, and queues the others by
+// priority class.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+)
+
+// Priority is the priority class of a job. Jobs of a higher class are admitted first.
+type Priority int
+
+const (
+	Low Priority = iota
+	Normal
+	High
+
+	nbPriorities = int(High) + 1
+)
+
+// ErrTooLarge is returned for jobs whose footprint exceeds the capacity of the scheduler,
+// which would never be admitted.
+var ErrTooLarge = errors.New("scheduler: job footprint exceeds the device memory")
+
+// Scheduler admits jobs as long as the sum of their footprints is at most its capacity.
+//
+// The queued jobs are admitted by priority class, and in order of arrival within a class. A
+// job is not admitted before the jobs queued ahead of it, even if it fits and they don't, so
+// that large jobs are not starved by small ones.
+type Scheduler struct {
+	capacity int64
+
+	lock   sync.Mutex
+	used   int64
+	queues [nbPriorities][]*waiter
+}
+
+type waiter struct {
+	footprint int64
+	admitted  chan struct{}
+}
+
+// New returns a scheduler for a device with capacity bytes of memory available to the jobs.
+func New(capacity int64) *Scheduler {
+	if capacity <= 0 {
+		panic("scheduler: capacity must be positive")
+	}
+	return &Scheduler{capacity: capacity}
+}
+
+// Acquire waits until a job of the given footprint, in bytes, is admitted, and returns the
+// function to call when the job is done. It returns an error if ctx is done before the job is
+// admitted, or ErrTooLarge.
+func (s *Scheduler) Acquire(ctx context.Context, footprint int64, priority Priority) (release func(), err error) {
+	if priority < Low || priority > High {
+		return nil, fmt.Errorf("scheduler: invalid priority %d", priority)
+	}
+	if footprint < 0 {
+		return nil, errors.New("scheduler: negative footprint")
+	}
+	if footprint > s.capacity {
+		return nil, ErrTooLarge
+	}
+
+	w := &waiter{footprint: footprint, admitted: make(chan struct{})}
+	s.lock.Lock()
+	s.queues[priority] = append(s.queues[priority], w)
+	s.admit()
+	s.lock.Unlock()
+
+	select {
+	case <-w.admitted:
+		return s.releaser(footprint), nil
+	case <-ctx.Done():
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	select {
+	case <-w.admitted:
+		// admitted in the meantime
+		s.used -= footprint
+	default:
+		q := s.queues[priority]
+		for i := range q {
+			if q[i] == w {
+				s.queues[priority] = append(q[:i], q[i+1:]...)
+				break
+			}
+		}
+	}
+	// the jobs behind this one may fit
+	s.admit()
+	return nil, ctx.Err()
+}
+
+// Prove proves the constraint system with groth16.Prove once admitted. footprint is the
+// device memory needed by the proof, in bytes, as estimated by groth16.EstimateMemory
+// (DeviceProof).
+func (s *Scheduler) Prove(ctx context.Context, footprint int64, priority Priority, r1cs constraint.ConstraintSystem, pk groth16.ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (groth16.Proof, error) {
+	release, err := s.Acquire(ctx, footprint, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return groth16.Prove(r1cs, pk, fullWitness, opts...)
+}
+
+// Stats is a snapshot of the state of a scheduler.
+type Stats struct {
+	// Capacity is the memory available to the jobs, and Used the sum of the footprints of the
+	// admitted jobs, in bytes.
+	Capacity, Used int64
+
+	// Queued is the number of jobs waiting, by priority class.
+	Queued [nbPriorities]int
+}
+
+// Stats returns the state of the scheduler.
+func (s *Scheduler) Stats() Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	st := Stats{Capacity: s.capacity, Used: s.used}
+	for p := range s.queues {
+		st.Queued[p] = len(s.queues[p])
+	}
+	return st
+}
+
+// admit admits the jobs at the head of the queues, by priority, as long as they fit.
+// s.lock must be held.
+func (s *Scheduler) admit() {
+	for p := nbPriorities - 1; p >= 0; p-- {
+		for len(s.queues[p]) > 0 {
+			w := s.queues[p][0]
+			if s.used+w.footprint > s.capacity {
+				return
+			}
+			s.used += w.footprint
+			close(w.admitted)
+			s.queues[p] = s.queues[p][1:]
+		}
+	}
+}
+
+func (s *Scheduler) releaser(footprint int64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.lock.Lock()
+			s.used -= footprint
+			s.admit()
+			s.lock.Unlock()
+		})
+	}
+}
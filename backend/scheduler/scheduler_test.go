@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts an Acquire and returns the channel receiving its release function once
+// the job is admitted.
+func acquireAsync(s *Scheduler, ctx context.Context, footprint int64, priority Priority) <-chan func() {
+	res := make(chan func(), 1)
+	go func() {
+		release, err := s.Acquire(ctx, footprint, priority)
+		if err == nil {
+			res <- release
+		}
+	}()
+	return res
+}
+
+// waitQueued waits until n jobs are queued with the given priority.
+func waitQueued(t *testing.T, s *Scheduler, priority Priority, n int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if s.Stats().Queued[priority] == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued jobs with priority %d, got %d", n, priority, s.Stats().Queued[priority])
+}
+
+func assertPending(t *testing.T, c <-chan func()) {
+	t.Helper()
+	select {
+	case <-c:
+		t.Fatal("job admitted beyond the capacity")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := New(10)
+	ctx := context.Background()
+
+	if _, err := s.Acquire(ctx, 11, Normal); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+
+	release, err := s.Acquire(ctx, 6, Normal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// low then high priority jobs, which don't fit
+	low := acquireAsync(s, ctx, 5, Low)
+	waitQueued(t, s, Low, 1)
+	high := acquireAsync(s, ctx, 5, High)
+	waitQueued(t, s, High, 1)
+	assertPending(t, low)
+	assertPending(t, high)
+
+	// the high priority job is admitted first, and the low priority one fits with it
+	release()
+	releaseHigh := <-high
+	releaseLow := <-low
+	if st := s.Stats(); st.Used != 10 {
+		t.Fatalf("expected 10 bytes used, got %d", st.Used)
+	}
+
+	// a small job doesn't overtake a large one queued ahead of it
+	large := acquireAsync(s, ctx, 10, Normal)
+	waitQueued(t, s, Normal, 1)
+	small := acquireAsync(s, ctx, 1, Normal)
+	waitQueued(t, s, Normal, 2)
+	releaseHigh()
+	assertPending(t, small)
+	releaseLow()
+	releaseLow() // no-op
+	releaseLarge := <-large
+	assertPending(t, small)
+	releaseLarge()
+	(<-small)()
+
+	if st := s.Stats(); st.Used != 0 || st.Queued != [nbPriorities]int{} {
+		t.Fatalf("unexpected final state %+v", st)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := New(10)
+	release, err := s.Acquire(context.Background(), 10, Normal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := s.Acquire(ctx, 10, High)
+		errs <- err
+	}()
+	waitQueued(t, s, High, 1)
+
+	// the job queued behind the cancelled one is admitted in its place
+	next := acquireAsync(s, context.Background(), 10, Normal)
+	waitQueued(t, s, Normal, 1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	release()
+	(<-next)()
+
+	if st := s.Stats(); st.Used != 0 {
+		t.Fatalf("expected no memory used, got %d", st.Used)
+	}
+}
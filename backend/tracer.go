@@ -0,0 +1,128 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensys/gnark/internal/nvtx"
+	"github.com/rs/zerolog"
+)
+
+// Stage is a stage of a proof, reported to a Tracer.
+type Stage uint8
+
+const (
+	StageProve Stage = iota
+	StageSolve
+	StageComputeH
+	StageCopyToDevice
+	StageINTT
+	StageNTT
+	StagePolyOps
+	StageMSMG1
+	StageMSMG2
+)
+
+// String returns the name of the stage.
+func (s Stage) String() string {
+	switch s {
+	case StageProve:
+		return "prove"
+	case StageSolve:
+		return "solve"
+	case StageComputeH:
+		return "computeH"
+	case StageCopyToDevice:
+		return "copy to device"
+	case StageINTT:
+		return "INTT"
+	case StageNTT:
+		return "NTT"
+	case StagePolyOps:
+		return "PolyOps"
+	case StageMSMG1:
+		return "MSM G1"
+	case StageMSMG2:
+		return "MSM G2"
+	default:
+		return "unknown"
+	}
+}
+
+// StageMetadata describes an occurrence of a stage.
+type StageMetadata struct {
+	// Label distinguishes the occurrences of a stage within a proof, for instance "BS1" or
+	// "KRS" for a multi-exponentiation, or "a" for the INTT of the vector a.
+	Label string
+
+	// Size is the number of elements processed by the stage, zero if not relevant.
+	Size int
+}
+
+// Tracer receives the stages of the provers which support it (for now, the GPU groth16
+// provers), for example to record them as OpenTelemetry spans. Stages may run
+// concurrently: the context returned by OnStageStart, which is passed to OnStageEnd and to
+// the start of the nested stages, allows to match them.
+type Tracer interface {
+	OnStageStart(ctx context.Context, stage Stage, meta StageMetadata) context.Context
+	OnStageEnd(ctx context.Context, stage Stage, meta StageMetadata, elapsed time.Duration)
+}
+
+// WithTracer sets the tracer of the prover stages. By default, they are logged at debug
+// level by LogTracer.
+func WithTracer(tracer Tracer) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Tracer = tracer
+		return nil
+	}
+}
+
+// LogTracer returns a Tracer logging the stages with their duration at debug level, or nil
+// if the debug level is disabled.
+func LogTracer(log zerolog.Logger) Tracer {
+	if log.GetLevel() > zerolog.DebugLevel || zerolog.GlobalLevel() > zerolog.DebugLevel {
+		return nil
+	}
+	return logTracer{log}
+}
+
+type logTracer struct {
+	log zerolog.Logger
+}
+
+func (t logTracer) OnStageStart(ctx context.Context, _ Stage, _ StageMetadata) context.Context {
+	return ctx
+}
+
+func (t logTracer) OnStageEnd(_ context.Context, stage Stage, meta StageMetadata, elapsed time.Duration) {
+	e := t.log.Debug().Str("stage", stage.String())
+	if meta.Label != "" {
+		e = e.Str("label", meta.Label)
+	}
+	if meta.Size != 0 {
+		e = e.Int("size", meta.Size)
+	}
+	e.Dur("took", elapsed).Msg("prover stage done")
+}
+
+// TraceStage reports the start of a stage to the tracer (which may be nil) and to NVTX, and
+// returns the context of the stage and the function reporting its end.
+func TraceStage(ctx context.Context, tracer Tracer, stage Stage, meta StageMetadata) (context.Context, func()) {
+	if tracer == nil && !nvtx.Enabled() {
+		return ctx, func() {}
+	}
+	name := stage.String()
+	if meta.Label != "" {
+		name += " " + meta.Label
+	}
+	endRange := nvtx.Range(name)
+	if tracer == nil {
+		return ctx, endRange
+	}
+	ctx = tracer.OnStageStart(ctx, stage, meta)
+	start := time.Now()
+	return ctx, func() {
+		tracer.OnStageEnd(ctx, stage, meta, time.Since(start))
+		endRange()
+	}
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type stageKey struct{}
+
+// recordingTracer records the stages, and the stage each one is nested in.
+type recordingTracer struct {
+	started, ended []string
+	parents        []Stage
+}
+
+func (t *recordingTracer) OnStageStart(ctx context.Context, stage Stage, meta StageMetadata) context.Context {
+	parent, ok := ctx.Value(stageKey{}).(Stage)
+	if !ok {
+		parent = stage
+	}
+	t.started = append(t.started, stage.String()+"/"+meta.Label)
+	t.parents = append(t.parents, parent)
+	return context.WithValue(ctx, stageKey{}, stage)
+}
+
+func (t *recordingTracer) OnStageEnd(ctx context.Context, stage Stage, meta StageMetadata, _ time.Duration) {
+	if ctx.Value(stageKey{}) != stage {
+		panic("context of another stage")
+	}
+	t.ended = append(t.ended, stage.String()+"/"+meta.Label)
+}
+
+func TestTraceStage(t *testing.T) {
+	var tracer recordingTracer
+	ctx, endProve := TraceStage(context.Background(), &tracer, StageProve, StageMetadata{})
+	_, endMSM := TraceStage(ctx, &tracer, StageMSMG1, StageMetadata{Label: "BS1", Size: 10})
+	endMSM()
+	endProve()
+
+	if len(tracer.started) != 2 || tracer.started[1] != "MSM G1/BS1" || tracer.parents[1] != StageProve {
+		t.Fatalf("unexpected stages started: %v, parents %v", tracer.started, tracer.parents)
+	}
+	if len(tracer.ended) != 2 || tracer.ended[0] != "MSM G1/BS1" || tracer.ended[1] != "prove/" {
+		t.Fatalf("unexpected stages ended: %v", tracer.ended)
+	}
+
+	// without tracer, stages are no-ops
+	ctx, end := TraceStage(context.Background(), nil, StageSolve, StageMetadata{})
+	end()
+	if ctx != context.Background() {
+		t.Fatal("context changed without tracer")
+	}
+}
+
+func TestLogTracer(t *testing.T) {
+	if LogTracer(zerolog.Nop()) != nil {
+		t.Fatal("disabled logger should give a nil tracer")
+	}
+	if LogTracer(zerolog.New(nil).Level(zerolog.InfoLevel)) != nil {
+		t.Fatal("debug level disabled should give a nil tracer")
+	}
+	if LogTracer(zerolog.New(nil).Level(zerolog.DebugLevel)) == nil {
+		t.Fatal("debug level enabled should give a tracer")
+	}
+}
@@ -0,0 +1,277 @@
+// Package columnar assigns bulk tabular data (CSV files, or any source of records such as a
+// Parquet or Arrow reader) to the array-shaped fields of a circuit assignment. The Parquet files
+// are read by the parquet sub-package, a module of its own.
+//
+// Each row of the table is an element of the arrays: with
+//
+//	type Circuit struct {
+//		Accounts [1 << 16]struct {
+//			Address, Balance frontend.Variable
+//		}
+//		Total frontend.Variable `gnark:",public"`
+//	}
+//
+// and the columns mapping {"address": "Accounts.Address", "balance": "Accounts.Balance"}, the
+// value of the column "balance" in the i-th row is assigned to Accounts[i].Balance.
+//
+// Records are read sequentially but parsed and converted to field elements in parallel, which
+// is where most of the time goes for large tables.
+package columnar
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// RecordReader reads a table row by row, each record being the values of the row in the
+// order of the columns. The first record is the header, holding the column names.
+//
+// *csv.Reader implements RecordReader.
+type RecordReader interface {
+	Read() (record []string, err error)
+}
+
+// Option configures Load.
+type Option func(*config) error
+
+type config struct {
+	nbWorkers int
+	batchSize int
+}
+
+// WithNbWorkers sets the number of goroutines parsing the values. Defaults to
+// runtime.NumCPU().
+func WithNbWorkers(nbWorkers int) Option {
+	return func(c *config) error {
+		if nbWorkers < 1 {
+			return fmt.Errorf("invalid number of workers %d", nbWorkers)
+		}
+		c.nbWorkers = nbWorkers
+		return nil
+	}
+}
+
+// LoadCSV is Load reading CSV records from r, with a header line.
+func LoadCSV(field *big.Int, assignment frontend.Circuit, r io.Reader, columns map[string]string, opts ...Option) (int, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	return Load(field, assignment, reader, columns, opts...)
+}
+
+// Load reads the records of r and assigns the values of the columns to the fields of the
+// assignment given by columns, a map from column name to field path. A field path is the
+// dot-separated list of the Go field names from the assignment to a frontend.Variable, and
+// must go through an array or a slice, indexed by the row number. Columns which are not in
+// columns are ignored.
+//
+// Values are integers in [0, field), in base 10 or prefixed by 0x for base 16 (see
+// big.Int.SetString with base 0). The arrays and slices must be large enough for all the rows;
+// their elements beyond the number of rows are left untouched. Load returns the number of rows
+// read.
+func Load(field *big.Int, assignment frontend.Circuit, r RecordReader, columns map[string]string, opts ...Option) (int, error) {
+	cfg := config{nbWorkers: runtime.NumCPU(), batchSize: 1024}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return 0, err
+		}
+	}
+
+	v := reflect.ValueOf(assignment)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0, errors.New("assignment must be a non-nil pointer")
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return 0, fmt.Errorf("reading header: %w", err)
+	}
+	targets, err := resolveColumns(header, columns, v)
+	if err != nil {
+		return 0, err
+	}
+
+	// records are read here and parsed by the workers, by batches
+	type batch struct {
+		row     int // number of the first row
+		records [][]string
+	}
+	batches := make(chan batch, cfg.nbWorkers)
+	var (
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+		errRow   int
+	)
+	setErr := func(row int, err error) {
+		errLock.Lock()
+		if firstErr == nil || row < errRow {
+			firstErr, errRow = err, row
+		}
+		errLock.Unlock()
+	}
+	for w := 0; w < cfg.nbWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				for i, record := range b.records {
+					if err := assignRecord(field, targets, b.row+i, record); err != nil {
+						setErr(b.row+i, err)
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	nbRows := 0
+	var readErr error
+	current := batch{records: make([][]string, 0, cfg.batchSize)}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("reading row %d: %w", nbRows, err)
+			break
+		}
+		current.records = append(current.records, record)
+		nbRows++
+		if len(current.records) == cfg.batchSize {
+			batches <- current
+			current = batch{row: nbRows, records: make([][]string, 0, cfg.batchSize)}
+		}
+	}
+	if len(current.records) != 0 {
+		batches <- current
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nbRows, firstErr
+	}
+	return nbRows, readErr
+}
+
+// target is a column assigned to a field of the assignment.
+type target struct {
+	column int
+	name   string
+	path   []string
+	root   reflect.Value
+}
+
+func resolveColumns(header []string, columns map[string]string, root reflect.Value) ([]target, error) {
+	targets := make([]target, 0, len(columns))
+	for i, name := range header {
+		path, ok := columns[name]
+		if !ok {
+			continue
+		}
+		t := target{column: i, name: name, path: strings.Split(path, "."), root: root}
+		// check the path on the first row, to report mapping errors before reading the table
+		if _, err := t.resolve(0); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) != len(columns) {
+		for name := range columns {
+			if !contains(header, name) {
+				return nil, fmt.Errorf("column %q not found", name)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// resolve returns the frontend.Variable of the row in the assignment.
+func (t *target) resolve(row int) (reflect.Value, error) {
+	v := t.root
+	path := t.path
+	indexed := false
+	for {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("column %q: nil pointer in %s", t.name, strings.Join(t.path, "."))
+			}
+			v = v.Elem()
+			continue
+		case reflect.Array, reflect.Slice:
+			if !indexed {
+				if row >= v.Len() {
+					return reflect.Value{}, fmt.Errorf("column %q: row %d exceeds the length %d of %s", t.name, row, v.Len(), strings.Join(t.path, "."))
+				}
+				v = v.Index(row)
+				indexed = true
+				continue
+			}
+		}
+		if len(path) == 0 {
+			break
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("column %q: %s is not a field path", t.name, strings.Join(t.path, "."))
+		}
+		f := v.FieldByName(path[0])
+		if !f.IsValid() {
+			return reflect.Value{}, fmt.Errorf("column %q: unknown field %s", t.name, path[0])
+		}
+		v = f
+		path = path[1:]
+	}
+
+	if !indexed {
+		return reflect.Value{}, fmt.Errorf("column %q: %s is not in an array or a slice", t.name, strings.Join(t.path, "."))
+	}
+	if v.Type() != tVariable || !v.CanSet() {
+		return reflect.Value{}, fmt.Errorf("column %q: %s is not a settable frontend.Variable", t.name, strings.Join(t.path, "."))
+	}
+	return v, nil
+}
+
+// assignRecord sets the values of the record in the assignment.
+func assignRecord(field *big.Int, targets []target, row int, record []string) error {
+	for i := range targets {
+		t := &targets[i]
+		if t.column >= len(record) {
+			return fmt.Errorf("row %d: missing column %q", row, t.name)
+		}
+		value, ok := new(big.Int).SetString(strings.TrimSpace(record[t.column]), 0)
+		if !ok {
+			return fmt.Errorf("row %d, column %q: invalid value %q", row, t.name, record[t.column])
+		}
+		if value.Sign() < 0 || value.Cmp(field) >= 0 {
+			return fmt.Errorf("row %d, column %q: value out of range [0, field)", row, t.name)
+		}
+		v, err := t.resolve(row)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
+
+var tVariable = reflect.TypeOf((*frontend.Variable)(nil)).Elem()
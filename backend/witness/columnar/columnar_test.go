@@ -0,0 +1,64 @@
+package columnar
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type airdropCircuit struct {
+	Accounts [5]struct {
+		Address, Balance frontend.Variable
+	}
+	Proofs []frontend.Variable
+	Total  frontend.Variable `gnark:",public"`
+}
+
+func (c *airdropCircuit) Define(api frontend.API) error {
+	return nil
+}
+
+func TestLoadCSV(t *testing.T) {
+	assert := require.New(t)
+	field := ecc.BN254.ScalarField()
+	columns := map[string]string{
+		"address": "Accounts.Address",
+		"balance": "Accounts.Balance",
+		"proof":   "Proofs",
+	}
+
+	const table = `address,comment,balance,proof
+0x01,first,10,7
+0x02,"second, quoted", 20,8
+0x03,,30,9
+`
+	for _, nbWorkers := range []int{1, 3} {
+		assignment := airdropCircuit{Proofs: make([]frontend.Variable, 3)}
+		nbRows, err := LoadCSV(field, &assignment, strings.NewReader(table), columns, WithNbWorkers(nbWorkers))
+		assert.NoError(err)
+		assert.Equal(3, nbRows)
+		for i := 0; i < nbRows; i++ {
+			assert.Equal(big.NewInt(int64(i+1)), assignment.Accounts[i].Address)
+			assert.Equal(big.NewInt(int64(10*(i+1))), assignment.Accounts[i].Balance)
+			assert.Equal(big.NewInt(int64(i+7)), assignment.Proofs[i])
+		}
+		assert.Nil(assignment.Accounts[3].Balance, "elements beyond the rows are untouched")
+	}
+
+	load := func(table string, columns map[string]string) error {
+		assignment := airdropCircuit{Proofs: make([]frontend.Variable, 2)}
+		_, err := LoadCSV(field, &assignment, strings.NewReader(table), columns)
+		return err
+	}
+	assert.ErrorContains(load("address,balance\n1,2\n", map[string]string{"unknown": "Total"}), `column "unknown" not found`)
+	assert.ErrorContains(load("total\n1\n", map[string]string{"total": "Total"}), "not in an array or a slice")
+	assert.ErrorContains(load("b\n1\n", map[string]string{"b": "Accounts.Amount"}), "unknown field Amount")
+	assert.ErrorContains(load("p\n1\n2\n3\n", map[string]string{"p": "Proofs"}), "row 2 exceeds the length 2 of Proofs")
+	assert.ErrorContains(load("b\n1\nx\n", map[string]string{"b": "Accounts.Balance"}), "invalid value")
+	assert.ErrorContains(load("b\n1\n-1\n", map[string]string{"b": "Accounts.Balance"}), "out of range")
+	assert.ErrorContains(load("b\n"+field.String()+"\n", map[string]string{"b": "Accounts.Balance"}), "out of range")
+}
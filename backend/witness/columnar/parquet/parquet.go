@@ -0,0 +1,177 @@
+// Package parquet reads the Parquet files with the columnar package, to assign bulk tabular data
+// to the array-shaped fields of a circuit assignment.
+//
+// It is a module of its own, so that the gnark module doesn't depend on the Parquet library:
+//
+//	go get github.com/consensys/gnark/backend/witness/columnar/parquet
+//
+// The columns are the leaf columns of the schema, named by their path joined by dots, and each
+// row of the file is a record of columnar.Load. The values are converted to integers as
+// follows:
+//
+//   - the booleans to 0 or 1, and the integers to their value, unsigned with the unsigned
+//     integer logical types;
+//   - the strings (UTF8 byte arrays) are parsed as the values of a CSV file, in base 10 or
+//     prefixed by 0x for base 16;
+//   - the other byte arrays, of fixed length or not, are big-endian unsigned integers, such as
+//     the 32 bytes of a hash or of a uint256.
+//
+// The repeated columns, the floating point numbers, the INT96 and decimal columns, and the
+// null values are rejected.
+package parquet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/backend/witness/columnar"
+	"github.com/consensys/gnark/frontend"
+	"github.com/parquet-go/parquet-go"
+)
+
+// batchSize is the number of rows read from the file at once.
+const batchSize = 256
+
+// Reader is a columnar.RecordReader of the rows of a Parquet file: its first record is the
+// header, the paths of the leaf columns.
+type Reader struct {
+	rows    *parquet.Reader
+	header  []string
+	leaves  []column
+	started bool
+
+	batch []parquet.Row
+	next  int // the index in batch of the next row
+	err   error
+}
+
+// column is a leaf column of the schema.
+type column struct {
+	name     string
+	kind     parquet.Kind
+	unsigned bool // the integers are unsigned
+	text     bool // the byte arrays are strings
+}
+
+// NewReader returns a Reader of the Parquet file of size bytes of r.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	f, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+	schema := f.Schema()
+	reader := &Reader{rows: parquet.NewReader(f)}
+	for _, path := range schema.Columns() {
+		leaf, _ := schema.Lookup(path...)
+		c := column{name: strings.Join(path, "."), kind: leaf.Node.Type().Kind()}
+		if leaf.MaxRepetitionLevel > 0 {
+			return nil, fmt.Errorf("column %q: repeated columns are not supported", c.name)
+		}
+		lt := leaf.Node.Type().LogicalType()
+		switch {
+		case c.kind == parquet.Float || c.kind == parquet.Double || c.kind == parquet.Int96:
+			return nil, fmt.Errorf("column %q: %s values are not supported", c.name, c.kind)
+		case lt != nil && lt.Decimal != nil:
+			return nil, fmt.Errorf("column %q: decimal values are not supported", c.name)
+		case lt != nil && lt.Integer != nil:
+			c.unsigned = !lt.Integer.IsSigned
+		case lt != nil && lt.UTF8 != nil:
+			c.text = true
+		}
+		reader.header = append(reader.header, c.name)
+		reader.leaves = append(reader.leaves, c)
+	}
+	return reader, nil
+}
+
+// Read implements columnar.RecordReader.
+func (r *Reader) Read() ([]string, error) {
+	if !r.started {
+		r.started = true
+		return append([]string(nil), r.header...), nil
+	}
+	for r.next == len(r.batch) {
+		if r.err != nil {
+			return nil, r.err
+		}
+		if r.batch == nil {
+			r.batch = make([]parquet.Row, batchSize)
+		}
+		n, err := r.rows.ReadRows(r.batch[:cap(r.batch)])
+		r.batch, r.next, r.err = r.batch[:n], 0, err
+		if n == 0 && err == nil {
+			r.err = io.ErrNoProgress
+		}
+	}
+	row := r.batch[r.next]
+	r.next++
+	return r.record(row)
+}
+
+// record returns the values of the row in the order of the columns.
+func (r *Reader) record(row parquet.Row) ([]string, error) {
+	record := make([]string, len(r.leaves))
+	set := make([]bool, len(r.leaves))
+	for _, v := range row {
+		i := v.Column()
+		if i < 0 || i >= len(r.leaves) || set[i] {
+			return nil, errors.New("unexpected value in the row")
+		}
+		c := &r.leaves[i]
+		if v.IsNull() {
+			return nil, fmt.Errorf("column %q: null value", c.name)
+		}
+		record[i], set[i] = c.format(v), true
+	}
+	return record, nil
+}
+
+// format returns the integer of the value, in base 10 or 16.
+func (c *column) format(v parquet.Value) string {
+	switch c.kind {
+	case parquet.Boolean:
+		if v.Boolean() {
+			return "1"
+		}
+		return "0"
+	case parquet.Int32:
+		if c.unsigned {
+			return strconv.FormatUint(uint64(v.Uint32()), 10)
+		}
+		return strconv.FormatInt(int64(v.Int32()), 10)
+	case parquet.Int64:
+		if c.unsigned {
+			return strconv.FormatUint(v.Uint64(), 10)
+		}
+		return strconv.FormatInt(v.Int64(), 10)
+	default:
+		b := v.ByteArray()
+		if c.text {
+			return string(b)
+		}
+		if len(b) == 0 {
+			return "0"
+		}
+		return "0x" + hex.EncodeToString(b)
+	}
+}
+
+// Close releases the file.
+func (r *Reader) Close() error {
+	return r.rows.Close()
+}
+
+// Load is columnar.Load reading the rows of the Parquet file of size bytes of r.
+func Load(field *big.Int, assignment frontend.Circuit, r io.ReaderAt, size int64, columns map[string]string, opts ...columnar.Option) (int, error) {
+	reader, err := NewReader(r, size)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	return columnar.Load(field, assignment, reader, columns, opts...)
+}
@@ -0,0 +1,96 @@
+package parquet
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+type airdropCircuit struct {
+	Accounts [5]struct {
+		Address, Balance, Eligible frontend.Variable
+	}
+	Hashes []frontend.Variable
+	Total  frontend.Variable `gnark:",public"`
+}
+
+func (c *airdropCircuit) Define(api frontend.API) error {
+	return nil
+}
+
+type account struct {
+	Address  string `parquet:"address"`
+	Comment  string `parquet:"comment"`
+	Balance  uint64 `parquet:"balance"`
+	Eligible bool   `parquet:"eligible"`
+	Hash     []byte `parquet:"hash"`
+	Nonce    int32  `parquet:"nonce"`
+}
+
+func TestLoad(t *testing.T) {
+	assert := require.New(t)
+	field := ecc.BN254.ScalarField()
+	columns := map[string]string{
+		"address":  "Accounts.Address",
+		"balance":  "Accounts.Balance",
+		"eligible": "Accounts.Eligible",
+		"hash":     "Hashes",
+	}
+
+	hash := bytes.Repeat([]byte{0xff}, 31)
+	var buf bytes.Buffer
+	assert.NoError(parquet.Write(&buf, []account{
+		{Address: "0x01", Comment: "first", Balance: 1 << 63, Eligible: true, Hash: hash},
+		{Address: "2", Comment: "second, quoted", Balance: 20, Hash: []byte{1, 2}},
+		{Address: "0x03", Balance: 30, Hash: nil},
+	}))
+	data := buf.Bytes()
+
+	assignment := airdropCircuit{Hashes: make([]frontend.Variable, 3)}
+	nbRows, err := Load(field, &assignment, bytes.NewReader(data), int64(len(data)), columns)
+	assert.NoError(err)
+	assert.Equal(3, nbRows)
+	for i, balance := range []*big.Int{new(big.Int).Lsh(big.NewInt(1), 63), big.NewInt(20), big.NewInt(30)} {
+		assert.Equal(big.NewInt(int64(i+1)), assignment.Accounts[i].Address)
+		assert.Equal(balance, assignment.Accounts[i].Balance)
+	}
+	assert.Equal(big.NewInt(1), assignment.Accounts[0].Eligible)
+	assert.Equal(big.NewInt(0), assignment.Accounts[1].Eligible)
+	assert.Equal(new(big.Int).SetBytes(hash), assignment.Hashes[0])
+	assert.Equal(big.NewInt(0x0102), assignment.Hashes[1])
+	assert.Equal(big.NewInt(0), assignment.Hashes[2])
+	assert.Nil(assignment.Accounts[3].Balance, "elements beyond the rows are untouched")
+
+	// the values are checked by columnar.Load
+	buf.Reset()
+	assert.NoError(parquet.Write(&buf, []account{{Address: "x"}}))
+	data = buf.Bytes()
+	_, err = Load(field, &assignment, bytes.NewReader(data), int64(len(data)), columns)
+	assert.ErrorContains(err, "invalid value")
+
+	type floats struct {
+		Balance float64 `parquet:"balance"`
+	}
+	buf.Reset()
+	assert.NoError(parquet.Write(&buf, []floats{{Balance: 1}}))
+	data = buf.Bytes()
+	_, err = NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorContains(err, "DOUBLE values are not supported")
+
+	type repeated struct {
+		Balance []int64 `parquet:"balance,list"`
+	}
+	buf.Reset()
+	assert.NoError(parquet.Write(&buf, []repeated{{Balance: []int64{1}}}))
+	data = buf.Bytes()
+	_, err = NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorContains(err, "repeated columns are not supported")
+
+	_, err = NewReader(bytes.NewReader([]byte("not parquet")), 11)
+	assert.Error(err)
+}
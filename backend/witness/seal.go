@@ -0,0 +1,76 @@
+package witness
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// SealKeySize is the size of the keys of Seal and Open, those of AES-256.
+const SealKeySize = 32
+
+// sealVersion is the first byte of the sealed witnesses, for the future formats.
+const sealVersion = 1
+
+// ErrSealed is returned by Open for the sealed witnesses which can't be opened: a wrong key
+// or additional data, or a corrupted or truncated witness.
+var ErrSealed = errors.New("cannot open the sealed witness")
+
+// Seal encrypts the binary encoding of w (see MarshalBinary) with AES-256-GCM under key, so
+// that the witnesses stored in job queues or sent to the proving machines are confidential and
+// authenticated. The additional data, for instance the name of the circuit or the ID of the
+// job, isn't encrypted but must be given to Open, which binds the witness to it.
+//
+// The sealed witness is a version byte, a random nonce of 12 bytes and the ciphertext with its
+// tag. As the nonces are random, a key should seal less than 2³² witnesses.
+func Seal(w Witness, key, additionalData []byte) ([]byte, error) {
+	aead, err := newSealCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(data)+aead.Overhead())
+	res[0] = sealVersion
+	if _, err := rand.Read(res[1:]); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	return aead.Seal(res, res[1:], data, additionalData), nil
+}
+
+// Open decrypts the witness sealed by Seal with key and the additional data into w, created
+// with New for the field of the witness. It returns an error wrapping ErrSealed if the sealed
+// witness can't be authenticated.
+func Open(w Witness, key, sealed, additionalData []byte) error {
+	aead, err := newSealCipher(key)
+	if err != nil {
+		return err
+	}
+	if len(sealed) < 1+aead.NonceSize()+aead.Overhead() {
+		return fmt.Errorf("%w: too short", ErrSealed)
+	}
+	if sealed[0] != sealVersion {
+		return fmt.Errorf("%w: unknown version %d", ErrSealed, sealed[0])
+	}
+	nonce, ciphertext := sealed[1:1+aead.NonceSize()], sealed[1+aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSealed, err)
+	}
+	return w.UnmarshalBinary(data)
+}
+
+func newSealCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != SealKeySize {
+		return nil, fmt.Errorf("invalid key size %d, expected %d", len(key), SealKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,414 @@
+package witness
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// Format is the encoding of a witness read by NewFromReader.
+type Format uint8
+
+const (
+	// Binary is the encoding produced by Witness.WriteTo.
+	Binary Format = iota
+	// JSON is the encoding produced by Witness.ToJSON.
+	JSON
+)
+
+// NewFromReader reads a witness for the circuit described by the schema s from r.
+//
+// Unlike ReadFrom and FromJSON, the input is never held in memory: values are decoded as they
+// are read and set in the witness vector directly, which makes it suitable for very large
+// witnesses. The input is validated against the schema while reading:
+//   - the number of public and secret values must match the schema;
+//   - every value must be in [0, field);
+//   - in JSON, unknown or duplicated fields and arrays longer than in the schema are rejected.
+//
+// If the input holds no secret values at all, the returned witness is a public witness.
+func NewFromReader(field *big.Int, s *schema.Schema, r io.Reader, format Format) (Witness, error) {
+	v, err := newVector(field, s.NbPublic+s.NbSecret)
+	if err != nil {
+		return nil, err
+	}
+	w := &witness{
+		vector:   v,
+		nbPublic: uint32(s.NbPublic),
+		nbSecret: uint32(s.NbSecret),
+	}
+
+	switch format {
+	case Binary:
+		err = w.readBinaryStream(field, bufio.NewReader(r))
+	case JSON:
+		err = w.readJSONStream(field, s, r)
+	default:
+		err = fmt.Errorf("unknown witness format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// FromJSON reads a witness encoded with ToJSON for the circuit described by the schema s,
+// validating it as NewFromReader does. Unlike Witness.FromJSON, which reduces the values
+// modulo the field, the errors wrap ErrInvalidWitness and name the offending value, so that
+// the witnesses generated by other services, see schema.Schema.ToJSONSchema, can be fixed.
+func FromJSON(field *big.Int, s *schema.Schema, data []byte) (Witness, error) {
+	return NewFromReader(field, s, bytes.NewReader(data), JSON)
+}
+
+// readBinaryStream reads a witness encoded with WriteTo, checking the header against the
+// expected number of public and secret values.
+func (w *witness) readBinaryStream(field *big.Int, r io.Reader) error {
+	var buf [4]byte
+	readUint32 := func() (uint32, error) {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint32(buf[:]), nil
+	}
+
+	nbPublic, err := readUint32()
+	if err != nil {
+		return err
+	}
+	nbSecret, err := readUint32()
+	if err != nil {
+		return err
+	}
+	if nbPublic != w.nbPublic {
+		return fmt.Errorf("%w: expected %d public values, got %d", ErrInvalidWitness, w.nbPublic, nbPublic)
+	}
+	if nbSecret != w.nbSecret {
+		if nbSecret != 0 {
+			return fmt.Errorf("%w: expected %d secret values, got %d", ErrInvalidWitness, w.nbSecret, nbSecret)
+		}
+		// public witness
+		w.nbSecret = 0
+		w.vector = resize(w.vector, int(nbPublic))
+	}
+
+	n, err := readUint32()
+	if err != nil {
+		return err
+	}
+	if n != w.nbPublic+w.nbSecret {
+		return fmt.Errorf("%w: vector length %d doesn't match header (%d public, %d secret)", ErrInvalidWitness, n, w.nbPublic, w.nbSecret)
+	}
+
+	b := make([]byte, elementSize(w.vector))
+	var value big.Int
+	for i := 0; i < int(n); i++ {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return fmt.Errorf("reading value %d: %w", i, err)
+		}
+		value.SetBytes(b)
+		if value.Cmp(field) >= 0 {
+			return fmt.Errorf("%w: value %d is not reduced modulo the field", ErrInvalidWitness, i)
+		}
+		if err := set(w.vector, i, &value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readJSONStream reads a witness encoded with ToJSON, token by token.
+func (w *witness) readJSONStream(field *big.Int, s *schema.Schema, r io.Reader) error {
+	d := jsonStreamDecoder{
+		dec:      json.NewDecoder(r),
+		field:    field,
+		vector:   w.vector,
+		nbPublic: s.NbPublic,
+		assigned: bitset.New(uint(s.NbPublic + s.NbSecret)),
+	}
+	d.dec.UseNumber()
+
+	if err := d.decodeValue(schema.Field{Type: schema.Struct, SubFields: s.Fields}, 0, 0); err != nil {
+		return err
+	}
+
+	if d.nbAssignedPublic != s.NbPublic {
+		return fmt.Errorf("%w: missing assignment for %s", ErrInvalidWitness, d.firstMissing(s.Fields))
+	}
+	switch d.nbAssignedSecret {
+	case s.NbSecret:
+	case 0:
+		// public witness
+		w.nbSecret = 0
+		v, err := newFrom(w.vector, s.NbPublic)
+		if err != nil {
+			return err
+		}
+		w.vector = v
+	default:
+		return fmt.Errorf("%w: missing assignment for %s", ErrInvalidWitness, d.firstMissing(s.Fields))
+	}
+	return nil
+}
+
+type jsonStreamDecoder struct {
+	dec      *json.Decoder
+	field    *big.Int
+	vector   any
+	nbPublic int
+	path     []string
+
+	// assigned marks the entries of the vector already read, to detect missing and
+	// duplicated values.
+	assigned                           *bitset.BitSet
+	nbAssignedPublic, nbAssignedSecret int
+
+	value big.Int
+}
+
+// decodeValue decodes the next JSON value as the schema field f, whose first public and
+// secret values are at offsets public and secret of their part of the witness. A null value
+// leaves the field unassigned.
+func (d *jsonStreamDecoder) decodeValue(f schema.Field, public, secret int) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return d.errorf("%v", err)
+	}
+	if tok == nil {
+		return nil
+	}
+
+	switch f.Type {
+	case schema.Leaf:
+		return d.decodeLeaf(tok, f.Visibility, public, secret)
+	case schema.Struct:
+		if tok != json.Delim('{') {
+			return d.errorf("expected an object, got %v", tok)
+		}
+		return d.decodeStruct(f.SubFields, public, secret)
+	case schema.Array:
+		if tok != json.Delim('[') {
+			return d.errorf("expected an array, got %v", tok)
+		}
+		return d.decodeArray(f, public, secret)
+	default:
+		return d.errorf("unexpected schema field type %d", f.Type)
+	}
+}
+
+func (d *jsonStreamDecoder) decodeStruct(fields []schema.Field, public, secret int) error {
+	// offsets of the fields
+	publicOffsets := make([]int, len(fields))
+	secretOffsets := make([]int, len(fields))
+	p, s := public, secret
+	for i := range fields {
+		publicOffsets[i], secretOffsets[i] = p, s
+		nbPublic, nbSecret := countValues(fields[i])
+		p += nbPublic
+		s += nbSecret
+	}
+
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return d.errorf("%v", err)
+		}
+		key := tok.(string) // object keys are always strings
+		i := fieldIndex(fields, key)
+		if i < 0 {
+			return d.errorf("unknown field %q", key)
+		}
+		d.path = append(d.path, key)
+		if err := d.decodeValue(fields[i], publicOffsets[i], secretOffsets[i]); err != nil {
+			return err
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+	_, err := d.dec.Token() // '}'
+	return err
+}
+
+func (d *jsonStreamDecoder) decodeArray(f schema.Field, public, secret int) error {
+	var nbPublic, nbSecret int
+	if len(f.SubFields) != 0 {
+		nbPublic, nbSecret = countValues(f.SubFields[0])
+	}
+
+	for i := 0; d.dec.More(); i++ {
+		if i >= f.ArraySize {
+			return d.errorf("expected %d elements", f.ArraySize)
+		}
+		d.path = append(d.path, strconv.Itoa(i))
+		if len(f.SubFields) == 0 {
+			// array of leaves
+			tok, err := d.dec.Token()
+			if err != nil {
+				return d.errorf("%v", err)
+			}
+			if tok != nil {
+				if err := d.decodeLeaf(tok, f.Visibility, public+i, secret+i); err != nil {
+					return err
+				}
+			}
+		} else if err := d.decodeValue(f.SubFields[0], public+i*nbPublic, secret+i*nbSecret); err != nil {
+			return err
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+	_, err := d.dec.Token() // ']'
+	return err
+}
+
+func (d *jsonStreamDecoder) decodeLeaf(tok json.Token, visibility schema.Visibility, public, secret int) error {
+	var s string
+	switch t := tok.(type) {
+	case json.Number:
+		s = string(t)
+	case string:
+		s = t
+	default:
+		return d.errorf("expected a field element, got %v", tok)
+	}
+	if _, ok := d.value.SetString(s, 0); !ok {
+		return d.errorf("invalid field element %q", s)
+	}
+	if d.value.Sign() < 0 || d.value.Cmp(d.field) >= 0 {
+		return d.errorf("value out of range [0, field)")
+	}
+
+	index := d.nbPublic + secret
+	if visibility == schema.Public {
+		index = public
+	}
+	if d.assigned.Test(uint(index)) {
+		return d.errorf("duplicated assignment")
+	}
+	if err := set(d.vector, index, &d.value); err != nil {
+		return d.errorf("%v", err)
+	}
+	d.assigned.Set(uint(index))
+	if visibility == schema.Public {
+		d.nbAssignedPublic++
+	} else {
+		d.nbAssignedSecret++
+	}
+	return nil
+}
+
+// firstMissing returns the name of the first unassigned value of the witness.
+func (d *jsonStreamDecoder) firstMissing(fields []schema.Field) string {
+	public, secret := 0, 0
+	var name string
+	var walk func(f schema.Field, path string) bool
+	isMissing := func(visibility schema.Visibility) bool {
+		var missing bool
+		if visibility == schema.Public {
+			missing = !d.assigned.Test(uint(public))
+			public++
+		} else {
+			missing = !d.assigned.Test(uint(d.nbPublic + secret))
+			secret++
+		}
+		return missing
+	}
+	walk = func(f schema.Field, path string) bool {
+		switch f.Type {
+		case schema.Leaf:
+			if isMissing(f.Visibility) {
+				name = path
+				return true
+			}
+		case schema.Struct:
+			for _, sf := range f.SubFields {
+				if walk(sf, joinPath(path, fieldName(sf))) {
+					return true
+				}
+			}
+		case schema.Array:
+			for i := 0; i < f.ArraySize; i++ {
+				p := joinPath(path, strconv.Itoa(i))
+				if len(f.SubFields) == 0 {
+					if isMissing(f.Visibility) {
+						name = p
+						return true
+					}
+				} else if walk(f.SubFields[0], p) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	walk(schema.Field{Type: schema.Struct, SubFields: fields}, "")
+	return name
+}
+
+func (d *jsonStreamDecoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidWitness, strings.Join(d.path, "_"), fmt.Sprintf(format, args...))
+}
+
+// countValues returns the number of public and secret values of the schema field.
+func countValues(f schema.Field) (nbPublic, nbSecret int) {
+	switch f.Type {
+	case schema.Leaf:
+		if f.Visibility == schema.Public {
+			return 1, 0
+		}
+		return 0, 1
+	case schema.Struct:
+		for _, sf := range f.SubFields {
+			p, s := countValues(sf)
+			nbPublic += p
+			nbSecret += s
+		}
+		return
+	case schema.Array:
+		if len(f.SubFields) == 0 {
+			if f.Visibility == schema.Public {
+				return f.ArraySize, 0
+			}
+			return 0, f.ArraySize
+		}
+		p, s := countValues(f.SubFields[0])
+		return p * f.ArraySize, s * f.ArraySize
+	}
+	return
+}
+
+// fieldName returns the JSON name of the field, as set by schema.Instantiate.
+func fieldName(f schema.Field) string {
+	if f.NameTag != "" {
+		return f.NameTag
+	}
+	return f.Name
+}
+
+// fieldIndex returns the index of the field with the JSON name key, or -1. As in
+// encoding/json, an exact match is preferred but the match is case-insensitive.
+func fieldIndex(fields []schema.Field, key string) int {
+	res := -1
+	for i := range fields {
+		name := fieldName(fields[i])
+		if name == key {
+			return i
+		}
+		if res == -1 && strings.EqualFold(name, key) {
+			res = i
+		}
+	}
+	return res
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "_" + name
+}
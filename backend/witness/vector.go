@@ -247,3 +247,27 @@ func resize(v any, n int) any {
 		panic("invalid input")
 	}
 }
+
+// elementSize returns the size in bytes of an element of the vector in its binary encoding.
+func elementSize(v any) int {
+	switch v.(type) {
+	case fr_bn254.Vector:
+		return fr_bn254.Bytes
+	case fr_bls12377.Vector:
+		return fr_bls12377.Bytes
+	case fr_bls12381.Vector:
+		return fr_bls12381.Bytes
+	case fr_bw6761.Vector:
+		return fr_bw6761.Bytes
+	case fr_bls24317.Vector:
+		return fr_bls24317.Bytes
+	case fr_bls24315.Vector:
+		return fr_bls24315.Bytes
+	case fr_bw6633.Vector:
+		return fr_bw6633.Bytes
+	case tinyfield.Vector:
+		return tinyfield.Bytes
+	default:
+		panic("invalid input")
+	}
+}
@@ -76,6 +76,9 @@ type Witness interface {
 	encoding.BinaryUnmarshaler
 
 	// Public returns the Public an object containing the public part of the Witness only.
+	//
+	// The commitment wires of the circuits committing to variables (see frontend.Committer)
+	// aren't part of the witness, public or not: the verifiers compute them from the proof.
 	Public() (Witness, error)
 
 	// Vector returns the underlying fr.Vector slice
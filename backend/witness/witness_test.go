@@ -86,6 +86,39 @@ func TestMarshal(t *testing.T) {
 	roundTripMarshalJSON(assert, assignment, false)
 }
 
+func TestSeal(t *testing.T) {
+	assert := require.New(t)
+
+	var assignment circuit
+	assignment.X = new(fr.Element).SetInt64(42)
+	assignment.Y = new(fr.Element).SetInt64(8000)
+	assignment.E = new(fr.Element).SetInt64(1)
+	w, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	key := bytes.Repeat([]byte{7}, witness.SealKeySize)
+	sealed, err := witness.Seal(w, key, []byte("circuit"))
+	assert.NoError(err)
+	data, err := w.MarshalBinary()
+	assert.NoError(err)
+	assert.False(bytes.Contains(sealed, data[len(data)-fr.Bytes:]), "the values must be encrypted")
+
+	opened, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(err)
+	assert.NoError(witness.Open(opened, key, sealed, []byte("circuit")))
+	assert.True(reflect.DeepEqual(w.Vector(), opened.Vector()))
+
+	// wrong key, additional data, or tampered witness
+	wrongKey := bytes.Repeat([]byte{8}, witness.SealKeySize)
+	assert.ErrorIs(witness.Open(opened, wrongKey, sealed, []byte("circuit")), witness.ErrSealed)
+	assert.ErrorIs(witness.Open(opened, key, sealed, []byte("other")), witness.ErrSealed)
+	sealed[len(sealed)-1] ^= 1
+	assert.ErrorIs(witness.Open(opened, key, sealed, []byte("circuit")), witness.ErrSealed)
+	assert.ErrorIs(witness.Open(opened, key, sealed[:10], []byte("circuit")), witness.ErrSealed)
+	_, err = witness.Seal(w, key[:16], nil)
+	assert.Error(err)
+}
+
 func TestPublic(t *testing.T) {
 	assert := require.New(t)
 
@@ -156,3 +189,140 @@ func roundTripMarshalJSON(assert *require.Assertions, assignment circuit, public
 	assert.True(reflect.DeepEqual(rw, w), "witness json round trip serialization")
 
 }
+
+type nestedCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	A [3]frontend.Variable
+	P [2]struct {
+		U frontend.Variable `gnark:"u,public"`
+		V [2]frontend.Variable
+	}
+}
+
+func (c *nestedCircuit) Define(frontend.API) error {
+	return nil
+}
+
+func TestNewFromReader(t *testing.T) {
+	assert := require.New(t)
+
+	var assignment nestedCircuit
+	assignment.X = 1
+	assignment.A = [3]frontend.Variable{2, 3, 4}
+	assignment.P[0].U, assignment.P[0].V = 5, [2]frontend.Variable{6, 7}
+	assignment.P[1].U, assignment.P[1].V = 8, [2]frontend.Variable{9, 10}
+
+	w, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	s, err := frontend.NewSchema(&assignment)
+	assert.NoError(err)
+
+	// binary
+	data, err := w.MarshalBinary()
+	assert.NoError(err)
+	rw, err := witness.NewFromReader(ecc.BN254.ScalarField(), s, bytes.NewReader(data), witness.Binary)
+	assert.NoError(err)
+	assert.True(reflect.DeepEqual(rw, w), "binary stream")
+
+	// json, in any key order
+	rw, err = witness.NewFromReader(ecc.BN254.ScalarField(), s, bytes.NewReader([]byte(`{
+		"P": [{"V": [6, 7], "u": 5}, {"u": "8", "V": ["0x9", 10]}],
+		"A": [2, 3, 4],
+		"X": 1
+	}`)), witness.JSON)
+	assert.NoError(err)
+	assert.True(reflect.DeepEqual(rw, w), "json stream")
+
+	// public part only
+	public, err := w.Public()
+	assert.NoError(err)
+	rw, err = witness.NewFromReader(ecc.BN254.ScalarField(), s, bytes.NewReader([]byte(`{"X": 1, "P": [{"u": 5}, {"u": 8}]}`)), witness.JSON)
+	assert.NoError(err)
+	assert.True(reflect.DeepEqual(rw, public), "public json stream")
+
+	// invalid inputs
+	modulus := ecc.BN254.ScalarField().String()
+	for _, input := range []string{
+		`{"X": 1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9]}]}`,                   // missing secret
+		`{"A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`,                       // missing public
+		`{"X": 1, "A": [2, 3, 4, 5], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`,            // array too long
+		`{"X": 1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}], "Y": 0}`,       // unknown field
+		`{"X": 1, "X": 1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`,       // duplicated field
+		`{"X": -1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`,              // negative
+		`{"X": ` + modulus + `, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`, // not reduced
+	} {
+		_, err = witness.NewFromReader(ecc.BN254.ScalarField(), s, bytes.NewReader([]byte(input)), witness.JSON)
+		assert.Error(err, input)
+	}
+
+	// binary witness with a value out of range
+	fr.Modulus().FillBytes(data[12 : 12+fr.Bytes])
+	_, err = witness.NewFromReader(ecc.BN254.ScalarField(), s, bytes.NewReader(data), witness.Binary)
+	assert.ErrorIs(err, witness.ErrInvalidWitness)
+}
+
+func TestFromJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var assignment nestedCircuit
+	assignment.X = 1
+	assignment.A = [3]frontend.Variable{2, 3, 4}
+	assignment.P[0].U, assignment.P[0].V = 5, [2]frontend.Variable{6, 7}
+	assignment.P[1].U, assignment.P[1].V = 8, [2]frontend.Variable{9, 10}
+
+	w, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	s, err := frontend.NewSchema(&assignment)
+	assert.NoError(err)
+
+	data, err := w.ToJSON(s)
+	assert.NoError(err)
+	rw, err := witness.FromJSON(ecc.BN254.ScalarField(), s, data)
+	assert.NoError(err)
+	assert.True(reflect.DeepEqual(rw, w))
+
+	// the errors name the offending value
+	modulus := ecc.BN254.ScalarField().String()
+	for input, name := range map[string]string{
+		`{"X": 1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": ` + modulus + `, "V": [9, 10]}]}`: "P_1_u",
+		`{"X": 1, "A": [2, 3.5, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9, 10]}]}`:             "A_1",
+		`{"X": 1, "A": [2, 3, 4], "P": [{"u": 5, "V": [6, 7]}, {"u": 8, "V": [9]}]}`:                   "P_1_V_1",
+	} {
+		_, err = witness.FromJSON(ecc.BN254.ScalarField(), s, []byte(input))
+		assert.ErrorIs(err, witness.ErrInvalidWitness, input)
+		assert.Contains(err.Error(), name, input)
+	}
+
+	// the JSON schema of the witness
+	for _, publicOnly := range []bool{false, true} {
+		b, err := s.ToJSONSchema(publicOnly)
+		assert.NoError(err)
+		var jsonSchema struct {
+			Required   []string `json:"required"`
+			Properties struct {
+				A struct {
+					MaxItems int `json:"maxItems"`
+					Items    struct {
+						Type string `json:"type"`
+					} `json:"items"`
+				}
+				P struct {
+					Items struct {
+						Required []string `json:"required"`
+					} `json:"items"`
+				}
+			} `json:"properties"`
+		}
+		assert.NoError(json.Unmarshal(b, &jsonSchema))
+		assert.Equal(3, jsonSchema.Properties.A.MaxItems)
+		if publicOnly {
+			assert.ElementsMatch([]string{"X", "P"}, jsonSchema.Required)
+			assert.Equal([]string{"u"}, jsonSchema.Properties.P.Items.Required)
+			assert.Equal("null", jsonSchema.Properties.A.Items.Type)
+		} else {
+			assert.ElementsMatch([]string{"X", "A", "P"}, jsonSchema.Required)
+			assert.ElementsMatch([]string{"u", "V"}, jsonSchema.Properties.P.Items.Required)
+			assert.Empty(jsonSchema.Properties.A.Items.Type)
+		}
+	}
+}
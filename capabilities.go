@@ -0,0 +1,141 @@
+package gnark
+
+import (
+	"math/big"
+	"runtime/debug"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	_ "github.com/consensys/gnark/backend/accel/icicle" // registers the CUDA provider, with the icicle build tag
+	_ "github.com/consensys/gnark/backend/accel/rocm"   // registers the AMD provider, with the rocm build tag
+)
+
+// SupportMatrix describes what this build of gnark supports. See Capabilities.
+type SupportMatrix struct {
+	// Curves lists the supported curves, in the order of Curves().
+	Curves []CurveSupport
+
+	// Backends lists the implemented proof systems, in the order of backend.Implemented().
+	Backends []BackendSupport
+
+	// IcicleVersion is the version of the icicle module the program is built with, or the
+	// empty string if it is unknown (for instance in tests, or without module information).
+	IcicleVersion string
+}
+
+// CurveSupport describes the support of a curve.
+type CurveSupport struct {
+	Curve ecc.ID
+
+	// GPUMSM and GPUNTT are true if the Groth16 prover computes the multi-scalar
+	// multiplications and the number theoretic transforms on the GPU: the prover runs on
+	// accelerators, and an accelerator provider other than the CPU emulation is registered
+	// for the curve (see accel.Providers).
+	GPUMSM, GPUNTT bool
+
+	// MaxNTTDomain is the largest NTT domain of the scalar field, 2^s where 2^s divides r-1,
+	// which bounds the number of constraints of a circuit on this curve. It is a property of
+	// the field: the memory of the devices isn't accounted for, see groth16.EstimateMemory.
+	MaxNTTDomain uint64
+}
+
+// BackendSupport describes the support of a proof system.
+type BackendSupport struct {
+	Backend backend.ID
+
+	// MaxCommitments is the maximal number of calls to the Commit method of the API per
+	// circuit, by curve. It is 0 if commitments are not supported and -1 if there is no
+	// limit.
+	MaxCommitments map[ecc.ID]int
+}
+
+// Capabilities returns the support matrix of this build of gnark, for applications which
+// route proving jobs depending on the curve, the proof system or the circuit.
+func Capabilities() SupportMatrix {
+	var m SupportMatrix
+
+	for _, curve := range Curves() {
+		m.Curves = append(m.Curves, curveSupport(curve, providers(curve)))
+	}
+
+	for _, id := range backend.Implemented() {
+		s := BackendSupport{Backend: id, MaxCommitments: make(map[ecc.ID]int)}
+		for _, curve := range Curves() {
+			switch {
+			case id == backend.GROTH16 && curve == ecc.BN254:
+				s.MaxCommitments[curve] = -1
+			case id == backend.GROTH16 || id == backend.PLONK:
+				s.MaxCommitments[curve] = 1
+			default:
+				s.MaxCommitments[curve] = 0
+			}
+		}
+		m.Backends = append(m.Backends, s)
+	}
+
+	m.IcicleVersion = icicleVersion()
+
+	return m
+}
+
+// deviceKernels are the kernels the Groth16 provers run on the accelerators of the backend/accel
+// registry, by curve: the BLS24-315 prover only runs its multi-scalar multiplications on them.
+var deviceKernels = map[ecc.ID]struct{ msm, ntt bool }{
+	ecc.BN254:     {msm: true, ntt: true},
+	ecc.BLS12_377: {msm: true, ntt: true},
+	ecc.BLS24_315: {msm: true},
+}
+
+// curveSupport returns the support of the curve, with the providers of the backend/accel
+// registry for the curve: the fallback providers, such as the CPU emulation, aren't GPUs.
+func curveSupport(curve ecc.ID, providers []accel.Provider) CurveSupport {
+	gpu := false
+	for _, p := range providers {
+		gpu = gpu || !p.Fallback
+	}
+	return CurveSupport{
+		Curve:        curve,
+		GPUMSM:       gpu && deviceKernels[curve].msm,
+		GPUNTT:       gpu && deviceKernels[curve].ntt,
+		MaxNTTDomain: maxNTTDomain(curve.ScalarField()),
+	}
+}
+
+// providers returns the providers registered for the curve.
+func providers(curve ecc.ID) []accel.Provider {
+	var res []accel.Provider
+	for _, name := range accel.Providers(curve) {
+		if p, err := accel.Lookup(name, curve); err == nil {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// maxNTTDomain returns 2^s, where s is the 2-adicity of the multiplicative group of the
+// field of order r.
+func maxNTTDomain(r *big.Int) uint64 {
+	s := new(big.Int).Sub(r, big.NewInt(1)).TrailingZeroBits()
+	if s >= 64 {
+		return 1 << 63
+	}
+	return 1 << s
+}
+
+// icicleVersion returns the version of the icicle module in the build information.
+func icicleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/ingonyama-zk/icicle" {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package gnark
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	assert := require.New(t)
+
+	m := Capabilities()
+	assert.Len(m.Curves, len(Curves()))
+	assert.Len(m.Backends, len(backend.Implemented()))
+
+	for _, c := range m.Curves {
+		switch c.Curve {
+		case ecc.BN254:
+			assert.Equal(uint64(1)<<28, c.MaxNTTDomain)
+		case ecc.BLS12_381:
+			assert.False(c.GPUMSM)
+			assert.Equal(uint64(1)<<32, c.MaxNTTDomain)
+		}
+	}
+
+	// the fallback providers don't count as GPUs, and only the curves with a device prover
+	// use the providers; the providers aren't registered, as the other tests would use them
+	fallback := accel.Provider{Name: "test/fallback", Fallback: true}
+	gpu := accel.Provider{Name: "test/gpu"}
+	c := curveSupport(ecc.BN254, []accel.Provider{fallback})
+	assert.False(c.GPUMSM || c.GPUNTT)
+	c = curveSupport(ecc.BN254, []accel.Provider{fallback, gpu})
+	assert.True(c.GPUMSM && c.GPUNTT)
+	c = curveSupport(ecc.BLS24_315, []accel.Provider{gpu})
+	assert.True(c.GPUMSM && !c.GPUNTT)
+	c = curveSupport(ecc.BLS12_381, []accel.Provider{gpu})
+	assert.False(c.GPUMSM, "the BLS12-381 prover doesn't run on accelerators")
+
+	for _, b := range m.Backends {
+		switch b.Backend {
+		case backend.GROTH16:
+			assert.Equal(-1, b.MaxCommitments[ecc.BN254])
+			assert.Equal(1, b.MaxCommitments[ecc.BLS12_377])
+		case backend.PLONKFRI:
+			assert.Equal(0, b.MaxCommitments[ecc.BN254])
+		}
+	}
+}
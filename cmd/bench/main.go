@@ -0,0 +1,109 @@
+// Command bench proves synthetic circuits, chains of multiplications, of given sizes with the
+// Groth16 GPU provers on each accelerator provider, and writes the durations of the prover
+// stages in JSON, to catch the performance regressions of the provers and of the providers
+// before a release:
+//
+//	bench -curve bn254 -sizes 16-26 -o current.json
+//	bench -curve bn254 -sizes 16-26 -baseline release.json -tolerance 0.1
+//
+// The providers are the ones registered for the curve (see accel.Providers), the CPU emulation
+// and, with their build tags, the GPU ones, or the ones of -accel. Each circuit is proved -reps
+// times after a warm-up proof, and the fastest proof is kept. With -baseline, the stages slower
+// than in the baseline by more than -tolerance are reported, and bench exits with 1.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/bench"
+	"github.com/consensys/gnark/logger"
+)
+
+func main() {
+	var (
+		curveName    = flag.String("curve", "bn254", "curve of the circuits, bn254 or bls12_377")
+		accelerators = flag.String("accel", "", "comma separated accelerator providers, all the registered ones by default")
+		sizes        = flag.String("sizes", "16-22", "log₂ of the numbers of constraints, for instance 16,18 or 16-26")
+		reps         = flag.Int("reps", 3, "proofs of each circuit, the fastest is kept")
+		output       = flag.String("o", "", "results file, the standard output by default")
+		baseline     = flag.String("baseline", "", "results file of a previous run to compare with")
+		tolerance    = flag.Float64("tolerance", 0.1, "relative slowdown of a stage reported as a regression")
+	)
+	flag.Parse()
+
+	log := logger.Logger()
+	curve := ecc.UNKNOWN
+	for _, c := range bench.Curves {
+		if c.String() == *curveName {
+			curve = c
+		}
+	}
+	if curve == ecc.UNKNOWN {
+		log.Fatal().Str("curve", *curveName).Msg("no GPU prover on this curve")
+	}
+	logSizes, err := bench.ParseSizes(*sizes)
+	if err != nil {
+		log.Fatal().Err(err).Msg("parsing the sizes")
+	}
+	var names []string
+	if *accelerators != "" {
+		names = strings.Split(*accelerators, ",")
+	}
+	var base []bench.Result
+	if *baseline != "" {
+		if base, err = readResults(*baseline); err != nil {
+			log.Fatal().Err(err).Msg("reading the baseline")
+		}
+	}
+
+	results, err := bench.Run(curve, names, logSizes, *reps)
+	for i := range results {
+		fmt.Fprintf(os.Stderr, "%-24s %10d constraints %14s\n", results[i].Key(), results[i].NbConstraints, results[i].Prove)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("benchmark failed")
+	}
+	if err = writeResults(*output, results); err != nil {
+		log.Fatal().Err(err).Msg("writing the results")
+	}
+
+	if *baseline != "" {
+		regressions := bench.Compare(base, results, *tolerance)
+		for _, r := range regressions {
+			fmt.Fprintln(os.Stderr, "regression:", r)
+		}
+		if len(regressions) != 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+func readResults(path string) ([]bench.Result, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []bench.Result
+	if err := json.Unmarshal(b, &results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return results, nil
+}
+
+func writeResults(path string, results []bench.Result) error {
+	b, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
@@ -0,0 +1,125 @@
+// Command csdiff compares two compiled Groth16 constraint systems, for instance the versions of
+// a circuit before and after an upgrade, to audit the change before the trusted setup
+// ceremony is run again:
+//
+//	csdiff -curve bn254 old.r1cs new.r1cs
+//
+// It reports the constraints added, removed and changed (see constraint.Diff), the numbers of
+// wires and commitments, and whether the keys change: when they do, the setup must be run
+// again and the verifying key changes. With -json, the report is the JSON encoding of
+// constraint.SystemDiff.
+//
+// The constraint systems are written with WriteTo, or with WriteMappableTo on BN254. As diff,
+// csdiff exits with 0 if the systems have the same constraints and keys, 1 if they differ,
+// and 2 on errors.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	cs "github.com/consensys/gnark/constraint/bn254"
+)
+
+func main() {
+	var (
+		curve   = flag.String("curve", "bn254", "curve of the constraint systems")
+		asJSON  = flag.Bool("json", false, "write the report in JSON")
+		summary = flag.Bool("summary", false, "only write the numbers of constraints and wires, and whether the keys change")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: csdiff [flags] old.r1cs new.r1cs")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	diff, err := compare(*curve, flag.Arg(0), flag.Arg(1))
+	if err == nil {
+		err = report(diff, *asJSON, *summary)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "csdiff:", err)
+		os.Exit(2)
+	}
+	if !diff.Equal() || diff.KeysChanged || diff.PublicInputsChanged {
+		os.Exit(1)
+	}
+}
+
+func compare(curve, oldPath, newPath string) (*constraint.SystemDiff, error) {
+	id, err := parseCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	a, err := readSystem(id, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readSystem(id, newPath)
+	if err != nil {
+		return nil, err
+	}
+	return constraint.Diff(a, b)
+}
+
+func report(diff *constraint.SystemDiff, asJSON, summary bool) error {
+	if summary {
+		diff.Changes = nil
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return err
+		}
+	} else if _, err := diff.WriteTo(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func readSystem(curve ecc.ID, path string) (constraint.ConstraintSystem, error) {
+	if curve == ecc.BN254 {
+		mappable, err := cs.IsMappableFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if mappable {
+			// the mapping is released when the process exits
+			r1cs, _, err := cs.OpenMapped(path)
+			return r1cs, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r1cs := groth16.NewCS(curve)
+	if _, err := r1cs.ReadFrom(bufio.NewReaderSize(f, 1<<20)); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return r1cs, nil
+}
+
+func parseCurve(s string) (ecc.ID, error) {
+	for _, curve := range gnark.Curves() {
+		if curve.String() == s {
+			return curve, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unknown curve %q", s)
+}
@@ -0,0 +1,110 @@
+// Command gnarkconv converts the Groth16 proving keys, verifying keys and proofs on BN254
+// between the encoding of this fork and the one of upstream gnark, so that the proofs of the
+// fork are verified by upstream verifiers, and the keys of upstream setups used by the fork:
+//
+//	gnarkconv -kind vk -to upstream -in circuit.vk -out circuit.upstream.vk
+//	gnarkconv -kind proof -to upstream -in proof.bin -out proof.upstream.bin
+//	gnarkconv -kind pk -to fork -in circuit.upstream.pk -out circuit.pk
+//
+// Upstream gnark supports at most one commitment per circuit: the keys and the proofs of the
+// circuits with several commitments aren't converted. The setup log of the verifying keys
+// isn't in the upstream encoding, and is lost. The other curves share the encoding of
+// upstream gnark, and need no conversion.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// object is a proving key, a verifying key or a proof, in both encodings.
+type object interface {
+	io.ReaderFrom
+	io.WriterTo
+	WriteRawTo(w io.Writer) (int64, error)
+	ReadUpstreamFrom(r io.Reader) (int64, error)
+	WriteUpstreamTo(w io.Writer) (int64, error)
+	WriteUpstreamRawTo(w io.Writer) (int64, error)
+}
+
+func main() {
+	var (
+		kind   = flag.String("kind", "proof", "object to convert: pk, vk or proof")
+		to     = flag.String("to", "upstream", "encoding of the output: upstream or fork")
+		raw    = flag.Bool("raw", false, "write the points uncompressed")
+		input  = flag.String("in", "", "input file")
+		output = flag.String("out", "", "output file")
+	)
+	flag.Parse()
+
+	if err := convert(*kind, *to, *raw, *input, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "gnarkconv:", err)
+		os.Exit(1)
+	}
+}
+
+func convert(kind, to string, raw bool, input, output string) error {
+	var o object
+	switch kind {
+	case "pk":
+		o = new(groth16.ProvingKey)
+	case "vk":
+		o = new(groth16.VerifyingKey)
+	case "proof":
+		o = new(groth16.Proof)
+	default:
+		return fmt.Errorf("unknown kind %q, expected pk, vk or proof", kind)
+	}
+	if to != "upstream" && to != "fork" {
+		return fmt.Errorf("unknown encoding %q, expected upstream or fork", to)
+	}
+	if input == "" || output == "" {
+		return fmt.Errorf("the input and output files are required")
+	}
+
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r := bufio.NewReaderSize(in, 1<<20)
+	if to == "upstream" {
+		_, err = o.ReadFrom(r)
+	} else {
+		_, err = o.ReadUpstreamFrom(r)
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", input, err)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(out, 1<<20)
+	switch {
+	case to == "upstream" && raw:
+		_, err = o.WriteUpstreamRawTo(w)
+	case to == "upstream":
+		_, err = o.WriteUpstreamTo(w)
+	case raw:
+		_, err = o.WriteRawTo(w)
+	default:
+		_, err = o.WriteTo(w)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/accel"
+)
+
+func init() {
+	kernels[ecc.BN254] = newKernelsBN254
+}
+
+// kernelsBN254 holds random inputs of the BN254 kernels on the device.
+type kernelsBN254 struct {
+	d                  accel.Device
+	n                  int
+	hostScalars        []fr.Element
+	scalars, nttOut    unsafe.Pointer
+	pointsG1, pointsG2 unsafe.Pointer
+	twiddles           unsafe.Pointer
+}
+
+func newKernelsBN254(d accel.Device, logSize int) (benchKernels, error) {
+	n := 1 << logSize
+	k := &kernelsBN254{d: d, n: n, hostScalars: make([]fr.Element, n)}
+	for i := range k.hostScalars {
+		k.hostScalars[i].SetRandom()
+	}
+
+	// the points are multiples of the generators, which is faster than hashing to the curve
+	_, _, g1, g2 := curve.Generators()
+	pointsG1 := curve.BatchScalarMultiplicationG1(&g1, k.hostScalars)
+	pointsG2 := curve.BatchScalarMultiplicationG2(&g2, k.hostScalars)
+
+	var err error
+	scalarBytes := n * fr.Bytes
+	if k.scalars, err = d.Malloc(scalarBytes); err != nil {
+		return nil, err
+	}
+	if err = d.CopyToDevice(k.scalars, unsafe.Pointer(&k.hostScalars[0]), scalarBytes); err != nil {
+		return nil, err
+	}
+	if err = d.FromMontgomery(k.scalars, n); err != nil {
+		return nil, err
+	}
+	if k.nttOut, err = d.Malloc(scalarBytes); err != nil {
+		return nil, err
+	}
+	if k.pointsG1, err = d.PointsG1ToDevice(unsafe.Pointer(&pointsG1[0]), n, accel.PointsConfig{}); err != nil {
+		return nil, err
+	}
+	if k.pointsG2, err = d.PointsG2ToDevice(unsafe.Pointer(&pointsG2[0]), n, accel.PointsConfig{}); err != nil {
+		return nil, err
+	}
+	if k.twiddles, err = d.Twiddles(n, false); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *kernelsBN254) msmG1(bucketFactor int) time.Duration {
+	var res curve.G1Jac
+	start := time.Now()
+	_ = k.d.Msm(unsafe.Pointer(&res), k.scalars, k.pointsG1, k.n, accel.MsmConfig{BucketFactor: bucketFactor})
+	return time.Since(start)
+}
+
+func (k *kernelsBN254) msmG2(bucketFactor int) time.Duration {
+	var res curve.G2Jac
+	start := time.Now()
+	_ = k.d.MsmG2(unsafe.Pointer(&res), k.scalars, k.pointsG2, k.n, accel.MsmConfig{BucketFactor: bucketFactor})
+	return time.Since(start)
+}
+
+func (k *kernelsBN254) ntt() time.Duration {
+	start := time.Now()
+	_ = k.d.Ntt(k.nttOut, k.scalars, k.twiddles, nil, k.n)
+	return time.Since(start)
+}
+
+func (k *kernelsBN254) hostToDevice() time.Duration {
+	start := time.Now()
+	_ = k.d.CopyToDevice(k.nttOut, unsafe.Pointer(&k.hostScalars[0]), k.n*fr.Bytes)
+	return time.Since(start)
+}
+
+func (k *kernelsBN254) free() {
+	for _, p := range []unsafe.Pointer{k.scalars, k.nttOut, k.pointsG1, k.pointsG2, k.twiddles} {
+		if p != nil {
+			_ = k.d.Free(p)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// deviceName returns the model of the current GPU as reported by nvidia-smi, or "unknown".
+func deviceName() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=name", "--format=csv,noheader").Output()
+	if err != nil {
+		return "unknown"
+	}
+	// the first GPU is the current device, unless CUDA_VISIBLE_DEVICES reorders them
+	name, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(name)
+}
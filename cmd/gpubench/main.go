@@ -0,0 +1,234 @@
+// Command gpubench benchmarks the kernels of the GPU provers on the accelerator device (see
+// accel.Open, the GNARK_ACCEL environment variable selects the provider), and writes the device profile used by groth16.EstimateProveTime:
+//
+//	gpubench -curve bn254 -sizes 16,18,20,22 -buckets 8,10,12 -o device.json
+//
+// It runs the multi-scalar multiplications in G1 for each size and bucket factor, the ones in
+// G2 and the NTTs for each size with the fastest bucket factor, and the copies of scalars to the
+// device. The throughputs of the profile are the ones of the largest size; the profile also
+// records the fastest bucket factor. The solver throughput and the fixed cost of a proof are
+// measured with a chain of multiplications proved with groth16.
+//
+// A profile is only meaningful for the curve and the device it was measured on.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/logger"
+)
+
+// benchKernels runs the kernels of a curve on random inputs of a given size on the device.
+type benchKernels interface {
+	msmG1(bucketFactor int) time.Duration
+	msmG2(bucketFactor int) time.Duration
+	ntt() time.Duration
+	hostToDevice() time.Duration
+	free()
+}
+
+// kernels returns the benchmarks of the kernels of the curves with a GPU prover, on inputs of
+// 2^logSize elements on the device.
+var kernels = map[ecc.ID]func(d accel.Device, logSize int) (benchKernels, error){}
+
+func main() {
+	var (
+		curveName = flag.String("curve", "bn254", "curve of the kernels")
+		sizes     = flag.String("sizes", "16,18,20,22", "log₂ of the input sizes")
+		buckets   = flag.String("buckets", "8,10,12", "bucket factors of the multi-scalar multiplications")
+		reps      = flag.Int("reps", 5, "runs of each benchmark, the fastest is kept")
+		logSolver = flag.Int("solver", 20, "log₂ of the number of constraints of the solver benchmark")
+		name      = flag.String("name", "", "device name in the profile, the GPU model by default")
+		output    = flag.String("o", "device.json", "device profile file")
+	)
+	flag.Parse()
+
+	log := logger.Logger()
+	curve := ecc.UNKNOWN
+	for c := range kernels {
+		if c.String() == *curveName {
+			curve = c
+		}
+	}
+	newKernels, ok := kernels[curve]
+	if !ok {
+		log.Fatal().Str("curve", *curveName).Msg("no GPU prover on this curve")
+	}
+	logSizes, err := parseInts(*sizes)
+	if err != nil || len(logSizes) == 0 {
+		log.Fatal().Err(err).Msg("parsing the sizes")
+	}
+	bucketFactors, err := parseInts(*buckets)
+	if err != nil || len(bucketFactors) == 0 {
+		log.Fatal().Err(err).Msg("parsing the bucket factors")
+	}
+	if *reps < 1 {
+		log.Fatal().Msg("invalid number of runs")
+	}
+	sort.Ints(logSizes)
+
+	device, err := accel.Open(curve)
+	if err != nil {
+		log.Fatal().Err(err).Msg("opening the device")
+	}
+
+	profile := groth16.DeviceProfile{Name: *name}
+	if profile.Name == "" {
+		profile.Name = deviceName()
+	}
+	if profile.Name == "unknown" {
+		profile.Name = device.Name()
+	}
+
+	fmt.Printf("%-6s %-10s %-8s %14s %16s\n", "size", "kernel", "buckets", "time", "throughput")
+	for _, logSize := range logSizes {
+		n := 1 << logSize
+		k, err := newKernels(device, logSize)
+		if err != nil {
+			log.Fatal().Err(err).Int("logSize", logSize).Msg("copying the inputs to the device")
+		}
+
+		bestFactor, bestG1 := 0, time.Duration(0)
+		for _, c := range bucketFactors {
+			d := fastest(*reps, func() time.Duration { return k.msmG1(c) })
+			printResult(logSize, "MSM G1", strconv.Itoa(c), d, float64(n), "points/s")
+			if bestFactor == 0 || d < bestG1 {
+				bestFactor, bestG1 = c, d
+			}
+		}
+		g2 := fastest(*reps, func() time.Duration { return k.msmG2(bestFactor) })
+		printResult(logSize, "MSM G2", strconv.Itoa(bestFactor), g2, float64(n), "points/s")
+		ntt := fastest(*reps, k.ntt)
+		butterflies := float64(n/2) * float64(logSize)
+		printResult(logSize, "NTT", "", ntt, butterflies, "butterflies/s")
+		h2d := fastest(*reps, k.hostToDevice)
+		scalarBytes := float64(n) * float64((curve.ScalarField().BitLen()+63)/64*8)
+		printResult(logSize, "H2D", "", h2d, scalarBytes, "bytes/s")
+		k.free()
+
+		// the sizes are sorted: the profile keeps the throughputs of the largest
+		profile.BucketFactor = bestFactor
+		profile.MSMG1 = float64(n) / bestG1.Seconds()
+		profile.MSMG2 = float64(n) / g2.Seconds()
+		profile.NTT = butterflies / ntt.Seconds()
+		profile.HostToDevice = scalarBytes / h2d.Seconds()
+	}
+
+	if profile.Solver, err = solverThroughput(curve, *logSolver); err != nil {
+		log.Fatal().Err(err).Msg("solver benchmark")
+	}
+	fmt.Printf("solver: %.3g instructions/s\n", profile.Solver)
+	if profile.Overhead, err = proofOverhead(curve, *reps); err != nil {
+		log.Fatal().Err(err).Msg("proof overhead benchmark")
+	}
+	fmt.Printf("overhead: %s\n", profile.Overhead)
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		log.Fatal().Err(err).Msg("encoding the profile")
+	}
+	if err = os.WriteFile(*output, append(data, '\n'), 0o644); err != nil {
+		log.Fatal().Err(err).Msg("writing the profile")
+	}
+}
+
+func parseInts(s string) ([]int, error) {
+	var res []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if v <= 0 {
+			return nil, fmt.Errorf("%d is not positive", v)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// fastest returns the fastest of reps runs, after a warm-up run.
+func fastest(reps int, run func() time.Duration) time.Duration {
+	run()
+	best := run()
+	for i := 1; i < reps; i++ {
+		if d := run(); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+func printResult(logSize int, kernel, buckets string, d time.Duration, work float64, unit string) {
+	fmt.Printf("2^%-4d %-10s %-8s %14s %10.3g %s\n", logSize, kernel, buckets, d, work/d.Seconds(), unit)
+}
+
+// chainCircuit is a chain of n multiplications.
+type chainCircuit struct {
+	X frontend.Variable
+	n int
+}
+
+func (c *chainCircuit) Define(api frontend.API) error {
+	y := c.X
+	for i := 0; i < c.n; i++ {
+		y = api.Mul(y, c.X)
+	}
+	api.AssertIsDifferent(y, 0)
+	return nil
+}
+
+// solverThroughput returns the number of instructions per second the solver runs, on a chain
+// of 2^logSize multiplications.
+func solverThroughput(curve ecc.ID, logSize int) (float64, error) {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &chainCircuit{n: 1 << logSize})
+	if err != nil {
+		return 0, err
+	}
+	w, err := frontend.NewWitness(&chainCircuit{X: 3}, curve.ScalarField())
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err = ccs.Solve(w); err != nil {
+		return 0, err
+	}
+	return float64(ccs.GetNbInstructions()) / time.Since(start).Seconds(), nil
+}
+
+// proofOverhead returns the time of the fastest proof of a circuit of a few constraints.
+func proofOverhead(curve ecc.ID, reps int) (time.Duration, error) {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &chainCircuit{n: 1})
+	if err != nil {
+		return 0, err
+	}
+	pk, _, err := groth16.Setup(ccs)
+	if err != nil {
+		return 0, err
+	}
+	w, err := frontend.NewWitness(&chainCircuit{X: 3}, curve.ScalarField())
+	if err != nil {
+		return 0, err
+	}
+	var proveErr error
+	d := fastest(reps, func() time.Duration {
+		start := time.Now()
+		if _, err := groth16.Prove(ccs, pk, w); err != nil {
+			proveErr = err
+		}
+		return time.Since(start)
+	})
+	return d, proveErr
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+)
+
+// config is the JSON configuration file of the service.
+type config struct {
+	Circuits []circuitConfig `json:"circuits"`
+}
+
+// circuitConfig describes a circuit served by proverd: its constraint system and proving key,
+// written with WriteTo.
+type circuitConfig struct {
+	Name  string `json:"name"`
+	Curve string `json:"curve"` // e.g. "bn254"
+	R1CS  string `json:"r1cs"`
+	PK    string `json:"pk"`
+}
+
+// circuit is a loaded circuit. The proving keys are device-resident on the curves with a GPU
+// prover.
+type circuit struct {
+	circuitConfig
+	curve ecc.ID
+	ccs   constraint.ConstraintSystem
+
+	// pks are the proving keys of the devices of the registry, by index. On the curves without
+	// a GPU prover, the devices share one proving key.
+	pks []groth16.ProvingKey
+
+	// version identifies the files the circuit was loaded from
+	version fileVersion
+}
+
+// fileVersion identifies the content of the files of a circuit without reading them.
+type fileVersion struct {
+	r1csSize, pkSize       int64
+	r1csModTime, pkModTime time.Time
+}
+
+// registry holds the loaded circuits, which are replaced all at once on reload. Jobs hold a
+// reference to their circuit and are not affected by reloads.
+type registry struct {
+	configPath string
+
+	// devices are the names of the accelerator providers of the worker pools, the empty name
+	// standing for the default provider (see accel.Open): each circuit has a proving key on
+	// each device.
+	devices []string
+
+	reloadLock sync.Mutex // reloads are sequential
+
+	lock     sync.RWMutex
+	circuits map[string]*circuit
+}
+
+func (r *registry) get(name string) (*circuit, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	c, ok := r.circuits[name]
+	return c, ok
+}
+
+func (r *registry) list() []*circuit {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	res := make([]*circuit, 0, len(r.circuits))
+	for _, c := range r.circuits {
+		res = append(res, c)
+	}
+	return res
+}
+
+// reload reads the configuration and loads its circuits. The circuits whose files didn't
+// change are kept as is. On error, the loaded circuits are left untouched.
+func (r *registry) reload() error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	f, err := os.Open(r.configPath)
+	if err != nil {
+		return err
+	}
+	var cfg config
+	err = json.NewDecoder(f).Decode(&cfg)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", r.configPath, err)
+	}
+
+	circuits := make(map[string]*circuit, len(cfg.Circuits))
+	for _, cc := range cfg.Circuits {
+		if _, ok := circuits[cc.Name]; ok || cc.Name == "" {
+			return fmt.Errorf("invalid or duplicate circuit name %q", cc.Name)
+		}
+		version, err := statFiles(cc)
+		if err != nil {
+			return err
+		}
+		if old, ok := r.get(cc.Name); ok && old.circuitConfig == cc && old.version == version {
+			circuits[cc.Name] = old
+			continue
+		}
+		c, err := loadCircuit(cc, r.devices)
+		if err != nil {
+			return fmt.Errorf("circuit %s: %w", cc.Name, err)
+		}
+		c.version = version
+		circuits[cc.Name] = c
+	}
+
+	r.lock.Lock()
+	r.circuits = circuits
+	r.lock.Unlock()
+	return nil
+}
+
+func statFiles(cc circuitConfig) (v fileVersion, err error) {
+	r1cs, err := os.Stat(cc.R1CS)
+	if err != nil {
+		return
+	}
+	pk, err := os.Stat(cc.PK)
+	if err != nil {
+		return
+	}
+	return fileVersion{
+		r1csSize:    r1cs.Size(),
+		pkSize:      pk.Size(),
+		r1csModTime: r1cs.ModTime(),
+		pkModTime:   pk.ModTime(),
+	}, nil
+}
+
+// loadCircuit loads the circuit, with a proving key copied to each of the devices.
+func loadCircuit(cc circuitConfig, devices []string) (*circuit, error) {
+	curve, err := parseCurve(cc.Curve)
+	if err != nil {
+		return nil, err
+	}
+	c := &circuit{circuitConfig: cc, curve: curve, ccs: groth16.NewCS(curve), pks: make([]groth16.ProvingKey, len(devices))}
+	if err := readFile(cc.R1CS, c.ccs); err != nil {
+		return nil, err
+	}
+	for i, device := range devices {
+		pk := groth16.NewProvingKey(curve)
+		if err := readFile(cc.PK, pk); err != nil {
+			return nil, err
+		}
+		dpk, ok := pk.(groth16.DeviceProvingKey)
+		if !ok {
+			// the proving key is on the host
+			for j := range c.pks {
+				c.pks[j] = pk
+			}
+			return c, nil
+		}
+		if device != "" {
+			if err := dpk.SetAccelerator(device); err != nil {
+				return nil, fmt.Errorf("copying the proving key to %s: %w", device, err)
+			}
+		}
+		c.pks[i] = pk
+	}
+	return c, nil
+}
+
+func parseCurve(s string) (ecc.ID, error) {
+	for _, curve := range gnark.Curves() {
+		if curve.String() == s {
+			return curve, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unknown curve %q", s)
+}
+
+func readFile(path string, r io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := r.ReadFrom(bufio.NewReaderSize(f, 1<<20)); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// readWitnessKey reads the key of the sealed witnesses, hex-encoded in the file.
+func readWitnessKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("witness key %s: %w", path, err)
+	}
+	if len(key) != witness.SealKeySize {
+		return nil, fmt.Errorf("witness key %s: %d bytes, expected %d", path, len(key), witness.SealKeySize)
+	}
+	return key, nil
+}
+
+// minAccelTokenSize is the minimum size of the token of the remote accelerator clients.
+const minAccelTokenSize = 16
+
+// readAccelToken reads the token of the remote accelerator clients, hex-encoded in the file.
+func readAccelToken(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	token, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("accelerator token %s: %w", path, err)
+	}
+	if len(token) < minAccelTokenSize {
+		return nil, fmt.Errorf("accelerator token %s: %d bytes, expected at least %d", path, len(token), minAccelTokenSize)
+	}
+	return token, nil
+}
+
+// accelTLSConfig returns the TLS configuration of the remote accelerator server, of the
+// certificate and key files, requiring the clients to present a certificate of the CA file if
+// not empty.
+func accelTLSConfig(certPath, keyPath, clientCAPath string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAPath != "" {
+		pem, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA %s: no certificate", clientCAPath)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
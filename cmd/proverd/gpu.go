@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gpuStatus is the utilization of a GPU, as reported by nvidia-smi.
+type gpuStatus struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	Utilization int    `json:"utilization"` // percent
+	MemoryUsed  int    `json:"memoryUsed"`  // MiB
+	MemoryTotal int    `json:"memoryTotal"` // MiB
+}
+
+// queryGPUs returns the utilization of the GPUs of the host.
+func queryGPUs(ctx context.Context) ([]gpuStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=index,name,utilization.gpu,memory.used,memory.total",
+		"--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi: %w", err)
+	}
+	return parseGPUs(string(out))
+}
+
+func parseGPUs(out string) ([]gpuStatus, error) {
+	r := csv.NewReader(strings.NewReader(out))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]gpuStatus, len(records))
+	for i, record := range records {
+		if len(record) != 5 {
+			return nil, fmt.Errorf("unexpected nvidia-smi output %q", out)
+		}
+		res[i].Name = record[1]
+		for j, v := range []*int{&res[i].Index, nil, &res[i].Utilization, &res[i].MemoryUsed, &res[i].MemoryTotal} {
+			if v == nil {
+				continue
+			}
+			if *v, err = strconv.Atoi(record[j]); err != nil {
+				// fields are "[N/A]" on some devices
+				*v = -1
+			}
+		}
+	}
+	return res, nil
+}
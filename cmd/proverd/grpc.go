@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/consensys/gnark/cmd/proverd/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// proverService serves the gRPC API of the server, see the pb package.
+type proverService struct {
+	pb.UnimplementedProverServer
+	s *server
+}
+
+func (g proverService) Prove(ctx context.Context, req *pb.ProveRequest) (*pb.ProveResponse, error) {
+	c, ok := g.s.registry.get(req.Circuit)
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.NotFound, "unknown circuit %q", req.Circuit)
+	}
+	wit, err := g.s.decodeWitness(c, req.Witness)
+	if err != nil {
+		return nil, grpcstatus.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := g.s.prove(ctx, c, wit, false)
+	if res.err != nil {
+		if res.err == errQueueFull {
+			return nil, grpcstatus.Error(codes.ResourceExhausted, res.err.Error())
+		}
+		if errors.Is(res.err, context.Canceled) || errors.Is(res.err, context.DeadlineExceeded) {
+			return nil, grpcstatus.FromContextError(res.err).Err()
+		}
+		// the witness doesn't solve the circuit
+		return nil, grpcstatus.Error(codes.FailedPrecondition, res.err.Error())
+	}
+	var buf bytes.Buffer
+	if _, err := res.proof.WriteTo(&buf); err != nil {
+		return nil, grpcstatus.Error(codes.Internal, err.Error())
+	}
+	return &pb.ProveResponse{Proof: buf.Bytes(), DurationNs: res.duration.Nanoseconds()}, nil
+}
+
+func (g proverService) Status(ctx context.Context, _ *pb.StatusRequest) (*pb.StatusResponse, error) {
+	st := g.s.status(ctx)
+	res := &pb.StatusResponse{
+		Workers:   int32(st.Workers),
+		Busy:      st.Busy,
+		Queued:    int32(st.Queued),
+		QueueSize: int32(st.QueueSize),
+		Proofs:    st.Proofs,
+		Failures:  st.Failures,
+		Rejected:  st.Rejected,
+		GpuError:  st.GPUError,
+	}
+	for _, c := range st.Circuits {
+		res.Circuits = append(res.Circuits, &pb.Circuit{Name: c.Name, Curve: c.Curve, NbConstraints: int64(c.NbConstraints)})
+	}
+	for _, d := range st.Devices {
+		res.Devices = append(res.Devices, &pb.Device{Name: d.Name, Workers: int32(d.Workers), Busy: d.Busy, Proofs: d.Proofs, Failures: d.Failures})
+	}
+	for _, gpu := range st.GPUs {
+		res.Gpus = append(res.Gpus, &pb.GPU{
+			Index:       int32(gpu.Index),
+			Name:        gpu.Name,
+			Utilization: int32(gpu.Utilization),
+			MemoryUsed:  int32(gpu.MemoryUsed),
+			MemoryTotal: int32(gpu.MemoryTotal),
+		})
+	}
+	return res, nil
+}
+
+// grpcServer returns a gRPC server of the API, whose requests carry witnesses of up to
+// maxWitnessSize bytes.
+func (s *server) grpcServer() *grpc.Server {
+	srv := grpc.NewServer(grpc.MaxRecvMsgSize(maxWitnessSize + 1<<10))
+	pb.RegisterProverServer(srv, proverService{s: s})
+	return srv
+}
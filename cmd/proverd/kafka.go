@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+// replyHeader is the header of a job message naming the topic of its result.
+const replyHeader = "reply-topic"
+
+// kafkaConfig configures the consumption of the jobs of a Kafka cluster.
+type kafkaConfig struct {
+	brokers []string
+	jobs    string // the topic of the jobs
+	group   string // the consumer group of the proverd instances, which share the partitions
+	results string // the topic of the results of the jobs without a reply-topic header
+}
+
+// kafkaMessage is a job message being proved, committed once done is closed and err is nil.
+type kafkaMessage struct {
+	msg  kafka.Message
+	done chan struct{}
+	err  error
+}
+
+// consumeKafka proves the jobs of the Kafka topic until ctx is done, as a member of the
+// consumer group. The offset of a job is committed once its result is published, in the order
+// of the partition, so that the jobs interrupted by a failure, a rebalance or a shutdown are
+// delivered again: Kafka delivers each message at least once, and the result cache answers the
+// jobs delivered again. The jobs share the workers and the queue of the HTTP API, and the
+// messages are fetched as long as the queue has room.
+func (s *server) consumeKafka(ctx context.Context, cfg kafkaConfig, cache *resultCache) {
+	log := logger.Logger().With().Str("jobs", cfg.jobs).Logger()
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	defer w.Close()
+
+	var delay time.Duration
+	for ctx.Err() == nil {
+		r := kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.brokers,
+			GroupID: cfg.group,
+			Topic:   cfg.jobs,
+			// the witnesses of large circuits exceed the default of 1MB
+			MaxBytes: 64 << 20,
+		})
+		log.Info().Strs("brokers", cfg.brokers).Msg("consuming the proof jobs")
+		consumed, err := s.consumeKafkaReader(ctx, r, w, cfg, cache)
+		_ = r.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		if consumed {
+			// a session which consumed jobs starts the backoff over
+			delay = 0
+		}
+		delay = backoff(delay)
+		log.Error().Err(err).Dur("retry", delay).Msg("consuming the proof jobs")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// consumeKafkaReader proves the jobs of the reader until ctx is done or a result can't be
+// published or committed; the jobs after it are then delivered again. It reports whether a job
+// was fetched.
+func (s *server) consumeKafkaReader(ctx context.Context, r *kafka.Reader, w *kafka.Writer, cfg kafkaConfig, cache *resultCache) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the jobs being proved or queued in the fetch order, bounded like the queue of the HTTP API
+	pending := make(chan *kafkaMessage, s.nbWorkers+cap(s.queue))
+	commitErr := make(chan error, 1)
+	go func() {
+		for m := range pending {
+			<-m.done
+			err := m.err
+			if err == nil {
+				err = r.CommitMessages(ctx, m.msg)
+			}
+			if err != nil {
+				commitErr <- err
+				cancel()
+				// the next jobs complete with the cancellation, without being committed
+				for range pending {
+				}
+				return
+			}
+		}
+		close(commitErr)
+	}()
+
+	consumed, err := s.fetchKafka(ctx, r, w, cfg, cache, pending)
+	close(pending)
+	if cErr, ok := <-commitErr; ok {
+		return consumed, cErr
+	}
+	return consumed, err
+}
+
+// fetchKafka fetches the jobs of the reader in pending until an error, and reports whether a
+// job was fetched.
+func (s *server) fetchKafka(ctx context.Context, r *kafka.Reader, w *kafka.Writer, cfg kafkaConfig, cache *resultCache, pending chan<- *kafkaMessage) (bool, error) {
+	consumed := false
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			return consumed, err
+		}
+		consumed = true
+		m := &kafkaMessage{msg: msg, done: make(chan struct{})}
+		select {
+		case pending <- m:
+		case <-ctx.Done():
+			return consumed, ctx.Err()
+		}
+		go func() {
+			defer close(m.done)
+			reply := cfg.results
+			for _, h := range m.msg.Headers {
+				if h.Key == replyHeader {
+					reply = string(h.Value)
+				}
+			}
+			data, ok := s.runJob(ctx, m.msg.Value, cache)
+			if !ok {
+				m.err = ctx.Err()
+				return
+			}
+			// the result has the key of the job, so that the results of a key stay in one partition
+			m.err = w.WriteMessages(ctx, kafka.Message{Topic: reply, Key: m.msg.Key, Value: data})
+		}()
+	}
+}
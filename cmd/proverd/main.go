@@ -0,0 +1,241 @@
+// Command proverd is a Groth16 proving service.
+//
+// It loads the circuits listed in a JSON configuration file:
+//
+//	{"circuits": [{"name": "transfer", "curve": "bn254", "r1cs": "transfer.r1cs", "pk": "transfer.pk"}]}
+//
+// where the constraint systems and proving keys are written with WriteTo. The proving keys of
+// the curves with a GPU prover stay resident on the device between proofs.
+//
+// The HTTP API is:
+//
+//	POST /v1/prove/{circuit}  body: full witness (witness.MarshalBinary), response: proof (WriteTo)
+//	GET  /v1/status           circuits, queue, counters of the devices and GPU utilization (nvidia-smi), in JSON
+//
+// With -grpc-addr, the same API is also served over gRPC, see the Prover service of the pb
+// package.
+//
+// The administration API is served on its own listener, -admin-addr, on the loopback
+// interface by default, so that the clients of the proofs can't reach it:
+//
+//	GET  /v1/status           as above
+//	POST /v1/reload           reloads the configuration
+//
+// SIGHUP also reloads the configuration: the circuits whose files changed are loaded again, the
+// others are kept, and proofs in progress are not affected.
+//
+// Proof requests are queued and served by a pool of -workers workers per device of -devices, a
+// list of accelerator providers of the backend/accel registry: for instance
+// "icicle/0,icicle/1" for the first two CUDA GPUs (see icicle.GPUName), or "cpu" for the CPU
+// emulation. Each circuit has a copy of its proving key on each device, and the pools share
+// the queue. By default, a single pool proves on the default provider (see accel.Open). When
+// the queue is full, requests are rejected with 503, or RESOURCE_EXHAUSTED over gRPC.
+//
+// With -nats, proverd also consumes proof jobs from a NATS subject (-nats-jobs), as a member of
+// a queue group (-nats-group) sharing the jobs between the proverd instances. A job is a JSON
+// object
+//
+//	{"id": "job-1", "circuit": "transfer", "witness": "<base64 of witness.MarshalBinary>"}
+//
+// and its result, published on the reply subject of the job message if any, or else on
+// -nats-results, is
+//
+//	{"id": "job-1", "proof": "<base64 of WriteTo>", "duration": "1.2s"}
+//
+// or {"id": "job-1", "error": "..."}. The results of the last -result-cache jobs are kept by
+// ID: a job delivered again, for instance retried by its client, is answered from the cache,
+// or waits for the proof in progress, instead of being proved twice. A job reusing the ID of a
+// cached job with another circuit or witness is answered with an error. proverd only consumes
+// core NATS subjects, not JetStream.
+//
+// With -kafka, proverd consumes the same jobs from a Kafka topic (-kafka-jobs), as a member of
+// a consumer group (-kafka-group). The result is written on the topic of the reply-topic header
+// of the job message if any, or else on -kafka-results, with the key of the job message. The
+// offset of a job is committed once its result is written, so that the jobs are delivered at
+// least once; the jobs delivered again are answered from the result cache.
+//
+// With -witness-key, the witnesses of the HTTP requests and of the jobs are sealed with
+// witness.Seal, under the key of the file (32 bytes, hex-encoded) and with the name of the
+// circuit as additional data, so that they are encrypted in the job queues and on the network;
+// the unsealed witnesses are rejected. The key is only read by the proverd process.
+//
+// With -accel-addr, proverd also serves its accelerator to the provers of other machines (see
+// the backend/accel/remote package), for instance to the nodes without a GPU solving the
+// witnesses, which register the address with remote.Register. The clients must know the token
+// of the -accel-token file (at least 16 bytes, hex-encoded), given to remote.WithToken. With
+// -accel-tls-cert and -accel-tls-key, the accelerator is served over TLS, and with
+// -accel-client-ca, only to the clients presenting a certificate of that CA.
+//
+// The command is a module of its own, so that the gnark module doesn't depend on the NATS,
+// Kafka and gRPC clients; build it from its directory.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/consensys/gnark/backend/accel/remote"
+	"github.com/consensys/gnark/logger"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", ":8080", "listen address")
+		grpcAddr  = flag.String("grpc-addr", "", "listen address of the gRPC API; not served if empty")
+		adminAddr = flag.String("admin-addr", "localhost:8081", "listen address of the administration API; not served if empty")
+		cfgPath   = flag.String("config", "proverd.json", "configuration file")
+		devices   = flag.String("devices", "", "accelerator providers of the worker pools, comma separated, e.g. icicle/0,icicle/1; one pool on the default provider if empty")
+		nbWorkers = flag.Int("workers", 1, "number of concurrent proofs per device")
+		queueSize = flag.Int("queue", 64, "number of queued proof requests")
+
+		natsURL      = flag.String("nats", "", "URLs of the NATS servers of the proof jobs, comma separated, nats://[user:password@]host[:port] or tls://...; none if empty")
+		natsJobs     = flag.String("nats-jobs", "proverd.jobs", "NATS subject of the proof jobs")
+		natsGroup    = flag.String("nats-group", "proverd", "NATS queue group of the proverd instances")
+		natsResults  = flag.String("nats-results", "proverd.results", "NATS subject of the results of the jobs without a reply subject")
+		kafkaBrokers = flag.String("kafka", "", "addresses of the Kafka brokers of the proof jobs, comma separated, host:port; none if empty")
+		kafkaJobs    = flag.String("kafka-jobs", "proverd.jobs", "Kafka topic of the proof jobs")
+		kafkaGroup   = flag.String("kafka-group", "proverd", "Kafka consumer group of the proverd instances")
+		kafkaResults = flag.String("kafka-results", "proverd.results", "Kafka topic of the results of the jobs without a reply-topic header")
+
+		cacheLen = flag.Int("result-cache", 1024, "number of job results kept by job ID")
+
+		accelAddr      = flag.String("accel-addr", "", "listen address of the remote accelerator protocol; not served if empty")
+		accelTokenPath = flag.String("accel-token", "", "file of the hex-encoded token of the remote accelerator clients, required with -accel-addr")
+		accelCert      = flag.String("accel-tls-cert", "", "certificate file of the remote accelerator server; served without TLS if empty")
+		accelKey       = flag.String("accel-tls-key", "", "key file of the certificate of -accel-tls-cert")
+		accelClientCA  = flag.String("accel-client-ca", "", "CA file of the certificates required from the remote accelerator clients; not required if empty")
+
+		witnessKeyPath = flag.String("witness-key", "", "file of the hex-encoded AES-256 key of the sealed witnesses; the witnesses are not sealed if empty")
+	)
+	flag.Parse()
+
+	log := logger.Logger()
+	if *nbWorkers < 1 || *queueSize < 0 {
+		log.Fatal().Msg("invalid number of workers or queue size")
+	}
+
+	reg := &registry{configPath: *cfgPath, devices: strings.Split(*devices, ",")}
+	if err := reg.reload(); err != nil {
+		log.Fatal().Err(err).Msg("loading the configuration")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var witnessKey []byte
+	if *witnessKeyPath != "" {
+		var err error
+		if witnessKey, err = readWitnessKey(*witnessKeyPath); err != nil {
+			log.Fatal().Err(err).Msg("reading the witness key")
+		}
+	}
+	s := newServer(reg, *nbWorkers, *queueSize, witnessKey)
+	s.run(ctx)
+	// the jobs delivered by both queues are proved once
+	cache := newResultCache(*cacheLen)
+	if *natsURL != "" {
+		go s.consumeNATS(ctx, natsConfig{url: *natsURL, jobs: *natsJobs, group: *natsGroup, results: *natsResults}, cache)
+	}
+	if *kafkaBrokers != "" {
+		brokers := strings.Split(*kafkaBrokers, ",")
+		go s.consumeKafka(ctx, kafkaConfig{brokers: brokers, jobs: *kafkaJobs, group: *kafkaGroup, results: *kafkaResults}, cache)
+	}
+
+	var accelServer *remote.Server
+	if *accelAddr != "" {
+		if *accelTokenPath == "" {
+			log.Fatal().Msg("-accel-addr requires -accel-token")
+		}
+		token, err := readAccelToken(*accelTokenPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("reading the accelerator token")
+		}
+		accelOpts := []remote.ServerOption{remote.RequireToken(token)}
+		if *accelCert != "" {
+			cfg, err := accelTLSConfig(*accelCert, *accelKey, *accelClientCA)
+			if err != nil {
+				log.Fatal().Err(err).Msg("loading the accelerator TLS configuration")
+			}
+			accelOpts = append(accelOpts, remote.WithServerTLS(cfg))
+		} else if *accelClientCA != "" {
+			log.Fatal().Msg("-accel-client-ca requires -accel-tls-cert")
+		}
+		l, err := net.Listen("tcp", *accelAddr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("listening for the remote accelerator clients")
+		}
+		accelServer = remote.NewServer("", accelOpts...)
+		go func() {
+			if err := accelServer.Serve(l); err != nil && !errors.Is(err, remote.ErrServerClosed) {
+				log.Fatal().Err(err).Msg("serving the remote accelerator")
+			}
+		}()
+	}
+
+	httpServer := &http.Server{Addr: *addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("serving")
+		}
+	}()
+	var adminServer *http.Server
+	if *adminAddr != "" {
+		adminServer = &http.Server{Addr: *adminAddr, Handler: s.adminHandler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal().Err(err).Msg("serving the administration API")
+			}
+		}()
+	}
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		l, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("listening for the gRPC clients")
+		}
+		grpcServer = s.grpcServer()
+		go func() {
+			if err := grpcServer.Serve(l); err != nil {
+				log.Fatal().Err(err).Msg("serving the gRPC API")
+			}
+		}()
+	}
+	log.Info().Str("addr", *addr).Int("circuits", len(reg.list())).Msg("proverd started")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			if err := reg.reload(); err != nil {
+				log.Error().Err(err).Msg("reloading the configuration")
+			} else {
+				log.Info().Int("circuits", len(reg.list())).Msg("configuration reloaded")
+			}
+			continue
+		}
+		break
+	}
+
+	// let the requests in progress complete, then stop the workers
+	log.Info().Msg("shutting down")
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	if err := httpServer.Shutdown(context.Background()); err != nil {
+		log.Error().Err(err).Msg("shutting down")
+	}
+	if adminServer != nil {
+		_ = adminServer.Close()
+	}
+	if accelServer != nil {
+		_ = accelServer.Close()
+	}
+}
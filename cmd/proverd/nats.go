@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/nats-io/nats.go"
+)
+
+// natsConfig configures the consumption of the jobs of a NATS server.
+type natsConfig struct {
+	url     string
+	jobs    string // the subject of the jobs
+	group   string // the queue group of the proverd instances, which share the jobs
+	results string // the subject of the results of the jobs without a reply subject
+}
+
+// consumeNATS proves the jobs of the NATS subject until ctx is done. The client reconnects by
+// itself when the connection drops; consumeNATS connects again, with a backoff, when the server
+// can't be reached or the connection is closed. The jobs share the workers and the queue of
+// the HTTP API. The messages are read as long as the queue has room; beyond, they wait in the
+// pending buffer of the subscription, and the client drops the messages of a subscription late
+// for too long (slow consumer), so that the queue group should have enough instances for the
+// load.
+//
+// Core NATS delivers each message at most once; the result cache makes the consumption
+// idempotent for the clients which retry a job with the same ID.
+func (s *server) consumeNATS(ctx context.Context, cfg natsConfig, cache *resultCache) {
+	log := logger.Logger().With().Str("jobs", cfg.jobs).Logger()
+	var delay time.Duration
+	for ctx.Err() == nil {
+		conn, err := nats.Connect(cfg.url, nats.Name("proverd"), nats.MaxReconnects(-1),
+			nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+				log.Error().Err(err).Msg("consuming the proof jobs")
+			}))
+		if err == nil {
+			log.Info().Str("url", redactURL(cfg.url)).Msg("consuming the proof jobs")
+			delay = 0
+			err = s.consumeNATSConn(ctx, conn, cfg, cache)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		delay = backoff(delay)
+		log.Error().Err(err).Dur("retry", delay).Msg("consuming the proof jobs")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *server) consumeNATSConn(ctx context.Context, conn *nats.Conn, cfg natsConfig, cache *resultCache) error {
+	defer conn.Close()
+
+	// the queue group shares the jobs between the instances
+	sub, err := conn.QueueSubscribeSync(cfg.jobs, cfg.group)
+	if err != nil {
+		return err
+	}
+	// the jobs being proved or queued, bounded like the queue of the HTTP API
+	pending := make(chan struct{}, s.nbWorkers+cap(s.queue))
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if errors.Is(err, nats.ErrSlowConsumer) {
+			// the messages dropped are reported by the error handler, the next ones are delivered
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case pending <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		go func() {
+			defer func() { <-pending }()
+			reply := msg.Reply
+			if reply == "" {
+				reply = cfg.results
+			}
+			data, ok := s.runJob(ctx, msg.Data, cache)
+			if !ok {
+				return
+			}
+			if err := conn.Publish(reply, data); err != nil {
+				log := logger.Logger()
+				log.Error().Err(err).Msg("publishing the result of a proof job")
+			}
+		}()
+	}
+}
+
+// redactURL removes the credentials of the URL, for the logs.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid URL"
+	}
+	u.User = nil
+	return u.String()
+}
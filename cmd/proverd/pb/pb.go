@@ -0,0 +1,4 @@
+// Package pb is the gRPC API of the proverd command, generated from proverd.proto.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proverd.proto
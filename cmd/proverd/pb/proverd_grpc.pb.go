@@ -0,0 +1,158 @@
+// The gRPC API of the proverd command, equivalent to its HTTP API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proverd.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Prover_Prove_FullMethodName  = "/gnark.proverd.Prover/Prove"
+	Prover_Status_FullMethodName = "/gnark.proverd.Prover/Status"
+)
+
+// ProverClient is the client API for Prover service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProverClient interface {
+	// Prove proves the circuit with the full witness. It fails with NOT_FOUND if the circuit is
+	// unknown, INVALID_ARGUMENT if the witness can't be decoded, RESOURCE_EXHAUSTED if the queue
+	// is full, and FAILED_PRECONDITION if the witness doesn't solve the circuit.
+	Prove(ctx context.Context, in *ProveRequest, opts ...grpc.CallOption) (*ProveResponse, error)
+	// Status returns the circuits, the queue, the counters of the devices and the utilization of
+	// the GPUs.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type proverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProverClient(cc grpc.ClientConnInterface) ProverClient {
+	return &proverClient{cc}
+}
+
+func (c *proverClient) Prove(ctx context.Context, in *ProveRequest, opts ...grpc.CallOption) (*ProveResponse, error) {
+	out := new(ProveResponse)
+	err := c.cc.Invoke(ctx, Prover_Prove_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Prover_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProverServer is the server API for Prover service.
+// All implementations must embed UnimplementedProverServer
+// for forward compatibility
+type ProverServer interface {
+	// Prove proves the circuit with the full witness. It fails with NOT_FOUND if the circuit is
+	// unknown, INVALID_ARGUMENT if the witness can't be decoded, RESOURCE_EXHAUSTED if the queue
+	// is full, and FAILED_PRECONDITION if the witness doesn't solve the circuit.
+	Prove(context.Context, *ProveRequest) (*ProveResponse, error)
+	// Status returns the circuits, the queue, the counters of the devices and the utilization of
+	// the GPUs.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	mustEmbedUnimplementedProverServer()
+}
+
+// UnimplementedProverServer must be embedded to have forward compatible implementations.
+type UnimplementedProverServer struct {
+}
+
+func (UnimplementedProverServer) Prove(context.Context, *ProveRequest) (*ProveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prove not implemented")
+}
+func (UnimplementedProverServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedProverServer) mustEmbedUnimplementedProverServer() {}
+
+// UnsafeProverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProverServer will
+// result in compilation errors.
+type UnsafeProverServer interface {
+	mustEmbedUnimplementedProverServer()
+}
+
+func RegisterProverServer(s grpc.ServiceRegistrar, srv ProverServer) {
+	s.RegisterService(&Prover_ServiceDesc, srv)
+}
+
+func _Prover_Prove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverServer).Prove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prover_Prove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverServer).Prove(ctx, req.(*ProveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prover_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prover_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Prover_ServiceDesc is the grpc.ServiceDesc for Prover service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Prover_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gnark.proverd.Prover",
+	HandlerType: (*ProverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Prove",
+			Handler:    _Prover_Prove_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Prover_Status_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proverd.proto",
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/logger"
+)
+
+// maxWitnessSize bounds the size of the witnesses accepted by the service.
+const maxWitnessSize = 1 << 30
+
+// job is a proof request, queued until a worker picks it.
+type job struct {
+	ctx     context.Context
+	circuit *circuit
+	witness witness.Witness
+	done    chan result
+}
+
+type result struct {
+	proof    groth16.Proof
+	duration time.Duration
+	err      error
+}
+
+// server queues the proof requests and proves them with a pool of workers per device.
+type server struct {
+	registry  *registry
+	queue     chan job
+	pools     []*pool
+	nbWorkers int // of all the pools
+
+	// witnessKey opens the sealed witnesses (see witness.Seal), or is nil if the witnesses
+	// are not sealed
+	witnessKey []byte
+
+	nbRejected atomic.Int64
+}
+
+// pool is the workers of a device of the registry, which share the queue with the other pools.
+type pool struct {
+	index     int    // in the devices of the registry, and the proving keys of the circuits
+	device    string // the name of the accelerator provider, empty for the default one
+	nbWorkers int
+
+	busy, nbProofs, nbFailures atomic.Int64
+}
+
+// newServer returns a server with nbWorkers workers per device of the registry.
+func newServer(r *registry, nbWorkers, queueSize int, witnessKey []byte) *server {
+	s := &server{registry: r, queue: make(chan job, queueSize), nbWorkers: nbWorkers * len(r.devices), witnessKey: witnessKey}
+	for i, device := range r.devices {
+		s.pools = append(s.pools, &pool{index: i, device: device, nbWorkers: nbWorkers})
+	}
+	return s
+}
+
+// run starts the workers, which stop when ctx is done.
+func (s *server) run(ctx context.Context) {
+	for _, p := range s.pools {
+		for i := 0; i < p.nbWorkers; i++ {
+			go s.work(ctx, p)
+		}
+	}
+}
+
+func (s *server) work(ctx context.Context, p *pool) {
+	var opts []backend.ProverOption
+	if p.device != "" {
+		opts = append(opts, backend.WithAccelerator(p.device))
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			if err := j.ctx.Err(); err != nil {
+				// the client is gone
+				j.done <- result{err: err}
+				continue
+			}
+			p.busy.Add(1)
+			start := time.Now()
+			proof, err := groth16.Prove(j.circuit.ccs, j.circuit.pks[p.index], j.witness, opts...)
+			p.busy.Add(-1)
+			if err != nil {
+				p.nbFailures.Add(1)
+			} else {
+				p.nbProofs.Add(1)
+			}
+			j.done <- result{proof: proof, duration: time.Since(start), err: err}
+		}
+	}
+}
+
+// errQueueFull is returned by prove when the queue has no room for the proof.
+var errQueueFull = errors.New("queue full")
+
+// prove queues the proof of the witness and waits for it until ctx is done. If the queue is
+// full, it waits for room if wait is set, or else returns errQueueFull. The error of the
+// result is the one of the prover, or of ctx.
+func (s *server) prove(ctx context.Context, c *circuit, wit witness.Witness, wait bool) result {
+	j := job{ctx: ctx, circuit: c, witness: wit, done: make(chan result, 1)}
+	if wait {
+		select {
+		case s.queue <- j:
+		case <-ctx.Done():
+			return result{err: ctx.Err()}
+		}
+	} else {
+		select {
+		case s.queue <- j:
+		default:
+			s.nbRejected.Add(1)
+			return result{err: errQueueFull}
+		}
+	}
+	select {
+	case res := <-j.done:
+		return res
+	case <-ctx.Done():
+		// the workers stop with the consumption
+		return result{err: ctx.Err()}
+	}
+}
+
+// handler returns the handler of the HTTP API: the proofs and the status.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/prove/", s.handleProve)
+	mux.HandleFunc("/v1/status", s.handleStatus)
+	return mux
+}
+
+// adminHandler returns the handler of the administration API, served on its own listener: the
+// reloads and the status.
+func (s *server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", s.handleStatus)
+	mux.HandleFunc("/v1/reload", s.handleReload)
+	return mux
+}
+
+// handleProve proves the circuit named in the path, with the full witness in the body encoded
+// with witness.MarshalBinary, or sealed (see decodeWitness). It responds with the proof encoded
+// with WriteTo, and its duration in the X-Prove-Duration header.
+func (s *server) handleProve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/v1/prove/")
+	c, ok := s.registry.get(name)
+	if !ok {
+		http.Error(w, "unknown circuit "+strconv.Quote(name), http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxWitnessSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	wit, err := s.decodeWitness(c, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := s.prove(r.Context(), c, wit, false)
+	if res.err != nil {
+		if res.err == errQueueFull {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, res.err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(res.err, context.Canceled) || errors.Is(res.err, context.DeadlineExceeded) {
+			return
+		}
+		// the witness doesn't solve the circuit
+		http.Error(w, res.err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := res.proof.WriteTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Prove-Duration", res.duration.String())
+	_, _ = w.Write(buf.Bytes())
+}
+
+// decodeWitness decodes a full witness of the circuit, encoded with witness.MarshalBinary. With
+// a witness key, the witness must be sealed with it by witness.Seal, with the name of the
+// circuit as additional data.
+func (s *server) decodeWitness(c *circuit, data []byte) (witness.Witness, error) {
+	wit, err := witness.New(c.curve.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	if s.witnessKey != nil {
+		err = witness.Open(wit, s.witnessKey, data, []byte(c.Name))
+	} else {
+		err = wit.UnmarshalBinary(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid witness: %w", err)
+	}
+	return wit, nil
+}
+
+// status is the response of /v1/status. The counters of the server sum the ones of the
+// devices.
+type status struct {
+	Circuits  []circuitStatus `json:"circuits"`
+	Workers   int             `json:"workers"`
+	Busy      int64           `json:"busy"`
+	Queued    int             `json:"queued"`
+	QueueSize int             `json:"queueSize"`
+	Proofs    int64           `json:"proofs"`
+	Failures  int64           `json:"failures"`
+	Rejected  int64           `json:"rejected"`
+	Devices   []deviceStatus  `json:"devices"`
+	GPUs      []gpuStatus     `json:"gpus,omitempty"`
+	GPUError  string          `json:"gpuError,omitempty"`
+}
+
+// deviceStatus is the status of the worker pool of a device.
+type deviceStatus struct {
+	Name     string `json:"name"` // empty for the default provider
+	Workers  int    `json:"workers"`
+	Busy     int64  `json:"busy"`
+	Proofs   int64  `json:"proofs"`
+	Failures int64  `json:"failures"`
+}
+
+type circuitStatus struct {
+	Name          string `json:"name"`
+	Curve         string `json:"curve"`
+	NbConstraints int    `json:"nbConstraints"`
+}
+
+// status returns the status of the server.
+func (s *server) status(ctx context.Context) status {
+	st := status{
+		Workers:   s.nbWorkers,
+		Queued:    len(s.queue),
+		QueueSize: cap(s.queue),
+		Rejected:  s.nbRejected.Load(),
+	}
+	for _, p := range s.pools {
+		d := deviceStatus{Name: p.device, Workers: p.nbWorkers, Busy: p.busy.Load(), Proofs: p.nbProofs.Load(), Failures: p.nbFailures.Load()}
+		st.Busy += d.Busy
+		st.Proofs += d.Proofs
+		st.Failures += d.Failures
+		st.Devices = append(st.Devices, d)
+	}
+	for _, c := range s.registry.list() {
+		st.Circuits = append(st.Circuits, circuitStatus{Name: c.Name, Curve: c.curve.String(), NbConstraints: c.ccs.GetNbConstraints()})
+	}
+	sort.Slice(st.Circuits, func(i, j int) bool { return st.Circuits[i].Name < st.Circuits[j].Name })
+
+	gpus, err := queryGPUs(ctx)
+	if err != nil {
+		st.GPUError = err.Error()
+	}
+	st.GPUs = gpus
+	return st
+}
+
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.status(r.Context()))
+}
+
+// handleReload reloads the configuration, as SIGHUP does.
+func (s *server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.registry.reload(); err != nil {
+		log := logger.Logger()
+		log.Error().Err(err).Msg("reloading the configuration")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/cmd/proverd/pb"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// testService is a server of the square circuit, whose files are in dir.
+type testService struct {
+	*server
+	dir string
+	vk  groth16.VerifyingKey
+}
+
+// newTestService returns a server of the square circuit, with nbWorkers workers per device,
+// which are not started.
+func newTestService(t *testing.T, devices []string, nbWorkers, queueSize int) *testService {
+	assert := require.New(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	dir := t.TempDir()
+	for name, w := range map[string]interface {
+		WriteTo(w io.Writer) (int64, error)
+	}{"square.r1cs": ccs, "square.pk": pk} {
+		var buf bytes.Buffer
+		_, err := w.WriteTo(&buf)
+		assert.NoError(err)
+		assert.NoError(os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o600))
+	}
+	ts := &testService{dir: dir, vk: vk}
+	ts.writeConfig(t, "square")
+
+	reg := &registry{configPath: filepath.Join(dir, "proverd.json"), devices: devices}
+	assert.NoError(reg.reload())
+	ts.server = newServer(reg, nbWorkers, queueSize, nil)
+	return ts
+}
+
+// writeConfig writes the configuration of circuits with the files of the square circuit.
+func (ts *testService) writeConfig(t *testing.T, names ...string) {
+	var cfg config
+	for _, name := range names {
+		cfg.Circuits = append(cfg.Circuits, circuitConfig{
+			Name:  name,
+			Curve: "bn254",
+			R1CS:  filepath.Join(ts.dir, "square.r1cs"),
+			PK:    filepath.Join(ts.dir, "square.pk"),
+		})
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(ts.dir, "proverd.json"), data, 0o600))
+}
+
+func squareWitness(t *testing.T, x, y int) []byte {
+	wit, err := frontend.NewWitness(&squareCircuit{X: x, Y: y}, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	data, err := wit.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+// verify verifies the proof, encoded with WriteTo, of the square of x.
+func (ts *testService) verify(t *testing.T, proof []byte, x int) {
+	assert := require.New(t)
+	p := groth16.NewProof(ecc.BN254)
+	_, err := p.ReadFrom(bytes.NewReader(proof))
+	assert.NoError(err)
+	public, err := frontend.NewWitness(&squareCircuit{Y: x * x}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(p, ts.vk, public))
+}
+
+func TestProve(t *testing.T) {
+	assert := require.New(t)
+	ts := newTestService(t, []string{""}, 1, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts.run(ctx)
+	srv := httptest.NewServer(ts.handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/v1/prove/square", "application/octet-stream", bytes.NewReader(squareWitness(t, 3, 9)))
+	assert.NoError(err)
+	var proof bytes.Buffer
+	_, err = proof.ReadFrom(resp.Body)
+	resp.Body.Close()
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, resp.StatusCode, proof.String())
+	assert.NotEmpty(resp.Header.Get("X-Prove-Duration"))
+	ts.verify(t, proof.Bytes(), 3)
+
+	for _, tc := range []struct {
+		path    string
+		witness []byte
+		status  int
+	}{
+		{"/v1/prove/unknown", squareWitness(t, 3, 9), http.StatusNotFound},
+		{"/v1/prove/square", []byte("invalid"), http.StatusBadRequest},
+		{"/v1/prove/square", squareWitness(t, 3, 10), http.StatusUnprocessableEntity},
+	} {
+		resp, err := http.Post(srv.URL+tc.path, "application/octet-stream", bytes.NewReader(tc.witness))
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(tc.status, resp.StatusCode, tc.path)
+	}
+}
+
+func TestProveQueueFull(t *testing.T) {
+	assert := require.New(t)
+	// without room in the queue, nor workers to take the jobs
+	ts := newTestService(t, []string{""}, 1, 0)
+	srv := httptest.NewServer(ts.handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/v1/prove/square", "application/octet-stream", bytes.NewReader(squareWitness(t, 3, 9)))
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal("1", resp.Header.Get("Retry-After"))
+	assert.EqualValues(1, ts.nbRejected.Load())
+}
+
+func TestReload(t *testing.T) {
+	assert := require.New(t)
+	ts := newTestService(t, []string{""}, 1, 4)
+	admin := httptest.NewServer(ts.adminHandler())
+	defer admin.Close()
+	public := httptest.NewServer(ts.handler())
+	defer public.Close()
+
+	reload := func(url string) int {
+		resp, err := http.Post(url+"/v1/reload", "", nil)
+		assert.NoError(err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// the reloads are only served by the administration API
+	ts.writeConfig(t, "square", "square2")
+	assert.Equal(http.StatusNotFound, reload(public.URL))
+	_, ok := ts.registry.get("square2")
+	assert.False(ok)
+
+	old, _ := ts.registry.get("square")
+	assert.Equal(http.StatusNoContent, reload(admin.URL))
+	_, ok = ts.registry.get("square2")
+	assert.True(ok)
+	c, _ := ts.registry.get("square")
+	assert.True(c == old, "the unchanged circuits must be kept")
+
+	resp, err := http.Get(admin.URL + "/v1/reload")
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// an invalid configuration leaves the circuits untouched
+	assert.NoError(os.WriteFile(ts.registry.configPath, []byte("{"), 0o600))
+	assert.Equal(http.StatusInternalServerError, reload(admin.URL))
+	assert.Len(ts.registry.list(), 2)
+}
+
+func TestStatus(t *testing.T) {
+	assert := require.New(t)
+	// a pool on the default provider and a pool on the CPU emulation
+	ts := newTestService(t, []string{"", "cpu"}, 2, 8)
+	c, _ := ts.registry.get("square")
+	assert.Len(c.pks, 2)
+	assert.Equal("cpu", c.pks[1].(groth16.DeviceProvingKey).Accelerator())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts.run(ctx)
+	srv := httptest.NewServer(ts.handler())
+	defer srv.Close()
+
+	const nbProofs = 6
+	var wg sync.WaitGroup
+	statusCodes, proofs, errs := make([]int, nbProofs), make([][]byte, nbProofs), make([]error, nbProofs)
+	witnesses := make([][]byte, nbProofs)
+	for i := range witnesses {
+		witnesses[i] = squareWitness(t, i+2, (i+2)*(i+2))
+	}
+	for i := 0; i < nbProofs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Post(srv.URL+"/v1/prove/square", "application/octet-stream", bytes.NewReader(witnesses[i]))
+			if errs[i] = err; err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			var proof bytes.Buffer
+			_, errs[i] = proof.ReadFrom(resp.Body)
+			statusCodes[i], proofs[i] = resp.StatusCode, proof.Bytes()
+		}(i)
+	}
+	wg.Wait()
+	for i := range proofs {
+		assert.NoError(errs[i])
+		assert.Equal(http.StatusOK, statusCodes[i], string(proofs[i]))
+		ts.verify(t, proofs[i], i+2)
+	}
+
+	resp, err := http.Get(srv.URL + "/v1/status")
+	assert.NoError(err)
+	var st status
+	err = json.NewDecoder(resp.Body).Decode(&st)
+	resp.Body.Close()
+	assert.NoError(err)
+
+	assert.Equal([]circuitStatus{{Name: "square", Curve: "bn254", NbConstraints: c.ccs.GetNbConstraints()}}, st.Circuits)
+	assert.Equal(4, st.Workers)
+	assert.Equal(8, st.QueueSize)
+	assert.EqualValues(nbProofs, st.Proofs)
+	assert.Len(st.Devices, 2)
+	var nbDeviceProofs int64
+	for i, d := range st.Devices {
+		assert.Equal([]string{"", "cpu"}[i], d.Name)
+		assert.Equal(2, d.Workers)
+		nbDeviceProofs += d.Proofs
+	}
+	assert.EqualValues(nbProofs, nbDeviceProofs)
+}
+
+func TestUnknownDevice(t *testing.T) {
+	ts := newTestService(t, []string{""}, 1, 1)
+	reg := &registry{configPath: ts.registry.configPath, devices: []string{"unknown"}}
+	require.Error(t, reg.reload())
+}
+
+func TestGRPC(t *testing.T) {
+	assert := require.New(t)
+	ts := newTestService(t, []string{""}, 1, 0)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	srv := ts.grpcServer()
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(err)
+	defer conn.Close()
+	client := pb.NewProverClient(conn)
+	ctx := context.Background()
+
+	// the workers are not started yet
+	_, err = client.Prove(ctx, &pb.ProveRequest{Circuit: "square", Witness: squareWitness(t, 3, 9)})
+	assert.Equal(codes.ResourceExhausted, grpcstatus.Code(err), fmt.Sprint(err))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ts.run(runCtx)
+	for _, tc := range []struct {
+		circuit string
+		witness []byte
+		code    codes.Code
+	}{
+		{"unknown", squareWitness(t, 3, 9), codes.NotFound},
+		{"square", []byte("invalid"), codes.InvalidArgument},
+		{"square", squareWitness(t, 3, 10), codes.FailedPrecondition},
+	} {
+		_, err = client.Prove(ctx, &pb.ProveRequest{Circuit: tc.circuit, Witness: tc.witness})
+		assert.Equal(tc.code, grpcstatus.Code(err), fmt.Sprint(err))
+	}
+	// the unbuffered queue takes the job once a worker waits for it
+	var res *pb.ProveResponse
+	for i := 0; i < 100; i++ {
+		if res, err = client.Prove(ctx, &pb.ProveRequest{Circuit: "square", Witness: squareWitness(t, 3, 9)}); grpcstatus.Code(err) != codes.ResourceExhausted {
+			break
+		}
+	}
+	assert.NoError(err)
+	ts.verify(t, res.Proof, 3)
+
+	st, err := client.Status(ctx, &pb.StatusRequest{})
+	assert.NoError(err)
+	assert.Len(st.Circuits, 1)
+	assert.EqualValues(1, st.Proofs)
+	assert.Len(st.Devices, 1)
+}
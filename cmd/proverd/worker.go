@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// queueJob is a proof job consumed from a job queue (NATS subject or Kafka topic), in JSON.
+type queueJob struct {
+	// ID identifies the job: the jobs delivered several times with the same ID are proved once,
+	// see resultCache.
+	ID      string `json:"id"`
+	Circuit string `json:"circuit"`
+
+	// Witness is the full witness, encoded with witness.MarshalBinary or sealed (base64 in
+	// JSON), see decodeWitness.
+	Witness []byte `json:"witness"`
+}
+
+// queueResult is the result of a queueJob, published on the reply subject or topic of the job
+// if it has one, or else on the result subject or topic.
+type queueResult struct {
+	ID string `json:"id"`
+
+	// Proof is the proof encoded with WriteTo, or Error the reason of the failure.
+	Proof    []byte `json:"proof,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// digest identifies the payload of a job, so that a job ID reused for another job is told
+// apart from a job delivered again.
+func (j *queueJob) digest() [sha256.Size]byte {
+	h := sha256.New()
+	_ = json.NewEncoder(h).Encode([]string{j.Circuit})
+	h.Write(j.Witness)
+	var d [sha256.Size]byte
+	h.Sum(d[:0])
+	return d
+}
+
+// runJob decodes the job of a message, proves it or reuses the result of the job with the
+// same ID, and returns the encoded result. It returns false if the result must not be
+// published, the job being interrupted by the shutdown.
+func (s *server) runJob(ctx context.Context, data []byte, cache *resultCache) ([]byte, bool) {
+	var j queueJob
+	if err := json.Unmarshal(data, &j); err != nil {
+		return encodeResult(queueResult{Error: "invalid job: " + err.Error()}), true
+	}
+	return cache.do(j.ID, j.digest(), func() ([]byte, bool) {
+		res := encodeResult(s.proveJob(ctx, j))
+		// the jobs interrupted by the shutdown are proved again at their next delivery
+		return res, ctx.Err() == nil
+	})
+}
+
+func encodeResult(res queueResult) []byte {
+	data, err := json.Marshal(res)
+	if err != nil {
+		data, _ = json.Marshal(queueResult{ID: res.ID, Error: err.Error()})
+	}
+	return data
+}
+
+// proveJob proves the job with the workers of the server.
+func (s *server) proveJob(ctx context.Context, j queueJob) queueResult {
+	res := queueResult{ID: j.ID}
+	c, ok := s.registry.get(j.Circuit)
+	if !ok {
+		res.Error = "unknown circuit " + j.Circuit
+		return res
+	}
+	wit, err := s.decodeWitness(c, j.Witness)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	r := s.prove(ctx, c, wit, true)
+	if r.err != nil {
+		res.Error = r.err.Error()
+		return res
+	}
+	var buf bytes.Buffer
+	if _, err := r.proof.WriteTo(&buf); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Proof, res.Duration = buf.Bytes(), r.duration.String()
+	return res
+}
+
+// resultCache holds the encoded results of the last jobs by ID, so that a job delivered several
+// times is proved once: the deliveries of a job in progress wait for its result. A job reusing
+// the ID of a cached job with another payload is rejected, and doesn't evict the cached one.
+type resultCache struct {
+	capacity int
+
+	lock    sync.Mutex
+	entries map[string]*list.Element // of *cachedResult
+	lru     *list.List               // the most recently used first
+}
+
+type cachedResult struct {
+	id     string
+	digest [sha256.Size]byte
+	done   chan struct{}
+	data   []byte
+	cached bool
+}
+
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{capacity: capacity, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// do returns the result of the job with the ID and the digest of its payload, computing it
+// with f if it isn't cached. f returns false if its result must not be cached nor published,
+// and do returns it. The jobs without an ID are not cached.
+func (c *resultCache) do(id string, digest [sha256.Size]byte, f func() ([]byte, bool)) ([]byte, bool) {
+	if id == "" || c.capacity <= 0 {
+		return f()
+	}
+
+	c.lock.Lock()
+	if e, ok := c.entries[id]; ok {
+		r := e.Value.(*cachedResult)
+		if r.digest != digest {
+			c.lock.Unlock()
+			return encodeResult(queueResult{ID: id, Error: "the job ID is reused for another circuit or witness"}), true
+		}
+		c.lru.MoveToFront(e)
+		c.lock.Unlock()
+		<-r.done
+		return r.data, r.cached
+	}
+	r := &cachedResult{id: id, digest: digest, done: make(chan struct{})}
+	c.entries[id] = c.lru.PushFront(r)
+	c.evict()
+	c.lock.Unlock()
+
+	r.data, r.cached = f()
+	close(r.done)
+	if !r.cached {
+		c.lock.Lock()
+		if e, ok := c.entries[id]; ok && e.Value == r {
+			c.lru.Remove(e)
+			delete(c.entries, id)
+		}
+		c.lock.Unlock()
+	}
+	return r.data, r.cached
+}
+
+// evict removes the least recently used results beyond the capacity. The results in progress
+// stay reachable by their waiters.
+func (c *resultCache) evict() {
+	for c.lru.Len() > c.capacity {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*cachedResult).id)
+	}
+}
+
+// backoff returns the delay before the next attempt to connect to a job queue, doubled from 1
+// second up to 30 seconds.
+func backoff(previous time.Duration) time.Duration {
+	if previous == 0 {
+		return time.Second
+	}
+	if previous *= 2; previous > 30*time.Second {
+		return 30 * time.Second
+	}
+	return previous
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache(t *testing.T) {
+	assert := require.New(t)
+	cache := newResultCache(2)
+	job1 := queueJob{ID: "job-1", Circuit: "transfer", Witness: []byte{1}}
+	nbCalls := 0
+	prove := func(data string, cached bool) func() ([]byte, bool) {
+		return func() ([]byte, bool) {
+			nbCalls++
+			return []byte(data), cached
+		}
+	}
+
+	// a job delivered again is answered from the cache
+	data, ok := cache.do(job1.ID, job1.digest(), prove("proof-1", true))
+	assert.True(ok)
+	assert.Equal("proof-1", string(data))
+	data, ok = cache.do(job1.ID, job1.digest(), prove("proof-2", true))
+	assert.True(ok)
+	assert.Equal("proof-1", string(data))
+	assert.Equal(1, nbCalls)
+
+	// a job reusing the ID with another witness or circuit is rejected
+	for _, j := range []queueJob{
+		{ID: job1.ID, Circuit: job1.Circuit, Witness: []byte{2}},
+		{ID: job1.ID, Circuit: "transfer2", Witness: job1.Witness},
+	} {
+		data, ok = cache.do(j.ID, j.digest(), prove("proof-3", true))
+		assert.True(ok)
+		var res queueResult
+		assert.NoError(json.Unmarshal(data, &res))
+		assert.Equal(job1.ID, res.ID)
+		assert.NotEmpty(res.Error)
+	}
+	assert.Equal(1, nbCalls)
+	data, _ = cache.do(job1.ID, job1.digest(), prove("proof-4", true))
+	assert.Equal("proof-1", string(data), "the cached result must be kept")
+
+	// the results not cached are computed again
+	job2 := queueJob{ID: "job-2", Circuit: "transfer", Witness: []byte{1}}
+	_, ok = cache.do(job2.ID, job2.digest(), prove("interrupted", false))
+	assert.False(ok)
+	data, ok = cache.do(job2.ID, job2.digest(), prove("proof-5", true))
+	assert.True(ok)
+	assert.Equal("proof-5", string(data))
+
+	// the least recently used results are evicted
+	job3 := queueJob{ID: "job-3"}
+	cache.do(job3.ID, job3.digest(), prove("proof-6", true))
+	nbCalls = 0
+	data, _ = cache.do(job1.ID, job1.digest(), prove("proof-7", true))
+	assert.Equal("proof-7", string(data))
+	assert.Equal(1, nbCalls)
+}
@@ -47,29 +47,33 @@ func newCoeffTable(capacity int) CoeffTable {
 }
 
 func (ct *CoeffTable) AddCoeff(coeff constraint.Element) uint32 {
+	if cID, ok := ct.CoeffID(&coeff); ok {
+		return cID
+	}
+	cc := *(*fr.Element)(coeff[:])
+	cID := uint32(len(ct.Coefficients))
+	ct.Coefficients = append(ct.Coefficients, cc)
+	ct.mCoeffs[cc] = cID
+	return cID
+}
+
+// CoeffID returns the ID of the coefficient if the table holds it. It doesn't modify the table,
+// and may hence be called concurrently as long as no coefficient is added.
+func (ct *CoeffTable) CoeffID(coeff *constraint.Element) (uint32, bool) {
 	c := (*fr.Element)(coeff[:])
-	var cID uint32
 	if c.IsZero() {
-		cID = constraint.CoeffIdZero
+		return constraint.CoeffIdZero, true
 	} else if c.IsOne() {
-		cID = constraint.CoeffIdOne
+		return constraint.CoeffIdOne, true
 	} else if c.Equal(&two) {
-		cID = constraint.CoeffIdTwo
+		return constraint.CoeffIdTwo, true
 	} else if c.Equal(&minusOne) {
-		cID = constraint.CoeffIdMinusOne
+		return constraint.CoeffIdMinusOne, true
 	} else if c.Equal(&minusTwo) {
-		cID = constraint.CoeffIdMinusTwo
-	} else {
-		cc := *c
-		if id, ok := ct.mCoeffs[cc]; ok {
-			cID = id
-		} else {
-			cID = uint32(len(ct.Coefficients))
-			ct.Coefficients = append(ct.Coefficients, cc)
-			ct.mCoeffs[cc] = cID
-		}
+		return constraint.CoeffIdMinusTwo, true
 	}
-	return cID
+	cID, ok := ct.mCoeffs[*c]
+	return cID, ok
 }
 
 func (ct *CoeffTable) MakeTerm(coeff *constraint.Element, variableID int) constraint.Term {
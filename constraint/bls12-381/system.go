@@ -74,6 +74,15 @@ func (cs *system) Solve(witness witness.Witness, opts ...csolver.Option) (any, e
 		return nil, err
 	}
 
+	// the values spilled to disk are released after the logs are printed, unless the
+	// solution holds them
+	keepValues := false
+	defer func() {
+		if solver.releaseValues != nil && !keepValues {
+			_ = solver.releaseValues()
+		}
+	}()
+
 	// defer log printing once all solver.values are computed
 	// (or sooner, if a constraint is not satisfied)
 	defer solver.printLogs(cs.Logs)
@@ -94,6 +103,8 @@ func (cs *system) Solve(witness witness.Witness, opts ...csolver.Option) (any, e
 		res.A = solver.a
 		res.B = solver.b
 		res.C = solver.c
+		res.release = solver.releaseValues
+		keepValues = true
 		return &res, nil
 	} else {
 		// sparse R1CS
@@ -135,6 +146,17 @@ func (cs *system) GetNbCoefficients() int {
 	return len(cs.Coefficients)
 }
 
+// Hash returns the SHA-256 hash of the constraint system, with its coefficients, see
+// constraint.System.HashWithCoefficients.
+func (cs *system) Hash() []byte {
+	return cs.System.HashWithCoefficients(func(w io.Writer) {
+		for i := range cs.Coefficients {
+			b := cs.Coefficients[i].Bytes()
+			_, _ = w.Write(b[:])
+		}
+	})
+}
+
 // CurveID returns curve ID as defined in gnark-crypto
 func (cs *system) CurveID() ecc.ID {
 	return ecc.BLS12_381
@@ -260,6 +282,21 @@ func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []
 type R1CSSolution struct {
 	W       fr.Vector
 	A, B, C fr.Vector
+
+	release func() error // unmaps W if it is spilled to disk
+}
+
+// Release unmaps the wire values if the solver spilled them to disk (see
+// solver.WithSpillToDisk), after which the solution must not be used. It is a no-op
+// otherwise.
+func (t *R1CSSolution) Release() error {
+	if t.release == nil {
+		return nil
+	}
+	release := t.release
+	t.release = nil
+	t.W = nil
+	return release()
 }
 
 func (t *R1CSSolution) WriteTo(w io.Writer) (int64, error) {
@@ -360,6 +397,7 @@ func getTagSet() cbor.TagSet {
 	addType(reflect.TypeOf(constraint.BlueprintGenericSparseR1C{}))
 	addType(reflect.TypeOf(constraint.BlueprintSparseR1CAdd{}))
 	addType(reflect.TypeOf(constraint.BlueprintSparseR1CMul{}))
+	addType(reflect.TypeOf(constraint.BlueprintSparseR1CBool{}))
 
 	return ts
 }
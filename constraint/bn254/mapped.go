@@ -0,0 +1,253 @@
+package cs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// Mappable layout
+//
+//	header   -> [magic | uint32(byteOrderMark) | uint32(version) | uint32(sizeof(int)) | uint32(0) | nbSections x (uint64(offset), uint64(size))]
+//	sections -> [cbor(system without the sections below) | Instructions | CallData | Coefficients | level offsets | Levels | ConstraintPermutation]
+//
+// Header fields are little-endian. Sections are raw copies of the in-memory slices (native
+// byte order, Montgomery form for the coefficients), aligned on mappedAlign bytes, so that
+// they can be used in place once the file is mapped in memory.
+const (
+	mappedMagic         = "gnarkcs\x00"
+	mappedVersion       = 1
+	mappedByteOrderMark = 0x01020304
+	mappedAlign         = 64
+)
+
+const (
+	sectionMeta = iota
+	sectionInstructions
+	sectionCallData
+	sectionCoefficients
+	sectionLevelOffsets
+	sectionLevels
+	sectionPermutation
+	nbSections
+)
+
+const mappedHeaderSize = len(mappedMagic) + 4*4 + nbSections*16
+
+// WriteMappableTo encodes the constraint system into provided io.Writer in a layout which can
+// be mapped in memory with OpenMapped.
+//
+// The bulk of the system (instructions, calldata, coefficients and levels) is written as raw
+// memory; the resulting file can only be opened on a platform with the same byte order and
+// int size.
+func (cs *system) WriteMappableTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+
+	// everything but the large slices is cbor encoded.
+	meta := *cs
+	meta.Instructions = nil
+	meta.CallData = nil
+	meta.Coefficients = nil
+	meta.Levels = nil
+	meta.ConstraintPermutation = nil
+	var bMeta bytes.Buffer
+	if _, err := meta.WriteTo(&bMeta); err != nil {
+		return 0, err
+	}
+
+	levelOffsets := make([]uint64, len(cs.Levels)+1)
+	levels := make([][]byte, len(cs.Levels))
+	for i, level := range cs.Levels {
+		levelOffsets[i+1] = levelOffsets[i] + uint64(len(level))
+		levels[i] = asBytes(level)
+	}
+
+	sections := [nbSections][][]byte{
+		sectionMeta:         {bMeta.Bytes()},
+		sectionInstructions: {asBytes(cs.Instructions)},
+		sectionCallData:     {asBytes(cs.CallData)},
+		sectionCoefficients: {asBytes(cs.Coefficients)},
+		sectionLevelOffsets: {asBytes(levelOffsets)},
+		sectionLevels:       levels,
+		sectionPermutation:  {asBytes(cs.ConstraintPermutation)},
+	}
+
+	header := make([]byte, mappedHeaderSize)
+	copy(header, mappedMagic)
+	h := header[len(mappedMagic):]
+	binary.LittleEndian.PutUint32(h[4:], mappedVersion)
+	binary.LittleEndian.PutUint32(h[8:], uint32(unsafe.Sizeof(int(0))))
+	*(*uint32)(unsafe.Pointer(&h[0])) = mappedByteOrderMark // native byte order
+	offset := alignUp(uint64(mappedHeaderSize))
+	for i := range sections {
+		size := uint64(0)
+		for _, chunk := range sections[i] {
+			size += uint64(len(chunk))
+		}
+		binary.LittleEndian.PutUint64(h[16+16*i:], offset)
+		binary.LittleEndian.PutUint64(h[16+16*i+8:], size)
+		offset = alignUp(offset + size)
+	}
+
+	if _, err := _w.Write(header); err != nil {
+		return _w.N, err
+	}
+	var padding [mappedAlign]byte
+	for i := range sections {
+		if _, err := _w.Write(padding[:alignUp(uint64(_w.N))-uint64(_w.N)]); err != nil {
+			return _w.N, err
+		}
+		for _, chunk := range sections[i] {
+			if _, err := _w.Write(chunk); err != nil {
+				return _w.N, err
+			}
+		}
+	}
+
+	return _w.N, nil
+}
+
+// OpenMapped maps in memory a constraint system file written with WriteMappableTo.
+//
+// Instructions, calldata, coefficients and levels are not deserialized but point directly
+// into the mapped file, and are paged in by the operating system as the solver and the
+// prover iterate over them. They are read-only: the returned system must not be modified
+// (e.g. by adding constraints or calling ReorderInstructions).
+//
+// The returned io.Closer unmaps the file; the system must not be used after it is closed.
+func OpenMapped(path string) (*system, io.Closer, error) {
+	data, err := mapFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cs, err := readMapped(data)
+	if err != nil {
+		_ = unmapFile(data)
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cs, mapping(data), nil
+}
+
+// IsMappableFile reports whether the file at path was written with WriteMappableTo.
+func IsMappableFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var magic [len(mappedMagic)]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic[:]) == mappedMagic, nil
+}
+
+type mapping []byte
+
+func (m mapping) Close() error {
+	return unmapFile(m)
+}
+
+func readMapped(data []byte) (*system, error) {
+	if len(data) < mappedHeaderSize || string(data[:len(mappedMagic)]) != mappedMagic {
+		return nil, errors.New("not a mappable constraint system")
+	}
+	h := data[len(mappedMagic):]
+	if *(*uint32)(unsafe.Pointer(&h[0])) != mappedByteOrderMark {
+		return nil, errors.New("mappable constraint system written with a different byte order")
+	}
+	if v := binary.LittleEndian.Uint32(h[4:]); v != mappedVersion {
+		return nil, fmt.Errorf("unsupported mappable constraint system version %d", v)
+	}
+	if s := binary.LittleEndian.Uint32(h[8:]); s != uint32(unsafe.Sizeof(int(0))) {
+		return nil, fmt.Errorf("mappable constraint system written with %d bytes ints", s)
+	}
+
+	var sections [nbSections][]byte
+	for i := range sections {
+		offset := binary.LittleEndian.Uint64(h[16+16*i:])
+		size := binary.LittleEndian.Uint64(h[16+16*i+8:])
+		if offset%mappedAlign != 0 || offset > uint64(len(data)) || size > uint64(len(data))-offset {
+			return nil, fmt.Errorf("invalid section %d", i)
+		}
+		sections[i] = data[offset : offset+size : offset+size]
+	}
+
+	cs := &system{}
+	if _, err := cs.ReadFrom(bytes.NewReader(sections[sectionMeta])); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if cs.Instructions, err = fromBytes[constraint.Instruction](sections[sectionInstructions]); err != nil {
+		return nil, err
+	}
+	if cs.CallData, err = fromBytes[uint32](sections[sectionCallData]); err != nil {
+		return nil, err
+	}
+	if cs.Coefficients, err = fromBytes[fr.Element](sections[sectionCoefficients]); err != nil {
+		return nil, err
+	}
+	if cs.ConstraintPermutation, err = fromBytes[uint32](sections[sectionPermutation]); err != nil {
+		return nil, err
+	}
+
+	levelOffsets, err := fromBytes[uint64](sections[sectionLevelOffsets])
+	if err != nil {
+		return nil, err
+	}
+	levels, err := fromBytes[int](sections[sectionLevels])
+	if err != nil {
+		return nil, err
+	}
+	if len(levelOffsets) == 0 || levelOffsets[len(levelOffsets)-1] != uint64(len(levels)) {
+		return nil, errors.New("invalid levels")
+	}
+	cs.Levels = make([][]int, len(levelOffsets)-1)
+	for i := range cs.Levels {
+		start, end := levelOffsets[i], levelOffsets[i+1]
+		if start > end || end > uint64(len(levels)) {
+			return nil, errors.New("invalid levels")
+		}
+		cs.Levels[i] = levels[start:end:end]
+	}
+
+	return cs, nil
+}
+
+// asBytes returns the memory backing s.
+func asBytes[T any](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+// fromBytes returns a slice of T backed by b, which must be aligned for T.
+func fromBytes[T any](b []byte) ([]T, error) {
+	var t T
+	size := int(unsafe.Sizeof(t))
+	if len(b)%size != 0 {
+		return nil, fmt.Errorf("section size %d is not a multiple of %d", len(b), size)
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), len(b)/size), nil
+}
+
+func alignUp(n uint64) uint64 {
+	return (n + mappedAlign - 1) &^ (mappedAlign - 1)
+}
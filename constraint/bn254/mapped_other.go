@@ -0,0 +1,16 @@
+//go:build !unix
+
+package cs
+
+import (
+	"os"
+)
+
+// mapFile reads the file in memory, on platforms where mapping it isn't supported.
+func mapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func unmapFile([]byte) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package cs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestMapped(t *testing.T) {
+	dir := t.TempDir()
+
+	for name := range circuits.Circuits {
+		t.Run(name, func(t *testing.T) {
+			tc := circuits.Circuits[name]
+
+			ccs, err := frontend.Compile(fr.Modulus(), r1cs.NewBuilder, tc.Circuit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if testing.Short() && ccs.GetNbConstraints() > 50 {
+				return
+			}
+			r1cs1 := ccs.(*cs.R1CS)
+
+			path := filepath.Join(dir, name)
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r1cs1.WriteMappableTo(f); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			mapped, closer, err := cs.OpenMapped(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closer.Close()
+
+			if diff := cmp.Diff(r1cs1, mapped,
+				cmpopts.EquateEmpty(),
+				cmpopts.IgnoreFields(cs.R1CS{},
+					"System.q",
+					"field",
+					"CoeffTable.mCoeffs",
+					"System.lbWireLevel",
+					"System.lbHints",
+					"System.genericHint",
+					"System.SymbolTable",
+					"System.lbOutputs",
+					"System.bitLen",
+					"System.hash")); diff != "" {
+				t.Fatalf("round trip mismatch (-want +got):\n%s", diff)
+			}
+
+			// the solver runs on the mapped system
+			for _, assignment := range tc.ValidAssignments {
+				w, err := frontend.NewWitness(assignment, fr.Modulus())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := mapped.Solve(w, solver.WithHints(tc.HintFunctions...)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMappedInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid")
+	if err := os.WriteFile(path, []byte("not a constraint system"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cs.OpenMapped(path); err == nil {
+		t.Fatal("expected an error")
+	}
+}
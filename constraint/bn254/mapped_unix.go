@@ -0,0 +1,36 @@
+//go:build unix
+
+package cs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the file read-only in memory.
+func mapFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, errors.New("empty file")
+	}
+	if int64(int(size)) != size {
+		return nil, errors.New("file too large to be mapped")
+	}
+
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE)
+}
+
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,58 @@
+package cs_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/backend/circuits"
+)
+
+func TestSpillToDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	for name := range circuits.Circuits {
+		t.Run(name, func(t *testing.T) {
+			tc := circuits.Circuits[name]
+
+			ccs, err := frontend.Compile(fr.Modulus(), r1cs.NewBuilder, tc.Circuit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if testing.Short() && ccs.GetNbConstraints() > 50 {
+				return
+			}
+
+			for _, assignment := range tc.ValidAssignments {
+				w, err := frontend.NewWitness(assignment, fr.Modulus())
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := ccs.Solve(w, solver.WithHints(tc.HintFunctions...))
+				if err != nil {
+					t.Fatal(err)
+				}
+				spilled, err := ccs.Solve(w, solver.WithHints(tc.HintFunctions...), solver.WithSpillToDisk(dir))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want, got := expected.(*cs.R1CSSolution), spilled.(*cs.R1CSSolution)
+				if len(want.W) != len(got.W) {
+					t.Fatalf("got %d wires, expected %d", len(got.W), len(want.W))
+				}
+				for i := range want.W {
+					if !want.W[i].Equal(&got.W[i]) {
+						t.Fatalf("wire %d: got %s, expected %s", i, got.W[i].String(), want.W[i].String())
+					}
+				}
+				if err := got.Release(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -84,7 +84,8 @@ func TestSerialization(t *testing.T) {
 						"System.genericHint",
 						"System.SymbolTable",
 						"System.lbOutputs",
-						"System.bitLen")); diff != "" {
+						"System.bitLen",
+						"System.hash")); diff != "" {
 					t.Fatalf("round trip mismatch (-want +got):\n%s", diff)
 				}
 			}
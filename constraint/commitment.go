@@ -1,7 +1,9 @@
 package constraint
 
 import (
+	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/consensys/gnark/constraint/solver"
 )
@@ -60,3 +62,78 @@ func (i *Commitment) PrivateToPublic() []int {
 func (i *Commitment) PrivateCommitted() []int {
 	return i.Committed[i.NbPublicCommitted():]
 }
+
+// Commitments are the commitments of a constraint system, in the order they were added. In
+// groth16, each commitment has its own commitment key and proof of knowledge.
+type Commitments []Commitment
+
+// Is returns true if there is at least one commitment.
+func (c Commitments) Is() bool {
+	return len(c) != 0
+}
+
+// Single returns the commitment of a system with at most one commitment, or an empty
+// Commitment if there is none. It is meant for the backends supporting a single commitment
+// per circuit.
+func (c Commitments) Single() (Commitment, error) {
+	switch len(c) {
+	case 0:
+		return Commitment{}, nil
+	case 1:
+		return c[0], nil
+	default:
+		return Commitment{}, fmt.Errorf("%d commitments: only one commitment per circuit is supported by this backend", len(c))
+	}
+}
+
+// NbPrivateCommitted returns the total number of private variables committed to.
+func (c Commitments) NbPrivateCommitted() int {
+	n := 0
+	for i := range c {
+		n += c[i].NbPrivateCommitted
+	}
+	return n
+}
+
+// PrivateToPublic returns the sorted indexes of the variables which are private to the
+// constraint system but public to Groth16, over all the commitments.
+func (c Commitments) PrivateToPublic() []int {
+	res := make([]int, 0, c.NbPrivateCommitted()+len(c))
+	for i := range c {
+		res = append(res, c[i].PrivateToPublic()...)
+	}
+	sort.Ints(res)
+	return res
+}
+
+// checkDisjoint returns an error if the private variables committed to by o are committed to
+// by i, or if one of the commitments commits to the other one.
+func (i *Commitment) checkDisjoint(o *Commitment) error {
+	a, b := i.PrivateCommitted(), o.PrivateCommitted()
+	for len(a) != 0 && len(b) != 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			return fmt.Errorf("variable %d is already committed to", a[0])
+		}
+	}
+	if contains(o.Committed, i.CommitmentIndex) || contains(i.Committed, o.CommitmentIndex) {
+		return fmt.Errorf("committing to a commitment is not supported")
+	}
+	return nil
+}
+
+func contains(sorted []int, v int) bool {
+	for _, e := range sorted {
+		if e == v {
+			return true
+		}
+		if e > v {
+			break
+		}
+	}
+	return false
+}
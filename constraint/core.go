@@ -3,6 +3,7 @@ package constraint
 import (
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/blang/semver/v4"
 	"github.com/consensys/gnark"
@@ -72,6 +73,9 @@ type System struct {
 	// several constraints may point to the same debug info
 	MDebug map[int]int
 
+	// stacks of all the constraints, nil unless the source mapping is enabled
+	SourceMap *SourceMap
+
 	// maps hintID to hint string identifier
 	MHintsDependencies map[solver.HintID]string
 
@@ -83,6 +87,10 @@ type System struct {
 	// we need to init the level builder lbWireLevel from the existing constraints.
 	Levels [][]int
 
+	// ConstraintPermutation maps a constraint ID to the ID the constraint had when it was added
+	// to the system. It is nil unless the instructions were reordered (see ReorderInstructions).
+	ConstraintPermutation []uint32
+
 	// scalar field
 	q      *big.Int `cbor:"-"`
 	bitLen int      `cbor:"-"`
@@ -92,9 +100,19 @@ type System struct {
 	lbOutputs   []uint32         `cbor:"-"` // wire outputs for current constraint.
 	lbHints     map[int]struct{} `cbor:"-"` // hints we processed in current round
 
-	CommitmentInfo Commitment
+	CommitmentInfo Commitments
+
+	// optional features the system requires, checked when it is deserialized
+	Features Features
+
+	// wires marked as boolean by the frontend, which assumes but doesn't constrain them to be
+	// boolean (see frontend.Compiler.MarkBoolean), checked by Lint
+	BooleanWires []uint32
 
 	genericHint BlueprintID
+
+	// hash of the system, computed once by HashWithCoefficients
+	hash atomic.Value
 }
 
 // NewSystem initialize the common structure among constraint system
@@ -129,6 +147,10 @@ func (system *System) AddBlueprint(b Blueprint) BlueprintID {
 	return BlueprintID(len(system.Blueprints) - 1)
 }
 
+func (system *System) GetBlueprint(id BlueprintID) Blueprint {
+	return system.Blueprints[id]
+}
+
 func (system *System) GetNbSecretVariables() int {
 	return len(system.Secret)
 }
@@ -163,6 +185,10 @@ func (system *System) CheckSerializationHeader() error {
 	if !ok {
 		return fmt.Errorf("when parsing serialized modulus: %s", system.ScalarField)
 	}
+	if err := system.Features.Check(); err != nil {
+		return fmt.Errorf("constraint system compiled with gnark %s: %w", system.GnarkVersion, err)
+	}
+
 	curveID := utils.FieldToCurve(scalarField)
 	if curveID == ecc.UNKNOWN && scalarField.Cmp(tinyfield.Modulus()) != 0 {
 		return fmt.Errorf("unsupported scalar field %s", scalarField.Text(16))
@@ -205,12 +231,23 @@ func (system *System) AddSecretVariable(name string) (idx int) {
 }
 
 func (system *System) AddSolverHint(f solver.Hint, input []LinearExpression, nbOutput int) (internalVariables []int, err error) {
+	hintUUID, hintID, err := solver.LookupHint(f)
+	if err != nil {
+		return nil, err
+	}
+	return system.addSolverHint(hintUUID, hintID, input, nbOutput)
+}
+
+func (system *System) AddSolverHintForId(id solver.HintID, input []LinearExpression, nbOutput int) (internalVariables []int, err error) {
+	return system.addSolverHint(id, solver.GetHintNameByID(id), input, nbOutput)
+}
+
+func (system *System) addSolverHint(hintUUID solver.HintID, hintID string, input []LinearExpression, nbOutput int) (internalVariables []int, err error) {
 	if nbOutput <= 0 {
 		return nil, fmt.Errorf("hint function must return at least one output")
 	}
 
 	// register the hint as dependency
-	hintUUID, hintID := solver.GetHintID(f), solver.GetHintName(f)
 	if id, ok := system.MHintsDependencies[hintUUID]; ok {
 		// hint already registered, let's ensure string id matches
 		if id != hintID {
@@ -247,20 +284,38 @@ func (system *System) AddSolverHint(f solver.Hint, input []LinearExpression, nbO
 	return
 }
 
+// AddCommitment adds a commitment to the system. The private variables committed to must not
+// be committed to by another commitment, nor be the variable of another commitment.
 func (system *System) AddCommitment(c Commitment) error {
-	if system.CommitmentInfo.Is() {
-		return fmt.Errorf("currently only one commitment per circuit is supported")
+	for i := range system.CommitmentInfo {
+		if err := system.CommitmentInfo[i].checkDisjoint(&c); err != nil {
+			return fmt.Errorf("commitment %d: %w", len(system.CommitmentInfo), err)
+		}
 	}
 
-	system.CommitmentInfo = c
+	system.CommitmentInfo = append(system.CommitmentInfo, c)
+	system.RequireFeatures(FeatureCommitments)
+	if len(system.CommitmentInfo) > 1 {
+		system.RequireFeatures(FeatureMultiCommitments)
+	}
 
 	return nil
 }
 
+// GetCommitments returns the commitments of the system, in the order they were added.
+func (system *System) GetCommitments() Commitments {
+	return system.CommitmentInfo
+}
+
 func (system *System) AddLog(l LogEntry) {
 	system.Logs = append(system.Logs, l)
 }
 
+// MarkBoolean records that the frontend marked the wire as boolean.
+func (system *System) MarkBoolean(wireID int) {
+	system.BooleanWires = append(system.BooleanWires, uint32(wireID))
+}
+
 func (system *System) AttachDebugInfo(debugInfo DebugInfo, constraintID []int) {
 	system.DebugInfo = append(system.DebugInfo, LogEntry(debugInfo))
 	id := len(system.DebugInfo) - 1
@@ -301,6 +356,7 @@ func (cs *System) AddR1C(c R1C, bID BlueprintID) int {
 	profile.RecordConstraint()
 	instruction := cs.compressR1C(&c, bID)
 	cs.Instructions = append(cs.Instructions, instruction)
+	cs.attachSource(int(instruction.ConstraintOffset))
 
 	cs.updateLevel(len(cs.Instructions)-1, &c)
 
@@ -311,6 +367,7 @@ func (cs *System) AddSparseR1C(c SparseR1C, bID BlueprintID) int {
 	profile.RecordConstraint()
 	instruction := cs.compressSparseR1C(&c, bID)
 	cs.Instructions = append(cs.Instructions, instruction)
+	cs.attachSource(int(instruction.ConstraintOffset))
 
 	cs.updateLevel(len(cs.Instructions)-1, &c)
 
@@ -0,0 +1,438 @@
+package constraint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// DiffKind is the kind of a ConstraintChange.
+type DiffKind uint8
+
+const (
+	DiffAdded DiffKind = iota
+	DiffRemoved
+	DiffChanged
+)
+
+// MarshalText implements encoding.TextMarshaler, for the JSON reports.
+func (k DiffKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// DiffConstraint is a constraint as reported by Diff.
+type DiffConstraint struct {
+	// ID is the ID of the constraint in its system.
+	ID int
+
+	// Constraint is the formatted constraint, see Diff for the naming of internal wires.
+	Constraint string
+
+	// Location is the circuit code which added the constraint ("function file:line", innermost
+	// frame first), if the system holds debug information or a source map for it.
+	Location []string
+}
+
+// ConstraintChange is a difference between two constraint systems.
+type ConstraintChange struct {
+	Kind DiffKind
+
+	// Old is the constraint in the first system, nil if Kind == DiffAdded.
+	Old *DiffConstraint
+
+	// New is the constraint in the second system, nil if Kind == DiffRemoved.
+	New *DiffConstraint
+}
+
+// SystemDiff is the structural difference between two constraint systems.
+type SystemDiff struct {
+	Changes []ConstraintChange
+
+	// NbConstraints of the old and new systems.
+	NbConstraints [2]int
+	// NbUnchanged is the number of constraints common to the two systems.
+	NbUnchanged int
+
+	// NbPublic, NbSecret and NbInternal are the numbers of wires of the old and new systems,
+	// the constant wire being public.
+	NbPublic, NbSecret, NbInternal [2]int
+	// NbCommitments of the old and new systems.
+	NbCommitments [2]int
+
+	// PublicInputsChanged is true if the public inputs, their number or their names, changed:
+	// the verifiers must be given the new public witnesses.
+	PublicInputsChanged bool
+
+	// KeysChanged is true if the keys of the systems differ (see System.HashWithCoefficients):
+	// the setup, and the trusted setup ceremony of Groth16, must be run again, and the
+	// verifying key changes. The keys don't change if only the names of the inputs, the hints
+	// or the debug information changed.
+	KeysChanged bool
+}
+
+// Equal returns true if the two systems have the same constraints.
+func (d *SystemDiff) Equal() bool {
+	return len(d.Changes) == 0
+}
+
+// WriteTo writes a human readable report of the diff, one line per constraint prefixed by
+// '-' (removed) or '+' (added), followed by its location.
+func (d *SystemDiff) WriteTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+
+	fmt.Fprintf(&_w, "constraints: %d -> %d (%d unchanged, %d changes)\n", d.NbConstraints[0], d.NbConstraints[1], d.NbUnchanged, len(d.Changes))
+	fmt.Fprintf(&_w, "wires: public %d -> %d, secret %d -> %d, internal %d -> %d\n", d.NbPublic[0], d.NbPublic[1], d.NbSecret[0], d.NbSecret[1], d.NbInternal[0], d.NbInternal[1])
+	fmt.Fprintf(&_w, "commitments: %d -> %d\n", d.NbCommitments[0], d.NbCommitments[1])
+	switch {
+	case d.KeysChanged && d.PublicInputsChanged:
+		fmt.Fprintln(&_w, "keys: changed, the setup must be run again and the public inputs changed")
+	case d.KeysChanged:
+		fmt.Fprintln(&_w, "keys: changed, the setup must be run again")
+	case d.PublicInputsChanged:
+		fmt.Fprintln(&_w, "keys: unchanged, but the public inputs were renamed")
+	default:
+		fmt.Fprintln(&_w, "keys: unchanged")
+	}
+	writeConstraint := func(prefix byte, c *DiffConstraint) {
+		fmt.Fprintf(&_w, "%c [%d] %s\n", prefix, c.ID, c.Constraint)
+		for _, l := range c.Location {
+			fmt.Fprintf(&_w, "\t%s\n", l)
+		}
+	}
+	for _, c := range d.Changes {
+		if c.Old != nil {
+			writeConstraint('-', c.Old)
+		}
+		if c.New != nil {
+			writeConstraint('+', c.New)
+		}
+	}
+	return _w.N, nil
+}
+
+// Diff compares the constraints of two compiled systems, in order, and returns the constraints
+// added, removed and changed between a and b.
+//
+// Constraints are compared on their formatted form, with coefficients resolved to their values
+// and public and secret wires designated by their names. Internal wires don't have a stable ID
+// when constraints are added or removed, so an internal wire is named after the instruction
+// which first references it: "v~d.k" is the k-th new wire of the instruction d instructions
+// before the current one. This keeps unchanged regions of the circuits identical even if the
+// internal wires were renumbered.
+//
+// The comparison is a longest common subsequence of the constraints; it runs in
+// O((N+M)·D) time, where D is the number of changes, so it is meant for reviewing incremental
+// changes to a circuit rather than comparing unrelated circuits.
+func Diff(a, b ConstraintSystem) (*SystemDiff, error) {
+	sa, ok := a.(systemGetter)
+	if !ok {
+		return nil, errors.New("unsupported constraint system")
+	}
+	sb, ok := b.(systemGetter)
+	if !ok {
+		return nil, errors.New("unsupported constraint system")
+	}
+	if a.Field().Cmp(b.Field()) != 0 {
+		return nil, errors.New("constraint systems are defined over different fields")
+	}
+
+	ca := sa.getSystem().diffConstraints(a)
+	cb := sb.getSystem().diffConstraints(b)
+
+	ha := make([]uint64, len(ca))
+	for i := range ca {
+		ha[i] = hashString(ca[i].Constraint)
+	}
+	hb := make([]uint64, len(cb))
+	for i := range cb {
+		hb[i] = hashString(cb[i].Constraint)
+	}
+	equal := func(i, j int) bool {
+		return ha[i] == hb[j] && ca[i].Constraint == cb[j].Constraint
+	}
+
+	res := &SystemDiff{NbConstraints: [2]int{len(ca), len(cb)}}
+	for i, s := range []*System{sa.getSystem(), sb.getSystem()} {
+		res.NbPublic[i], res.NbSecret[i], res.NbInternal[i] = len(s.Public), len(s.Secret), s.NbInternalVariables
+		res.NbCommitments[i] = len(s.CommitmentInfo)
+	}
+	res.PublicInputsChanged = !equalStrings(sa.getSystem().Public, sb.getSystem().Public)
+
+	// pair the removed and added constraints of each hunk as changes
+	var removed, added []int
+	flush := func() {
+		for len(removed) != 0 && len(added) != 0 {
+			res.Changes = append(res.Changes, ConstraintChange{Kind: DiffChanged, Old: &ca[removed[0]], New: &cb[added[0]]})
+			removed, added = removed[1:], added[1:]
+		}
+		for _, i := range removed {
+			res.Changes = append(res.Changes, ConstraintChange{Kind: DiffRemoved, Old: &ca[i]})
+		}
+		for _, j := range added {
+			res.Changes = append(res.Changes, ConstraintChange{Kind: DiffAdded, New: &cb[j]})
+		}
+		removed, added = removed[:0], added[:0]
+	}
+	for _, op := range myersDiff(len(ca), len(cb), equal) {
+		switch op.kind {
+		case diffOpEqual:
+			flush()
+			res.NbUnchanged++
+		case diffOpDelete:
+			removed = append(removed, op.i)
+		case diffOpInsert:
+			added = append(added, op.j)
+		}
+	}
+	flush()
+
+	// the curve typed systems hash what their keys depend on
+	hashA, okA := a.(hasher)
+	hashB, okB := b.(hasher)
+	if okA && okB {
+		res.KeysChanged = !bytes.Equal(hashA.Hash(), hashB.Hash())
+	} else {
+		res.KeysChanged = !res.Equal() || res.NbPublic[0] != res.NbPublic[1] || res.NbSecret[0] != res.NbSecret[1] ||
+			res.NbInternal[0] != res.NbInternal[1] || res.NbCommitments[0] != res.NbCommitments[1]
+	}
+
+	return res, nil
+}
+
+// hasher is implemented by the curve typed constraint systems, see System.HashWithCoefficients.
+type hasher interface {
+	Hash() []byte
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// systemGetter is implemented by the constraint systems embedding a System.
+type systemGetter interface {
+	getSystem() *System
+}
+
+func (system *System) getSystem() *System {
+	return system
+}
+
+// diffConstraints returns the formatted constraints of the system, see Diff.
+func (system *System) diffConstraints(r Resolver) []DiffConstraint {
+	res := make([]DiffConstraint, 0, system.NbConstraints)
+
+	type firstSeen struct{ instruction, k int }
+	nbPublic, nbSecret := len(system.Public), len(system.Secret)
+	seen := make(map[int]firstSeen)
+	resolver := &diffResolver{Resolver: r, nbVariables: nbPublic + nbSecret}
+
+	var r1c R1C
+	var sparseR1C SparseR1C
+	var hint HintMapping
+	for iID, inst := range system.Instructions {
+		calldata := system.GetCallData(inst)
+
+		var it Iterable
+		var format func() string
+		switch blueprint := system.Blueprints[inst.BlueprintID].(type) {
+		case BlueprintR1C:
+			blueprint.DecompressR1C(&r1c, calldata)
+			it = &r1c
+			format = func() string { return r1c.String(resolver) }
+		case BlueprintSparseR1C:
+			blueprint.DecompressSparseR1C(&sparseR1C, calldata)
+			it = &sparseR1C
+			format = func() string { return sparseR1C.String(resolver) }
+		case BlueprintHint:
+			blueprint.DecompressHint(&hint, calldata)
+			it = &hint
+		default:
+			continue
+		}
+
+		// name the internal wires relatively to the instruction which first references them
+		k := 0
+		next := it.WireIterator()
+		for wID := next(); wID != -1; wID = next() {
+			if wID < resolver.nbVariables {
+				continue
+			}
+			if _, ok := seen[wID]; !ok {
+				seen[wID] = firstSeen{instruction: iID, k: k}
+				k++
+			}
+		}
+		if format == nil {
+			continue
+		}
+		resolver.name = func(wID int) string {
+			s := seen[wID]
+			return "v~" + strconv.Itoa(iID-s.instruction) + "." + strconv.Itoa(s.k)
+		}
+
+		cID := int(inst.ConstraintOffset)
+		res = append(res, DiffConstraint{
+			ID:         cID,
+			Constraint: format(),
+			Location:   system.ConstraintSource(system.OriginalConstraintID(cID)),
+		})
+	}
+
+	return res
+}
+
+// diffResolver resolves internal wires with name.
+type diffResolver struct {
+	Resolver
+	nbVariables int
+	name        func(wID int) string
+}
+
+func (r *diffResolver) VariableToString(vID int) string {
+	if vID < r.nbVariables {
+		return r.Resolver.VariableToString(vID)
+	}
+	return r.name(vID)
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, s)
+	return h.Sum64()
+}
+
+type diffOpKind uint8
+
+const (
+	diffOpEqual diffOpKind = iota
+	diffOpDelete
+	diffOpInsert
+)
+
+type diffOp struct {
+	kind diffOpKind
+	i, j int // indexes in the old and new sequences
+}
+
+// myersDiff returns the shortest edit script from a sequence of length n to a sequence of
+// length m, where equal(i, j) reports whether a[i] == b[j].
+//
+// See E. Myers, "An O(ND) Difference Algorithm and Its Variations", 1986.
+func myersDiff(n, m int, equal func(i, j int) bool) []diffOp {
+	// common prefix and suffix are not part of the search
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, n+m-2*suffix-prefix)
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: diffOpEqual, i: i, j: i})
+	}
+
+	// search on the middle part; trace[d][k+d] is the furthest x reached on diagonal k with d edits.
+	n0, m0 := n-prefix-suffix, m-prefix-suffix
+	eq := func(x, y int) bool { return equal(prefix+x, prefix+y) }
+	var trace [][]int
+	for d := 0; ; d++ {
+		v := make([]int, 2*d+1)
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if d == 0 {
+				x = 0
+			} else {
+				prev := trace[d-1]
+				if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+					x = prev[k+1+d-1]
+				} else {
+					x = prev[k-1+d-1] + 1
+				}
+			}
+			y := x - k
+			for x < n0 && y < m0 && eq(x, y) {
+				x++
+				y++
+			}
+			v[k+d] = x
+			if x >= n0 && y >= m0 {
+				done = true
+				break
+			}
+		}
+		trace = append(trace, v)
+		if done {
+			break
+		}
+	}
+
+	// backtrack
+	middle := make([]diffOp, 0, n0+m0)
+	x, y := n0, m0
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			middle = append(middle, diffOp{kind: diffOpEqual, i: prefix + x, j: prefix + y})
+		}
+		if prevK == k+1 {
+			middle = append(middle, diffOp{kind: diffOpInsert, i: prefix + prevX, j: prefix + prevY})
+		} else {
+			middle = append(middle, diffOp{kind: diffOpDelete, i: prefix + prevX, j: prefix + prevY})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		middle = append(middle, diffOp{kind: diffOpEqual, i: prefix + x, j: prefix + y})
+	}
+	for i := len(middle) - 1; i >= 0; i-- {
+		ops = append(ops, middle[i])
+	}
+
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, diffOp{kind: diffOpEqual, i: n - suffix + i, j: m - suffix + i})
+	}
+	return ops
+}
@@ -0,0 +1,100 @@
+package constraint_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/debug"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type chainCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+
+	extra bool `gnark:"-"`
+}
+
+func (c *chainCircuit) Define(api frontend.API) error {
+	a := c.X
+	for i := 0; i < 10; i++ {
+		a = api.Mul(a, c.X)
+		if c.extra && i == 5 {
+			api.AssertIsDifferent(a, 0)
+		}
+	}
+	api.AssertIsEqual(a, c.Y)
+	return nil
+}
+
+// renamedCircuit is chainCircuit with its public input renamed.
+type renamedCircuit struct {
+	X frontend.Variable
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *renamedCircuit) Define(api frontend.API) error {
+	return (&chainCircuit{X: c.X, Y: c.Z}).Define(api)
+}
+
+func TestDiff(t *testing.T) {
+	assert := require.New(t)
+
+	before, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &chainCircuit{})
+	assert.NoError(err)
+	after, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &chainCircuit{extra: true})
+	assert.NoError(err)
+
+	diff, err := constraint.Diff(before, before)
+	assert.NoError(err)
+	assert.True(diff.Equal())
+	assert.False(diff.KeysChanged)
+	assert.Equal(before.GetNbConstraints(), diff.NbUnchanged)
+
+	diff, err = constraint.Diff(before, after)
+	assert.NoError(err)
+	assert.False(diff.Equal())
+	assert.Equal([2]int{before.GetNbConstraints(), after.GetNbConstraints()}, diff.NbConstraints)
+	assert.Equal([2]int{before.GetNbInternalVariables(), after.GetNbInternalVariables()}, diff.NbInternal)
+	assert.Equal([2]int{2, 2}, diff.NbPublic)
+	assert.True(diff.KeysChanged)
+	assert.False(diff.PublicInputsChanged)
+
+	// the internal wires are renumbered after the new constraint, but only the constraints
+	// around it are reported.
+	nbAdded, nbRemoved := 0, 0
+	for _, c := range diff.Changes {
+		switch c.Kind {
+		case constraint.DiffAdded:
+			nbAdded++
+		case constraint.DiffRemoved:
+			nbRemoved++
+		}
+	}
+	assert.Equal(after.GetNbConstraints()-before.GetNbConstraints(), nbAdded-nbRemoved)
+	assert.LessOrEqual(len(diff.Changes), 2)
+	assert.Equal(before.GetNbConstraints()-1, diff.NbUnchanged)
+
+	var buf bytes.Buffer
+	_, err = diff.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Contains(buf.String(), "+ [")
+	assert.Contains(buf.String(), "keys: changed")
+	if debug.Debug {
+		assert.True(strings.Contains(buf.String(), "diff_test.go"), "location of the new constraint")
+	}
+
+	// the names of the inputs don't change the keys
+	renamed, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &renamedCircuit{})
+	assert.NoError(err)
+	diff, err = constraint.Diff(before, renamed)
+	assert.NoError(err)
+	assert.False(diff.Equal())
+	assert.True(diff.PublicInputsChanged)
+	assert.False(diff.KeysChanged)
+}
@@ -0,0 +1,95 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feature is an optional feature of the constraint systems, recorded in the serialized system and
+// in the keys derived from it, so that a version of gnark lacking it rejects the artifacts when
+// it loads them rather than failing later, or producing invalid proofs.
+type Feature string
+
+const (
+	// FeatureCommitments is set if the system commits to some of its wires.
+	FeatureCommitments Feature = "commitments"
+
+	// FeatureMultiCommitments is set if the system has more than one commitment.
+	FeatureMultiCommitments Feature = "multi-commitments"
+
+	// FeatureLookup is set if a gadget of the circuit uses the log-derivative lookup arguments.
+	FeatureLookup Feature = "lookup"
+
+	// FeatureReorderedConstraints is set if the instructions were reordered, see
+	// System.ReorderInstructions: the constraint IDs of the solver errors are then translated
+	// with the ConstraintPermutation.
+	FeatureReorderedConstraints Feature = "reordered-constraints"
+)
+
+// supportedFeatures are the features this version of gnark handles.
+var supportedFeatures = map[Feature]bool{
+	FeatureCommitments:          true,
+	FeatureMultiCommitments:     true,
+	FeatureLookup:               true,
+	FeatureReorderedConstraints: true,
+}
+
+// ErrUnsupportedFeature is returned when loading an artifact which requires a feature this
+// version of gnark lacks.
+var ErrUnsupportedFeature = errors.New("unsupported feature")
+
+// Features are the features a constraint system requires, sorted. The hints the system calls
+// are not listed: they are recorded in System.MHintsDependencies, and the solver checks that
+// they are registered before solving.
+type Features []Feature
+
+// Has returns true if f is one of the features.
+func (fs Features) Has(f Feature) bool {
+	for i := range fs {
+		if fs[i] == f {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns a copy of the features with f added, sorted.
+func (fs Features) With(f ...Feature) Features {
+	res := fs
+	for _, f := range f {
+		if !res.Has(f) {
+			res = append(res[:len(res):len(res)], f)
+			sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+		}
+	}
+	return res
+}
+
+// Check returns an error wrapping ErrUnsupportedFeature, which lists the features this version
+// of gnark lacks, if any.
+func (fs Features) Check() error {
+	var missing []string
+	for _, f := range fs {
+		if !supportedFeatures[f] {
+			missing = append(missing, string(f))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedFeature, strings.Join(missing, ", "))
+}
+
+// RequireFeatures records that the system requires the features. The system records the ones
+// its commitments and layout imply; the frontend adds the capabilities the gadgets of the
+// circuit required.
+func (system *System) RequireFeatures(f ...Feature) {
+	system.Features = system.Features.With(f...)
+}
+
+// GetFeatures returns the features the system requires.
+func (system *System) GetFeatures() Features {
+	return system.Features
+}
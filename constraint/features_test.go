@@ -0,0 +1,81 @@
+package constraint_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/stretchr/testify/require"
+)
+
+type lookupCircuit struct {
+	Entries [4]frontend.Variable
+	Index   frontend.Variable
+	Value   frontend.Variable `gnark:",public"`
+}
+
+func (c *lookupCircuit) Define(api frontend.API) error {
+	t := logderivlookup.New(api)
+	for i := range c.Entries {
+		t.Insert(c.Entries[i])
+	}
+	api.AssertIsEqual(t.Lookup(c.Index)[0], c.Value)
+	return nil
+}
+
+func TestFeatures(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoChainsCircuit{})
+	assert.NoError(err)
+	assert.Empty(ccs.GetFeatures())
+
+	ccs, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &lookupCircuit{}, frontend.WithConstraintReordering())
+	assert.NoError(err)
+	assert.Equal(constraint.Features{constraint.FeatureCommitments, constraint.FeatureLookup, constraint.FeatureReorderedConstraints}, ccs.GetFeatures())
+
+	// the features are serialized with the system and the verifying key
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	assert.Equal(ccs.GetFeatures(), vk.(*groth16_bn254.VerifyingKey).Features)
+
+	var buf bytes.Buffer
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(err)
+	var read cs.R1CS
+	_, err = read.ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(ccs.GetFeatures(), read.GetFeatures())
+
+	buf.Reset()
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	var readVK groth16_bn254.VerifyingKey
+	_, err = readVK.ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(ccs.GetFeatures(), readVK.Features)
+
+	// a feature of a later version is rejected when loading the artifacts
+	ccs.RequireFeatures("future-feature")
+	buf.Reset()
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = read.ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.True(errors.Is(err, constraint.ErrUnsupportedFeature), err)
+	assert.ErrorContains(err, "future-feature")
+
+	readVK.Features = ccs.GetFeatures()
+	buf.Reset()
+	_, err = readVK.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = new(groth16_bn254.VerifyingKey).ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.True(errors.Is(err, constraint.ErrUnsupportedFeature), err)
+}
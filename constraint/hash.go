@@ -0,0 +1,78 @@
+package constraint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// hashDomain separates the hashes of the constraint systems from other SHA-256 hashes.
+const hashDomain = "gnark constraint system v1\x00"
+
+// HashWithCoefficients returns the SHA-256 hash of what the keys of the proof systems depend
+// on: the field, the numbers of wires, the commitments and the constraints in order, with the
+// coefficient table written by writeCoefficients. The names of the inputs, the hints, the logs
+// and the debug information aren't hashed: two systems with the same hash have the same keys.
+//
+// The curve typed systems implement Hash with it. The hash is computed once: the system must
+// not be modified afterwards, except by ReorderInstructions.
+func (system *System) HashWithCoefficients(writeCoefficients func(w io.Writer)) []byte {
+	if h, _ := system.hash.Load().([]byte); len(h) != 0 {
+		return h
+	}
+
+	h := sha256.New()
+	w := bufio.NewWriterSize(h, 1<<16)
+	var buf []byte
+	writeUint32 := func(v ...uint32) {
+		buf = buf[:0]
+		for _, x := range v {
+			buf = binary.BigEndian.AppendUint32(buf, x)
+		}
+		_, _ = w.Write(buf)
+	}
+
+	_, _ = w.WriteString(hashDomain)
+	_, _ = w.WriteString(system.ScalarField)
+	_ = w.WriteByte(0)
+	writeUint32(uint32(system.Type), uint32(len(system.Public)), uint32(len(system.Secret)),
+		uint32(system.NbInternalVariables), uint32(system.NbConstraints))
+
+	writeCoefficients(w)
+
+	// the commitments are given to the setup of the keys
+	commitments, err := json.Marshal(system.CommitmentInfo)
+	if err != nil {
+		panic(err) // the commitments are plain values
+	}
+	writeUint32(uint32(len(commitments)))
+	_, _ = w.Write(commitments)
+
+	writeExpression := func(l LinearExpression) {
+		writeUint32(uint32(len(l)))
+		for _, t := range l {
+			writeUint32(t.CID, t.VID)
+		}
+	}
+	switch system.Type {
+	case SystemR1CS:
+		it := system.GetR1CIterator()
+		for c := it.Next(); c != nil; c = it.Next() {
+			writeExpression(c.L)
+			writeExpression(c.R)
+			writeExpression(c.O)
+		}
+	case SystemSparseR1CS:
+		it := system.GetSparseR1CIterator()
+		for c := it.Next(); c != nil; c = it.Next() {
+			writeUint32(c.XA, c.XB, c.XC, c.QL, c.QR, c.QO, c.QM, c.QC, uint32(c.Commitment))
+		}
+	}
+
+	_ = w.Flush()
+	sum := h.Sum(nil)
+	system.hash.Store(sum)
+	return sum
+}
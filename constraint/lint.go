@@ -0,0 +1,289 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// LintRule is a suspicious pattern detected by Lint.
+type LintRule uint8
+
+const (
+	// LintHintOutput reports the outputs of hints referenced by a single constraint which
+	// doesn't determine them: the wire appears in a product with itself, as the square root
+	// of a value asserted with out*out == value, or along with another wire referenced by no
+	// other constraint. The prover may then pick other values for the output.
+	LintHintOutput LintRule = iota
+
+	// LintBoolean reports the inputs and the outputs of hints marked as boolean (see
+	// frontend.Compiler.MarkBoolean), and so never constrained by the assertions of the
+	// frontend, but not constrained to be boolean by the system.
+	LintBoolean
+
+	// LintZeroCoefficient reports the wires referenced by a single term of the constraints,
+	// with a zero coefficient, which so doesn't constrain them.
+	LintZeroCoefficient
+)
+
+func (r LintRule) String() string {
+	switch r {
+	case LintHintOutput:
+		return "hint-output"
+	case LintBoolean:
+		return "boolean"
+	case LintZeroCoefficient:
+		return "zero-coefficient"
+	default:
+		return "unknown"
+	}
+}
+
+// LintFinding is a suspicious pattern found by Lint.
+type LintFinding struct {
+	Rule LintRule
+
+	// Wire is the ID of the wire concerned, and WireName its name.
+	Wire     int
+	WireName string
+
+	// Constraint is the ID of the constraint concerned, or -1 if the wire isn't referenced by
+	// any constraint.
+	Constraint int
+
+	// Location is the circuit code which added the constraint ("function file:line", innermost
+	// frame first), if the system holds debug information or a source map for it.
+	Location []string
+
+	Message string
+}
+
+// LintReport are the findings of Lint, ordered by rule.
+type LintReport struct {
+	Findings []LintFinding
+}
+
+// WriteTo writes a human readable report, one line per finding followed by its location.
+func (r *LintReport) WriteTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+
+	fmt.Fprintf(&_w, "%d findings\n", len(r.Findings))
+	for _, f := range r.Findings {
+		if f.Constraint >= 0 {
+			fmt.Fprintf(&_w, "[%s] %s: %s (constraint %d)\n", f.Rule, f.WireName, f.Message, f.Constraint)
+		} else {
+			fmt.Fprintf(&_w, "[%s] %s: %s\n", f.Rule, f.WireName, f.Message)
+		}
+		for _, l := range f.Location {
+			fmt.Fprintf(&_w, "\t%s\n", l)
+		}
+	}
+	return _w.N, nil
+}
+
+// Lint checks a compiled system for patterns which usually reveal an under-constrained
+// circuit, see the LintRule constants. All the rules are checked if none is given.
+//
+// The rules are heuristics on the structure of the constraints: a finding isn't necessarily a
+// bug, and a system without finding may still be under-constrained. The locations of the
+// findings are only known if the circuit was compiled with debug information or a source map
+// (see frontend.WithSourceMapping).
+func Lint(cs ConstraintSystem, rules ...LintRule) (*LintReport, error) {
+	s, ok := cs.(systemGetter)
+	if !ok {
+		return nil, errors.New("unsupported constraint system")
+	}
+	if len(rules) == 0 {
+		rules = []LintRule{LintHintOutput, LintBoolean, LintZeroCoefficient}
+	}
+	return s.getSystem().lint(cs, rules), nil
+}
+
+// wireRefs counts the references of a wire by the constraints.
+type wireRefs struct {
+	nb         int  // number of constraints referencing the wire with a non-zero coefficient
+	nbZero     int  // number of terms referencing the wire with a zero coefficient
+	constraint int  // last constraint referencing the wire
+	hintOutput bool // the wire is an output of a hint
+	boolean    bool // the wire is constrained to be boolean
+	reported   bool // a finding of the current rule was reported for the wire
+}
+
+// lintRef is the reference of a wire by a term of a constraint.
+type lintRef struct {
+	wire  int
+	zero  bool // the coefficient of the term is zero
+	left  bool // the term is in the left factor of the product (L or xa)
+	right bool // the term is in the right factor of the product (R or xb)
+}
+
+func (system *System) lint(r Resolver, rules []LintRule) *LintReport {
+	nbWires := len(system.Public) + len(system.Secret) + system.NbInternalVariables
+	nbInputs := len(system.Public) + len(system.Secret)
+	refs := make([]wireRefs, nbWires)
+
+	// first pass: count the references of the wires
+	system.forEachLintConstraint(func(cID int, cRefs []lintRef) {
+		for _, ref := range cRefs {
+			w := &refs[ref.wire]
+			if ref.zero {
+				w.nbZero++
+				continue
+			}
+			// a wire referenced by several terms of the constraint counts once
+			if w.nb == 0 || w.constraint != cID {
+				w.nb++
+				w.constraint = cID
+			}
+		}
+		if w, ok := system.isBooleanConstraint(cRefs); ok {
+			refs[w].boolean = true
+		}
+	}, func(h *HintMapping) {
+		for w := h.OutputRange.Start; w < h.OutputRange.End; w++ {
+			refs[w].hintOutput = true
+		}
+	})
+
+	report := &LintReport{}
+	finding := func(rule LintRule, wID, cID int, msg string) {
+		f := LintFinding{Rule: rule, Wire: wID, WireName: r.VariableToString(wID), Constraint: cID, Message: msg}
+		if cID >= 0 {
+			f.Location = system.ConstraintSource(system.OriginalConstraintID(cID))
+		}
+		report.Findings = append(report.Findings, f)
+	}
+
+	for _, rule := range rules {
+		for w := range refs {
+			refs[w].reported = false
+		}
+		switch rule {
+		case LintHintOutput:
+			// second pass: check the constraints of the hint outputs referenced once
+			system.forEachLintConstraint(func(cID int, cRefs []lintRef) {
+				for _, ref := range cRefs {
+					w := &refs[ref.wire]
+					if ref.zero || !w.hintOutput || w.nb != 1 || w.reported {
+						continue
+					}
+					if msg := underDetermined(ref.wire, cRefs, refs, nbInputs); msg != "" {
+						finding(LintHintOutput, ref.wire, cID, msg)
+						w.reported = true
+					}
+				}
+			}, nil)
+		case LintBoolean:
+			for _, wID := range system.BooleanWires {
+				w := int(wID)
+				input := w < nbInputs
+				if w >= nbWires || refs[w].boolean || refs[w].reported || !(input || refs[w].hintOutput) {
+					continue
+				}
+				cID := -1
+				if refs[w].nb != 0 {
+					cID = refs[w].constraint
+				}
+				finding(LintBoolean, w, cID, "marked as boolean but not constrained to be boolean")
+				refs[w].reported = true
+			}
+		case LintZeroCoefficient:
+			for w := range refs {
+				if refs[w].nb == 0 && refs[w].nbZero == 1 {
+					finding(LintZeroCoefficient, w, -1, "only referenced with a zero coefficient")
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// underDetermined returns why the single constraint referencing the wire doesn't determine
+// it, or "" if it may. The inputs, set by the witness, are determined.
+func underDetermined(wire int, cRefs []lintRef, refs []wireRefs, nbInputs int) string {
+	left, right := false, false
+	for _, ref := range cRefs {
+		if ref.zero {
+			continue
+		}
+		if ref.wire == wire {
+			left, right = left || ref.left, right || ref.right
+		} else if ref.wire >= nbInputs && refs[ref.wire].nb == 1 {
+			return "hint output constrained by a single constraint, along with a wire no other constraint references"
+		}
+	}
+	if left && right {
+		return "hint output constrained by a single constraint, in a product with itself"
+	}
+	return ""
+}
+
+// isBooleanConstraint returns the wire w if the constraint only references w, in a product
+// with itself, as w*(1-w) == 0 does.
+func (system *System) isBooleanConstraint(cRefs []lintRef) (int, bool) {
+	w, left, right := -1, false, false
+	for _, ref := range cRefs {
+		if ref.zero || (system.Type == SystemR1CS && ref.wire == 0) {
+			continue // the constant wire of the R1CS
+		}
+		if w != -1 && ref.wire != w {
+			return -1, false
+		}
+		w, left, right = ref.wire, left || ref.left, right || ref.right
+	}
+	return w, w != -1 && left && right
+}
+
+// forEachLintConstraint calls onConstraint with the wire references of the constraints, and
+// onHint, if not nil, with the hints.
+func (system *System) forEachLintConstraint(onConstraint func(cID int, cRefs []lintRef), onHint func(h *HintMapping)) {
+	var r1c R1C
+	var sparseR1C SparseR1C
+	var hint HintMapping
+	var cRefs []lintRef
+	for _, inst := range system.Instructions {
+		calldata := system.GetCallData(inst)
+		cRefs = cRefs[:0]
+
+		switch blueprint := system.Blueprints[inst.BlueprintID].(type) {
+		case BlueprintR1C:
+			blueprint.DecompressR1C(&r1c, calldata)
+			for i, l := range []LinearExpression{r1c.L, r1c.R, r1c.O} {
+				for _, t := range l {
+					if t.IsConstant() {
+						continue
+					}
+					cRefs = append(cRefs, lintRef{wire: t.WireID(), zero: t.CoeffID() == CoeffIdZero, left: i == 0, right: i == 1})
+				}
+			}
+		case BlueprintSparseR1C:
+			blueprint.DecompressSparseR1C(&sparseR1C, calldata)
+			c := &sparseR1C
+			// the unused wires of a sparse constraint are 0, with zero coefficients
+			mul := c.QM != CoeffIdZero
+			for _, ref := range []lintRef{
+				{wire: int(c.XA), zero: c.QL == CoeffIdZero && !mul, left: mul},
+				{wire: int(c.XB), zero: c.QR == CoeffIdZero && !mul, right: mul},
+				{wire: int(c.XC), zero: c.QO == CoeffIdZero},
+			} {
+				if ref.zero && ref.wire == 0 {
+					continue
+				}
+				cRefs = append(cRefs, ref)
+			}
+		case BlueprintHint:
+			if onHint != nil {
+				blueprint.DecompressHint(&hint, calldata)
+				onHint(&hint)
+			}
+			continue
+		default:
+			continue
+		}
+
+		onConstraint(int(inst.ConstraintOffset), cRefs)
+	}
+}
@@ -0,0 +1,105 @@
+package constraint_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type lintCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+
+	sqrtOnce, unconstrainedBoolean bool `gnark:"-"`
+}
+
+func (c *lintCircuit) Define(api frontend.API) error {
+	sqrt, err := api.Compiler().NewHint(sqrtHint, 1, c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(api.Mul(sqrt[0], sqrt[0]), c.X)
+	if !c.sqrtOnce {
+		// the square root is determined by its sign
+		api.AssertIsEqual(api.Mul(sqrt[0], c.Y), c.Y)
+	}
+
+	b, err := api.Compiler().NewHint(sqrtHint, 1, c.Y)
+	if err != nil {
+		return err
+	}
+	if c.unconstrainedBoolean {
+		api.Compiler().MarkBoolean(b[0])
+	}
+	api.AssertIsBoolean(b[0]) // skipped if marked
+	api.AssertIsEqual(api.Select(b[0], c.X, c.Y), c.Y)
+	return nil
+}
+
+func sqrtHint(field *big.Int, inputs, outputs []*big.Int) error {
+	outputs[0].ModSqrt(inputs[0], field)
+	return nil
+}
+
+func TestLint(t *testing.T) {
+	for _, builder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		assert := require.New(t)
+
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), builder, &lintCircuit{})
+		assert.NoError(err)
+		report, err := constraint.Lint(ccs)
+		assert.NoError(err)
+		assert.Empty(report.Findings)
+
+		ccs, err = frontend.Compile(ecc.BN254.ScalarField(), builder, &lintCircuit{sqrtOnce: true, unconstrainedBoolean: true}, frontend.WithSourceMapping())
+		assert.NoError(err)
+		report, err = constraint.Lint(ccs)
+		assert.NoError(err)
+		assert.Len(report.Findings, 2)
+		assert.Equal(constraint.LintHintOutput, report.Findings[0].Rule)
+		assert.Equal(constraint.LintBoolean, report.Findings[1].Rule)
+		assert.NotEmpty(report.Findings[0].Location, "location of the constraint")
+
+		report, err = constraint.Lint(ccs, constraint.LintBoolean)
+		assert.NoError(err)
+		assert.Len(report.Findings, 1)
+
+		var buf bytes.Buffer
+		_, err = report.WriteTo(&buf)
+		assert.NoError(err)
+		assert.Contains(buf.String(), "[boolean]")
+	}
+}
+
+func TestLintZeroCoefficient(t *testing.T) {
+	assert := require.New(t)
+
+	r1cs := cs.NewR1CS(0)
+	blueprint := r1cs.AddBlueprint(&constraint.BlueprintGenericR1C{})
+	ONE := r1cs.AddPublicVariable("1")
+	X := r1cs.AddSecretVariable("X")
+	Z := r1cs.AddSecretVariable("Z")
+	cZero, cOne := r1cs.FromInterface(0), r1cs.FromInterface(1)
+
+	// X == X + 0⋅Z
+	r1cs.AddR1C(constraint.R1C{
+		L: constraint.LinearExpression{r1cs.MakeTerm(&cOne, X)},
+		R: constraint.LinearExpression{r1cs.MakeTerm(&cOne, ONE)},
+		O: constraint.LinearExpression{r1cs.MakeTerm(&cOne, X), r1cs.MakeTerm(&cZero, Z)},
+	}, blueprint)
+
+	report, err := constraint.Lint(r1cs)
+	assert.NoError(err)
+	assert.Len(report.Findings, 1)
+	assert.Equal(constraint.LintZeroCoefficient, report.Findings[0].Rule)
+	assert.Equal("Z", report.Findings[0].WireName)
+	assert.Equal(-1, report.Findings[0].Constraint)
+}
@@ -0,0 +1,152 @@
+package constraint
+
+import (
+	"errors"
+	"sort"
+)
+
+// ReorderInstructions lays out the instructions of the system in blocks ordered for solving:
+// instructions are grouped by level and, within a level, sorted by the highest wire they
+// reference, so that consecutive instructions touch neighbouring wires. The calldata is
+// rewritten in the same order and each level becomes a contiguous range of instructions.
+//
+// Constraint IDs change; ConstraintPermutation records, for each new constraint ID, the ID
+// the constraint had when it was added to the system. Debug info and solver errors keep
+// referring to the original IDs. The constraint indexes of the commitments of PLONK systems
+// are remapped to the new IDs; wire IDs don't change.
+//
+// This is meant to be called once the system is fully built (e.g. at the end of
+// frontend.Compile); constraints added afterwards are not reordered.
+func (system *System) ReorderInstructions() error {
+	nbInstructions := len(system.Instructions)
+	if nbInstructions == 0 {
+		return nil
+	}
+
+	order := make([]int, 0, nbInstructions)
+	keys := make([]int, nbInstructions)
+	for _, level := range system.Levels {
+		for _, iID := range level {
+			keys[iID] = system.maxWireID(system.Instructions[iID])
+		}
+		start := len(order)
+		order = append(order, level...)
+		block := order[start:]
+		sort.SliceStable(block, func(i, j int) bool {
+			return keys[block[i]] < keys[block[j]]
+		})
+	}
+	if len(order) != nbInstructions {
+		return errors.New("levels don't cover all instructions of the constraint system")
+	}
+
+	instructions := make([]Instruction, nbInstructions)
+	callData := make([]uint32, 0, len(system.CallData))
+	permutation := make([]uint32, system.NbConstraints)
+	newIDs := make([]int, system.NbConstraints) // constraint ID before this call -> new ID
+	nbConstraints := 0
+	for newID, oldID := range order {
+		inst := system.Instructions[oldID]
+		blueprint := system.Blueprints[inst.BlueprintID]
+
+		// the calldata of an instruction with a dynamic number of inputs starts with its length
+		nbInputs := blueprint.NbInputs()
+		if nbInputs < 0 {
+			nbInputs = int(system.CallData[inst.StartCallData])
+		}
+
+		instructions[newID] = Instruction{
+			BlueprintID:      inst.BlueprintID,
+			ConstraintOffset: uint32(nbConstraints),
+			StartCallData:    uint64(len(callData)),
+		}
+		callData = append(callData, system.CallData[inst.StartCallData:inst.StartCallData+uint64(nbInputs)]...)
+
+		for k := 0; k < blueprint.NbConstraints(); k++ {
+			permutation[nbConstraints] = system.originalConstraintID(inst.ConstraintOffset + uint32(k))
+			newIDs[inst.ConstraintOffset+uint32(k)] = nbConstraints
+			nbConstraints++
+		}
+	}
+	if nbConstraints != system.NbConstraints {
+		return errors.New("instructions don't account for all constraints of the constraint system")
+	}
+
+	offset := 0
+	for i := range system.Levels {
+		n := len(system.Levels[i])
+		for j := 0; j < n; j++ {
+			system.Levels[i][j] = offset + j
+		}
+		offset += n
+	}
+
+	if system.Type == SystemSparseR1CS {
+		// in PLONK, the commitments refer to the constraints defining the committed values
+		// and the commitment
+		for i := range system.CommitmentInfo {
+			c := &system.CommitmentInfo[i]
+			committed := make([]int, len(c.Committed))
+			for j, cID := range c.Committed {
+				committed[j] = newIDs[cID]
+			}
+			c.Committed = committed
+			c.CommitmentIndex = newIDs[c.CommitmentIndex]
+		}
+	}
+
+	system.Instructions = instructions
+	system.CallData = callData
+	system.ConstraintPermutation = permutation
+	system.RequireFeatures(FeatureReorderedConstraints)
+	system.hash.Store([]byte(nil)) // the constraints are in another order
+
+	return nil
+}
+
+// OriginalConstraintID returns the ID a constraint had when it was added to the system,
+// before any call to ReorderInstructions.
+func (system *System) OriginalConstraintID(cID int) int {
+	return int(system.originalConstraintID(uint32(cID)))
+}
+
+func (system *System) originalConstraintID(cID uint32) uint32 {
+	if int(cID) >= len(system.ConstraintPermutation) {
+		// not reordered
+		return cID
+	}
+	return system.ConstraintPermutation[cID]
+}
+
+// maxWireID returns the highest wire ID referenced by the instruction, or -1 if the
+// instruction is not a constraint or a hint.
+func (system *System) maxWireID(inst Instruction) int {
+	calldata := system.GetCallData(inst)
+
+	var it Iterable
+	switch blueprint := system.Blueprints[inst.BlueprintID].(type) {
+	case BlueprintR1C:
+		var c R1C
+		blueprint.DecompressR1C(&c, calldata)
+		it = &c
+	case BlueprintSparseR1C:
+		var c SparseR1C
+		blueprint.DecompressSparseR1C(&c, calldata)
+		it = &c
+	case BlueprintHint:
+		var h HintMapping
+		blueprint.DecompressHint(&h, calldata)
+		it = &h
+	default:
+		return -1
+	}
+
+	res := -1
+	next := it.WireIterator()
+	for wID := next(); wID != -1; wID = next() {
+		if wID > res {
+			res = wID
+		}
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package constraint_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type twoChainsCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *twoChainsCircuit) Define(api frontend.API) error {
+	a, b := c.X, c.Y
+	for i := 0; i < 8; i++ {
+		a = api.Mul(a, a)
+		b = api.Mul(b, c.X)
+		api.AssertIsDifferent(a, b)
+	}
+	api.AssertIsEqual(api.Add(a, b), c.Z)
+	return nil
+}
+
+func TestReorderInstructions(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoChainsCircuit{})
+	assert.NoError(err)
+	reordered, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoChainsCircuit{}, frontend.WithConstraintReordering())
+	assert.NoError(err)
+
+	assert.Equal(ccs.GetNbConstraints(), reordered.GetNbConstraints())
+	assert.Equal(ccs.GetNbInstructions(), reordered.GetNbInstructions())
+
+	// levels are contiguous blocks of instructions
+	levels := reordered.(*cs.R1CS).Levels
+	next := 0
+	for _, level := range levels {
+		for _, iID := range level {
+			assert.Equal(next, iID)
+			next++
+		}
+	}
+
+	// the permutation maps each constraint to a distinct original constraint
+	seen := make(map[int]struct{})
+	for i := 0; i < reordered.GetNbConstraints(); i++ {
+		seen[reordered.OriginalConstraintID(i)] = struct{}{}
+	}
+	assert.Equal(reordered.GetNbConstraints(), len(seen))
+
+	// the reordered system is still a valid R1CS for groth16
+	// a = 3^(2^8), b = 2·3^8
+	z := new(big.Int).Exp(big.NewInt(3), big.NewInt(256), ecc.BN254.ScalarField())
+	z.Add(z, big.NewInt(2*6561))
+	assignment, err := frontend.NewWitness(&twoChainsCircuit{X: 3, Y: 2, Z: z}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := assignment.Public()
+	assert.NoError(err)
+
+	pk, vk, err := groth16.Setup(reordered)
+	assert.NoError(err)
+	proof, err := groth16.Prove(reordered, pk, assignment)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+}
+
+// solver errors refer to the constraint IDs of the circuit definition
+func TestReorderInstructionsDebugInfo(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoChainsCircuit{})
+	assert.NoError(err)
+	reordered, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoChainsCircuit{}, frontend.WithConstraintReordering())
+	assert.NoError(err)
+
+	invalid, err := frontend.NewWitness(&twoChainsCircuit{X: 2, Y: 3, Z: 4}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	var expected, actual *cs.UnsatisfiedConstraintError
+	_, err = ccs.Solve(invalid)
+	assert.True(errors.As(err, &expected))
+	_, err = reordered.Solve(invalid)
+	assert.True(errors.As(err, &actual))
+	assert.Equal(expected.CID, actual.CID)
+	assert.Equal(expected.Error(), actual.Error())
+}
+
+type committedChainsCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *committedChainsCircuit) Define(api frontend.API) error {
+	a, b := c.X, c.Y
+	for i := 0; i < 8; i++ {
+		a = api.Mul(a, a)
+		b = api.Mul(b, c.X)
+	}
+	commitment, err := api.Compiler().(frontend.Committer).Commit(a, b, c.Z)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, a)
+	api.AssertIsEqual(api.Add(a, b), c.Z)
+	return nil
+}
+
+// the constraints of the commitments of PLONK systems are remapped
+func TestReorderInstructionsPlonkCommitment(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &committedChainsCircuit{})
+	assert.NoError(err)
+	reordered, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &committedChainsCircuit{}, frontend.WithConstraintReordering())
+	assert.NoError(err)
+
+	info, reorderedInfo := ccs.GetCommitments()[0], reordered.GetCommitments()[0]
+	assert.Equal(ccs.OriginalConstraintID(info.CommitmentIndex), reordered.OriginalConstraintID(reorderedInfo.CommitmentIndex))
+	for i := range info.Committed {
+		assert.Equal(info.Committed[i], reordered.OriginalConstraintID(reorderedInfo.Committed[i]))
+	}
+
+	z := new(big.Int).Exp(big.NewInt(3), big.NewInt(256), ecc.BN254.ScalarField())
+	z.Add(z, big.NewInt(2*6561))
+	assignment, err := frontend.NewWitness(&committedChainsCircuit{X: 3, Y: 2, Z: z}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := assignment.Public()
+	assert.NoError(err)
+
+	srs, err := test.NewKZGSRS(reordered)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(reordered, srs)
+	assert.NoError(err)
+	proof, err := plonk.Prove(reordered, pk, assignment)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, public))
+}
@@ -1,10 +1,12 @@
 package solver
 
 import (
+	"fmt"
 	"hash/fnv"
 	"math/big"
 	"reflect"
 	"runtime"
+	"strconv"
 )
 
 // HintID is a unique identifier for a hint function used for lookup.
@@ -85,17 +87,71 @@ type Hint func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error
 
 // GetHintID is a reference function for computing the hint ID based on a function name
 func GetHintID(fn Hint) HintID {
-	hf := fnv.New32a()
-	name := GetHintName(fn)
+	return hintIDFromName(GetHintName(fn))
+}
 
-	// TODO relying on name to derive UUID is risky; if fn is an anonymous func, wil be package.glob..funcN
-	// and if new anonymous functions are added in the package, N may change, so will UUID.
-	hf.Write([]byte(name)) // #nosec G104 -- does not err
+// GetHintName returns the name of the hint function: its qualified name if it was registered
+// with RegisterNamedHint under a single name, or else the name of the Go function.
+func GetHintName(fn Hint) string {
+	registryM.RLock()
+	defer registryM.RUnlock()
+	name, _ := hintName(fn)
+	return name
+}
 
-	return HintID(hf.Sum32())
+// LookupHint returns the ID and the name of the hint function, as GetHintID and GetHintName.
+// It returns an error if the function was registered with RegisterNamedHint under several
+// names, which the function doesn't tell apart: it must then be called by its ID.
+func LookupHint(fn Hint) (HintID, string, error) {
+	registryM.RLock()
+	defer registryM.RUnlock()
+	name, ok := hintName(fn)
+	if !ok {
+		return 0, "", fmt.Errorf("hint %s is registered under several names, call it by its ID", name)
+	}
+	return hintIDFromName(name), name, nil
 }
 
-func GetHintName(fn Hint) string {
+// hintName is GetHintName, for callers holding registryM. It returns false if the function
+// was registered under several names.
+func hintName(fn Hint) (string, bool) {
 	fnptr := reflect.ValueOf(fn).Pointer()
-	return runtime.FuncForPC(fnptr).Name()
+	name, ok := registryNames[fnptr]
+	if ok && name != "" {
+		return name, true
+	}
+	return runtime.FuncForPC(fnptr).Name(), !ok
+}
+
+// GetHintNameByID returns the name of the hint registered with the ID, or its decimal
+// representation if it isn't registered or its name can't be recovered.
+func GetHintNameByID(key HintID) string {
+	registryM.RLock()
+	defer registryM.RUnlock()
+	if name, ok := registryIDNames[key]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(key), 10)
+}
+
+// NamedHintID returns the ID of the hint registered with RegisterNamedHint under the namespace,
+// name and version.
+func NamedHintID(namespace, name string, version uint32) HintID {
+	return hintIDFromName(HintName(namespace, name, version))
+}
+
+// HintName returns the qualified name "namespace/name@vversion" of a versioned hint.
+func HintName(namespace, name string, version uint32) string {
+	return namespace + "/" + name + "@v" + strconv.FormatUint(uint64(version), 10)
+}
+
+func hintIDFromName(name string) HintID {
+	hf := fnv.New32a()
+
+	// relying on the Go function name to derive the ID is risky; if fn is an anonymous func, it
+	// is package.glob..funcN and if new anonymous functions are added in the package, N may
+	// change, so will the ID. Named hints (RegisterNamedHint) don't have this issue.
+	hf.Write([]byte(name)) // #nosec G104 -- does not err
+
+	return HintID(hf.Sum32())
 }
@@ -0,0 +1,32 @@
+package solver
+
+import (
+	"math/big"
+)
+
+// BatchHint evaluates several calls of a hint at once: inputs[i] and outputs[i] are the
+// inputs and the outputs of the call i, as for a Hint. It lets the hints which are cheap
+// individually but called a lot, such as the emulated multiplications, be evaluated in a
+// single vectorized pass, possibly on a device.
+type BatchHint func(field *big.Int, inputs, outputs [][]*big.Int) error
+
+// WithBatchHint is a solver option evaluating the calls of the hint h with f. The solver
+// collects the calls of h of each of its levels, which are independent, and passes them to f
+// at once instead of calling h on each. The calls are memoized by the hint cache as the calls
+// of h (see WithHintCache), and f only gets the ones missing from it. If f fails, the solver
+// calls h on each call of the batch, to return the error of the failing one as for h.
+//
+// The batch evaluation runs alone: it should use the CPUs (or a device) itself.
+func WithBatchHint(h Hint, f BatchHint) Option {
+	return func(opt *Config) error {
+		id := GetHintID(h)
+		if _, ok := opt.HintFunctions[id]; !ok {
+			opt.HintFunctions[id] = h
+		}
+		if opt.BatchHints == nil {
+			opt.BatchHints = make(map[HintID]BatchHint)
+		}
+		opt.BatchHints[id] = f
+		return nil
+	}
+}
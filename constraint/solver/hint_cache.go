@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"container/list"
+	"encoding/binary"
+	"math/big"
+	"sync"
+)
+
+// entryOverhead approximates the memory of a cache entry besides its key and outputs: the
+// list element, the map entry and the big.Int headers.
+const entryOverhead = 128
+
+// HintCache memoizes the results of pure hints, the hints whose outputs only depend on their
+// inputs and the field, so that solving similar witnesses, for instance in batch proving,
+// doesn't recompute the expensive hints (emulated inverses, decompositions...) with inputs
+// already seen. It is safe for concurrent use, and may be shared by the solvers of any curve.
+//
+// The memory of the entries is bounded: the least recently used are evicted first.
+type HintCache struct {
+	maxBytes int
+	pure     map[HintID]struct{}
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     list.List // of *hintCacheEntry, the most recently used first
+	bytes   int
+	stats   HintCacheStats
+}
+
+// HintCacheStats are the statistics of a HintCache.
+type HintCacheStats struct {
+	Hits, Misses uint64
+	Entries      int
+	Bytes        int // approximate memory of the entries
+}
+
+type hintCacheEntry struct {
+	key     string
+	outputs []*big.Int
+	size    int
+}
+
+// NewHintCache returns a cache of the results of the given hints, which must be pure, using
+// about maxBytes of memory. The other hints are never cached.
+func NewHintCache(maxBytes int, pureHints ...Hint) *HintCache {
+	c := &HintCache{
+		maxBytes: maxBytes,
+		pure:     make(map[HintID]struct{}, len(pureHints)),
+		entries:  make(map[string]*list.Element),
+	}
+	for _, h := range pureHints {
+		c.pure[GetHintID(h)] = struct{}{}
+	}
+	return c
+}
+
+// WithHintCache is a solver option memoizing the pure hints of the cache, see HintCache. The
+// hints given by the other options are cached too, whatever the order of the options.
+func WithHintCache(c *HintCache) Option {
+	return func(opt *Config) error {
+		opt.hintCache = c
+		return nil
+	}
+}
+
+// Stats returns the statistics of the cache.
+func (c *HintCache) Stats() HintCacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stats := c.stats
+	stats.Entries, stats.Bytes = len(c.entries), c.bytes
+	return stats
+}
+
+// Reset empties the cache and its statistics.
+func (c *HintCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+	c.stats = HintCacheStats{}
+}
+
+// wrap replaces the pure hints of the functions and of the batch hints by their memoized
+// versions.
+func (c *HintCache) wrap(hintFunctions map[HintID]Hint, batchHints map[HintID]BatchHint) {
+	for id, f := range hintFunctions {
+		if _, ok := c.pure[id]; ok {
+			hintFunctions[id] = c.memoize(id, f)
+		}
+	}
+	for id, f := range batchHints {
+		if _, ok := c.pure[id]; ok {
+			batchHints[id] = c.memoizeBatch(id, f)
+		}
+	}
+}
+
+func (c *HintCache) memoize(id HintID, f Hint) Hint {
+	return func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+		key := hintCacheKey(id, field, inputs, len(outputs))
+		if c.get(key, outputs) {
+			return nil
+		}
+		// the hint runs unlocked: concurrent misses on a key compute it several times
+		if err := f(field, inputs, outputs); err != nil {
+			return err
+		}
+		c.put(key, outputs)
+		return nil
+	}
+}
+
+// memoizeBatch returns the batch hint evaluating the calls missing from the cache only, with
+// the same keys as the memoized hint: the calls of a batch and the single calls share the
+// entries.
+func (c *HintCache) memoizeBatch(id HintID, f BatchHint) BatchHint {
+	return func(field *big.Int, inputs, outputs [][]*big.Int) error {
+		var keys []string
+		var missedInputs, missedOutputs [][]*big.Int
+		for i := range inputs {
+			key := hintCacheKey(id, field, inputs[i], len(outputs[i]))
+			if !c.get(key, outputs[i]) {
+				keys = append(keys, key)
+				missedInputs = append(missedInputs, inputs[i])
+				missedOutputs = append(missedOutputs, outputs[i])
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := f(field, missedInputs, missedOutputs); err != nil {
+			return err
+		}
+		for i, key := range keys {
+			c.put(key, missedOutputs[i])
+		}
+		return nil
+	}
+}
+
+func (c *HintCache) get(key string, outputs []*big.Int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	for i, o := range e.Value.(*hintCacheEntry).outputs {
+		outputs[i].Set(o)
+	}
+	return true
+}
+
+func (c *HintCache) put(key string, outputs []*big.Int) {
+	entry := &hintCacheEntry{key: key, outputs: make([]*big.Int, len(outputs)), size: len(key) + entryOverhead}
+	for i, o := range outputs {
+		entry.outputs[i] = new(big.Int).Set(o)
+		entry.size += len(o.Bits()) * 8
+	}
+	if entry.size > c.maxBytes {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	for c.bytes+entry.size > c.maxBytes {
+		last := c.lru.Back()
+		evicted := last.Value.(*hintCacheEntry)
+		c.lru.Remove(last)
+		delete(c.entries, evicted.key)
+		c.bytes -= evicted.size
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.bytes += entry.size
+}
+
+// hintCacheKey encodes the call: the hint, the number of outputs, the field and the inputs,
+// each as its length and its big-endian bytes.
+func hintCacheKey(id HintID, field *big.Int, inputs []*big.Int, nbOutputs int) string {
+	key := make([]byte, 0, 8+(1+len(inputs))*(4+32))
+	key = binary.BigEndian.AppendUint32(key, uint32(id))
+	key = binary.BigEndian.AppendUint32(key, uint32(nbOutputs))
+	for _, x := range append([]*big.Int{field}, inputs...) {
+		b := x.Bytes()
+		key = binary.BigEndian.AppendUint32(key, uint32(len(b)))
+		key = append(key, b...)
+	}
+	return string(key)
+}
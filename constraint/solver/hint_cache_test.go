@@ -0,0 +1,142 @@
+package solver
+
+import (
+	"math/big"
+	"testing"
+)
+
+var nbCountingHintCalls int
+
+func testCountingHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	nbCountingHintCalls++
+	outputs[0].Add(inputs[0], big.NewInt(1)).Mod(outputs[0], field)
+	return nil
+}
+
+func TestHintCache(t *testing.T) {
+	cache := NewHintCache(1<<20, testCountingHint)
+	cfg, err := NewConfig(WithHintCache(cache), WithHints(testCountingHint, testSquareHint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := big.NewInt(101)
+	call := func(id HintID, x int64) *big.Int {
+		outputs := []*big.Int{new(big.Int), new(big.Int)}
+		if err := cfg.HintFunctions[id](field, []*big.Int{big.NewInt(x), big.NewInt(x)}, outputs); err != nil {
+			t.Fatal(err)
+		}
+		return outputs[0]
+	}
+
+	nbCountingHintCalls = 0
+	id := GetHintID(testCountingHint)
+	for i := 0; i < 3; i++ {
+		if res := call(id, 5); res.Int64() != 6 {
+			t.Fatalf("cached hint returned %s", res)
+		}
+	}
+	if res := call(id, 7); res.Int64() != 8 {
+		t.Fatalf("cached hint returned %s", res)
+	}
+	if nbCountingHintCalls != 2 {
+		t.Fatalf("the hint ran %d times for 2 distinct inputs", nbCountingHintCalls)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Fatalf("unexpected statistics %+v", stats)
+	}
+
+	// the other field is another entry
+	field = big.NewInt(103)
+	call(id, 5)
+	if nbCountingHintCalls != 3 {
+		t.Fatal("the field must be part of the key")
+	}
+
+	// the hints which aren't pure aren't cached
+	call(GetHintID(testSquareHint), 5)
+	if cache.Stats().Misses != 3 {
+		t.Fatal("a hint which isn't pure was cached")
+	}
+}
+
+func TestHintCacheEviction(t *testing.T) {
+	// room for 2 entries
+	key := hintCacheKey(GetHintID(testCountingHint), big.NewInt(101), []*big.Int{big.NewInt(1)}, 1)
+	cache := NewHintCache(2*(len(key)+entryOverhead+8), testCountingHint)
+	cfg, err := NewConfig(WithHints(testCountingHint), WithHintCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := cfg.HintFunctions[GetHintID(testCountingHint)]
+	call := func(x int64) {
+		if err := f(big.NewInt(101), []*big.Int{big.NewInt(x)}, []*big.Int{new(big.Int)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nbCountingHintCalls = 0
+	call(1)
+	call(2)
+	call(1) // 1 is now the most recently used
+	call(3) // evicts 2
+	call(1)
+	if nbCountingHintCalls != 3 {
+		t.Fatalf("the hint ran %d times, expected 3", nbCountingHintCalls)
+	}
+	call(2)
+	if nbCountingHintCalls != 4 {
+		t.Fatal("the least recently used entry wasn't evicted")
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Bytes > 2*(len(key)+entryOverhead+8) {
+		t.Fatalf("unexpected statistics %+v", stats)
+	}
+}
+
+func TestHintCacheBatch(t *testing.T) {
+	var nbBatchedCalls int
+	batch := func(field *big.Int, inputs, outputs [][]*big.Int) error {
+		for i := range inputs {
+			nbBatchedCalls++
+			outputs[i][0].Add(inputs[i][0], big.NewInt(1)).Mod(outputs[i][0], field)
+		}
+		return nil
+	}
+	cache := NewHintCache(1<<20, testCountingHint)
+	cfg, err := NewConfig(WithHintCache(cache), WithBatchHint(testCountingHint, batch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := GetHintID(testCountingHint)
+	field := big.NewInt(101)
+
+	// a single call fills the cache for the batches
+	nbCountingHintCalls = 0
+	if err := cfg.HintFunctions[id](field, []*big.Int{big.NewInt(5)}, []*big.Int{new(big.Int)}); err != nil {
+		t.Fatal(err)
+	}
+	inputs := [][]*big.Int{{big.NewInt(5)}, {big.NewInt(7)}, {big.NewInt(7)}}
+	for i := 0; i < 2; i++ {
+		outputs := [][]*big.Int{{new(big.Int)}, {new(big.Int)}, {new(big.Int)}}
+		if err := cfg.BatchHints[id](field, inputs, outputs); err != nil {
+			t.Fatal(err)
+		}
+		for j, expected := range []int64{6, 8, 8} {
+			if outputs[j][0].Int64() != expected {
+				t.Fatalf("cached batch hint returned %s for call %d", outputs[j][0], j)
+			}
+		}
+	}
+	// the first batch evaluates the calls of 7, both missed before the evaluation
+	if nbCountingHintCalls != 1 || nbBatchedCalls != 2 {
+		t.Fatalf("the hint ran %d times and the batch evaluated %d calls", nbCountingHintCalls, nbBatchedCalls)
+	}
+
+	// the batches fill the cache for the single calls
+	if err := cfg.HintFunctions[id](field, []*big.Int{big.NewInt(7)}, []*big.Int{new(big.Int)}); err != nil {
+		t.Fatal(err)
+	}
+	if nbCountingHintCalls != 1 {
+		t.Fatal("the result of the batch wasn't cached")
+	}
+}
@@ -2,6 +2,7 @@ package solver
 
 import (
 	"math/big"
+	"reflect"
 	"sync"
 
 	"github.com/consensys/gnark/logger"
@@ -12,8 +13,10 @@ func init() {
 }
 
 var (
-	registry  = make(map[HintID]Hint)
-	registryM sync.RWMutex
+	registry        = make(map[HintID]Hint)
+	registryNames   = make(map[uintptr]string) // code pointer to the name of a named hint, "" if it has several
+	registryIDNames = make(map[HintID]string)  // name of the registered hints
+	registryM       sync.RWMutex
 )
 
 // RegisterHint registers a hint function in the global registry.
@@ -21,17 +24,59 @@ func RegisterHint(hintFns ...Hint) {
 	registryM.Lock()
 	defer registryM.Unlock()
 	for _, hintFn := range hintFns {
-		key := GetHintID(hintFn)
-		name := GetHintName(hintFn)
+		name, _ := hintName(hintFn)
+		key := hintIDFromName(name)
 		if _, ok := registry[key]; ok {
 			log := logger.Logger()
 			log.Warn().Str("name", name).Msg("function registered multiple times")
 			return
 		}
 		registry[key] = hintFn
+		registryIDNames[key] = name
 	}
 }
 
+// RegisterNamedHint registers a hint function in the global registry under the qualified name
+// "namespace/name@vversion" (see HintName), from which its ID is derived instead of from the
+// name of the Go function. The ID is then stable when the function is renamed or moved, and a
+// circuit compiled with a version of a hint can't be solved with another one: a new version
+// of a hint with different semantics must be registered as a new function.
+//
+// The function may be a stub for a hint executed out of process, which the prover provides
+// with WithRemoteHints.
+//
+// The name of a function is found from its code, which the closures of a same function literal
+// share: a function registered under several names, such as the stubs returned by a factory,
+// can't be called with NewHint, and must be called with the returned ID with NewHintForId.
+func RegisterNamedHint(namespace, name string, version uint32, hintFn Hint) HintID {
+	qualified := HintName(namespace, name, version)
+	key := hintIDFromName(qualified)
+
+	registryM.Lock()
+	defer registryM.Unlock()
+	if _, ok := registry[key]; ok {
+		log := logger.Logger()
+		log.Warn().Str("name", qualified).Msg("function registered multiple times")
+		return key
+	}
+	fnptr := reflect.ValueOf(hintFn).Pointer()
+	if previous, ok := registryNames[fnptr]; ok && previous != qualified {
+		registryNames[fnptr] = ""
+	} else {
+		registryNames[fnptr] = qualified
+	}
+	registry[key] = hintFn
+	registryIDNames[key] = qualified
+	return key
+}
+
+// GetRegisteredHint returns the hint function registered with the ID, or nil.
+func GetRegisteredHint(key HintID) Hint {
+	registryM.RLock()
+	defer registryM.RUnlock()
+	return registry[key]
+}
+
 // GetRegisteredHints returns all registered hint functions.
 func GetRegisteredHints() []Hint {
 	registryM.RLock()
@@ -0,0 +1,254 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+)
+
+// The remote hints are executed by a hint server, for example a Rust witness generator, which
+// the solver reaches over a local socket instead of linking the hint code into the prover.
+//
+// The client sends a request per hint call and the server answers it on the same connection.
+// The integers are big-endian, and the byte strings (names, big integers as unsigned
+// big-endian bytes) are prefixed with their length as an uint32:
+//
+//	request:  name, field modulus, uint32 number of inputs, inputs..., uint32 number of outputs
+//	response: byte 0, outputs...   if the hint succeeded, with exactly the requested outputs
+//	          byte 1, message      if the hint failed
+//
+// The name is the qualified name of the hint, see HintName.
+
+const maxRemoteHintMessage = 1 << 24
+
+// RemoteHints is a client of a hint server. It is safe for concurrent use, the calls being
+// serialized on a single connection, which is re-established after an I/O error.
+type RemoteHints struct {
+	network, address string
+
+	lock sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// DialHints connects to the hint server listening at the address on the network ("unix" or
+// "tcp"), see net.Dial.
+func DialHints(network, address string) (*RemoteHints, error) {
+	r := &RemoteHints{network: network, address: address}
+	if err := r.dial(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RemoteHints) dial() error {
+	conn, err := net.Dial(r.network, r.address)
+	if err != nil {
+		return fmt.Errorf("dial hint server: %w", err)
+	}
+	r.conn = conn
+	r.r = bufio.NewReader(conn)
+	r.w = bufio.NewWriter(conn)
+	return nil
+}
+
+// Close closes the connection to the hint server.
+func (r *RemoteHints) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	return err
+}
+
+// Hint returns a hint function executing the hint with the qualified name on the server.
+func (r *RemoteHints) Hint(name string) Hint {
+	return func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+		return r.call(name, field, inputs, outputs)
+	}
+}
+
+func (r *RemoteHints) call(name string, field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.conn == nil {
+		if err := r.dial(); err != nil {
+			return err
+		}
+	}
+
+	hintErr, err := r.roundTrip(name, field, inputs, outputs)
+	if err != nil {
+		// the connection is in an unknown state
+		_ = r.conn.Close()
+		r.conn = nil
+		return fmt.Errorf("remote hint %s: %w", name, err)
+	}
+	if hintErr != "" {
+		return fmt.Errorf("remote hint %s: %s", name, hintErr)
+	}
+	return nil
+}
+
+// roundTrip sends the request and reads the outputs, or the error message of the hint.
+func (r *RemoteHints) roundTrip(name string, field *big.Int, inputs []*big.Int, outputs []*big.Int) (string, error) {
+	writeBytes(r.w, []byte(name))
+	writeBytes(r.w, field.Bytes())
+	writeUint32(r.w, uint32(len(inputs)))
+	for _, in := range inputs {
+		writeBytes(r.w, in.Bytes())
+	}
+	writeUint32(r.w, uint32(len(outputs)))
+	if err := r.w.Flush(); err != nil {
+		return "", err
+	}
+
+	status, err := r.r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch status {
+	case 0:
+		for _, out := range outputs {
+			b, err := readBytes(r.r)
+			if err != nil {
+				return "", err
+			}
+			out.SetBytes(b)
+		}
+		return "", nil
+	case 1:
+		msg, err := readBytes(r.r)
+		if err != nil {
+			return "", err
+		}
+		if len(msg) == 0 {
+			msg = []byte("hint failed")
+		}
+		return string(msg), nil
+	default:
+		return "", fmt.Errorf("invalid response status %d", status)
+	}
+}
+
+// WithRemoteHints is a solver option which executes the hints with the qualified names (see
+// HintName) on the hint server, in place of the functions registered with RegisterNamedHint.
+func WithRemoteHints(r *RemoteHints, names ...string) Option {
+	return func(opt *Config) error {
+		for _, name := range names {
+			opt.HintFunctions[hintIDFromName(name)] = r.Hint(name)
+		}
+		return nil
+	}
+}
+
+// ServeHints serves the hints, indexed by their qualified name, to the RemoteHints clients
+// connecting to the listener. It returns when the listener fails, for instance when it is
+// closed.
+func ServeHints(l net.Listener, hints map[string]Hint) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveHintsConn(conn, hints)
+	}
+}
+
+func serveHintsConn(conn net.Conn, hints map[string]Hint) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		name, err := readBytes(r)
+		if err != nil {
+			return
+		}
+		fieldBytes, err := readBytes(r)
+		if err != nil {
+			return
+		}
+		nbInputs, err := readUint32(r)
+		if err != nil || nbInputs > maxRemoteHintMessage {
+			return
+		}
+		inputs := make([]*big.Int, nbInputs)
+		for i := range inputs {
+			b, err := readBytes(r)
+			if err != nil {
+				return
+			}
+			inputs[i] = new(big.Int).SetBytes(b)
+		}
+		nbOutputs, err := readUint32(r)
+		if err != nil || nbOutputs > maxRemoteHintMessage {
+			return
+		}
+		outputs := make([]*big.Int, nbOutputs)
+		for i := range outputs {
+			outputs[i] = new(big.Int)
+		}
+
+		hintErr := errors.New("unknown hint " + string(name))
+		if f, ok := hints[string(name)]; ok {
+			hintErr = f(new(big.Int).SetBytes(fieldBytes), inputs, outputs)
+		}
+		if hintErr != nil {
+			_ = w.WriteByte(1)
+			writeBytes(w, []byte(hintErr.Error()))
+		} else {
+			_ = w.WriteByte(0)
+			for _, out := range outputs {
+				writeBytes(w, out.Bytes())
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// the bufio.Writer errors are sticky and returned by Flush.
+
+func writeUint32(w *bufio.Writer, v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	_, _ = w.Write(buf[:])
+}
+
+func writeBytes(w *bufio.Writer, b []byte) {
+	writeUint32(w, uint32(len(b)))
+	_, _ = w.Write(b)
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxRemoteHintMessage {
+		return nil, fmt.Errorf("message of %d bytes exceeds the limit", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,111 @@
+package solver
+
+import (
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSquareHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].Mul(inputs[0], inputs[0]).Mod(outputs[0], field)
+	outputs[1].Set(inputs[1])
+	return nil
+}
+
+func testStubHint(_ *big.Int, _ []*big.Int, _ []*big.Int) error {
+	return nil
+}
+
+func TestNamedHint(t *testing.T) {
+	id := RegisterNamedHint("test", "stub", 2, testStubHint)
+	if id != NamedHintID("test", "stub", 2) || id != GetHintID(testStubHint) {
+		t.Fatal("the ID of a named hint must derive from its qualified name")
+	}
+	if name := GetHintName(testStubHint); name != "test/stub@v2" {
+		t.Fatalf("unexpected name %s", name)
+	}
+	if NamedHintID("test", "stub", 1) == id {
+		t.Fatal("versions must have distinct IDs")
+	}
+}
+
+func testConstantHint(c int64) Hint {
+	return func(_ *big.Int, _ []*big.Int, outputs []*big.Int) error {
+		outputs[0].SetInt64(c)
+		return nil
+	}
+}
+
+func TestNamedHintsOfFactory(t *testing.T) {
+	a, b := testConstantHint(1), testConstantHint(2)
+	idA := RegisterNamedHint("test", "a", 1, a)
+	idB := RegisterNamedHint("test", "b", 1, b)
+	if idA == idB {
+		t.Fatal("the names must have distinct IDs")
+	}
+	if id := GetHintID(a); id == idA || id == idB {
+		t.Fatal("the closures of a factory must not be identified by the name of another")
+	}
+	if _, _, err := LookupHint(a); err == nil {
+		t.Fatal("expected an error on a function registered under several names")
+	}
+	if GetHintNameByID(idA) != "test/a@v1" || GetHintNameByID(idB) != "test/b@v1" {
+		t.Fatal("unexpected names")
+	}
+
+	opt, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[HintID]int64{idA: 1, idB: 2} {
+		outputs := []*big.Int{new(big.Int)}
+		if err := opt.HintFunctions[id](nil, nil, outputs); err != nil {
+			t.Fatal(err)
+		}
+		if outputs[0].Int64() != want {
+			t.Fatalf("hint %s: expected %d, got %s", GetHintNameByID(id), want, outputs[0])
+		}
+	}
+}
+
+func TestRemoteHints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hints.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip("unix sockets not supported:", err)
+	}
+	defer l.Close()
+	square := HintName("test", "square", 1)
+	go ServeHints(l, map[string]Hint{square: testSquareHint}) // #nosec G104 -- returns when closed
+
+	client, err := DialHints("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	opt, err := NewConfig(WithRemoteHints(client, square))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := opt.HintFunctions[NamedHintID("test", "square", 1)]
+	if !ok {
+		t.Fatal("remote hint not set")
+	}
+
+	field := big.NewInt(101)
+	outputs := []*big.Int{new(big.Int), new(big.Int)}
+	if err := f(field, []*big.Int{big.NewInt(12), big.NewInt(0)}, outputs); err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Int64() != 43 || outputs[1].Sign() != 0 {
+		t.Fatalf("unexpected outputs %s, %s", outputs[0], outputs[1])
+	}
+
+	err = client.Hint(HintName("test", "square", 2))(field, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown hint test/square@v2") {
+		t.Fatalf("expected an unknown hint error, got %v", err)
+	}
+}
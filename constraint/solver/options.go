@@ -12,8 +12,13 @@ type Option func(*Config) error
 
 // Config is the configuration for the solver with the options applied.
 type Config struct {
-	HintFunctions map[HintID]Hint // defaults to all built-in hint functions
-	Logger        zerolog.Logger  // defaults to gnark.Logger
+	HintFunctions map[HintID]Hint      // defaults to all built-in hint functions
+	BatchHints    map[HintID]BatchHint // the hints evaluated by batches, see WithBatchHint
+	Logger        zerolog.Logger       // defaults to gnark.Logger
+	Spill         bool                 // back the solution with a temporary file, see WithSpillToDisk
+	SpillDir      string               // directory of the temporary file, defaults to os.TempDir()
+
+	hintCache *HintCache // memoizes the pure hints, see WithHintCache
 }
 
 // WithHints is a solver option that specifies additional hint functions to be used
@@ -57,13 +62,18 @@ func WithLogger(l zerolog.Logger) Option {
 func NewConfig(opts ...Option) (Config, error) {
 	log := logger.Logger()
 	opt := Config{Logger: log, HintFunctions: make(map[HintID]Hint)}
-	for _, v := range GetRegisteredHints() {
-		opt.HintFunctions[GetHintID(v)] = v
+	registryM.RLock()
+	for k, v := range registry {
+		opt.HintFunctions[k] = v
 	}
+	registryM.RUnlock()
 	for _, option := range opts {
 		if err := option(&opt); err != nil {
 			return Config{}, err
 		}
 	}
+	if opt.hintCache != nil {
+		opt.hintCache.wrap(opt.HintFunctions, opt.BatchHints)
+	}
 	return opt, nil
 }
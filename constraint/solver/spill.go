@@ -0,0 +1,39 @@
+package solver
+
+import (
+	"unsafe"
+)
+
+// WithSpillToDisk is a solver option which backs the wire values of the solution with a
+// memory-mapped temporary file in dir (os.TempDir() if empty), for circuits whose wires don't
+// fit in memory. The kernel then writes the cold regions of the vector back to the file and
+// keeps in memory the pages of the wires being solved and of their dependencies, trading speed
+// for feasibility.
+//
+// Only the wire values are spilled: the solved flags of the wires, and the vectors A, B and C
+// of the R1CS solutions (one element per constraint each), stay in memory.
+//
+// The file is removed as soon as it is mapped, and its space is reclaimed when the solution
+// is released. On platforms without memory mapping, the vector is allocated in memory.
+func WithSpillToDisk(dir string) Option {
+	return func(opt *Config) error {
+		opt.Spill = true
+		opt.SpillDir = dir
+		return nil
+	}
+}
+
+// NewSpilledVector returns a vector of n elements backed by a memory-mapped temporary file in
+// dir, and the function unmapping it. The vector must not be used after the release.
+func NewSpilledVector[T any](dir string, n int) ([]T, func() error, error) {
+	if n == 0 {
+		return nil, func() error { return nil }, nil
+	}
+	var zero T
+	data, err := mapTempFile(dir, n*int(unsafe.Sizeof(zero)))
+	if err != nil {
+		return nil, nil, err
+	}
+	v := unsafe.Slice((*T)(unsafe.Pointer(&data[0])), n)
+	return v, func() error { return unmapTempFile(data) }, nil
+}
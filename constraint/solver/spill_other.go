@@ -0,0 +1,12 @@
+//go:build !unix
+
+package solver
+
+// mapTempFile allocates the memory, on platforms where mapping a file isn't supported.
+func mapTempFile(_ string, size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func unmapTempFile([]byte) error {
+	return nil
+}
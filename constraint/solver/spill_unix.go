@@ -0,0 +1,28 @@
+//go:build unix
+
+package solver
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapTempFile maps a zeroed temporary file of the given size, which is removed right away.
+func mapTempFile(dir string, size int) ([]byte, error) {
+	f, err := os.CreateTemp(dir, "gnark-solver-*")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, err
+	}
+
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+func unmapTempFile(data []byte) error {
+	return syscall.Munmap(data)
+}
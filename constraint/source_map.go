@@ -0,0 +1,104 @@
+package constraint
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceMap maps all the constraints of a system to the stack recorded when they were added,
+// so that the solver errors point to the circuit source even without debug information. See
+// frontend.WithSourceMapping.
+//
+// The stacks are deduplicated and the consecutive constraints with the same stack share a
+// run, which keeps the map small compared to the system.
+type SourceMap struct {
+	// Stacks are the distinct stacks, as location IDs in the SymbolTable.
+	Stacks [][]int
+
+	// Runs are sorted by their first constraint.
+	Runs []SourceRun
+
+	mStacks map[string]int // stack key to id in Stacks
+}
+
+// SourceRun is a range of consecutive constraints with the same stack, which ends at the first
+// constraint of the next run.
+type SourceRun struct {
+	FirstConstraint uint32
+	Stack           uint32
+}
+
+// EnableSourceMapping makes the system record the stack of all the constraints added from now
+// on, at a memory and compile time cost.
+func (system *System) EnableSourceMapping() {
+	if system.SourceMap == nil {
+		system.SourceMap = &SourceMap{}
+	}
+}
+
+// attachSource records the current stack for the constraints starting at cID, if the source
+// mapping is enabled.
+func (system *System) attachSource(cID int) {
+	m := system.SourceMap
+	if m == nil {
+		return
+	}
+	if m.mStacks == nil {
+		m.mStacks = make(map[string]int, len(m.Stacks))
+		for sID := range m.Stacks {
+			m.mStacks[stackKey(m.Stacks[sID])] = sID
+		}
+	}
+	stack := system.SymbolTable.CollectFullStack()
+	key := stackKey(stack)
+	sID, ok := m.mStacks[key]
+	if !ok {
+		sID = len(m.Stacks)
+		m.Stacks = append(m.Stacks, stack)
+		m.mStacks[key] = sID
+	}
+
+	if n := len(m.Runs); n != 0 && m.Runs[n-1].Stack == uint32(sID) {
+		return
+	}
+	m.Runs = append(m.Runs, SourceRun{FirstConstraint: uint32(cID), Stack: uint32(sID)})
+}
+
+// ConstraintSource returns the source location of the constraint cID (an original constraint
+// ID, see OriginalConstraintID) as "function file:line" entries, innermost first. It is the stack
+// of its debug information if any, or the one recorded by the source mapping, or nil.
+func (system *System) ConstraintSource(cID int) []string {
+	if dID, ok := system.MDebug[cID]; ok && dID < len(system.DebugInfo) {
+		return system.stackLocations(system.DebugInfo[dID].Stack)
+	}
+
+	if system.SourceMap == nil {
+		return nil
+	}
+	runs := system.SourceMap.Runs
+	i := sort.Search(len(runs), func(i int) bool { return int(runs[i].FirstConstraint) > cID }) - 1
+	if i < 0 || int(runs[i].Stack) >= len(system.SourceMap.Stacks) {
+		return nil
+	}
+	return system.stackLocations(system.SourceMap.Stacks[runs[i].Stack])
+}
+
+func stackKey(stack []int) string {
+	var sbb strings.Builder
+	for _, lID := range stack {
+		sbb.WriteString(strconv.Itoa(lID))
+		sbb.WriteByte(',')
+	}
+	return sbb.String()
+}
+
+func (system *System) stackLocations(stack []int) []string {
+	res := make([]string, 0, len(stack))
+	for _, lID := range stack {
+		location := system.SymbolTable.Locations[lID]
+		function := system.SymbolTable.Functions[location.FunctionID]
+		res = append(res, function.Name+" "+function.Filename+":"+strconv.Itoa(int(location.Line)))
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package constraint_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type sourceMapCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *sourceMapCircuit) Define(api frontend.API) error {
+	a := c.X
+	for i := 0; i < 4; i++ {
+		a = api.Mul(a, c.X)
+	}
+	assertPower(api, a, c.Y)
+	return nil
+}
+
+// assertPower and assertEqual make a stack deeper than the 2 frames of the debug information.
+func assertPower(api frontend.API, a, y frontend.Variable) {
+	assertEqual(api, a, y)
+}
+
+func assertEqual(api frontend.API, a, b frontend.Variable) {
+	api.AssertIsEqual(a, b)
+}
+
+func TestSourceMapping(t *testing.T) {
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		assert := require.New(t)
+
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), newBuilder, &sourceMapCircuit{}, frontend.WithSourceMapping())
+		assert.NoError(err)
+
+		invalid, err := frontend.NewWitness(&sourceMapCircuit{X: 2, Y: 3}, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		_, err = ccs.Solve(invalid)
+		assert.Error(err)
+		assert.Contains(err.Error(), "constraint_test.assertEqual source_map_test.go")
+		assert.Contains(err.Error(), "constraint_test.(*sourceMapCircuit).Define source_map_test.go")
+		assert.NotContains(err.Error(), "builder")
+	}
+}
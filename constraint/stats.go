@@ -0,0 +1,194 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// R1CSStats are the statistics of a R1CS which determine the cost of its groth16 proofs, see
+// Stats. They are encoded in JSON for the tools picking the provers and the hardware.
+type R1CSStats struct {
+	NbConstraints  int `json:"nbConstraints"`
+	NbInstructions int `json:"nbInstructions"`
+
+	// NbWires is the number of wires: the public ones (the constant wire included), the secret
+	// ones, and the internal ones.
+	NbWires    int `json:"nbWires"`
+	NbPublic   int `json:"nbPublic"`
+	NbSecret   int `json:"nbSecret"`
+	NbInternal int `json:"nbInternal"`
+
+	// DomainSize is the size of the domain of the NTTs, the number of constraints rounded up
+	// to a power of 2.
+	DomainSize uint64 `json:"domainSize"`
+
+	// NbInfinityA (resp. NbInfinityB) is the number of wires absent from the left (resp.
+	// right) factors of all the constraints, whose points of A (resp. B) in the proving key
+	// are at infinity, and skipped by the MSMs. The ratios are to NbWires.
+	NbInfinityA    int     `json:"nbInfinityA"`
+	NbInfinityB    int     `json:"nbInfinityB"`
+	InfinityRatioA float64 `json:"infinityRatioA"`
+	InfinityRatioB float64 `json:"infinityRatioB"`
+
+	// NbUnusedPrivate is the number of private wires in no constraint, whose points of K are
+	// at infinity.
+	NbUnusedPrivate int `json:"nbUnusedPrivate"`
+
+	Commitments []CommitmentStats `json:"commitments"`
+	MSM         MSMStats          `json:"msm"`
+}
+
+// CommitmentStats are the sizes of a commitment of the system.
+type CommitmentStats struct {
+	NbCommitted        int `json:"nbCommitted"`
+	NbPublicCommitted  int `json:"nbPublicCommitted"`
+	NbPrivateCommitted int `json:"nbPrivateCommitted"`
+}
+
+// MSMStats are the sizes of the multi-scalar multiplications of a groth16 proof, without the
+// points at infinity of the proving key, before the optional removal of the zero wire values
+// (see backend.WithSparseMSM).
+type MSMStats struct {
+	// A and B1 are the MSMs in G1 of the wire values for [A]1 and [B]1, and B2 the one in G2
+	// for [B]2.
+	A  int `json:"a"`
+	B1 int `json:"b1"`
+	B2 int `json:"b2"`
+
+	// K is the MSM of the private wire values, and Z the one of the quotient h, for [Krs]1.
+	K int `json:"k"`
+	Z int `json:"z"`
+
+	// Commitments are the MSMs of the private committed values of each commitment, computed
+	// twice: for the commitment and for its proof of knowledge.
+	Commitments []int `json:"commitments"`
+
+	// G1 and G2 are the total number of points of the MSMs in G1 and in G2.
+	G1 int `json:"g1"`
+	G2 int `json:"g2"`
+}
+
+// Stats returns the statistics of a R1CS, see R1CSStats.
+func Stats(cs ConstraintSystem) (*R1CSStats, error) {
+	s, ok := cs.(systemGetter)
+	if !ok {
+		return nil, errors.New("unsupported constraint system")
+	}
+	system := s.getSystem()
+	if system.Type != SystemR1CS {
+		return nil, errors.New("the statistics are only implemented for R1CS")
+	}
+	return system.stats(), nil
+}
+
+func (system *System) stats() *R1CSStats {
+	nbWires := len(system.Public) + len(system.Secret) + system.NbInternalVariables
+	res := &R1CSStats{
+		NbConstraints:  system.GetNbConstraints(),
+		NbInstructions: system.GetNbInstructions(),
+		NbWires:        nbWires,
+		NbPublic:       len(system.Public),
+		NbSecret:       len(system.Secret),
+		NbInternal:     system.NbInternalVariables,
+		DomainSize:     ecc.NextPowerOfTwo(uint64(system.GetNbConstraints())),
+	}
+
+	// the wires in the left, right and output factors of the constraints, with a non-zero
+	// coefficient
+	const (
+		inL = 1 << iota
+		inR
+		inO
+	)
+	refs := make([]uint8, nbWires)
+	it := system.GetR1CIterator()
+	for c := it.Next(); c != nil; c = it.Next() {
+		for i, l := range []LinearExpression{c.L, c.R, c.O} {
+			for _, t := range l {
+				if t.CoeffID() != CoeffIdZero {
+					refs[t.WireID()] |= 1 << i
+				}
+			}
+		}
+	}
+	for _, r := range refs {
+		if r&inL == 0 {
+			res.NbInfinityA++
+		}
+		if r&inR == 0 {
+			res.NbInfinityB++
+		}
+	}
+	if nbWires != 0 {
+		res.InfinityRatioA = float64(res.NbInfinityA) / float64(nbWires)
+		res.InfinityRatioB = float64(res.NbInfinityB) / float64(nbWires)
+	}
+
+	// the private wires of Krs exclude the private committed wires and the commitments, which
+	// are public to groth16; the points of the wires in no constraint are at infinity
+	public := make(map[int]bool)
+	for _, w := range system.CommitmentInfo.PrivateToPublic() {
+		public[w] = true
+	}
+	for w := len(system.Public); w < nbWires; w++ {
+		if public[w] {
+			continue
+		}
+		if refs[w] == 0 {
+			res.NbUnusedPrivate++
+		} else {
+			res.MSM.K++
+		}
+	}
+
+	res.MSM.A = nbWires - res.NbInfinityA
+	res.MSM.B1 = nbWires - res.NbInfinityB
+	res.MSM.B2 = res.MSM.B1
+	if res.DomainSize > 0 {
+		res.MSM.Z = int(res.DomainSize - 1) // deg(h) = n-2
+	}
+	res.MSM.G1 = res.MSM.A + res.MSM.B1 + res.MSM.K + res.MSM.Z
+	res.MSM.G2 = res.MSM.B2
+
+	res.Commitments = make([]CommitmentStats, len(system.CommitmentInfo))
+	res.MSM.Commitments = make([]int, len(system.CommitmentInfo))
+	for i := range system.CommitmentInfo {
+		c := &system.CommitmentInfo[i]
+		res.Commitments[i] = CommitmentStats{
+			NbCommitted:        c.NbCommitted(),
+			NbPublicCommitted:  c.NbPublicCommitted(),
+			NbPrivateCommitted: c.NbPrivateCommitted,
+		}
+		for _, w := range c.PrivateCommitted() {
+			if refs[w] != 0 {
+				res.MSM.Commitments[i]++
+			}
+		}
+		res.MSM.G1 += 2 * res.MSM.Commitments[i]
+	}
+
+	return res
+}
+
+// WriteTo writes a human readable report of the statistics.
+func (s *R1CSStats) WriteTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+
+	fmt.Fprintf(&_w, "constraints:  %d (domain %d), %d instructions\n", s.NbConstraints, s.DomainSize, s.NbInstructions)
+	fmt.Fprintf(&_w, "wires:        %d (%d public, %d secret, %d internal)\n", s.NbWires, s.NbPublic, s.NbSecret, s.NbInternal)
+	fmt.Fprintf(&_w, "infinity:     A %d (%.1f%%), B %d (%.1f%%), %d unused private wires\n", s.NbInfinityA, 100*s.InfinityRatioA, s.NbInfinityB, 100*s.InfinityRatioB, s.NbUnusedPrivate)
+	for i, c := range s.Commitments {
+		fmt.Fprintf(&_w, "commitment %d: %d committed (%d public, %d private)\n", i, c.NbCommitted, c.NbPublicCommitted, c.NbPrivateCommitted)
+	}
+	fmt.Fprintf(&_w, "MSM G1:       %d points: A %d, B %d, K %d, Z %d", s.MSM.G1, s.MSM.A, s.MSM.B1, s.MSM.K, s.MSM.Z)
+	for i, n := range s.MSM.Commitments {
+		fmt.Fprintf(&_w, ", commitment %d 2×%d", i, n)
+	}
+	fmt.Fprintf(&_w, "\nMSM G2:       %d points: B %d\n", s.MSM.G2, s.MSM.B2)
+
+	return _w.N, nil
+}
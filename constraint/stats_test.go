@@ -0,0 +1,57 @@
+package constraint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &lookupCircuit{})
+	assert.NoError(err)
+	stats, err := constraint.Stats(ccs)
+	assert.NoError(err)
+	assert.Equal(ccs.GetNbConstraints(), stats.NbConstraints)
+	assert.Equal(ccs.GetNbPublicVariables(), stats.NbPublic)
+	assert.Len(stats.Commitments, 1)
+
+	// the sizes are the ones of the proving key
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	_pk := pk.(*groth16_bn254.ProvingKey)
+	assert.EqualValues(_pk.NbInfinityA, stats.NbInfinityA)
+	assert.EqualValues(_pk.NbInfinityB, stats.NbInfinityB)
+	assert.Equal(len(_pk.G1.A), stats.MSM.A)
+	assert.Equal(len(_pk.G1.B), stats.MSM.B1)
+	assert.Equal(len(_pk.G2.B), stats.MSM.B2)
+	assert.Equal(len(_pk.G1.K)-len(_pk.G1InfPointIndices.K), stats.MSM.K)
+	assert.EqualValues(_pk.Domain.Cardinality, stats.DomainSize)
+	assert.Equal(len(_pk.CommitmentKeys[0].Basis), stats.Commitments[0].NbPrivateCommitted)
+
+	var buf bytes.Buffer
+	_, err = stats.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Contains(buf.String(), "MSM G2")
+
+	encoded, err := json.Marshal(stats)
+	assert.NoError(err)
+	var decoded constraint.R1CSStats
+	assert.NoError(json.Unmarshal(encoded, &decoded))
+	assert.Equal(*stats, decoded)
+
+	sparse, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &lookupCircuit{})
+	assert.NoError(err)
+	_, err = constraint.Stats(sparse)
+	assert.Error(err)
+}
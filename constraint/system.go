@@ -46,11 +46,21 @@ type ConstraintSystem interface {
 	// AddSolverHint adds a hint to the solver such that the output variables will be computed
 	// using a call to output := f(input...) at solve time.
 	AddSolverHint(f solver.Hint, input []LinearExpression, nbOutput int) (internalVariables []int, err error)
+	// AddSolverHintForId is AddSolverHint for the hint registered with the ID, or provided to the
+	// solver with it.
+	AddSolverHintForId(id solver.HintID, input []LinearExpression, nbOutput int) (internalVariables []int, err error)
 
 	AddCommitment(c Commitment) error
 
+	// GetCommitments returns the commitments of the constraint system, in the order they were added.
+	GetCommitments() Commitments
+
 	AddLog(l LogEntry)
 
+	// MarkBoolean records that the frontend marked the wire as boolean, without necessarily
+	// constraining it, for Lint.
+	MarkBoolean(wireID int)
+
 	// MakeTerm returns a new Term. The constraint system may store coefficients in a map, so
 	// calls to this function will grow the memory usage of the constraint system.
 	MakeTerm(coeff *Element, variableID int) Term
@@ -59,6 +69,10 @@ type ConstraintSystem interface {
 	// but is not purging for unused coeff either, so this grows memory usage.
 	AddCoeff(coeff Element) uint32
 
+	// CoeffID returns the ID of the coefficient if the system holds it. It doesn't modify the
+	// system, and may be called concurrently as long as no coefficient is added.
+	CoeffID(coeff *Element) (uint32, bool)
+
 	NewDebugInfo(errName string, i ...interface{}) DebugInfo
 
 	// AttachDebugInfo enables attaching debug information to multiple constraints.
@@ -66,13 +80,34 @@ type ConstraintSystem interface {
 	// debug information only once.
 	AttachDebugInfo(debugInfo DebugInfo, constraintID []int)
 
+	// EnableSourceMapping records the stack of all the constraints added from now on, to
+	// locate the unsatisfied constraints in the solver errors. See System.ConstraintSource.
+	EnableSourceMapping()
+
 	// CheckUnconstrainedWires returns and error if the constraint system has wires that are not uniquely constrained.
 	// This is experimental.
 	CheckUnconstrainedWires() error
 
+	// RequireFeatures records optional features the system requires, see Feature.
+	RequireFeatures(f ...Feature)
+
+	// GetFeatures returns the optional features the system requires, checked when it is
+	// deserialized.
+	GetFeatures() Features
+
+	// ReorderInstructions lays out the instructions in blocks ordered for solving and keeps
+	// a permutation map to the original constraint IDs. See System.ReorderInstructions.
+	ReorderInstructions() error
+
+	// OriginalConstraintID returns the ID a constraint had before the instructions were reordered.
+	OriginalConstraintID(cID int) int
+
 	// AddBlueprint registers the given blueprint and returns its id. This should be called only once per blueprint.
 	AddBlueprint(b Blueprint) BlueprintID
 
+	// GetBlueprint returns the blueprint with the given id.
+	GetBlueprint(id BlueprintID) Blueprint
+
 	GetInstruction(int) Instruction
 
 	GetCoefficient(i int) Element
@@ -44,9 +44,15 @@ type solver struct {
 	solved   []bool
 	nbSolved uint64
 
+	// releaseValues unmaps values if they are spilled to disk, nil otherwise
+	releaseValues func() error
+
 	// maps hintID to hint function
 	mHintsFunctions map[csolver.HintID]csolver.Hint
 
+	// maps hintID to the batch evaluation of its calls, see csolver.WithBatchHint
+	batchHints map[csolver.HintID]csolver.BatchHint
+
 	// used to out api.Println
 	logger zerolog.Logger
 
@@ -90,11 +96,23 @@ func newSolver(cs *system, witness fr.Vector, opts ...csolver.Option) (*solver,
 		return nil, fmt.Errorf("solver missing hint(s): %v", missing)
 	}
 
+	var values []fr.Element
+	var releaseValues func() error
+	if opt.Spill {
+		if values, releaseValues, err = csolver.NewSpilledVector[fr.Element](opt.SpillDir, nbWires); err != nil {
+			return nil, fmt.Errorf("spill the solution to disk: %w", err)
+		}
+	} else {
+		values = make([]fr.Element, nbWires)
+	}
+
 	s := solver{
 		system:          cs,
-		values:          make([]fr.Element, nbWires),
+		values:          values,
 		solved:          make([]bool, nbWires),
 		mHintsFunctions: hintFunctions,
+		batchHints:      opt.BatchHints,
+		releaseValues:   releaseValues,
 		logger:          opt.Logger,
 		q:               cs.Field(),
 	}
@@ -192,9 +210,7 @@ func (s *solver) solveWithHint(h *constraint.HintMapping) error {
 	}
 
 	// tmp IO big int memory
-	nbInputs := len(h.Inputs)
 	nbOutputs := int(h.OutputRange.End - h.OutputRange.Start)
-	inputs := make([]*big.Int, nbInputs)
 	outputs := make([]*big.Int, nbOutputs)
 	for i := 0; i < nbOutputs; i++ {
 		outputs[i] = pool.BigInt.Get()
@@ -204,18 +220,7 @@ func (s *solver) solveWithHint(h *constraint.HintMapping) error {
 	q := pool.BigInt.Get()
 	q.Set(s.q)
 
-	for i := 0; i < nbInputs; i++ {
-		var v fr.Element
-		for _, term := range h.Inputs[i] {
-			if term.IsConstant() {
-				v.Add(&v, &s.Coefficients[term.CoeffID()])
-				continue
-			}
-			s.accumulateInto(term, &v)
-		}
-		inputs[i] = pool.BigInt.Get()
-		v.BigInt(inputs[i])
-	}
+	inputs := s.hintInputs(h)
 
 	err := f(q, inputs, outputs)
 
@@ -235,6 +240,99 @@ func (s *solver) solveWithHint(h *constraint.HintMapping) error {
 	return err
 }
 
+// hintInputs returns the values of the inputs of the hint, in big integers from the pool.
+func (s *solver) hintInputs(h *constraint.HintMapping) []*big.Int {
+	inputs := make([]*big.Int, len(h.Inputs))
+	for i := range inputs {
+		var v fr.Element
+		for _, term := range h.Inputs[i] {
+			if term.IsConstant() {
+				v.Add(&v, &s.Coefficients[term.CoeffID()])
+				continue
+			}
+			s.accumulateInto(term, &v)
+		}
+		inputs[i] = pool.BigInt.Get()
+		v.BigInt(inputs[i])
+	}
+	return inputs
+}
+
+// solveBatchHints evaluates the calls of the batch hints in the level (see
+// csolver.WithBatchHint), a batch per hint, and returns the other instructions of the level.
+// If a batch fails, its calls are evaluated one by one as the other hints, so that the error
+// is the one of the failing call, located as in processInstruction.
+func (s *solver) solveBatchHints(level []int) ([]int, error) {
+	type batch struct {
+		mappings        []constraint.HintMapping
+		cIDs            []uint32
+		inputs, outputs [][]*big.Int
+	}
+	batches := make(map[csolver.HintID]*batch)
+	var ids []csolver.HintID
+	others := make([]int, 0, len(level))
+	for _, i := range level {
+		inst := s.Instructions[i]
+		bc, ok := s.Blueprints[inst.BlueprintID].(constraint.BlueprintHint)
+		if !ok {
+			others = append(others, i)
+			continue
+		}
+		var h constraint.HintMapping
+		bc.DecompressHint(&h, s.GetCallData(inst))
+		if _, ok := s.batchHints[h.HintID]; !ok {
+			others = append(others, i)
+			continue
+		}
+		b, ok := batches[h.HintID]
+		if !ok {
+			b = new(batch)
+			batches[h.HintID] = b
+			ids = append(ids, h.HintID)
+		}
+		outputs := make([]*big.Int, h.OutputRange.End-h.OutputRange.Start)
+		for j := range outputs {
+			outputs[j] = pool.BigInt.Get()
+			outputs[j].SetUint64(0)
+		}
+		b.mappings = append(b.mappings, h)
+		b.cIDs = append(b.cIDs, inst.ConstraintOffset)
+		b.inputs = append(b.inputs, s.hintInputs(&h))
+		b.outputs = append(b.outputs, outputs)
+	}
+
+	q := pool.BigInt.Get()
+	defer pool.BigInt.Put(q)
+	for _, id := range ids {
+		b := batches[id]
+		q.Set(s.q)
+		err := s.batchHints[id](q, b.inputs, b.outputs)
+		for j := range b.mappings {
+			var v fr.Element
+			for k, o := range b.outputs[j] {
+				if err == nil {
+					v.SetBigInt(o)
+					s.set(int(b.mappings[j].OutputRange.Start)+k, v)
+				}
+				pool.BigInt.Put(o)
+			}
+			for _, in := range b.inputs[j] {
+				pool.BigInt.Put(in)
+			}
+		}
+		if err != nil {
+			for j := range b.mappings {
+				if err := s.solveWithHint(&b.mappings[j]); err != nil {
+					return nil, s.wrapErrWithDebugInfo(b.cIDs[j], err)
+				}
+			}
+			// the calls only fail together
+			return nil, s.wrapErrWithDebugInfo(b.cIDs[0], err)
+		}
+	}
+	return others, nil
+}
+
 func (s *solver) printLogs(logs []constraint.LogEntry) {
 	if s.logger.GetLevel() == zerolog.Disabled {
 		return
@@ -374,7 +472,10 @@ func (solver *solver) processInstruction(inst constraint.Instruction, scratch *s
 	// TODO @gbotrel may be worth it to move hint logic in blueprint "solve"
 	if bc, ok := blueprint.(constraint.BlueprintHint); ok {
 		bc.DecompressHint(&scratch.tHint, calldata)
-		return solver.solveWithHint(&scratch.tHint)
+		if err := solver.solveWithHint(&scratch.tHint); err != nil {
+			return solver.wrapErrWithDebugInfo(cID, err)
+		}
+		return nil
 	}
 
 	return nil
@@ -428,6 +529,12 @@ func (solver *solver) run() error {
 
 	// for each level, we push the tasks
 	for _, level := range solver.Levels {
+		if len(solver.batchHints) != 0 {
+			var err error
+			if level, err = solver.solveBatchHints(level); err != nil {
+				return err
+			}
+		}
 
 		// max CPU to use
 		maxCPU := float64(len(level)) / minWorkPerCPU
@@ -587,24 +694,31 @@ func (solver *solver) solveR1C(cID uint32, r *constraint.R1C) error {
 // UnsatisfiedConstraintError wraps an error with useful metadata on the unsatisfied constraint
 type UnsatisfiedConstraintError struct {
 	Err       error
-	CID       int     // constraint ID
-	DebugInfo *string // optional debug info
+	CID       int      // constraint ID
+	DebugInfo *string  // optional debug info
+	Source    []string // optional source location, see frontend.WithSourceMapping
 }
 
 func (r *UnsatisfiedConstraintError) Error() string {
 	if r.DebugInfo != nil {
 		return fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, *r.DebugInfo)
 	}
+	if len(r.Source) != 0 {
+		return fmt.Sprintf("constraint #%d is not satisfied: %s\n\tat %s", r.CID, r.Err.Error(), strings.Join(r.Source, "\n\tat "))
+	}
 	return fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, r.Err.Error())
 }
 
 func (solver *solver) wrapErrWithDebugInfo(cID uint32, err error) *UnsatisfiedConstraintError {
-	var debugInfo *string
-	if dID, ok := solver.MDebug[int(cID)]; ok {
-		debugInfo = new(string)
-		*debugInfo = solver.logValue(solver.DebugInfo[dID])
+	// debug info is attached to the constraint IDs at compile time
+	oID := solver.OriginalConstraintID(int(cID))
+
+	if dID, ok := solver.MDebug[oID]; ok {
+		debugInfo := solver.logValue(solver.DebugInfo[dID])
+		return &UnsatisfiedConstraintError{CID: oID, Err: err, DebugInfo: &debugInfo}
 	}
-	return &UnsatisfiedConstraintError{CID: int(cID), Err: err, DebugInfo: debugInfo}
+	// without debug info, the source mapping (if enabled) still locates the constraint
+	return &UnsatisfiedConstraintError{CID: oID, Err: err, Source: solver.ConstraintSource(oID)}
 }
 
 // temporary variables to avoid memallocs in hotloop
@@ -20,17 +20,46 @@ func NewSymbolTable() SymbolTable {
 	}
 }
 
+// internalPackages are the prefixes of the functions of gnark between the circuit code and the
+// collection of the stack, which CollectStack omits.
+var internalPackages = []string{
+	"github.com/consensys/gnark/constraint.",
+	"github.com/consensys/gnark/constraint/",
+	"github.com/consensys/gnark/debug.",
+	"github.com/consensys/gnark/frontend.",
+	"github.com/consensys/gnark/frontend/cs/",
+	"github.com/consensys/gnark/test.(*engine)",
+}
+
+// CollectStack returns the locations of the current stack, up to the Define method of the
+// circuit, without the internal frames of gnark. Without the debug build tag, only the 2
+// innermost frames are kept.
 func (st *SymbolTable) CollectStack() []int {
-	var r []int
 	if Debug {
-		r = make([]int, 0, 2)
+		return st.collectStack(-1)
+	}
+	return st.collectStack(2)
+}
+
+// CollectFullStack returns the locations of the current stack as CollectStack, without limit
+// on the number of frames.
+func (st *SymbolTable) CollectFullStack() []int {
+	return st.collectStack(-1)
+}
+
+// collectStack returns at most maxFrames locations of the stack of the caller of its caller,
+// all of them if maxFrames is negative.
+func (st *SymbolTable) collectStack(maxFrames int) []int {
+	var r []int
+	if maxFrames >= 0 {
+		r = make([]int, 0, maxFrames)
 	} else {
 		r = make([]int, 0, 5)
 	}
 	// derived from: https://golang.org/pkg/runtime/#example_Frames
 	// we stop when func name == Define as it is where the gnark circuit code should start
 
-	// Ask runtime.Callers for up to 10 pcs
+	// Ask runtime.Callers for up to 20 pcs
 	var pc [20]uintptr
 	n := runtime.Callers(4, pc[:])
 	if n == 0 {
@@ -39,7 +68,6 @@ func (st *SymbolTable) CollectStack() []int {
 		return r
 	}
 	frames := runtime.CallersFrames(pc[:n]) // pass only valid pcs to runtime.CallersFrames
-	cpt := 0
 	// Loop to get frames.
 	// A fixed number of pcs can expand to an indefinite number of Frames.
 	for {
@@ -48,28 +76,17 @@ func (st *SymbolTable) CollectStack() []int {
 		function := fe[len(fe)-1]
 
 		if !Debug {
-			if cpt == 2 {
-				// limit stack size to 2 when debug is not set.
+			if len(r) == maxFrames {
 				break
 			}
-			if strings.Contains(function, "runtime.gopanic") {
-				continue
+			if !isInternal(&frame) {
+				frame.File = filepath.Base(frame.File)
+				r = append(r, st.locationID(&frame))
 			}
-			if strings.Contains(function, "frontend.(*constraintSystem)") {
-				continue
-			}
-			if strings.Contains(frame.File, "test/engine.go") {
-				continue
-			}
-			if strings.Contains(frame.File, "gnark/frontend/cs") {
-				continue
-			}
-			frame.File = filepath.Base(frame.File)
+		} else {
+			r = append(r, st.locationID(&frame))
 		}
 
-		r = append(r, st.locationID(&frame))
-		cpt++
-
 		if !more {
 			break
 		}
@@ -83,6 +100,21 @@ func (st *SymbolTable) CollectStack() []int {
 	return r
 }
 
+// isInternal returns true if the frame is one of the runtime or of gnark to omit from the stacks.
+// The frames are matched on their function, as the paths of the files depend on where the
+// module is.
+func isInternal(frame *runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "runtime.gopanic") {
+		return true
+	}
+	for _, p := range internalPackages {
+		if strings.HasPrefix(frame.Function, p) {
+			return true
+		}
+	}
+	return false
+}
+
 func (st *SymbolTable) locationID(frame *runtime.Frame) int {
 	lID, ok := st.mLocations[uint64(frame.PC)]
 	if !ok {
@@ -0,0 +1,142 @@
+// Package reserve is a reference proof of solvency: an exchange commits to the balances it
+// owes its users in Merkle trees, and proves that the committed balances sum to a total
+// covered by its reserves.
+//
+// The accounts are proven by batches (see Circuit and Prover); each user then checks that its
+// account is in one of the published batches with an InclusionProof, natively or in zero
+// knowledge with InclusionCircuit.
+package reserve
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/accumulator/merkle"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// BalanceBits is the bit size of the balances. Sums of up to 2^(254-64) balances can't wrap
+// around the BN254 scalar field.
+const BalanceBits = 64
+
+// Circuit proves the liabilities of a batch of accounts: every balance is in [0, 2^64), the
+// accounts are the leaves of the Tree of root Root, and Total is the sum of their balances.
+type Circuit struct {
+	Accounts []AccountVariables
+	Root     frontend.Variable `gnark:",public"`
+	Total    frontend.Variable `gnark:",public"`
+}
+
+// AccountVariables is an Account in a circuit.
+type AccountVariables struct {
+	UserID, Balance frontend.Variable
+}
+
+// NewCircuit returns a circuit for batches of batchSize accounts, which must be a power of
+// two.
+func NewCircuit(batchSize int) *Circuit {
+	return &Circuit{Accounts: make([]AccountVariables, batchSize)}
+}
+
+// Define declares the circuit's constraints.
+func (circuit *Circuit) Define(api frontend.API) error {
+	if n := len(circuit.Accounts); n == 0 || bits.OnesCount(uint(n)) != 1 {
+		return fmt.Errorf("batch size %d is not a power of two", n)
+	}
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	rc := rangecheck.New(api)
+
+	nodes := make([]frontend.Variable, len(circuit.Accounts))
+	total := frontend.Variable(0)
+	for i, account := range circuit.Accounts {
+		rc.Check(account.Balance, BalanceBits)
+		total = api.Add(total, account.Balance)
+
+		h.Write(account.UserID, account.Balance)
+		data := h.Sum()
+		h.Reset()
+		h.Write(data)
+		nodes[i] = h.Sum()
+		h.Reset()
+	}
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			h.Write(nodes[2*i], nodes[2*i+1])
+			nodes[i] = h.Sum()
+			h.Reset()
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+
+	api.AssertIsEqual(nodes[0], circuit.Root)
+	api.AssertIsEqual(total, circuit.Total)
+	return nil
+}
+
+// Assign returns the assignment of the circuit for the accounts of the tree.
+func Assign(accounts []Account, tree *Tree) *Circuit {
+	assignment := NewCircuit(len(accounts))
+	for i := range accounts {
+		assignment.Accounts[i] = AccountVariables{UserID: toBigInt(accounts[i].UserID), Balance: accounts[i].Balance}
+	}
+	assignment.Root = toBigInt(tree.Root())
+	assignment.Total = tree.Total()
+	return assignment
+}
+
+// InclusionCircuit proves that the account of a user is in a tree of public root, without
+// revealing its position nor its balance.
+type InclusionCircuit struct {
+	Root    frontend.Variable `gnark:",public"`
+	UserID  frontend.Variable `gnark:",public"`
+	Balance frontend.Variable
+	Index   frontend.Variable
+	Proof   merkle.MerkleProof
+}
+
+// NewInclusionCircuit returns an inclusion circuit for trees of the given depth.
+func NewInclusionCircuit(depth int) *InclusionCircuit {
+	return &InclusionCircuit{Proof: merkle.MerkleProof{Path: make([]frontend.Variable, depth+1)}}
+}
+
+// Define declares the circuit's constraints.
+func (circuit *InclusionCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.UserID, circuit.Balance)
+	api.AssertIsEqual(circuit.Proof.Path[0], h.Sum())
+	h.Reset()
+
+	api.AssertIsEqual(circuit.Proof.RootHash, circuit.Root)
+	circuit.Proof.VerifyProof(api, &h, circuit.Index)
+	return nil
+}
+
+// AssignInclusion returns the assignment of InclusionCircuit for the proof of the account.
+func AssignInclusion(root fr.Element, account Account, proof InclusionProof) *InclusionCircuit {
+	assignment := NewInclusionCircuit(len(proof.Siblings))
+	assignment.Root = toBigInt(root)
+	assignment.UserID = toBigInt(account.UserID)
+	assignment.Balance = account.Balance
+	assignment.Index = proof.Index
+	assignment.Proof.RootHash = assignment.Root
+	assignment.Proof.Path[0] = toBigInt(accountData(&account))
+	for i := range proof.Siblings {
+		assignment.Proof.Path[i+1] = toBigInt(proof.Siblings[i])
+	}
+	return assignment
+}
+
+func toBigInt(e fr.Element) *big.Int {
+	return e.BigInt(new(big.Int))
+}
@@ -0,0 +1,112 @@
+package reserve
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Prover proves the liabilities of an exchange, by batches of accounts of a fixed size.
+//
+// Proofs are generated with groth16.Prove: building with the icicle tag runs them on the GPU,
+// and the proving key is uploaded once for all the batches.
+type Prover struct {
+	batchSize int
+	ccs       constraint.ConstraintSystem
+	pk        groth16.ProvingKey
+	vk        groth16.VerifyingKey
+}
+
+// BatchProof proves that the accounts of the tree of root Root owe Total.
+type BatchProof struct {
+	Root  fr.Element
+	Total *big.Int
+	Proof groth16.Proof
+}
+
+// Report is the published proof of the liabilities of the exchange. A user checks its
+// inclusion in one of the batches with the InclusionProof of its account.
+type Report struct {
+	Batches []BatchProof
+	Total   *big.Int
+}
+
+// NewProver compiles the circuit for batches of batchSize accounts and runs the groth16
+// setup. In production, the keys come from a multi-party setup instead.
+func NewProver(batchSize int, opts ...frontend.CompileOption) (*Prover, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewCircuit(batchSize), opts...)
+	if err != nil {
+		return nil, err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{batchSize: batchSize, ccs: ccs, pk: pk, vk: vk}, nil
+}
+
+// VerifyingKey returns the key to verify the reports of the prover with VerifyReport.
+func (p *Prover) VerifyingKey() groth16.VerifyingKey {
+	return p.vk
+}
+
+// Prove splits the accounts in batches, padded with empty accounts, and proves each batch.
+// It returns the report to publish and the tree of each batch, from which the inclusion
+// proofs of the users are extracted.
+func (p *Prover) Prove(accounts []Account, opts ...backend.ProverOption) (*Report, []*Tree, error) {
+	report := &Report{Total: new(big.Int)}
+	var trees []*Tree
+	for start := 0; start < len(accounts); start += p.batchSize {
+		batch := make([]Account, p.batchSize)
+		copy(batch, accounts[start:])
+
+		tree, err := NewTree(batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := frontend.NewWitness(Assign(batch, tree), ecc.BN254.ScalarField())
+		if err != nil {
+			return nil, nil, err
+		}
+		proof, err := groth16.Prove(p.ccs, p.pk, w, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("batch %d: %w", len(trees), err)
+		}
+
+		report.Batches = append(report.Batches, BatchProof{Root: tree.Root(), Total: tree.Total(), Proof: proof})
+		report.Total.Add(report.Total, tree.Total())
+		trees = append(trees, tree)
+	}
+	return report, trees, nil
+}
+
+// VerifyReport verifies the proofs of the report, and that the reserves of the exchange cover
+// its liabilities.
+func VerifyReport(vk groth16.VerifyingKey, report *Report, reserves *big.Int) error {
+	total := new(big.Int)
+	for i, batch := range report.Batches {
+		publicWitness, err := frontend.NewWitness(&Circuit{Root: toBigInt(batch.Root), Total: batch.Total}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+		if err != nil {
+			return err
+		}
+		if err := groth16.Verify(batch.Proof, vk, publicWitness); err != nil {
+			return fmt.Errorf("batch %d: %w", i, err)
+		}
+		total.Add(total, batch.Total)
+	}
+	if total.Cmp(report.Total) != 0 {
+		return errors.New("report total doesn't match the batches")
+	}
+	if total.Cmp(reserves) > 0 {
+		return fmt.Errorf("liabilities %s exceed reserves %s", total, reserves)
+	}
+	return nil
+}
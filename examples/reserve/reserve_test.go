@@ -0,0 +1,87 @@
+package reserve
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+func randomAccounts(n int) []Account {
+	rnd := rand.New(rand.NewSource(42)) //#nosec G404 -- test only
+	accounts := make([]Account, n)
+	for i := range accounts {
+		accounts[i].UserID.SetUint64(rnd.Uint64())
+		accounts[i].Balance = rnd.Uint64()
+	}
+	return accounts
+}
+
+func TestCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	accounts := randomAccounts(4)
+	tree, err := NewTree(accounts)
+	assert.NoError(err)
+
+	// the range checks use a lookup argument, which plonkFRI lacks
+	opts := []test.TestingOption{test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16, backend.PLONK)}
+	assert.ProverSucceeded(NewCircuit(4), Assign(accounts, tree), opts...)
+
+	wrongTotal := Assign(accounts, tree)
+	wrongTotal.Total = new(big.Int).Add(tree.Total(), big.NewInt(1))
+	assert.ProverFailed(NewCircuit(4), wrongTotal, opts...)
+
+	hidden := Assign(accounts, tree)
+	hidden.Accounts[2].Balance = 0
+	assert.ProverFailed(NewCircuit(4), hidden, opts...)
+}
+
+func TestInclusion(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	accounts := randomAccounts(8)
+	tree, err := NewTree(accounts)
+	assert.NoError(err)
+
+	proof, err := tree.Prove(5)
+	assert.NoError(err)
+	assert.True(proof.Verify(tree.Root(), accounts[5]))
+	assert.False(proof.Verify(tree.Root(), accounts[4]))
+
+	assert.NoError(test.IsSolved(NewInclusionCircuit(3), AssignInclusion(tree.Root(), accounts[5], proof), ecc.BN254.ScalarField()))
+
+	var otherRoot fr.Element
+	otherRoot.SetUint64(1)
+	assert.Error(test.IsSolved(NewInclusionCircuit(3), AssignInclusion(otherRoot, accounts[5], proof), ecc.BN254.ScalarField()))
+}
+
+func TestProver(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	prover, err := NewProver(4)
+	assert.NoError(err)
+
+	accounts := randomAccounts(6)
+	report, trees, err := prover.Prove(accounts)
+	assert.NoError(err)
+	assert.Equal(2, len(report.Batches))
+
+	expected := new(big.Int)
+	for i := range accounts {
+		expected.Add(expected, new(big.Int).SetUint64(accounts[i].Balance))
+	}
+	assert.Equal(0, expected.Cmp(report.Total))
+
+	// the last user finds its account in the second batch
+	proof, err := trees[1].Prove(1)
+	assert.NoError(err)
+	assert.True(proof.Verify(report.Batches[1].Root, accounts[5]))
+
+	assert.NoError(VerifyReport(prover.VerifyingKey(), report, expected))
+	assert.Error(VerifyReport(prover.VerifyingKey(), report, new(big.Int).Sub(expected, big.NewInt(1))))
+}
@@ -0,0 +1,127 @@
+package reserve
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+// Account is an entry of the liabilities of the exchange.
+type Account struct {
+	// UserID identifies the user, typically a salted hash of its identifier so that the tree
+	// doesn't reveal it.
+	UserID fr.Element
+	// Balance is the amount the exchange owes to the user.
+	Balance uint64
+}
+
+// Tree is a Merkle tree over accounts, as proven by Circuit.
+//
+// The leaf of an account is H(H(UserID, Balance)) and the nodes are H(left, right), where H
+// is MiMC over BN254; this is the layout of std/accumulator/merkle, so that inclusion proofs
+// can also be verified in a circuit (see InclusionCircuit).
+type Tree struct {
+	// levels[0] are the leaves, levels[len(levels)-1] is the root.
+	levels [][]fr.Element
+	total  big.Int
+}
+
+// InclusionProof proves that an account is in a Tree.
+type InclusionProof struct {
+	// Index is the position of the account in the tree.
+	Index uint64
+	// Siblings are the sibling nodes from the leaf to the root.
+	Siblings []fr.Element
+}
+
+// NewTree builds the tree of the accounts, whose number must be a power of two.
+func NewTree(accounts []Account) (*Tree, error) {
+	if len(accounts) == 0 || bits.OnesCount(uint(len(accounts))) != 1 {
+		return nil, fmt.Errorf("number of accounts %d is not a power of two", len(accounts))
+	}
+
+	t := &Tree{}
+	leaves := make([]fr.Element, len(accounts))
+	var balance big.Int
+	for i := range accounts {
+		data := accountData(&accounts[i])
+		leaves[i] = hash(data)
+		t.total.Add(&t.total, balance.SetUint64(accounts[i].Balance))
+	}
+	t.levels = append(t.levels, leaves)
+	for level := leaves; len(level) > 1; {
+		level = hashLevel(level)
+		t.levels = append(t.levels, level)
+	}
+	return t, nil
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() fr.Element {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Total returns the sum of the balances of the accounts.
+func (t *Tree) Total() *big.Int {
+	return new(big.Int).Set(&t.total)
+}
+
+// Prove returns the inclusion proof of the i-th account.
+func (t *Tree) Prove(i uint64) (InclusionProof, error) {
+	if i >= uint64(len(t.levels[0])) {
+		return InclusionProof{}, errors.New("account index out of range")
+	}
+	proof := InclusionProof{Index: i, Siblings: make([]fr.Element, 0, len(t.levels)-1)}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		proof.Siblings = append(proof.Siblings, level[i^1])
+		i >>= 1
+	}
+	return proof, nil
+}
+
+// Verify returns true if the proof proves that account is in the tree of the given root.
+func (proof *InclusionProof) Verify(root fr.Element, account Account) bool {
+	node := hash(accountData(&account))
+	index := proof.Index
+	for i := range proof.Siblings {
+		if index&1 == 0 {
+			node = hash(node, proof.Siblings[i])
+		} else {
+			node = hash(proof.Siblings[i], node)
+		}
+		index >>= 1
+	}
+	return index == 0 && node.Equal(&root)
+}
+
+// accountData returns H(UserID, Balance), the data of the leaf of the account.
+func accountData(account *Account) fr.Element {
+	var balance fr.Element
+	balance.SetUint64(account.Balance)
+	return hash(account.UserID, balance)
+}
+
+// hashLevel returns the parent level of the nodes.
+func hashLevel(nodes []fr.Element) []fr.Element {
+	res := make([]fr.Element, len(nodes)/2)
+	for i := range res {
+		res[i] = hash(nodes[2*i], nodes[2*i+1])
+	}
+	return res
+}
+
+// hash returns the MiMC hash of the elements, as std/hash/mimc computes it.
+func hash(elements ...fr.Element) fr.Element {
+	h := mimc.NewMiMC()
+	for i := range elements {
+		b := elements[i].Bytes()
+		_, _ = h.Write(b[:])
+	}
+	var res fr.Element
+	res.SetBytes(h.Sum(nil))
+	return res
+}
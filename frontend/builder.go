@@ -38,6 +38,11 @@ type Compiler interface {
 	// If nbOutputs is specified, it must be >= 1 and <= f.NbOutputs
 	NewHint(f solver.Hint, nbOutputs int, inputs ...Variable) ([]Variable, error)
 
+	// NewHintForId is NewHint for the hint function with the ID, registered with
+	// solver.RegisterNamedHint or provided to the solver with it. It calls the hints
+	// registered under several names, which the function doesn't tell apart.
+	NewHintForId(id solver.HintID, nbOutputs int, inputs ...Variable) ([]Variable, error)
+
 	// ConstantValue returns the big.Int value of v and true if op is a success.
 	// nil and false if failure. This API returns a boolean to allow for future refactoring
 	// replacing *big.Int with fr.Element
@@ -74,6 +79,10 @@ type Builder interface {
 
 // Committer allows to commit to the variables and returns the commitment. The
 // commitment can be used as a challenge using Fiat-Shamir heuristic.
+//
+// The R1CS builder supports several commitments per circuit, provided they don't commit to
+// the same secret variables nor to each other; Groth16 on BN254 is the only backend proving
+// such circuits. The PLONK builder supports a single commitment.
 type Committer interface {
 	// Commit commits to the variables and returns the commitment.
 	Commit(toCommit ...Variable) (commitment Variable, err error)
@@ -87,3 +96,12 @@ type Rangechecker interface {
 	// Check checks that the given variable v has bit-length bits.
 	Check(v Variable, bits int)
 }
+
+// Multiplexer allows to select a variable from a list with an index, with a
+// builder specific implementation. Not all compilers implement this
+// interface. Users should instead use
+// [github.com/consensys/gnark/std/selector.Mux] which uses it when available.
+type Multiplexer interface {
+	// Mux returns inputs[sel]. sel must be in [0, len(inputs)).
+	Mux(sel Variable, inputs ...Variable) Variable
+}
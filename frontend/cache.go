@@ -0,0 +1,346 @@
+package frontend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/debug"
+	"github.com/consensys/gnark/logger"
+)
+
+// CompileCache holds compiled constraint systems, keyed by a hash of the compilation inputs:
+// the field, the builder, the compile options and the circuit value (its type and the value of
+// all its fields, including the ones ignored by the schema). See [WithCache].
+//
+// The code of Define is not part of the key. Within a process the code doesn't change, but
+// entries stored on disk are also keyed by a hash of the executable, so that rebuilding the
+// program invalidates them.
+//
+// A CompileCache is safe for concurrent use. Entries are never evicted.
+type CompileCache struct {
+	dir string
+
+	lock    sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	t    reflect.Type // concrete type of the constraint system
+	data []byte       // serialized constraint system
+}
+
+// NewCompileCache returns a new CompileCache. If dir is not empty, compiled constraint systems
+// are also stored in dir and survive the process; dir is created if it doesn't exist.
+func NewCompileCache(dir string) (*CompileCache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return &CompileCache{
+		dir:     dir,
+		entries: make(map[cacheKey]cacheEntry),
+	}, nil
+}
+
+// WithCache is a compile option which looks up the compiled constraint system in cache before
+// compiling the circuit, and stores it in cache after. On a cache hit, circuit.Define is not
+// called and the circuit value is left untouched: unlike after a compilation, its Variable
+// fields are not assigned the variables of the builder, and the values Define would store in
+// the circuit are not set.
+//
+// To compile a gadget once and instantiate it many times within circuits, see [Template].
+//
+// Circuits holding values which can't be hashed (functions, channels) are always compiled.
+func WithCache(cache *CompileCache) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.Cache = cache
+		return nil
+	}
+}
+
+// key returns the cache key of the compilation, or false if the circuit can't be hashed.
+func (c *CompileCache) key(field *big.Int, newBuilder NewBuilder, circuit Circuit, opt CompileConfig) (cacheKey, bool) {
+	h := sha256.New()
+	hv := valueHasher{h: h, seen: make(map[uintptr]int)}
+
+	_, _ = h.Write(field.Bytes())
+	_, _ = io.WriteString(h, runtime.FuncForPC(reflect.ValueOf(newBuilder).Pointer()).Name())
+	if debug.Debug {
+		_, _ = io.WriteString(h, "debug")
+	}
+
+	// options which don't change the compiled system
+	opt.Cache = nil
+	opt.Capacity = 0
+	opt.NbWorkers = 0
+	if !hv.write(reflect.ValueOf(opt)) || !hv.write(reflect.ValueOf(circuit)) {
+		return cacheKey{}, false
+	}
+
+	var key cacheKey
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+// get returns the cached constraint system, or nil. newEmpty returns an empty constraint system
+// of the type compiled by the builder, to decode entries read from disk.
+func (c *CompileCache) get(key cacheKey, newEmpty func() (constraint.ConstraintSystem, error)) constraint.ConstraintSystem {
+	log := logger.Logger()
+
+	c.lock.Lock()
+	entry, inMemory := c.entries[key]
+	c.lock.Unlock()
+
+	if !inMemory {
+		path, onDisk := c.path(key)
+		if !onDisk {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Warn().Err(err).Str("path", path).Msg("reading compile cache")
+			}
+			return nil
+		}
+		cs, err := newEmpty()
+		if err != nil {
+			return nil
+		}
+		entry = cacheEntry{t: reflect.TypeOf(cs), data: data}
+	}
+
+	cs := reflect.New(entry.t.Elem()).Interface().(constraint.ConstraintSystem)
+	if _, err := cs.ReadFrom(bytes.NewReader(entry.data)); err != nil {
+		log.Warn().Err(err).Msg("decoding cached constraint system")
+		return nil
+	}
+
+	if !inMemory {
+		c.lock.Lock()
+		c.entries[key] = entry
+		c.lock.Unlock()
+	}
+	return cs
+}
+
+// put stores the constraint system in the cache. Failing to write it on disk is not an error,
+// the constraint system is then only cached in memory.
+func (c *CompileCache) put(key cacheKey, cs constraint.ConstraintSystem) error {
+	var buf bytes.Buffer
+	if _, err := cs.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	c.entries[key] = cacheEntry{t: reflect.TypeOf(cs), data: buf.Bytes()}
+	c.lock.Unlock()
+
+	if path, ok := c.path(key); ok {
+		if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+			log := logger.Logger()
+			log.Warn().Err(err).Str("path", path).Msg("writing compile cache")
+		}
+	}
+	return nil
+}
+
+// path returns the file of the entry on disk, or false if the cache is in memory only or the
+// executable can't be hashed.
+func (c *CompileCache) path(key cacheKey) (string, bool) {
+	if c.dir == "" {
+		return "", false
+	}
+	exeOnce.Do(func() {
+		exeDigest, exeErr = executableDigest()
+	})
+	if exeErr != nil {
+		return "", false
+	}
+	h := sha256.New()
+	_, _ = h.Write(exeDigest[:])
+	_, _ = h.Write(key[:])
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil))+".cs"), true
+}
+
+var (
+	exeOnce   sync.Once
+	exeDigest [sha256.Size]byte
+	exeErr    error
+)
+
+// executableDigest returns the SHA-256 of the running executable.
+func executableDigest() (digest [sha256.Size]byte, err error) {
+	path, err := os.Executable()
+	if err != nil {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return
+	}
+	copy(digest[:], h.Sum(nil))
+	return
+}
+
+// writeFileAtomic writes to a temporary file renamed to path once complete, so that concurrent
+// compilations never read a partially written entry.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// valueHasher writes a deterministic encoding of Go values in h.
+type valueHasher struct {
+	h    hash.Hash
+	seen map[uintptr]int // pointers already written, to handle cycles
+	buf  [8]byte
+}
+
+func (hv *valueHasher) writeUint64(v uint64) {
+	binary.LittleEndian.PutUint64(hv.buf[:], v)
+	_, _ = hv.h.Write(hv.buf[:])
+}
+
+func (hv *valueHasher) writeString(s string) {
+	hv.writeUint64(uint64(len(s)))
+	_, _ = io.WriteString(hv.h, s)
+}
+
+func (hv *valueHasher) writeType(t reflect.Type) {
+	hv.writeString(t.PkgPath() + "." + t.String())
+}
+
+// write writes the value v, and returns false if it holds a value which can't be hashed.
+func (hv *valueHasher) write(v reflect.Value) bool {
+	if !v.IsValid() {
+		hv.writeString("nil")
+		return true
+	}
+	hv.writeType(v.Type())
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			hv.writeUint64(1)
+		} else {
+			hv.writeUint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hv.writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		hv.writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		hv.writeUint64(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		hv.writeUint64(math.Float64bits(real(v.Complex())))
+		hv.writeUint64(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		hv.writeString(v.String())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hv.write(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			hv.writeString("nil")
+			return true
+		}
+		hv.writeUint64(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if !hv.write(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hv.write(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			hv.writeString("nil")
+			return true
+		}
+		if id, ok := hv.seen[v.Pointer()]; ok {
+			hv.writeString("ref")
+			hv.writeUint64(uint64(id))
+			return true
+		}
+		hv.seen[v.Pointer()] = len(hv.seen)
+		return hv.write(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			hv.writeString("nil")
+			return true
+		}
+		return hv.write(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			hv.writeString("nil")
+			return true
+		}
+		// entries are written sorted by the encoding of their key
+		type entry struct {
+			key   []byte
+			value reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			kh := valueHasher{h: sha256.New(), seen: make(map[uintptr]int)}
+			if !kh.write(iter.Key()) {
+				return false
+			}
+			entries = append(entries, entry{key: kh.h.Sum(nil), value: iter.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+		hv.writeUint64(uint64(len(entries)))
+		for _, e := range entries {
+			_, _ = hv.h.Write(e.key)
+			if !hv.write(e.value) {
+				return false
+			}
+		}
+	default:
+		// functions, channels and unsafe pointers
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package frontend_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type cachedCircuit struct {
+	X, Y   frontend.Variable
+	nbMuls int
+}
+
+// nbDefineCalls counts the calls to cachedCircuit.Define.
+var nbDefineCalls int
+
+func (c *cachedCircuit) Define(api frontend.API) error {
+	nbDefineCalls++
+	res := c.X
+	for i := 0; i < c.nbMuls; i++ {
+		res = api.Mul(res, c.X)
+	}
+	api.AssertIsEqual(res, c.Y)
+	return nil
+}
+
+func TestCompileCache(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	cache, err := frontend.NewCompileCache(dir)
+	assert.NoError(err)
+
+	compile := func(newBuilder frontend.NewBuilder, nbMuls int) constraint.ConstraintSystem {
+		t.Helper()
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), newBuilder, &cachedCircuit{nbMuls: nbMuls}, frontend.WithCache(cache))
+		assert.NoError(err)
+		return ccs
+	}
+	serialize := func(ccs constraint.ConstraintSystem) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		_, err := ccs.WriteTo(&buf)
+		assert.NoError(err)
+		return buf.Bytes()
+	}
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		nbDefineCalls = 0
+		expected := compile(newBuilder, 10)
+		assert.Equal(1, nbDefineCalls)
+
+		// same circuit: Define is not called again and the system is a copy
+		cached := compile(newBuilder, 10)
+		assert.Equal(1, nbDefineCalls)
+		assert.IsType(expected, cached)
+		assert.Equal(serialize(expected), serialize(cached))
+
+		// the value of fields ignored by the schema is part of the key
+		other := compile(newBuilder, 11)
+		assert.Equal(2, nbDefineCalls)
+		assert.Equal(expected.GetNbConstraints()+1, other.GetNbConstraints())
+	}
+
+	// entries are read back from disk by a new cache
+	cache, err = frontend.NewCompileCache(dir)
+	assert.NoError(err)
+	nbDefineCalls = 0
+	_ = compile(r1cs.NewBuilder, 10)
+	assert.Equal(0, nbDefineCalls)
+}
@@ -0,0 +1,161 @@
+package frontend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/internal/kvstore"
+)
+
+// Capability is a feature of a proving backend, which some gadgets require.
+type Capability uint8
+
+const (
+	// CapabilityCommitment is the support of a commitment to variables (see Committer), from
+	// which the backend derives a challenge by hashing to the scalar field.
+	CapabilityCommitment Capability = iota + 1
+
+	// CapabilityMultiCommitment is the support of several commitments in a circuit.
+	CapabilityMultiCommitment
+
+	// CapabilityLookup is the support of the log-derivative lookup arguments (lookup tables
+	// and range checks), which need a commitment for their challenge.
+	CapabilityLookup
+)
+
+// String returns the name of the capability.
+func (c Capability) String() string {
+	switch c {
+	case CapabilityCommitment:
+		return "commitments"
+	case CapabilityMultiCommitment:
+		return "multiple commitments"
+	case CapabilityLookup:
+		return "lookup arguments"
+	default:
+		return "unknown capability"
+	}
+}
+
+// WithBackend is a compile option which sets the backend the circuit is compiled for. The
+// compilation then fails if a gadget requires a capability the backend lacks on the curve of
+// the field, and gadgets may pick an implementation the backend supports (see Supports).
+func WithBackend(id backend.ID) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.Backend = id
+		return nil
+	}
+}
+
+// Supports returns true if the backend the circuit is compiled for (see WithBackend) has the
+// capability, or if the backend is not known.
+func Supports(api API, c Capability) bool {
+	s := getCapabilities(api)
+	return s == nil || backendSupports(s.backend, s.curve, c)
+}
+
+// RequireCapability records that the gadget requires the capability of the backend. If the
+// circuit is compiled for a backend lacking it (see WithBackend), the compilation fails with an
+// error naming the gadget.
+func RequireCapability(api API, gadget string, c Capability) {
+	if s := getCapabilities(api); s != nil {
+		s.requirements = append(s.requirements, requirement{gadget, c})
+	}
+}
+
+type requirement struct {
+	gadget     string
+	capability Capability
+}
+
+// capabilities is the target backend of a compilation and the requirements of its gadgets.
+type capabilities struct {
+	backend      backend.ID
+	curve        ecc.ID
+	requirements []requirement
+}
+
+type capabilitiesKey struct{}
+
+func getCapabilities(api API) *capabilities {
+	kv, ok := api.Compiler().(kvstore.Store)
+	if !ok {
+		return nil
+	}
+	s, _ := kv.GetKeyValue(capabilitiesKey{}).(*capabilities)
+	return s
+}
+
+// feature returns the feature of the constraint systems a gadget requiring the capability needs.
+func (c Capability) feature() constraint.Feature {
+	switch c {
+	case CapabilityCommitment:
+		return constraint.FeatureCommitments
+	case CapabilityMultiCommitment:
+		return constraint.FeatureMultiCommitments
+	case CapabilityLookup:
+		return constraint.FeatureLookup
+	default:
+		return constraint.Feature(c.String())
+	}
+}
+
+// setTarget records the backend the builder compiles for, UNKNOWN if not set.
+func setTarget(builder Builder, id backend.ID, curve ecc.ID) {
+	if kv, ok := builder.(kvstore.Store); ok {
+		kv.SetKeyValue(capabilitiesKey{}, &capabilities{backend: id, curve: curve})
+	}
+}
+
+// checkCapabilities returns an error listing the gadgets whose requirements the target backend
+// doesn't meet.
+func checkCapabilities(builder Builder) error {
+	s := getCapabilities(builder)
+	if s == nil {
+		return nil
+	}
+	var missing []string
+	seen := make(map[requirement]bool)
+	for _, r := range s.requirements {
+		if seen[r] || backendSupports(s.backend, s.curve, r.capability) {
+			continue
+		}
+		seen[r] = true
+		missing = append(missing, fmt.Sprintf("%s requires %s", r.gadget, r.capability))
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s on %s doesn't support the circuit: %s", s.backend, s.curve, strings.Join(missing, "; "))
+}
+
+// requiredFeatures returns the features of the constraint system the gadgets required.
+func requiredFeatures(builder Builder) []constraint.Feature {
+	s := getCapabilities(builder)
+	if s == nil {
+		return nil
+	}
+	res := make([]constraint.Feature, 0, len(s.requirements))
+	for _, r := range s.requirements {
+		res = append(res, r.capability.feature())
+	}
+	return res
+}
+
+// backendSupports returns true if the backend has the capability on the curve.
+func backendSupports(id backend.ID, curve ecc.ID, c Capability) bool {
+	switch id {
+	case backend.GROTH16:
+		// only the BN254 prover handles several commitment keys
+		return c != CapabilityMultiCommitment || curve == ecc.BN254
+	case backend.PLONK:
+		return c != CapabilityMultiCommitment
+	case backend.PLONKFRI:
+		return false
+	default:
+		return true
+	}
+}
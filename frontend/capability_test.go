@@ -0,0 +1,79 @@
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/stretchr/testify/require"
+)
+
+type lookupCircuit struct {
+	Index, Value frontend.Variable
+}
+
+func (c *lookupCircuit) Define(api frontend.API) error {
+	t := logderivlookup.New(api)
+	for i := 0; i < 4; i++ {
+		t.Insert(i * i)
+	}
+	api.AssertIsEqual(t.Lookup(c.Index)[0], c.Value)
+	return nil
+}
+
+type twoCommitmentsCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *twoCommitmentsCircuit) Define(api frontend.API) error {
+	committer := api.Compiler().(frontend.Committer)
+	cX, err := committer.Commit(c.X)
+	if err != nil {
+		return err
+	}
+	cY, err := committer.Commit(c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cX, cY)
+	return nil
+}
+
+type rangeCheckCircuit struct {
+	X frontend.Variable
+}
+
+func (c *rangeCheckCircuit) Define(api frontend.API) error {
+	rangecheck.New(api).Check(c.X, 8)
+	return nil
+}
+
+func TestCompileCapabilities(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &lookupCircuit{}, frontend.WithBackend(backend.PLONK))
+	assert.NoError(err)
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &lookupCircuit{}, frontend.WithBackend(backend.PLONKFRI))
+	assert.ErrorContains(err, "logderivlookup.Table requires lookup arguments")
+
+	// only groth16 on BN254 supports several commitments
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{}, frontend.WithBackend(backend.GROTH16))
+	assert.NoError(err)
+	_, err = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{}, frontend.WithBackend(backend.GROTH16))
+	assert.ErrorContains(err, "requires multiple commitments")
+	_, err = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{})
+	assert.NoError(err, "without target backend, nothing is checked")
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &twoCommitmentsCircuit{}, frontend.WithBackend(backend.PLONK))
+	assert.ErrorContains(err, "requires multiple commitments")
+
+	// the range checks fall back to a binary decomposition without lookup arguments
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &rangeCheckCircuit{}, frontend.WithBackend(backend.PLONKFRI))
+	assert.NoError(err)
+	assert.False(ccs.(*cs_bn254.SparseR1CS).CommitmentInfo.Is())
+}
@@ -6,11 +6,14 @@ import (
 	"math/big"
 	"reflect"
 
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/debug"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/internal/circuitdefer"
+	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/profile"
 )
 
 // Compile will generate a ConstraintSystem from the given circuit
@@ -45,6 +48,33 @@ func Compile(field *big.Int, newBuilder NewBuilder, circuit Circuit, opts ...Com
 		}
 	}
 
+	// the profile needs the constraints to be added again, the cache is bypassed
+	if opt.Profile != nil {
+		p := profile.Start(opt.Profile...)
+		defer p.Stop()
+	}
+
+	// look up the compiled circuit in the cache, before parseCircuit sets its inputs
+	var key cacheKey
+	cacheable := false
+	if opt.Cache != nil && opt.Profile == nil {
+		if key, cacheable = opt.Cache.key(field, newBuilder, circuit, opt); cacheable {
+			newEmpty := func() (constraint.ConstraintSystem, error) {
+				builder, err := newBuilder(field, opt)
+				if err != nil {
+					return nil, err
+				}
+				return builder.Compile()
+			}
+			if cs := opt.Cache.get(key, newEmpty); cs != nil {
+				log.Info().Msg("compiled circuit found in cache")
+				return cs, nil
+			}
+		} else {
+			log.Warn().Msg("circuit can't be hashed, compile cache disabled")
+		}
+	}
+
 	// instantiate new builder
 	builder, err := newBuilder(field, opt)
 	if err != nil {
@@ -52,16 +82,45 @@ func Compile(field *big.Int, newBuilder NewBuilder, circuit Circuit, opts ...Com
 		return nil, fmt.Errorf("new compiler: %w", err)
 	}
 
+	setTarget(builder, opt.Backend, utils.FieldToCurve(field))
+
 	// parse the circuit builds a schema of the circuit
 	// and call circuit.Define() method to initialize a list of constraints in the compiler
 	if err = parseCircuit(builder, circuit); err != nil {
+		// a gadget may fail because of a missing capability, which is the more actionable error
+		if cErr := checkCapabilities(builder); cErr != nil {
+			err = fmt.Errorf("%v: %w", cErr, err)
+		}
 		log.Err(err).Msg("parsing circuit")
 		return nil, fmt.Errorf("parse circuit: %w", err)
 
 	}
+	if err = checkCapabilities(builder); err != nil {
+		log.Err(err).Msg("checking backend capabilities")
+		return nil, err
+	}
 
 	// compile the circuit into its final form
-	return builder.Compile()
+	cs, err := builder.Compile()
+	if err != nil {
+		return nil, err
+	}
+	cs.RequireFeatures(requiredFeatures(builder)...)
+
+	if opt.ReorderConstraints {
+		if err = cs.ReorderInstructions(); err != nil {
+			log.Err(err).Msg("reordering constraints")
+			return nil, fmt.Errorf("reorder constraints: %w", err)
+		}
+	}
+
+	if cacheable {
+		if err = opt.Cache.put(key, cs); err != nil {
+			log.Warn().Err(err).Msg("caching compiled circuit")
+		}
+	}
+
+	return cs, nil
 }
 
 func parseCircuit(builder Builder, circuit Circuit) (err error) {
@@ -154,6 +213,13 @@ type CompileConfig struct {
 	Capacity                  int
 	IgnoreUnconstrainedInputs bool
 	CompressThreshold         int
+	ReorderConstraints        bool
+	NbWorkers                 int
+	Cache                     *CompileCache
+	Backend                   backend.ID
+	SourceMapping             bool
+	Profile                   []profile.Option
+	ComparisonBits            int
 }
 
 // WithCapacity is a compile option that specifies the estimated capacity needed
@@ -203,6 +269,92 @@ func WithCompressThreshold(threshold int) CompileOption {
 	}
 }
 
+// WithConstraintReordering is a compile option which lays out the compiled constraint
+// system in blocks ordered for solving: constraints are grouped by dependency level and
+// sorted by the wires they reference, which improves the solver throughput on large
+// circuits. See [constraint.System.ReorderInstructions].
+//
+// Constraint IDs of the compiled system differ from the order in which constraints were
+// defined; the original IDs are still used in debug info and solver errors.
+func WithConstraintReordering() CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.ReorderConstraints = true
+		return nil
+	}
+}
+
+// WithSourceMapping is a compile option which records the source location of all the
+// constraints, and not only of the assertions compiled with the debug tag, so that the solver
+// errors locate the unsatisfied constraint in the circuit code. See
+// [constraint.System.ConstraintSource].
+//
+// The stacks are deduplicated and shared by the consecutive constraints added by the same
+// code, but collecting them slows down the compilation, and the source map grows with the
+// number of distinct locations; it is meant for debugging large circuits.
+func WithSourceMapping() CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.SourceMapping = true
+		return nil
+	}
+}
+
+// WithProfile is a compile option which profiles the constraints added by the compilation,
+// attributing them to the call stacks of the circuit, as a session of the profile package with
+// the options; for instance
+//
+//	frontend.WithProfile(profile.WithPath("circuit.pprof"), profile.WithFlameGraph("circuit.folded"))
+//
+// writes a pprof profile (go tool pprof -top circuit.pprof) and the folded stacks of a flame
+// graph. The circuit is compiled even if it is in the compile cache.
+func WithProfile(options ...profile.Option) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.Profile = append([]profile.Option{}, options...)
+		return nil
+	}
+}
+
+// WithNbWorkers is a compile option which sets the number of goroutines the builder may use
+// to reduce large linear expressions (e.g. api.Add with thousands of operands, or long
+// accumulation chains), and to look up their coefficients in the coefficient table of the
+// constraint system. The circuit Define method itself is always run sequentially, and the
+// compiled system is identical to the one of a sequential compilation.
+//
+// This option is currently used by the R1CS builder only. If this option is not given, or if
+// nbWorkers is less than 2, then the compilation is sequential.
+func WithNbWorkers(nbWorkers int) CompileOption {
+	return func(opt *CompileConfig) error {
+		if nbWorkers < 0 {
+			return fmt.Errorf("invalid number of workers %d", nbWorkers)
+		}
+		opt.NbWorkers = nbWorkers
+		return nil
+	}
+}
+
+// WithBoundedComparisons is a compile option which makes the R1CS builder compare the operands
+// of api.Cmp and api.AssertIsLessOrEqual as values of at most nbBits bits: the operands are
+// range checked, and the result of the comparison is taken from a hint and checked with a
+// range check of their difference (see [github.com/consensys/gnark/std/math/cmp]), instead of
+// decomposing both operands in as many bits as the field. As the range checks of a circuit are
+// batched against a commitment, a comparison of 64-bit values then costs tens of constraints
+// instead of thousands.
+//
+// The circuit is unsatisfiable if an operand doesn't fit in nbBits bits, the bound of
+// api.AssertIsLessOrEqual included; the comparisons with a constant which doesn't fit are
+// compiled as without the option. api.IsZero is unchanged, as it costs 2 constraints. nbBits
+// must be less than the bit length of the field minus 1, and 0 disables the option.
+//
+// This option is used by the R1CS builder only.
+func WithBoundedComparisons(nbBits int) CompileOption {
+	return func(opt *CompileConfig) error {
+		if nbBits < 0 {
+			return fmt.Errorf("invalid number of bits %d", nbBits)
+		}
+		opt.ComparisonBits = nbBits
+		return nil
+	}
+}
+
 var tVariable reflect.Type
 
 func init() {
@@ -19,6 +19,7 @@ package r1cs
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -33,6 +34,8 @@ import (
 	"github.com/consensys/gnark/frontend/internal/expr"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/rangecheck"
 )
 
 // ---------------------------------------------------------------------------------------------
@@ -113,55 +116,20 @@ func (builder *builder) add(vars []expr.LinearExpression, sub bool, capacity int
 	// we build a sorted output by iterating all the lists in order and dealing
 	// with the edge cases (same variable ID, coeff == 0, etc.)
 
-	// initialize the min-heap
-
-	for lID, v := range vars {
-		builder.heap = append(builder.heap, linMeta{val: v[0].VID, lID: lID})
-	}
-	builder.heap.heapify()
-
 	if res == nil {
 		t := make(expr.LinearExpression, 0, capacity)
 		res = &t
 	}
-	curr := -1
 
-	// process all the terms from all the inputs, in sorted order
-	for len(builder.heap) > 0 {
-		lID, tID := builder.heap[0].lID, builder.heap[0].tID
-		if tID == len(vars[lID])-1 {
-			// last element, we remove it from the heap.
-			builder.heap.popHead()
-		} else {
-			// increment and fix the heap
-			builder.heap[0].tID++
-			builder.heap[0].val = vars[lID][tID+1].VID
-			builder.heap.fix(0)
-		}
-		t := &vars[lID][tID]
-		if t.Coeff.IsZero() {
-			continue // is this really needed?
-		}
-		if curr != -1 && t.VID == (*res)[curr].VID {
-			// accumulate, it's the same variable ID
-			if sub && lID != 0 {
-				(*res)[curr].Coeff = builder.cs.Sub((*res)[curr].Coeff, t.Coeff)
-			} else {
-				(*res)[curr].Coeff = builder.cs.Add((*res)[curr].Coeff, t.Coeff)
-			}
-			if (*res)[curr].Coeff.IsZero() {
-				// remove self.
-				(*res) = (*res)[:curr]
-				curr--
-			}
-		} else {
-			// append, it's a new variable ID
-			(*res) = append((*res), *t)
-			curr++
-			if sub && lID != 0 {
-				(*res)[curr].Coeff = builder.cs.Neg((*res)[curr].Coeff)
-			}
-		}
+	// with sub, all the expressions but the first one are negated
+	negFrom := len(vars)
+	if sub {
+		negFrom = 1
+	}
+	if builder.config.NbWorkers > 1 && len(vars) > 1 && nbTerms(vars) >= parallelAddMinTerms {
+		builder.mergeParallel(vars, negFrom, res)
+	} else {
+		mergeLinearExpressions(builder.cs, &builder.heap, vars, negFrom, res)
 	}
 
 	if len((*res)) == 0 {
@@ -230,6 +198,16 @@ func (builder *builder) Mul(i1, i2 frontend.Variable, in ...frontend.Variable) f
 }
 
 func (builder *builder) mulConstant(v1 expr.LinearExpression, lambda constraint.Element, inPlace bool) expr.LinearExpression {
+	if lambda.IsZero() {
+		// the result is the constant 0, we return it as such so that it keeps folding
+		// in subsequent operations.
+		if inPlace {
+			res := append(v1[:0], expr.NewTerm(0, lambda))
+			return res
+		}
+		return expr.NewLinearExpression(0, lambda)
+	}
+
 	// multiplying a frontend.Variable by a constant -> we updated the coefficients in the linear expression
 	// leading to that frontend.Variable
 	var res expr.LinearExpression
@@ -359,6 +337,19 @@ func (builder *builder) ToBinary(i1 frontend.Variable, n ...int) []frontend.Vari
 		}
 	}
 
+	// constant input: the bits are constants too, no hint nor constraint is needed
+	if c, ok := builder.constantValue(i1); ok {
+		n := builder.cs.ToBigInt(c)
+		if n.BitLen() > nbBits {
+			panic(fmt.Sprintf("constant %s doesn't fit in %d bits", n.String(), nbBits))
+		}
+		res := make([]frontend.Variable, nbBits)
+		for i := 0; i < nbBits; i++ {
+			res[i] = builder.toVariable(n.Bit(i))
+		}
+		return res
+	}
+
 	return bits.ToBinary(builder, i1, bits.WithNbDigits(nbBits))
 }
 
@@ -405,6 +396,20 @@ func (builder *builder) Or(_a, _b frontend.Variable) frontend.Variable {
 	builder.AssertIsBoolean(a)
 	builder.AssertIsBoolean(b)
 
+	// if one of the inputs is a constant, the result is either 1 or the other input
+	if c, ok := builder.constantValue(a); ok {
+		if builder.isCstOne(c) {
+			return builder.cstOne()
+		}
+		return b
+	}
+	if c, ok := builder.constantValue(b); ok {
+		if builder.isCstOne(c) {
+			return builder.cstOne()
+		}
+		return a
+	}
+
 	// the formulation used is for easing up the conversion to sparse r1cs
 	res := builder.newInternalVariable()
 	builder.MarkBoolean(res)
@@ -567,9 +572,22 @@ func (builder *builder) IsZero(i1 frontend.Variable) frontend.Variable {
 }
 
 // Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2
+//
+// With the option frontend.WithBoundedComparisons, the operands are compared with a hint and a
+// range check.
 func (builder *builder) Cmp(i1, i2 frontend.Variable) frontend.Variable {
 
 	vars, _ := builder.toVariables(i1, i2)
+
+	if c1, ok1 := builder.constantValue(vars[0]); ok1 {
+		if c2, ok2 := builder.constantValue(vars[1]); ok2 {
+			return builder.toVariable(builder.cs.ToBigInt(c1).Cmp(builder.cs.ToBigInt(c2)))
+		}
+	}
+	if builder.isBounded(vars[0], vars[1]) {
+		return builder.boundedComparator(vars[0], vars[1]).Cmp(vars[0], vars[1])
+	}
+
 	bi1 := builder.ToBinary(vars[0], builder.cs.FieldBitLen())
 	bi2 := builder.ToBinary(vars[1], builder.cs.FieldBitLen())
 
@@ -592,6 +610,40 @@ func (builder *builder) Cmp(i1, i2 frontend.Variable) frontend.Variable {
 	return res
 }
 
+// isBounded returns true if the operands of a comparison are compared with the bounded
+// comparator, that is if the option frontend.WithBoundedComparisons is set and the constant
+// operands fit in its number of bits.
+func (builder *builder) isBounded(operands ...frontend.Variable) bool {
+	nbBits := builder.config.ComparisonBits
+	if nbBits == 0 {
+		return false
+	}
+	for _, v := range operands {
+		if c, ok := builder.constantValue(v); ok && builder.cs.ToBigInt(c).BitLen() > nbBits {
+			return false
+		}
+	}
+	return true
+}
+
+// boundedComparator range checks the variable operands of a comparison to the number of bits
+// of the option frontend.WithBoundedComparisons, and returns the comparator of the values of
+// this range, created at the first comparison.
+func (builder *builder) boundedComparator(operands ...frontend.Variable) *cmp.BoundedComparator {
+	nbBits := builder.config.ComparisonBits
+	if builder.comparator == nil {
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+		builder.comparator = cmp.NewBoundedComparator(builder, bound.Sub(bound, big.NewInt(1)))
+		builder.rangechecker = rangecheck.New(builder)
+	}
+	for _, v := range operands {
+		if _, ok := builder.constantValue(v); !ok {
+			builder.rangechecker.Check(v, nbBits)
+		}
+	}
+	return builder.comparator
+}
+
 // Println enables circuit debugging and behaves almost like fmt.Println()
 //
 // the print will be done once the R1CS.Solve() method is executed
@@ -675,7 +727,19 @@ func (builder *builder) Compiler() frontend.Compiler {
 	return builder
 }
 
+// Commit registers a commitment to the variables of v in the CommitmentInfo of the
+// constraint system and returns the commitment. It implements [frontend.Committer].
+//
+// The committed wires are the wires of the linear expressions of v, sorted and without
+// duplicates; constants are ignored. The value of the commitment is computed by the prover,
+// which overrides the placeholder hint; the returned variable can be used as a Fiat-Shamir
+// challenge.
 func (builder *builder) Commit(v ...frontend.Variable) (frontend.Variable, error) {
+	frontend.RequireCapability(builder, "Commit", frontend.CapabilityCommitment)
+	if builder.cs.GetCommitments().Is() {
+		frontend.RequireCapability(builder, "Commit (called more than once)", frontend.CapabilityMultiCommitment)
+	}
+
 	// we want to build a sorted slice of committed variables, without duplicates
 	// this is the same algorithm as builder.add(...); but we expect len(v) to be quite large.
 
@@ -731,8 +795,10 @@ func (builder *builder) Commit(v ...frontend.Variable) (frontend.Variable, error
 	commitment := constraint.NewCommitment(committed, nbPublicCommitted)
 
 	// hint is used at solving time to compute the actual value of the commitment
-	// it is going to be dynamically replaced at solving time.
-	hintOut, err := builder.NewHint(cs.Bsb22CommitmentComputePlaceholder, 1, builder.getCommittedVariables(&commitment)...)
+	// it is going to be dynamically replaced at solving time. Its first input is the index of
+	// the commitment, for the prover to tell the commitments apart.
+	hintIn := append([]frontend.Variable{len(builder.cs.GetCommitments())}, builder.getCommittedVariables(&commitment)...)
+	hintOut, err := builder.NewHint(cs.Bsb22CommitmentComputePlaceholder, 1, hintIn...)
 	if err != nil {
 		return nil, err
 	}
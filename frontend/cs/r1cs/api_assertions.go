@@ -99,6 +99,11 @@ func (builder *builder) AssertIsLessOrEqual(v frontend.Variable, bound frontend.
 		}
 	}
 
+	if !(vConst && bConst) && builder.isBounded(v, bound) {
+		builder.boundedComparator(v, bound).AssertIsLessEq(v, bound)
+		return
+	}
+
 	// bound is constant
 	if bConst {
 		vv := builder.toVariable(v)
@@ -18,6 +18,7 @@ package r1cs
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"reflect"
 	"sort"
@@ -34,6 +35,7 @@ import (
 	"github.com/consensys/gnark/internal/tinyfield"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/std/math/cmp"
 
 	bls12377r1cs "github.com/consensys/gnark/constraint/bls12-377"
 	bls12381r1cs "github.com/consensys/gnark/constraint/bls12-381"
@@ -49,6 +51,9 @@ import (
 // NewBuilder returns a new R1CS builder which implements frontend.API.
 // Additionally, this builder also implements [frontend.Committer].
 func NewBuilder(field *big.Int, config frontend.CompileConfig) (frontend.Builder, error) {
+	if config.ComparisonBits > field.BitLen()-2 {
+		return nil, fmt.Errorf("bounded comparisons of %d bits, more than the field size minus 2", config.ComparisonBits)
+	}
 	return newBuilder(field, config), nil
 }
 
@@ -72,6 +77,11 @@ type builder struct {
 	mbuf2 expr.LinearExpression
 
 	genericGate constraint.BlueprintID
+
+	// comparator and rangechecker compare the operands of the option
+	// frontend.WithBoundedComparisons, see boundedComparator
+	comparator   *cmp.BoundedComparator
+	rangechecker frontend.Rangechecker
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -116,6 +126,9 @@ func newBuilder(field *big.Int, config frontend.CompileConfig) *builder {
 		}
 		panic("not implemented")
 	}
+	if config.SourceMapping {
+		builder.cs.EnableSourceMapping()
+	}
 
 	builder.tOne = builder.cs.One()
 	builder.cs.AddPublicVariable("1")
@@ -204,6 +217,9 @@ func (builder *builder) getLinearExpression(_l interface{}) constraint.LinearExp
 				return builder.cOne
 			}
 		}
+		if builder.config.NbWorkers > 1 && len(tl) >= parallelAddMinTerms {
+			return builder.makeTermsParallel(tl)
+		}
 		L = make(constraint.LinearExpression, 0, len(tl))
 		for _, t := range tl {
 			L = append(L, builder.cs.MakeTerm(&t.Coeff, t.VID))
@@ -237,6 +253,10 @@ func (builder *builder) MarkBoolean(v frontend.Variable) {
 	list := builder.mtBooleans[key]
 	list = append(list, l)
 	builder.mtBooleans[key] = list
+
+	if len(l) == 1 && builder.isCstOne(l[0].Coeff) {
+		builder.cs.MarkBoolean(l[0].VID)
+	}
 }
 
 // IsBoolean returns true if given variable was marked as boolean in the compiler (see MarkBoolean)
@@ -371,6 +391,17 @@ func (builder *builder) toVariables(in ...frontend.Variable) ([]expr.LinearExpre
 // No new constraints are added to the newly created wire and must be added
 // manually in the circuit. Failing to do so leads to solver failure.
 func (builder *builder) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	return builder.newHint(f, 0, nbOutputs, inputs)
+}
+
+// NewHintForId is NewHint for the hint function registered with the ID, or provided to the
+// solver with it.
+func (builder *builder) NewHintForId(id solver.HintID, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	return builder.newHint(nil, id, nbOutputs, inputs)
+}
+
+// newHint adds the hint f, or the hint with the ID if f is nil.
+func (builder *builder) newHint(f solver.Hint, id solver.HintID, nbOutputs int, inputs []frontend.Variable) ([]frontend.Variable, error) {
 	hintInputs := make([]constraint.LinearExpression, len(inputs))
 
 	// TODO @gbotrel hint input pass
@@ -387,7 +418,13 @@ func (builder *builder) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend
 		}
 	}
 
-	internalVariables, err := builder.cs.AddSolverHint(f, hintInputs, nbOutputs)
+	var internalVariables []int
+	var err error
+	if f != nil {
+		internalVariables, err = builder.cs.AddSolverHint(f, hintInputs, nbOutputs)
+	} else {
+		internalVariables, err = builder.cs.AddSolverHintForId(id, hintInputs, nbOutputs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,163 @@
+package r1cs
+
+import (
+	"sync"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend/internal/expr"
+)
+
+// parallelAddMinTerms is the minimal number of terms of the linear expressions to add for
+// the builder to split the merge among its workers (see frontend.WithNbWorkers), and of a
+// linear expression for it to split the lookups of its coefficients (see makeTermsParallel).
+const parallelAddMinTerms = 1 << 13
+
+// mergeLinearExpressions appends to res the sorted, reduced sum of the sorted linear
+// expressions vars, where the expressions vars[negFrom:] are negated. h is a scratch heap,
+// empty when the function returns.
+//
+// field is only used for coefficient arithmetic, which is stateless; distinct heaps can
+// hence be merged concurrently.
+func mergeLinearExpressions(field constraint.Field, h *minHeap, vars []expr.LinearExpression, negFrom int, res *expr.LinearExpression) {
+	// initialize the min-heap
+	for lID, v := range vars {
+		*h = append(*h, linMeta{val: v[0].VID, lID: lID})
+	}
+	h.heapify()
+
+	curr := len(*res) - 1
+
+	// process all the terms from all the inputs, in sorted order
+	for len(*h) > 0 {
+		lID, tID := (*h)[0].lID, (*h)[0].tID
+		if tID == len(vars[lID])-1 {
+			// last element, we remove it from the heap.
+			h.popHead()
+		} else {
+			// increment and fix the heap
+			(*h)[0].tID++
+			(*h)[0].val = vars[lID][tID+1].VID
+			h.fix(0)
+		}
+		t := &vars[lID][tID]
+		if t.Coeff.IsZero() {
+			continue // is this really needed?
+		}
+		if curr != -1 && t.VID == (*res)[curr].VID {
+			// accumulate, it's the same variable ID
+			if lID >= negFrom {
+				(*res)[curr].Coeff = field.Sub((*res)[curr].Coeff, t.Coeff)
+			} else {
+				(*res)[curr].Coeff = field.Add((*res)[curr].Coeff, t.Coeff)
+			}
+			if (*res)[curr].Coeff.IsZero() {
+				// remove self.
+				(*res) = (*res)[:curr]
+				curr--
+			}
+		} else {
+			// append, it's a new variable ID
+			(*res) = append((*res), *t)
+			curr++
+			if lID >= negFrom {
+				(*res)[curr].Coeff = field.Neg((*res)[curr].Coeff)
+			}
+		}
+	}
+}
+
+// mergeParallel is mergeLinearExpressions split among the builder workers: vars is cut in
+// chunks merged concurrently, and the partial sums are merged in res. Since a reduced sum is
+// unique, the result is identical to the sequential merge.
+func (builder *builder) mergeParallel(vars []expr.LinearExpression, negFrom int, res *expr.LinearExpression) {
+	nbChunks := builder.config.NbWorkers
+	if nbChunks > len(vars) {
+		nbChunks = len(vars)
+	}
+	chunkSize := (len(vars) + nbChunks - 1) / nbChunks
+
+	partials := make([]expr.LinearExpression, (len(vars)+chunkSize-1)/chunkSize)
+
+	var wg sync.WaitGroup
+	for i := range partials {
+		start := i * chunkSize
+		end := start + chunkSize
+		if end > len(vars) {
+			end = len(vars)
+		}
+		chunk := vars[start:end]
+
+		// negation index relative to the chunk
+		chunkNegFrom := negFrom - start
+		if chunkNegFrom < 0 {
+			chunkNegFrom = 0
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := make(minHeap, 0, len(chunk))
+			partial := make(expr.LinearExpression, 0, nbTerms(chunk))
+			mergeLinearExpressions(builder.cs, &h, chunk, chunkNegFrom, &partial)
+			partials[i] = partial
+		}(i)
+	}
+	wg.Wait()
+
+	// the partial sums are already negated where needed; empty ones are skipped since the
+	// heap is initialized with the first term of each expression.
+	n := 0
+	for _, p := range partials {
+		if len(p) != 0 {
+			partials[n] = p
+			n++
+		}
+	}
+	mergeLinearExpressions(builder.cs, &builder.heap, partials[:n], n, res)
+}
+
+// makeTermsParallel returns the terms of the constraint system for the linear expression l,
+// like successive calls to MakeTerm. The coefficient table is only read while l is sharded
+// among the builder workers, which look up the IDs of the coefficients of their shard; the
+// coefficients the table lacks are then added in the order of l, so that they get the IDs of
+// a sequential compilation.
+func (builder *builder) makeTermsParallel(l expr.LinearExpression) constraint.LinearExpression {
+	res := make(constraint.LinearExpression, len(l))
+	found := make([]bool, len(l))
+
+	nbShards := builder.config.NbWorkers
+	shardSize := (len(l) + nbShards - 1) / nbShards
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(l); start += shardSize {
+		end := start + shardSize
+		if end > len(l) {
+			end = len(l)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				res[i].VID = uint32(l[i].VID)
+				res[i].CID, found[i] = builder.cs.CoeffID(&l[i].Coeff)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	for i := range res {
+		if !found[i] {
+			res[i].CID = builder.cs.AddCoeff(l[i].Coeff)
+		}
+	}
+	return res
+}
+
+// nbTerms returns the total number of terms of the linear expressions.
+func nbTerms(vars []expr.LinearExpression) int {
+	n := 0
+	for _, v := range vars {
+		n += len(v)
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package r1cs
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Mux returns inputs[sel]. sel must be in [0, len(inputs)), otherwise the constraints are not
+// satisfied. It implements [frontend.Multiplexer].
+//
+// sel is decomposed in k = ⌈log₂(len(inputs))⌉ bits, which select the output in a tree of
+// Lookup2 (3 constraints for 4 inputs, 1 if they are constants). Compared to a multiplexer
+// with an indicator per input, this uses about len(inputs) + 2k constraints instead of
+// 2·len(inputs), and len(inputs)/2 + 2k if the inputs are constants.
+func (builder *builder) Mux(sel frontend.Variable, inputs ...frontend.Variable) frontend.Variable {
+	if len(inputs) == 0 {
+		panic("Mux: no inputs")
+	}
+
+	if c, ok := builder.constantValue(sel); ok {
+		i := builder.cs.ToBigInt(c)
+		if !i.IsUint64() || i.Uint64() >= uint64(len(inputs)) {
+			panic("Mux: constant selector out of range")
+		}
+		return inputs[i.Uint64()]
+	}
+	if len(inputs) == 1 {
+		builder.AssertIsEqual(sel, 0)
+		return inputs[0]
+	}
+
+	nbBits := bits.Len(uint(len(inputs) - 1))
+	selBits := builder.ToBinary(sel, nbBits)
+	if len(inputs) != 1<<nbBits {
+		builder.assertBitsLessOrEqCst(selBits, big.NewInt(int64(len(inputs)-1)))
+	}
+	return builder.muxBits(selBits, inputs)
+}
+
+// muxBits returns inputs[Σ 2ⁱ·selBits[i]], where selBits are boolean constrained and the
+// selected index is known to be less than len(inputs).
+func (builder *builder) muxBits(selBits []frontend.Variable, inputs []frontend.Variable) frontend.Variable {
+	// the indexes beyond len(inputs) are never selected, so the incomplete groups of inputs
+	// are padded with their last element, which makes the corresponding tree nodes cheaper.
+	for len(inputs) > 1 {
+		if len(selBits) == 1 || len(inputs) <= 2 {
+			next := make([]frontend.Variable, (len(inputs)+1)/2)
+			for i := range next {
+				if 2*i+1 < len(inputs) {
+					next[i] = builder.Select(selBits[0], inputs[2*i+1], inputs[2*i])
+				} else {
+					next[i] = inputs[2*i]
+				}
+			}
+			inputs, selBits = next, selBits[1:]
+			continue
+		}
+
+		next := make([]frontend.Variable, (len(inputs)+3)/4)
+		for i := range next {
+			group := inputs[4*i:]
+			switch len(group) {
+			case 1:
+				next[i] = group[0]
+			case 2:
+				next[i] = builder.Select(selBits[0], group[1], group[0])
+			case 3:
+				next[i] = builder.Lookup2(selBits[0], selBits[1], group[0], group[1], group[2], group[2])
+			default:
+				next[i] = builder.Lookup2(selBits[0], selBits[1], group[0], group[1], group[2], group[3])
+			}
+		}
+		inputs, selBits = next, selBits[2:]
+	}
+	return inputs[0]
+}
+
+// assertBitsLessOrEqCst asserts that Σ 2ⁱ·aBits[i] ⩽ bound, where aBits are boolean
+// constrained. It is mustBeLessOrEqCst without the decomposition in bits.
+func (builder *builder) assertBitsLessOrEqCst(aBits []frontend.Variable, bound *big.Int) {
+	nbBits := len(aBits)
+	if bound.BitLen() > nbBits {
+		return // always true
+	}
+
+	// p[i] == 1 → a[j] == c[j] for all j ⩾ i
+	p := make([]frontend.Variable, nbBits+1)
+	p[nbBits] = builder.cstOne()
+	for i := nbBits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 0 {
+			p[i] = p[i+1]
+		} else {
+			p[i] = builder.Mul(p[i+1], aBits[i])
+		}
+	}
+
+	for i := nbBits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 0 {
+			// (1 - p(i+1) - ai) * ai == 0
+			l := builder.Sub(1, p[i+1], aBits[i])
+			builder.cs.AddR1C(builder.newR1C(l, aBits[i], builder.cstZero()), builder.genericGate)
+		}
+	}
+}
+
+// Table is a read-only table of values, looked up at variable indexes with the multiplexer of
+// the R1CS builder.
+//
+// Lookups in a table of constants cost about len(entries)/2 constraints each; for many lookups
+// in a large table, [github.com/consensys/gnark/std/lookup/logderivlookup] amortizes better.
+type Table struct {
+	builder *builder
+	entries []frontend.Variable
+}
+
+// NewTable returns a read-only table of the entries, which are copied. api must be a builder
+// returned by NewBuilder.
+func NewTable(api frontend.API, entries ...frontend.Variable) *Table {
+	b, ok := api.(*builder)
+	if !ok {
+		panic("NewTable: api is not an R1CS builder")
+	}
+	if len(entries) == 0 {
+		panic("NewTable: no entries")
+	}
+	return &Table{builder: b, entries: append([]frontend.Variable(nil), entries...)}
+}
+
+// Len returns the number of entries of the table.
+func (t *Table) Len() int {
+	return len(t.entries)
+}
+
+// Lookup returns the entry at index, which must be in [0, t.Len()).
+func (t *Table) Lookup(index frontend.Variable) frontend.Variable {
+	return t.builder.Mux(index, t.entries...)
+}
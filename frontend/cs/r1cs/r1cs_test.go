@@ -17,12 +17,18 @@ limitations under the License.
 package r1cs
 
 import (
+	"bytes"
+	"math/big"
+	"math/bits"
 	"math/rand"
+	"reflect"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/internal/expr"
 )
@@ -92,6 +98,96 @@ func TestCompress(t *testing.T) {
 	}
 }
 
+func TestConstantFolding(t *testing.T) {
+	cs := newBuilder(ecc.BN254.ScalarField(), frontend.CompileConfig{})
+
+	isConstant := func(v frontend.Variable, expected uint64) {
+		t.Helper()
+		c, ok := cs.constantValue(v)
+		if !ok {
+			t.Fatal("expected a constant")
+		}
+		if n := cs.cs.ToBigInt(c); !n.IsUint64() || n.Uint64() != expected {
+			t.Fatalf("expected %d, got %s", expected, n.String())
+		}
+	}
+
+	// fully constant subgraph
+	a := cs.Add(2, 3)
+	b := cs.Mul(a, 4, 5)
+	c := cs.Select(1, b, 42)
+	d := cs.Sub(c, cs.Div(b, 2))
+	isConstant(d, 50)
+	isConstant(cs.Inverse(cs.Inverse(d)), 50)
+	isConstant(cs.Lookup2(1, 0, 10, 11, 12, 13), 11)
+	isConstant(cs.IsZero(cs.Sub(d, 50)), 1)
+	isConstant(cs.Or(0, 1), 1)
+	isConstant(cs.Xor(1, 1), 0)
+	isConstant(cs.And(1, 1), 1)
+	isConstant(cs.Cmp(d, 3), 1)
+	isConstant(cs.FromBinary(cs.ToBinary(d, 8)...), 50)
+
+	// a variable multiplied by 0 is the constant 0
+	x := cs.newInternalVariable()
+	zero := cs.Mul(x, 0)
+	isConstant(zero, 0)
+	isConstant(cs.Mul(zero, x, x), 0)
+	isConstant(cs.Select(x, 7, 7), 7)
+	if cs.Or(x, 0).(expr.LinearExpression)[0].WireID() != x[0].WireID() {
+		t.Fatal("x OR 0 should be x")
+	}
+
+	// boolean constraint on x from the Select/Or calls only
+	if n := cs.cs.GetNbConstraints(); n != 1 {
+		t.Fatalf("expected 1 constraint, got %d", n)
+	}
+}
+
+type largeSumCircuit struct {
+	X [100]frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *largeSumCircuit) Define(api frontend.API) error {
+	// many small linear expressions, with duplicated variables cancelling out
+	terms := make([]frontend.Variable, 3*parallelAddMinTerms)
+	for i := range terms {
+		terms[i] = api.Mul(c.X[i%len(c.X)], i%7-3)
+	}
+	sum := api.Add(terms[0], terms[1], terms[2:]...)
+	diff := api.Sub(terms[0], terms[1], terms[2:]...)
+
+	// a large linear expression of distinct wires, with coefficients the table lacks
+	wires := make([]frontend.Variable, parallelAddMinTerms)
+	for i := range wires {
+		wires[i] = api.Mul(c.X[i%len(c.X)], c.X[i/len(c.X)], i+3)
+	}
+	api.AssertIsEqual(api.Mul(sum, diff), api.Add(c.Y, wires[0], wires[1:]...))
+	return nil
+}
+
+func TestParallelAdd(t *testing.T) {
+	compile := func(opts ...frontend.CompileOption) []byte {
+		t.Helper()
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), NewBuilder, &largeSumCircuit{}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := ccs.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	expected := compile()
+	for _, nbWorkers := range []int{2, 3, 8} {
+		if !bytes.Equal(expected, compile(frontend.WithNbWorkers(nbWorkers))) {
+			t.Fatalf("compiling with %d workers doesn't give the sequential constraint system", nbWorkers)
+		}
+	}
+}
+
 func BenchmarkReduce(b *testing.B) {
 	cs := newBuilder(ecc.BN254.ScalarField(), frontend.CompileConfig{})
 	// 4 interesting cases;
@@ -160,3 +256,209 @@ func TestPreCompileHook(t *testing.T) {
 		t.Error("callback not called")
 	}
 }
+
+type muxCircuit struct {
+	Sel   frontend.Variable
+	In    []frontend.Variable
+	Out   frontend.Variable
+	table []int // if set, looked up instead of In
+}
+
+func (c *muxCircuit) Define(api frontend.API) error {
+	if c.table != nil {
+		entries := make([]frontend.Variable, len(c.table))
+		for i := range c.table {
+			entries[i] = c.table[i]
+		}
+		api.AssertIsEqual(NewTable(api, entries...).Lookup(c.Sel), c.Out)
+		return nil
+	}
+	api.AssertIsEqual(api.(frontend.Multiplexer).Mux(c.Sel, c.In...), c.Out)
+	return nil
+}
+
+func TestMux(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	for n := 1; n <= 9; n++ {
+		table := make([]int, n)
+		for i := range table {
+			table[i] = 10*i + 7
+		}
+		for _, constant := range []bool{false, true} {
+			circuit := muxCircuit{In: make([]frontend.Variable, n)}
+			if constant {
+				circuit.table = table
+			}
+			ccs, err := frontend.Compile(field, NewBuilder, &circuit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			isSolved := func(sel, out int) bool {
+				assignment := muxCircuit{Sel: sel, In: make([]frontend.Variable, n), Out: out}
+				for i := range table {
+					assignment.In[i] = table[i]
+				}
+				w, err := frontend.NewWitness(&assignment, field)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ccs.IsSolved(w) == nil
+			}
+			for sel := 0; sel < n; sel++ {
+				if !isSolved(sel, table[sel]) {
+					t.Fatalf("n=%d constant=%t: Mux(%d) failed", n, constant, sel)
+				}
+				if isSolved(sel, table[sel]+1) {
+					t.Fatalf("n=%d constant=%t: Mux(%d) accepted a wrong output", n, constant, sel)
+				}
+			}
+			if isSolved(n, table[n-1]) || isSolved(1<<bits.Len(uint(n)), table[0]) {
+				t.Fatalf("n=%d constant=%t: Mux accepted an out of range selector", n, constant)
+			}
+		}
+	}
+
+	// constraints count: the table of constants is cheaper than the generic multiplexer
+	table := make([]int, 64)
+	for i := range table {
+		table[i] = i*i + 1
+	}
+	for _, circuit := range []*muxCircuit{{In: make([]frontend.Variable, 64)}, {table: table}} {
+		ccs, err := frontend.Compile(field, NewBuilder, circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bound := 2 * 64
+		if circuit.table != nil {
+			bound = 64/2 + 2*6
+		}
+		if nb := ccs.GetNbConstraints(); nb > bound {
+			t.Fatalf("expected at most %d constraints, got %d", bound, nb)
+		}
+	}
+}
+
+type commitCircuit struct {
+	P    frontend.Variable `gnark:",public"`
+	X, Y frontend.Variable
+	Z    frontend.Variable
+	mode int // 0: two commitments, 1: constants only, 2: overlapping commitments
+}
+
+func (c *commitCircuit) Define(api frontend.API) error {
+	committer := api.(frontend.Committer)
+	switch c.mode {
+	case 1:
+		_, err := committer.Commit(3, 4)
+		return err
+	case 2:
+		if _, err := committer.Commit(c.X, c.Y); err != nil {
+			return err
+		}
+		_, err := committer.Commit(c.Y, c.Z)
+		return err
+	}
+	c0, err := committer.Commit(c.Y, c.X, c.P, 3, c.X, api.Add(c.X, c.Y))
+	if err != nil {
+		return err
+	}
+	c1, err := committer.Commit(c.Z)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(api.Mul(c0, c1), c.X)
+	return nil
+}
+
+func TestCommit(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+
+	ccs, err := frontend.Compile(field, NewBuilder, &commitCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments := ccs.(*cs_bn254.R1CS).CommitmentInfo
+	if len(commitments) != 2 {
+		t.Fatalf("expected 2 commitments, got %d", len(commitments))
+	}
+	// wires: 0 is ONE, 1 is P, then X, Y and Z
+	if !reflect.DeepEqual(commitments[0].Committed, []int{1, 2, 3}) || commitments[0].NbPrivateCommitted != 2 {
+		t.Fatalf("unexpected first commitment %v", commitments[0])
+	}
+	if !reflect.DeepEqual(commitments[1].Committed, []int{4}) || commitments[1].NbPrivateCommitted != 1 {
+		t.Fatalf("unexpected second commitment %v", commitments[1])
+	}
+	if commitments[0].CommitmentIndex == commitments[1].CommitmentIndex {
+		t.Fatal("commitments share their wire")
+	}
+
+	for _, mode := range []int{1, 2} {
+		if _, err := frontend.Compile(field, NewBuilder, &commitCircuit{mode: mode}); err == nil {
+			t.Fatalf("mode %d: expected a compilation error", mode)
+		}
+	}
+}
+
+func constantHint(c int64) solver.Hint {
+	return func(_ *big.Int, _ []*big.Int, outputs []*big.Int) error {
+		outputs[0].SetInt64(c)
+		return nil
+	}
+}
+
+var (
+	hintA  = constantHint(1)
+	hintB  = constantHint(2)
+	hintID = [2]solver.HintID{
+		solver.RegisterNamedHint("r1cs_test", "a", 1, hintA),
+		solver.RegisterNamedHint("r1cs_test", "b", 1, hintB),
+	}
+)
+
+type namedHintCircuit struct {
+	A, B  frontend.Variable
+	byFun bool
+}
+
+func (c *namedHintCircuit) Define(api frontend.API) error {
+	if c.byFun {
+		_, err := api.Compiler().NewHint(hintA, 1)
+		return err
+	}
+	for i, v := range []frontend.Variable{c.A, c.B} {
+		res, err := api.Compiler().NewHintForId(hintID[i], 1, v)
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(res[0], v)
+	}
+	return nil
+}
+
+func TestNewHintForId(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+
+	if _, err := frontend.Compile(field, NewBuilder, &namedHintCircuit{byFun: true}); err == nil {
+		t.Fatal("expected an error calling a hint registered under several names by its function")
+	}
+
+	ccs, err := frontend.Compile(field, NewBuilder, &namedHintCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := frontend.NewWitness(&namedHintCircuit{A: 1, B: 2}, field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ccs.Solve(w); err != nil {
+		t.Fatal(err)
+	}
+	w, err = frontend.NewWitness(&namedHintCircuit{A: 2, B: 1}, field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ccs.Solve(w); err == nil {
+		t.Fatal("expected the hints to be told apart")
+	}
+}
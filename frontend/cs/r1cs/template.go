@@ -0,0 +1,144 @@
+package r1cs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/internal/expr"
+	"github.com/consensys/gnark/internal/circuitdefer"
+)
+
+// template is a gadget compiled by CompileTemplate. Its system has the wire 1, the inputs as
+// secret wires and the wires created by the gadget as internal wires.
+type template struct {
+	cs       constraint.R1CS
+	nbInputs int
+	outputs  []expr.LinearExpression
+}
+
+// CompileTemplate calls define on a new builder with the inputs as secret variables and keeps
+// the constraints it adds. See frontend.Template.
+func (builder *builder) CompileTemplate(nbInputs int, define frontend.TemplateFunc) (frontend.CompiledTemplate, error) {
+	config := builder.config
+	config.Capacity = 0
+	tb := newBuilder(builder.Field(), config)
+
+	inputs := make([]frontend.Variable, nbInputs)
+	for i := range inputs {
+		inputs[i] = expr.NewLinearExpression(tb.cs.AddSecretVariable(fmt.Sprintf("in%d", i)), tb.tOne)
+	}
+	outputs, err := define(tb, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(circuitdefer.GetAll[func(frontend.API) error](tb)) != 0 {
+		return nil, errors.New("template calls Defer")
+	}
+	if tb.cs.GetCommitments().Is() {
+		return nil, errors.New("template commits to variables")
+	}
+
+	nbWires := tb.cs.GetNbPublicVariables() + tb.cs.GetNbSecretVariables() + tb.cs.GetNbInternalVariables()
+	t := template{
+		cs:       tb.cs,
+		nbInputs: nbInputs,
+		outputs:  make([]expr.LinearExpression, len(outputs)),
+	}
+	for i, o := range outputs {
+		t.outputs[i] = tb.toVariable(o).Clone()
+		for _, term := range t.outputs[i] {
+			if term.VID >= nbWires {
+				return nil, fmt.Errorf("template output %d is not a variable of the template", i)
+			}
+		}
+	}
+	return &t, nil
+}
+
+// InstantiateTemplate adds the constraints and the hints of the compiled template to the
+// builder. The wire 1 and the inputs of the template are replaced by the constant 1 and
+// inputs, and its internal wires by new internal wires. See frontend.Template.
+func (builder *builder) InstantiateTemplate(ct frontend.CompiledTemplate, inputs []frontend.Variable) ([]frontend.Variable, error) {
+	t, ok := ct.(*template)
+	if !ok {
+		return nil, fmt.Errorf("template compiled by %T", ct)
+	}
+	if len(inputs) != t.nbInputs {
+		return nil, fmt.Errorf("template has %d inputs, got %d", t.nbInputs, len(inputs))
+	}
+
+	// wires[i] is the linear expression replacing the wire i of the template; the internal
+	// wires are created when first seen.
+	nbWires := t.cs.GetNbPublicVariables() + t.cs.GetNbSecretVariables() + t.cs.GetNbInternalVariables()
+	wires := make([]expr.LinearExpression, nbWires)
+	wires[0] = builder.cstOne()
+	for i, in := range inputs {
+		wires[1+i] = builder.toVariable(in)
+	}
+	wire := func(vid int) expr.LinearExpression {
+		if wires[vid] == nil {
+			wires[vid] = builder.newInternalVariable()
+		}
+		return wires[vid]
+	}
+
+	// instantiate replaces the wires of the terms of l.
+	instantiate := func(l constraint.LinearExpression) expr.LinearExpression {
+		vars := make([]expr.LinearExpression, 0, len(l))
+		for _, term := range l {
+			coeff := t.cs.GetCoefficient(term.CoeffID())
+			if term.IsConstant() {
+				vars = append(vars, expr.NewLinearExpression(0, coeff))
+			} else {
+				vars = append(vars, builder.mulConstant(wire(term.WireID()), coeff, false))
+			}
+		}
+		if len(vars) == 0 {
+			return builder.cstZero()
+		}
+		return builder.add(vars, false, 0, nil).(expr.LinearExpression)
+	}
+
+	var (
+		r1c constraint.R1C
+		hm  constraint.HintMapping
+	)
+	for i := 0; i < t.cs.GetNbInstructions(); i++ {
+		inst := t.cs.GetInstruction(i)
+		calldata := t.cs.GetCallData(inst)
+
+		switch blueprint := t.cs.GetBlueprint(inst.BlueprintID).(type) {
+		case constraint.BlueprintR1C:
+			blueprint.DecompressR1C(&r1c, calldata)
+			l, r, o := instantiate(r1c.L), instantiate(r1c.R), instantiate(r1c.O)
+			builder.cs.AddR1C(builder.newR1C(l, r, o), builder.genericGate)
+		case constraint.BlueprintHint:
+			blueprint.DecompressHint(&hm, calldata)
+			hintInputs := make([]constraint.LinearExpression, len(hm.Inputs))
+			for j, in := range hm.Inputs {
+				hintInputs[j] = builder.getLinearExpression(instantiate(in))
+			}
+			outputs, err := builder.cs.AddSolverHintForId(hm.HintID, hintInputs, int(hm.OutputRange.End-hm.OutputRange.Start))
+			if err != nil {
+				return nil, err
+			}
+			for j, vid := range outputs {
+				wires[int(hm.OutputRange.Start)+j] = expr.NewLinearExpression(vid, builder.tOne)
+			}
+		default:
+			return nil, fmt.Errorf("template instruction %d: unsupported blueprint %T", i, blueprint)
+		}
+	}
+
+	res := make([]frontend.Variable, len(t.outputs))
+	for i, o := range t.outputs {
+		vars := make([]expr.LinearExpression, len(o))
+		for j, term := range o {
+			vars[j] = builder.mulConstant(wire(term.VID), term.Coeff, false)
+		}
+		res[i] = builder.add(vars, false, 0, nil)
+	}
+	return res, nil
+}
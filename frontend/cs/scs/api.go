@@ -17,6 +17,7 @@ limitations under the License.
 package scs
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -562,6 +563,11 @@ func (builder *builder) Compiler() frontend.Compiler {
 }
 
 func (builder *builder) Commit(v ...frontend.Variable) (frontend.Variable, error) {
+	frontend.RequireCapability(builder, "Commit", frontend.CapabilityCommitment)
+	if builder.cs.GetCommitments().Is() {
+		frontend.RequireCapability(builder, "Commit (called more than once)", frontend.CapabilityMultiCommitment)
+		return nil, errors.New("only one commitment per circuit is supported")
+	}
 
 	v = filterConstants(v) // TODO: @Tabaie Settle on a way to represent even constants; conventional hash?
 
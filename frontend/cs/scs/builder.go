@@ -106,6 +106,9 @@ func newBuilder(field *big.Int, config frontend.CompileConfig) *builder {
 		}
 		panic("not implemented")
 	}
+	if config.SourceMapping {
+		b.cs.EnableSourceMapping()
+	}
 
 	b.tOne = b.cs.One()
 	b.tMinusOne = b.cs.FromInterface(-1)
@@ -267,7 +270,11 @@ func (builder *builder) MarkBoolean(v frontend.Variable) {
 		}
 		return
 	}
-	builder.mtBooleans[v.(expr.Term)] = struct{}{}
+	t := v.(expr.Term)
+	builder.mtBooleans[t] = struct{}{}
+	if builder.cs.IsOne(t.Coeff) {
+		builder.cs.MarkBoolean(t.VID)
+	}
 }
 
 var tVariable reflect.Type
@@ -324,6 +331,17 @@ func (builder *builder) constantValue(v frontend.Variable) (constraint.Element,
 // No new constraints are added to the newly created wire and must be added
 // manually in the circuit. Failing to do so leads to solver failure.
 func (builder *builder) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	return builder.newHint(f, 0, nbOutputs, inputs)
+}
+
+// NewHintForId is NewHint for the hint function registered with the ID, or provided to the
+// solver with it.
+func (builder *builder) NewHintForId(id solver.HintID, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	return builder.newHint(nil, id, nbOutputs, inputs)
+}
+
+// newHint adds the hint f, or the hint with the ID if f is nil.
+func (builder *builder) newHint(f solver.Hint, id solver.HintID, nbOutputs int, inputs []frontend.Variable) ([]frontend.Variable, error) {
 	hintInputs := make([]constraint.LinearExpression, len(inputs))
 
 	// ensure inputs are set and pack them in a []uint64
@@ -339,7 +357,13 @@ func (builder *builder) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend
 		}
 	}
 
-	internalVariables, err := builder.cs.AddSolverHint(f, hintInputs, nbOutputs)
+	var internalVariables []int
+	var err error
+	if f != nil {
+		internalVariables, err = builder.cs.AddSolverHint(f, hintInputs, nbOutputs)
+	} else {
+		internalVariables, err = builder.cs.AddSolverHintForId(id, hintInputs, nbOutputs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -484,7 +508,9 @@ func (builder *builder) addConstraintExist(a, b expr.Term, k constraint.Element)
 				// compute n, the coefficient for the output wire
 				q2, ok = builder.cs.Inverse(q2)
 				if !ok {
-					panic("div by 0") // shouldn't happen
+					// the recorded addition has no xb (qR == 0), as when adding a constant: it
+					// matches wire 0 but can't be reused
+					return expr.Term{}, false
 				}
 				q2 = builder.cs.Mul(q2, q4)
 				return expr.NewTerm(int(c.XC), q2), true
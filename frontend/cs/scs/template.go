@@ -0,0 +1,264 @@
+package scs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/internal/expr"
+	"github.com/consensys/gnark/internal/circuitdefer"
+)
+
+// template is a gadget compiled by CompileTemplate. Its system has the inputs as secret wires
+// and the wires created by the gadget as internal wires.
+type template struct {
+	cs       constraint.SparseR1CS
+	nbInputs int
+	outputs  []templateWire
+}
+
+// templateWire is a term or a constant.
+type templateWire struct {
+	term     expr.Term
+	constant bool
+	value    constraint.Element
+}
+
+// CompileTemplate calls define on a new builder with the inputs as secret variables and keeps
+// the constraints it adds. See frontend.Template.
+func (builder *builder) CompileTemplate(nbInputs int, define frontend.TemplateFunc) (frontend.CompiledTemplate, error) {
+	config := builder.config
+	config.Capacity = 0
+	tb := newBuilder(builder.Field(), config)
+
+	inputs := make([]frontend.Variable, nbInputs)
+	for i := range inputs {
+		inputs[i] = expr.NewTerm(tb.cs.AddSecretVariable(fmt.Sprintf("in%d", i)), tb.tOne)
+	}
+	outputs, err := define(tb, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(circuitdefer.GetAll[func(frontend.API) error](tb)) != 0 {
+		return nil, errors.New("template calls Defer")
+	}
+	if tb.cs.GetCommitments().Is() {
+		return nil, errors.New("template commits to variables")
+	}
+
+	nbWires := tb.cs.GetNbPublicVariables() + tb.cs.GetNbSecretVariables() + tb.cs.GetNbInternalVariables()
+	t := template{
+		cs:       tb.cs,
+		nbInputs: nbInputs,
+		outputs:  make([]templateWire, len(outputs)),
+	}
+	for i, o := range outputs {
+		if c, ok := tb.constantValue(o); ok {
+			t.outputs[i] = templateWire{constant: true, value: c}
+			continue
+		}
+		term := o.(expr.Term)
+		if term.VID >= nbWires {
+			return nil, fmt.Errorf("template output %d is not a variable of the template", i)
+		}
+		t.outputs[i] = templateWire{term: term}
+	}
+	return &t, nil
+}
+
+// InstantiateTemplate adds the constraints and the hints of the compiled template to the
+// builder. The inputs of the template are replaced by inputs, and its internal wires by new
+// internal wires; the constant inputs are folded in the constraints. See frontend.Template.
+func (builder *builder) InstantiateTemplate(ct frontend.CompiledTemplate, inputs []frontend.Variable) ([]frontend.Variable, error) {
+	t, ok := ct.(*template)
+	if !ok {
+		return nil, fmt.Errorf("template compiled by %T", ct)
+	}
+	if len(inputs) != t.nbInputs {
+		return nil, fmt.Errorf("template has %d inputs, got %d", t.nbInputs, len(inputs))
+	}
+
+	// wires[i] replaces the wire i of the template; the internal wires are created when
+	// first seen.
+	nbWires := t.cs.GetNbPublicVariables() + t.cs.GetNbSecretVariables() + t.cs.GetNbInternalVariables()
+	wires := make([]*templateWire, nbWires)
+	for i, in := range inputs {
+		if c, ok := builder.constantValue(in); ok {
+			wires[i] = &templateWire{constant: true, value: c}
+		} else {
+			wires[i] = &templateWire{term: in.(expr.Term)}
+		}
+	}
+	wire := func(vid uint32) *templateWire {
+		if wires[vid] == nil {
+			wires[vid] = &templateWire{term: builder.newInternalVariable()}
+		}
+		return wires[vid]
+	}
+
+	var (
+		c  constraint.SparseR1C
+		hm constraint.HintMapping
+	)
+	for i := 0; i < t.cs.GetNbInstructions(); i++ {
+		inst := t.cs.GetInstruction(i)
+		calldata := t.cs.GetCallData(inst)
+
+		switch blueprint := t.cs.GetBlueprint(inst.BlueprintID).(type) {
+		case constraint.BlueprintSparseR1C:
+			blueprint.DecompressSparseR1C(&c, calldata)
+			if err := builder.instantiateSparseR1C(t, &c, wire); err != nil {
+				return nil, fmt.Errorf("template instruction %d: %w", i, err)
+			}
+		case constraint.BlueprintHint:
+			blueprint.DecompressHint(&hm, calldata)
+			hintInputs := make([]constraint.LinearExpression, len(hm.Inputs))
+			for j, in := range hm.Inputs {
+				hintInputs[j] = builder.instantiateHintInput(t, in, wire)
+			}
+			outputs, err := builder.cs.AddSolverHintForId(hm.HintID, hintInputs, int(hm.OutputRange.End-hm.OutputRange.Start))
+			if err != nil {
+				return nil, err
+			}
+			for j, vid := range outputs {
+				wires[int(hm.OutputRange.Start)+j] = &templateWire{term: expr.NewTerm(vid, builder.tOne)}
+			}
+		default:
+			return nil, fmt.Errorf("template instruction %d: unsupported blueprint %T", i, blueprint)
+		}
+	}
+
+	res := make([]frontend.Variable, len(t.outputs))
+	for i, o := range t.outputs {
+		if o.constant {
+			res[i] = builder.cs.ToBigInt(o.value)
+			continue
+		}
+		w := wire(uint32(o.term.VID))
+		if w.constant {
+			res[i] = builder.cs.ToBigInt(builder.cs.Mul(w.value, o.term.Coeff))
+			continue
+		}
+		res[i] = expr.NewTerm(w.term.VID, builder.cs.Mul(w.term.Coeff, o.term.Coeff))
+	}
+	return res, nil
+}
+
+// instantiateSparseR1C adds the constraint c of the template t, with the wires replaced by
+// wire. The constant wires are folded in the constant or the linear coefficients.
+func (builder *builder) instantiateSparseR1C(t *template, c *constraint.SparseR1C, wire func(uint32) *templateWire) error {
+	if c.Commitment != constraint.NOT {
+		return errors.New("commitment constraint")
+	}
+	coeff := func(cID uint32) constraint.Element {
+		return t.cs.GetCoefficient(int(cID))
+	}
+
+	// the wires of the new constraint, in the order xa, xb, xc, and their linear coefficients
+	var (
+		vids   [3]int
+		qs     [3]constraint.Element
+		nbVids int
+		qM     constraint.Element
+		qC     = coeff(c.QC)
+	)
+	addLinear := func(term expr.Term, q constraint.Element) error {
+		q = builder.cs.Mul(q, term.Coeff)
+		for i := 0; i < nbVids; i++ {
+			if vids[i] == term.VID {
+				qs[i] = builder.cs.Add(qs[i], q)
+				return nil
+			}
+		}
+		if nbVids == len(vids) {
+			return errors.New("more than 3 wires")
+		}
+		vids[nbVids], qs[nbVids] = term.VID, q
+		nbVids++
+		return nil
+	}
+
+	// the product comes first, it needs its wires in xa and xb
+	if q := coeff(c.QM); !q.IsZero() {
+		a, b := wire(c.XA), wire(c.XB)
+		var err error
+		switch {
+		case a.constant && b.constant:
+			qC = builder.cs.Add(qC, builder.cs.Mul(q, builder.cs.Mul(a.value, b.value)))
+		case a.constant:
+			err = addLinear(b.term, builder.cs.Mul(q, a.value))
+		case b.constant:
+			err = addLinear(a.term, builder.cs.Mul(q, b.value))
+		default:
+			qM = builder.cs.Mul(q, builder.cs.Mul(a.term.Coeff, b.term.Coeff))
+			vids[0], vids[1] = a.term.VID, b.term.VID
+			nbVids = 2
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, l := range [...]struct{ vid, cID uint32 }{{c.XA, c.QL}, {c.XB, c.QR}, {c.XC, c.QO}} {
+		q := coeff(l.cID)
+		if q.IsZero() {
+			continue
+		}
+		w := wire(l.vid)
+		if w.constant {
+			qC = builder.cs.Add(qC, builder.cs.Mul(q, w.value))
+			continue
+		}
+		if err := addLinear(w.term, q); err != nil {
+			return err
+		}
+	}
+
+	if nbVids == 0 {
+		if !qC.IsZero() {
+			return errors.New("constraint not satisfied by the constant inputs")
+		}
+		return nil
+	}
+
+	builder.cs.AddSparseR1C(constraint.SparseR1C{
+		XA: uint32(vids[0]),
+		XB: uint32(vids[1]),
+		XC: uint32(vids[2]),
+		QL: builder.cs.AddCoeff(qs[0]),
+		QR: builder.cs.AddCoeff(qs[1]),
+		QO: builder.cs.AddCoeff(qs[2]),
+		QM: builder.cs.AddCoeff(qM),
+		QC: builder.cs.AddCoeff(qC),
+	}, builder.genericGate)
+	return nil
+}
+
+// instantiateHintInput returns the hint input in of the template t, with the wires replaced by
+// wire.
+func (builder *builder) instantiateHintInput(t *template, in constraint.LinearExpression, wire func(uint32) *templateWire) constraint.LinearExpression {
+	var (
+		res      constraint.LinearExpression
+		constant constraint.Element
+	)
+	for _, term := range in {
+		q := t.cs.GetCoefficient(term.CoeffID())
+		if term.IsConstant() {
+			constant = builder.cs.Add(constant, q)
+			continue
+		}
+		w := wire(term.VID)
+		if w.constant {
+			constant = builder.cs.Add(constant, builder.cs.Mul(q, w.value))
+			continue
+		}
+		q = builder.cs.Mul(q, w.term.Coeff)
+		res = append(res, builder.cs.MakeTerm(&q, w.term.VID))
+	}
+	if !constant.IsZero() || len(res) == 0 {
+		term := builder.cs.MakeTerm(&constant, 0)
+		term.MarkConstant()
+		res = append(res, term)
+	}
+	return res
+}
@@ -0,0 +1,95 @@
+package schema
+
+import "encoding/json"
+
+// elementPattern matches the field elements given as strings: decimal or hexadecimal.
+const elementPattern = "^(0|[1-9][0-9]*|0[xX][0-9a-fA-F]+)$"
+
+// ToJSONSchema returns a JSON Schema (draft 2020-12) describing the JSON encoding of the
+// witnesses of the circuit, as produced by Witness.ToJSON, so that the services generating
+// witnesses in other languages can validate them before proving.
+//
+// Field elements are non-negative integers, or strings holding a decimal or hexadecimal number;
+// their range depends on the field, and is only checked when the witness is read. Arrays have
+// their exact size, and unknown properties are rejected. If publicOnly is set, the schema
+// describes a public witness instead: the secret values must then be omitted or null.
+func (s Schema) ToJSONSchema(publicOnly bool) ([]byte, error) {
+	root := jsonSchemaObject(s.Fields, publicOnly)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$defs"] = map[string]any{
+		"element": map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "integer", "minimum": 0},
+				map[string]any{"type": "string", "pattern": elementPattern},
+			},
+		},
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func jsonSchemaField(f Field, publicOnly bool) map[string]any {
+	switch f.Type {
+	case Struct:
+		return jsonSchemaObject(f.SubFields, publicOnly)
+	case Array:
+		var items map[string]any
+		if len(f.SubFields) == 0 {
+			items = jsonSchemaLeaf(f.Visibility, publicOnly)
+		} else {
+			items = jsonSchemaField(f.SubFields[0], publicOnly)
+		}
+		return map[string]any{
+			"type":     "array",
+			"items":    items,
+			"minItems": f.ArraySize,
+			"maxItems": f.ArraySize,
+		}
+	default:
+		return jsonSchemaLeaf(f.Visibility, publicOnly)
+	}
+}
+
+func jsonSchemaObject(fields []Field, publicOnly bool) map[string]any {
+	properties := make(map[string]any, len(fields))
+	required := make([]string, 0, len(fields))
+	for _, f := range fields {
+		name := f.Name
+		if f.NameTag != "" {
+			name = f.NameTag
+		}
+		properties[name] = jsonSchemaField(f, publicOnly)
+		if !publicOnly || hasPublic(f) {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonSchemaLeaf describes a field element; as in the witness, an unset visibility is secret.
+func jsonSchemaLeaf(visibility Visibility, publicOnly bool) map[string]any {
+	if visibility != Public {
+		if publicOnly {
+			return map[string]any{"type": "null", "description": Secret.String()}
+		}
+		return map[string]any{"$ref": "#/$defs/element", "description": Secret.String()}
+	}
+	return map[string]any{"$ref": "#/$defs/element", "description": Public.String()}
+}
+
+// hasPublic reports whether the field holds public values.
+func hasPublic(f Field) bool {
+	if f.Type == Leaf || (f.Type == Array && len(f.SubFields) == 0) {
+		return f.Visibility == Public
+	}
+	for _, sf := range f.SubFields {
+		if hasPublic(sf) {
+			return true
+		}
+	}
+	return false
+}
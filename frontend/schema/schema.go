@@ -359,6 +359,7 @@ func parse(r []Field, input interface{}, target reflect.Type, parentFullName, pa
 		var err error
 		for j := 0; j < tValue.Len(); j++ {
 			val := tValue.Index(j)
+			callInitHook(val)
 			if val.CanAddr() && val.Addr().CanInterface() {
 				fqn := getFullName(parentFullName, strconv.Itoa(j), "")
 				subFields, err = parse(subFields, val.Addr().Interface(), target, fqn, fqn, parentTagName, parentVisibility, nbPublic, nbSecret)
@@ -91,8 +91,9 @@ func (w *walker) Slice(value reflect.Value) error {
 	return nil
 }
 
-func (w *walker) SliceElem(index int, _ reflect.Value) error {
+func (w *walker) SliceElem(index int, v reflect.Value) error {
 	w.path.push(LeafInfo{Visibility: w.visibility(), name: strconv.Itoa(index)})
+	callInitHook(v)
 	return nil
 }
 
@@ -103,8 +104,9 @@ func (w *walker) Array(value reflect.Value) error {
 	}
 	return nil
 }
-func (w *walker) ArrayElem(index int, _ reflect.Value) error {
+func (w *walker) ArrayElem(index int, v reflect.Value) error {
 	w.path.push(LeafInfo{Visibility: w.visibility(), name: strconv.Itoa(index)})
+	callInitHook(v)
 	return nil
 }
 
@@ -150,14 +152,9 @@ func (w *walker) StructField(sf reflect.StructField, v reflect.Value) error {
 		return reflectwalk.ErrSkipEntry // skipping "-"
 	}
 
-	if v.CanAddr() && v.Addr().CanInterface() {
-		// TODO @gbotrel don't like that hook, undesirable side effects
-		// will be hard to detect; (for example calling Parse multiple times will init multiple times!)
-		value := v.Addr().Interface()
-		if ih, hasInitHook := value.(InitHook); hasInitHook {
-			ih.GnarkInitHook()
-		}
-	}
+	// TODO @gbotrel don't like that hook, undesirable side effects
+	// will be hard to detect; (for example calling Parse multiple times will init multiple times!)
+	callInitHook(v)
 
 	// default visibility: parent (or unset)
 	parentVisibility := w.visibility()
@@ -262,3 +259,13 @@ func (s *pathStack) pop() {
 func (s *pathStack) top() LeafInfo {
 	return (*s)[len(*s)-1]
 }
+
+// callInitHook calls the init hook of the value, a struct field or an element of a slice or
+// an array, if it has one.
+func callInitHook(v reflect.Value) {
+	if v.CanAddr() && v.Addr().CanInterface() {
+		if ih, hasInitHook := v.Addr().Interface().(InitHook); hasInitHook {
+			ih.GnarkInitHook()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package frontend
+
+import (
+	"math/big"
+	"reflect"
+	"sync"
+)
+
+// TemplateFunc defines a gadget: it adds the constraints of the gadget to api and returns its
+// outputs from its inputs.
+type TemplateFunc func(api API, inputs []Variable) ([]Variable, error)
+
+// Template is a gadget compiled once and instantiated many times. The first call to
+// Instantiate for a builder kind, a field and a number of inputs calls define on a new builder
+// with variable inputs and keeps the constraints it adds; the following calls copy these
+// constraints, with the inputs in place of the template inputs, without calling define.
+//
+// The instances are hence not optimized for constant inputs, and they don't keep what define
+// records besides the constraints and the hints: logs, debug information and variables marked
+// boolean. define must only use its inputs, constants and the variables it creates, and
+// templates calling Defer or committing to variables can't be compiled.
+//
+// Builders which don't implement TemplateBuilder, such as the test engine, call define on every
+// instantiation.
+//
+// A Template is safe for concurrent use.
+type Template struct {
+	define TemplateFunc
+
+	lock     sync.Mutex
+	compiled map[templateKey]CompiledTemplate
+}
+
+type templateKey struct {
+	builder  reflect.Type
+	field    string
+	nbInputs int
+}
+
+// NewTemplate returns a new Template of the gadget define.
+func NewTemplate(define TemplateFunc) *Template {
+	return &Template{
+		define:   define,
+		compiled: make(map[templateKey]CompiledTemplate),
+	}
+}
+
+// Instantiate adds the constraints of the template to api and returns the outputs for inputs.
+func (t *Template) Instantiate(api API, inputs ...Variable) ([]Variable, error) {
+	builder, ok := api.(TemplateBuilder)
+	if !ok {
+		return t.define(api, inputs)
+	}
+	compiled, err := t.compile(builder, len(inputs))
+	if err != nil {
+		return nil, err
+	}
+	return builder.InstantiateTemplate(compiled, inputs)
+}
+
+// compile returns the template compiled by builder with nbInputs inputs.
+func (t *Template) compile(builder TemplateBuilder, nbInputs int) (CompiledTemplate, error) {
+	key := templateKey{
+		builder:  reflect.TypeOf(builder),
+		field:    builder.Field().String(),
+		nbInputs: nbInputs,
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if compiled, ok := t.compiled[key]; ok {
+		return compiled, nil
+	}
+	compiled, err := builder.CompileTemplate(nbInputs, t.define)
+	if err != nil {
+		return nil, err
+	}
+	t.compiled[key] = compiled
+	return compiled, nil
+}
+
+// TemplateBuilder is implemented by the builders instantiating compiled templates, see
+// [Template].
+type TemplateBuilder interface {
+	// Field returns the finite field modulus injected by the compiler
+	Field() *big.Int
+
+	// CompileTemplate calls define with nbInputs variable inputs on a new builder of the same
+	// kind and returns the constraints it added.
+	CompileTemplate(nbInputs int, define TemplateFunc) (CompiledTemplate, error)
+
+	// InstantiateTemplate adds the constraints of the compiled template to the builder, with
+	// inputs in place of the template inputs, and returns the outputs of the template.
+	InstantiateTemplate(t CompiledTemplate, inputs []Variable) ([]Variable, error)
+}
+
+// CompiledTemplate is a template compiled by a TemplateBuilder. It is only instantiated by the
+// builders of the same kind, on the same field.
+type CompiledTemplate interface{}
@@ -0,0 +1,99 @@
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// nbTemplateCalls counts the calls to the define function of gadget.
+var nbTemplateCalls int
+
+// gadget returns x*y + x + (x == y), with x on 8 bits. It uses hints through IsZero and
+// ToBinary.
+var gadget = frontend.NewTemplate(func(api frontend.API, inputs []frontend.Variable) ([]frontend.Variable, error) {
+	nbTemplateCalls++
+	x, y := inputs[0], inputs[1]
+	res := api.Add(api.Mul(x, y), api.FromBinary(api.ToBinary(x, 8)...), api.IsZero(api.Sub(x, y)))
+	return []frontend.Variable{res}, nil
+})
+
+func gadgetValue(x, y int) int {
+	res := x*y + x
+	if x == y {
+		res++
+	}
+	return res
+}
+
+type templateCircuit struct {
+	X [3]frontend.Variable
+	Y [3]frontend.Variable `gnark:",public"`
+}
+
+func (c *templateCircuit) Define(api frontend.API) error {
+	for i := range c.X {
+		res, err := gadget.Instantiate(api, c.X[i], c.X[(i+1)%len(c.X)])
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(res[0], c.Y[i])
+	}
+
+	// constant inputs
+	res, err := gadget.Instantiate(api, c.X[0], 7)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res[0], api.Add(api.Mul(c.X[0], 8), api.IsZero(api.Sub(c.X[0], 7))))
+	res, err = gadget.Instantiate(api, 3, 3)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res[0], gadgetValue(3, 3))
+	return nil
+}
+
+func TestTemplate(t *testing.T) {
+	assert := require.New(t)
+
+	x := [3]int{7, 7, 200}
+	good := templateCircuit{X: [3]frontend.Variable{x[0], x[1], x[2]}}
+	bad := templateCircuit{X: good.X}
+	for i := range x {
+		good.Y[i] = gadgetValue(x[i], x[(i+1)%len(x)])
+		bad.Y[i] = good.Y[i]
+	}
+	bad.Y[1] = 0
+
+	field := ecc.BN254.ScalarField()
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		nbTemplateCalls = 0
+		ccs, err := frontend.Compile(field, newBuilder, &templateCircuit{})
+		assert.NoError(err)
+		assert.Equal(1, nbTemplateCalls, "the template is compiled once per builder")
+
+		// compiled templates are kept across compilations
+		_, err = frontend.Compile(field, newBuilder, &templateCircuit{})
+		assert.NoError(err)
+		assert.Equal(1, nbTemplateCalls)
+
+		w, err := frontend.NewWitness(&good, field)
+		assert.NoError(err)
+		assert.NoError(ccs.IsSolved(w))
+
+		w, err = frontend.NewWitness(&bad, field)
+		assert.NoError(err)
+		assert.Error(ccs.IsSolved(w))
+	}
+
+	// the test engine calls define on every instantiation
+	nbTemplateCalls = 0
+	assert.NoError(test.IsSolved(&templateCircuit{}, &good, field))
+	assert.Equal(5, nbTemplateCalls)
+}
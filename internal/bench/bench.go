@@ -0,0 +1,268 @@
+// Package bench proves synthetic circuits of given sizes with the Groth16 GPU provers, on each
+// accelerator provider, and records the durations of the prover stages, so that performance
+// regressions of the provers and of the providers are caught by comparing runs (see Compare).
+//
+// It backs cmd/bench and the benchmarks of its tests.
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/backend/envelope"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Curves are the curves with a GPU prover.
+var Curves = []ecc.ID{ecc.BN254, ecc.BLS12_377}
+
+// Circuit is a chain of multiplications, y = X·(X+1)·…·(X+n-2), of n constraints.
+type Circuit struct {
+	X frontend.Variable
+	n int
+}
+
+// NewCircuit returns a Circuit of 2^logSize constraints.
+func NewCircuit(logSize int) *Circuit {
+	return &Circuit{n: 1 << logSize}
+}
+
+// Define implements frontend.Circuit.
+func (c *Circuit) Define(api frontend.API) error {
+	y := c.X
+	for i := 1; i < c.n; i++ {
+		y = api.Mul(y, api.Add(c.X, i))
+	}
+	api.AssertIsDifferent(y, 0)
+	return nil
+}
+
+// System is a compiled Circuit and its witness.
+type System struct {
+	Curve   ecc.ID
+	LogSize int
+	CCS     constraint.ConstraintSystem
+	Witness witness.Witness
+
+	// Compile is the duration of the compilation of the circuit.
+	Compile time.Duration
+}
+
+// Compile compiles the Circuit of 2^logSize constraints on the curve.
+func Compile(curve ecc.ID, logSize int) (*System, error) {
+	if !hasGPUProver(curve) {
+		return nil, fmt.Errorf("%w: no GPU prover on %s", backend.ErrNotSupported, curve)
+	}
+	start := time.Now()
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, NewCircuit(logSize))
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+	w, err := frontend.NewWitness(&Circuit{X: 3}, curve.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	return &System{Curve: curve, LogSize: logSize, CCS: ccs, Witness: w, Compile: elapsed}, nil
+}
+
+// Result is the outcome of the benchmark of a System on an accelerator provider. The durations
+// are the ones of the fastest proof.
+type Result struct {
+	Curve         string                 `json:"curve"`
+	Accelerator   string                 `json:"accelerator"`
+	LogSize       int                    `json:"logSize"`
+	NbConstraints int                    `json:"nbConstraints"`
+	Compile       time.Duration          `json:"compile"`
+	Setup         time.Duration          `json:"setup"`
+	Prove         time.Duration          `json:"prove"`
+	Stages        []envelope.StageTiming `json:"stages"`
+}
+
+// Key identifies the benchmark of the result, to match it with the one of another run.
+func (r *Result) Key() string {
+	return fmt.Sprintf("%s/%s/2^%d", r.Curve, r.Accelerator, r.LogSize)
+}
+
+// Prove proves the System reps times, after a warm-up proof, on a device of the named
+// accelerator provider (see accel.OpenProvider), with the keys of groth16.DummySetup.
+func (s *System) Prove(accelerator string, reps int) (*Result, error) {
+	if reps < 1 {
+		return nil, errors.New("invalid number of runs")
+	}
+	start := time.Now()
+	pk, err := groth16.DummySetup(s.CCS)
+	if err != nil {
+		return nil, err
+	}
+	dpk := pk.(groth16.DeviceProvingKey)
+	if err := dpk.SetAccelerator(accelerator); err != nil {
+		return nil, err
+	}
+	if accelerator != cpu.Name {
+		// there is no way to free the device copies but to move them, to the host
+		defer dpk.SetAccelerator(cpu.Name)
+	}
+	res := &Result{
+		Curve:         s.Curve.String(),
+		Accelerator:   accelerator,
+		LogSize:       s.LogSize,
+		NbConstraints: s.CCS.GetNbConstraints(),
+		Compile:       s.Compile,
+		Setup:         time.Since(start),
+	}
+
+	for i := 0; i <= reps; i++ {
+		var timings envelope.Timings
+		start := time.Now()
+		if _, err := groth16.Prove(s.CCS, pk, s.Witness, backend.WithTracer(&timings)); err != nil {
+			return nil, err
+		}
+		elapsed := time.Since(start)
+		// the first proof warms the device up
+		if i == 1 || (i > 1 && elapsed < res.Prove) {
+			res.Prove, res.Stages = elapsed, timings.Stages()
+		}
+	}
+	return res, nil
+}
+
+// Run compiles the Circuit of each size and proves it on each of the accelerator providers,
+// all the providers of the curve (see accel.Providers) if there are none.
+func Run(curve ecc.ID, accelerators []string, logSizes []int, reps int) ([]Result, error) {
+	if len(accelerators) == 0 {
+		accelerators = accel.Providers(curve)
+	}
+	var results []Result
+	for _, logSize := range logSizes {
+		s, err := Compile(curve, logSize)
+		if err != nil {
+			return results, err
+		}
+		for _, a := range accelerators {
+			res, err := s.Prove(a, reps)
+			if err != nil {
+				return results, fmt.Errorf("%s 2^%d: %w", a, logSize, err)
+			}
+			results = append(results, *res)
+		}
+	}
+	return results, nil
+}
+
+// StageDurations returns the total duration of each stage of the result, by stage and label
+// (for instance "MSM G1 KRS"), and of the proof, under "prove".
+func (r *Result) StageDurations() map[string]time.Duration {
+	d := map[string]time.Duration{"prove": r.Prove}
+	for _, s := range r.Stages {
+		key := s.Stage
+		if s.Label != "" {
+			key += " " + s.Label
+		}
+		if key != "prove" {
+			d[key] += s.Duration
+		}
+	}
+	return d
+}
+
+// MinDuration is the duration under which the stages of the baseline are not compared, their
+// variations being noise.
+const MinDuration = 10 * time.Millisecond
+
+// Regression is a stage slower than in the baseline.
+type Regression struct {
+	Key      string        `json:"key"`
+	Stage    string        `json:"stage"`
+	Baseline time.Duration `json:"baseline"`
+	Current  time.Duration `json:"current"`
+}
+
+// Ratio returns the duration of the stage relative to the baseline.
+func (r Regression) Ratio() float64 {
+	return float64(r.Current) / float64(r.Baseline)
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s: %s -> %s (%+.1f%%)", r.Key, r.Stage, r.Baseline, r.Current, 100*(r.Ratio()-1))
+}
+
+// Compare returns the stages of the results slower than in the results of the baseline with
+// the same key by more than tolerance, relative (0.1 is 10%). The stages shorter than
+// MinDuration in the baseline and the results without a baseline are ignored.
+func Compare(baseline, current []Result, tolerance float64) []Regression {
+	base := make(map[string]map[string]time.Duration, len(baseline))
+	for i := range baseline {
+		base[baseline[i].Key()] = baseline[i].StageDurations()
+	}
+	var regressions []Regression
+	for i := range current {
+		key := current[i].Key()
+		b, ok := base[key]
+		if !ok {
+			continue
+		}
+		c := current[i].StageDurations()
+		stages := make([]string, 0, len(c))
+		for stage := range c {
+			stages = append(stages, stage)
+		}
+		sort.Strings(stages)
+		for _, stage := range stages {
+			if b[stage] < MinDuration {
+				continue
+			}
+			if float64(c[stage]) > float64(b[stage])*(1+tolerance) {
+				regressions = append(regressions, Regression{Key: key, Stage: stage, Baseline: b[stage], Current: c[stage]})
+			}
+		}
+	}
+	return regressions
+}
+
+// ParseSizes parses a list of log₂ of circuit sizes, comma separated values or ranges, for
+// instance "16,18" or "16-26".
+func ParseSizes(s string) ([]int, error) {
+	var res []int
+	for _, f := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(f), "-")
+		a, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, err
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(to); err != nil {
+				return nil, err
+			}
+		}
+		if a <= 0 || b < a {
+			return nil, fmt.Errorf("invalid size %q", f)
+		}
+		for i := a; i <= b; i++ {
+			res = append(res, i)
+		}
+	}
+	return res, nil
+}
+
+func hasGPUProver(curve ecc.ID) bool {
+	for _, c := range Curves {
+		if c == curve {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/backend/envelope"
+	"github.com/stretchr/testify/require"
+)
+
+// sizesEnvVar is the environment variable of the sizes of BenchmarkProve, as parsed by
+// ParseSizes, for instance GNARK_BENCH_SIZES=16-26.
+const sizesEnvVar = "GNARK_BENCH_SIZES"
+
+func TestProve(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := Compile(ecc.BN254, 8)
+	assert.NoError(err)
+	assert.Equal(1<<8, s.CCS.GetNbConstraints())
+
+	res, err := s.Prove(cpu.Name, 1)
+	assert.NoError(err)
+	assert.Equal("bn254/cpu/2^8", res.Key())
+	assert.Positive(res.Prove)
+	d := res.StageDurations()
+	assert.Equal(res.Prove, d["prove"])
+	assert.Contains(d, "solve")
+	assert.Contains(d, "computeH")
+
+	// the results are machine readable
+	b, err := json.Marshal(res)
+	assert.NoError(err)
+	var decoded Result
+	assert.NoError(json.Unmarshal(b, &decoded))
+	assert.Equal(*res, decoded)
+
+	_, err = Compile(ecc.BLS12_381, 8)
+	assert.Error(err)
+}
+
+func TestCompare(t *testing.T) {
+	assert := require.New(t)
+
+	result := func(prove, msm time.Duration) Result {
+		return Result{
+			Curve: "bn254", Accelerator: cpu.Name, LogSize: 20, Prove: prove,
+			Stages: []envelope.StageTiming{
+				{Stage: "MSM G1", Label: "KRS", Duration: msm},
+				{Stage: "NTT", Label: "a", Duration: time.Millisecond},
+			},
+		}
+	}
+	baseline := []Result{result(time.Second, 500*time.Millisecond)}
+
+	assert.Empty(Compare(baseline, []Result{result(time.Second, 540*time.Millisecond)}, 0.1))
+
+	regressions := Compare(baseline, []Result{result(1200*time.Millisecond, 700*time.Millisecond)}, 0.1)
+	assert.Len(regressions, 2)
+	assert.Equal("MSM G1 KRS", regressions[0].Stage)
+	assert.InDelta(1.4, regressions[0].Ratio(), 1e-9)
+	assert.Equal("prove", regressions[1].Stage)
+
+	// the stages under MinDuration and the results without a baseline are ignored
+	slow := result(time.Second, 500*time.Millisecond)
+	slow.Stages[1].Duration = MinDuration / 2
+	assert.Empty(Compare(baseline, []Result{slow}, 0.1))
+	slow.LogSize = 22
+	slow.Prove = 10 * time.Second
+	assert.Empty(Compare(baseline, []Result{slow}, 0.1))
+}
+
+func TestParseSizes(t *testing.T) {
+	assert := require.New(t)
+
+	sizes, err := ParseSizes("16-18, 20,22")
+	assert.NoError(err)
+	assert.Equal([]int{16, 17, 18, 20, 22}, sizes)
+
+	for _, s := range []string{"", "a", "0", "18-16", "16-"} {
+		_, err = ParseSizes(s)
+		assert.Error(err, s)
+	}
+}
+
+// BenchmarkProve proves the Circuit of each size on each of the accelerator providers of the
+// curves with a GPU prover, and reports the durations of the stages of the proofs:
+//
+//	GNARK_BENCH_SIZES=16-26 go test -run none -bench Prove ./internal/bench
+func BenchmarkProve(b *testing.B) {
+	logSizes := []int{12, 14}
+	if s := os.Getenv(sizesEnvVar); s != "" {
+		var err error
+		if logSizes, err = ParseSizes(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for _, curve := range Curves {
+		for _, logSize := range logSizes {
+			s, err := Compile(curve, logSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, a := range accel.Providers(curve) {
+				b.Run(fmt.Sprintf("%s/%s/2^%d", curve, a, logSize), func(b *testing.B) {
+					res, err := s.Prove(a, b.N)
+					if err != nil {
+						b.Fatal(err)
+					}
+					// ns/op is the fastest proof, without the setup and the warm-up
+					b.ReportMetric(float64(res.Prove.Nanoseconds()), "ns/op")
+					for stage, d := range res.StageDurations() {
+						if stage != "prove" {
+							b.ReportMetric(float64(d.Nanoseconds()), strings.ReplaceAll(stage, " ", "_")+"-ns")
+						}
+					}
+				})
+			}
+		}
+	}
+}
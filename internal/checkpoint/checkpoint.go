@@ -0,0 +1,168 @@
+// Package checkpoint stores the intermediate results of a long computation in a directory,
+// so that a process killed in the middle resumes from them instead of starting over.
+//
+// The results are bound to a key, for instance a hash of the inputs of the computation: the
+// results of another key are removed when the directory is opened. Each result is a file
+// written atomically, with a SHA-256 checksum: the truncated or corrupted results are
+// ignored, as if they were missing.
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	keyFile = "checkpoint.key"
+	ext     = ".ckpt"
+)
+
+// Dir is a directory of results.
+type Dir struct {
+	path string
+}
+
+// Open returns the directory at path, created if needed, for the results of the key. The
+// results of a different key are removed.
+func Open(path string, key []byte) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	d := &Dir{path: path}
+	previous, err := os.ReadFile(filepath.Join(path, keyFile))
+	if err == nil && bytes.Equal(previous, key) {
+		return d, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := d.removeResults(); err != nil {
+		return nil, err
+	}
+	if err := writeFile(filepath.Join(path, keyFile), func(w io.Writer) error {
+		_, err := w.Write(key)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Has returns true if the directory has the named result, without checking it.
+func (d *Dir) Has(name string) bool {
+	_, err := os.Stat(d.file(name))
+	return err == nil
+}
+
+// Save stores the named result, written by w.
+func (d *Dir) Save(name string, w io.WriterTo) error {
+	return writeFile(d.file(name), func(f io.Writer) error {
+		h := sha256.New()
+		if _, err := w.WriteTo(io.MultiWriter(f, h)); err != nil {
+			return err
+		}
+		_, err := f.Write(h.Sum(nil))
+		return err
+	})
+}
+
+// Load reads the named result with r. It returns false if the result is missing, or if its
+// checksum doesn't match, in which case r may have read a part of it.
+func (d *Dir) Load(name string, r io.ReaderFrom) (bool, error) {
+	f, err := os.Open(d.file(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < sha256.Size {
+		return false, nil
+	}
+
+	h := sha256.New()
+	br := bufio.NewReaderSize(f, 1<<20)
+	data := io.TeeReader(io.LimitReader(br, info.Size()-sha256.Size), h)
+	if _, err := r.ReadFrom(data); err != nil {
+		return false, nil
+	}
+	// the reader may not consume all the data
+	if _, err := io.Copy(io.Discard, data); err != nil {
+		return false, err
+	}
+	var sum [sha256.Size]byte
+	if _, err := io.ReadFull(br, sum[:]); err != nil {
+		return false, err
+	}
+	return bytes.Equal(sum[:], h.Sum(nil)), nil
+}
+
+// Remove removes the results and the key. The directory itself is kept.
+func (d *Dir) Remove() error {
+	if err := d.removeResults(); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(d.path, keyFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (d *Dir) file(name string) string {
+	return filepath.Join(d.path, name+ext)
+}
+
+func (d *Dir) removeResults() error {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ext) || strings.HasSuffix(e.Name(), ext+".tmp") {
+			if err := os.Remove(filepath.Join(d.path, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file atomically: to a temporary file synced and renamed.
+func writeFile(path string, write func(io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("checkpoint %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -24,6 +24,8 @@ func main() {
 		CSPath:   "../../../constraint/bls12-377/",
 		Curve:    "BLS12-377",
 		CurveID:  "BLS12_377",
+		// the GPU prover
+		handWritten: []string{"groth16/marshal.go", "groth16/prove.go", "groth16/setup.go"},
 	}
 	bls12_381 := templateData{
 		RootPath: "../../../backend/{?}/bls12-381/",
@@ -36,6 +38,11 @@ func main() {
 		CSPath:   "../../../constraint/bn254/",
 		Curve:    "BN254",
 		CurveID:  "BN254",
+		// the GPU prover, the several commitments and the device ceremony
+		handWritten: []string{
+			"groth16/commitment_test.go", "groth16/marshal.go", "groth16/prove.go", "groth16/setup.go", "groth16/verify.go",
+			"groth16/mpcsetup",
+		},
 	}
 	bw6_761 := templateData{
 		RootPath: "../../../backend/{?}/bw6-761/",
@@ -157,14 +164,14 @@ func main() {
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
 			}
-			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
+			if err := d.generate("groth16", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
 			}
 
 			entries = []bavard.Entry{
 				{File: filepath.Join(groth16Dir, "commitment_test.go"), Templates: []string{"groth16/tests/groth16.commitment.go.tmpl", importCurve}},
 			}
-			if err := bgen.Generate(d, "groth16_test", "./template/zkpschemes/", entries...); err != nil {
+			if err := d.generate("groth16_test", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
 			}
 
@@ -180,7 +187,7 @@ func main() {
 				{File: filepath.Join(groth16MpcSetupDir, "utils.go"), Templates: []string{"groth16/mpcsetup/utils.go.tmpl", importCurve}},
 			}
 
-			if err := bgen.Generate(d, "mpcsetup", "./template/zkpschemes/", entries...); err != nil {
+			if err := d.generate("mpcsetup", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
 			}
 
@@ -239,4 +246,35 @@ type templateData struct {
 	Curve     string
 	CurveID   string
 	noBackend bool
+
+	// handWritten are the files and directories of the backends which diverged from the
+	// templates and are maintained by hand, relative to the backends directory, for instance
+	// "groth16/prove.go" for RootPath with {?} replaced by groth16.
+	handWritten []string
+}
+
+// generate generates the entries as bgen.Generate, but the hand written ones.
+func (d templateData) generate(packageName, baseTmplDir string, entries ...bavard.Entry) error {
+	var toGenerate []bavard.Entry
+	for _, e := range entries {
+		if !d.isHandWritten(e.File) {
+			toGenerate = append(toGenerate, e)
+		}
+	}
+	if len(toGenerate) == 0 {
+		return nil
+	}
+	return bgen.Generate(d, packageName, baseTmplDir, toGenerate...)
+}
+
+func (d templateData) isHandWritten(file string) bool {
+	file = filepath.Clean(file)
+	for _, h := range d.handWritten {
+		scheme, rest, _ := strings.Cut(h, "/")
+		path := filepath.Join(strings.Replace(d.RootPath, "{?}", scheme, 1), rest)
+		if file == path || strings.HasPrefix(file, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,30 @@
+// Package nvtx annotates the GPU provers with NVTX ranges, which Nsight Systems shows on its
+// timeline above the kernels they launch.
+//
+// The ranges are emitted by the binaries built with the nvtx build tag (which requires the
+// NVTX 3 headers of the CUDA toolkit in the include path, for instance with
+// CGO_CFLAGS=-I/usr/local/cuda/include), when the GNARK_NVTX environment variable is 1.
+// Otherwise, ranges cost a branch.
+package nvtx
+
+import "os"
+
+// EnvVar is the environment variable enabling the ranges.
+const EnvVar = "GNARK_NVTX"
+
+var enabled = supported && os.Getenv(EnvVar) == "1"
+
+// Enabled returns true if the ranges are emitted.
+func Enabled() bool {
+	return enabled
+}
+
+// Range starts a range of the given name, and returns the function ending it. Ranges are
+// process-wide, so that they can start and end on different goroutines or threads.
+func Range(name string) (end func()) {
+	if !enabled {
+		return func() {}
+	}
+	id := rangeStart(name)
+	return func() { rangeEnd(id) }
+}
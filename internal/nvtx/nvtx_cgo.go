@@ -0,0 +1,24 @@
+//go:build nvtx
+
+package nvtx
+
+/*
+#cgo LDFLAGS: -ldl
+#include <stdlib.h>
+#include <nvtx3/nvToolsExt.h>
+*/
+import "C"
+
+import "unsafe"
+
+const supported = true
+
+func rangeStart(name string) uint64 {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	return uint64(C.nvtxRangeStartA(cName))
+}
+
+func rangeEnd(id uint64) {
+	C.nvtxRangeEnd(C.nvtxRangeId_t(id))
+}
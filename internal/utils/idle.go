@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// IdleWindow is the duration over which IdleNbTasks measures the idle CPUs.
+var IdleWindow = 10 * time.Millisecond
+
+// IdleCPUs estimates the number of CPUs the process leaves idle, out of GOMAXPROCS (or
+// runtime.NumCPU() if lower), from the CPU time it consumes over window. All the threads of
+// the process count, including the ones running cgo calls (e.g. a GPU driver spinning on a
+// kernel).
+//
+// The CPU classes of runtime/metrics are only refreshed by the garbage collector, which is why
+// the process CPU time is read from the operating system instead. ok is false if it isn't
+// available on this platform.
+func IdleCPUs(window time.Duration) (idle float64, ok bool) {
+	start := time.Now()
+	cpu0, ok := processCPUTime()
+	if !ok {
+		return 0, false
+	}
+	time.Sleep(window)
+	cpu1, _ := processCPUTime()
+	return idleCPUs(nbProcs(), cpu1-cpu0, time.Since(start)), true
+}
+
+// nbProcs returns the number of CPUs the process can run on at once.
+func nbProcs() int {
+	n := runtime.GOMAXPROCS(0)
+	if nbCPUs := runtime.NumCPU(); nbCPUs < n {
+		n = nbCPUs
+	}
+	return n
+}
+
+// idleCPUs returns the number of CPUs out of nbProcs left idle by a process which consumed
+// cpu time over elapsed.
+func idleCPUs(nbProcs int, cpu, elapsed time.Duration) float64 {
+	busy := float64(cpu) / float64(elapsed)
+	idle := float64(nbProcs) - busy
+	if idle < 0 {
+		idle = 0
+	} else if idle > float64(nbProcs) {
+		idle = float64(nbProcs)
+	}
+	return idle
+}
+
+var (
+	idleOnce    sync.Once
+	idleNbTasks int
+)
+
+// IdleNbTasks returns a number of tasks for a CPU-bound computation, sized on the CPUs idle
+// (see IdleCPUs) the first time it is called, and at least 1. The CPUs are sampled once, so
+// that the following computations don't wait IdleWindow, and the result is cached for the
+// lifetime of the process. It returns runtime.NumCPU() if the idle CPUs can't be measured.
+func IdleNbTasks() int {
+	idleOnce.Do(func() {
+		idleNbTasks = nbTasksOf(IdleCPUs(IdleWindow))
+	})
+	return idleNbTasks
+}
+
+// nbTasksOf returns the number of tasks for idle CPUs, see IdleNbTasks.
+func nbTasksOf(idle float64, ok bool) int {
+	if !ok {
+		return runtime.NumCPU()
+	}
+	nbTasks := int(idle + 0.5)
+	if nbTasks < 1 {
+		nbTasks = 1
+	}
+	return nbTasks
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIdleCPUs(t *testing.T) {
+	for _, c := range []struct {
+		nbProcs       int
+		cpu, elapsed  time.Duration
+		expectedIdle  float64
+		expectedTasks int
+	}{
+		{4, 0, time.Second, 4, 4},
+		{4, time.Second, time.Second, 3, 3},
+		{4, 2500 * time.Millisecond, time.Second, 1.5, 2},
+		{4, 4 * time.Second, time.Second, 0, 1},
+		// the threads of cgo calls may run on more CPUs than GOMAXPROCS
+		{4, 6 * time.Second, time.Second, 0, 1},
+		{1, 0, time.Second, 1, 1},
+	} {
+		idle := idleCPUs(c.nbProcs, c.cpu, c.elapsed)
+		if idle != c.expectedIdle {
+			t.Fatalf("%d CPUs busy %s over %s: expected %f idle CPUs, got %f", c.nbProcs, c.cpu, c.elapsed, c.expectedIdle, idle)
+		}
+		if n := nbTasksOf(idle, true); n != c.expectedTasks {
+			t.Fatalf("%f idle CPUs: expected %d tasks, got %d", idle, c.expectedTasks, n)
+		}
+	}
+	if n := nbTasksOf(0, false); n != runtime.NumCPU() {
+		t.Fatalf("unmeasured idle CPUs: expected %d tasks, got %d", runtime.NumCPU(), n)
+	}
+
+	// the measure is clamped to the CPUs of the process
+	if idle, ok := IdleCPUs(time.Millisecond); ok && (idle < 0 || idle > float64(nbProcs())) {
+		t.Fatalf("idle CPUs %f out of [0, %d]", idle, nbProcs())
+	}
+
+	// the tasks are sampled once
+	n := IdleNbTasks()
+	if n < 1 {
+		t.Fatalf("IdleNbTasks returned %d", n)
+	}
+	if m := IdleNbTasks(); m != n {
+		t.Fatalf("IdleNbTasks isn't cached: %d then %d", n, m)
+	}
+}
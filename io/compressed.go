@@ -0,0 +1,348 @@
+package io
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// Codec compresses the chunks of the compressed format of WriteCompressed.
+type Codec struct {
+	// Name identifies the codec in the compressed data.
+	Name string
+
+	// Compress appends the compression of src to dst, and Decompress the decompression.
+	Compress, Decompress func(dst, src []byte) ([]byte, error)
+}
+
+// CodecFlate is the name of the codec of compress/flate, registered by default.
+const CodecFlate = "flate"
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{CodecFlate: {Name: CodecFlate, Compress: flateCompress, Decompress: flateDecompress}}}
+
+// RegisterCodec registers a codec for WriteCompressed and ReadCompressed, replacing the codec
+// of the same name if any. For instance, the applications depending on a zstd implementation
+// register it as "zstd", which decompresses much faster than flate.
+func RegisterCodec(c Codec) {
+	codecs.Lock()
+	codecs.m[c.Name] = c
+	codecs.Unlock()
+}
+
+func lookupCodec(name string) (Codec, error) {
+	codecs.RLock()
+	c, ok := codecs.m[name]
+	codecs.RUnlock()
+	if !ok {
+		return Codec{}, fmt.Errorf("unknown codec %q: see RegisterCodec", name)
+	}
+	return c, nil
+}
+
+func flateCompress(dst, src []byte) ([]byte, error) {
+	b := bytes.NewBuffer(dst)
+	w, err := flate.NewWriter(b, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func flateDecompress(dst, src []byte) ([]byte, error) {
+	b := bytes.NewBuffer(dst)
+	r := flate.NewReader(bytes.NewReader(src))
+	if _, err := b.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), r.Close()
+}
+
+// compressedMagic starts the compressed data, with the version of the format.
+var compressedMagic = [8]byte{'g', 'n', 'a', 'r', 'k', 'z', 0, 1}
+
+// chunkSize is the size of the uncompressed chunks.
+var chunkSize = 16 << 20
+
+// IsCompressed returns true if the data, of at least 8 bytes, starts like the output of
+// WriteCompressed.
+func IsCompressed(header []byte) bool {
+	return bytes.HasPrefix(header, compressedMagic[:])
+}
+
+// WriteCompressed writes the raw encoding of v (see WriterRawTo) in chunks compressed with the
+// codec, in parallel, and returns the number of bytes written to w.
+//
+// The format is the magic, the name of the codec and the size of the chunks, followed by the
+// chunks, each with its uncompressed size, its compressed size and the CRC-32C of the
+// compressed data, and by a chunk of size 0. The chunks are decompressed in parallel too by
+// ReadCompressed.
+func WriteCompressed(w io.Writer, v WriterRawTo, codec string) (int64, error) {
+	c, err := lookupCodec(codec)
+	if err != nil {
+		return 0, err
+	}
+	if len(c.Name) > 255 {
+		return 0, errors.New("codec name too long")
+	}
+
+	header := make([]byte, 0, len(compressedMagic)+1+len(c.Name)+4)
+	header = append(header, compressedMagic[:]...)
+	header = append(header, byte(len(c.Name)))
+	header = append(header, c.Name...)
+	header = binary.BigEndian.AppendUint32(header, uint32(chunkSize))
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+
+	cw := newChunkWriter(w, c)
+	cw.n = int64(n)
+	_, err = v.WriteRawTo(cw)
+	if errClose := cw.Close(); err == nil {
+		err = errClose
+	}
+	return cw.n, err
+}
+
+// chunk is a compressed chunk, or the error compressing or decompressing it.
+type chunk struct {
+	rawSize int
+	data    []byte
+	err     error
+}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// chunkWriter compresses the chunks written in parallel, and writes them in order to w.
+type chunkWriter struct {
+	w     io.Writer
+	codec Codec
+	buf   []byte
+
+	// the chunks being compressed, in order; the capacity bounds the chunks in memory
+	chunks  chan chan chunk
+	stopped chan struct{}
+
+	// set by the goroutine writing the chunks, read once stopped
+	n   int64
+	err error
+}
+
+func newChunkWriter(w io.Writer, codec Codec) *chunkWriter {
+	cw := &chunkWriter{
+		w:       w,
+		codec:   codec,
+		buf:     make([]byte, 0, chunkSize),
+		chunks:  make(chan chan chunk, runtime.NumCPU()),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(cw.stopped)
+		var frame [12]byte
+		for res := range cw.chunks {
+			c := <-res
+			if cw.err != nil {
+				continue // the remaining chunks are dropped
+			}
+			if c.err != nil {
+				cw.err = c.err
+				continue
+			}
+			binary.BigEndian.PutUint32(frame[0:4], uint32(c.rawSize))
+			binary.BigEndian.PutUint32(frame[4:8], uint32(len(c.data)))
+			binary.BigEndian.PutUint32(frame[8:12], crc32.Checksum(c.data, crcTable))
+			cw.err = cw.write(frame[:], c.data)
+		}
+	}()
+	return cw
+}
+
+func (cw *chunkWriter) write(data ...[]byte) error {
+	for _, d := range data {
+		n, err := cw.w.Write(d)
+		cw.n += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		m := copy(cw.buf[len(cw.buf):cap(cw.buf)], p)
+		cw.buf, p = cw.buf[:len(cw.buf)+m], p[m:]
+		if len(cw.buf) == cap(cw.buf) {
+			cw.flush()
+		}
+	}
+	return written, nil
+}
+
+// flush compresses the current chunk in the background.
+func (cw *chunkWriter) flush() {
+	res := make(chan chunk, 1)
+	cw.chunks <- res
+	go func(raw []byte) {
+		data, err := cw.codec.Compress(nil, raw)
+		res <- chunk{rawSize: len(raw), data: data, err: err}
+	}(cw.buf)
+	cw.buf = make([]byte, 0, chunkSize)
+}
+
+// Close writes the last chunks and the end of the data, and returns the first error writing
+// or compressing a chunk.
+func (cw *chunkWriter) Close() error {
+	if len(cw.buf) > 0 {
+		cw.flush()
+	}
+	close(cw.chunks)
+	<-cw.stopped
+	if cw.err != nil {
+		return cw.err
+	}
+	var end [12]byte
+	return cw.write(end[:])
+}
+
+// ReadCompressed reads v (see io.ReaderFrom) from the output of WriteCompressed, decompressing
+// the chunks in parallel, and returns the number of bytes read from r.
+func ReadCompressed(r io.Reader, v io.ReaderFrom) (int64, error) {
+	var header [len(compressedMagic) + 1]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if !IsCompressed(header[:]) {
+		return int64(len(header)), errors.New("not compressed data, or unsupported version")
+	}
+	nameAndSize := make([]byte, int(header[len(compressedMagic)])+4)
+	if _, err := io.ReadFull(r, nameAndSize); err != nil {
+		return int64(len(header)), err
+	}
+	n := int64(len(header) + len(nameAndSize))
+	c, err := lookupCodec(string(nameAndSize[:len(nameAndSize)-4]))
+	if err != nil {
+		return n, err
+	}
+	maxChunkSize := int(binary.BigEndian.Uint32(nameAndSize[len(nameAndSize)-4:]))
+
+	cr := &chunkReader{
+		chunks:  make(chan chan chunk, runtime.NumCPU()),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		n:       n,
+	}
+	go cr.readChunks(r, c, maxChunkSize)
+	_, err = v.ReadFrom(cr)
+	close(cr.stop)
+	<-cr.stopped
+	return cr.n, err
+}
+
+// chunkReader reads the chunks, decompressed in parallel, in order.
+type chunkReader struct {
+	chunks        chan chan chunk
+	stop, stopped chan struct{}
+
+	// set by the goroutine reading the chunks, read once chunks is closed
+	n   int64
+	err error
+
+	cur    []byte
+	errCur error
+}
+
+// readChunks reads the chunks of r, and decompresses them in the background, until the end of
+// the data or stop is closed.
+func (cr *chunkReader) readChunks(r io.Reader, c Codec, maxChunkSize int) {
+	defer close(cr.stopped)
+	defer close(cr.chunks)
+	var frame [12]byte
+	for {
+		m, err := io.ReadFull(r, frame[:])
+		cr.n += int64(m)
+		if err != nil {
+			cr.err = unexpectedEOF(err)
+			return
+		}
+		rawSize := int(binary.BigEndian.Uint32(frame[0:4]))
+		size := int(binary.BigEndian.Uint32(frame[4:8]))
+		crc := binary.BigEndian.Uint32(frame[8:12])
+		if rawSize == 0 {
+			return
+		}
+		// the codecs may expand incompressible data a bit
+		if rawSize > maxChunkSize || size > 2*maxChunkSize+1024 {
+			cr.err = errors.New("invalid compressed chunk size")
+			return
+		}
+		data := make([]byte, size)
+		m, err = io.ReadFull(r, data)
+		cr.n += int64(m)
+		if err != nil {
+			cr.err = unexpectedEOF(err)
+			return
+		}
+
+		res := make(chan chunk, 1)
+		select {
+		case cr.chunks <- res:
+		case <-cr.stop:
+			return
+		}
+		go func() {
+			if crc32.Checksum(data, crcTable) != crc {
+				res <- chunk{err: errors.New("corrupted compressed chunk")}
+				return
+			}
+			raw, err := c.Decompress(make([]byte, 0, rawSize), data)
+			if err == nil && len(raw) != rawSize {
+				err = errors.New("corrupted compressed chunk")
+			}
+			res <- chunk{data: raw, err: err}
+		}()
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.cur) == 0 {
+		if cr.errCur != nil {
+			return 0, cr.errCur
+		}
+		res, ok := <-cr.chunks
+		if !ok {
+			if cr.errCur = cr.err; cr.errCur == nil {
+				cr.errCur = io.EOF
+			}
+			continue
+		}
+		c := <-res
+		cr.cur, cr.errCur = c.data, c.err
+	}
+	n := copy(p, cr.cur)
+	cr.cur = cr.cur[n:]
+	return n, nil
+}
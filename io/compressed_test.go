@@ -0,0 +1,80 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rawBytes encodes and decodes itself as is.
+type rawBytes []byte
+
+func (b rawBytes) WriteRawTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (b *rawBytes) ReadFrom(r io.Reader) (int64, error) {
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(r)
+	*b = buf.Bytes()
+	return n, err
+}
+
+func TestCompressed(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(size int) { chunkSize = size }(chunkSize)
+	chunkSize = 1000
+
+	// compressible and incompressible data, spanning several chunks or not
+	data := make([]byte, 10500)
+	rand.New(rand.NewSource(0)).Read(data[5000:])
+	RegisterCodec(Codec{
+		Name:       "none",
+		Compress:   func(dst, src []byte) ([]byte, error) { return append(dst, src...), nil },
+		Decompress: func(dst, src []byte) ([]byte, error) { return append(dst, src...), nil },
+	})
+
+	for _, codec := range []string{CodecFlate, "none"} {
+		for _, size := range []int{0, 1, 999, 1000, 1001, len(data)} {
+			var buf bytes.Buffer
+			written, err := WriteCompressed(&buf, rawBytes(data[:size]), codec)
+			assert.NoError(err)
+			assert.Equal(int64(buf.Len()), written)
+			assert.True(IsCompressed(buf.Bytes()))
+
+			var read rawBytes
+			n, err := ReadCompressed(bytes.NewReader(buf.Bytes()), &read)
+			assert.NoError(err)
+			assert.Equal(written, n)
+			assert.Equal(data[:size], []byte(read))
+		}
+	}
+
+	var buf bytes.Buffer
+	_, err := WriteCompressed(&buf, rawBytes(data), CodecFlate)
+	assert.NoError(err)
+	compressed := buf.Bytes()
+
+	// corrupted or truncated data
+	corrupted := append([]byte(nil), compressed...)
+	corrupted[len(corrupted)/2] ^= 1
+	var read rawBytes
+	_, err = ReadCompressed(bytes.NewReader(corrupted), &read)
+	assert.Error(err)
+	_, err = ReadCompressed(bytes.NewReader(compressed[:len(compressed)-12]), &read)
+	assert.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	// the errors compressing are returned
+	RegisterCodec(Codec{
+		Name:     "failing",
+		Compress: func(dst, src []byte) ([]byte, error) { return nil, errors.New("failing") },
+	})
+	_, err = WriteCompressed(&buf, rawBytes(data), "failing")
+	assert.Error(err)
+}
@@ -0,0 +1,213 @@
+// Package options gathers the settings of the phases of gnark, compiling, setting up, proving and
+// verifying, in a Config from which each phase takes its own options, so that the settings
+// common to several phases are given once, and can be overridden by environment variables in
+// containerized deployments.
+//
+//	cfg, err := options.New(options.WithAccelerator("icicle"), options.WithNbWorkers(16))
+//	cfg.Apply()
+//	ccs, err := frontend.Compile(field, r1cs.NewBuilder, &circuit, cfg.CompileOptions()...)
+//	pk, vk, err := groth16.Setup(ccs, cfg.SetupOptions()...)
+//	proof, err := groth16.Prove(ccs, pk, witness, cfg.ProverOptions()...)
+//
+// The verifiers take no options: only Apply, setting the log level, affects them.
+package options
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accel"
+	"github.com/consensys/gnark/backend/accel/cpu"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/rs/zerolog"
+)
+
+// The environment variables overriding the options of New. Their empty values are ignored.
+const (
+	// EnvAccelerator overrides WithAccelerator. It is also read by the accel package when no
+	// accelerator is given.
+	EnvAccelerator = accel.EnvVar
+
+	// EnvNbWorkers overrides WithNbWorkers, with a number.
+	EnvNbWorkers = "GNARK_NB_WORKERS"
+
+	// EnvDeviceMemoryLimit overrides WithDeviceMemoryLimit, with a number of bytes.
+	EnvDeviceMemoryLimit = "GNARK_DEVICE_MEMORY_LIMIT"
+
+	// EnvLogLevel overrides WithLogLevel, with a zerolog level, for instance "debug".
+	EnvLogLevel = "GNARK_LOG_LEVEL"
+)
+
+// Config is the configuration of the phases with the options applied. See the options for the
+// details of the fields.
+type Config struct {
+	Accelerator       string
+	NbWorkers         int
+	DeviceMemoryLimit uint64
+
+	// LogLevel is the level of the logger of gnark set by Apply, if SetLogLevel is true.
+	LogLevel    zerolog.Level
+	SetLogLevel bool
+
+	// the options of a single phase, following the options of the settings above
+	Compile []frontend.CompileOption
+	Setup   []backend.SetupOption
+	Prover  []backend.ProverOption
+}
+
+// Option defines an option of New. See the descriptions of the functions returning instances
+// of this type for the available options.
+type Option func(*Config) error
+
+// New returns the Config with the options applied, and then the environment variables, so that
+// a deployment can override the settings of the code.
+func New(opts ...Option) (Config, error) {
+	var cfg Config
+	for _, option := range opts {
+		if err := option(&cfg); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) applyEnv() error {
+	if v := os.Getenv(EnvAccelerator); v != "" {
+		cfg.Accelerator = v
+	}
+	if v := os.Getenv(EnvNbWorkers); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: invalid number of workers %q", EnvNbWorkers, v)
+		}
+		cfg.NbWorkers = n
+	}
+	if v := os.Getenv(EnvDeviceMemoryLimit); v != "" {
+		limit, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number of bytes %q", EnvDeviceMemoryLimit, v)
+		}
+		cfg.DeviceMemoryLimit = limit
+	}
+	if v := os.Getenv(EnvLogLevel); v != "" {
+		level, err := zerolog.ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvLogLevel, err)
+		}
+		cfg.LogLevel, cfg.SetLogLevel = level, true
+	}
+	return nil
+}
+
+// WithAccelerator sets the accelerator provider of the backend/accel registry the proving keys
+// are copied to by the setup, and the proofs must run on. See backend.WithSetupAccelerator and
+// backend.WithAccelerator.
+func WithAccelerator(name string) Option {
+	return func(cfg *Config) error {
+		cfg.Accelerator = name
+		return nil
+	}
+}
+
+// WithNbWorkers sets the number of CPU workers of the compilation, and of tasks of the CPU
+// multi-exponentiation of the prover, or zero for the defaults. See frontend.WithNbWorkers and
+// backend.WithMultiExpNbTasks. The number of tasks isn't given to the provers when the
+// accelerator is set to a provider other than the CPU emulation, whose devices don't run the
+// multi-exponentiations on CPU tasks.
+func WithNbWorkers(nbWorkers int) Option {
+	return func(cfg *Config) error {
+		if nbWorkers < 0 {
+			return fmt.Errorf("invalid number of workers %d", nbWorkers)
+		}
+		cfg.NbWorkers = nbWorkers
+		return nil
+	}
+}
+
+// WithDeviceMemoryLimit sets the device memory a proof may need, in bytes, or zero for no limit.
+// See backend.WithDeviceMemoryLimit.
+func WithDeviceMemoryLimit(limit uint64) Option {
+	return func(cfg *Config) error {
+		cfg.DeviceMemoryLimit = limit
+		return nil
+	}
+}
+
+// WithLogLevel sets the level of the logger of gnark, set by Config.Apply.
+func WithLogLevel(level zerolog.Level) Option {
+	return func(cfg *Config) error {
+		cfg.LogLevel, cfg.SetLogLevel = level, true
+		return nil
+	}
+}
+
+// WithCompileOptions adds options of the compilation only.
+func WithCompileOptions(opts ...frontend.CompileOption) Option {
+	return func(cfg *Config) error {
+		cfg.Compile = append(cfg.Compile, opts...)
+		return nil
+	}
+}
+
+// WithSetupOptions adds options of the setup only.
+func WithSetupOptions(opts ...backend.SetupOption) Option {
+	return func(cfg *Config) error {
+		cfg.Setup = append(cfg.Setup, opts...)
+		return nil
+	}
+}
+
+// WithProverOptions adds options of the prover only.
+func WithProverOptions(opts ...backend.ProverOption) Option {
+	return func(cfg *Config) error {
+		cfg.Prover = append(cfg.Prover, opts...)
+		return nil
+	}
+}
+
+// Apply applies the process-wide settings: the log level of the logger of gnark.
+func (cfg *Config) Apply() {
+	if cfg.SetLogLevel {
+		logger.Set(logger.Logger().Level(cfg.LogLevel))
+	}
+}
+
+// CompileOptions returns the options of frontend.Compile.
+func (cfg *Config) CompileOptions() []frontend.CompileOption {
+	var opts []frontend.CompileOption
+	if cfg.NbWorkers != 0 {
+		opts = append(opts, frontend.WithNbWorkers(cfg.NbWorkers))
+	}
+	return append(opts, cfg.Compile...)
+}
+
+// SetupOptions returns the options of the groth16 setup. Like all the setup options, the
+// accelerator is only supported on BN254.
+func (cfg *Config) SetupOptions() []backend.SetupOption {
+	var opts []backend.SetupOption
+	if cfg.Accelerator != "" {
+		opts = append(opts, backend.WithSetupAccelerator(cfg.Accelerator))
+	}
+	return append(opts, cfg.Setup...)
+}
+
+// ProverOptions returns the options of the provers.
+func (cfg *Config) ProverOptions() []backend.ProverOption {
+	var opts []backend.ProverOption
+	if cfg.Accelerator != "" {
+		opts = append(opts, backend.WithAccelerator(cfg.Accelerator))
+	}
+	if cfg.NbWorkers != 0 && (cfg.Accelerator == "" || cfg.Accelerator == cpu.Name) {
+		opts = append(opts, backend.WithMultiExpNbTasks(cfg.NbWorkers))
+	}
+	if cfg.DeviceMemoryLimit != 0 {
+		opts = append(opts, backend.WithDeviceMemoryLimit(cfg.DeviceMemoryLimit))
+	}
+	return append(opts, cfg.Prover...)
+}
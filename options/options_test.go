@@ -0,0 +1,79 @@
+package options_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/options"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mulCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *mulCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.Y), c.Z)
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, err := options.New(options.WithNbWorkers(4), options.WithLogLevel(zerolog.WarnLevel), options.WithProverOptions(backend.WithSparseMSM(false)))
+	assert.NoError(err)
+	assert.Equal(4, cfg.NbWorkers)
+	assert.Len(cfg.CompileOptions(), 1)
+	assert.Len(cfg.SetupOptions(), 0)
+	assert.Len(cfg.ProverOptions(), 2)
+
+	// the GPUs don't run the multi-exponentiations on CPU tasks
+	cfg, err = options.New(options.WithNbWorkers(4), options.WithAccelerator("icicle"))
+	assert.NoError(err)
+	assert.Len(cfg.ProverOptions(), 1)
+
+	// the environment variables override the options
+	t.Setenv(options.EnvNbWorkers, "8")
+	t.Setenv(options.EnvDeviceMemoryLimit, "1")
+	t.Setenv(options.EnvLogLevel, "error")
+	cfg, err = options.New(options.WithNbWorkers(4), options.WithLogLevel(zerolog.WarnLevel))
+	assert.NoError(err)
+	assert.Equal(8, cfg.NbWorkers)
+	assert.Equal(uint64(1), cfg.DeviceMemoryLimit)
+	assert.Equal(zerolog.ErrorLevel, cfg.LogLevel)
+
+	// each phase takes its options
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &mulCircuit{}, cfg.CompileOptions()...)
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs, cfg.SetupOptions()...)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mulCircuit{X: 2, Y: 3, Z: 6}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, w, cfg.ProverOptions()...)
+	assert.Error(err, "the device memory limit is exceeded")
+
+	// the keys are copied to the accelerator the proofs require
+	t.Setenv(options.EnvDeviceMemoryLimit, "")
+	t.Setenv(options.EnvAccelerator, "")
+	cfg, err = options.New(options.WithAccelerator("cpu"))
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs, cfg.SetupOptions()...)
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w, cfg.ProverOptions()...)
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public))
+
+	t.Setenv(options.EnvNbWorkers, "many")
+	_, err = options.New()
+	assert.Error(err)
+	_, err = options.New(options.WithNbWorkers(-1))
+	assert.Error(err)
+}
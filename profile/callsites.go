@@ -0,0 +1,120 @@
+package profile
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// CallSite is the number of constraints attributed to a line of the circuit or of its gadgets.
+type CallSite struct {
+	// Function is the function of the line, with its package name, for instance
+	// "sha2.(*digest).Write".
+	Function string
+	File     string
+	Line     int
+
+	// Flat is the number of constraints added by the line itself, that is by a call to the
+	// frontend API, and Cum the number of constraints added by the line and its callees.
+	Flat, Cum int
+}
+
+// CallSites returns the breakdown of the constraints by line, the lines of the most constraints
+// (cumulated) first. It must be called after Stop.
+func (p *Profile) CallSites() []CallSite {
+	// the locations are program counters, several of which may be on the same line
+	type key struct {
+		function, file string
+		line           int64
+	}
+	keyOf := func(l *profile.Location) key {
+		return key{l.Line[0].Function.Name, l.Line[0].Function.Filename, l.Line[0].Line}
+	}
+	sites := make(map[key]*CallSite)
+	site := func(k key) *CallSite {
+		s, ok := sites[k]
+		if !ok {
+			s = &CallSite{Function: k.function, File: k.file, Line: int(k.line)}
+			sites[k] = s
+		}
+		return s
+	}
+	for _, sample := range p.pprof.Sample {
+		if len(sample.Location) == 0 {
+			continue
+		}
+		n := int(sample.Value[0])
+		site(keyOf(sample.Location[0])).Flat += n
+		// the lines of recursive calls are counted once
+		seen := make(map[key]bool, len(sample.Location))
+		for _, l := range sample.Location {
+			if k := keyOf(l); !seen[k] {
+				seen[k] = true
+				site(k).Cum += n
+			}
+		}
+	}
+
+	res := make([]CallSite, 0, len(sites))
+	for _, s := range sites {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Cum != res[j].Cum {
+			return res[i].Cum > res[j].Cum
+		}
+		if res[i].Flat != res[j].Flat {
+			return res[i].Flat > res[j].Flat
+		}
+		if res[i].File != res[j].File {
+			return res[i].File < res[j].File
+		}
+		return res[i].Line < res[j].Line
+	})
+	return res
+}
+
+// WriteFlameGraph writes the profile as folded stacks, one line per call stack with its number
+// of constraints, for instance
+//
+//	main.(*Circuit).Define;sha2.(*digest).Write;r1cs.(*builder).Mul 1024
+//
+// which flame graph tools read (flamegraph.pl, inferno, speedscope). It must be called after
+// Stop.
+func (p *Profile) WriteFlameGraph(w io.Writer) error {
+	counts := make(map[string]int64)
+	var stacks []string
+	var sb strings.Builder
+	for _, sample := range p.pprof.Sample {
+		if len(sample.Location) == 0 {
+			continue
+		}
+		sb.Reset()
+		// the locations of a sample are from the leaf to the root
+		for i := len(sample.Location) - 1; i >= 0; i-- {
+			if sb.Len() != 0 {
+				sb.WriteByte(';')
+			}
+			sb.WriteString(sample.Location[i].Line[0].Function.Name)
+		}
+		stack := sb.String()
+		if _, ok := counts[stack]; !ok {
+			stacks = append(stacks, stack)
+		}
+		counts[stack] += sample.Value[0]
+	}
+	sort.Strings(stacks)
+
+	bw := bufio.NewWriter(w)
+	for _, stack := range stacks {
+		bw.WriteString(stack)
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatInt(counts[stack], 10))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
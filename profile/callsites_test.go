@@ -0,0 +1,71 @@
+//go:build !windows
+
+package profile_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallSites(t *testing.T) {
+	assert := assert.New(t)
+
+	p := profile.Start(profile.WithNoOutput())
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &Circuit{})
+	assert.NoError(err)
+	p.Stop()
+
+	sites := p.CallSites()
+	assert.NotEmpty(sites)
+	// the circuit has all the constraints, added by the gadget
+	assert.Equal("profile_test.(*Circuit).Define", sites[0].Function)
+	assert.Equal(2, sites[0].Cum)
+	assert.Equal(0, sites[0].Flat)
+	assert.Equal("profile_test.(*obj).Define", sites[1].Function)
+	assert.Equal(2, sites[1].Cum)
+	flat := 0
+	for _, s := range sites {
+		flat += s.Flat
+	}
+	assert.Equal(p.NbConstraints(), flat)
+
+	var buf bytes.Buffer
+	assert.NoError(p.WriteFlameGraph(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 2)
+	for _, line := range lines {
+		assert.True(strings.HasPrefix(line, "profile_test.(*Circuit).Define;profile_test.(*obj).Define;"), line)
+		assert.True(strings.HasSuffix(line, " 1"), line)
+	}
+}
+
+func TestCompileWithProfile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	pprofPath, foldedPath := filepath.Join(dir, "circuit.pprof"), filepath.Join(dir, "circuit.folded")
+
+	cache, err := frontend.NewCompileCache("")
+	assert.NoError(err)
+	for i := 0; i < 2; i++ {
+		// the second compilation is profiled too, the cache being bypassed
+		assert.NoError(os.RemoveAll(foldedPath))
+		_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &Circuit{},
+			frontend.WithCache(cache), frontend.WithProfile(profile.WithPath(pprofPath), profile.WithFlameGraph(foldedPath)))
+		assert.NoError(err)
+
+		folded, err := os.ReadFile(foldedPath)
+		assert.NoError(err)
+		assert.Contains(string(folded), "profile_test.(*obj).Define;")
+		_, err = os.Stat(pprofPath)
+		assert.NoError(err)
+	}
+}
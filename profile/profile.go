@@ -29,6 +29,9 @@ type Profile struct {
 	// if blank, profiile is not written to disk
 	filePath string
 
+	// if not blank, the profile is also written to flameGraphPath as folded stacks
+	flameGraphPath string
+
 	// actual pprof profile struct
 	// details on pprof format: https://github.com/google/pprof/blob/main/proto/README.md
 	pprof profile.Profile
@@ -62,6 +65,13 @@ func WithNoOutput() Option {
 	}
 }
 
+// WithFlameGraph makes Stop also write the profile to path as folded stacks, see WriteFlameGraph.
+func WithFlameGraph(path string) Option {
+	return func(p *Profile) {
+		p.flameGraphPath = path
+	}
+}
+
 // Start creates a new active profiling session. When Stop() is called, this session is removed from
 // active profiling sessions and may be serialized to disk as a pprof compatible file (see ProfilePath option).
 //
@@ -134,6 +144,17 @@ func (p *Profile) Stop() {
 		log.Warn().Msg("gnark profiling disabled [not writing to disk]")
 	}
 
+	if p.flameGraphPath != "" {
+		f, err := os.Create(p.flameGraphPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not create gnark flame graph")
+		}
+		if err := p.WriteFlameGraph(f); err != nil {
+			log.Error().Err(err).Msg("writing flame graph")
+		}
+		f.Close()
+		log.Info().Str("path", p.flameGraphPath).Msg("gnark flame graph written")
+	}
 }
 
 // NbConstraints return number of collected samples (constraints) by the profile session
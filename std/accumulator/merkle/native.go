@@ -0,0 +1,116 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// NativeTree is a perfect Merkle tree of arity 2, 4 or 8, the native counterpart of Verifier,
+// to compute the roots and proofs assigned to circuits. Values (leaves and hashes) are encoded
+// on h.Size() bytes, which for a field hash function such as MiMC are the big-endian encodings
+// of field elements.
+type NativeTree struct {
+	h     hash.Hash
+	arity int
+
+	// levels[l] are the nodes of the level l, from the hashes of the leaves to the root
+	levels [][][]byte
+}
+
+// NewNativeTree returns the tree of the leaves, whose number must be a power of the arity.
+func NewNativeTree(h hash.Hash, arity int, leaves [][]byte) (*NativeTree, error) {
+	if _, err := indexBits(arity); err != nil {
+		return nil, err
+	}
+	n := 1
+	for n < len(leaves) {
+		n *= arity
+	}
+	if n != len(leaves) {
+		return nil, fmt.Errorf("merkle: the number of leaves %d is not a power of %d", len(leaves), arity)
+	}
+
+	t := &NativeTree{h: h, arity: arity}
+	level := make([][]byte, len(leaves))
+	for i := range leaves {
+		var err error
+		if level[i], err = t.sum(leaves[i]); err != nil {
+			return nil, err
+		}
+	}
+	t.levels = append(t.levels, level)
+	for len(level) > 1 {
+		parents := make([][]byte, len(level)/arity)
+		for j := range parents {
+			var err error
+			if parents[j], err = t.sum(level[j*arity : (j+1)*arity]...); err != nil {
+				return nil, err
+			}
+		}
+		t.levels = append(t.levels, parents)
+		level = parents
+	}
+	return t, nil
+}
+
+// Depth returns the number of levels above the leaves.
+func (t *NativeTree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Root returns the root of the tree.
+func (t *NativeTree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Prove returns the path of the proof of membership of the leaf at index, as Proof.Path.
+func (t *NativeTree) Prove(index int) ([][][]byte, error) {
+	return t.path(index, t.Depth())
+}
+
+// MultiProve returns the paths and the cap of the multiproof of membership of the leaves at
+// the indices, as MultiProof.Paths and MultiProof.Cap. The cap is the widest level with at
+// most as many nodes as there are indices.
+func (t *NativeTree) MultiProve(indices []int) ([][][][]byte, [][]byte, error) {
+	if len(indices) == 0 {
+		return nil, nil, errors.New("merkle: no index")
+	}
+	depth := t.Depth()
+	for depth > 0 && len(t.levels[depth-1]) <= len(indices) {
+		depth--
+	}
+	paths := make([][][][]byte, len(indices))
+	for i, index := range indices {
+		var err error
+		if paths[i], err = t.path(index, depth); err != nil {
+			return nil, nil, err
+		}
+	}
+	return paths, t.levels[depth], nil
+}
+
+// path returns the siblings of the nodes of the leaf at index, up to the level depth.
+func (t *NativeTree) path(index, depth int) ([][][]byte, error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil, errors.New("merkle: index out of range")
+	}
+	path := make([][][]byte, depth)
+	for l := range path {
+		position := index % t.arity
+		first := index - position
+		path[l] = append(append(make([][]byte, 0, t.arity-1), t.levels[l][first:index]...), t.levels[l][index+1:first+t.arity]...)
+		index /= t.arity
+	}
+	return path, nil
+}
+
+func (t *NativeTree) sum(data ...[]byte) ([]byte, error) {
+	t.h.Reset()
+	for _, d := range data {
+		if _, err := t.h.Write(d); err != nil {
+			return nil, err
+		}
+	}
+	return t.h.Sum(nil), nil
+}
@@ -0,0 +1,191 @@
+package merkle
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// Verifier verifies the proofs of membership in perfect Merkle trees of arity 2, 4 or 8,
+// whose leaves are hashed with H(leaf) and nodes with H(children...), H being any
+// [hash.Hash] such as MiMC or Poseidon2. NewNativeTree builds the trees and their proofs.
+type Verifier struct {
+	api         frontend.API
+	h           hash.Hash
+	arity       int
+	nbIndexBits int // per level
+}
+
+// Proof is a proof of membership of the leaf at index Index.
+type Proof struct {
+	Index frontend.Variable
+
+	// Path are the siblings of the nodes from the leaf to the root, bottom-up: the arity-1
+	// other children of their parent, from the left.
+	Path [][]frontend.Variable
+}
+
+// MultiProof is a proof of membership of several leaves, sharing the top of the tree: the
+// nodes of the level of the cap, from which the root is hashed once.
+type MultiProof struct {
+	Indices []frontend.Variable
+
+	// Paths are the paths of the leaves, as Proof.Path, from the leaves to the cap.
+	Paths [][][]frontend.Variable
+
+	// Cap are the nodes of the level of the cap, from the left, whose number is a power of
+	// the arity.
+	Cap []frontend.Variable
+}
+
+// NewVerifier returns a verifier of the trees of the arity, 2, 4 or 8, hashed with h.
+func NewVerifier(api frontend.API, h hash.Hash, arity int) (*Verifier, error) {
+	nbIndexBits, err := indexBits(arity)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{api: api, h: h, arity: arity, nbIndexBits: nbIndexBits}, nil
+}
+
+// VerifyProof asserts that leaf is the leaf at index p.Index of the tree of the root, of
+// depth len(p.Path). It costs a hash per level and the decomposition of the index.
+func (v *Verifier) VerifyProof(root, leaf frontend.Variable, p Proof) {
+	digits := v.digits(p.Index, len(p.Path))
+	v.api.AssertIsEqual(v.climb(leaf, digits, p.Path), root)
+}
+
+// VerifyMultiProof asserts that leaves[i] is the leaf at index p.Indices[i] of the tree of the
+// root, for all i. The leaves are hashed up to the cap, whose nodes are looked up by the
+// indices in a table (see the logderivlookup package), and the cap is hashed to the root
+// once: with a cap of about as many nodes as there are leaves (see NativeTree.MultiProve),
+// the multiproof saves the hashes of the levels above the cap on each leaf.
+func (v *Verifier) VerifyMultiProof(root frontend.Variable, leaves []frontend.Variable, p MultiProof) {
+	if len(leaves) != len(p.Indices) || len(leaves) != len(p.Paths) {
+		panic("merkle: a multiproof needs an index and a path per leaf")
+	}
+	capHeight := 0
+	for n := 1; n < len(p.Cap); n *= v.arity {
+		capHeight++
+	}
+	if pow(v.arity, capHeight) != len(p.Cap) {
+		panic(fmt.Sprintf("merkle: the cap has %d nodes, not a power of %d", len(p.Cap), v.arity))
+	}
+
+	capTable := logderivlookup.New(v.api)
+	for _, node := range p.Cap {
+		capTable.Insert(node)
+	}
+	for i := range leaves {
+		depth := len(p.Paths[i])
+		digits := v.digits(p.Indices[i], depth+capHeight)
+		node := v.climb(leaves[i], digits[:depth], p.Paths[i])
+
+		// the position in the cap is given by the remaining digits
+		var position frontend.Variable = 0
+		for l := len(digits) - 1; l >= depth; l-- {
+			position = v.api.Add(v.api.Mul(position, v.arity), digits[l].value)
+		}
+		v.api.AssertIsEqual(capTable.Lookup(position)[0], node)
+	}
+
+	level := p.Cap
+	for len(level) > 1 {
+		parents := make([]frontend.Variable, len(level)/v.arity)
+		for j := range parents {
+			parents[j] = v.nodeSum(level[j*v.arity : (j+1)*v.arity])
+		}
+		level = parents
+	}
+	v.api.AssertIsEqual(level[0], root)
+}
+
+// digit is a digit of an index in the base of the arity, with its indicators: isEqual[j] is 1
+// if the digit is j and 0 otherwise.
+type digit struct {
+	value   frontend.Variable
+	isEqual []frontend.Variable
+}
+
+// digits returns the n digits of the index in the base of the arity, from the least
+// significant. The constraints are not satisfied if the index doesn't fit.
+func (v *Verifier) digits(index frontend.Variable, n int) []digit {
+	bits := v.api.ToBinary(index, n*v.nbIndexBits)
+	res := make([]digit, n)
+	for l := range res {
+		b := bits[l*v.nbIndexBits : (l+1)*v.nbIndexBits]
+		res[l].value = v.api.FromBinary(b...)
+
+		// the indicators of the first bits times the indicators of the next bit
+		res[l].isEqual = []frontend.Variable{1}
+		for _, bit := range b {
+			isEqual := make([]frontend.Variable, 2*len(res[l].isEqual))
+			for j, e := range res[l].isEqual {
+				isEqual[j+len(res[l].isEqual)] = v.api.Mul(e, bit)
+				isEqual[j] = v.api.Sub(e, isEqual[j+len(res[l].isEqual)])
+			}
+			res[l].isEqual = isEqual
+		}
+	}
+	return res
+}
+
+// climb returns the node of the level len(path) above the leaf, at the position of the
+// digits.
+func (v *Verifier) climb(leaf frontend.Variable, digits []digit, path [][]frontend.Variable) frontend.Variable {
+	node := leafSum(v.api, v.h, leaf)
+	children := make([]frontend.Variable, v.arity)
+	for l := range path {
+		siblings, d := path[l], digits[l]
+		if len(siblings) != v.arity-1 {
+			panic(fmt.Sprintf("merkle: the level %d of the path has %d siblings, expected %d", l, len(siblings), v.arity-1))
+		}
+
+		// the child j is the node if the digit is j, else the sibling j if the digit is
+		// greater, and the sibling j-1 if it is less
+		var isGreater frontend.Variable = 0
+		for j := v.arity - 1; j >= 0; j-- {
+			switch j {
+			case v.arity - 1:
+				children[j] = siblings[j-1]
+			case 0:
+				children[j] = siblings[0]
+			default:
+				children[j] = v.api.Add(siblings[j-1], v.api.Mul(isGreater, v.api.Sub(siblings[j], siblings[j-1])))
+			}
+			children[j] = v.api.Add(children[j], v.api.Mul(d.isEqual[j], v.api.Sub(node, children[j])))
+			isGreater = v.api.Add(isGreater, d.isEqual[j])
+		}
+		node = v.nodeSum(children)
+	}
+	return node
+}
+
+// nodeSum returns the hash of the node of the children.
+func (v *Verifier) nodeSum(children []frontend.Variable) frontend.Variable {
+	v.h.Reset()
+	v.h.Write(children...)
+	return v.h.Sum()
+}
+
+// indexBits returns the number of bits of the digits of the indices in the base of the arity.
+func indexBits(arity int) (int, error) {
+	switch arity {
+	case 2:
+		return 1, nil
+	case 4:
+		return 2, nil
+	case 8:
+		return 3, nil
+	}
+	return 0, fmt.Errorf("merkle: unsupported arity %d, expected 2, 4 or 8", arity)
+}
+
+func pow(a, n int) int {
+	res := 1
+	for i := 0; i < n; i++ {
+		res *= a
+	}
+	return res
+}
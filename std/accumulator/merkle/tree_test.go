@@ -0,0 +1,99 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/poseidon2"
+	"github.com/consensys/gnark/test"
+)
+
+type treeCircuit struct {
+	Arity      int
+	Root       frontend.Variable `gnark:",public"`
+	Leaf       frontend.Variable
+	Proof      Proof
+	Leaves     []frontend.Variable
+	MultiProof MultiProof
+}
+
+func (c *treeCircuit) Define(api frontend.API) error {
+	h, err := poseidon2.NewHasher(api)
+	if err != nil {
+		return err
+	}
+	v, err := NewVerifier(api, h, c.Arity)
+	if err != nil {
+		return err
+	}
+	v.VerifyProof(c.Root, c.Leaf, c.Proof)
+	v.VerifyMultiProof(c.Root, c.Leaves, c.MultiProof)
+	return nil
+}
+
+// pathToVariables returns the path as variables, and its shape for the circuit.
+func pathToVariables(path [][][]byte) (assignment, shape [][]frontend.Variable) {
+	assignment = make([][]frontend.Variable, len(path))
+	shape = make([][]frontend.Variable, len(path))
+	for l := range path {
+		assignment[l] = make([]frontend.Variable, len(path[l]))
+		shape[l] = make([]frontend.Variable, len(path[l]))
+		for j := range path[l] {
+			assignment[l][j] = path[l][j]
+		}
+	}
+	return
+}
+
+func TestTree(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, arity := range []int{2, 4, 8} {
+		h, err := poseidon2.NewNativeHasher(ecc.BN254)
+		assert.NoError(err)
+		nbLeaves := arity * arity * arity
+		if arity == 2 {
+			nbLeaves = 16
+		}
+		leaves := make([][]byte, nbLeaves)
+		for i := range leaves {
+			leaves[i] = big.NewInt(int64(1000 + i)).FillBytes(make([]byte, h.Size()))
+		}
+		tree, err := NewNativeTree(h, arity, leaves)
+		assert.NoError(err)
+
+		index := nbLeaves - 3
+		path, err := tree.Prove(index)
+		assert.NoError(err)
+		indices := []int{1, nbLeaves / 2, nbLeaves - 1, 5}
+		paths, capNodes, err := tree.MultiProve(indices)
+		assert.NoError(err)
+
+		circuit := treeCircuit{Arity: arity, Leaves: make([]frontend.Variable, len(indices))}
+		witness := treeCircuit{Arity: arity, Root: tree.Root(), Leaf: leaves[index]}
+		witness.Proof.Index = index
+		witness.Proof.Path, circuit.Proof.Path = pathToVariables(path)
+		witness.MultiProof.Cap = make([]frontend.Variable, len(capNodes))
+		circuit.MultiProof.Cap = make([]frontend.Variable, len(capNodes))
+		for j := range capNodes {
+			witness.MultiProof.Cap[j] = capNodes[j]
+		}
+		for i, index := range indices {
+			witness.Leaves = append(witness.Leaves, leaves[index])
+			witness.MultiProof.Indices = append(witness.MultiProof.Indices, index)
+			circuit.MultiProof.Indices = append(circuit.MultiProof.Indices, nil)
+			p, shape := pathToVariables(paths[i])
+			witness.MultiProof.Paths = append(witness.MultiProof.Paths, p)
+			circuit.MultiProof.Paths = append(circuit.MultiProof.Paths, shape)
+		}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "arity %d", arity)
+
+		// a leaf at another index
+		witness.Proof.Index = index - 1
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "arity %d", arity)
+		witness.Proof.Index = index
+		witness.MultiProof.Indices[2] = indices[0]
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "arity %d", arity)
+	}
+}
@@ -42,7 +42,9 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package merkle provides a ZKP-circuit function to verify merkle proofs.
+// Package merkle provides ZKP-circuit functions to verify merkle proofs: MerkleProof for the
+// binary trees of the merkletree package of gnark-crypto, and Verifier for the trees of arity
+// 2, 4 or 8 over any hash, with multiproofs, built natively by NativeTree.
 package merkle
 
 import (
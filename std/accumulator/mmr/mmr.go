@@ -0,0 +1,120 @@
+// Package mmr provides ZKP-circuit functions to append to and prove membership in a Merkle
+// Mountain Range (MMR), and a native implementation to build the witnesses.
+//
+// An MMR of n leaves is a list of perfect binary Merkle trees, one of height h for each bit h
+// set in n, the highest first. Appending a leaf adds a tree of height 0 and merges the trees
+// of same height, like a binary counter increments. Leaves are hashed with H(leaf) and nodes
+// with H(left, right), where H is any [hash.Hash], e.g. MiMC.
+//
+// An MMR of depth d holds up to 2ᵈ-1 leaves. Its state is the number of leaves and the roots
+// of the trees (the peaks), indexed by their height; the peaks of the heights which are not
+// set in n are zero. The root of the MMR is H(n, peaks[0], …, peaks[d-1]).
+package mmr
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// MMR is the state of a Merkle Mountain Range, whose depth is len(Peaks).
+type MMR struct {
+	// Size is the number of leaves.
+	Size frontend.Variable
+
+	// Peaks are the roots of the trees, indexed by height; they are zero for the heights
+	// which are not set in Size.
+	Peaks []frontend.Variable
+}
+
+// Proof is a proof of membership of the leaf at index Index.
+type Proof struct {
+	Index frontend.Variable
+
+	// Path are the siblings of the nodes from the leaf to the peak, bottom-up. It has
+	// len(Peaks)-1 elements, the ones above the peak are ignored.
+	Path []frontend.Variable
+}
+
+// leafSum returns the hash of the leaf.
+func leafSum(h hash.Hash, leaf frontend.Variable) frontend.Variable {
+	h.Reset()
+	h.Write(leaf)
+	return h.Sum()
+}
+
+// nodeSum returns the hash of the node of children a and b.
+func nodeSum(h hash.Hash, a, b frontend.Variable) frontend.Variable {
+	h.Reset()
+	h.Write(a, b)
+	return h.Sum()
+}
+
+// Root returns the root of the MMR, H(Size, Peaks...).
+func (m *MMR) Root(api frontend.API, h hash.Hash) frontend.Variable {
+	h.Reset()
+	h.Write(m.Size)
+	h.Write(m.Peaks...)
+	return h.Sum()
+}
+
+// Append returns the MMR with the leaf appended. It costs len(m.Peaks)+1 hashes. The
+// constraints are not satisfied if m is full, or if m.Size doesn't fit on len(m.Peaks) bits.
+func (m *MMR) Append(api frontend.API, h hash.Hash, leaf frontend.Variable) MMR {
+	sizeBits := api.ToBinary(m.Size, len(m.Peaks))
+
+	res := MMR{
+		Size:  api.Add(m.Size, 1),
+		Peaks: make([]frontend.Variable, len(m.Peaks)),
+	}
+
+	// carry is the root of the new tree while active, i.e. while the bits of the size are
+	// set: it is merged with the peaks of same height.
+	carry := leafSum(h, leaf)
+	active := frontend.Variable(1)
+	for l := range m.Peaks {
+		merge := api.And(active, sizeBits[l])
+		res.Peaks[l] = api.Select(active, api.Select(sizeBits[l], 0, carry), m.Peaks[l])
+		carry = api.Select(merge, nodeSum(h, m.Peaks[l], carry), carry)
+		active = merge
+	}
+	// all the bits are set: the MMR is full
+	api.AssertIsEqual(active, 0)
+
+	return res
+}
+
+// VerifyProof asserts that leaf is the leaf at index p.Index of the MMR, which must be less
+// than m.Size. It costs len(m.Peaks) hashes.
+func (m *MMR) VerifyProof(api frontend.API, h hash.Hash, leaf frontend.Variable, p Proof) {
+	depth := len(m.Peaks)
+	if len(p.Path) != depth-1 {
+		panic("mmr: the proof path must have len(Peaks)-1 elements")
+	}
+	sizeBits := api.ToBinary(m.Size, depth)
+	indexBits := api.ToBinary(p.Index, depth)
+
+	// the nodes from the leaf up to the top level. The lower bits of the index are its
+	// position in its tree: they tell whether the node is on the left or on the right.
+	nodes := make([]frontend.Variable, depth)
+	nodes[0] = leafSum(h, leaf)
+	for l := 1; l < depth; l++ {
+		left := api.Select(indexBits[l-1], p.Path[l-1], nodes[l-1])
+		right := api.Select(indexBits[l-1], nodes[l-1], p.Path[l-1])
+		nodes[l] = nodeSum(h, left, right)
+	}
+
+	// the leaf is in the tree of height the highest bit set in the size and not in the
+	// index: isPeak[l] is 1 for this height only, and never if the index is not less than
+	// the size.
+	prefixEqual := frontend.Variable(1)
+	nbPeaks := frontend.Variable(0)
+	diff := frontend.Variable(0)
+	for l := depth - 1; l >= 0; l-- {
+		isPeak := api.Mul(prefixEqual, sizeBits[l], api.Sub(1, indexBits[l]))
+		nbPeaks = api.Add(nbPeaks, isPeak)
+		diff = api.Add(diff, api.Mul(isPeak, api.Sub(nodes[l], m.Peaks[l])))
+		prefixEqual = api.Mul(prefixEqual, api.Sub(1, api.Xor(sizeBits[l], indexBits[l])))
+	}
+	api.AssertIsEqual(nbPeaks, 1)
+	api.AssertIsEqual(diff, 0)
+}
@@ -0,0 +1,115 @@
+package mmr
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const testDepth = 4
+
+// mmrCircuit appends Leaf to Old and proves the membership of Member in the new MMR.
+type mmrCircuit struct {
+	Old           MMR
+	Root, NewRoot frontend.Variable `gnark:",public"`
+	Leaf          frontend.Variable
+	Member        frontend.Variable
+	Proof         Proof
+}
+
+func newCircuit() *mmrCircuit {
+	return &mmrCircuit{
+		Old:   MMR{Peaks: make([]frontend.Variable, testDepth)},
+		Proof: Proof{Path: make([]frontend.Variable, testDepth-1)},
+	}
+}
+
+func (c *mmrCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Old.Root(api, &h), c.Root)
+	m := c.Old.Append(api, &h, c.Leaf)
+	api.AssertIsEqual(m.Root(api, &h), c.NewRoot)
+	m.VerifyProof(api, &h, c.Member, c.Proof)
+	return nil
+}
+
+func randomLeaf(t *testing.T) []byte {
+	var e fr.Element
+	if _, err := e.SetRandom(); err != nil {
+		t.Fatal(err)
+	}
+	b := e.Bytes()
+	return b[:]
+}
+
+func TestMMR(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	native := NewNative(hash.MIMC_BN254.New(), testDepth)
+	var leaves [][]byte
+
+	// the MMR is full after 2ᵈ-1 leaves
+	for size := 0; size < 1<<testDepth-1; size++ {
+		witness := newCircuit()
+		witness.Old.Size = size
+		for l, p := range native.Peaks() {
+			witness.Old.Peaks[l] = p
+		}
+		root, err := native.Root()
+		assert.NoError(err)
+		witness.Root = root
+
+		leaf := randomLeaf(t)
+		assert.NoError(native.Append(leaf))
+		leaves = append(leaves, leaf)
+		witness.Leaf = leaf
+		newRoot, err := native.Root()
+		assert.NoError(err)
+		witness.NewRoot = newRoot
+
+		for _, index := range []uint64{0, uint64(size / 2), uint64(size)} {
+			path, err := native.Prove(index)
+			assert.NoError(err)
+			witness.Member = leaves[index]
+			witness.Proof.Index = index
+			for l := range path {
+				witness.Proof.Path[l] = path[l]
+			}
+			assert.SolvingSucceeded(newCircuit(), witness, test.WithCurves(ecc.BN254))
+		}
+
+		// wrong member
+		witness.Member = randomLeaf(t)
+		assert.SolvingFailed(newCircuit(), witness, test.WithCurves(ecc.BN254))
+
+		// index beyond the size, with the path of the last leaf
+		witness.Member = leaf
+		witness.Proof.Index = size + 1
+		assert.SolvingFailed(newCircuit(), witness, test.WithCurves(ecc.BN254))
+	}
+	assert.Error(native.Append(randomLeaf(t)))
+
+	// appending to a full MMR
+	full := newCircuit()
+	full.Old.Size = native.Size()
+	for l, p := range native.Peaks() {
+		full.Old.Peaks[l] = p
+	}
+	root, err := native.Root()
+	assert.NoError(err)
+	full.Root = root
+	full.Leaf, full.Member, full.Proof.Index = 0, 0, 0
+	for l := range full.Proof.Path {
+		full.Proof.Path[l] = 0
+	}
+	full.NewRoot = 0
+	assert.SolvingFailed(newCircuit(), full, test.WithCurves(ecc.BN254))
+}
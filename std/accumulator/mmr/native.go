@@ -0,0 +1,108 @@
+package mmr
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// Native is the native counterpart of MMR, to compute the states, roots and proofs assigned to
+// circuits. Values (leaves, hashes, and the size in the root) are encoded on h.Size()
+// bytes, which for a field hash function such as MiMC are the big-endian encodings of field
+// elements.
+type Native struct {
+	h     hash.Hash
+	depth int
+
+	// nodes[l] are the roots of the complete subtrees of height l, in the order of the leaves
+	nodes [][][]byte
+}
+
+// NewNative returns an empty MMR of the given depth, which holds up to 2ᵈᵉᵖᵗʰ-1 leaves.
+func NewNative(h hash.Hash, depth int) *Native {
+	if depth < 1 || depth > 63 {
+		panic("mmr: depth must be in [1, 63]")
+	}
+	return &Native{h: h, depth: depth, nodes: make([][][]byte, depth)}
+}
+
+// Size returns the number of leaves.
+func (m *Native) Size() uint64 {
+	return uint64(len(m.nodes[0]))
+}
+
+// Append appends the leaf.
+func (m *Native) Append(leaf []byte) error {
+	if m.Size() == 1<<m.depth-1 {
+		return errors.New("mmr: full")
+	}
+	node, err := m.sum(leaf)
+	if err != nil {
+		return err
+	}
+	m.nodes[0] = append(m.nodes[0], node)
+	for l := 0; len(m.nodes[l])%2 == 0; l++ {
+		n := len(m.nodes[l])
+		if node, err = m.sum(m.nodes[l][n-2], m.nodes[l][n-1]); err != nil {
+			return err
+		}
+		m.nodes[l+1] = append(m.nodes[l+1], node)
+	}
+	return nil
+}
+
+// Peaks returns the peaks, indexed by height, as in MMR.Peaks.
+func (m *Native) Peaks() [][]byte {
+	size := m.Size()
+	peaks := make([][]byte, m.depth)
+	for l := range peaks {
+		if size>>l&1 == 1 {
+			// the trees of greater heights come first
+			peaks[l] = m.nodes[l][size>>(l+1)<<1]
+		} else {
+			peaks[l] = make([]byte, m.h.Size())
+		}
+	}
+	return peaks
+}
+
+// Root returns the root of the MMR, as MMR.Root.
+func (m *Native) Root() ([]byte, error) {
+	size := make([]byte, m.h.Size())
+	binary.BigEndian.PutUint64(size[len(size)-8:], m.Size())
+	return m.sum(append([][]byte{size}, m.Peaks()...)...)
+}
+
+// Prove returns the path of the proof of membership of the leaf at index, as Proof.Path.
+func (m *Native) Prove(index uint64) ([][]byte, error) {
+	size := m.Size()
+	if index >= size {
+		return nil, errors.New("mmr: index out of range")
+	}
+	// the height of the tree of the leaf is the highest bit set in the size and not in the
+	// index
+	height := 63
+	for size>>height&1 == index>>height&1 {
+		height--
+	}
+
+	path := make([][]byte, m.depth-1)
+	for l := range path {
+		if l < height {
+			path[l] = m.nodes[l][index>>l^1]
+		} else {
+			path[l] = make([]byte, m.h.Size())
+		}
+	}
+	return path, nil
+}
+
+func (m *Native) sum(data ...[]byte) ([]byte, error) {
+	m.h.Reset()
+	for _, d := range data {
+		if _, err := m.h.Write(d); err != nil {
+			return nil, err
+		}
+	}
+	return m.h.Sum(nil), nil
+}
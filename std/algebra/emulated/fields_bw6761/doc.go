@@ -0,0 +1,6 @@
+// Package fields_bw6761 implements the fields arithmetic of the Fp6 tower
+// used to compute the pairing over the BW6-761 curve.
+//
+//	𝔽p³[u] = 𝔽p/u³+4
+//	𝔽p⁶[v] = 𝔽p³/v²-u
+package fields_bw6761
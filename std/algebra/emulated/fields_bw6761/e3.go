@@ -0,0 +1,295 @@
+package fields_bw6761
+
+import (
+	"math/big"
+
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type curveF = emulated.Field[emulated.BW6761Fp]
+type baseEl = emulated.Element[emulated.BW6761Fp]
+
+type E3 struct {
+	A0, A1, A2 baseEl
+}
+
+type Ext3 struct {
+	api frontend.API
+	fp  *curveF
+}
+
+func NewExt3(api frontend.API) *Ext3 {
+	fp, err := emulated.NewField[emulated.BW6761Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	return &Ext3{api: api, fp: fp}
+}
+
+// MulFpByNonResidue returns x*(-4)
+func (e Ext3) MulFpByNonResidue(x *baseEl) *baseEl {
+	z := e.fp.MulConst(x, big.NewInt(4))
+	return e.fp.Neg(z)
+}
+
+func (e Ext3) Zero() *E3 {
+	zero := e.fp.Zero()
+	return &E3{
+		A0: *zero,
+		A1: *zero,
+		A2: *zero,
+	}
+}
+
+func (e Ext3) One() *E3 {
+	one := e.fp.One()
+	zero := e.fp.Zero()
+	return &E3{
+		A0: *one,
+		A1: *zero,
+		A2: *zero,
+	}
+}
+
+func (e Ext3) IsZero(z *E3) frontend.Variable {
+	a0 := e.fp.IsZero(&z.A0)
+	a1 := e.fp.IsZero(&z.A1)
+	a2 := e.fp.IsZero(&z.A2)
+	return e.api.And(e.api.And(a0, a1), a2)
+}
+
+func (e Ext3) Add(x, y *E3) *E3 {
+	z0 := e.fp.Add(&x.A0, &y.A0)
+	z1 := e.fp.Add(&x.A1, &y.A1)
+	z2 := e.fp.Add(&x.A2, &y.A2)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+func (e Ext3) Sub(x, y *E3) *E3 {
+	z0 := e.fp.Sub(&x.A0, &y.A0)
+	z1 := e.fp.Sub(&x.A1, &y.A1)
+	z2 := e.fp.Sub(&x.A2, &y.A2)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+func (e Ext3) Neg(x *E3) *E3 {
+	z0 := e.fp.Neg(&x.A0)
+	z1 := e.fp.Neg(&x.A1)
+	z2 := e.fp.Neg(&x.A2)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+func (e Ext3) Double(x *E3) *E3 {
+	two := big.NewInt(2)
+	z0 := e.fp.MulConst(&x.A0, two)
+	z1 := e.fp.MulConst(&x.A1, two)
+	z2 := e.fp.MulConst(&x.A2, two)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+func (e Ext3) MulByElement(x *E3, y *baseEl) *E3 {
+	z0 := e.fp.MulMod(&x.A0, y)
+	z1 := e.fp.MulMod(&x.A1, y)
+	z2 := e.fp.MulMod(&x.A2, y)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+func (e Ext3) MulByConstElement(x *E3, y *big.Int) *E3 {
+	z0 := e.fp.MulConst(&x.A0, y)
+	z1 := e.fp.MulConst(&x.A1, y)
+	z2 := e.fp.MulConst(&x.A2, y)
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+// MulByNonResidue returns x*u
+func (e Ext3) MulByNonResidue(x *E3) *E3 {
+	z0 := e.MulFpByNonResidue(&x.A2)
+	return &E3{
+		A0: *z0,
+		A1: x.A0,
+		A2: x.A1,
+	}
+}
+
+// Mul returns x*y, following Algorithm 13 from https://eprint.iacr.org/2010/354.pdf
+func (e Ext3) Mul(x, y *E3) *E3 {
+	t0 := e.fp.MulMod(&x.A0, &y.A0)
+	t1 := e.fp.MulMod(&x.A1, &y.A1)
+	t2 := e.fp.MulMod(&x.A2, &y.A2)
+
+	c0 := e.fp.Add(&x.A1, &x.A2)
+	tmp := e.fp.Add(&y.A1, &y.A2)
+	c0 = e.fp.MulMod(c0, tmp)
+	c0 = e.fp.Sub(c0, t1)
+	c0 = e.fp.Sub(c0, t2)
+	c0 = e.MulFpByNonResidue(c0)
+
+	tmp = e.fp.Add(&x.A0, &x.A2)
+	c2 := e.fp.Add(&y.A0, &y.A2)
+	c2 = e.fp.MulMod(c2, tmp)
+	c2 = e.fp.Sub(c2, t0)
+	c2 = e.fp.Sub(c2, t2)
+
+	c1 := e.fp.Add(&x.A0, &x.A1)
+	tmp = e.fp.Add(&y.A0, &y.A1)
+	c1 = e.fp.MulMod(c1, tmp)
+	c1 = e.fp.Sub(c1, t0)
+	c1 = e.fp.Sub(c1, t1)
+	t2 = e.MulFpByNonResidue(t2)
+
+	z0 := e.fp.Add(c0, t0)
+	z1 := e.fp.Add(c1, t2)
+	z2 := e.fp.Add(c2, t1)
+
+	return &E3{
+		A0: *z0,
+		A1: *z1,
+		A2: *z2,
+	}
+}
+
+// Square returns x², following Algorithm 16 from https://eprint.iacr.org/2010/354.pdf
+func (e Ext3) Square(x *E3) *E3 {
+	c6 := e.fp.MulConst(&x.A1, big.NewInt(2))
+	c4 := e.fp.MulMod(&x.A0, c6) // x.A0 * xA1 * 2
+	c5 := e.fp.MulMod(&x.A2, &x.A2)
+	c1 := e.MulFpByNonResidue(c5)
+	c1 = e.fp.Add(c1, c4)
+	c2 := e.fp.Sub(c4, c5)
+
+	c3 := e.fp.MulMod(&x.A0, &x.A0)
+	c4 = e.fp.Sub(&x.A0, &x.A1)
+	c4 = e.fp.Add(c4, &x.A2)
+	c5 = e.fp.MulMod(c6, &x.A2) // x.A1 * xA2 * 2
+	c4 = e.fp.MulMod(c4, c4)
+	c0 := e.MulFpByNonResidue(c5)
+	c4 = e.fp.Add(c4, c5)
+	c4 = e.fp.Sub(c4, c3)
+
+	z0 := e.fp.Add(c0, c3)
+	z2 := e.fp.Add(c2, c4)
+
+	return &E3{
+		A0: *z0,
+		A1: *c1,
+		A2: *z2,
+	}
+}
+
+// MulBy01 multiplication by sparse element (c0,c1,0)
+func (e Ext3) MulBy01(z *E3, c0, c1 *baseEl) *E3 {
+	a := e.fp.MulMod(&z.A0, c0)
+	b := e.fp.MulMod(&z.A1, c1)
+
+	tmp := e.fp.Add(&z.A1, &z.A2)
+	t0 := e.fp.MulMod(c1, tmp)
+	t0 = e.fp.Sub(t0, b)
+	t0 = e.MulFpByNonResidue(t0)
+	t0 = e.fp.Add(t0, a)
+
+	tmp = e.fp.Add(&z.A0, &z.A2)
+	t2 := e.fp.MulMod(c0, tmp)
+	t2 = e.fp.Sub(t2, a)
+	t2 = e.fp.Add(t2, b)
+
+	t1 := e.fp.Add(c0, c1)
+	tmp = e.fp.Add(&z.A0, &z.A1)
+	t1 = e.fp.MulMod(t1, tmp)
+	t1 = e.fp.Sub(t1, a)
+	t1 = e.fp.Sub(t1, b)
+
+	return &E3{
+		A0: *t0,
+		A1: *t1,
+		A2: *t2,
+	}
+}
+
+func (e Ext3) AssertIsEqual(x, y *E3) {
+	e.fp.AssertIsEqual(&x.A0, &y.A0)
+	e.fp.AssertIsEqual(&x.A1, &y.A1)
+	e.fp.AssertIsEqual(&x.A2, &y.A2)
+}
+
+func FromE3(y *bw6761.E3) E3 {
+	return E3{
+		A0: emulated.ValueOf[emulated.BW6761Fp](y.A0),
+		A1: emulated.ValueOf[emulated.BW6761Fp](y.A1),
+		A2: emulated.ValueOf[emulated.BW6761Fp](y.A2),
+	}
+}
+
+func (e Ext3) Inverse(x *E3) *E3 {
+	res, err := e.fp.NewHint(inverseE3Hint, 3, &x.A0, &x.A1, &x.A2)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	inv := E3{
+		A0: *res[0],
+		A1: *res[1],
+		A2: *res[2],
+	}
+	one := e.One()
+
+	// 1 == inv * x
+	_one := e.Mul(&inv, x)
+	e.AssertIsEqual(one, _one)
+
+	return &inv
+
+}
+
+func (e Ext3) DivUnchecked(x, y *E3) *E3 {
+	res, err := e.fp.NewHint(divE3Hint, 3, &x.A0, &x.A1, &x.A2, &y.A0, &y.A1, &y.A2)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	div := E3{
+		A0: *res[0],
+		A1: *res[1],
+		A2: *res[2],
+	}
+
+	// x == div * y
+	_x := e.Mul(&div, y)
+	e.AssertIsEqual(x, _x)
+
+	return &div
+}
+
+func (e Ext3) Select(selector frontend.Variable, z1, z0 *E3) *E3 {
+	a0 := e.fp.Select(selector, &z1.A0, &z0.A0)
+	a1 := e.fp.Select(selector, &z1.A1, &z0.A1)
+	a2 := e.fp.Select(selector, &z1.A2, &z0.A2)
+	return &E3{A0: *a0, A1: *a1, A2: *a2}
+}
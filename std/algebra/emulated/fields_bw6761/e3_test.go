@@ -0,0 +1,261 @@
+package fields_bw6761
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type e3Add struct {
+	A, B, C E3
+}
+
+func (circuit *e3Add) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.Add(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestAddFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E3
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Add(&a, &b)
+
+	witness := e3Add{
+		A: FromE3(&a),
+		B: FromE3(&b),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Add{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3Sub struct {
+	A, B, C E3
+}
+
+func (circuit *e3Sub) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.Sub(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestSubFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E3
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Sub(&a, &b)
+
+	witness := e3Sub{
+		A: FromE3(&a),
+		B: FromE3(&b),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Sub{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3Mul struct {
+	A, B, C E3
+}
+
+func (circuit *e3Mul) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.Mul(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E3
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Mul(&a, &b)
+
+	witness := e3Mul{
+		A: FromE3(&a),
+		B: FromE3(&b),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Mul{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3Square struct {
+	A, C E3
+}
+
+func (circuit *e3Square) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.Square(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestSquareFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E3
+	_, _ = a.SetRandom()
+	c.Square(&a)
+
+	witness := e3Square{
+		A: FromE3(&a),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Square{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3MulByNonResidue struct {
+	A, C E3
+}
+
+func (circuit *e3MulByNonResidue) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.MulByNonResidue(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulByNonResidueFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E3
+	_, _ = a.SetRandom()
+	c.MulByNonResidue(&a)
+
+	witness := e3MulByNonResidue{
+		A: FromE3(&a),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3MulByNonResidue{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3MulBy01 struct {
+	A  E3
+	C0 baseEl
+	C1 baseEl
+	C  E3
+}
+
+func (circuit *e3MulBy01) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.MulBy01(&circuit.A, &circuit.C0, &circuit.C1)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulBy01Fp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E3
+	var c0, c1 fp.Element
+	_, _ = a.SetRandom()
+	_, _ = c0.SetRandom()
+	_, _ = c1.SetRandom()
+	c.Set(&a)
+	c.MulBy01(&c0, &c1)
+
+	witness := e3MulBy01{
+		A:  FromE3(&a),
+		C0: emulated.ValueOf[emulated.BW6761Fp](c0),
+		C1: emulated.ValueOf[emulated.BW6761Fp](c1),
+		C:  FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3MulBy01{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3Div struct {
+	A, B, C E3
+}
+
+func (circuit *e3Div) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.DivUnchecked(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestDivFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E3
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Inverse(&b).Mul(&c, &a)
+
+	witness := e3Div{
+		A: FromE3(&a),
+		B: FromE3(&b),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Div{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e3Inverse struct {
+	A, C E3
+}
+
+func (circuit *e3Inverse) Define(api frontend.API) error {
+	e := NewExt3(api)
+	expected := e.Inverse(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestInverseFp3(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E3
+	_, _ = a.SetRandom()
+	c.Inverse(&a)
+
+	witness := e3Inverse{
+		A: FromE3(&a),
+		C: FromE3(&c),
+	}
+
+	err := test.IsSolved(&e3Inverse{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
@@ -0,0 +1,271 @@
+package fields_bw6761
+
+import (
+	"math/big"
+
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type E6 struct {
+	B0, B1 E3
+}
+
+type Ext6 struct {
+	*Ext3
+}
+
+func NewExt6(api frontend.API) *Ext6 {
+	return &Ext6{Ext3: NewExt3(api)}
+}
+
+func (e Ext6) One() *E6 {
+	z0 := e.Ext3.One()
+	z1 := e.Ext3.Zero()
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Zero() *E6 {
+	z0 := e.Ext3.Zero()
+	z1 := e.Ext3.Zero()
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) IsZero(z *E6) frontend.Variable {
+	b0 := e.Ext3.IsZero(&z.B0)
+	b1 := e.Ext3.IsZero(&z.B1)
+	return e.api.And(b0, b1)
+}
+
+func (e Ext6) Add(x, y *E6) *E6 {
+	z0 := e.Ext3.Add(&x.B0, &y.B0)
+	z1 := e.Ext3.Add(&x.B1, &y.B1)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Sub(x, y *E6) *E6 {
+	z0 := e.Ext3.Sub(&x.B0, &y.B0)
+	z1 := e.Ext3.Sub(&x.B1, &y.B1)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Neg(x *E6) *E6 {
+	z0 := e.Ext3.Neg(&x.B0)
+	z1 := e.Ext3.Neg(&x.B1)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Double(x *E6) *E6 {
+	z0 := e.Ext3.Double(&x.B0)
+	z1 := e.Ext3.Double(&x.B1)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Conjugate(x *E6) *E6 {
+	z1 := e.Ext3.Neg(&x.B1)
+	return &E6{
+		B0: x.B0,
+		B1: *z1,
+	}
+}
+
+func (e Ext6) Mul(x, y *E6) *E6 {
+	a := e.Ext3.Add(&x.B0, &x.B1)
+	b := e.Ext3.Add(&y.B0, &y.B1)
+	a = e.Ext3.Mul(a, b)
+	b = e.Ext3.Mul(&x.B0, &y.B0)
+	c := e.Ext3.Mul(&x.B1, &y.B1)
+	z1 := e.Ext3.Sub(a, b)
+	z1 = e.Ext3.Sub(z1, c)
+	z0 := e.Ext3.MulByNonResidue(c)
+	z0 = e.Ext3.Add(z0, b)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+// Square returns x², following Algorithm 22 from https://eprint.iacr.org/2010/354.pdf
+func (e Ext6) Square(x *E6) *E6 {
+	c0 := e.Ext3.Sub(&x.B0, &x.B1)
+	c3 := e.Ext3.MulByNonResidue(&x.B1)
+	c3 = e.Ext3.Neg(c3)
+	c3 = e.Ext3.Add(&x.B0, c3)
+	c2 := e.Ext3.Mul(&x.B0, &x.B1)
+	c0 = e.Ext3.Mul(c0, c3)
+	c0 = e.Ext3.Add(c0, c2)
+	z1 := e.Ext3.Double(c2)
+	c2 = e.Ext3.MulByNonResidue(c2)
+	z0 := e.Ext3.Add(c0, c2)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
+
+// CyclotomicSquare returns x² for x in the cyclotomic subgroup, following
+// Granger-Scott https://eprint.iacr.org/2009/565.pdf, 3.2
+func (e Ext6) CyclotomicSquare(x *E6) *E6 {
+	// x=(x0,x1,x2,x3,x4,x5,x6,x7) in E3⁶
+	// cyclosquare(x)=(3*x4²*u + 3*x0² - 2*x0,
+	//					3*x2²*u + 3*x3² - 2*x1,
+	//					3*x5²*u + 3*x1² - 2*x2,
+	//					6*x1*x5*u + 2*x3,
+	//					6*x0*x4 + 2*x4,
+	//					6*x2*x3 + 2*x5)
+	t0 := e.fp.MulMod(&x.B1.A1, &x.B1.A1)
+	t1 := e.fp.MulMod(&x.B0.A0, &x.B0.A0)
+	t6 := e.fp.Add(&x.B1.A1, &x.B0.A0)
+	t6 = e.fp.MulMod(t6, t6)
+	t6 = e.fp.Sub(t6, t0)
+	t6 = e.fp.Sub(t6, t1) // 2*x4*x0
+	t2 := e.fp.MulMod(&x.B0.A2, &x.B0.A2)
+	t3 := e.fp.MulMod(&x.B1.A0, &x.B1.A0)
+	t7 := e.fp.Add(&x.B0.A2, &x.B1.A0)
+	t7 = e.fp.MulMod(t7, t7)
+	t7 = e.fp.Sub(t7, t2)
+	t7 = e.fp.Sub(t7, t3) // 2*x2*x3
+	t4 := e.fp.MulMod(&x.B1.A2, &x.B1.A2)
+	t5 := e.fp.MulMod(&x.B0.A1, &x.B0.A1)
+	t8 := e.fp.Add(&x.B1.A2, &x.B0.A1)
+	t8 = e.fp.MulMod(t8, t8)
+	t8 = e.fp.Sub(t8, t4)
+	t8 = e.fp.Sub(t8, t5)
+	t8 = e.MulFpByNonResidue(t8) // 2*x5*x1*u
+
+	t0 = e.MulFpByNonResidue(t0)
+	t0 = e.fp.Add(t0, t1) // x4²*u + x0²
+	t2 = e.MulFpByNonResidue(t2)
+	t2 = e.fp.Add(t2, t3) // x2²*u + x3²
+	t4 = e.MulFpByNonResidue(t4)
+	t4 = e.fp.Add(t4, t5) // x5²*u + x1²
+
+	two := big.NewInt(2)
+	z00 := e.fp.Sub(t0, &x.B0.A0)
+	z00 = e.fp.MulConst(z00, two)
+	z00 = e.fp.Add(z00, t0)
+	z01 := e.fp.Sub(t2, &x.B0.A1)
+	z01 = e.fp.MulConst(z01, two)
+	z01 = e.fp.Add(z01, t2)
+	z02 := e.fp.Sub(t4, &x.B0.A2)
+	z02 = e.fp.MulConst(z02, two)
+	z02 = e.fp.Add(z02, t4)
+
+	z10 := e.fp.Add(t8, &x.B1.A0)
+	z10 = e.fp.MulConst(z10, two)
+	z10 = e.fp.Add(z10, t8)
+	z11 := e.fp.Add(t6, &x.B1.A1)
+	z11 = e.fp.MulConst(z11, two)
+	z11 = e.fp.Add(z11, t6)
+	z12 := e.fp.Add(t7, &x.B1.A2)
+	z12 = e.fp.MulConst(z12, two)
+	z12 = e.fp.Add(z12, t7)
+
+	return &E6{
+		B0: E3{A0: *z00, A1: *z01, A2: *z02},
+		B1: E3{A0: *z10, A1: *z11, A2: *z12},
+	}
+}
+
+// Frobenius returns x^p
+func (e Ext6) Frobenius(x *E6) *E6 {
+	// the coefficients of u, u², v, uv and u²v are multiplied by
+	// u^(p-1) = (-4)^((p-1)/3), u^(2(p-1)), v^(p-1) = (-4)^((p-1)/6),
+	// u^(p-1)·v^(p-1) = -1 and u^(2(p-1))·v^(p-1).
+	frobA := emulated.ValueOf[emulated.BW6761Fp]("4922464560225523242118178942575080391082002530232324381063048548642823052024664478336818169867474395270858391911405337707247735739826664939444490469542109391530482826728203582549674992333383150446779312029624171857054392282775648")
+	frobB := emulated.ValueOf[emulated.BW6761Fp]("1968985824090209297278610739700577151397666382303825728450741611566800370218827257750865013421937292370006175842381275743914023380727582819905021229583192207421122272650305267822868639090213645505120388400344940985710520836292650")
+	frobC := emulated.ValueOf[emulated.BW6761Fp]("4922464560225523242118178942575080391082002530232324381063048548642823052024664478336818169867474395270858391911405337707247735739826664939444490469542109391530482826728203582549674992333383150446779312029624171857054392282775649")
+	frobBC := emulated.ValueOf[emulated.BW6761Fp]("1968985824090209297278610739700577151397666382303825728450741611566800370218827257750865013421937292370006175842381275743914023380727582819905021229583192207421122272650305267822868639090213645505120388400344940985710520836292651")
+
+	return &E6{
+		B0: E3{
+			A0: x.B0.A0,
+			A1: *e.fp.MulMod(&x.B0.A1, &frobA),
+			A2: *e.fp.MulMod(&x.B0.A2, &frobB),
+		},
+		B1: E3{
+			A0: *e.fp.MulMod(&x.B1.A0, &frobC),
+			A1: *e.fp.Neg(&x.B1.A1),
+			A2: *e.fp.MulMod(&x.B1.A2, &frobBC),
+		},
+	}
+}
+
+func (e Ext6) AssertIsEqual(x, y *E6) {
+	e.Ext3.AssertIsEqual(&x.B0, &y.B0)
+	e.Ext3.AssertIsEqual(&x.B1, &y.B1)
+}
+
+func FromE6(y *bw6761.E6) E6 {
+	return E6{
+		B0: FromE3(&y.B0),
+		B1: FromE3(&y.B1),
+	}
+
+}
+
+func (e Ext6) Inverse(x *E6) *E6 {
+	res, err := e.fp.NewHint(inverseE6Hint, 6, &x.B0.A0, &x.B0.A1, &x.B0.A2, &x.B1.A0, &x.B1.A1, &x.B1.A2)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	inv := E6{
+		B0: E3{A0: *res[0], A1: *res[1], A2: *res[2]},
+		B1: E3{A0: *res[3], A1: *res[4], A2: *res[5]},
+	}
+
+	one := e.One()
+
+	// 1 == inv * x
+	_one := e.Mul(&inv, x)
+	e.AssertIsEqual(one, _one)
+
+	return &inv
+
+}
+
+func (e Ext6) DivUnchecked(x, y *E6) *E6 {
+	res, err := e.fp.NewHint(divE6Hint, 6, &x.B0.A0, &x.B0.A1, &x.B0.A2, &x.B1.A0, &x.B1.A1, &x.B1.A2, &y.B0.A0, &y.B0.A1, &y.B0.A2, &y.B1.A0, &y.B1.A1, &y.B1.A2)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	div := E6{
+		B0: E3{A0: *res[0], A1: *res[1], A2: *res[2]},
+		B1: E3{A0: *res[3], A1: *res[4], A2: *res[5]},
+	}
+
+	// x == div * y
+	_x := e.Mul(&div, y)
+	e.AssertIsEqual(x, _x)
+
+	return &div
+}
+
+func (e Ext6) Select(selector frontend.Variable, z1, z0 *E6) *E6 {
+	b0 := e.Ext3.Select(selector, &z1.B0, &z0.B0)
+	b1 := e.Ext3.Select(selector, &z1.B1, &z0.B1)
+	return &E6{B0: *b0, B1: *b1}
+}
@@ -0,0 +1,138 @@
+package fields_bw6761
+
+func (e Ext6) nSquare(z *E6, n int) *E6 {
+	for i := 0; i < n; i++ {
+		z = e.CyclotomicSquare(z)
+	}
+	return z
+}
+
+// Expt set z to x^t in E6 and return z
+// const t uint64 = 9586122913090633729
+func (e Ext6) Expt(x *E6) *E6 {
+	// t in binary: 1000010100001000110000000000000000000000000000000000000000000001
+	// drop the low 46 bits (all 0 except the least significant bit): 100001010000100011 = 136227
+	// Shortest addition chains can be found at https://wwwhomes.uni-bielefeld.de/achim/addition_chain.html
+
+	// a shortest addition chain for 136227
+	z := e.nSquare(x, 5)
+	z = e.Mul(z, x)
+	x33 := z
+	z = e.nSquare(z, 7)
+	z = e.Mul(z, x33)
+	z = e.nSquare(z, 4)
+	z = e.Mul(z, x)
+	z = e.CyclotomicSquare(z)
+	z = e.Mul(z, x)
+
+	// the remaining 46 bits
+	z = e.nSquare(z, 46)
+	z = e.Mul(z, x)
+
+	return z
+}
+
+// Expc2 set z to x^c2 in E6 and return z
+// ht, hy = 13, 9
+// c2 = ht+hy = 22 (10110)
+func (e Ext6) Expc2(x *E6) *E6 {
+	z := e.CyclotomicSquare(x)
+	z = e.CyclotomicSquare(z)
+	z = e.Mul(z, x)
+	z = e.CyclotomicSquare(z)
+	z = e.Mul(z, x)
+	z = e.CyclotomicSquare(z)
+
+	return z
+}
+
+// Expc1 set z to x^c1 in E6 and return z
+// ht, hy = 13, 9
+// c1 = ht**2+3*hy**2 = 412 (110011100)
+func (e Ext6) Expc1(x *E6) *E6 {
+	z := e.CyclotomicSquare(x)
+	z = e.Mul(z, x)
+	z = e.nSquare(z, 3)
+	z = e.Mul(z, x)
+	z = e.CyclotomicSquare(z)
+	z = e.Mul(z, x)
+	z = e.CyclotomicSquare(z)
+	z = e.Mul(z, x)
+	z = e.nSquare(z, 2)
+
+	return z
+}
+
+// MulBy34 multiplies z by an E6 sparse element of the form
+//
+//	E6{
+//		B0: E3{A0: 1, A1: 0, A2: 0},
+//		B1: E3{A0: c3, A1: c4, A2: 0},
+//	}
+func (e Ext6) MulBy34(z *E6, c3, c4 *baseEl) *E6 {
+	a := e.Ext3.MulBy01(&z.B1, c3, c4)
+	a = e.Ext3.MulByNonResidue(a)
+	b := e.Ext3.MulBy01(&z.B0, c3, c4)
+
+	zB0 := e.Ext3.Add(&z.B0, a)
+	zB1 := e.Ext3.Add(&z.B1, b)
+
+	return &E6{
+		B0: *zB0,
+		B1: *zB1,
+	}
+}
+
+//	multiplies two E6 sparse element of the form:
+//
+//	E6{
+//		B0: E3{A0: 1, A1: 0, A2: 0},
+//		B1: E3{A0: c3, A1: c4, A2: 0},
+//	}
+//
+// and
+//
+//	E6{
+//		B0: E3{A0: 1, A1: 0, A2: 0},
+//		B1: E3{A0: d3, A1: d4, A2: 0},
+//	}
+func (e Ext6) Mul34By34(d3, d4, c3, c4 *baseEl) *[5]baseEl {
+	x3 := e.fp.MulMod(c3, d3)
+	x4 := e.fp.MulMod(c4, d4)
+	tmp := e.fp.Add(c3, c4)
+	x34 := e.fp.Add(d3, d4)
+	x34 = e.fp.MulMod(x34, tmp)
+	x34 = e.fp.Sub(x34, x3)
+	x34 = e.fp.Sub(x34, x4)
+
+	zB0A0 := e.MulFpByNonResidue(x4)
+	zB0A0 = e.fp.Add(zB0A0, e.fp.One())
+	zB1A0 := e.fp.Add(c3, d3)
+	zB1A1 := e.fp.Add(c4, d4)
+
+	return &[5]baseEl{*zB0A0, *x3, *x34, *zB1A0, *zB1A1}
+}
+
+// MulBy01234 multiplies z by an E6 sparse element of the form
+//
+//	E6{
+//		B0: E3{A0: c0, A1: c1, A2: c2},
+//		B1: E3{A0: c3, A1: c4, A2: 0},
+//	}
+func (e Ext6) MulBy01234(z *E6, x *[5]baseEl) *E6 {
+	c0 := &E3{A0: x[0], A1: x[1], A2: x[2]}
+	c1 := &E3{A0: x[3], A1: x[4], A2: *e.fp.Zero()}
+	a := e.Ext3.Add(&z.B0, &z.B1)
+	b := e.Ext3.Add(c0, c1)
+	a = e.Ext3.Mul(a, b)
+	b = e.Ext3.Mul(&z.B0, c0)
+	c := e.Ext3.MulBy01(&z.B1, &x[3], &x[4])
+	z1 := e.Ext3.Sub(a, b)
+	z1 = e.Ext3.Sub(z1, c)
+	z0 := e.Ext3.MulByNonResidue(c)
+	z0 = e.Ext3.Add(z0, b)
+	return &E6{
+		B0: *z0,
+		B1: *z1,
+	}
+}
@@ -0,0 +1,440 @@
+package fields_bw6761
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type e6Add struct {
+	A, B, C E6
+}
+
+func (circuit *e6Add) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Add(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestAddFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E6
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Add(&a, &b)
+
+	witness := e6Add{
+		A: FromE6(&a),
+		B: FromE6(&b),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Add{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Sub struct {
+	A, B, C E6
+}
+
+func (circuit *e6Sub) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Sub(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestSubFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E6
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Sub(&a, &b)
+
+	witness := e6Sub{
+		A: FromE6(&a),
+		B: FromE6(&b),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Sub{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Mul struct {
+	A, B, C E6
+}
+
+func (circuit *e6Mul) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Mul(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E6
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Mul(&a, &b)
+
+	witness := e6Mul{
+		A: FromE6(&a),
+		B: FromE6(&b),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Mul{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Square struct {
+	A, C E6
+}
+
+func (circuit *e6Square) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Square(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestSquareFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	_, _ = a.SetRandom()
+	c.Square(&a)
+
+	witness := e6Square{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Square{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Div struct {
+	A, B, C E6
+}
+
+func (circuit *e6Div) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.DivUnchecked(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestDivFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c bw6761.E6
+	_, _ = a.SetRandom()
+	_, _ = b.SetRandom()
+	c.Inverse(&b).Mul(&c, &a)
+
+	witness := e6Div{
+		A: FromE6(&a),
+		B: FromE6(&b),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Div{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Inverse struct {
+	A, C E6
+}
+
+func (circuit *e6Inverse) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Inverse(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestInverseFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	_, _ = a.SetRandom()
+	c.Inverse(&a)
+
+	witness := e6Inverse{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Inverse{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Conjugate struct {
+	A, C E6
+}
+
+func (circuit *e6Conjugate) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Conjugate(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestConjugateFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	_, _ = a.SetRandom()
+	c.Conjugate(&a)
+
+	witness := e6Conjugate{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Conjugate{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Frobenius struct {
+	A, C E6
+}
+
+func (circuit *e6Frobenius) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Frobenius(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestFrobeniusFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	_, _ = a.SetRandom()
+	c.Frobenius(&a)
+
+	witness := e6Frobenius{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Frobenius{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+// cyclotomic returns a random element of the cyclotomic subgroup.
+func cyclotomic() bw6761.E6 {
+	var a, tmp bw6761.E6
+	_, _ = a.SetRandom()
+
+	// a^((p³-1)(p+1))
+	tmp.Conjugate(&a)
+	a.Inverse(&a)
+	tmp.Mul(&tmp, &a)
+	a.Frobenius(&tmp).Mul(&a, &tmp)
+
+	return a
+}
+
+type e6CyclotomicSquare struct {
+	A, C E6
+}
+
+func (circuit *e6CyclotomicSquare) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.CyclotomicSquare(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestCyclotomicSquareFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var c bw6761.E6
+	a := cyclotomic()
+	c.CyclotomicSquare(&a)
+
+	witness := e6CyclotomicSquare{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6CyclotomicSquare{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Expt struct {
+	A, C E6
+}
+
+func (circuit *e6Expt) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.Expt(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestExptFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var c bw6761.E6
+	a := cyclotomic()
+	c.Expt(&a)
+
+	witness := e6Expt{
+		A: FromE6(&a),
+		C: FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Expt{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Expc struct {
+	A, C1, C2 E6
+}
+
+func (circuit *e6Expc) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected1 := e.Expc1(&circuit.A)
+	expected2 := e.Expc2(&circuit.A)
+	e.AssertIsEqual(expected1, &circuit.C1)
+	e.AssertIsEqual(expected2, &circuit.C2)
+	return nil
+}
+
+func TestExpcFp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var c1, c2 bw6761.E6
+	a := cyclotomic()
+	c1.Expc1(&a)
+	c2.Expc2(&a)
+
+	witness := e6Expc{
+		A:  FromE6(&a),
+		C1: FromE6(&c1),
+		C2: FromE6(&c2),
+	}
+
+	err := test.IsSolved(&e6Expc{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6MulBy34 struct {
+	A      E6
+	C3, C4 baseEl
+	C      E6
+}
+
+func (circuit *e6MulBy34) Define(api frontend.API) error {
+	e := NewExt6(api)
+	expected := e.MulBy34(&circuit.A, &circuit.C3, &circuit.C4)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulBy34Fp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	var one, c3, c4 fp.Element
+	_, _ = a.SetRandom()
+	one.SetOne()
+	_, _ = c3.SetRandom()
+	_, _ = c4.SetRandom()
+	c.Set(&a)
+	c.MulBy034(&one, &c3, &c4)
+
+	witness := e6MulBy34{
+		A:  FromE6(&a),
+		C3: emulated.ValueOf[emulated.BW6761Fp](c3),
+		C4: emulated.ValueOf[emulated.BW6761Fp](c4),
+		C:  FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6MulBy34{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
+
+type e6Mul34By34 struct {
+	A              E6
+	D3, D4, C3, C4 baseEl
+	C              E6
+}
+
+func (circuit *e6Mul34By34) Define(api frontend.API) error {
+	e := NewExt6(api)
+	prod := e.Mul34By34(&circuit.D3, &circuit.D4, &circuit.C3, &circuit.C4)
+	expected := e.MulBy01234(&circuit.A, prod)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMul34By34Fp6(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c bw6761.E6
+	var one, d3, d4, c3, c4 fp.Element
+	_, _ = a.SetRandom()
+	one.SetOne()
+	_, _ = d3.SetRandom()
+	_, _ = d4.SetRandom()
+	_, _ = c3.SetRandom()
+	_, _ = c4.SetRandom()
+	c.Set(&a)
+	c.MulBy034(&one, &c3, &c4)
+	one.SetOne()
+	c.MulBy034(&one, &d3, &d4)
+
+	witness := e6Mul34By34{
+		A:  FromE6(&a),
+		D3: emulated.ValueOf[emulated.BW6761Fp](d3),
+		D4: emulated.ValueOf[emulated.BW6761Fp](d4),
+		C3: emulated.ValueOf[emulated.BW6761Fp](c3),
+		C4: emulated.ValueOf[emulated.BW6761Fp](c4),
+		C:  FromE6(&c),
+	}
+
+	err := test.IsSolved(&e6Mul34By34{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+}
@@ -0,0 +1,8 @@
+// Package sw_bw6761 implements G1 and G2 arithmetics and pairing computation over BW6-761 curve.
+//
+// The implementation follows [Housni22]: "Pairings in Rank-1 Constraint Systems"
+// and the optimal Tate pairing of [HG21] computed by gnark-crypto.
+//
+// [Housni22]: https://eprint.iacr.org/2022/1162
+// [HG21]: https://eprint.iacr.org/2021/1359
+package sw_bw6761
@@ -0,0 +1,465 @@
+package sw_bw6761
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bw6761"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type baseEl = emulated.Element[emulated.BW6761Fp]
+
+type Pairing struct {
+	api frontend.API
+	*fields_bw6761.Ext6
+	curveF *emulated.Field[emulated.BW6761Fp]
+}
+
+type GTEl = fields_bw6761.E6
+
+func NewGTEl(v bw6761.GT) GTEl {
+	return GTEl{
+		B0: fields_bw6761.E3{
+			A0: emulated.ValueOf[emulated.BW6761Fp](v.B0.A0),
+			A1: emulated.ValueOf[emulated.BW6761Fp](v.B0.A1),
+			A2: emulated.ValueOf[emulated.BW6761Fp](v.B0.A2),
+		},
+		B1: fields_bw6761.E3{
+			A0: emulated.ValueOf[emulated.BW6761Fp](v.B1.A0),
+			A1: emulated.ValueOf[emulated.BW6761Fp](v.B1.A1),
+			A2: emulated.ValueOf[emulated.BW6761Fp](v.B1.A2),
+		},
+	}
+}
+
+func NewPairing(api frontend.API) (*Pairing, error) {
+	ba, err := emulated.NewField[emulated.BW6761Fp](api)
+	if err != nil {
+		return nil, fmt.Errorf("new base api: %w", err)
+	}
+	return &Pairing{
+		api:    api,
+		Ext6:   fields_bw6761.NewExt6(api),
+		curveF: ba,
+	}, nil
+}
+
+// FinalExponentiation computes the exponentiation zᵈ where
+//
+//	d = (p⁶-1)/r = (p⁶-1)/Φ₆(p) ⋅ Φ₆(p)/r = (p³-1)(p+1)(p²-p+1)/r
+//
+// we use instead
+//
+//	d = s ⋅ (p³-1)(p+1)(p²-p+1)/r
+//
+// where s is the cofactor 12(x₀+1) (El Housni and Guillevic).
+//
+// Unlike the torus-based final exponentiation of BLS12-381, all the operations
+// of the hard part are well defined on 1, so that the method applies to any
+// non-zero z, including the products of several Miller loops.
+func (pr Pairing) FinalExponentiation(z *GTEl) *GTEl {
+
+	// Easy part
+	// (p³-1)(p+1)
+	buf := pr.Ext6.Conjugate(z)
+	buf = pr.Ext6.DivUnchecked(buf, z)
+	result := pr.Ext6.Frobenius(buf)
+	result = pr.Ext6.Mul(result, buf)
+
+	// Hard part (up to permutation)
+	// El Housni and Guillevic
+	// https://eprint.iacr.org/2020/351.pdf
+	m1 := pr.Ext6.Expt(result)
+	_m1 := pr.Ext6.Conjugate(m1)
+	m2 := pr.Ext6.Expt(m1)
+	_m2 := pr.Ext6.Conjugate(m2)
+	m3 := pr.Ext6.Expt(m2)
+	f0 := pr.Ext6.Frobenius(result)
+	f0 = pr.Ext6.Mul(f0, result)
+	f0 = pr.Ext6.Mul(f0, m2)
+	m2 = pr.Ext6.CyclotomicSquare(_m1)
+	f0 = pr.Ext6.Mul(f0, m2)
+	f0_36 := pr.Ext6.CyclotomicSquare(f0)
+	f0_36 = pr.Ext6.CyclotomicSquare(f0_36)
+	f0_36 = pr.Ext6.CyclotomicSquare(f0_36)
+	f0_36 = pr.Ext6.Mul(f0_36, f0)
+	f0_36 = pr.Ext6.CyclotomicSquare(f0_36)
+	f0_36 = pr.Ext6.CyclotomicSquare(f0_36)
+	g0 := pr.Ext6.Mul(result, m1)
+	g0 = pr.Ext6.Frobenius(g0)
+	g0 = pr.Ext6.Mul(g0, m3)
+	g0 = pr.Ext6.Mul(g0, _m2)
+	g0 = pr.Ext6.Mul(g0, _m1)
+	g1 := pr.Ext6.Expt(g0)
+	_g1 := pr.Ext6.Conjugate(g1)
+	g2 := pr.Ext6.Expt(g1)
+	g3 := pr.Ext6.Expt(g2)
+	_g3 := pr.Ext6.Conjugate(g3)
+	g4 := pr.Ext6.Expt(g3)
+	_g4 := pr.Ext6.Conjugate(g4)
+	g5 := pr.Ext6.Expt(g4)
+	_g5 := pr.Ext6.Conjugate(g5)
+	g6 := pr.Ext6.Expt(g5)
+	gA := pr.Ext6.Mul(g3, _g5)
+	gA = pr.Ext6.CyclotomicSquare(gA)
+	gA = pr.Ext6.Mul(gA, g6)
+	gA = pr.Ext6.Mul(gA, g1)
+	gA = pr.Ext6.Mul(gA, g0)
+	g034 := pr.Ext6.Mul(g0, g3)
+	g034 = pr.Ext6.Mul(g034, _g4)
+	gB := pr.Ext6.CyclotomicSquare(g034)
+	gB = pr.Ext6.Mul(gB, g034)
+	gB = pr.Ext6.Mul(gB, g5)
+	gB = pr.Ext6.Mul(gB, _g1)
+	_g1g2 := pr.Ext6.Mul(_g1, g2)
+	gC := pr.Ext6.Mul(_g3, _g1g2)
+	gC = pr.Ext6.CyclotomicSquare(gC)
+	gC = pr.Ext6.Mul(gC, _g1g2)
+	gC = pr.Ext6.Mul(gC, g0)
+	gC = pr.Ext6.CyclotomicSquare(gC)
+	gC = pr.Ext6.Mul(gC, g2)
+	gC = pr.Ext6.Mul(gC, g0)
+	gC = pr.Ext6.Mul(gC, g4)
+
+	// ht, hy = 13, 9
+	// c1 = ht**2+3*hy**2 = 412
+	h1 := pr.Ext6.Expc1(gA)
+	// c2 = ht+hy = 22
+	h2 := pr.Ext6.Expc2(gB)
+	h2g2C := pr.Ext6.CyclotomicSquare(gC)
+	h2g2C = pr.Ext6.Mul(h2g2C, h2)
+	h4 := pr.Ext6.CyclotomicSquare(h2g2C)
+	h4 = pr.Ext6.Mul(h4, h2g2C)
+	h4 = pr.Ext6.CyclotomicSquare(h4)
+	result = pr.Ext6.Mul(h1, h4)
+	result = pr.Ext6.Mul(result, f0_36)
+
+	return result
+}
+
+// lineEvaluation represents a sparse Fp6 Elmt (result of the line evaluation)
+// line: 1 + R0(x/y) + R1(1/y) = 0 instead of R0'*y + R1'*x + R2' = 0 This
+// makes the multiplication by lines (MulBy34) and between lines (Mul34By34)
+// circuit-efficient.
+type lineEvaluation struct {
+	R0, R1 baseEl
+}
+
+// Pair calculates the reduced pairing for a set of points
+// ∏ᵢ e(Pᵢ, Qᵢ).
+//
+// This function doesn't check that the inputs are in the correct subgroups.
+func (pr Pairing) Pair(P []*G1Affine, Q []*G2Affine) (*GTEl, error) {
+	res, err := pr.MillerLoop(P, Q)
+	if err != nil {
+		return nil, fmt.Errorf("miller loop: %w", err)
+	}
+	res = pr.FinalExponentiation(res)
+	return res, nil
+}
+
+// PairingCheck calculates the reduced pairing for a set of points and asserts if the result is One
+// ∏ᵢ e(Pᵢ, Qᵢ) =? 1
+//
+// This function doesn't check that the inputs are in the correct subgroups.
+func (pr Pairing) PairingCheck(P []*G1Affine, Q []*G2Affine) error {
+	f, err := pr.Pair(P, Q)
+	if err != nil {
+		return err
+
+	}
+	one := pr.One()
+	pr.AssertIsEqual(f, one)
+
+	return nil
+}
+
+func (pr Pairing) AssertIsEqual(x, y *GTEl) {
+	pr.Ext6.AssertIsEqual(x, y)
+}
+
+// loopCounter0 = x₀+1 in binary, loopCounter1 = x₀³-x₀²-x₀ in 2-NAF
+//
+//	x₀=9586122913090633729
+var (
+	loopCounter0 = [190]int8{0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, -1, 0, 1, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	loopCounter1 [190]int8
+)
+
+func init() {
+	T, _ := new(big.Int).SetString("880904806456922042166256752416502360955572640081583800319", 10)
+	ecc.NafDecomposition(T, loopCounter1[:])
+}
+
+// MillerLoop computes the optimal Tate multi-Miller loop
+// ∏ᵢ { fᵢ_{x₀+1+λ(x₀³-x₀²-x₀),Pᵢ}(Qᵢ) }
+//
+// Alg.2 in https://eprint.iacr.org/2021/1359.pdf
+//
+// The result is equal to the one of gnark-crypto up to a factor in 𝔽p, which
+// is canceled by the final exponentiation.
+func (pr Pairing) MillerLoop(P []*G1Affine, Q []*G2Affine) (*GTEl, error) {
+	// check input size match
+	n := len(P)
+	if n == 0 || n != len(Q) {
+		return nil, errors.New("invalid inputs sizes")
+	}
+
+	// ω the primitive cube root of unity of the endomorphism (x, y) ↦ (ω·x, y)
+	// of G1, of eigenvalue x₀³-x₀²-x₀
+	thirdRootOne := emulated.ValueOf[emulated.BW6761Fp]("4922464560225523242118178942575080391082002530232324381063048548642823052024664478336818169867474395270858391911405337707247735739826664939444490469542109391530482826728203582549674992333383150446779312029624171857054392282775648")
+
+	// precomputations
+	p1 := make([]*G1Affine, n)
+	p01 := make([]*G1Affine, n) // P0+P1
+	p10 := make([]*G1Affine, n) // P0-P1
+	l01 := make([]*lineEvaluation, n)
+	pAcc := make([]*G1Affine, n)
+	yInv := make([]*baseEl, n)
+	xOverY := make([]*baseEl, n)
+
+	for k := 0; k < n; k++ {
+		// P and Q are supposed to be on G1 and G2 respectively of prime order r.
+		// The point (x,0) is of order 2. But this function does not check
+		// subgroup membership.
+		// Anyway (x,0) cannot be on the twist of BW6-761 because -4 is a cubic
+		// non-residue in Fp, so 1/y is well defined for all points Q's.
+		yInv[k] = pr.curveF.Inverse(&Q[k].Y)
+		xOverY[k] = pr.curveF.MulMod(&Q[k].X, yInv[k])
+
+		p1[k] = &G1Affine{
+			X: *pr.curveF.MulMod(&P[k].X, &thirdRootOne),
+			Y: *pr.curveF.Neg(&P[k].Y),
+		}
+		pAcc[k] = p1[k]
+
+		// l_{p0,p1}(q)
+		p01[k], l01[k] = pr.addStep(p1[k], P[k])
+		l01[k].R0 = *pr.curveF.MulMod(&l01[k].R0, xOverY[k])
+		l01[k].R1 = *pr.curveF.MulMod(&l01[k].R1, yInv[k])
+
+		p10[k], _ = pr.addStep(pr.neg(p1[k]), P[k])
+	}
+
+	// f_{a0+λ*a1,P}(Q)
+	res := pr.Ext6.One()
+	var l0, l *lineEvaluation
+
+	// i = len(loopCounter0) - 2, separately to avoid an E6 Square
+	// (Square(res) = 1² = 1)
+	// j = 0
+	// k = 0, separately to avoid MulBy34 (res × ℓ)
+	// (assign line to res)
+
+	// pAcc[0] ← 2pAcc[0] and l0 the tangent ℓ passing 2pAcc[0]
+	pAcc[0], l0 = pr.doubleStep(pAcc[0])
+	// line evaluation at Q[0] (assign)
+	res.B1.A0 = *pr.curveF.MulMod(&l0.R0, xOverY[0])
+	res.B1.A1 = *pr.curveF.MulMod(&l0.R1, yInv[0])
+
+	if n >= 2 {
+		// k = 1, separately to avoid MulBy34 (res × ℓ)
+		// pAcc[1] ← 2pAcc[1] and l0 the tangent ℓ passing 2pAcc[1]
+		pAcc[1], l0 = pr.doubleStep(pAcc[1])
+		// line evaluation at Q[1]
+		l0.R0 = *pr.curveF.MulMod(&l0.R0, xOverY[1])
+		l0.R1 = *pr.curveF.MulMod(&l0.R1, yInv[1])
+		// ℓ × res
+		prodLines := *pr.Mul34By34(&l0.R0, &l0.R1, &res.B1.A0, &res.B1.A1)
+		res.B0.A0 = prodLines[0]
+		res.B0.A1 = prodLines[1]
+		res.B0.A2 = prodLines[2]
+		res.B1.A0 = prodLines[3]
+		res.B1.A1 = prodLines[4]
+	}
+
+	for k := 2; k < n; k++ {
+		// pAcc[k] ← 2pAcc[k] and l0 the tangent ℓ passing 2pAcc[k]
+		pAcc[k], l0 = pr.doubleStep(pAcc[k])
+		// line evaluation at Q[k]
+		l0.R0 = *pr.curveF.MulMod(&l0.R0, xOverY[k])
+		l0.R1 = *pr.curveF.MulMod(&l0.R1, yInv[k])
+		// ℓ × res
+		res = pr.MulBy34(res, &l0.R0, &l0.R1)
+	}
+
+	for i := len(loopCounter0) - 3; i >= 1; i-- {
+		// mutualize the square among n Miller loops
+		// (∏ᵢfᵢ)²
+		res = pr.Square(res)
+
+		j := loopCounter1[i]*3 + loopCounter0[i]
+
+		for k := 0; k < n; k++ {
+			// pAcc[k] ← 2pAcc[k] and l0 the tangent ℓ passing 2pAcc[k]
+			pAcc[k], l0 = pr.doubleStep(pAcc[k])
+			// line evaluation at Q[k]
+			l0.R0 = *pr.curveF.MulMod(&l0.R0, xOverY[k])
+			l0.R1 = *pr.curveF.MulMod(&l0.R1, yInv[k])
+
+			if j == 0 {
+				// ℓ × res
+				res = pr.MulBy34(res, &l0.R0, &l0.R1)
+				continue
+			}
+
+			// the point added to pAcc[k] and, when it isn't ±p0 or ±p1, the
+			// line ℓ passing p0 and p1 which completes the line to it
+			var a *G1Affine
+			var l2 *lineEvaluation
+			switch j {
+			case -4:
+				a, l2 = pr.neg(p01[k]), l01[k]
+			case -3:
+				a = pr.neg(p1[k])
+			case -2:
+				a, l2 = p10[k], l01[k]
+			case -1:
+				a = pr.neg(P[k])
+			case 1:
+				a = P[k]
+			case 2:
+				a, l2 = pr.neg(p10[k]), l01[k]
+			case 3:
+				a = p1[k]
+			case 4:
+				a, l2 = p01[k], l01[k]
+			default:
+				return nil, errors.New("invalid loopCounter")
+			}
+
+			// pAcc[k] ← pAcc[k]+a and l the line ℓ passing pAcc[k] and a
+			pAcc[k], l = pr.addStep(pAcc[k], a)
+			// line evaluation at Q[k]
+			l.R0 = *pr.curveF.MulMod(&l.R0, xOverY[k])
+			l.R1 = *pr.curveF.MulMod(&l.R1, yInv[k])
+
+			if l2 == nil {
+				// ℓ × ℓ
+				prodLines := pr.Mul34By34(&l.R0, &l.R1, &l0.R0, &l0.R1)
+				// (ℓ × ℓ) × res
+				res = pr.MulBy01234(res, prodLines)
+			} else {
+				// ℓ × ℓ
+				prodLines := pr.Mul34By34(&l.R0, &l.R1, &l2.R0, &l2.R1)
+				// ℓ × res
+				res = pr.MulBy34(res, &l0.R0, &l0.R1)
+				// (ℓ × ℓ) × res
+				res = pr.MulBy01234(res, prodLines)
+			}
+		}
+	}
+
+	// i = 0, separately to avoid a point doubling
+	// j = -3, the line passing 2pAcc[k] and -p1[k] being vertical
+	res = pr.Square(res)
+	for k := 0; k < n; k++ {
+		// l0 the tangent ℓ passing 2pAcc[k]
+		l0 = pr.tangentCompute(pAcc[k])
+		// line evaluation at Q[k]
+		l0.R0 = *pr.curveF.MulMod(&l0.R0, xOverY[k])
+		l0.R1 = *pr.curveF.MulMod(&l0.R1, yInv[k])
+		// ℓ × res
+		res = pr.MulBy34(res, &l0.R0, &l0.R1)
+	}
+
+	return res, nil
+}
+
+func (pr Pairing) neg(p *G1Affine) *G1Affine {
+	return &G1Affine{
+		X: p.X,
+		Y: *pr.curveF.Neg(&p.Y),
+	}
+}
+
+// doubleStep doubles a point in affine coordinates, and evaluates the line in Miller loop
+// https://eprint.iacr.org/2022/1162 (Section 6.1)
+func (pr Pairing) doubleStep(p1 *G1Affine) (*G1Affine, *lineEvaluation) {
+
+	var p G1Affine
+	var line lineEvaluation
+
+	// λ = 3x²/2y
+	n := pr.curveF.MulMod(&p1.X, &p1.X)
+	three := big.NewInt(3)
+	n = pr.curveF.MulConst(n, three)
+	d := pr.curveF.MulConst(&p1.Y, big.NewInt(2))
+	λ := pr.curveF.Div(n, d)
+
+	// xr = λ²-2x
+	xr := pr.curveF.MulMod(λ, λ)
+	xr = pr.curveF.Sub(xr, &p1.X)
+	xr = pr.curveF.Sub(xr, &p1.X)
+
+	// yr = λ(x-xr)-y
+	yr := pr.curveF.Sub(&p1.X, xr)
+	yr = pr.curveF.MulMod(λ, yr)
+	yr = pr.curveF.Sub(yr, &p1.Y)
+
+	p.X = *pr.curveF.Reduce(xr)
+	p.Y = *pr.curveF.Reduce(yr)
+
+	line.R0 = *pr.curveF.Neg(λ)
+	line.R1 = *pr.curveF.MulMod(λ, &p1.X)
+	line.R1 = *pr.curveF.Sub(&line.R1, &p1.Y)
+
+	return &p, &line
+
+}
+
+// addStep adds two points in affine coordinates, and evaluates the line in Miller loop
+// https://eprint.iacr.org/2022/1162 (Section 6.1)
+func (pr Pairing) addStep(p1, p2 *G1Affine) (*G1Affine, *lineEvaluation) {
+
+	// compute λ = (y2-y1)/(x2-x1)
+	p2ypy := pr.curveF.Sub(&p2.Y, &p1.Y)
+	p2xpx := pr.curveF.Sub(&p2.X, &p1.X)
+	λ := pr.curveF.Div(p2ypy, p2xpx)
+
+	// xr = λ²-x1-x2
+	λλ := pr.curveF.MulMod(λ, λ)
+	p2xpx = pr.curveF.Add(&p1.X, &p2.X)
+	xr := pr.curveF.Sub(λλ, p2xpx)
+
+	// yr = λ(x1-xr) - y1
+	pxrx := pr.curveF.Sub(&p1.X, xr)
+	λpxrx := pr.curveF.MulMod(λ, pxrx)
+	yr := pr.curveF.Sub(λpxrx, &p1.Y)
+
+	var res G1Affine
+	res.X = *pr.curveF.Reduce(xr)
+	res.Y = *pr.curveF.Reduce(yr)
+
+	var line lineEvaluation
+	line.R0 = *pr.curveF.Neg(λ)
+	line.R1 = *pr.curveF.MulMod(λ, &p1.X)
+	line.R1 = *pr.curveF.Sub(&line.R1, &p1.Y)
+
+	return &res, &line
+
+}
+
+// tangentCompute computes the tangent line at p1 but does not compute 2p1
+func (pr Pairing) tangentCompute(p1 *G1Affine) *lineEvaluation {
+
+	// λ = 3x²/2y
+	n := pr.curveF.MulMod(&p1.X, &p1.X)
+	three := big.NewInt(3)
+	n = pr.curveF.MulConst(n, three)
+	d := pr.curveF.MulConst(&p1.Y, big.NewInt(2))
+	λ := pr.curveF.Div(n, d)
+
+	var line lineEvaluation
+	line.R0 = *pr.curveF.Neg(λ)
+	line.R1 = *pr.curveF.MulMod(λ, &p1.X)
+	line.R1 = *pr.curveF.Sub(&line.R1, &p1.Y)
+
+	return &line
+
+}
@@ -14,16 +14,17 @@ This package implements unified and complete point addition. The method
 [Curve.AddUnified] can be used for point additions or in case of points at
 infinity. As such, this package does not expose separate Add and Double methods.
 
-The package provides a few curve parameters, see functions [GetSecp256k1Params]
-and [GetBN254Params].
+The package provides a few curve parameters, see functions [GetSecp256k1Params],
+[GetBN254Params], [GetBLS12381Params], [GetBW6761Params], [GetP256Params],
+[GetStarkCurveParams], [GetPallasParams] and [GetVestaParams].
 
 Unconventionally, this package uses type parameters to define the base field of
 the points and variables to define the coefficients of the curve. This is due to
 how the emulated elements are constructed by their type parameters. To unify the
 different conventions, we provide the method [GetCurveParams] to allow resolving
 a particular curve parameter depending on the type parameter defining the base
-field. For now, we only have a single curve defined on every base field, but
-this may change in the future with the addition of additional curves.
+field. The parameters of other curves can be registered with
+[RegisterCurveParams], a single curve being defined on every base field.
 
 This package uses field emulation (unlike packages
 [github.com/consensys/gnark/std/algebra/native/sw_bls12377] and
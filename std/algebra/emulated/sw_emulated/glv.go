@@ -0,0 +1,89 @@
+package sw_emulated
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// glvBits returns the number of bits of the absolute values of the halves of the
+// GLV decompositions of the scalars modulo r: they are less than about √r.
+func glvBits(r *big.Int) int {
+	return (r.BitLen()+1)/2 + 2
+}
+
+// scalarMulGLV computes s * p with the GLV method [GLV01]: the prover gives the
+// decomposition s = s₁ + λ·s₂ mod r, with |s₁| and |s₂| of about half the bits
+// of r, which is checked in the scalar field, and [s]p = [s₁]p + [s₂]φ(p) is
+// computed by a joint double-and-add on the halves, with φ(p) = (ω·x, y).
+//
+// The double-and-add reads the bits bᵢ of the halves as the digits 2bᵢ-1 in
+// {-1, 1}: the accumulator starts from p + φ(p) as if the top bits were 1, at
+// each step it is doubled and added ±p ± φ(p), and the bits at position 0
+// finally subtract p and φ(p) if they are 0. As the halves have leading zeros,
+// the accumulator would stay at p + φ(p) and meet the added point in the
+// incomplete formulas: it is offset by the generator g, whose multiple
+// [2ⁿ⁻¹]g is subtracted at the end.
+//
+// ⚠️  p must be on the curve, and nonzero. When s=0, it returns (0,0).
+//
+// [GLV01]: https://www.iacr.org/archive/crypto2001/21390189.pdf
+func (c *Curve[B, S]) scalarMulGLV(p *AffinePoint[B], s *emulated.Element[S]) *AffinePoint[B] {
+	var st S
+	nbBits := glvBits(st.Modulus())
+
+	// the decomposition, with the halves as their bits
+	sr := c.scalarApi.Reduce(s)
+	inputs := append(limbsConstants(st.BitsPerLimb(), st.Modulus(), c.params.Eigenvalue, len(sr.Limbs)), sr.Limbs...)
+	res, err := c.api.Compiler().NewHint(decomposeScalarGLV, 4, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	s1Bits, s2Bits := c.api.ToBinary(res[0], nbBits), c.api.ToBinary(res[1], nbBits)
+	sign1, sign2 := res[2], res[3]
+	c.api.AssertIsBoolean(sign1)
+	c.api.AssertIsBoolean(sign2)
+	s1, s2 := c.scalarApi.FromBits(s1Bits...), c.scalarApi.FromBits(s2Bits...)
+	s1 = c.scalarApi.Select(sign1, c.scalarApi.Neg(s1), s1)
+	s2 = c.scalarApi.Select(sign2, c.scalarApi.Neg(s2), s2)
+	lambda := emulated.ValueOf[S](c.params.Eigenvalue)
+	c.scalarApi.AssertIsEqual(c.scalarApi.Add(s1, c.scalarApi.Mul(s2, &lambda)), s)
+
+	// ±p and ±φ(p), of the signs of the halves
+	omega := emulated.ValueOf[B](c.params.ThirdRootOne)
+	phi := &AffinePoint[B]{X: *c.baseApi.MulMod(&p.X, &omega), Y: p.Y}
+	p1 := c.Select(sign1, c.Neg(p), p)
+	p2 := c.Select(sign2, c.Neg(phi), phi)
+
+	// p1 + p2, -p1 - p2, p1 - p2, -p1 + p2
+	sum := c.add(p1, p2)
+	diff := c.add(p1, c.Neg(p2))
+	acc := c.add(&c.g, sum)
+	for i := nbBits - 1; i > 0; i-- {
+		b := c.Lookup2(s1Bits[i], s2Bits[i], c.Neg(sum), diff, c.Neg(diff), sum)
+		acc = c.doubleAndAdd(acc, b)
+	}
+
+	// i = 0
+	// we use AddUnified here so that when s=0, the result is (0,0) after
+	// subtracting the offset
+	acc = c.Select(s1Bits[0], acc, c.AddUnified(acc, c.Neg(p1)))
+	acc = c.Select(s2Bits[0], acc, c.AddUnified(acc, c.Neg(p2)))
+	// gm[i] = [2^i]g for i ≥ 3
+	return c.AddUnified(acc, c.Neg(&c.gm[nbBits-1]))
+}
+
+// limbsConstants returns the inputs of decomposeScalarGLV before the limbs of
+// the scalar: the number of bits per limb, the number of limbs, and the limbs of
+// the modulus r and of the eigenvalue λ, all constants.
+func limbsConstants(bitsPerLimb uint, r, lambda *big.Int, nbLimbs int) []frontend.Variable {
+	res := []frontend.Variable{bitsPerLimb, nbLimbs}
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bitsPerLimb), big.NewInt(1))
+	for _, v := range []*big.Int{r, lambda} {
+		for i := 0; i < nbLimbs; i++ {
+			res = append(res, new(big.Int).And(new(big.Int).Rsh(v, uint(i)*bitsPerLimb), mask))
+		}
+	}
+	return res
+}
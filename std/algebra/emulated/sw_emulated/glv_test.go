@@ -0,0 +1,138 @@
+package sw_emulated
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type ScalarMulGLVTest[T, S emulated.FieldParams] struct {
+	P, Q AffinePoint[T]
+	S    emulated.Element[S]
+	glv  bool
+}
+
+func (c *ScalarMulGLVTest[T, S]) Define(api frontend.API) error {
+	var opts []Option
+	if c.glv {
+		opts = append(opts, WithGLV())
+	}
+	cr, err := New[T, S](api, GetCurveParams[T](), opts...)
+	if err != nil {
+		return err
+	}
+	res := cr.ScalarMul(&c.P, &c.S)
+	cr.AssertIsEqual(res, &c.Q)
+	return nil
+}
+
+func TestEndomorphism(t *testing.T) {
+	assert := test.NewAssert(t)
+	// [λ]g = (ω·x, y)
+	for _, c := range []struct {
+		params CurveParams
+		p      *big.Int
+	}{
+		{GetSecp256k1Params(), emulated.Secp256k1Fp{}.Modulus()},
+		{GetBN254Params(), emulated.BN254Fp{}.Modulus()},
+	} {
+		q := nativeScalarMul(c.params, c.p, c.params.Eigenvalue)
+		x := new(big.Int).Mul(c.params.Gx, c.params.ThirdRootOne)
+		assert.Equal(0, q[0].Cmp(x.Mod(x, c.p)))
+		assert.Equal(0, q[1].Cmp(c.params.Gy))
+	}
+}
+
+func TestScalarMulGLV(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, g := secp256k1.Generators()
+	for _, s := range []*big.Int{
+		randomScalar(t, ecc.SECP256K1.ScalarField()),
+		big.NewInt(1),
+		new(big.Int).Sub(ecc.SECP256K1.ScalarField(), big.NewInt(1)),
+	} {
+		var S secp256k1.G1Affine
+		S.ScalarMultiplication(&g, s)
+		circuit := ScalarMulGLVTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{glv: true}
+		witness := ScalarMulGLVTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			S: emulated.ValueOf[emulated.Secp256k1Fr](s),
+			P: AffinePoint[emulated.Secp256k1Fp]{
+				X: emulated.ValueOf[emulated.Secp256k1Fp](g.X),
+				Y: emulated.ValueOf[emulated.Secp256k1Fp](g.Y),
+			},
+			Q: AffinePoint[emulated.Secp256k1Fp]{
+				X: emulated.ValueOf[emulated.Secp256k1Fp](S.X),
+				Y: emulated.ValueOf[emulated.Secp256k1Fp](S.Y),
+			},
+		}
+		err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.NoError(err, "s = %s", s)
+	}
+}
+
+func TestScalarMulGLV2(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g, _ := bn254.Generators()
+	var S, infinity bn254.G1Affine
+	s := randomScalar(t, ecc.BN254.ScalarField())
+	S.ScalarMultiplication(&g, s)
+
+	circuit := ScalarMulGLVTest[emulated.BN254Fp, emulated.BN254Fr]{glv: true}
+	for _, c := range []struct {
+		s       *big.Int
+		p, q    bn254.G1Affine
+		comment string
+	}{
+		{s, g, S, "s * g"},
+		{new(big.Int), S, infinity, "0 * S == (0,0)"},
+		{s, infinity, infinity, "s * (0,0) == (0,0)"},
+	} {
+		witness := ScalarMulGLVTest[emulated.BN254Fp, emulated.BN254Fr]{
+			S: emulated.ValueOf[emulated.BN254Fr](c.s),
+			P: AffinePoint[emulated.BN254Fp]{
+				X: emulated.ValueOf[emulated.BN254Fp](c.p.X),
+				Y: emulated.ValueOf[emulated.BN254Fp](c.p.Y),
+			},
+			Q: AffinePoint[emulated.BN254Fp]{
+				X: emulated.ValueOf[emulated.BN254Fp](c.q.X),
+				Y: emulated.ValueOf[emulated.BN254Fp](c.q.Y),
+			},
+		}
+		err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.NoError(err, c.comment)
+	}
+}
+
+func TestScalarMulGLVConstraints(t *testing.T) {
+	assert := test.NewAssert(t)
+	nbConstraints := func(glv bool) int {
+		ccs, err := frontend.Compile(testCurve.ScalarField(), r1cs.NewBuilder, &ScalarMulGLVTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{glv: glv})
+		assert.NoError(err)
+		return ccs.GetNbConstraints()
+	}
+	withGLV, without := nbConstraints(true), nbConstraints(false)
+	assert.Less(withGLV, without)
+	t.Logf("ScalarMul: %d constraints with GLV, %d without", withGLV, without)
+}
+
+func TestNewGLVNoEndomorphism(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, err := New[emulated.P256Fp, emulated.P256Fr](nil, GetP256Params(), WithGLV())
+	assert.Error(err)
+}
+
+func randomScalar(t *testing.T, r *big.Int) *big.Int {
+	s, err := rand.Int(rand.Reader, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package sw_emulated
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hints used in this package
+func GetHints() []solver.Hint {
+	return []solver.Hint{decomposeScalarGLV}
+}
+
+// decomposeScalarGLV returns |s₁|, |s₂| and their signs (1 if negative) such that
+// s = s₁ + λ·s₂ mod r, given the inputs of limbsConstants and the limbs of s.
+func decomposeScalarGLV(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) < 2 || len(outputs) != 4 {
+		return errors.New("expecting the limb parameters and four outputs")
+	}
+	bitsPerLimb, nbLimbs := uint(inputs[0].Uint64()), int(inputs[1].Int64())
+	if len(inputs) != 2+3*nbLimbs {
+		return errors.New("expecting the limbs of the modulus, the eigenvalue and the scalar")
+	}
+	values := make([]*big.Int, 3)
+	for j := range values {
+		values[j] = new(big.Int)
+		for i := nbLimbs - 1; i >= 0; i-- {
+			values[j].Lsh(values[j], bitsPerLimb).Add(values[j], inputs[2+j*nbLimbs+i])
+		}
+	}
+	r, lambda, s := values[0], values[1], values[2]
+	s.Mod(s, r)
+
+	var lattice ecc.Lattice
+	ecc.PrecomputeLattice(r, lambda, &lattice)
+	k := ecc.SplitScalar(s, &lattice)
+	for i := range k {
+		outputs[i].Abs(&k[i])
+		if k[i].Sign() < 0 {
+			outputs[2+i].SetUint64(1)
+		} else {
+			outputs[2+i].SetUint64(0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package sw_emulated
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// JointScalarMulBase computes [s1]g + [s2]p and returns it, where g is the
+// fixed generator. It doesn't modify p, s1 nor s2.
+//
+// ✅ p can be (0,0) and s1, s2 can be 0.
+//
+// It shares the doublings of the two scalar multiplications, see
+// [Curve.MultiScalarMul].
+func (c *Curve[B, S]) JointScalarMulBase(p *AffinePoint[B], s2, s1 *emulated.Element[S]) *AffinePoint[B] {
+	res, err := c.MultiScalarMul([]*AffinePoint[B]{&c.g, p}, []*emulated.Element[S]{s1, s2})
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// MultiScalarMul computes the multi-scalar multiplication Σ [s[i]]p[i] and
+// returns it. It doesn't modify p nor s. It returns an error if p and s are of
+// different lengths or empty.
+//
+// ✅ The points can be (0,0) and the scalars can be 0.
+//
+// It computes a joint left-to-right double-and-add on windows of 2 bits of the
+// scalars, so that the doublings are shared by all the points and there is an
+// addition per window and point. The bits bᵢ are read as the digits 2bᵢ-1 in
+// {-1, 1}, and the windows as the digits in {-3, -1, 1, 3}, looked up from the
+// precomputed ±p[i] and ±[3]p[i], which are never zero. The accumulator starts
+// from Σ p[i] as if the top bits were 1, and the bits at position 0 finally
+// subtract p[i] if they are 0.
+//
+// As we use incomplete formulas for the addition law, the accumulator is
+// offset by a point o of unknown discrete logarithm, so that it never meets the
+// added points, and [2ⁿ⁻¹]o is subtracted at the end.
+func (c *Curve[B, S]) MultiScalarMul(p []*AffinePoint[B], s []*emulated.Element[S]) (*AffinePoint[B], error) {
+	if len(p) != len(s) {
+		return nil, fmt.Errorf("mismatching lengths: %d points and %d scalars", len(p), len(s))
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("no points")
+	}
+	var (
+		bt B
+		st S
+	)
+	// n covers all the bits of the reduced scalars, which may be larger than the
+	// modulus, and is odd, so that the bits 1 to n-1 are split in windows of 2 bits
+	n := int(st.NbLimbs()*st.BitsPerLimb()) | 1
+
+	// the zero points are replaced by g, and their scalars by 0
+	points := make([]*AffinePoint[B], len(p))
+	bits := make([][]frontend.Variable, len(p))
+	zero := emulated.ValueOf[S](0)
+	for i := range p {
+		selector := c.api.And(c.baseApi.IsZero(&p[i].X), c.baseApi.IsZero(&p[i].Y))
+		points[i] = c.Select(selector, &c.g, p[i])
+		sr := c.scalarApi.Reduce(c.scalarApi.Select(selector, &zero, s[i]))
+		bits[i] = c.scalarApi.ToBits(sr)
+		for len(bits[i]) < n {
+			bits[i] = append(bits[i], 0)
+		}
+	}
+
+	// -[3]p[i], -p[i], p[i], [3]p[i]
+	tables := make([][4]*AffinePoint[B], len(p))
+	for i, q := range points {
+		triple := c.triple(q)
+		tables[i] = [4]*AffinePoint[B]{c.Neg(triple), c.Neg(q), q, triple}
+	}
+
+	o := offsetPoint(c.params.A, c.params.B, bt.Modulus())
+	acc := &AffinePoint[B]{X: emulated.ValueOf[B](o[0]), Y: emulated.ValueOf[B](o[1])}
+	for _, q := range points {
+		acc = c.add(acc, q)
+	}
+	for i := n - 1; i > 0; i -= 2 {
+		acc = c.double(acc)
+		for j, t := range tables {
+			d := c.Lookup2(bits[j][i-1], bits[j][i], t[0], t[1], t[2], t[3])
+			if j == 0 {
+				acc = c.doubleAndAdd(acc, d)
+			} else {
+				acc = c.add(acc, d)
+			}
+		}
+	}
+
+	// i = 0
+	// we use AddUnified here so that when the result is zero, it is (0,0) after
+	// subtracting the offset
+	for j, q := range points {
+		acc = c.Select(bits[j][0], acc, c.AddUnified(acc, c.Neg(q)))
+	}
+	for i := 1; i < n; i++ {
+		o = affineDouble(c.params.A, bt.Modulus(), o)
+	}
+	oNeg := &AffinePoint[B]{
+		X: emulated.ValueOf[B](o[0]),
+		Y: emulated.ValueOf[B](new(big.Int).Sub(bt.Modulus(), o[1])),
+	}
+	return c.AddUnified(acc, oNeg), nil
+}
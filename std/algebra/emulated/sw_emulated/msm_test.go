@@ -0,0 +1,148 @@
+package sw_emulated
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type MultiScalarMulTest[T, S emulated.FieldParams] struct {
+	Points  []AffinePoint[T]
+	Scalars []emulated.Element[S]
+	Res     AffinePoint[T]
+}
+
+func (c *MultiScalarMulTest[T, S]) Define(api frontend.API) error {
+	cr, err := New[T, S](api, GetCurveParams[T]())
+	if err != nil {
+		return err
+	}
+	ps := make([]*AffinePoint[T], len(c.Points))
+	for i := range c.Points {
+		ps[i] = &c.Points[i]
+	}
+	ss := make([]*emulated.Element[S], len(c.Scalars))
+	for i := range c.Scalars {
+		ss[i] = &c.Scalars[i]
+	}
+	res, err := cr.MultiScalarMul(ps, ss)
+	if err != nil {
+		return err
+	}
+	cr.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestMultiScalarMul(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, g := secp256k1.Generators()
+	r := ecc.SECP256K1.ScalarField()
+	var infinity secp256k1.G1Affine
+
+	for _, c := range []struct {
+		// the points are the multiples of g by k
+		k, s    []*big.Int
+		comment string
+	}{
+		{[]*big.Int{randomScalar(t, r), randomScalar(t, r), randomScalar(t, r)}, []*big.Int{randomScalar(t, r), randomScalar(t, r), randomScalar(t, r)}, "random"},
+		{[]*big.Int{randomScalar(t, r), big.NewInt(0)}, []*big.Int{randomScalar(t, r), randomScalar(t, r)}, "zero point"},
+		{[]*big.Int{randomScalar(t, r), randomScalar(t, r)}, []*big.Int{big.NewInt(0), randomScalar(t, r)}, "zero scalar"},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(2), new(big.Int).Sub(r, big.NewInt(1))}, "zero result"},
+	} {
+		circuit := MultiScalarMulTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			Points:  make([]AffinePoint[emulated.Secp256k1Fp], len(c.k)),
+			Scalars: make([]emulated.Element[emulated.Secp256k1Fr], len(c.s)),
+		}
+		witness := MultiScalarMulTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			Points:  make([]AffinePoint[emulated.Secp256k1Fp], len(c.k)),
+			Scalars: make([]emulated.Element[emulated.Secp256k1Fr], len(c.s)),
+		}
+		expected := new(big.Int)
+		for i := range c.k {
+			var p secp256k1.G1Affine
+			if c.k[i].Sign() == 0 {
+				p = infinity
+			} else {
+				p.ScalarMultiplication(&g, c.k[i])
+			}
+			witness.Points[i] = AffinePoint[emulated.Secp256k1Fp]{
+				X: emulated.ValueOf[emulated.Secp256k1Fp](p.X),
+				Y: emulated.ValueOf[emulated.Secp256k1Fp](p.Y),
+			}
+			witness.Scalars[i] = emulated.ValueOf[emulated.Secp256k1Fr](c.s[i])
+			expected.Add(expected, new(big.Int).Mul(c.k[i], c.s[i]))
+		}
+		var res secp256k1.G1Affine
+		if expected.Mod(expected, r).Sign() == 0 {
+			res = infinity
+		} else {
+			res.ScalarMultiplication(&g, expected)
+		}
+		witness.Res = AffinePoint[emulated.Secp256k1Fp]{
+			X: emulated.ValueOf[emulated.Secp256k1Fp](res.X),
+			Y: emulated.ValueOf[emulated.Secp256k1Fp](res.Y),
+		}
+		err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+		assert.NoError(err, c.comment)
+	}
+}
+
+func TestMultiScalarMulLengths(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := MultiScalarMulTest[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+		Points:  make([]AffinePoint[emulated.Secp256k1Fp], 2),
+		Scalars: make([]emulated.Element[emulated.Secp256k1Fr], 1),
+	}
+	_, err := frontend.Compile(testCurve.ScalarField(), r1cs.NewBuilder, &circuit)
+	assert.Error(err)
+}
+
+type JointScalarMulBaseTest[T, S emulated.FieldParams] struct {
+	P, Res AffinePoint[T]
+	S1, S2 emulated.Element[S]
+}
+
+func (c *JointScalarMulBaseTest[T, S]) Define(api frontend.API) error {
+	cr, err := New[T, S](api, GetCurveParams[T]())
+	if err != nil {
+		return err
+	}
+	res := cr.JointScalarMulBase(&c.P, &c.S2, &c.S1)
+	cr.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestJointScalarMulBase(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g, _ := bn254.Generators()
+	r := ecc.BN254.ScalarField()
+	k, s1, s2 := randomScalar(t, r), randomScalar(t, r), randomScalar(t, r)
+	var p, res bn254.G1Affine
+	p.ScalarMultiplication(&g, k)
+	e := new(big.Int).Mul(k, s2)
+	e.Add(e, s1)
+	res.ScalarMultiplication(&g, e.Mod(e, r))
+
+	circuit := JointScalarMulBaseTest[emulated.BN254Fp, emulated.BN254Fr]{}
+	witness := JointScalarMulBaseTest[emulated.BN254Fp, emulated.BN254Fr]{
+		P: AffinePoint[emulated.BN254Fp]{
+			X: emulated.ValueOf[emulated.BN254Fp](p.X),
+			Y: emulated.ValueOf[emulated.BN254Fp](p.Y),
+		},
+		Res: AffinePoint[emulated.BN254Fp]{
+			X: emulated.ValueOf[emulated.BN254Fp](res.X),
+			Y: emulated.ValueOf[emulated.BN254Fp](res.Y),
+		},
+		S1: emulated.ValueOf[emulated.BN254Fr](s1),
+		S2: emulated.ValueOf[emulated.BN254Fr](s2),
+	}
+	err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
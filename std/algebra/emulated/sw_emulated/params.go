@@ -1,10 +1,14 @@
 package sw_emulated
 
 import (
+	"crypto/elliptic"
 	"math/big"
+	"sync"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
 	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
 	"github.com/consensys/gnark/std/math/emulated"
 )
 
@@ -14,12 +18,19 @@ import (
 //	Y² = X³ + aX + b
 //
 // The base point is defined by (Gx, Gy).
+//
+// The curves with an efficient endomorphism φ(x, y) = (ω·x, y), ω being a
+// primitive cube root of unity in the base field, also define its eigenvalue
+// λ, such that φ(P) = [λ]P, for the GLV scalar multiplication (see [WithGLV]).
 type CurveParams struct {
 	A  *big.Int      // a in curve equation
 	B  *big.Int      // b in curve equation
 	Gx *big.Int      // base point x
 	Gy *big.Int      // base point y
 	Gm [][2]*big.Int // m*base point coords
+
+	ThirdRootOne *big.Int // ω, nil without endomorphism
+	Eigenvalue   *big.Int // λ, nil without endomorphism
 }
 
 // GetSecp256k1Params returns curve parameters for the curve secp256k1. When
@@ -33,6 +44,9 @@ func GetSecp256k1Params() CurveParams {
 		Gx: g1aff.X.BigInt(new(big.Int)),
 		Gy: g1aff.Y.BigInt(new(big.Int)),
 		Gm: computeSecp256k1Table(),
+
+		ThirdRootOne: mustParse("55594575648329892869085402983802832744385952214688224221778511981742606582254"),
+		Eigenvalue:   mustParse("37718080363155996902926221483475020450927657555482586988616620542887997980018"),
 	}
 }
 
@@ -47,6 +61,9 @@ func GetBN254Params() CurveParams {
 		Gx: g1aff.X.BigInt(new(big.Int)),
 		Gy: g1aff.Y.BigInt(new(big.Int)),
 		Gm: computeBN254Table(),
+
+		ThirdRootOne: mustParse("2203960485148121921418603742825762020974279258880205651966"),
+		Eigenvalue:   mustParse("4407920970296243842393367215006156084916469457145843978461"),
 	}
 }
 
@@ -64,29 +81,140 @@ func GetBLS12381Params() CurveParams {
 	}
 }
 
+// GetBW6761Params returns the curve parameters for the curve BW6-761. When
+// initialising new curve, use the base field [emulated.BW6761Fp] and scalar
+// field [emulated.BW6761Fr].
+func GetBW6761Params() CurveParams {
+	_, _, g1aff, _ := bw6761.Generators()
+	a, p := big.NewInt(0), emulated.BW6761Fp{}.Modulus()
+	gx, gy := g1aff.X.BigInt(new(big.Int)), g1aff.Y.BigInt(new(big.Int))
+	return CurveParams{
+		A:  a,
+		B:  new(big.Int).Sub(p, big.NewInt(1)),
+		Gx: gx,
+		Gy: gy,
+		Gm: computeTableN(a, p, gx, gy, emulated.BW6761Fr{}.Modulus().BitLen()),
+	}
+}
+
+// GetP256Params returns the curve parameters for the curve NIST P-256
+// (secp256r1). When initialising new curve, use the base field
+// [emulated.P256Fp] and scalar field [emulated.P256Fr].
+func GetP256Params() CurveParams {
+	params := elliptic.P256().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	return CurveParams{
+		A:  a,
+		B:  new(big.Int).Set(params.B),
+		Gx: new(big.Int).Set(params.Gx),
+		Gy: new(big.Int).Set(params.Gy),
+		Gm: computeTable(a, params.P, params.Gx, params.Gy),
+	}
+}
+
+// GetStarkCurveParams returns the curve parameters for the Stark curve of
+// StarkNet. When initialising new curve, use the base field
+// [emulated.STARKCurveFp] and scalar field [emulated.STARKCurveFr].
+func GetStarkCurveParams() CurveParams {
+	_, g1aff := starkcurve.Generators()
+	a, b := starkcurve.CurveCoefficients()
+	res := CurveParams{
+		A:  a.BigInt(new(big.Int)),
+		B:  b.BigInt(new(big.Int)),
+		Gx: g1aff.X.BigInt(new(big.Int)),
+		Gy: g1aff.Y.BigInt(new(big.Int)),
+	}
+	res.Gm = computeTable(res.A, emulated.STARKCurveFp{}.Modulus(), res.Gx, res.Gy)
+	return res
+}
+
+// GetPallasParams returns the curve parameters for the curve Pallas of the
+// Pasta cycle. When initialising new curve, use the base field
+// [emulated.PallasFp] and scalar field [emulated.PallasFr].
+func GetPallasParams() CurveParams {
+	return getPastaParams(emulated.PallasFp{}.Modulus())
+}
+
+// GetVestaParams returns the curve parameters for the curve Vesta of the Pasta
+// cycle. When initialising new curve, use the base field [emulated.VestaFp] and
+// scalar field [emulated.VestaFr].
+func GetVestaParams() CurveParams {
+	return getPastaParams(emulated.VestaFp{}.Modulus())
+}
+
+// getPastaParams returns the parameters of the curve y² = x³ + 5 over the field
+// of modulus p, of generator (-1, 2).
+func getPastaParams(p *big.Int) CurveParams {
+	a, gx := big.NewInt(0), new(big.Int).Sub(p, big.NewInt(1))
+	return CurveParams{
+		A:  a,
+		B:  big.NewInt(5),
+		Gx: gx,
+		Gy: big.NewInt(2),
+		Gm: computeTable(a, p, gx, big.NewInt(2)),
+	}
+}
+
+func mustParse(decimal string) *big.Int {
+	res, ok := new(big.Int).SetString(decimal, 10)
+	if !ok {
+		panic("invalid constant " + decimal)
+	}
+	return res
+}
+
 // GetCurveParams returns suitable curve parameters given the parametric type Base as base field.
+// The parameters of the curves of this package are registered, and others may be registered
+// with [RegisterCurveParams]. It panics if no parameters are registered for the modulus of
+// Base.
 func GetCurveParams[Base emulated.FieldParams]() CurveParams {
 	var t Base
-	switch t.Modulus().Text(16) {
-	case "fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f":
-		return secp256k1Params
-	case "30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47":
-		return bn254Params
-	case "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab":
-		return bls12381Params
-	default:
+	curveParamsLock.RLock()
+	defer curveParamsLock.RUnlock()
+	params, ok := curveParams[t.Modulus().Text(16)]
+	if !ok {
 		panic("no stored parameters")
 	}
+	return params
+}
+
+// RegisterCurveParams registers the parameters of a curve over the base field Base, so that
+// GetCurveParams[Base] returns them, for instance for a curve which isn't provided by this
+// package. The parameters replace those registered for the same modulus. The multiples of the
+// generator Gm used by [Curve.ScalarMulBase] are computed if they are not given, the order of
+// the generator being then an odd prime larger than 7. It panics if the generator is not on
+// the curve.
+func RegisterCurveParams[Base emulated.FieldParams](params CurveParams) {
+	var t Base
+	p := t.Modulus()
+	// y² = x³ + ax + b
+	lhs := new(big.Int).Mul(params.Gy, params.Gy)
+	rhs := new(big.Int).Mul(params.Gx, params.Gx)
+	rhs.Add(rhs, params.A).Mul(rhs, params.Gx).Add(rhs, params.B)
+	if lhs.Sub(lhs, rhs).Mod(lhs, p).Sign() != 0 {
+		panic("the generator is not on the curve")
+	}
+	if len(params.Gm) == 0 {
+		params.Gm = computeTable(params.A, p, params.Gx, params.Gy)
+	}
+
+	curveParamsLock.Lock()
+	defer curveParamsLock.Unlock()
+	curveParams[p.Text(16)] = params
 }
 
 var (
-	secp256k1Params CurveParams
-	bn254Params     CurveParams
-	bls12381Params  CurveParams
+	curveParamsLock sync.RWMutex
+	curveParams     = make(map[string]CurveParams) // by the hexadecimal modulus of the base field
 )
 
 func init() {
-	secp256k1Params = GetSecp256k1Params()
-	bn254Params = GetBN254Params()
-	bls12381Params = GetBLS12381Params()
+	RegisterCurveParams[emulated.Secp256k1Fp](GetSecp256k1Params())
+	RegisterCurveParams[emulated.BN254Fp](GetBN254Params())
+	RegisterCurveParams[emulated.BLS12381Fp](GetBLS12381Params())
+	RegisterCurveParams[emulated.BW6761Fp](GetBW6761Params())
+	RegisterCurveParams[emulated.P256Fp](GetP256Params())
+	RegisterCurveParams[emulated.STARKCurveFp](GetStarkCurveParams())
+	RegisterCurveParams[emulated.PallasFp](GetPallasParams())
+	RegisterCurveParams[emulated.VestaFp](GetVestaParams())
 }
@@ -1,6 +1,7 @@
 package sw_emulated
 
 import (
+	"crypto/sha256"
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254"
@@ -58,3 +59,75 @@ func computeBN254Table() [][2]*big.Int {
 	}
 	return table
 }
+
+// computeTable returns the multiples of the generator (gx, gy) used by ScalarMulBase, [3]g,
+// [5]g, [7]g and then [2^i]g from i = 3, for the curve of coefficient a over the field of
+// modulus p, with affine arithmetic. The order of the generator must be an odd prime larger
+// than 7.
+func computeTable(a, p, gx, gy *big.Int) [][2]*big.Int {
+	return computeTableN(a, p, gx, gy, 256)
+}
+
+// computeTableN is as computeTable, for the scalars of up to n bits.
+func computeTableN(a, p, gx, gy *big.Int, n int) [][2]*big.Int {
+	g := [2]*big.Int{gx, gy}
+	table := make([][2]*big.Int, n)
+	tmp := g
+	for i := 1; i < n; i++ {
+		tmp = affineDouble(a, p, tmp)
+		switch i {
+		case 1, 2:
+			table[i-1] = affineAdd(p, tmp, g)
+		case 3:
+			table[i-1] = affineAdd(p, tmp, [2]*big.Int{g[0], new(big.Int).Sub(p, g[1])})
+			fallthrough
+		default:
+			table[i] = tmp
+		}
+	}
+	return table
+}
+
+// affineAdd returns p1 + p2, for p1 ≠ ±p2.
+func affineAdd(p *big.Int, p1, p2 [2]*big.Int) [2]*big.Int {
+	dx := new(big.Int).Sub(p2[0], p1[0])
+	lambda := new(big.Int).Sub(p2[1], p1[1])
+	lambda.Mul(lambda, dx.ModInverse(dx.Mod(dx, p), p)).Mod(lambda, p)
+	return affineLine(p, lambda, p1, p2[0])
+}
+
+// affineDouble returns 2·p1, for p1 of non-zero y.
+func affineDouble(a, p *big.Int, p1 [2]*big.Int) [2]*big.Int {
+	num := new(big.Int).Mul(p1[0], p1[0])
+	num.Mul(num, big.NewInt(3)).Add(num, a)
+	den := new(big.Int).Lsh(p1[1], 1)
+	lambda := num.Mul(num, den.ModInverse(den.Mod(den, p), p)).Mod(num, p)
+	return affineLine(p, lambda, p1, p1[0])
+}
+
+// affineLine returns the third point of the line of slope lambda through p1 and the point of
+// abscissa x2, negated.
+func affineLine(p, lambda *big.Int, p1 [2]*big.Int, x2 *big.Int) [2]*big.Int {
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, p1[0]).Sub(x3, x2).Mod(x3, p)
+	y3 := new(big.Int).Sub(p1[0], x3)
+	y3.Mul(y3, lambda).Sub(y3, p1[1]).Mod(y3, p)
+	return [2]*big.Int{x3, y3}
+}
+
+// offsetPoint returns a point of the curve y² = x³ + ax + b over the field of modulus p whose
+// discrete logarithm to the generator is unknown: its abscissa is the first one from the hash
+// of a fixed string, incremented, for which x³ + ax + b is a square.
+func offsetPoint(a, b, p *big.Int) [2]*big.Int {
+	h := sha256.Sum256([]byte("gnark sw_emulated offset point"))
+	x := new(big.Int).SetBytes(h[:])
+	x.Mod(x, p)
+	for {
+		rhs := new(big.Int).Mul(x, x)
+		rhs.Add(rhs, a).Mul(rhs, x).Add(rhs, b).Mod(rhs, p)
+		if y := new(big.Int).ModSqrt(rhs, p); y != nil && y.Sign() != 0 {
+			return [2]*big.Int{x, y}
+		}
+		x.Add(x, big.NewInt(1)).Mod(x, p)
+	}
+}
@@ -0,0 +1,171 @@
+package sw_emulated
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+func TestComputeTable(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetSecp256k1Params()
+	table := computeTable(params.A, emulated.Secp256k1Fp{}.Modulus(), params.Gx, params.Gy)
+	for i := range table {
+		assert.Equal(0, table[i][0].Cmp(params.Gm[i][0]), "x of the entry %d", i)
+		assert.Equal(0, table[i][1].Cmp(params.Gm[i][1]), "y of the entry %d", i)
+	}
+}
+
+func TestPastaParams(t *testing.T) {
+	assert := test.NewAssert(t)
+	// the generators are of the order of the scalar fields: [r-1]g = -g
+	for _, c := range []struct {
+		params CurveParams
+		p, r   *big.Int
+	}{
+		{GetPallasParams(), emulated.PallasFp{}.Modulus(), emulated.PallasFr{}.Modulus()},
+		{GetVestaParams(), emulated.VestaFp{}.Modulus(), emulated.VestaFr{}.Modulus()},
+	} {
+		q := nativeScalarMul(c.params, c.p, new(big.Int).Sub(c.r, big.NewInt(1)))
+		assert.Equal(0, q[0].Cmp(c.params.Gx))
+		assert.Equal(0, q[1].Cmp(new(big.Int).Sub(c.p, c.params.Gy)))
+	}
+}
+
+func TestScalarMulBaseP256(t *testing.T) {
+	assert := test.NewAssert(t)
+	s, err := rand.Int(rand.Reader, emulated.P256Fr{}.Modulus())
+	assert.NoError(err)
+	x, y := elliptic.P256().ScalarBaseMult(s.Bytes())
+
+	circuit := ScalarMulBaseTest[emulated.P256Fp, emulated.P256Fr]{}
+	witness := ScalarMulBaseTest[emulated.P256Fp, emulated.P256Fr]{
+		S: emulated.ValueOf[emulated.P256Fr](s),
+		Q: AffinePoint[emulated.P256Fp]{
+			X: emulated.ValueOf[emulated.P256Fp](x),
+			Y: emulated.ValueOf[emulated.P256Fp](y),
+		},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+func TestScalarMulBaseStarkCurve(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, g := starkcurve.Generators()
+	s, err := rand.Int(rand.Reader, emulated.STARKCurveFr{}.Modulus())
+	assert.NoError(err)
+	var S starkcurve.G1Affine
+	S.ScalarMultiplication(&g, s)
+
+	circuit := ScalarMulBaseTest[emulated.STARKCurveFp, emulated.STARKCurveFr]{}
+	witness := ScalarMulBaseTest[emulated.STARKCurveFp, emulated.STARKCurveFr]{
+		S: emulated.ValueOf[emulated.STARKCurveFr](s),
+		Q: AffinePoint[emulated.STARKCurveFp]{
+			X: emulated.ValueOf[emulated.STARKCurveFp](S.X),
+			Y: emulated.ValueOf[emulated.STARKCurveFp](S.Y),
+		},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+func TestScalarMulBasePallas(t *testing.T) {
+	assert := test.NewAssert(t)
+	s, err := rand.Int(rand.Reader, emulated.PallasFr{}.Modulus())
+	assert.NoError(err)
+	q := nativeScalarMul(GetPallasParams(), emulated.PallasFp{}.Modulus(), s)
+
+	circuit := ScalarMulBaseTest[emulated.PallasFp, emulated.PallasFr]{}
+	witness := ScalarMulBaseTest[emulated.PallasFp, emulated.PallasFr]{
+		S: emulated.ValueOf[emulated.PallasFr](s),
+		Q: AffinePoint[emulated.PallasFp]{
+			X: emulated.ValueOf[emulated.PallasFp](q[0]),
+			Y: emulated.ValueOf[emulated.PallasFp](q[1]),
+		},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+func TestScalarMulBaseBW6761(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g, _ := bw6761.Generators()
+	s, err := rand.Int(rand.Reader, emulated.BW6761Fr{}.Modulus())
+	assert.NoError(err)
+	var S bw6761.G1Affine
+	S.ScalarMultiplication(&g, s)
+
+	circuit := ScalarMulBaseTest[emulated.BW6761Fp, emulated.BW6761Fr]{}
+	witness := ScalarMulBaseTest[emulated.BW6761Fp, emulated.BW6761Fr]{
+		S: emulated.ValueOf[emulated.BW6761Fr](s),
+		Q: AffinePoint[emulated.BW6761Fp]{
+			X: emulated.ValueOf[emulated.BW6761Fp](S.X),
+			Y: emulated.ValueOf[emulated.BW6761Fp](S.Y),
+		},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+// bls12377Fr is the scalar field of BLS12-377, whose curve isn't provided by the package.
+type bls12377Fr struct{}
+
+func (fr bls12377Fr) NbLimbs() uint     { return 4 }
+func (fr bls12377Fr) BitsPerLimb() uint { return 64 }
+func (fr bls12377Fr) IsPrime() bool     { return true }
+func (fr bls12377Fr) Modulus() *big.Int { return ecc.BLS12_377.ScalarField() }
+
+func TestRegisterCurveParams(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g, _ := bls12377.Generators()
+	params := CurveParams{
+		A:  big.NewInt(0),
+		B:  big.NewInt(1),
+		Gx: g.X.BigInt(new(big.Int)),
+		Gy: g.Y.BigInt(new(big.Int)),
+	}
+	assert.Panics(func() {
+		wrong := params
+		wrong.B = big.NewInt(2)
+		RegisterCurveParams[emulated.BLS12377Fp](wrong)
+	})
+	RegisterCurveParams[emulated.BLS12377Fp](params)
+	assert.Len(GetCurveParams[emulated.BLS12377Fp]().Gm, 256)
+
+	s, err := rand.Int(rand.Reader, ecc.BLS12_377.ScalarField())
+	assert.NoError(err)
+	var S bls12377.G1Affine
+	S.ScalarMultiplication(&g, s)
+	circuit := ScalarMulBaseTest[emulated.BLS12377Fp, bls12377Fr]{}
+	witness := ScalarMulBaseTest[emulated.BLS12377Fp, bls12377Fr]{
+		S: emulated.ValueOf[bls12377Fr](s),
+		Q: AffinePoint[emulated.BLS12377Fp]{
+			X: emulated.ValueOf[emulated.BLS12377Fp](S.X),
+			Y: emulated.ValueOf[emulated.BLS12377Fp](S.Y),
+		},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+// nativeScalarMul returns [s]g by double-and-add in affine coordinates, for 1 < s < r-1.
+func nativeScalarMul(params CurveParams, p, s *big.Int) [2]*big.Int {
+	g := [2]*big.Int{params.Gx, params.Gy}
+	res := g
+	for i := s.BitLen() - 2; i >= 0; i-- {
+		res = affineDouble(params.A, p, res)
+		if s.Bit(i) == 1 {
+			res = affineAdd(p, res, g)
+		}
+	}
+	return res
+}
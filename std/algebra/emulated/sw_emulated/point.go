@@ -8,11 +8,39 @@ import (
 	"github.com/consensys/gnark/std/math/emulated"
 )
 
+// Option configures a [Curve].
+type Option func(*config) error
+
+type config struct {
+	glv bool
+}
+
+// WithGLV makes [Curve.ScalarMul] use the endomorphism of the curve, which must
+// be given by the parameters (for instance secp256k1 and BN254), to split the
+// scalars in two halves: it costs about half as many constraints. See
+// [Curve.ScalarMul].
+func WithGLV() Option {
+	return func(cfg *config) error {
+		cfg.glv = true
+		return nil
+	}
+}
+
 // New returns a new [Curve] instance over the base field Base and scalar field
 // Scalars defined by the curve parameters params. It returns an error if
 // initialising the field emulation fails (for example, when the native field is
-// too small) or when the curve parameters are incompatible with the fields.
-func New[Base, Scalars emulated.FieldParams](api frontend.API, params CurveParams) (*Curve[Base, Scalars], error) {
+// too small) or when the curve parameters are incompatible with the fields or
+// the options.
+func New[Base, Scalars emulated.FieldParams](api frontend.API, params CurveParams, opts ...Option) (*Curve[Base, Scalars], error) {
+	var cfg config
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	if cfg.glv && (params.ThirdRootOne == nil || params.Eigenvalue == nil) {
+		return nil, fmt.Errorf("the curve has no endomorphism for the GLV scalar multiplication")
+	}
 	ba, err := emulated.NewField[Base](api)
 	if err != nil {
 		return nil, fmt.Errorf("new base api: %w", err)
@@ -39,6 +67,7 @@ func New[Base, Scalars emulated.FieldParams](api frontend.API, params CurveParam
 		gm:   emuGm,
 		a:    emulated.ValueOf[Base](params.A),
 		addA: params.A.Cmp(big.NewInt(0)) != 0,
+		glv:  cfg.glv,
 	}, nil
 }
 
@@ -61,6 +90,9 @@ type Curve[Base, Scalars emulated.FieldParams] struct {
 
 	a    emulated.Element[Base]
 	addA bool
+
+	// glv is true if ScalarMul uses the endomorphism of the curve.
+	glv bool
 }
 
 // Generator returns the base point of the curve. The method does not copy and
@@ -352,6 +384,9 @@ func (c *Curve[B, S]) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 *AffinePo
 // positions 1, n-2 and n-1 outside of the loop to optimize the number of
 // constraints using [ELM03] (Section 3.1)
 //
+// With the option [WithGLV], it computes instead the joint double-and-add of
+// the GLV decomposition, see scalarMulGLV.
+//
 // [ELM03]: https://arxiv.org/pdf/math/0208038.pdf
 // [HMV04]: https://link.springer.com/book/10.1007/b97644
 // [EVM]: https://ethereum.github.io/yellowpaper/paper.pdf
@@ -362,6 +397,12 @@ func (c *Curve[B, S]) ScalarMul(p *AffinePoint[B], s *emulated.Element[S]) *Affi
 	one := c.baseApi.One()
 	p = c.Select(selector, &AffinePoint[B]{X: *one, Y: *one}, p)
 
+	if c.glv {
+		res := c.scalarMulGLV(p, s)
+		zero := c.baseApi.Zero()
+		return c.Select(selector, &AffinePoint[B]{X: *zero, Y: *zero}, res)
+	}
+
 	var st S
 	sr := c.scalarApi.Reduce(s)
 	sBits := c.scalarApi.ToBits(sr)
@@ -0,0 +1,108 @@
+package sw_bls12377
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/frontend"
+)
+
+var (
+	offsetOnce sync.Once
+	// offset is a point of unknown discrete logarithm and offsetN = [2ⁿ⁻¹]offset, with n
+	// the number of bits of the scalars in MultiScalarMul
+	offset, offsetN [2]*big.Int
+)
+
+// msmBits is the number of bits of the scalars in MultiScalarMul, odd.
+var msmBits = ecc.BLS12_377.ScalarField().BitLen() | 1
+
+func getOffsetPoints() ([2]*big.Int, [2]*big.Int) {
+	offsetOnce.Do(func() {
+		o, err := bls12377.HashToG1([]byte("offset point"), []byte("gnark sw_bls12377 msm"))
+		if err != nil {
+			panic(err)
+		}
+		var oN bls12377.G1Affine
+		oN.ScalarMultiplication(&o, new(big.Int).Lsh(big.NewInt(1), uint(msmBits-1)))
+		offset = [2]*big.Int{o.X.BigInt(new(big.Int)), o.Y.BigInt(new(big.Int))}
+		offsetN = [2]*big.Int{oN.X.BigInt(new(big.Int)), oN.Y.BigInt(new(big.Int))}
+	})
+	return offset, offsetN
+}
+
+// JointScalarMulBase computes [s1]g1 + [s2]Q and returns it, where g1 is the fixed
+// generator. It doesn't modify Q, s1 nor s2. See MultiScalarMul.
+func (P *G1Affine) JointScalarMulBase(api frontend.API, Q G1Affine, s1, s2 frontend.Variable) *G1Affine {
+	points := getCurvePoints()
+	return P.MultiScalarMul(api, []G1Affine{{points.G1x, points.G1y}, Q}, []frontend.Variable{s1, s2})
+}
+
+// MultiScalarMul computes Σ [s[i]]Q[i], sets P to it and returns P. It panics if Q and s
+// are of different lengths or empty.
+//
+// It computes a joint left-to-right double-and-add on windows of 2 bits of the scalars,
+// reading the bits bᵢ as the digits 2bᵢ-1 and the windows as the digits in {-3, -1, 1, 3},
+// looked up from ±Q[i] and ±[3]Q[i]: the doublings are shared by all the points. The
+// accumulator starts from Σ Q[i] as if the top bits were 1, offset by a point of unknown
+// discrete logarithm so that the incomplete formulas apply, and the bits at position 0
+// finally subtract Q[i] if they are 0.
+//
+// ⚠️  The points must be nonzero and so must be the result. The scalars are decomposed
+// on the bits of the order of the group.
+func (P *G1Affine) MultiScalarMul(api frontend.API, Q []G1Affine, s []frontend.Variable) *G1Affine {
+	if len(Q) != len(s) || len(Q) == 0 {
+		panic(fmt.Sprintf("expected as many points as scalars, got %d points and %d scalars", len(Q), len(s)))
+	}
+
+	bits := make([][]frontend.Variable, len(s))
+	for i := range s {
+		bits[i] = api.ToBinary(s[i], msmBits)
+	}
+
+	// -[3]Q[i], -Q[i], Q[i], [3]Q[i]
+	tables := make([][4]G1Affine, len(Q))
+	for i := range Q {
+		var triple, tripleNeg, neg G1Affine
+		triple.Double(api, Q[i])
+		triple.AddAssign(api, Q[i])
+		tripleNeg.Neg(api, triple)
+		neg.Neg(api, Q[i])
+		tables[i] = [4]G1Affine{tripleNeg, neg, Q[i], triple}
+	}
+
+	o, oN := getOffsetPoints()
+	acc := G1Affine{X: o[0], Y: o[1]}
+	for i := range Q {
+		acc.AddAssign(api, Q[i])
+	}
+	for i := msmBits - 1; i > 0; i -= 2 {
+		acc.Double(api, acc)
+		for j, t := range tables {
+			d := G1Affine{
+				X: api.Lookup2(bits[j][i-1], bits[j][i], t[0].X, t[1].X, t[2].X, t[3].X),
+				Y: api.Lookup2(bits[j][i-1], bits[j][i], t[0].Y, t[1].Y, t[2].Y, t[3].Y),
+			}
+			if j == 0 {
+				acc.DoubleAndAdd(api, &acc, &d)
+			} else {
+				acc.AddAssign(api, d)
+			}
+		}
+	}
+
+	// i = 0
+	for j := range Q {
+		tmp := acc
+		tmp.AddAssign(api, tables[j][1])
+		acc.Select(api, bits[j][0], acc, tmp)
+	}
+	acc.AddAssign(api, G1Affine{X: oN[0], Y: api.Neg(oN[1])})
+
+	P.X = acc.X
+	P.Y = acc.Y
+	return P
+}
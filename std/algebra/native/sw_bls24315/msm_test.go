@@ -0,0 +1,89 @@
+package sw_bls24315
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type g1MultiScalarMul struct {
+	A []G1Affine
+	C G1Affine `gnark:",public"`
+	R []frontend.Variable
+}
+
+func (circuit *g1MultiScalarMul) Define(api frontend.API) error {
+	expected := G1Affine{}
+	expected.MultiScalarMul(api, circuit.A, circuit.R)
+	expected.AssertIsEqual(api, circuit.C)
+	return nil
+}
+
+func TestMultiScalarMulG1(t *testing.T) {
+	const nbPoints = 3
+	circuit := g1MultiScalarMul{A: make([]G1Affine, nbPoints), R: make([]frontend.Variable, nbPoints)}
+	witness := g1MultiScalarMul{A: make([]G1Affine, nbPoints), R: make([]frontend.Variable, nbPoints)}
+
+	var c bls24315.G1Jac
+	for i := 0; i < nbPoints; i++ {
+		_a := randomPointG1()
+		var a bls24315.G1Affine
+		a.FromJacobian(&_a)
+		witness.A[i].Assign(&a)
+
+		var r fr.Element
+		_, _ = r.SetRandom()
+		witness.R[i] = r.String()
+		var br big.Int
+		_a.ScalarMultiplication(&_a, r.BigInt(&br))
+		c.AddAssign(&_a)
+	}
+	var cAff bls24315.G1Affine
+	cAff.FromJacobian(&c)
+	witness.C.Assign(&cAff)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type g1JointScalarMulBase struct {
+	A      G1Affine
+	C      G1Affine `gnark:",public"`
+	R1, R2 frontend.Variable
+}
+
+func (circuit *g1JointScalarMulBase) Define(api frontend.API) error {
+	expected := G1Affine{}
+	expected.JointScalarMulBase(api, circuit.A, circuit.R1, circuit.R2)
+	expected.AssertIsEqual(api, circuit.C)
+	return nil
+}
+
+func TestJointScalarMulBaseG1(t *testing.T) {
+	_a := randomPointG1()
+	var a, c bls24315.G1Affine
+	a.FromJacobian(&_a)
+	gJac, _, _, _ := bls24315.Generators()
+
+	var circuit, witness g1JointScalarMulBase
+	var r1, r2 fr.Element
+	_, _ = r1.SetRandom()
+	_, _ = r2.SetRandom()
+	witness.R1 = r1.String()
+	witness.R2 = r2.String()
+	witness.A.Assign(&a)
+	var br big.Int
+	gJac.ScalarMultiplication(&gJac, r1.BigInt(&br))
+	_a.ScalarMultiplication(&_a, r2.BigInt(&br))
+	gJac.AddAssign(&_a)
+	c.FromJacobian(&gJac)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
@@ -0,0 +1,130 @@
+package kzg_bn254
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/transcript"
+)
+
+// ValueOfVK returns the assignment of the verification key vk.
+func ValueOfVK(vk kzg.VerifyingKey) VK {
+	return VK{
+		G1: sw_bn254.NewG1Affine(vk.G1),
+		G2: [2]sw_bn254.G2Affine{sw_bn254.NewG2Affine(vk.G2[0]), sw_bn254.NewG2Affine(vk.G2[1])},
+	}
+}
+
+// ValueOfDigest returns the assignment of the commitment d.
+func ValueOfDigest(d kzg.Digest) Digest {
+	return sw_bn254.NewG1Affine(d)
+}
+
+// ValueOfScalar returns the assignment of the scalar s.
+func ValueOfScalar(s fr.Element) Scalar {
+	return emulated.ValueOf[emulated.BN254Fr](s)
+}
+
+// ValueOfOpeningProof returns the assignment of the opening proof.
+func ValueOfOpeningProof(proof kzg.OpeningProof) OpeningProof {
+	return OpeningProof{
+		H:            sw_bn254.NewG1Affine(proof.H),
+		ClaimedValue: ValueOfScalar(proof.ClaimedValue),
+	}
+}
+
+// ValueOfBatchOpeningProof returns the assignment of the batch opening proof.
+func ValueOfBatchOpeningProof(proof kzg.BatchOpeningProof) BatchOpeningProof {
+	res := BatchOpeningProof{
+		H:             sw_bn254.NewG1Affine(proof.H),
+		ClaimedValues: make([]Scalar, len(proof.ClaimedValues)),
+	}
+	for i := range proof.ClaimedValues {
+		res.ClaimedValues[i] = ValueOfScalar(proof.ClaimedValues[i])
+	}
+	return res
+}
+
+// BatchOpenSinglePoint creates a batch opening proof at point of a list of polynomials, as
+// kzg.BatchOpenSinglePoint, but with the challenge γ derived as by
+// [Verifier.AssertBatchProofSinglePoint].
+func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []kzg.Digest, point fr.Element, pk kzg.ProvingKey) (kzg.BatchOpeningProof, error) {
+	if len(polynomials) != len(digests) || len(polynomials) == 0 {
+		return kzg.BatchOpeningProof{}, kzg.ErrInvalidNbDigests
+	}
+	largestPoly := 0
+	for _, p := range polynomials {
+		if len(p) == 0 || len(p) > len(pk.G1) {
+			return kzg.BatchOpeningProof{}, kzg.ErrInvalidPolynomialSize
+		}
+		if len(p) > largestPoly {
+			largestPoly = len(p)
+		}
+	}
+
+	var res kzg.BatchOpeningProof
+	res.ClaimedValues = make([]fr.Element, len(polynomials))
+	for i := range polynomials {
+		res.ClaimedValues[i] = eval(polynomials[i], point)
+	}
+	gamma, err := DeriveGamma(point, digests, res.ClaimedValues)
+	if err != nil {
+		return kzg.BatchOpeningProof{}, err
+	}
+
+	// ∑ᵢγⁱfᵢ
+	folded := make([]fr.Element, largestPoly)
+	var gammaI, tmp fr.Element
+	gammaI.SetOne()
+	for _, p := range polynomials {
+		for j := range p {
+			tmp.Mul(&p[j], &gammaI)
+			folded[j].Add(&folded[j], &tmp)
+		}
+		gammaI.Mul(&gammaI, &gamma)
+	}
+	proof, err := kzg.Open(folded, point, pk)
+	if err != nil {
+		return kzg.BatchOpeningProof{}, err
+	}
+	res.H = proof.H
+	return res, nil
+}
+
+// DeriveGamma returns the challenge γ folding the openings of the digests at point, as
+// derived in the circuit: the challenge of a MiMC transcript absorbing the point, the
+// coordinates of the digests and the claimed values.
+func DeriveGamma(point fr.Element, digests []kzg.Digest, claimedValues []fr.Element) (fr.Element, error) {
+	t := transcript.NewNative(transcript.NewNativeHashSponge(hash.MIMC_BN254.New()), transcriptDomain)
+	transcript.AbsorbNativeElements[emulated.BN254Fr](t, "point", point.BigInt(new(big.Int)))
+	coordinates := make([]*big.Int, 0, 2*len(digests))
+	for i := range digests {
+		coordinates = append(coordinates, digests[i].X.BigInt(new(big.Int)), digests[i].Y.BigInt(new(big.Int)))
+	}
+	transcript.AbsorbNativeElements[emulated.BN254Fp](t, "digests", coordinates...)
+	values := make([]*big.Int, len(claimedValues))
+	for i := range claimedValues {
+		values[i] = claimedValues[i].BigInt(new(big.Int))
+	}
+	transcript.AbsorbNativeElements[emulated.BN254Fr](t, "claimed values", values...)
+	c, err := transcript.SqueezeNativeElement[emulated.BN254Fr](t, "gamma")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var gamma fr.Element
+	gamma.SetBigInt(c)
+	return gamma, nil
+}
+
+// eval returns p(point) where p is interpreted as a polynomial ∑_{i<len(p)}p[i]Xⁱ.
+func eval(p []fr.Element, point fr.Element) fr.Element {
+	var res fr.Element
+	for i := len(p) - 1; i >= 0; i-- {
+		res.Mul(&res, &point).Add(&res, &p[i])
+	}
+	return res
+}
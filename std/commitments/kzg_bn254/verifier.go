@@ -0,0 +1,164 @@
+// Package kzg_bn254 provides a ZKP-circuit function to verify BN254 KZG openings, with the
+// BN254 arithmetic emulated, inside a circuit over any field.
+//
+// The openings of several polynomials at a single point are folded with a challenge derived
+// by a MiMC transcript, see package [github.com/consensys/gnark/std/transcript], which requires
+// a BN254 circuit. The prover derives the same challenge with [BatchOpenSinglePoint].
+package kzg_bn254
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/transcript"
+)
+
+// transcriptDomain separates the transcripts of the batch openings.
+const transcriptDomain = "kzg_bn254"
+
+// Scalar is an element of the BN254 scalar field.
+type Scalar = emulated.Element[emulated.BN254Fr]
+
+// Digest commitment of a polynomial.
+type Digest = sw_bn254.G1Affine
+
+// VK verification key (G1 and G2 parts of SRS)
+type VK struct {
+	G1 sw_bn254.G1Affine    // G₁
+	G2 [2]sw_bn254.G2Affine // [G₂, [α]G₂]
+}
+
+// OpeningProof KZG proof for opening at a single point.
+type OpeningProof struct {
+	// H quotient polynomial (f - f(z))/(x-z)
+	H sw_bn254.G1Affine
+
+	// ClaimedValue purported value
+	ClaimedValue Scalar
+}
+
+// BatchOpeningProof opening proof for many polynomials at the same point.
+type BatchOpeningProof struct {
+	// H quotient polynomial Sum_i gamma**i*(f - f(z))/(x-z)
+	H sw_bn254.G1Affine
+
+	// ClaimedValues purported values
+	ClaimedValues []Scalar
+}
+
+// Verifier verifies KZG opening proofs.
+type Verifier struct {
+	api     frontend.API
+	curve   *sw_emulated.Curve[emulated.BN254Fp, emulated.BN254Fr]
+	base    *emulated.Field[emulated.BN254Fp]
+	scalars *emulated.Field[emulated.BN254Fr]
+	pairing *sw_bn254.Pairing
+}
+
+// NewVerifier returns a verifier of KZG opening proofs in the circuit of api.
+func NewVerifier(api frontend.API) (*Verifier, error) {
+	curve, err := sw_emulated.New[emulated.BN254Fp, emulated.BN254Fr](api, sw_emulated.GetBN254Params())
+	if err != nil {
+		return nil, fmt.Errorf("new curve: %w", err)
+	}
+	base, err := emulated.NewField[emulated.BN254Fp](api)
+	if err != nil {
+		return nil, fmt.Errorf("new base field: %w", err)
+	}
+	scalars, err := emulated.NewField[emulated.BN254Fr](api)
+	if err != nil {
+		return nil, fmt.Errorf("new scalar field: %w", err)
+	}
+	pairing, err := sw_bn254.NewPairing(api)
+	if err != nil {
+		return nil, fmt.Errorf("new pairing: %w", err)
+	}
+	return &Verifier{api: api, curve: curve, base: base, scalars: scalars, pairing: pairing}, nil
+}
+
+// AssertProof asserts that proof is a valid opening proof of the polynomial of commitment at
+// point.
+//
+// It checks e([f(α) - f(a) + a·H(α)]G₁, G₂)·e([-H(α)]G₁, [α]G₂) = 1, which needs no scalar
+// multiplication in G₂: the G₁ terms are computed with a multi-scalar multiplication.
+func (v *Verifier) AssertProof(vk VK, commitment Digest, proof OpeningProof, point *Scalar) error {
+	// [a·H(α) - f(a)]G₁
+	tmp, err := v.curve.MultiScalarMul(
+		[]*sw_bn254.G1Affine{&vk.G1, &proof.H},
+		[]*Scalar{v.scalars.Neg(&proof.ClaimedValue), point},
+	)
+	if err != nil {
+		return err
+	}
+	// [f(α) - f(a) + a·H(α)]G₁
+	lhs := v.curve.AddUnified(&commitment, tmp)
+
+	// [-H(α)]G₁
+	negH := v.curve.Neg(&proof.H)
+
+	if err := v.pairing.PairingCheck(
+		[]*sw_bn254.G1Affine{lhs, negH},
+		[]*sw_bn254.G2Affine{&vk.G2[0], &vk.G2[1]},
+	); err != nil {
+		return fmt.Errorf("pairing check: %w", err)
+	}
+	return nil
+}
+
+// AssertBatchProofSinglePoint asserts that proof is a valid opening proof of the polynomials
+// of digests at point, as produced by [BatchOpenSinglePoint]. The circuit must be over the
+// BN254 scalar field.
+func (v *Verifier) AssertBatchProofSinglePoint(vk VK, digests []Digest, proof BatchOpeningProof, point *Scalar) error {
+	if len(digests) != len(proof.ClaimedValues) || len(digests) == 0 {
+		return fmt.Errorf("expected as many digests as claimed values, got %d digests and %d values", len(digests), len(proof.ClaimedValues))
+	}
+	gamma, err := v.deriveGamma(digests, proof.ClaimedValues, point)
+	if err != nil {
+		return err
+	}
+
+	// ∑ᵢγⁱdigestᵢ and ∑ᵢγⁱf(a)
+	points := make([]*sw_bn254.G1Affine, len(digests))
+	gammas := make([]*Scalar, len(digests))
+	gammas[0] = v.scalars.One()
+	folded := &proof.ClaimedValues[0]
+	for i := range digests {
+		points[i] = &digests[i]
+		if i > 0 {
+			gammas[i] = v.scalars.MulMod(gammas[i-1], gamma)
+			folded = v.scalars.Add(folded, v.scalars.Mul(gammas[i], &proof.ClaimedValues[i]))
+		}
+	}
+	foldedDigest, err := v.curve.MultiScalarMul(points, gammas)
+	if err != nil {
+		return err
+	}
+
+	return v.AssertProof(vk, *foldedDigest, OpeningProof{H: proof.H, ClaimedValue: *v.scalars.Reduce(folded)}, point)
+}
+
+// deriveGamma returns the challenge γ of a MiMC transcript absorbing the point, the
+// coordinates of the digests and the claimed values, as DeriveGamma.
+func (v *Verifier) deriveGamma(digests []Digest, claimedValues []Scalar, point *Scalar) (*Scalar, error) {
+	h, err := mimc.NewMiMC(v.api)
+	if err != nil {
+		return nil, fmt.Errorf("new hash: %w", err)
+	}
+	t := transcript.New(transcript.NewHashSponge(&h), transcriptDomain)
+	transcript.AbsorbElements(v.api, v.scalars, t, "point", point)
+	coordinates := make([]*emulated.Element[emulated.BN254Fp], 0, 2*len(digests))
+	for i := range digests {
+		coordinates = append(coordinates, &digests[i].X, &digests[i].Y)
+	}
+	transcript.AbsorbElements(v.api, v.base, t, "digests", coordinates...)
+	values := make([]*Scalar, len(claimedValues))
+	for i := range claimedValues {
+		values[i] = &claimedValues[i]
+	}
+	transcript.AbsorbElements(v.api, v.scalars, t, "claimed values", values...)
+	return transcript.SqueezeElement(v.api, v.scalars, t, "gamma"), nil
+}
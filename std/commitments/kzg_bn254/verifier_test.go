@@ -0,0 +1,151 @@
+package kzg_bn254
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type verifierCircuit struct {
+	VerifKey VK
+	Proof    OpeningProof
+	Com      Digest
+	S        Scalar
+}
+
+func (circuit *verifierCircuit) Define(api frontend.API) error {
+	verifier, err := NewVerifier(api)
+	if err != nil {
+		return err
+	}
+	return verifier.AssertProof(circuit.VerifKey, circuit.Com, circuit.Proof, &circuit.S)
+}
+
+func newSRS(t *testing.T) *kzg.SRS {
+	alpha, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := kzg.NewSRS(64, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srs
+}
+
+func randomPolynomial(size int) []fr.Element {
+	f := make([]fr.Element, size)
+	for i := range f {
+		f[i].SetRandom()
+	}
+	return f
+}
+
+func TestVerifier(t *testing.T) {
+	assert := test.NewAssert(t)
+	srs := newSRS(t)
+
+	f := randomPolynomial(50)
+	com, err := kzg.Commit(f, srs.Pk)
+	assert.NoError(err)
+	var point fr.Element
+	point.SetRandom()
+	proof, err := kzg.Open(f, point, srs.Pk)
+	assert.NoError(err)
+	assert.NoError(kzg.Verify(&com, &proof, point, srs.Vk))
+
+	witness := verifierCircuit{
+		VerifKey: ValueOfVK(srs.Vk),
+		Proof:    ValueOfOpeningProof(proof),
+		Com:      ValueOfDigest(com),
+		S:        ValueOfScalar(point),
+	}
+	assert.NoError(test.IsSolved(&verifierCircuit{}, &witness, ecc.BN254.ScalarField()))
+
+	// wrong claimed value
+	proof.ClaimedValue.Double(&proof.ClaimedValue)
+	witness.Proof = ValueOfOpeningProof(proof)
+	assert.Error(test.IsSolved(&verifierCircuit{}, &witness, ecc.BN254.ScalarField()))
+}
+
+type batchVerifierCircuit struct {
+	VerifKey VK
+	Proof    BatchOpeningProof
+	Digests  []Digest
+	S        Scalar
+}
+
+func (circuit *batchVerifierCircuit) Define(api frontend.API) error {
+	verifier, err := NewVerifier(api)
+	if err != nil {
+		return err
+	}
+	return verifier.AssertBatchProofSinglePoint(circuit.VerifKey, circuit.Digests, circuit.Proof, &circuit.S)
+}
+
+func TestBatchVerifierSinglePoint(t *testing.T) {
+	assert := test.NewAssert(t)
+	srs := newSRS(t)
+
+	const nbPolynomials = 3
+	polynomials := make([][]fr.Element, nbPolynomials)
+	digests := make([]kzg.Digest, nbPolynomials)
+	for i := range polynomials {
+		polynomials[i] = randomPolynomial(20 + i)
+		var err error
+		digests[i], err = kzg.Commit(polynomials[i], srs.Pk)
+		assert.NoError(err)
+	}
+	var point fr.Element
+	point.SetRandom()
+	proof, err := BatchOpenSinglePoint(polynomials, digests, point, srs.Pk)
+	assert.NoError(err)
+
+	// the folded proof verifies out of the circuit
+	gamma, err := DeriveGamma(point, digests, proof.ClaimedValues)
+	assert.NoError(err)
+	folded, err := kzg.Commit(foldPolynomials(polynomials, gamma), srs.Pk)
+	assert.NoError(err)
+	var foldedValue, gammaI, tmp fr.Element
+	gammaI.SetOne()
+	for i := range proof.ClaimedValues {
+		tmp.Mul(&proof.ClaimedValues[i], &gammaI)
+		foldedValue.Add(&foldedValue, &tmp)
+		gammaI.Mul(&gammaI, &gamma)
+	}
+	assert.NoError(kzg.Verify(&folded, &kzg.OpeningProof{H: proof.H, ClaimedValue: foldedValue}, point, srs.Vk))
+
+	circuit := batchVerifierCircuit{Digests: make([]Digest, nbPolynomials), Proof: BatchOpeningProof{ClaimedValues: make([]Scalar, nbPolynomials)}}
+	witness := batchVerifierCircuit{
+		VerifKey: ValueOfVK(srs.Vk),
+		Proof:    ValueOfBatchOpeningProof(proof),
+		Digests:  make([]Digest, nbPolynomials),
+		S:        ValueOfScalar(point),
+	}
+	for i := range digests {
+		witness.Digests[i] = ValueOfDigest(digests[i])
+	}
+	assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+}
+
+func foldPolynomials(polynomials [][]fr.Element, gamma fr.Element) []fr.Element {
+	var res []fr.Element
+	var gammaI, tmp fr.Element
+	gammaI.SetOne()
+	for _, p := range polynomials {
+		for len(res) < len(p) {
+			res = append(res, fr.Element{})
+		}
+		for j := range p {
+			tmp.Mul(&p[j], &gammaI)
+			res[j].Add(&res[j], &tmp)
+		}
+		gammaI.Mul(&gammaI, &gamma)
+	}
+	return res
+}
@@ -0,0 +1,362 @@
+// Package rlp decodes RLP encodings in circuit, such as the Ethereum block headers, receipts
+// and transactions, validating their length prefixes.
+//
+// The encoding is a slice of variables holding one byte each, followed by zeros, and its
+// length a variable: the items are decoded at variable positions, with lookups in a table of
+// the bytes (see [github.com/consensys/gnark/std/lookup/logderivlookup]). The headers are
+// checked to be canonical, as the decoders of the Ethereum clients do: the lengths are
+// encoded on the fewest bytes, in the short form below 56 bytes, and a single byte below 0x80
+// is its own encoding. The items are checked to end within the encoding, and the lists to be
+// exactly covered by their items, so that a prover can't shift the fields of a structure.
+//
+// For instance, the number of a block is decoded from the RLP encoding of its header by
+//
+//	d := rlp.NewDecoder(api, header, headerLen)
+//	fields := d.List(d.Decode(), 17)
+//	number := d.Uint(fields[8], 8)
+//
+// The typed transactions and receipts of EIP-2718 are their type byte followed by the
+// encoding of their payload, which is decoded with d.Item(1).
+//
+// The payloads of at most 2²⁴-1 bytes are supported. Decoding an item costs 15 lookups and
+// a range check. The encodings authenticated by their hashes, such as the trie nodes of the
+// evm package, can be decoded for 9 lookups with the options WithoutCanonicalCheck and
+// WithLengthBytes(2), for the payloads of at most 2¹⁶-1 bytes.
+package rlp
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/kvstore"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// Item is a decoded item: a byte string or a list, whose payload is the Length bytes starting
+// at Offset, and whose encoding ends at End. The payload of a single byte below 0x80 is the
+// byte itself.
+type Item struct {
+	Offset, Length, End frontend.Variable
+
+	// IsList is 1 for a list, 0 for a byte string.
+	IsList frontend.Variable
+}
+
+// maxLengthBytes is the largest number of bytes of the lengths of the long headers.
+const maxLengthBytes = 3
+
+// maxLengthBits is the number of bits of the lengths and the positions.
+const maxLengthBits = 25
+
+// Option configures a Decoder.
+type Option func(*config)
+
+type config struct {
+	nonCanonical bool
+	lengthBytes  int
+}
+
+// WithoutCanonicalCheck doesn't check that the headers are canonical, nor that the integers
+// have no leading zeros, which saves 4 lookups per item. It is meant for the encodings
+// authenticated by their hashes, whose encoder is trusted.
+func WithoutCanonicalCheck() Option {
+	return func(cfg *config) {
+		cfg.nonCanonical = true
+	}
+}
+
+// WithLengthBytes sets the largest number of bytes, from 1 to 3, of the lengths of the long
+// headers, 3 by default: the payloads of at most 2^(8·n)-1 bytes are supported, and each
+// length byte less costs 2 lookups less per item.
+func WithLengthBytes(n int) Option {
+	return func(cfg *config) {
+		cfg.lengthBytes = n
+	}
+}
+
+// Decoder decodes the items of an RLP encoding.
+type Decoder struct {
+	api    frontend.API
+	length frontend.Variable
+	cmp    *cmp.BoundedComparator
+	cfg    config
+
+	bytes    *logderivlookup.Table
+	prefixes *prefixTables
+}
+
+// NewDecoder returns a decoder of the encoding of length bytes in data, followed by zeros, and
+// range checks its bytes.
+func NewDecoder(api frontend.API, data []frontend.Variable, length frontend.Variable, opts ...Option) *Decoder {
+	cfg := config{lengthBytes: maxLengthBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.lengthBytes < 1 || cfg.lengthBytes > maxLengthBytes {
+		panic("rlp: lengths of 1 to 3 bytes supported")
+	}
+	d := &Decoder{
+		api:      api,
+		length:   length,
+		cmp:      cmp.NewBoundedComparator(api, new(big.Int).Lsh(big.NewInt(1), maxLengthBits)),
+		cfg:      cfg,
+		bytes:    logderivlookup.New(api),
+		prefixes: prefixTablesOf(api, cfg),
+	}
+	rc := rangecheck.New(api)
+	for i := range data {
+		rc.Check(data[i], 8)
+		d.bytes.Insert(data[i])
+	}
+	// the header of an item at the end of data is read past it
+	for i := 0; i < cfg.lengthBytes; i++ {
+		d.bytes.Insert(0)
+	}
+	rc.Check(length, maxLengthBits)
+	d.cmp.AssertIsLessEq(length, len(data))
+	return d
+}
+
+// Decode decodes the item of the whole encoding.
+func (d *Decoder) Decode() Item {
+	item := d.Item(0)
+	d.api.AssertIsEqual(item.End, d.length)
+	return item
+}
+
+// Item decodes the item whose encoding starts at offset, and asserts that its header is
+// canonical and that it ends within the encoding.
+func (d *Decoder) Item(offset frontend.Variable) Item {
+	return d.ItemIf(offset, 1)
+}
+
+// ItemIf decodes the item at offset as Item if enabled is 1. If enabled is 0, nothing is
+// asserted and the item is meaningless; offset must still be at most the length of data, as
+// its header is read.
+func (d *Decoder) ItemIf(offset, enabled frontend.Variable) Item {
+	api, t := d.api, d.prefixes
+	positions := make([]frontend.Variable, 1+d.cfg.lengthBytes)
+	for i := range positions {
+		positions[i] = api.Add(offset, i)
+	}
+	b := d.bytes.Lookup(positions...)
+	p := b[0]
+	// the properties of the header, see prefixTables, looked up when needed
+	prop := func(i int) frontend.Variable {
+		return t.props[i].Lookup(p)[0]
+	}
+	valid, isList, header, length := prop(0), prop(1), prop(2), prop(3)
+	for i := 1; i <= d.cfg.lengthBytes; i++ {
+		length = api.Add(length, api.Mul(prop(3+i), b[i]))
+	}
+	api.AssertIsEqual(api.Mul(enabled, api.Sub(valid, 1)), 0)
+
+	if !d.cfg.nonCanonical {
+		// the length is encoded on the fewest bytes: the first length byte is at least 56 if
+		// alone, else non-zero; and a single byte string is at least 0x80
+		class := t.class.Lookup(b[1])[0]
+		api.AssertIsEqual(api.Mul(enabled, prop(7), api.Sub(class, 2), api.Sub(class, 3)), 0)
+		api.AssertIsEqual(api.Mul(enabled, prop(8), api.Sub(class, 1), api.Sub(class, 2), api.Sub(class, 3)), 0)
+		api.AssertIsEqual(api.Mul(enabled, prop(9), api.Sub(class, 3)), 0)
+	}
+
+	start := api.Add(offset, header)
+	end := api.Add(start, length)
+	d.cmp.AssertIsLessEq(api.Mul(enabled, end), d.length)
+	return Item{Offset: start, Length: length, End: end, IsList: isList}
+}
+
+// List decodes the items in the payload of list, and asserts that list is a list of exactly
+// nbItems items.
+func (d *Decoder) List(list Item, nbItems int) []Item {
+	d.api.AssertIsEqual(list.IsList, 1)
+	items := make([]Item, nbItems)
+	offset := list.Offset
+	for i := range items {
+		items[i] = d.Item(offset)
+		offset = items[i].End
+	}
+	d.api.AssertIsEqual(offset, list.End)
+	return items
+}
+
+// ListUpTo decodes the items in the payload of list, such as the logs of a receipt, and
+// returns maxItems items and their number, asserting that list is a list of at most maxItems
+// items. The items past the number are empty items at the end of the list.
+func (d *Decoder) ListUpTo(list Item, maxItems int) ([]Item, frontend.Variable) {
+	api := d.api
+	api.AssertIsEqual(list.IsList, 1)
+	items := make([]Item, maxItems)
+	offset := list.Offset
+	var count frontend.Variable = 0
+	for i := range items {
+		active := api.Sub(1, api.IsZero(api.Sub(list.End, offset)))
+		item := d.ItemIf(offset, active)
+		items[i] = Item{
+			Offset: api.Select(active, item.Offset, offset),
+			Length: api.Mul(active, item.Length),
+			End:    api.Select(active, item.End, offset),
+			IsList: api.Mul(active, item.IsList),
+		}
+		offset = items[i].End
+		count = api.Add(count, active)
+	}
+	api.AssertIsEqual(offset, list.End)
+	return items, count
+}
+
+// Bytes returns the payload of item on maxLen bytes, followed by zeros. It asserts that item
+// is a byte string of at most maxLen bytes.
+func (d *Decoder) Bytes(item Item, maxLen int) []frontend.Variable {
+	res, _ := d.read(item, maxLen)
+	return res
+}
+
+// Uint returns the big-endian integer in the payload of item, and asserts that item is a byte
+// string of at most maxLen bytes without leading zeros, as the integers are encoded, unless
+// with WithoutCanonicalCheck. The integer must fit in the field: 8·maxLen must be less than
+// its bit length.
+func (d *Decoder) Uint(item Item, maxLen int) frontend.Variable {
+	api := d.api
+	if 8*maxLen >= api.Compiler().FieldBitLen() {
+		panic("rlp: integer larger than the field")
+	}
+	b, inside := d.read(item, maxLen)
+	if maxLen > 0 && !d.cfg.nonCanonical {
+		api.AssertIsEqual(api.Mul(inside[0], api.IsZero(b[0])), 0)
+	}
+	var res frontend.Variable = 0
+	for i := range b {
+		// the bytes past the payload are zero and leave the result unchanged
+		res = api.Add(api.Mul(res, api.Add(1, api.Mul(inside[i], 255))), b[i])
+	}
+	return res
+}
+
+// read returns the payload of item on maxLen bytes followed by zeros, and the indicators of
+// the payload positions.
+func (d *Decoder) read(item Item, maxLen int) (res, inside []frontend.Variable) {
+	api := d.api
+	api.AssertIsEqual(item.IsList, 0)
+	d.cmp.AssertIsLessEq(item.Length, maxLen)
+	res = make([]frontend.Variable, maxLen)
+	inside = make([]frontend.Variable, maxLen)
+	for i := range res {
+		inside[i] = d.cmp.IsLess(i, item.Length)
+		// past the payload, read the position 0 to stay in the table
+		b := d.bytes.Lookup(api.Mul(inside[i], api.Add(item.Offset, i)))[0]
+		res[i] = api.Mul(inside[i], b)
+	}
+	return res, inside
+}
+
+// Byte returns the byte at position i of data, which must be less than len(data).
+func (d *Decoder) Byte(i frontend.Variable) frontend.Variable {
+	return d.bytes.Lookup(i)[0]
+}
+
+// prefixTables map the first byte of an encoding to the properties of its header, and the
+// next byte to its class.
+type prefixTables struct {
+	// valid, isList, header, length, mul1, mul2, mul3, isLong1, isLongMore, isShortOne: the
+	// length is length + mul1·b₁ + mul2·b₂ + mul3·b₃ with the next bytes; isLong1 is 1 if the
+	// length is on one byte b₁, which must then be at least 56, isLongMore if it is on more
+	// bytes, b₁ being then non-zero, and isShortOne if the item is a string of one byte b₁,
+	// which must then be at least 0x80. The prefixes of the lengths on more bytes than
+	// configured are invalid.
+	props []*logderivlookup.Table
+
+	// class is 0 for 0, 1 for [1, 56), 2 for [56, 0x80) and 3 for [0x80, 0x100).
+	class *logderivlookup.Table
+}
+
+// prefixTablesKey is the key of the prefix tables of a configuration in the key-value store of
+// the builder.
+type prefixTablesKey config
+
+// prefixTablesOf returns the prefix tables of the decoders of cfg, shared by the decoders of the
+// circuit with the same options when the builder is a key-value store.
+func prefixTablesOf(api frontend.API, cfg config) *prefixTables {
+	kv, ok := api.Compiler().(kvstore.Store)
+	if !ok {
+		return newPrefixTables(api, cfg)
+	}
+	if t, ok := kv.GetKeyValue(prefixTablesKey(cfg)).(*prefixTables); ok {
+		return t
+	}
+	t := newPrefixTables(api, cfg)
+	kv.SetKeyValue(prefixTablesKey(cfg), t)
+	return t
+}
+
+// newPrefixTables returns the tables of the decoders of cfg. The tables the decoders don't
+// look up are nil, as a table without lookups can't be committed.
+func newPrefixTables(api frontend.API, cfg config) *prefixTables {
+	t := &prefixTables{props: make([]*logderivlookup.Table, 10)}
+	for i := range t.props {
+		if i <= 3+cfg.lengthBytes || i >= 7 && !cfg.nonCanonical {
+			t.props[i] = logderivlookup.New(api)
+		}
+	}
+	if !cfg.nonCanonical {
+		t.class = logderivlookup.New(api)
+	}
+	for p := 0; p < 256; p++ {
+		// valid, isList, header, length, mul1, mul2, mul3, isLong1, isLongMore, isShortOne
+		var props [10]int
+		switch {
+		case p < 0x80:
+			// single byte
+			props[0], props[3] = 1, 1
+		case p <= 0xb7, p >= 0xc0 && p <= 0xf7:
+			// short string or list
+			base := 0x80
+			if p >= 0xc0 {
+				base, props[1] = 0xc0, 1
+			}
+			props[0], props[2], props[3] = 1, 1, p-base
+			if p == 0x81 {
+				props[9] = 1
+			}
+		case p <= 0xb7+cfg.lengthBytes, p >= 0xf8 && p <= 0xf7+cfg.lengthBytes:
+			// long string or list, of one to three bytes of length
+			nbLenBytes := p - 0xb7
+			if p >= 0xf8 {
+				nbLenBytes, props[1] = p-0xf7, 1
+			}
+			props[0], props[2] = 1, 1+nbLenBytes
+			mul := 1
+			for i := nbLenBytes; i >= 1; i-- {
+				props[3+i] = mul
+				mul *= 256
+			}
+			if nbLenBytes == 1 {
+				props[7] = 1
+			} else {
+				props[8] = 1
+			}
+		}
+		for i := range t.props {
+			if t.props[i] != nil {
+				t.props[i].Insert(props[i])
+			}
+		}
+		if t.class == nil {
+			continue
+		}
+
+		switch {
+		case p == 0:
+			t.class.Insert(0)
+		case p < 56:
+			t.class.Insert(1)
+		case p < 0x80:
+			t.class.Insert(2)
+		default:
+			t.class.Insert(3)
+		}
+	}
+	return t
+}
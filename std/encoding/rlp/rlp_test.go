@@ -0,0 +1,195 @@
+package rlp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// encodeBytes returns the RLP encoding of a byte string.
+func encodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(encodeHeader(0x80, len(b)), b...)
+}
+
+// encodeList returns the RLP encoding of a list of encoded items.
+func encodeList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(encodeHeader(0xc0, len(payload)), payload...)
+}
+
+func encodeHeader(base byte, length int) []byte {
+	if length < 56 {
+		return []byte{base + byte(length)}
+	}
+	l := new(big.Int).SetInt64(int64(length)).Bytes()
+	return append([]byte{base + 55 + byte(len(l))}, l...)
+}
+
+func encodeUint(v uint64) []byte {
+	return encodeBytes(new(big.Int).SetUint64(v).Bytes())
+}
+
+func toVariables(b []byte, n int) []frontend.Variable {
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = 0
+		if i < len(b) {
+			res[i] = b[i]
+		}
+	}
+	return res
+}
+
+const (
+	testMaxLen  = 512
+	testMaxLogs = 3
+)
+
+// receiptCircuit decodes a receipt [status, cumulative gas, bloom, logs] and checks its
+// fields.
+type receiptCircuit struct {
+	Data       []frontend.Variable
+	Length     frontend.Variable
+	Status     frontend.Variable
+	Gas        frontend.Variable
+	NbLogs     frontend.Variable
+	FirstTopic [32]frontend.Variable
+}
+
+func (c *receiptCircuit) Define(api frontend.API) error {
+	d := NewDecoder(api, c.Data, c.Length)
+	fields := d.List(d.Decode(), 4)
+	api.AssertIsEqual(d.Uint(fields[0], 1), c.Status)
+	api.AssertIsEqual(d.Uint(fields[1], 8), c.Gas)
+	bloom := d.Bytes(fields[2], 256)
+	api.AssertIsEqual(fields[2].Length, 256)
+	api.AssertIsEqual(bloom[255], 0xff)
+
+	logs, nbLogs := d.ListUpTo(fields[3], testMaxLogs)
+	api.AssertIsEqual(nbLogs, c.NbLogs)
+	log := d.List(logs[0], 3)
+	topics := d.List(log[1], 1)
+	topic := d.Bytes(topics[0], 32)
+	for i := range topic {
+		api.AssertIsEqual(topic[i], c.FirstTopic[i])
+	}
+	return nil
+}
+
+func encodeReceipt(gas uint64, nbLogs int) ([]byte, []byte) {
+	bloom := make([]byte, 256)
+	bloom[255] = 0xff
+	topic := make([]byte, 32)
+	for i := range topic {
+		topic[i] = byte(i + 1)
+	}
+	var logs [][]byte
+	for i := 0; i < nbLogs; i++ {
+		logs = append(logs, encodeList(encodeBytes(make([]byte, 20)), encodeList(encodeBytes(topic)), encodeBytes([]byte{byte(i)})))
+	}
+	return encodeList(encodeUint(1), encodeUint(gas), encodeBytes(bloom), encodeList(logs...)), topic
+}
+
+func receiptWitness(receipt, topic []byte, gas uint64, nbLogs int) *receiptCircuit {
+	w := &receiptCircuit{Data: toVariables(receipt, testMaxLen), Length: len(receipt), Status: 1, Gas: gas, NbLogs: nbLogs}
+	copy(w.FirstTopic[:], toVariables(topic, 32))
+	return w
+}
+
+func TestReceipt(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := &receiptCircuit{Data: make([]frontend.Variable, testMaxLen)}
+	for _, nbLogs := range []int{1, 3} {
+		for _, gas := range []uint64{0, 0x5208, 1 << 40} {
+			receipt, topic := encodeReceipt(gas, nbLogs)
+			assert.NoError(test.IsSolved(circuit, receiptWitness(receipt, topic, gas, nbLogs), ecc.BN254.ScalarField()), "%d logs, gas %d", nbLogs, gas)
+		}
+	}
+
+	// the encoding ends after the length
+	receipt, topic := encodeReceipt(0x5208, 1)
+	w := receiptWitness(receipt, topic, 0x5208, 1)
+	w.Length = len(receipt) - 1
+	assert.Error(test.IsSolved(circuit, w, ecc.BN254.ScalarField()))
+}
+
+type itemCircuit struct {
+	Data   []frontend.Variable
+	Length frontend.Variable
+	Value  frontend.Variable
+
+	opts []Option
+}
+
+func (c *itemCircuit) Define(api frontend.API) error {
+	d := NewDecoder(api, c.Data, c.Length, c.opts...)
+	api.AssertIsEqual(d.Uint(d.Decode(), 8), c.Value)
+	return nil
+}
+
+func TestCanonical(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := &itemCircuit{Data: make([]frontend.Variable, 80)}
+	long := make([]byte, 60)
+	for _, tc := range []struct {
+		name    string
+		data    []byte
+		value   uint64
+		isValid bool
+	}{
+		{"single byte", []byte{0x05}, 5, true},
+		{"short string", []byte{0x82, 0x01, 0x00}, 256, true},
+		{"zero", []byte{0x80}, 0, true},
+		{"single byte in a string", []byte{0x81, 0x05}, 5, false},
+		{"leading zero", []byte{0x82, 0x00, 0x01}, 1, false},
+		{"zero byte", []byte{0x00}, 0, false},
+		{"long form of a short length", []byte{0xb8, 0x02, 0x01, 0x00}, 256, false},
+		{"leading zero in the length", append([]byte{0xb9, 0x00, 60}, long...), 0, false},
+		{"list", []byte{0xc1, 0x05}, 5, false},
+		{"invalid prefix", []byte{0xbb, 0, 0, 0, 0}, 0, false},
+	} {
+		w := &itemCircuit{Data: toVariables(tc.data, 80), Length: len(tc.data), Value: tc.value}
+		err := test.IsSolved(circuit, w, ecc.BN254.ScalarField())
+		if tc.isValid {
+			assert.NoError(err, tc.name)
+		} else {
+			assert.Error(err, tc.name)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := &itemCircuit{Data: make([]frontend.Variable, 80), opts: []Option{WithoutCanonicalCheck(), WithLengthBytes(2)}}
+	for _, tc := range []struct {
+		name    string
+		data    []byte
+		value   uint64
+		isValid bool
+	}{
+		{"short string", []byte{0x82, 0x01, 0x00}, 256, true},
+		{"single byte in a string", []byte{0x81, 0x05}, 5, true},
+		{"leading zero", []byte{0x82, 0x00, 0x01}, 1, true},
+		{"long form of a short length", []byte{0xb8, 0x02, 0x01, 0x00}, 256, true},
+		{"length on 2 bytes", []byte{0xb9, 0x00, 0x02, 0x01, 0x00}, 256, true},
+		{"length on 3 bytes", []byte{0xba, 0x00, 0x00, 0x02, 0x01, 0x00}, 256, false},
+		{"list", []byte{0xc1, 0x05}, 5, false},
+	} {
+		w := &itemCircuit{Data: toVariables(tc.data, 80), Length: len(tc.data), Value: tc.value}
+		err := test.IsSolved(circuit, w, ecc.BN254.ScalarField())
+		if tc.isValid {
+			assert.NoError(err, tc.name)
+		} else {
+			assert.Error(err, tc.name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package evm
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// buffer gives access to the nibbles of a byte string at variable positions.
+type buffer struct {
+	api frontend.API
+
+	// nibbles holds the high then the low nibble of each byte, padded with the slack. Its table
+	// is only created at the first lookup, as a table without lookups can't be committed.
+	nibbles      []frontend.Variable
+	nibblesTable *logderivlookup.Table
+}
+
+// newBuffer range checks data and returns its buffer. Reads up to 2·slack positions past the
+// end of the nibbles of data return 0.
+func newBuffer(api frontend.API, data []frontend.Variable, slack int) *buffer {
+	b := &buffer{api: api}
+	rc := rangecheck.New(api)
+	var nibbles []frontend.Variable
+	if len(data) > 0 {
+		var err error
+		if nibbles, err = api.Compiler().NewHint(nibblesHint, 2*len(data), data...); err != nil {
+			panic(err)
+		}
+	}
+	for i := range data {
+		hi, lo := nibbles[2*i], nibbles[2*i+1]
+		rc.Check(hi, 4)
+		rc.Check(lo, 4)
+		api.AssertIsEqual(data[i], api.Add(api.Mul(hi, 16), lo))
+		b.nibbles = append(b.nibbles, hi, lo)
+	}
+	for i := 0; i < slack; i++ {
+		b.nibbles = append(b.nibbles, 0, 0)
+	}
+	return b
+}
+
+// nibbleAt returns the nibble at position i, the nibble 2j being the high nibble of the byte j.
+func (b *buffer) nibbleAt(i frontend.Variable) frontend.Variable {
+	if b.nibblesTable == nil {
+		b.nibblesTable = logderivlookup.New(b.api)
+		for _, e := range b.nibbles {
+			b.nibblesTable.Insert(e)
+		}
+	}
+	return b.nibblesTable.Lookup(i)[0]
+}
+
+// lessThan returns the n indicators t < length for t in [0, n), and the n+1 indicators
+// t = length for t in [0, n]. It asserts that the length is in [0, n]. When enabled is 0, the
+// length is taken as 0.
+func lessThan(api frontend.API, length frontend.Variable, n int, enabled frontend.Variable) (lt, eq []frontend.Variable) {
+	length = api.Mul(length, enabled)
+	lt = make([]frontend.Variable, n)
+	eq = make([]frontend.Variable, n+1)
+	// inside is 1 until the position of length, and 0 from it
+	inside := frontend.Variable(1)
+	for t := 0; t <= n; t++ {
+		eq[t] = api.IsZero(api.Sub(length, t))
+		inside = api.Sub(inside, eq[t])
+		if t < n {
+			lt[t] = inside
+		}
+	}
+	// length is in [0, n] iff inside went down to 0 exactly once
+	api.AssertIsEqual(inside, 0)
+	return lt, eq
+}
@@ -0,0 +1,11 @@
+// Package evm implements gadgets to verify Ethereum data in circuits: the Keccak-256 hash
+// function and the verification of Merkle-Patricia trie proofs, from which the storage proofs
+// are built. The RLP encodings are decoded with the
+// [github.com/consensys/gnark/std/encoding/rlp] package.
+//
+// Byte strings are slices of variables holding one byte each, together with a variable length
+// when it is not known at compile time: the bytes beyond the length are ignored. The
+// functions reading bytes at variable positions use lookup tables (see
+// [github.com/consensys/gnark/std/lookup/logderivlookup]), and range check the bytes they are
+// given.
+package evm
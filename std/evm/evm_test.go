@@ -0,0 +1,211 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+func hashKeccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// encodeBytes returns the RLP encoding of a byte string.
+func encodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(encodeHeader(0x80, len(b)), b...)
+}
+
+// encodeList returns the RLP encoding of a list of encoded items.
+func encodeList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(encodeHeader(0xc0, len(payload)), payload...)
+}
+
+func encodeHeader(base byte, length int) []byte {
+	switch {
+	case length < 56:
+		return []byte{base + byte(length)}
+	case length < 256:
+		return []byte{base + 56, byte(length)}
+	default:
+		return []byte{base + 57, byte(length >> 8), byte(length)}
+	}
+}
+
+func encodeUint(v uint64) []byte {
+	return encodeBytes(new(big.Int).SetUint64(v).Bytes())
+}
+
+func toVariables(b []byte, n int) []frontend.Variable {
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = 0
+		if i < len(b) {
+			res[i] = b[i]
+		}
+	}
+	return res
+}
+
+func toBytes32(b []byte) (res [32]frontend.Variable) {
+	copy(res[:], toVariables(b, 32))
+	return
+}
+
+type keccakCircuit struct {
+	Data     []frontend.Variable
+	Length   frontend.Variable
+	Expected [32]frontend.Variable
+}
+
+func (c *keccakCircuit) Define(api frontend.API) error {
+	h := Keccak256(api, c.Data, c.Length)
+	for i := range h {
+		api.AssertIsEqual(h[i], c.Expected[i])
+	}
+	return nil
+}
+
+func TestKeccak256(t *testing.T) {
+	assert := test.NewAssert(t)
+	const maxLen = 200
+	data := make([]byte, maxLen)
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	circuit := keccakCircuit{Data: make([]frontend.Variable, maxLen)}
+	for _, length := range []int{0, 1, 135, 136, 200} {
+		witness := keccakCircuit{Data: toVariables(data[:length], maxLen), Length: length, Expected: toBytes32(hashKeccak256(data[:length]))}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+
+	witness := keccakCircuit{Data: toVariables(data, maxLen), Length: 10, Expected: toBytes32(hashKeccak256(data[:11]))}
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
+
+// compactPath returns the hex-prefix encoding of a path of nibbles.
+func compactPath(nibbles []byte, isLeaf bool) []byte {
+	flag := byte(len(nibbles) % 2)
+	if isLeaf {
+		flag += 2
+	}
+	res := []byte{flag << 4}
+	if len(nibbles)%2 == 1 {
+		res[0] |= nibbles[0]
+		nibbles = nibbles[1:]
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		res = append(res, nibbles[i]<<4|nibbles[i+1])
+	}
+	return res
+}
+
+func toNibbles(b []byte) []byte {
+	res := make([]byte, 0, 2*len(b))
+	for _, x := range b {
+		res = append(res, x>>4, x&0xf)
+	}
+	return res
+}
+
+func encodeAccount(nonce, balance uint64, storageRoot, codeHash []byte) []byte {
+	return encodeList(encodeUint(nonce), encodeUint(balance), encodeBytes(storageRoot), encodeBytes(codeHash))
+}
+
+func branchNode(children map[byte][]byte) []byte {
+	items := make([][]byte, 17)
+	for i := range items {
+		items[i] = encodeBytes(nil)
+		if c, ok := children[byte(i)]; ok {
+			items[i] = encodeBytes(hashKeccak256(c))
+		}
+	}
+	return encodeList(items...)
+}
+
+const (
+	mptDepth    = 4
+	mptNodeLen  = 135
+	mptValueLen = 80
+)
+
+type mptCircuit struct {
+	Root, Key      [32]frontend.Variable
+	Proof          MPTProof
+	Nonce, Balance frontend.Variable
+	CodeHash       [32]frontend.Variable
+}
+
+func (c *mptCircuit) Define(api frontend.API) error {
+	value, length := VerifyMPTProof(api, c.Root, c.Key, c.Proof, mptValueLen)
+	account := DecodeAccount(api, value, length)
+	api.AssertIsEqual(account.Nonce, c.Nonce)
+	api.AssertIsEqual(account.Balance, c.Balance)
+	for i := range account.CodeHash {
+		api.AssertIsEqual(account.CodeHash[i], c.CodeHash[i])
+	}
+	return nil
+}
+
+func TestMPT(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// keys 0x1234…, 0x1235… and 0xa0…: the root is a branch with an extension of path 23 to a
+	// branch at nibble 1, and a leaf at nibble a
+	var keys [3][]byte
+	for i := range keys {
+		keys[i] = hashKeccak256([]byte{byte(i)})
+	}
+	keys[0][0], keys[0][1] = 0x12, 0x34
+	keys[1][0], keys[1][1] = 0x12, 0x35
+	keys[2][0] = 0xa0
+	codeHash := hashKeccak256([]byte("code"))
+	var accounts [3][]byte
+	for i := range accounts {
+		accounts[i] = encodeAccount(uint64(i+1), uint64(1000*(i+1)), hashKeccak256([]byte("storage")), codeHash)
+	}
+
+	leaf := func(i, consumed int) []byte {
+		return encodeList(encodeBytes(compactPath(toNibbles(keys[i])[consumed:], true)), encodeBytes(accounts[i]))
+	}
+	leaf0, leaf1, leaf2 := leaf(0, 4), leaf(1, 4), leaf(2, 1)
+	inner := branchNode(map[byte][]byte{4: leaf0, 5: leaf1})
+	extension := encodeList(encodeBytes(compactPath([]byte{2, 3}, false)), encodeBytes(hashKeccak256(inner)))
+	root := branchNode(map[byte][]byte{1: extension, 0xa: leaf2})
+
+	circuit := mptCircuit{Proof: NewMPTProof(mptDepth, mptNodeLen)}
+	for i, nodes := range [][][]byte{{root, extension, inner, leaf0}, {root, extension, inner, leaf1}, {root, leaf2}} {
+		proof, err := AssignMPTProof(nodes, mptDepth, mptNodeLen)
+		assert.NoError(err)
+		witness := mptCircuit{
+			Root:     toBytes32(hashKeccak256(root)),
+			Key:      toBytes32(keys[i]),
+			Proof:    proof,
+			Nonce:    i + 1,
+			Balance:  1000 * (i + 1),
+			CodeHash: toBytes32(codeHash),
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+		if i == 0 {
+			// the proof of another key
+			witness.Key = toBytes32(keys[1])
+			assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all the hints used in this package.
+func GetHints() []solver.Hint {
+	return []solver.Hint{nibblesHint}
+}
+
+// nibblesHint returns the high and low nibbles of each input byte.
+func nibblesHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(outputs) != 2*len(inputs) {
+		return errors.New("expected two outputs per input")
+	}
+	for i, in := range inputs {
+		if !in.IsUint64() || in.Uint64() > 0xff {
+			return errors.New("input is not a byte")
+		}
+		b := in.Uint64()
+		outputs[2*i].SetUint64(b >> 4)
+		outputs[2*i+1].SetUint64(b & 0xf)
+	}
+	return nil
+}
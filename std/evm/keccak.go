@@ -0,0 +1,53 @@
+package evm
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/keccak256"
+)
+
+// Keccak256 returns the bytes of the Keccak-256 hash, as used by Ethereum, of the first length
+// bytes of data. The bytes of data must be range checked by the caller, and length be at most
+// len(data).
+//
+// The hash costs len(data)/136 + 1 Keccak-f permutations of the keccak256 package, whatever
+// the length.
+func Keccak256(api frontend.API, data []frontend.Variable, length frontend.Variable) [32]frontend.Variable {
+	nbBlocks := len(data)/keccak256.Rate + 1
+	inside, isEnd := lessThan(api, length, len(data), 1)
+
+	// pad10*1: the byte at the position length is 0x01 and the last byte of the block
+	// containing it 0x80, together 0x81 if they are the same.
+	padded := make([]frontend.Variable, nbBlocks*keccak256.Rate)
+	isLastBlock := make([]frontend.Variable, nbBlocks)
+	for i := range isLastBlock {
+		isLastBlock[i] = 0
+	}
+	for j := range padded {
+		padded[j] = 0
+		if j < len(data) {
+			padded[j] = api.Mul(data[j], inside[j])
+		}
+		if j <= len(data) {
+			padded[j] = api.Add(padded[j], isEnd[j])
+			isLastBlock[j/keccak256.Rate] = api.Add(isLastBlock[j/keccak256.Rate], isEnd[j])
+		}
+	}
+	for i := range isLastBlock {
+		last := (i+1)*keccak256.Rate - 1
+		padded[last] = api.Add(padded[last], api.Mul(isLastBlock[i], 0x80))
+	}
+
+	st := keccak256.NewState()
+	var res [32]frontend.Variable
+	for i := range res {
+		res[i] = 0
+	}
+	for i := 0; i < nbBlocks; i++ {
+		keccak256.Absorb(api, &st, padded[i*keccak256.Rate:(i+1)*keccak256.Rate])
+		digest := keccak256.Squeeze(api, &st)
+		for j := range res {
+			res[j] = api.Add(res[j], api.Mul(isLastBlock[i], digest[j]))
+		}
+	}
+	return res
+}
@@ -0,0 +1,208 @@
+package evm
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/encoding/rlp"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/selector"
+)
+
+// rlpOptions are the options of the decoders of the nodes and of their values: their encodings
+// are authenticated by their hashes, and the nodes are at most 532 bytes long.
+var rlpOptions = []rlp.Option{rlp.WithoutCanonicalCheck(), rlp.WithLengthBytes(2)}
+
+// MPTProof is a proof of the value of a key in an Ethereum Merkle-Patricia trie: the RLP
+// encodings of the nodes on the path from the root to the leaf of the key, as returned by
+// eth_getProof.
+type MPTProof struct {
+	// Nodes are the encodings of the nodes, followed by zeros up to the maximal node length.
+	// The nodes past the leaf are zero.
+	Nodes [][]frontend.Variable
+
+	// Lengths are the lengths of the encodings.
+	Lengths []frontend.Variable
+}
+
+// NewMPTProof returns a proof of at most maxDepth nodes of at most maxNodeLen bytes, to be
+// used in circuit definitions. A branch node is at most 532 bytes long.
+func NewMPTProof(maxDepth, maxNodeLen int) MPTProof {
+	p := MPTProof{Nodes: make([][]frontend.Variable, maxDepth), Lengths: make([]frontend.Variable, maxDepth)}
+	for i := range p.Nodes {
+		p.Nodes[i] = make([]frontend.Variable, maxNodeLen)
+	}
+	return p
+}
+
+// AssignMPTProof returns the assignment of a proof defined with NewMPTProof(maxDepth,
+// maxNodeLen) from the encodings of its nodes.
+func AssignMPTProof(nodes [][]byte, maxDepth, maxNodeLen int) (MPTProof, error) {
+	if len(nodes) > maxDepth {
+		return MPTProof{}, fmt.Errorf("proof of %d nodes, at most %d supported", len(nodes), maxDepth)
+	}
+	p := NewMPTProof(maxDepth, maxNodeLen)
+	for i := range p.Nodes {
+		var node []byte
+		if i < len(nodes) {
+			node = nodes[i]
+		}
+		if len(node) > maxNodeLen {
+			return MPTProof{}, fmt.Errorf("node %d of %d bytes, at most %d supported", i, len(node), maxNodeLen)
+		}
+		for j := range p.Nodes[i] {
+			p.Nodes[i][j] = 0
+			if j < len(node) {
+				p.Nodes[i][j] = node[j]
+			}
+		}
+		p.Lengths[i] = len(node)
+	}
+	return p, nil
+}
+
+// VerifyMPTProof asserts that proof is a proof of the value of key in the trie of the given
+// root, and returns the value on maxValueLen bytes followed by zeros, and its length.
+//
+// The key is the path in the trie: the Keccak-256 hash of the address in the state trie, and
+// of the slot in a storage trie. The nodes are referenced by their hashes, which is always the
+// case for the tries of 32-byte keys except for the nodes shorter than 32 bytes, which are
+// embedded in their parent: proofs with such nodes are not supported.
+//
+// The nodes are decoded with the decoder of the rlp package. Each node costs a Keccak-256 hash
+// of the maximal node length, and about 12000 R1CS constraints and 400 lookups.
+func VerifyMPTProof(api frontend.API, root, key [32]frontend.Variable, proof MPTProof, maxValueLen int) (value []frontend.Variable, length frontend.Variable) {
+	if len(proof.Nodes) == 0 || len(proof.Nodes) != len(proof.Lengths) {
+		panic("evm: invalid proof shape")
+	}
+	// the key nibbles are read up to 128 positions
+	keyBuf := newBuffer(api, key[:], 32)
+
+	expected := root                 // reference of the current node
+	pos := frontend.Variable(0)      // number of key nibbles consumed
+	active := frontend.Variable(1)   // 1 until the leaf
+	nbLeaves := frontend.Variable(0) // number of leaves met, must be 1
+	value = make([]frontend.Variable, maxValueLen)
+	for t := range value {
+		value[t] = 0
+	}
+	length = 0
+
+	for i, node := range proof.Nodes {
+		nodeLen := proof.Lengths[i]
+		d := rlp.NewDecoder(api, node, nodeLen, rlpOptions...)
+		nibbles := newBuffer(api, node, 0)
+
+		h := Keccak256(api, node, nodeLen)
+		for t := range h {
+			api.AssertIsEqual(api.Mul(active, api.Sub(h[t], expected[t])), 0)
+		}
+		list := d.ItemIf(0, active)
+		api.AssertIsEqual(api.Mul(active, api.Sub(list.IsList, 1)), 0)
+		api.AssertIsEqual(api.Mul(active, api.Sub(list.End, nodeLen)), 0)
+
+		// a branch has 17 items, an extension or a leaf 2: the items of the latter past the
+		// second are decoded at the position 0, and ignored
+		items := make([]rlp.Item, 17)
+		items[0] = d.ItemIf(list.Offset, active)
+		items[1] = d.ItemIf(items[0].End, active)
+		isShort := api.IsZero(api.Sub(items[1].End, list.End))
+		isBranch := api.Mul(active, api.Sub(1, isShort))
+		for k := 2; k < len(items); k++ {
+			items[k] = d.ItemIf(api.Mul(isBranch, items[k-1].End), isBranch)
+		}
+		api.AssertIsEqual(api.Mul(isBranch, api.Sub(items[16].End, list.End)), 0)
+
+		// the path of a short node is compact encoded: the high nibble of its first byte is
+		// 2·isLeaf + odd, followed by the low nibble if odd, then by the bytes of the path
+		path := items[0]
+		api.AssertIsEqual(api.Mul(active, isShort, path.IsList), 0)
+		flag := api.Mul(active, isShort, nibbles.nibbleAt(api.Mul(path.Offset, 2)))
+		flagBits := bits.ToBinary(api, flag, bits.WithNbDigits(2))
+		odd, isLeaf := flagBits[0], flagBits[1]
+		pathLen := api.Mul(isShort, api.Add(api.Mul(api.Sub(path.Length, 1), 2), odd))
+		inPath, _ := lessThan(api, pathLen, 64, active)
+		first := api.Sub(api.Add(api.Mul(path.Offset, 2), 2), odd)
+		for t := range inPath {
+			pathNibble := nibbles.nibbleAt(api.Mul(inPath[t], api.Add(first, t)))
+			keyNibble := keyBuf.nibbleAt(api.Mul(inPath[t], api.Add(pos, t)))
+			api.AssertIsEqual(api.Mul(inPath[t], api.Sub(pathNibble, keyNibble)), 0)
+		}
+
+		// the child of a branch is the item of the next key nibble, the one of an extension
+		// its second item; it must be a hash
+		var offsets, lengths, lists [16]frontend.Variable
+		for k := range offsets {
+			offsets[k], lengths[k], lists[k] = items[k].Offset, items[k].Length, items[k].IsList
+		}
+		nibble := keyBuf.nibbleAt(api.Mul(isBranch, pos))
+		child := rlp.Item{
+			Offset: api.Select(isBranch, selector.Mux(api, nibble, offsets[:]...), items[1].Offset),
+			Length: api.Select(isBranch, selector.Mux(api, nibble, lengths[:]...), items[1].Length),
+			IsList: api.Select(isBranch, selector.Mux(api, nibble, lists[:]...), items[1].IsList),
+		}
+		hasChild := api.Mul(active, api.Sub(1, isLeaf))
+		api.AssertIsEqual(api.Mul(hasChild, api.Sub(child.Length, 32)), 0)
+		api.AssertIsEqual(api.Mul(hasChild, child.IsList), 0)
+		for t := range expected {
+			expected[t] = d.Byte(api.Mul(hasChild, api.Add(child.Offset, t)))
+		}
+
+		// the leaf completes the key, and holds the value in its second item
+		leafHere := api.Mul(active, isLeaf)
+		api.AssertIsEqual(api.Mul(leafHere, api.Sub(api.Add(pos, pathLen), 64)), 0)
+		v := d.Bytes(rlp.Item{
+			Offset: items[1].Offset,
+			Length: api.Mul(leafHere, items[1].Length),
+			IsList: api.Mul(leafHere, items[1].IsList),
+		}, maxValueLen)
+		for t := range value {
+			value[t] = api.Add(value[t], v[t])
+		}
+		length = api.Add(length, api.Mul(leafHere, items[1].Length))
+		nbLeaves = api.Add(nbLeaves, leafHere)
+
+		pos = api.Add(pos, api.Mul(active, api.Add(isBranch, api.Mul(api.Sub(1, isLeaf), pathLen))))
+		active = api.Sub(active, leafHere)
+	}
+	api.AssertIsEqual(nbLeaves, 1)
+	return value, length
+}
+
+// Account is an Ethereum account, the value of the state trie at its address.
+type Account struct {
+	Nonce, Balance        frontend.Variable
+	StorageRoot, CodeHash [32]frontend.Variable
+}
+
+// DecodeAccount decodes the RLP encoding of an account in the first length bytes of value,
+// as returned by VerifyMPTProof. The balance must fit in the field, on 31 bytes for the
+// fields of 254 bits.
+func DecodeAccount(api frontend.API, value []frontend.Variable, length frontend.Variable) Account {
+	d := rlp.NewDecoder(api, value, length, rlpOptions...)
+	items := d.List(d.Decode(), 4)
+
+	var a Account
+	a.Nonce = d.Uint(items[0], 8)
+	a.Balance = d.Uint(items[1], min(32, (api.Compiler().FieldBitLen()-1)/8))
+	for i, h := range []*[32]frontend.Variable{&a.StorageRoot, &a.CodeHash} {
+		item := items[2+i]
+		api.AssertIsEqual(item.Length, 32)
+		copy(h[:], d.Bytes(item, 32))
+	}
+	return a
+}
+
+// DecodeStorageValue decodes the RLP encoding of a storage slot in the first length bytes of
+// value, as returned by VerifyMPTProof. The value must fit in the field.
+func DecodeStorageValue(api frontend.API, value []frontend.Variable, length frontend.Variable) frontend.Variable {
+	d := rlp.NewDecoder(api, value, length, rlpOptions...)
+	return d.Uint(d.Decode(), min(32, (api.Compiler().FieldBitLen()-1)/8))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
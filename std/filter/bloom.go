@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+// Bloom is a Bloom filter of m bits with k hash functions. The positions of an element x are
+// the low log₂(m) bits of H(j, x) for j in [0, k).
+//
+// With n elements, the false positive rate is about (1 - e^(-k·n/m))^k, minimal for
+// k = m/n·ln(2). A lookup costs k hashes, k canonical decompositions of a hash (about 2
+// constraints per bit of the field each) and k table lookups.
+type Bloom struct {
+	api      frontend.API
+	h        hash.Hash
+	table    *logderivlookup.Table
+	logSize  int
+	nbHashes int
+}
+
+// NewBloom returns the Bloom filter of filterBits, whose length must be a power of two, with
+// nbHashes hash functions derived from h. It asserts that the bits are boolean.
+func NewBloom(api frontend.API, h hash.Hash, filterBits []frontend.Variable, nbHashes int) *Bloom {
+	logSize := log2(len(filterBits), "number of bits")
+	if nbHashes < 1 {
+		panic("filter: at least one hash function is needed")
+	}
+	table := logderivlookup.New(api)
+	for _, b := range filterBits {
+		api.AssertIsBoolean(b)
+		table.Insert(b)
+	}
+	return &Bloom{api: api, h: h, table: table, logSize: logSize, nbHashes: nbHashes}
+}
+
+// IsMember returns 1 if the k bits of x are set, 0 otherwise. See the package documentation
+// for the soundness of membership checks.
+func (b *Bloom) IsMember(x frontend.Variable) frontend.Variable {
+	api := b.api
+	positions := make([]frontend.Variable, b.nbHashes)
+	for j := range positions {
+		hBits := hashToBits(api, b.h, j, x)
+		positions[j] = bits.FromBinary(api, hBits[:b.logSize], bits.WithUnconstrainedInputs())
+	}
+	res := frontend.Variable(1)
+	for _, bit := range b.table.Lookup(positions...) {
+		res = api.Mul(res, bit)
+	}
+	return res
+}
+
+// AssertMember asserts that x is in the filter, which is not sound by itself: see the package
+// documentation.
+func (b *Bloom) AssertMember(x frontend.Variable) {
+	b.api.AssertIsEqual(b.IsMember(x), 1)
+}
+
+// AssertNotMember asserts that x is not in the filter, hence not in the set it was built
+// from.
+func (b *Bloom) AssertNotMember(x frontend.Variable) {
+	b.api.AssertIsEqual(b.IsMember(x), 0)
+}
@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+// Cuckoo is a cuckoo filter of m buckets of b entries, storing f-bit fingerprints. The
+// fingerprint of an element x is 1 + the low f bits of H(x), so that the empty entries are
+// zero. x is stored in one of its two buckets: i₁, the next log₂(m) bits of H(x), and
+// i₂ = i₁ ⊕ the low log₂(m) bits of H(fingerprint).
+//
+// The false positive rate is at most 2·b/2ᶠ, whatever the number of elements. A lookup costs
+// 2 hashes, 2 canonical decompositions of a hash (about 2 constraints per bit of the field
+// each) and 2·b table lookups.
+type Cuckoo struct {
+	api               frontend.API
+	h                 hash.Hash
+	table             *logderivlookup.Table
+	logNbBuckets      int
+	bucketSize        int
+	fingerprintNbBits int
+}
+
+// NewCuckoo returns the cuckoo filter of entries, the buckets one after the other. The number
+// of buckets len(entries)/bucketSize must be a power of two, and fingerprintNbBits+log₂ of it
+// less than the bit length of the field.
+func NewCuckoo(api frontend.API, h hash.Hash, entries []frontend.Variable, bucketSize, fingerprintNbBits int) *Cuckoo {
+	if bucketSize < 1 || len(entries)%bucketSize != 0 {
+		panic("filter: the number of entries must be a multiple of the bucket size")
+	}
+	logNbBuckets := log2(len(entries)/bucketSize, "number of buckets")
+	if fingerprintNbBits < 1 || fingerprintNbBits+logNbBuckets >= api.Compiler().FieldBitLen() {
+		panic("filter: invalid fingerprint size")
+	}
+	table := logderivlookup.New(api)
+	for _, e := range entries {
+		table.Insert(e)
+	}
+	return &Cuckoo{
+		api:               api,
+		h:                 h,
+		table:             table,
+		logNbBuckets:      logNbBuckets,
+		bucketSize:        bucketSize,
+		fingerprintNbBits: fingerprintNbBits,
+	}
+}
+
+// IsMember returns 1 if the fingerprint of x is in one of its buckets, 0 otherwise. See the
+// package documentation for the soundness of membership checks.
+func (c *Cuckoo) IsMember(x frontend.Variable) frontend.Variable {
+	api := c.api
+
+	hBits := hashToBits(api, c.h, x)
+	fingerprint := api.Add(1, bits.FromBinary(api, hBits[:c.fingerprintNbBits], bits.WithUnconstrainedInputs()))
+	i1Bits := hBits[c.fingerprintNbBits : c.fingerprintNbBits+c.logNbBuckets]
+
+	fBits := hashToBits(api, c.h, fingerprint)
+	i2Bits := make([]frontend.Variable, c.logNbBuckets)
+	for i := range i2Bits {
+		i2Bits[i] = api.Xor(i1Bits[i], fBits[i])
+	}
+
+	i1 := bits.FromBinary(api, i1Bits, bits.WithUnconstrainedInputs())
+	i2 := bits.FromBinary(api, i2Bits, bits.WithUnconstrainedInputs())
+	positions := make([]frontend.Variable, 0, 2*c.bucketSize)
+	for _, i := range []frontend.Variable{i1, i2} {
+		first := api.Mul(i, c.bucketSize)
+		for s := 0; s < c.bucketSize; s++ {
+			positions = append(positions, api.Add(first, s))
+		}
+	}
+
+	// the fingerprint is in a bucket iff the product of the differences is zero
+	prod := frontend.Variable(1)
+	for _, e := range c.table.Lookup(positions...) {
+		prod = api.Mul(prod, api.Sub(e, fingerprint))
+	}
+	return api.IsZero(prod)
+}
+
+// AssertMember asserts that x is in the filter, which is not sound by itself: see the package
+// documentation.
+func (c *Cuckoo) AssertMember(x frontend.Variable) {
+	c.api.AssertIsEqual(c.IsMember(x), 1)
+}
+
+// AssertNotMember asserts that x is not in the filter, hence not in the set it was built
+// from.
+func (c *Cuckoo) AssertNotMember(x frontend.Variable) {
+	c.api.AssertIsEqual(c.IsMember(x), 0)
+}
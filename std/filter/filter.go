@@ -0,0 +1,72 @@
+// Package filter provides ZKP-circuit functions to look up elements in probabilistic set
+// filters (Bloom and cuckoo filters), and native implementations to build the filters.
+//
+// A filter is much smaller than the set it represents and a lookup costs a few hashes and
+// table lookups, against one hash per level for a Merkle proof. In exchange, filters have
+// false positives: an element which is not in the set may be reported as a member.
+//
+// # Soundness
+//
+// The filters never have false negatives: a member of the set is always found. Hence proofs
+// of NON-membership (AssertNotMember) are sound, which is what denylist checks need: a proof
+// that x is not in a filter built from the set S implies that x ∉ S.
+//
+// Proofs of membership (AssertMember) are not sound by themselves. With a false positive rate
+// ε, a prover free to choose the element finds a non-member which passes the check after
+// about 1/ε hash evaluations. AssertMember should only be used when the element is otherwise
+// bound (for instance, signed by a third party or committed to before the filter is built), or
+// with filters sized for ε ≤ 2⁻λ, which defeats their purpose for most sets.
+//
+// In both cases the filter itself must be bound to the statement: either the circuit embeds
+// it as constants, or its entries are part of the witness and their hash is checked against a
+// public input. Otherwise the prover simply chooses the filter.
+//
+// The positions in the filters are derived from field hashes of the element, such as MiMC.
+// The hashes are decomposed in canonical bits (a field element has a single decomposition),
+// so that the prover can't choose among several positions.
+package filter
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// hashToBits returns the little-endian canonical decomposition of the hash of the inputs.
+func hashToBits(api frontend.API, h hash.Hash, inputs ...frontend.Variable) []frontend.Variable {
+	h.Reset()
+	h.Write(inputs...)
+	bits := api.ToBinary(h.Sum(), api.Compiler().FieldBitLen())
+	assertCanonical(api, bits)
+	return bits
+}
+
+// assertCanonical asserts that Σ 2ⁱ·bits[i] is less than the modulus, where bits are
+// boolean constrained and as many as the bits of the modulus.
+func assertCanonical(api frontend.API, bits []frontend.Variable) {
+	bound := new(big.Int).Sub(api.Compiler().Field(), big.NewInt(1))
+
+	// prefixEqual is 1 as long as the bits are the ones of the bound, from the top
+	prefixEqual := frontend.Variable(1)
+	for i := len(bits) - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			prefixEqual = api.Mul(prefixEqual, bits[i])
+		} else {
+			api.AssertIsEqual(api.Mul(prefixEqual, bits[i]), 0)
+		}
+	}
+}
+
+// log2 returns log₂(n), and panics if n is not a power of two.
+func log2(n int, what string) int {
+	if n <= 0 || n&(n-1) != 0 {
+		panic("filter: the " + what + " must be a power of two")
+	}
+	l := 0
+	for n > 1 {
+		n >>= 1
+		l++
+	}
+	return l
+}
@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const (
+	bloomLogSize     = 8
+	bloomNbHashes    = 3
+	cuckooLogBuckets = 3
+	cuckooBucketSize = 4
+	cuckooNbBits     = 16
+)
+
+type bloomCircuit struct {
+	Bits              []frontend.Variable
+	Member, NonMember frontend.Variable
+}
+
+func (c *bloomCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	f := NewBloom(api, &h, c.Bits, bloomNbHashes)
+	f.AssertMember(c.Member)
+	f.AssertNotMember(c.NonMember)
+	return nil
+}
+
+type cuckooCircuit struct {
+	Entries           []frontend.Variable
+	Member, NonMember frontend.Variable
+}
+
+func (c *cuckooCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	f := NewCuckoo(api, &h, c.Entries, cuckooBucketSize, cuckooNbBits)
+	f.AssertMember(c.Member)
+	f.AssertNotMember(c.NonMember)
+	return nil
+}
+
+// randomElements returns n random field elements, encoded on fr.Bytes bytes.
+func randomElements(t *testing.T, n int) [][]byte {
+	res := make([][]byte, n)
+	for i := range res {
+		var e fr.Element
+		if _, err := e.SetRandom(); err != nil {
+			t.Fatal(err)
+		}
+		b := e.Bytes()
+		res[i] = b[:]
+	}
+	return res
+}
+
+// nonMember returns a random element which is not in the filter.
+func nonMember(t *testing.T, contains func([]byte) (bool, error)) []byte {
+	for {
+		x := randomElements(t, 1)[0]
+		ok, err := contains(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return x
+		}
+	}
+}
+
+func TestBloom(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	native := NewNativeBloom(hash.MIMC_BN254.New(), bloomLogSize, bloomNbHashes)
+	set := randomElements(t, 20)
+	for _, x := range set {
+		assert.NoError(native.Add(x))
+	}
+	for _, x := range set {
+		ok, err := native.Contains(x)
+		assert.NoError(err)
+		assert.True(ok)
+	}
+
+	circuit := bloomCircuit{Bits: make([]frontend.Variable, 1<<bloomLogSize)}
+	witness := bloomCircuit{Bits: make([]frontend.Variable, 1<<bloomLogSize)}
+	for i, b := range native.Bits() {
+		witness.Bits[i] = b
+	}
+	witness.Member, witness.NonMember = set[3], nonMember(t, native.Contains)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	witness.Member, witness.NonMember = witness.NonMember, witness.Member
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+func TestCuckoo(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	native := NewNativeCuckoo(hash.MIMC_BN254.New(), cuckooLogBuckets, cuckooBucketSize, cuckooNbBits)
+	set := randomElements(t, 20)
+	for _, x := range set {
+		assert.NoError(native.Insert(x))
+	}
+	for _, x := range set {
+		ok, err := native.Contains(x)
+		assert.NoError(err)
+		assert.True(ok)
+	}
+
+	nbEntries := cuckooBucketSize << cuckooLogBuckets
+	circuit := cuckooCircuit{Entries: make([]frontend.Variable, nbEntries)}
+	witness := cuckooCircuit{Entries: make([]frontend.Variable, nbEntries)}
+	for i, e := range native.Entries() {
+		witness.Entries[i] = e
+	}
+	for _, x := range set[:4] {
+		witness.Member, witness.NonMember = x, nonMember(t, native.Contains)
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+
+	witness.Member, witness.NonMember = witness.NonMember, witness.Member
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,221 @@
+package filter
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+	"math/rand"
+)
+
+// NativeBloom is the native counterpart of Bloom, to build the filters assigned to circuits.
+// Elements are encoded on h.Size() bytes, which for a field hash function such as MiMC are the
+// big-endian encodings of field elements.
+type NativeBloom struct {
+	h        hash.Hash
+	bits     []uint8
+	nbHashes int
+}
+
+// NewNativeBloom returns an empty Bloom filter of 2^logSize bits with nbHashes hash functions.
+func NewNativeBloom(h hash.Hash, logSize, nbHashes int) *NativeBloom {
+	if logSize < 0 || logSize > 32 || nbHashes < 1 {
+		panic("filter: invalid Bloom filter parameters")
+	}
+	return &NativeBloom{h: h, bits: make([]uint8, 1<<logSize), nbHashes: nbHashes}
+}
+
+// Add adds x to the filter.
+func (b *NativeBloom) Add(x []byte) error {
+	positions, err := b.positions(x)
+	if err != nil {
+		return err
+	}
+	for _, p := range positions {
+		b.bits[p] = 1
+	}
+	return nil
+}
+
+// Contains returns true if x may be in the set, false if it is not.
+func (b *NativeBloom) Contains(x []byte) (bool, error) {
+	positions, err := b.positions(x)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range positions {
+		if b.bits[p] == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Bits returns the bits of the filter, as assigned to NewBloom.
+func (b *NativeBloom) Bits() []uint8 {
+	return b.bits
+}
+
+func (b *NativeBloom) positions(x []byte) ([]uint64, error) {
+	res := make([]uint64, b.nbHashes)
+	for j := range res {
+		d, err := sum(b.h, encodeUint64(b.h, uint64(j)), x)
+		if err != nil {
+			return nil, err
+		}
+		res[j] = lowBits(d, 0, len(b.bits))
+	}
+	return res, nil
+}
+
+// NativeCuckoo is the native counterpart of Cuckoo, to build the filters assigned to circuits.
+// Elements are encoded as for NativeBloom.
+type NativeCuckoo struct {
+	h                 hash.Hash
+	entries           []uint64
+	bucketSize        int
+	fingerprintNbBits int
+}
+
+// maxKicks is the number of entries moved by Insert before giving up.
+const maxKicks = 500
+
+// NewNativeCuckoo returns an empty cuckoo filter of 2^logNbBuckets buckets of bucketSize
+// entries, storing fingerprints of fingerprintNbBits bits.
+func NewNativeCuckoo(h hash.Hash, logNbBuckets, bucketSize, fingerprintNbBits int) *NativeCuckoo {
+	if logNbBuckets < 0 || logNbBuckets > 32 || bucketSize < 1 || fingerprintNbBits < 1 || fingerprintNbBits > 62 {
+		panic("filter: invalid cuckoo filter parameters")
+	}
+	return &NativeCuckoo{
+		h:                 h,
+		entries:           make([]uint64, bucketSize<<logNbBuckets),
+		bucketSize:        bucketSize,
+		fingerprintNbBits: fingerprintNbBits,
+	}
+}
+
+// Insert inserts x in the filter. It returns an error if the filter is too full, in which
+// case the filter is left unchanged.
+func (c *NativeCuckoo) Insert(x []byte) error {
+	f, i1, i2, err := c.locate(x)
+	if err != nil {
+		return err
+	}
+	if c.insertInBucket(i1, f) || c.insertInBucket(i2, f) {
+		return nil
+	}
+
+	// evict random entries to their other bucket, and undo the moves if it doesn't end
+	type move struct {
+		position int
+		previous uint64
+	}
+	var moves []move
+	i := i1
+	if rand.Intn(2) == 1 {
+		i = i2
+	}
+	for k := 0; k < maxKicks; k++ {
+		position := int(i)*c.bucketSize + rand.Intn(c.bucketSize)
+		moves = append(moves, move{position, c.entries[position]})
+		f, c.entries[position] = c.entries[position], f
+		if i, err = c.otherBucket(i, f); err != nil {
+			break
+		}
+		if c.insertInBucket(i, f) {
+			return nil
+		}
+	}
+	for k := len(moves) - 1; k >= 0; k-- {
+		c.entries[moves[k].position] = moves[k].previous
+	}
+	if err != nil {
+		return err
+	}
+	return errors.New("filter: cuckoo filter is full")
+}
+
+// Contains returns true if x may be in the set, false if it is not.
+func (c *NativeCuckoo) Contains(x []byte) (bool, error) {
+	f, i1, i2, err := c.locate(x)
+	if err != nil {
+		return false, err
+	}
+	for _, i := range []uint64{i1, i2} {
+		for _, e := range c.bucket(i) {
+			if e == f {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Entries returns the entries of the filter, as assigned to NewCuckoo.
+func (c *NativeCuckoo) Entries() []uint64 {
+	return c.entries
+}
+
+func (c *NativeCuckoo) nbBuckets() int {
+	return len(c.entries) / c.bucketSize
+}
+
+func (c *NativeCuckoo) bucket(i uint64) []uint64 {
+	return c.entries[int(i)*c.bucketSize : int(i+1)*c.bucketSize]
+}
+
+func (c *NativeCuckoo) insertInBucket(i, f uint64) bool {
+	bucket := c.bucket(i)
+	for s := range bucket {
+		if bucket[s] == 0 {
+			bucket[s] = f
+			return true
+		}
+	}
+	return false
+}
+
+// locate returns the fingerprint and the buckets of x.
+func (c *NativeCuckoo) locate(x []byte) (f, i1, i2 uint64, err error) {
+	d, err := sum(c.h, x)
+	if err != nil {
+		return
+	}
+	f = 1 + lowBits(d, 0, 1<<c.fingerprintNbBits)
+	i1 = lowBits(d, c.fingerprintNbBits, c.nbBuckets())
+	i2, err = c.otherBucket(i1, f)
+	return
+}
+
+// otherBucket returns the bucket of the fingerprint f which is not i.
+func (c *NativeCuckoo) otherBucket(i, f uint64) (uint64, error) {
+	d, err := sum(c.h, encodeUint64(c.h, f))
+	if err != nil {
+		return 0, err
+	}
+	return i ^ lowBits(d, 0, c.nbBuckets()), nil
+}
+
+// sum returns the hash of the data as an integer.
+func sum(h hash.Hash, data ...[]byte) (*big.Int, error) {
+	h.Reset()
+	for _, d := range data {
+		if _, err := h.Write(d); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).SetBytes(h.Sum(nil)), nil
+}
+
+// lowBits returns the bits [from, from+log₂(n)) of d, n being a power of two.
+func lowBits(d *big.Int, from int, n int) uint64 {
+	v := new(big.Int).Rsh(d, uint(from))
+	return v.And(v, big.NewInt(int64(n-1))).Uint64()
+}
+
+// encodeUint64 encodes v on h.Size() bytes.
+func encodeUint64(h hash.Hash, v uint64) []byte {
+	b := make([]byte, h.Size())
+	binary.BigEndian.PutUint64(b[len(b)-8:], v)
+	return b
+}
@@ -0,0 +1,117 @@
+// Package batch computes large numbers of hashes natively and in parallel, with the encodings
+// of the std gadgets, to build the witnesses of circuits: hashed leaves, Merkle trees, ...
+//
+// The hash functions are given by their constructors, for instance hash.MIMC_BN254.New of
+// gnark-crypto for the MiMC gadget of std/hash/mimc on BN254. The hashes are computed on the
+// CPU cores; the icicle version used by this module has no hashing kernels.
+package batch
+
+import (
+	"errors"
+	"hash"
+	"sync"
+
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// Sum returns the hashes of the inputs, computed in parallel.
+func Sum(newHash func() hash.Hash, inputs [][]byte) ([][]byte, error) {
+	res := make([][]byte, len(inputs))
+	err := parallelize(newHash, len(inputs), func(h hash.Hash, i int) error {
+		if _, err := h.Write(inputs[i]); err != nil {
+			return err
+		}
+		res[i] = h.Sum(nil)
+		return nil
+	})
+	return res, err
+}
+
+// Tree is a Merkle tree, as verified by the gadget of std/accumulator/merkle: the leaves are
+// hashed as H(leaf), and the nodes as H(left ∥ right).
+type Tree struct {
+	leaves [][]byte
+
+	// levels[0] are the hashes of the leaves, and the last level the root
+	levels [][][]byte
+}
+
+// NewTree returns the Merkle tree of the leaves, whose number must be a power of two, hashing
+// each level in parallel.
+func NewTree(newHash func() hash.Hash, leaves [][]byte) (*Tree, error) {
+	if len(leaves) == 0 || len(leaves)&(len(leaves)-1) != 0 {
+		return nil, errors.New("batch: the number of leaves must be a power of two")
+	}
+	level, err := Sum(newHash, leaves)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tree{leaves: leaves, levels: [][][]byte{level}}
+	for len(level) > 1 {
+		children := level
+		level = make([][]byte, len(children)/2)
+		err := parallelize(newHash, len(level), func(h hash.Hash, i int) error {
+			if _, err := h.Write(children[2*i]); err != nil {
+				return err
+			}
+			if _, err := h.Write(children[2*i+1]); err != nil {
+				return err
+			}
+			level[i] = h.Sum(nil)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		t.levels = append(t.levels, level)
+	}
+	return t, nil
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Depth returns the depth of the tree, log₂ of its number of leaves.
+func (t *Tree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Proof returns the proof of the leaf at index, as the Path of merkle.MerkleProof: the leaf,
+// followed by the siblings from the bottom of the tree.
+func (t *Tree) Proof(index int) [][]byte {
+	if index < 0 || index >= len(t.leaves) {
+		panic("batch: leaf index out of range")
+	}
+	path := [][]byte{t.leaves[index]}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		path = append(path, level[index^1])
+		index >>= 1
+	}
+	return path
+}
+
+// parallelize calls work for the n indices, on one hash instance per task, which is reset
+// before each call. It returns the first error.
+func parallelize(newHash func() hash.Hash, n int, work func(h hash.Hash, i int) error) error {
+	var (
+		lock     sync.Mutex
+		firstErr error
+	)
+	utils.Parallelize(n, func(start, end int) {
+		h := newHash()
+		for i := start; i < end; i++ {
+			h.Reset()
+			if err := work(h, i); err != nil {
+				lock.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	return firstErr
+}
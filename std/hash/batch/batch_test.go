@@ -0,0 +1,77 @@
+package batch
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/accumulator/merkle"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+func randomLeaves(t *testing.T, n int) [][]byte {
+	res := make([][]byte, n)
+	for i := range res {
+		var e fr.Element
+		if _, err := e.SetRandom(); err != nil {
+			t.Fatal(err)
+		}
+		b := e.Bytes()
+		res[i] = b[:]
+	}
+	return res
+}
+
+func TestSum(t *testing.T) {
+	inputs := randomLeaves(t, 1000)
+	hashes, err := Sum(hash.MIMC_BN254.New, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := hash.MIMC_BN254.New()
+	for i := range inputs {
+		h.Reset()
+		h.Write(inputs[i])
+		if !bytes.Equal(h.Sum(nil), hashes[i]) {
+			t.Fatalf("hash %d mismatch", i)
+		}
+	}
+}
+
+type merkleCircuit struct {
+	M    merkle.MerkleProof
+	Leaf frontend.Variable
+}
+
+func (c *merkleCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	c.M.VerifyProof(api, &h, c.Leaf)
+	return nil
+}
+
+func TestTree(t *testing.T) {
+	assert := test.NewAssert(t)
+	const depth = 6
+	tree, err := NewTree(hash.MIMC_BN254.New, randomLeaves(t, 1<<depth))
+	assert.NoError(err)
+	assert.Equal(depth, tree.Depth())
+
+	circuit := merkleCircuit{M: merkle.MerkleProof{Path: make([]frontend.Variable, depth+1)}}
+	for _, index := range []int{0, 13, 1<<depth - 1} {
+		witness := merkleCircuit{M: merkle.MerkleProof{RootHash: tree.Root(), Path: make([]frontend.Variable, depth+1)}, Leaf: index}
+		for i, p := range tree.Proof(index) {
+			witness.M.Path[i] = p
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+
+	_, err = NewTree(hash.MIMC_BN254.New, randomLeaves(t, 3))
+	assert.Error(err)
+}
@@ -0,0 +1,73 @@
+package keccak256
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+type sumCircuit struct {
+	Data   []frontend.Variable
+	Digest [32]frontend.Variable
+}
+
+func (c *sumCircuit) Define(api frontend.API) error {
+	digest := Sum(api, c.Data)
+	for i := range digest {
+		api.AssertIsEqual(digest[i], c.Digest[i])
+	}
+	return nil
+}
+
+func bytesToVariables(b []byte) []frontend.Variable {
+	res := make([]frontend.Variable, len(b))
+	for i := range b {
+		res[i] = b[i]
+	}
+	return res
+}
+
+func TestSum(t *testing.T) {
+	assert := test.NewAssert(t)
+	// around the lengths of the padding in one and two blocks
+	for _, n := range []int{0, 3, 135, 136, 200} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		h := sha3.NewLegacyKeccak256()
+		h.Write(data)
+		digest := h.Sum(nil)
+
+		circuit := sumCircuit{Data: make([]frontend.Variable, n)}
+		witness := sumCircuit{Data: bytesToVariables(data)}
+		copy(witness.Digest[:], bytesToVariables(digest))
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "length %d", n)
+
+		witness.Digest[0] = (digest[0] + 1) & 0xff
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "length %d", n)
+	}
+}
+
+type permuteCircuit struct {
+	State State
+}
+
+func (c *permuteCircuit) Define(api frontend.API) error {
+	Permute(api, &c.State)
+	return nil
+}
+
+func TestPermuteConstraints(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &permuteCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nb := ccs.GetNbConstraints(); nb != 145920 {
+		t.Fatalf("the permutation has %d constraints, expected 145920", nb)
+	}
+}
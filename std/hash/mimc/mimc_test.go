@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package mimc
+package mimc_test
 
 import (
 	"math/big"
@@ -23,6 +23,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/test"
 )
 
@@ -32,12 +33,12 @@ type mimcCircuit struct {
 }
 
 func (circuit *mimcCircuit) Define(api frontend.API) error {
-	mimc, err := NewMiMC(api)
+	h, err := mimc.NewMiMC(api)
 	if err != nil {
 		return err
 	}
-	mimc.Write(circuit.Data[:]...)
-	result := mimc.Sum()
+	h.Write(circuit.Data[:]...)
+	result := h.Sum()
 	api.AssertIsEqual(result, circuit.ExpectedResult)
 	return nil
 }
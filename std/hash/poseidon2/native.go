@@ -0,0 +1,163 @@
+package poseidon2
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// NativePermutation is the native counterpart of Permutation.
+type NativePermutation struct {
+	params *Parameters
+}
+
+// NewNativePermutation returns the permutation of the width over the scalar field of the
+// curve, see NewParameters.
+func NewNativePermutation(curve ecc.ID, width int) (*NativePermutation, error) {
+	params, err := NewParameters(curve, width)
+	if err != nil {
+		return nil, err
+	}
+	return &NativePermutation{params: params}, nil
+}
+
+// Permute applies the permutation to the state, of the width of the permutation, whose
+// elements are reduced modulo the field.
+func (p *NativePermutation) Permute(state []*big.Int) {
+	if len(state) != p.params.Width {
+		panic(fmt.Sprintf("the state has %d elements, expected %d", len(state), p.params.Width))
+	}
+	q := p.params.Field
+	for i := range state {
+		state[i].Mod(state[i], q)
+	}
+	p.externalLayer(state)
+	d := big.NewInt(int64(p.params.Degree))
+	for i, keys := range p.params.RoundKeys {
+		if p.params.isFullRound(i) {
+			for j := range state {
+				state[j].Add(state[j], keys[j]).Exp(state[j], d, q)
+			}
+			p.externalLayer(state)
+		} else {
+			state[0].Add(state[0], keys[0]).Exp(state[0], d, q)
+			p.internalLayer(state)
+		}
+	}
+}
+
+// Compress returns the compression P(left, right)₀ + left, with a permutation of width 2.
+func (p *NativePermutation) Compress(left, right *big.Int) *big.Int {
+	if p.params.Width != 2 {
+		panic("the compression needs a permutation of width 2")
+	}
+	state := []*big.Int{new(big.Int).Set(left), new(big.Int).Set(right)}
+	p.Permute(state)
+	state[0].Add(state[0], left)
+	return state[0].Mod(state[0], p.params.Field)
+}
+
+func (p *NativePermutation) externalLayer(state []*big.Int) {
+	sum := new(big.Int)
+	for i := range state {
+		sum.Add(sum, state[i])
+	}
+	for i := range state {
+		state[i].Add(state[i], sum).Mod(state[i], p.params.Field)
+	}
+}
+
+func (p *NativePermutation) internalLayer(state []*big.Int) {
+	sum := new(big.Int)
+	for i := range state {
+		sum.Add(sum, state[i])
+	}
+	var d big.Int
+	for i := range state {
+		state[i].Mul(state[i], d.SetInt64(p.params.InternalDiagonal[i])).Add(state[i], sum).Mod(state[i], p.params.Field)
+	}
+}
+
+// NativeHasher is the native counterpart of Hasher, implementing the hash.Hash of the
+// standard library like the MiMC of gnark-crypto: the elements are written as their
+// big-endian encodings on BlockSize bytes, and the digest is encoded the same.
+type NativeHasher struct {
+	perm *NativePermutation
+	data []*big.Int
+}
+
+// NewNativeHasher returns a sponge over the scalar field of the curve.
+func NewNativeHasher(curve ecc.ID) (*NativeHasher, error) {
+	perm, err := NewNativePermutation(curve, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeHasher{perm: perm}, nil
+}
+
+// Write adds the elements encoded in b to the data to hash. It returns an error if the length
+// of b isn't a multiple of BlockSize or if an element isn't reduced.
+func (h *NativeHasher) Write(b []byte) (int, error) {
+	size := h.BlockSize()
+	if len(b)%size != 0 {
+		return 0, fmt.Errorf("the length must be a multiple of %d bytes", size)
+	}
+	elements := make([]*big.Int, 0, len(b)/size)
+	for i := 0; i < len(b); i += size {
+		e := new(big.Int).SetBytes(b[i : i+size])
+		if e.Cmp(h.perm.params.Field) >= 0 {
+			return 0, errors.New("the element isn't reduced")
+		}
+		elements = append(elements, e)
+	}
+	h.WriteElements(elements...)
+	return len(b), nil
+}
+
+// WriteElements adds the elements, reduced modulo the field, to the data to hash.
+func (h *NativeHasher) WriteElements(elements ...*big.Int) {
+	for _, e := range elements {
+		h.data = append(h.data, new(big.Int).Mod(e, h.perm.params.Field))
+	}
+}
+
+// SumElement returns the digest of the data.
+func (h *NativeHasher) SumElement() *big.Int {
+	state := []*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+	padded := append(append(make([]*big.Int, 0, len(h.data)+2), h.data...), big.NewInt(1))
+	if len(padded)%2 == 1 {
+		padded = append(padded, new(big.Int))
+	}
+	for i := 0; i < len(padded); i += 2 {
+		state[1].Add(state[1], padded[i])
+		state[2].Add(state[2], padded[i+1])
+		h.perm.Permute(state)
+	}
+	return state[1]
+}
+
+// Sum appends the encoding of the digest of the data to b.
+func (h *NativeHasher) Sum(b []byte) []byte {
+	return append(b, h.SumElement().FillBytes(make([]byte, h.Size()))...)
+}
+
+// Reset empties the data to hash.
+func (h *NativeHasher) Reset() {
+	h.data = nil
+}
+
+// Size returns the number of bytes of the digest, the byte length of the field.
+func (h *NativeHasher) Size() int {
+	return (h.perm.params.Field.BitLen() + 7) / 8
+}
+
+// BlockSize returns the number of bytes of the elements written, the byte length of the
+// field.
+func (h *NativeHasher) BlockSize() int {
+	return h.Size()
+}
+
+var _ hash.Hash = (*NativeHasher)(nil)
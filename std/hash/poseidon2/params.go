@@ -0,0 +1,93 @@
+package poseidon2
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"golang.org/x/crypto/sha3"
+)
+
+// Parameters are the parameters of a Poseidon2 permutation. They are shared by the
+// permutations in circuit and the native ones, so that both compute the same function.
+type Parameters struct {
+	// Field is the modulus of the field of the permutation.
+	Field *big.Int
+
+	// Width is the number of elements of the state.
+	Width int
+
+	// Degree is the exponent of the S-box x ↦ x^Degree.
+	Degree int
+
+	// NbFullRounds is the number of full rounds, half before and half after the partial
+	// rounds, and NbPartialRounds the number of partial rounds.
+	NbFullRounds, NbPartialRounds int
+
+	// RoundKeys are the constants added to the state at the start of each round: Width
+	// constants for a full round, one to the first element for a partial round.
+	RoundKeys [][]*big.Int
+
+	// InternalDiagonal is the diagonal of the matrix of the partial rounds minus the identity:
+	// the element i is mapped to the sum of the state plus InternalDiagonal[i] times itself.
+	InternalDiagonal []int64
+}
+
+// NewParameters returns the parameters of the permutation of the width over the scalar field
+// of the curve. The widths 2, for Compress, and 3, for the sponge of Hasher, are supported.
+//
+// Over BN254, the S-box is x⁵, with 8 full rounds and 56 partial rounds. Over BLS12-377, on
+// which x⁵ isn't a permutation, the S-box is x¹⁷, with 8 full rounds and 31 partial rounds,
+// a margin above the bounds of the Poseidon paper for 128 bits of security.
+//
+// The round keys are derived from the seed "Poseidon2-<curve>[t=<width>,rF=<full
+// rounds>,rP=<partial rounds>,d=<degree>]" like the constants of MiMC: the seed is hashed
+// with Keccak-256, each key is the last digest reduced modulo the field, and the next digest
+// the hash of the last one.
+func NewParameters(curve ecc.ID, width int) (*Parameters, error) {
+	var p Parameters
+	switch curve {
+	case ecc.BN254:
+		p.Degree, p.NbFullRounds, p.NbPartialRounds = 5, 8, 56
+	case ecc.BLS12_377:
+		p.Degree, p.NbFullRounds, p.NbPartialRounds = 17, 8, 31
+	default:
+		return nil, fmt.Errorf("no parameters for the curve %s", curve)
+	}
+	switch width {
+	case 2:
+		p.InternalDiagonal = []int64{1, 2}
+	case 3:
+		p.InternalDiagonal = []int64{1, 1, 2}
+	default:
+		return nil, fmt.Errorf("unsupported width %d", width)
+	}
+	p.Field = curve.ScalarField()
+	p.Width = width
+
+	seed := fmt.Sprintf("Poseidon2-%s[t=%d,rF=%d,rP=%d,d=%d]", curve, width, p.NbFullRounds, p.NbPartialRounds, p.Degree)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(seed))
+	rnd := h.Sum(nil)
+	p.RoundKeys = make([][]*big.Int, p.NbFullRounds+p.NbPartialRounds)
+	for i := range p.RoundKeys {
+		n := 1
+		if p.isFullRound(i) {
+			n = width
+		}
+		p.RoundKeys[i] = make([]*big.Int, n)
+		for j := range p.RoundKeys[i] {
+			p.RoundKeys[i][j] = new(big.Int).SetBytes(rnd)
+			p.RoundKeys[i][j].Mod(p.RoundKeys[i][j], p.Field)
+			h.Reset()
+			h.Write(rnd)
+			rnd = h.Sum(nil)
+		}
+	}
+	return &p, nil
+}
+
+// isFullRound returns whether the round i is full.
+func (p *Parameters) isFullRound(i int) bool {
+	return i < p.NbFullRounds/2 || i >= p.NbFullRounds/2+p.NbPartialRounds
+}
@@ -0,0 +1,150 @@
+// Package poseidon2 implements the Poseidon2 permutation over the scalar fields of BN254 and
+// BLS12-377, in circuit and natively, with a sponge hash and a compression function for the
+// Merkle trees.
+//
+// The permutation is the one of the [Poseidon2 paper]: a linear layer, half of the full rounds,
+// the partial rounds and the other half of the full rounds. A full round adds its round keys to
+// the state, applies the S-box to all its elements and multiplies it by circ(2, 1, …, 1); a
+// partial round adds its key and applies the S-box to the first element only, and multiplies
+// the state by the matrix of ones plus a small diagonal. The linear layers are free in R1CS, so
+// that a permutation of width 3 costs 240 constraints over BN254.
+//
+// The native counterparts, NativePermutation and NativeHasher, take their parameters from the
+// same NewParameters as the circuits, so that the witness generators and the circuits can't
+// use different constants.
+//
+// Hasher is a sponge of the permutation of width 3, of rate 2: the elements are padded with a
+// one and zeros to a multiple of the rate, added to the last two elements of the state by
+// pairs before each permutation, and the digest is the second element of the state. Compress
+// maps two elements to one with the permutation of width 2, truncated with a feed-forward:
+// P(a, b)₀ + a.
+//
+// [Poseidon2 paper]: https://eprint.iacr.org/2023/323
+package poseidon2
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// Permutation is a Poseidon2 permutation in circuit.
+type Permutation struct {
+	api    frontend.API
+	params *Parameters
+}
+
+// NewPermutation returns the permutation of the width over the field of api, see
+// NewParameters.
+func NewPermutation(api frontend.API, width int) (*Permutation, error) {
+	params, err := NewParameters(utils.FieldToCurve(api.Compiler().Field()), width)
+	if err != nil {
+		return nil, err
+	}
+	return &Permutation{api: api, params: params}, nil
+}
+
+// Permute applies the permutation to the state, of the width of the permutation.
+func (p *Permutation) Permute(state []frontend.Variable) {
+	if len(state) != p.params.Width {
+		panic(fmt.Sprintf("the state has %d elements, expected %d", len(state), p.params.Width))
+	}
+	p.externalLayer(state)
+	for i, keys := range p.params.RoundKeys {
+		if p.params.isFullRound(i) {
+			for j := range state {
+				state[j] = p.sBox(p.api.Add(state[j], keys[j]))
+			}
+			p.externalLayer(state)
+		} else {
+			state[0] = p.sBox(p.api.Add(state[0], keys[0]))
+			p.internalLayer(state)
+		}
+	}
+}
+
+// Compress returns the compression P(left, right)₀ + left, with a permutation of width 2.
+func (p *Permutation) Compress(left, right frontend.Variable) frontend.Variable {
+	if p.params.Width != 2 {
+		panic("the compression needs a permutation of width 2")
+	}
+	state := []frontend.Variable{left, right}
+	p.Permute(state)
+	return p.api.Add(state[0], left)
+}
+
+// sBox returns x^Degree, by squarings and multiplications.
+func (p *Permutation) sBox(x frontend.Variable) frontend.Variable {
+	d := big.NewInt(int64(p.params.Degree))
+	res := x
+	for i := d.BitLen() - 2; i >= 0; i-- {
+		res = p.api.Mul(res, res)
+		if d.Bit(i) == 1 {
+			res = p.api.Mul(res, x)
+		}
+	}
+	return res
+}
+
+// externalLayer multiplies the state by circ(2, 1, …, 1): each element is added to the sum.
+func (p *Permutation) externalLayer(state []frontend.Variable) {
+	sum := p.api.Add(state[0], state[1], state[2:]...)
+	for i := range state {
+		state[i] = p.api.Add(sum, state[i])
+	}
+}
+
+// internalLayer multiplies the state by the matrix of ones plus the internal diagonal.
+func (p *Permutation) internalLayer(state []frontend.Variable) {
+	sum := p.api.Add(state[0], state[1], state[2:]...)
+	for i := range state {
+		state[i] = p.api.Add(sum, p.api.Mul(state[i], p.params.InternalDiagonal[i]))
+	}
+}
+
+// Hasher is the sponge of the Poseidon2 permutation of width 3, implementing hash.Hash. The
+// digest is the one of all the elements written since the last Reset.
+type Hasher struct {
+	api  frontend.API
+	perm *Permutation
+	data []frontend.Variable
+}
+
+// NewHasher returns a sponge over the field of api.
+func NewHasher(api frontend.API) (*Hasher, error) {
+	perm, err := NewPermutation(api, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &Hasher{api: api, perm: perm}, nil
+}
+
+// Write adds the elements to the data to hash.
+func (h *Hasher) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset empties the data to hash.
+func (h *Hasher) Reset() {
+	h.data = nil
+}
+
+// Sum returns the digest of the data.
+func (h *Hasher) Sum() frontend.Variable {
+	state := []frontend.Variable{0, 0, 0}
+	padded := append(append(make([]frontend.Variable, 0, len(h.data)+2), h.data...), 1)
+	if len(padded)%2 == 1 {
+		padded = append(padded, 0)
+	}
+	for i := 0; i < len(padded); i += 2 {
+		state[1] = h.api.Add(state[1], padded[i])
+		state[2] = h.api.Add(state[2], padded[i+1])
+		h.perm.Permute(state)
+	}
+	return state[1]
+}
+
+var _ hash.Hash = (*Hasher)(nil)
@@ -0,0 +1,107 @@
+package poseidon2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidon2Circuit struct {
+	Data     []frontend.Variable
+	Digest   frontend.Variable
+	Left     frontend.Variable
+	Right    frontend.Variable
+	Compress frontend.Variable
+}
+
+func (c *poseidon2Circuit) Define(api frontend.API) error {
+	h, err := NewHasher(api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Data...)
+	api.AssertIsEqual(h.Sum(), c.Digest)
+
+	p, err := NewPermutation(api, 2)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(p.Compress(c.Left, c.Right), c.Compress)
+	return nil
+}
+
+func TestNativeMatchesCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		for _, n := range []int{0, 1, 2, 5} {
+			data := make([]frontend.Variable, n)
+			h, err := NewNativeHasher(curve)
+			assert.NoError(err)
+			for i := range data {
+				e := big.NewInt(int64(i + 1))
+				e.Lsh(e, 250)
+				h.WriteElements(e)
+				data[i] = e
+			}
+			p, err := NewNativePermutation(curve, 2)
+			assert.NoError(err)
+			left, right := big.NewInt(int64(n)), big.NewInt(42)
+
+			circuit := poseidon2Circuit{Data: make([]frontend.Variable, n)}
+			witness := poseidon2Circuit{Data: data, Digest: h.SumElement(), Left: left, Right: right, Compress: p.Compress(left, right)}
+			assert.NoError(test.IsSolved(&circuit, &witness, curve.ScalarField()), "%s, %d elements", curve, n)
+
+			witness.Digest = new(big.Int).Add(h.SumElement(), big.NewInt(1))
+			assert.Error(test.IsSolved(&circuit, &witness, curve.ScalarField()), "%s, %d elements", curve, n)
+		}
+	}
+}
+
+func TestNativeHasher(t *testing.T) {
+	assert := test.NewAssert(t)
+	h, err := NewNativeHasher(ecc.BN254)
+	assert.NoError(err)
+
+	// the padding separates the data ending with zeros
+	h.WriteElements(big.NewInt(0))
+	d1 := h.Sum(nil)
+	_, err = h.Write(make([]byte, h.BlockSize()))
+	assert.NoError(err)
+	assert.NotEqual(d1, h.Sum(nil))
+
+	h.Reset()
+	h.WriteElements(big.NewInt(0))
+	assert.Equal(d1, h.Sum(nil))
+
+	_, err = h.Write(make([]byte, h.BlockSize()-1))
+	assert.Error(err)
+	_, err = h.Write(ecc.BN254.ScalarField().FillBytes(make([]byte, h.BlockSize())))
+	assert.Error(err)
+}
+
+type permutationCircuit struct {
+	State [3]frontend.Variable
+}
+
+func (c *permutationCircuit) Define(api frontend.API) error {
+	p, err := NewPermutation(api, 3)
+	if err != nil {
+		return err
+	}
+	p.Permute(c.State[:])
+	return nil
+}
+
+func TestPermutationConstraints(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &permutationCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nb := ccs.GetNbConstraints(); nb != 240 {
+		t.Fatalf("the permutation has %d constraints, expected 240", nb)
+	}
+}
@@ -0,0 +1,35 @@
+package sha2
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hints used in this package
+func GetHints() []solver.Hint {
+	return []solver.Hint{addHint}
+}
+
+// addHint returns the bits of the sum modulo 2^wordSize, least significant first, and the
+// carry, given the word size and the sum.
+func addHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 2 {
+		return errors.New("expecting the word size and the sum")
+	}
+	wordSize := int(inputs[0].Int64())
+	if len(outputs) != wordSize+1 {
+		return errors.New("expecting the bits and the carry")
+	}
+	sum := inputs[1]
+	for i := 0; i < wordSize; i++ {
+		outputs[i].SetUint64(uint64(sum.Bit(i)))
+	}
+	outputs[wordSize].Rsh(sum, uint(wordSize))
+	return nil
+}
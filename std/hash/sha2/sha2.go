@@ -0,0 +1,281 @@
+// Package sha2 computes the SHA-256 and SHA-512 digests (FIPS 180-4) of byte strings in
+// circuit, for the protocols hashing with them outside of the circuits: the hash-to-curve of
+// the BLS signatures, the Ed25519 signatures, Bitcoin and the Ethereum consensus layer.
+//
+// The bytes are variables, decomposed in bits, and the length of the data is fixed by the
+// circuit. The words are handled as their bits: the rotations and shifts are free, and the
+// boolean functions cost one or two constraints per bit. The additions modulo the size of the
+// words take the bits of the result and the carry from a hint: the bits are constrained to be
+// booleans and the carries, of three bits, are range checked together, through the commitment
+// in R1CS (see the rangecheck package), instead of decomposing the whole sums. A block costs
+// about 26k constraints in R1CS for SHA-256, and 67k for SHA-512 whose blocks are twice
+// larger.
+package sha2
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// Sum256 returns the SHA-256 digest of the bytes of data, constraining them to be bytes.
+func Sum256(api frontend.API, data []frontend.Variable) [32]frontend.Variable {
+	var res [32]frontend.Variable
+	copy(res[:], sum(api, &sha256Params, data))
+	return res
+}
+
+// Sum512 returns the SHA-512 digest of the bytes of data, constraining them to be bytes.
+func Sum512(api frontend.API, data []frontend.Variable) [64]frontend.Variable {
+	var res [64]frontend.Variable
+	copy(res[:], sum(api, &sha512Params, data))
+	return res
+}
+
+// params are the parameters of a function of the SHA-2 family.
+type params struct {
+	wordSize, nbRounds int
+	init, k            []uint64
+
+	// the rotations of Σ0 and Σ1, and the rotations and the shift of σ0 and σ1
+	bigSigma0, bigSigma1 [3]int
+	sigma0, sigma1       [3]int
+}
+
+var sha256Params = params{
+	wordSize:  32,
+	nbRounds:  64,
+	init:      sha256Init,
+	k:         sha256K,
+	bigSigma0: [3]int{2, 13, 22},
+	bigSigma1: [3]int{6, 11, 25},
+	sigma0:    [3]int{7, 18, 3},
+	sigma1:    [3]int{17, 19, 10},
+}
+
+var sha512Params = params{
+	wordSize:  64,
+	nbRounds:  80,
+	init:      sha512Init,
+	k:         sha512K,
+	bigSigma0: [3]int{28, 34, 39},
+	bigSigma1: [3]int{14, 18, 41},
+	sigma0:    [3]int{1, 8, 7},
+	sigma1:    [3]int{19, 61, 6},
+}
+
+// word is a word as its bits, least significant first.
+type word []frontend.Variable
+
+type hasher struct {
+	api frontend.API
+	rc  frontend.Rangechecker
+	*params
+}
+
+func sum(api frontend.API, p *params, data []frontend.Variable) []frontend.Variable {
+	h := hasher{api: api, rc: rangecheck.New(api), params: p}
+	wordBytes := p.wordSize / 8
+	blockSize := 16 * wordBytes
+
+	// the bytes as bits, padded with 1, zeros, and the length in bits on two words
+	msg := make([][]frontend.Variable, len(data), len(data)+blockSize+2*wordBytes+1)
+	for i := range data {
+		msg[i] = bits.ToBinary(api, data[i], bits.WithNbDigits(8))
+	}
+	msg = append(msg, constBits(0x80, 8))
+	for len(msg)%blockSize != blockSize-2*wordBytes {
+		msg = append(msg, constBits(0, 8))
+	}
+	length := new(big.Int).Lsh(big.NewInt(int64(len(data))), 3)
+	for i := 2*wordBytes - 1; i >= 0; i-- {
+		msg = append(msg, constBits(new(big.Int).Rsh(length, uint(8*i)).Uint64()&0xff, 8))
+	}
+
+	state := make([]word, 8)
+	for i := range state {
+		state[i] = constBits(p.init[i], p.wordSize)
+	}
+	w := make([]word, p.nbRounds)
+	for block := msg; len(block) > 0; block = block[blockSize:] {
+		for t := 0; t < 16; t++ {
+			w[t] = h.fromBytes(block[t*wordBytes : (t+1)*wordBytes])
+		}
+		for t := 16; t < p.nbRounds; t++ {
+			w[t] = h.add(h.value(h.smallSigma(w[t-2], p.sigma1)), h.value(w[t-7]), h.value(h.smallSigma(w[t-15], p.sigma0)), h.value(w[t-16]))
+		}
+
+		a, b, c, d, e, f, g, hh := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
+		for t := 0; t < p.nbRounds; t++ {
+			t1 := []frontend.Variable{h.value(hh), h.value(h.bigSigma(e, p.bigSigma1)), h.ch(e, f, g), new(big.Int).SetUint64(p.k[t]), h.value(w[t])}
+			t2 := []frontend.Variable{h.value(h.bigSigma(a, p.bigSigma0)), h.maj(a, b, c)}
+			hh, g, f = g, f, e
+			e = h.add(append([]frontend.Variable{h.value(d)}, t1...)...)
+			d, c, b = c, b, a
+			a = h.add(append(t1, t2...)...)
+		}
+		for i, x := range []word{a, b, c, d, e, f, g, hh} {
+			state[i] = h.add(h.value(state[i]), h.value(x))
+		}
+	}
+
+	// the words in big-endian, recomposing the bytes
+	digest := make([]frontend.Variable, 0, 8*wordBytes)
+	for i := range state {
+		for j := wordBytes - 1; j >= 0; j-- {
+			digest = append(digest, bits.FromBinary(api, state[i][8*j:8*j+8], bits.WithUnconstrainedInputs()))
+		}
+	}
+	return digest
+}
+
+// fromBytes returns the big-endian word of the bytes.
+func (h hasher) fromBytes(b [][]frontend.Variable) word {
+	res := make(word, 0, h.wordSize)
+	for j := len(b) - 1; j >= 0; j-- {
+		res = append(res, b[j]...)
+	}
+	return res
+}
+
+// value returns the integer value of w.
+func (h hasher) value(w word) frontend.Variable {
+	terms := make([]frontend.Variable, len(w))
+	for i := range w {
+		terms[i] = h.api.Mul(w[i], new(big.Int).Lsh(big.NewInt(1), uint(i)))
+	}
+	return h.api.Add(terms[0], terms[1], terms[2:]...)
+}
+
+// carryBits is the number of bits of the carries of the additions of at most 8 words.
+const carryBits = 3
+
+// add returns the sum of at most 8 values modulo 2^wordSize, whose bits and carry are given by
+// addHint.
+func (h hasher) add(values ...frontend.Variable) word {
+	if len(values) > 1<<carryBits {
+		panic("sha2: too many values to add")
+	}
+	sum := h.api.Add(values[0], values[1], values[2:]...)
+	res, err := h.api.Compiler().NewHint(addHint, h.wordSize+1, h.wordSize, sum)
+	if err != nil {
+		panic(err)
+	}
+	w, carry := word(res[:h.wordSize]), res[h.wordSize]
+	for i := range w {
+		h.api.AssertIsBoolean(w[i])
+	}
+	h.rc.Check(carry, carryBits)
+	h.api.AssertIsEqual(sum, h.api.Add(h.value(w), h.api.Mul(carry, new(big.Int).Lsh(big.NewInt(1), uint(h.wordSize)))))
+	return w
+}
+
+func (h hasher) xor3(x, y, z word) word {
+	res := make(word, len(x))
+	for i := range res {
+		res[i] = h.api.Xor(h.api.Xor(x[i], y[i]), z[i])
+	}
+	return res
+}
+
+// ch returns the value of (e ∧ f) ⊕ (¬e ∧ g), with a constraint per bit.
+func (h hasher) ch(e, f, g word) frontend.Variable {
+	res := make(word, len(e))
+	for i := range res {
+		res[i] = h.api.Add(g[i], h.api.Mul(e[i], h.api.Sub(f[i], g[i])))
+	}
+	return h.value(res)
+}
+
+// maj returns the value of the majority of a, b and c, with two constraints per bit.
+func (h hasher) maj(a, b, c word) frontend.Variable {
+	res := make(word, len(a))
+	for i := range res {
+		bc := h.api.Mul(b[i], c[i])
+		res[i] = h.api.Add(bc, h.api.Mul(a[i], h.api.Sub(h.api.Add(b[i], c[i]), h.api.Mul(bc, 2))))
+	}
+	return h.value(res)
+}
+
+func (h hasher) bigSigma(x word, r [3]int) word {
+	return h.xor3(rotr(x, r[0]), rotr(x, r[1]), rotr(x, r[2]))
+}
+
+func (h hasher) smallSigma(x word, r [3]int) word {
+	return h.xor3(rotr(x, r[0]), rotr(x, r[1]), shr(x, r[2]))
+}
+
+func rotr(x word, n int) word {
+	res := make(word, len(x))
+	for i := range res {
+		res[i] = x[(i+n)%len(x)]
+	}
+	return res
+}
+
+func shr(x word, n int) word {
+	res := make(word, len(x))
+	for i := range res {
+		if i+n < len(x) {
+			res[i] = x[i+n]
+		} else {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+func constBits(v uint64, n int) word {
+	res := make(word, n)
+	for i := range res {
+		res[i] = (v >> i) & 1
+	}
+	return res
+}
+
+// sha256Init and sha256K are the initial hash value and the round constants of SHA-256.
+var sha256Init = []uint64{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var sha256K = []uint64{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+// sha512Init and sha512K are the initial hash value and the round constants of SHA-512.
+var sha512Init = []uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var sha512K = []uint64{
+	0x428a2f98d728ae22, 0x7137449123ef65cd, 0xb5c0fbcfec4d3b2f, 0xe9b5dba58189dbbc,
+	0x3956c25bf348b538, 0x59f111f1b605d019, 0x923f82a4af194f9b, 0xab1c5ed5da6d8118,
+	0xd807aa98a3030242, 0x12835b0145706fbe, 0x243185be4ee4b28c, 0x550c7dc3d5ffb4e2,
+	0x72be5d74f27b896f, 0x80deb1fe3b1696b1, 0x9bdc06a725c71235, 0xc19bf174cf692694,
+	0xe49b69c19ef14ad2, 0xefbe4786384f25e3, 0x0fc19dc68b8cd5b5, 0x240ca1cc77ac9c65,
+	0x2de92c6f592b0275, 0x4a7484aa6ea6e483, 0x5cb0a9dcbd41fbd4, 0x76f988da831153b5,
+	0x983e5152ee66dfab, 0xa831c66d2db43210, 0xb00327c898fb213f, 0xbf597fc7beef0ee4,
+	0xc6e00bf33da88fc2, 0xd5a79147930aa725, 0x06ca6351e003826f, 0x142929670a0e6e70,
+	0x27b70a8546d22ffc, 0x2e1b21385c26c926, 0x4d2c6dfc5ac42aed, 0x53380d139d95b3df,
+	0x650a73548baf63de, 0x766a0abb3c77b2a8, 0x81c2c92e47edaee6, 0x92722c851482353b,
+	0xa2bfe8a14cf10364, 0xa81a664bbc423001, 0xc24b8b70d0f89791, 0xc76c51a30654be30,
+	0xd192e819d6ef5218, 0xd69906245565a910, 0xf40e35855771202a, 0x106aa07032bbd1b8,
+	0x19a4c116b8d2d0c8, 0x1e376c085141ab53, 0x2748774cdf8eeb99, 0x34b0bcb5e19b48a8,
+	0x391c0cb3c5c95a63, 0x4ed8aa4ae3418acb, 0x5b9cca4f7763e373, 0x682e6ff3d6b2b8a3,
+	0x748f82ee5defb2fc, 0x78a5636f43172f60, 0x84c87814a1f0ab72, 0x8cc702081a6439ec,
+	0x90befffa23631e28, 0xa4506cebde82bde9, 0xbef9a3f7b2c67915, 0xc67178f2e372532b,
+	0xca273eceea26619c, 0xd186b8c721c0c207, 0xeada7dd6cde0eb1e, 0xf57d4f7fee6ed178,
+	0x06f067aa72176fba, 0x0a637dc5a2c898a6, 0x113f9804bef90dae, 0x1b710b35131c471b,
+	0x28db77f523047d84, 0x32caab7b40c72493, 0x3c9ebe0a15c9bebc, 0x431d67c49c100d4c,
+	0x4cc5d4becb3e42b6, 0x597f299cfc657e2a, 0x5fcb6fab3ad6faec, 0x6c44198c4a475817,
+}
@@ -0,0 +1,76 @@
+package sha2
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+type sumCircuit struct {
+	Data   []frontend.Variable
+	Digest []frontend.Variable
+}
+
+func (c *sumCircuit) Define(api frontend.API) error {
+	var digest []frontend.Variable
+	if len(c.Digest) == 32 {
+		d := Sum256(api, c.Data)
+		digest = d[:]
+	} else {
+		d := Sum512(api, c.Data)
+		digest = d[:]
+	}
+	for i := range digest {
+		api.AssertIsEqual(digest[i], c.Digest[i])
+	}
+	return nil
+}
+
+func bytesToVariables(b []byte) []frontend.Variable {
+	res := make([]frontend.Variable, len(b))
+	for i := range b {
+		res[i] = b[i]
+	}
+	return res
+}
+
+func TestSum(t *testing.T) {
+	assert := test.NewAssert(t)
+	// around the lengths of the padding in one and two blocks
+	for _, n := range []int{0, 3, 55, 56, 111, 112, 200} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		d256, d512 := sha256.Sum256(data), sha512.Sum512(data)
+		for _, digest := range [][]byte{d256[:], d512[:]} {
+			circuit := sumCircuit{Data: make([]frontend.Variable, n), Digest: make([]frontend.Variable, len(digest))}
+			witness := sumCircuit{Data: bytesToVariables(data), Digest: bytesToVariables(digest)}
+			assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "length %d, digest size %d", n, len(digest))
+
+			witness.Digest[0] = (digest[0] + 1) & 0xff
+			assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+		}
+	}
+}
+
+func TestSum256Constraints(t *testing.T) {
+	// one and two blocks: the difference is the cost of a block, without the bytes
+	var nbConstraints [2]int
+	for i, n := range []int{55, 119} {
+		circuit := sumCircuit{Data: make([]frontend.Variable, n), Digest: make([]frontend.Variable, 32)}
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nbConstraints[i] = ccs.GetNbConstraints()
+	}
+	if nb := nbConstraints[1] - nbConstraints[0]; nb >= 30000 {
+		t.Fatalf("a block has %d constraints, expected less than 30000", nb)
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"sync"
 
 	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
 	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
 	"github.com/consensys/gnark/std/algebra/native/sw_bls24315"
+	"github.com/consensys/gnark/std/evm"
 	"github.com/consensys/gnark/std/evmprecompiles"
+	"github.com/consensys/gnark/std/hash/sha2"
 	"github.com/consensys/gnark/std/internal/logderivarg"
 	"github.com/consensys/gnark/std/lookup/logderivlookup"
 	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/cmp"
 	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/consensys/gnark/std/rangecheck"
 	"github.com/consensys/gnark/std/selector"
+	"github.com/consensys/gnark/std/signature/bls"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
 var registerOnce sync.Once
@@ -38,7 +45,14 @@ func registerHints() {
 	solver.RegisterHint(selector.GetHints()...)
 	solver.RegisterHint(emulated.GetHints()...)
 	solver.RegisterHint(rangecheck.GetHints()...)
+	solver.RegisterHint(cmp.GetHints()...)
 	solver.RegisterHint(evmprecompiles.GetHints()...)
+	solver.RegisterHint(evm.GetHints()...)
 	solver.RegisterHint(logderivarg.GetHints()...)
 	solver.RegisterHint(logderivlookup.GetHints()...)
+	solver.RegisterHint(bls.GetHints()...)
+	solver.RegisterHint(ecdsa.GetHints()...)
+	solver.RegisterHint(eddsa.GetHints()...)
+	solver.RegisterHint(sha2.GetHints()...)
+	solver.RegisterHint(sw_emulated.GetHints()...)
 }
@@ -2,9 +2,9 @@
 //
 // If the builder implements [frontend.Committer] interface, then we can commit
 // to the variables and get a commitment which can be used as a unique
-// randomness in the circuit. For current builders implementing this interface,
-// the function can only be called once in a circuit. This makes it difficult to
-// compose different gadgets which require randomness.
+// randomness in the circuit. Depending on the builder and the backend, the
+// function can only be called once in a circuit, or only on distinct variables.
+// This makes it difficult to compose different gadgets which require randomness.
 //
 // This package extends the commitment interface by allowing to receive several
 // functions unique commitment multiple times. It does this by collecting all
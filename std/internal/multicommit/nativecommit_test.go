@@ -1,4 +1,4 @@
-package multicommit
+package multicommit_test
 
 import (
 	"testing"
@@ -7,6 +7,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/internal/multicommit"
 	"github.com/consensys/gnark/test"
 )
 
@@ -15,8 +16,8 @@ type noRecursionCircuit struct {
 }
 
 func (c *noRecursionCircuit) Define(api frontend.API) error {
-	WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
-		WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error { return nil }, commitment)
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
+		multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error { return nil }, commitment)
 		return nil
 	}, c.X)
 	return nil
@@ -36,12 +37,12 @@ type multipleCommitmentCircuit struct {
 func (c *multipleCommitmentCircuit) Define(api frontend.API) error {
 	var stored frontend.Variable
 	// first callback receives first unique commitment derived from the root commitment
-	WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
 		api.AssertIsDifferent(c.X, commitment)
 		stored = commitment
 		return nil
 	}, c.X)
-	WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error {
 		api.AssertIsDifferent(stored, commitment)
 		return nil
 	}, c.X)
@@ -60,7 +61,7 @@ type noCommitVariable struct {
 }
 
 func (c *noCommitVariable) Define(api frontend.API) error {
-	WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error { return nil })
+	multicommit.WithCommitment(api, func(api frontend.API, commitment frontend.Variable) error { return nil })
 	return nil
 }
 
@@ -53,6 +53,7 @@ type result struct {
 // New returns a new [*Table]. It additionally defers building the
 // log-derivative argument.
 func New(api frontend.API) *Table {
+	frontend.RequireCapability(api, "logderivlookup.Table", frontend.CapabilityLookup)
 	t := &Table{api: api}
 	api.Compiler().Defer(t.commit)
 	return t
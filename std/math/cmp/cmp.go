@@ -0,0 +1,136 @@
+// Package cmp compares variables known to be close to each other with a hint and a range
+// check, instead of the bit decompositions of api.Cmp and api.AssertIsLessOrEqual.
+//
+// The comparisons of api decompose both operands in as many bits as the field, which costs
+// thousands of constraints per comparison with the R1CS builder. A BoundedComparator instead
+// takes the result of a comparison from a hint, and checks it with a single range check of
+// the difference of the operands, in the number of bits of its bound. With a builder
+// implementing frontend.Committer, the range checks of a circuit are batched in a lookup
+// argument against a commitment (see the rangecheck package), so that a comparison of 64-bit
+// values costs a handful of constraints:
+//
+//	comparator := cmp.NewBoundedComparator(api, new(big.Int).Lsh(big.NewInt(1), 64))
+//	comparator.AssertIsLessEq(timestamp, deadline)
+//	latest := comparator.Max(a, b)
+//
+// The operands are compared as signed values: a is less than b if (b-a) mod p is in
+// [1, (p-1)/2]. It coincides with the comparison of the integers when the operands are in
+// [0, (p-1)/2], and the circuit is unsatisfiable when the absolute difference of the
+// operands exceeds the bound of the comparator, so the bound must be established by the
+// circuit (for instance by range checking the operands).
+//
+// The R1CS builder compares the operands of api.Cmp and api.AssertIsLessOrEqual with a
+// BoundedComparator when the circuit is compiled with frontend.WithBoundedComparisons, which
+// range checks the operands to the given number of bits.
+//
+// api.IsZero doesn't need a replacement: it takes the inverse of its input from a hint, and
+// already costs 2 constraints.
+package cmp
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hints used in this package
+func GetHints() []solver.Hint {
+	return []solver.Hint{isLessHint}
+}
+
+// BoundedComparator compares variables whose absolute difference is bounded, see the package
+// documentation.
+type BoundedComparator struct {
+	api    frontend.API
+	rc     frontend.Rangechecker
+	nbBits int
+}
+
+// NewBoundedComparator returns a comparator of the variables whose absolute difference is at
+// most absDiffUpp. It panics if the bound exceeds a quarter of the field, for which the range
+// check of the difference would not establish the result of the comparison.
+func NewBoundedComparator(api frontend.API, absDiffUpp *big.Int) *BoundedComparator {
+	if absDiffUpp.Sign() <= 0 {
+		panic("the bound of the comparator must be positive")
+	}
+	nbBits := absDiffUpp.BitLen()
+	if nbBits > api.Compiler().FieldBitLen()-2 {
+		panic(fmt.Sprintf("the bound of the comparator has %d bits, more than the field size minus 2", nbBits))
+	}
+	return &BoundedComparator{
+		api:    api,
+		rc:     rangecheck.New(api),
+		nbBits: nbBits,
+	}
+}
+
+// IsLess returns 1 if a < b, and 0 otherwise.
+func (c *BoundedComparator) IsLess(a, b frontend.Variable) frontend.Variable {
+	res, err := c.api.Compiler().NewHint(isLessHint, 1, a, b)
+	if err != nil {
+		panic(err)
+	}
+	r := res[0]
+	c.api.AssertIsBoolean(r)
+
+	// the difference is b-a-1 if r = 1 and a-b otherwise, which is in the range of the bound
+	// only if r is the result of the comparison: else it is p minus a value of the range
+	aMinusB := c.api.Sub(a, b)
+	diff := c.api.Add(aMinusB, c.api.Mul(r, c.api.Sub(-1, c.api.Mul(aMinusB, 2))))
+	c.rc.Check(diff, c.nbBits)
+	return r
+}
+
+// IsLessEq returns 1 if a ≤ b, and 0 otherwise.
+func (c *BoundedComparator) IsLessEq(a, b frontend.Variable) frontend.Variable {
+	return c.api.Sub(1, c.IsLess(b, a))
+}
+
+// AssertIsLess fails if a ≥ b.
+func (c *BoundedComparator) AssertIsLess(a, b frontend.Variable) {
+	c.rc.Check(c.api.Sub(b, a, 1), c.nbBits)
+}
+
+// AssertIsLessEq fails if a > b.
+func (c *BoundedComparator) AssertIsLessEq(a, b frontend.Variable) {
+	c.rc.Check(c.api.Sub(b, a), c.nbBits)
+}
+
+// Cmp returns 1 if a > b, 0 if a = b and -1 if a < b, like api.Cmp.
+func (c *BoundedComparator) Cmp(a, b frontend.Variable) frontend.Variable {
+	return c.api.Sub(c.IsLess(b, a), c.IsLess(a, b))
+}
+
+// Min returns the minimum of a and b.
+func (c *BoundedComparator) Min(a, b frontend.Variable) frontend.Variable {
+	return c.api.Select(c.IsLess(a, b), a, b)
+}
+
+// Max returns the maximum of a and b.
+func (c *BoundedComparator) Max(a, b frontend.Variable) frontend.Variable {
+	return c.api.Select(c.IsLess(a, b), b, a)
+}
+
+// isLessHint returns 1 if a < b as signed values, that is if (b-a) mod p is in [1, (p-1)/2],
+// and 0 otherwise.
+func isLessHint(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) != 2 || len(outputs) != 1 {
+		return fmt.Errorf("expected 2 inputs and 1 output")
+	}
+	d := new(big.Int).Sub(inputs[1], inputs[0])
+	d.Mod(d, mod)
+	half := new(big.Int).Rsh(mod, 1)
+	if d.Sign() != 0 && d.Cmp(half) <= 0 {
+		outputs[0].SetUint64(1)
+	} else {
+		outputs[0].SetUint64(0)
+	}
+	return nil
+}
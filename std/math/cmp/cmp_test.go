@@ -0,0 +1,152 @@
+package cmp_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/test"
+)
+
+type cmpCircuit struct {
+	A, B                  frontend.Variable
+	IsLess, IsLessEq, Cmp frontend.Variable
+	Min, Max              frontend.Variable
+}
+
+func (c *cmpCircuit) Define(api frontend.API) error {
+	comparator := cmp.NewBoundedComparator(api, new(big.Int).Lsh(big.NewInt(1), 64))
+	api.AssertIsEqual(comparator.IsLess(c.A, c.B), c.IsLess)
+	api.AssertIsEqual(comparator.IsLessEq(c.A, c.B), c.IsLessEq)
+	api.AssertIsEqual(comparator.Cmp(c.A, c.B), c.Cmp)
+	api.AssertIsEqual(comparator.Min(c.A, c.B), c.Min)
+	api.AssertIsEqual(comparator.Max(c.A, c.B), c.Max)
+	return nil
+}
+
+func TestBoundedComparator(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	for _, tc := range []struct{ a, b int64 }{{3, 5}, {5, 3}, {4, 4}, {0, 1 << 62}, {-7, 2}, {2, -7}} {
+		isLess, isLessEq, res, min, max := 0, 0, 0, tc.a, tc.b
+		if tc.a < tc.b {
+			isLess, isLessEq, res = 1, 1, -1
+		} else if tc.a == tc.b {
+			isLessEq = 1
+		} else {
+			res, min, max = 1, tc.b, tc.a
+		}
+		assert.NoError(test.IsSolved(&cmpCircuit{}, &cmpCircuit{
+			A: tc.a, B: tc.b, IsLess: isLess, IsLessEq: isLessEq, Cmp: res, Min: min, Max: max,
+		}, field), "%d %d", tc.a, tc.b)
+	}
+
+	// a difference over the bound makes the circuit unsatisfiable, whatever the hint
+	a := new(big.Int).Lsh(big.NewInt(1), 65)
+	assert.Error(test.IsSolved(&cmpCircuit{}, &cmpCircuit{A: a, B: 0, IsLess: 0, IsLessEq: 0, Cmp: 1, Min: 0, Max: a}, field))
+}
+
+type assertCircuit struct {
+	A, B   frontend.Variable
+	strict bool
+}
+
+func (c *assertCircuit) Define(api frontend.API) error {
+	comparator := cmp.NewBoundedComparator(api, big.NewInt(1000))
+	if c.strict {
+		comparator.AssertIsLess(c.A, c.B)
+	} else {
+		comparator.AssertIsLessEq(c.A, c.B)
+	}
+	return nil
+}
+
+func TestAssert(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	for _, strict := range []bool{false, true} {
+		assert.NoError(test.IsSolved(&assertCircuit{strict: strict}, &assertCircuit{A: 10, B: 20}, field))
+		assert.NoError(test.IsSolved(&assertCircuit{strict: strict}, &assertCircuit{A: 10, B: 1010}, field))
+		assert.Error(test.IsSolved(&assertCircuit{strict: strict}, &assertCircuit{A: 20, B: 10}, field))
+	}
+	assert.NoError(test.IsSolved(&assertCircuit{}, &assertCircuit{A: 10, B: 10}, field))
+	assert.Error(test.IsSolved(&assertCircuit{strict: true}, &assertCircuit{A: 10, B: 10}, field))
+}
+
+type manyCmpCircuit struct {
+	A, B    []frontend.Variable
+	bounded bool
+}
+
+func (c *manyCmpCircuit) Define(api frontend.API) error {
+	comparator := cmp.NewBoundedComparator(api, new(big.Int).Lsh(big.NewInt(1), 64))
+	for i := range c.A {
+		if c.bounded {
+			api.AssertIsEqual(comparator.Cmp(c.A[i], c.B[i]), 1)
+		} else {
+			api.AssertIsEqual(api.Cmp(c.A[i], c.B[i]), 1)
+		}
+	}
+	return nil
+}
+
+func TestConstraintReduction(t *testing.T) {
+	assert := test.NewAssert(t)
+	const n = 100
+	nbConstraints := func(bounded bool) int {
+		circuit := manyCmpCircuit{A: make([]frontend.Variable, n), B: make([]frontend.Variable, n), bounded: bounded}
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+		assert.NoError(err)
+		return ccs.GetNbConstraints()
+	}
+	bounded, full := nbConstraints(true), nbConstraints(false)
+	t.Logf("%d comparisons: %d constraints, %d with api.Cmp", n, bounded, full)
+	assert.Less(10*bounded, full)
+
+	// api.Cmp of the values of 64 bits
+	circuit := manyCmpCircuit{A: make([]frontend.Variable, n), B: make([]frontend.Variable, n)}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit, frontend.WithBoundedComparisons(64))
+	assert.NoError(err)
+	t.Logf("%d constraints with frontend.WithBoundedComparisons", ccs.GetNbConstraints())
+	assert.Less(10*ccs.GetNbConstraints(), full)
+}
+
+type builderCmpCircuit struct {
+	A, B, Cmp frontend.Variable
+}
+
+func (c *builderCmpCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Cmp(c.A, c.B), c.Cmp)
+	api.AssertIsLessOrEqual(c.A, 1<<40)
+	// a constant out of the bounds: the full comparison
+	api.AssertIsLessOrEqual(c.B, new(big.Int).Lsh(big.NewInt(1), 64))
+	return nil
+}
+
+func TestBoundedComparisons(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	ccs, err := frontend.Compile(field, r1cs.NewBuilder, &builderCmpCircuit{}, frontend.WithBoundedComparisons(64))
+	assert.NoError(err)
+
+	isSolved := func(a, b, cmp any) error {
+		w, err := frontend.NewWitness(&builderCmpCircuit{A: a, B: b, Cmp: cmp}, field)
+		assert.NoError(err)
+		return ccs.IsSolved(w)
+	}
+	assert.NoError(isSolved(3, 5, -1))
+	assert.NoError(isSolved(5, 3, 1))
+	assert.NoError(isSolved(4, 4, 0))
+	assert.NoError(isSolved(0, uint64(1<<63), -1))
+	assert.Error(isSolved(3, 5, 1))
+	assert.Error(isSolved(1<<41, 5, 1), "over the bound of api.AssertIsLessOrEqual")
+	// operands over the bits of the option make the circuit unsatisfiable
+	assert.Error(isSolved(3, new(big.Int).Lsh(big.NewInt(1), 64), -1))
+	assert.Error(isSolved(-1, 5, -1))
+
+	_, err = frontend.Compile(field, r1cs.NewBuilder, &builderCmpCircuit{}, frontend.WithBoundedComparisons(field.BitLen()-1))
+	assert.Error(err)
+}
@@ -50,12 +50,28 @@ func nbMultiplicationResLimbs(lenLeft, lenRight int) int {
 // the product and stores it in output. See internal method
 // computeMultiplicationHint for the input packing.
 func MultiplicationHint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	nbLimbsLeft, err := parseMultiplicationInputs(mod, inputs, outputs)
+	if err != nil {
+		return err
+	}
+	tmp := new(big.Int)
+	for i, li := range inputs[3 : 3+nbLimbsLeft] {
+		for j, rj := range inputs[3+nbLimbsLeft:] {
+			outputs[i+j].Add(outputs[i+j], tmp.Mul(li, rj))
+		}
+	}
+	return nil
+}
+
+// parseMultiplicationInputs checks the inputs of MultiplicationHint, zeroes the outputs and
+// returns the number of limbs of the left factor.
+func parseMultiplicationInputs(mod *big.Int, inputs []*big.Int, outputs []*big.Int) (int, error) {
 	if len(inputs) < 3 {
-		return fmt.Errorf("input must be at least three elements")
+		return 0, fmt.Errorf("input must be at least three elements")
 	}
 	nbBits := int(inputs[0].Int64())
 	if 2*nbBits+1 >= mod.BitLen() {
-		return fmt.Errorf("can not fit multiplication result into limb of length %d", nbBits)
+		return 0, fmt.Errorf("can not fit multiplication result into limb of length %d", nbBits)
 	}
 	// TODO: check that the scalar field fits 2*nbBits + nbLimbs. 2*nbBits comes
 	// from multiplication and nbLimbs comes from additions.
@@ -64,24 +80,18 @@ func MultiplicationHint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) err
 	// TODO: get the limb length from the input instead of packing into input
 	nbLimbsRight := int(inputs[2].Int64())
 	if len(inputs) != 3+nbLimbsLeft+nbLimbsRight {
-		return fmt.Errorf("input invalid")
+		return 0, fmt.Errorf("input invalid")
 	}
 	if len(outputs) < nbLimbsLeft+nbLimbsRight-1 {
-		return fmt.Errorf("can not fit multiplication result into %d limbs", len(outputs))
+		return 0, fmt.Errorf("can not fit multiplication result into %d limbs", len(outputs))
 	}
 	for _, oi := range outputs {
 		if oi == nil {
-			return fmt.Errorf("output not initialized")
+			return 0, fmt.Errorf("output not initialized")
 		}
 		oi.SetUint64(0)
 	}
-	tmp := new(big.Int)
-	for i, li := range inputs[3 : 3+nbLimbsLeft] {
-		for j, rj := range inputs[3+nbLimbsLeft:] {
-			outputs[i+j].Add(outputs[i+j], tmp.Mul(li, rj))
-		}
-	}
-	return nil
+	return nbLimbsLeft, nil
 }
 
 // computeRemHint packs inputs for the RemHint hint function.
@@ -0,0 +1,111 @@
+package emulated
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// WithBatchHints is a solver option evaluating the calls of the multiplication and reduction
+// hints by batches, see [solver.WithBatchHint]: the calls of a level of the solver are
+// evaluated at once on all the CPUs, and the products of limbs of at most 64 bits with machine
+// words instead of big integers. These hints dominate the solving time of the circuits with a
+// lot of emulated arithmetic, such as the verifications of secp256k1 signatures.
+//
+// A device implementation of the multiplications can be given instead with
+// solver.WithBatchHint(MultiplicationHint, f).
+func WithBatchHints() solver.Option {
+	return func(opt *solver.Config) error {
+		for _, o := range []solver.Option{
+			solver.WithBatchHint(MultiplicationHint, BatchMultiplicationHint),
+			solver.WithBatchHint(QuoHint, batchOf(QuoHint)),
+			solver.WithBatchHint(RemHint, batchOf(RemHint)),
+		} {
+			if err := o(opt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// BatchMultiplicationHint is the batch evaluation of MultiplicationHint. The limbs of the
+// products of factors whose limbs fit on 64 bits are accumulated on three machine words.
+func BatchMultiplicationHint(mod *big.Int, inputs, outputs [][]*big.Int) error {
+	return parallelize(len(inputs), func(i int) error {
+		nbLimbsLeft, err := parseMultiplicationInputs(mod, inputs[i], outputs[i])
+		if err != nil {
+			return err
+		}
+		left, right := inputs[i][3:3+nbLimbsLeft], inputs[i][3+nbLimbsLeft:]
+		if !fitWords(left) || !fitWords(right) {
+			return MultiplicationHint(mod, inputs[i], outputs[i])
+		}
+
+		// the accumulators of the limbs of the product, on 192 bits, most significant word
+		// first: the sums of less than 2^64 products of 64 bits don't overflow
+		acc := make([][3]uint64, len(left)+len(right)-1)
+		for j, l := range left {
+			lw := l.Uint64()
+			for k, r := range right {
+				hi, lo := bits.Mul64(lw, r.Uint64())
+				a := &acc[j+k]
+				var carry uint64
+				a[2], carry = bits.Add64(a[2], lo, 0)
+				a[1], carry = bits.Add64(a[1], hi, carry)
+				a[0] += carry
+			}
+		}
+		var buf [24]byte
+		for j := range acc {
+			for k, w := range acc[j] {
+				binary.BigEndian.PutUint64(buf[8*k:], w)
+			}
+			outputs[i][j].SetBytes(buf[:])
+		}
+		return nil
+	})
+}
+
+// batchOf returns the batch evaluation of the hint calling it on all the CPUs.
+func batchOf(hint solver.Hint) solver.BatchHint {
+	return func(mod *big.Int, inputs, outputs [][]*big.Int) error {
+		return parallelize(len(inputs), func(i int) error {
+			return hint(mod, inputs[i], outputs[i])
+		})
+	}
+}
+
+// parallelize calls f on 0, …, n-1 on all the CPUs, and returns the first error.
+func parallelize(n int, f func(i int) error) error {
+	var (
+		lock     sync.Mutex
+		firstErr error
+	)
+	utils.Parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			if err := f(i); err != nil {
+				lock.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	return firstErr
+}
+
+func fitWords(limbs []*big.Int) bool {
+	for _, l := range limbs {
+		if l.Sign() < 0 || l.BitLen() > 64 {
+			return false
+		}
+	}
+	return true
+}
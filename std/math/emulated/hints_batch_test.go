@@ -0,0 +1,119 @@
+package emulated
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+func TestBatchMultiplicationHint(t *testing.T) {
+	assert := test.NewAssert(t)
+	mod := ecc.BN254.ScalarField()
+	var inputs, expected, outputs [][]*big.Int
+	// limbs on 64 bits, and larger limbs falling back to the big integers
+	for _, limbBits := range []int{64, 64, 70} {
+		in := []*big.Int{big.NewInt(64), big.NewInt(4), big.NewInt(5)}
+		for i := 0; i < 9; i++ {
+			l, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(limbBits)))
+			assert.NoError(err)
+			in = append(in, l)
+		}
+		inputs = append(inputs, in)
+		expected = append(expected, newBigInts(8))
+		outputs = append(outputs, newBigInts(8))
+		assert.NoError(MultiplicationHint(mod, in, expected[len(expected)-1]))
+	}
+	assert.NoError(BatchMultiplicationHint(mod, inputs, outputs))
+	for i := range outputs {
+		for j := range outputs[i] {
+			assert.Equal(0, expected[i][j].Cmp(outputs[i][j]), "call %d, limb %d", i, j)
+		}
+	}
+
+	inputs[1] = inputs[1][:5]
+	assert.Error(BatchMultiplicationHint(mod, inputs, outputs))
+}
+
+type mulChainCircuit struct {
+	X, Y Element[Secp256k1Fp]
+}
+
+func (c *mulChainCircuit) Define(api frontend.API) error {
+	f, err := NewField[Secp256k1Fp](api)
+	if err != nil {
+		return err
+	}
+	x := &c.X
+	for i := 0; i < 8; i++ {
+		x = f.Mul(x, f.Add(x, &c.X))
+	}
+	f.AssertIsEqual(x, &c.Y)
+	return nil
+}
+
+func TestWithBatchHints(t *testing.T) {
+	assert := test.NewAssert(t)
+	var fp Secp256k1Fp
+	x, y := big.NewInt(3), big.NewInt(3)
+	for i := 0; i < 8; i++ {
+		y.Mul(y, new(big.Int).Add(y, x)).Mod(y, fp.Modulus())
+	}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &mulChainCircuit{})
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mulChainCircuit{X: ValueOf[Secp256k1Fp](x), Y: ValueOf[Secp256k1Fp](y)}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// the batches evaluate all the calls of the multiplication hint
+	var nbCalls atomic.Int64
+	var nbBatchedCalls int
+	counted := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		nbCalls.Add(1)
+		return MultiplicationHint(mod, inputs, outputs)
+	}
+	_, err = ccs.Solve(w, solver.OverrideHint(solver.GetHintID(MultiplicationHint), counted))
+	assert.NoError(err)
+	countedBatch := func(mod *big.Int, inputs, outputs [][]*big.Int) error {
+		nbBatchedCalls += len(inputs)
+		return BatchMultiplicationHint(mod, inputs, outputs)
+	}
+	_, err = ccs.Solve(w, WithBatchHints(), solver.WithBatchHint(MultiplicationHint, countedBatch))
+	assert.NoError(err)
+	assert.NotZero(nbBatchedCalls)
+	assert.Equal(int(nbCalls.Load()), nbBatchedCalls)
+
+	wrong, err := frontend.NewWitness(&mulChainCircuit{X: ValueOf[Secp256k1Fp](x), Y: ValueOf[Secp256k1Fp](x)}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = ccs.Solve(wrong, WithBatchHints())
+	assert.Error(err)
+
+	// a failing batch returns the located error of the failing call, as without batches
+	failing := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		return errors.New("hint failed")
+	}
+	failingBatch := func(mod *big.Int, inputs, outputs [][]*big.Int) error {
+		return errors.New("batch failed")
+	}
+	_, expected := ccs.Solve(w, solver.OverrideHint(solver.GetHintID(MultiplicationHint), failing))
+	var located *cs_bn254.UnsatisfiedConstraintError
+	assert.True(errors.As(expected, &located))
+	_, err = ccs.Solve(w, solver.OverrideHint(solver.GetHintID(MultiplicationHint), failing), solver.WithBatchHint(MultiplicationHint, failingBatch))
+	assert.Error(err)
+	assert.Equal(expected.Error(), err.Error())
+}
+
+func newBigInts(n int) []*big.Int {
+	res := make([]*big.Int, n)
+	for i := range res {
+		res[i] = new(big.Int)
+	}
+	return res
+}
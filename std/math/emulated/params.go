@@ -17,12 +17,21 @@ type FieldParams interface {
 var (
 	qSecp256k1, rSecp256k1 *big.Int
 	qGoldilocks            *big.Int
+	qEd25519, rEd25519     *big.Int
+	qP256, rP256           *big.Int
+	qPallas, qVesta        *big.Int
 )
 
 func init() {
 	qSecp256k1, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
 	rSecp256k1, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
 	qGoldilocks, _ = new(big.Int).SetString("ffffffff00000001", 16)
+	qEd25519, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+	rEd25519, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+	qP256, _ = new(big.Int).SetString("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 16)
+	rP256, _ = new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+	qPallas, _ = new(big.Int).SetString("40000000000000000000000000000000224698fc094cf91b992d30ed00000001", 16)
+	qVesta, _ = new(big.Int).SetString("40000000000000000000000000000000224698fc0994a8dd8c46eb2100000001", 16)
 }
 
 // Goldilocks provide type parametrization for emulated field on 1 limb of width 64bits
@@ -97,3 +106,121 @@ func (fp BLS12381Fp) NbLimbs() uint     { return 6 }
 func (fp BLS12381Fp) BitsPerLimb() uint { return 64 }
 func (fp BLS12381Fp) IsPrime() bool     { return true }
 func (fp BLS12381Fp) Modulus() *big.Int { return ecc.BLS12_381.BaseField() }
+
+// BW6761Fp provides type parametrization for emulated field on 12 limbs of
+// width 64bits for modulus
+// 0x122e824fb83ce0ad187c94004faff3eb926186a81d14688528275ef8087be41707ba638e584e91903cebaff25b423048689c8ed12f9fd9071dcd3dc73ebff2e98a116c25667a8f8160cf8aeeaf0a437e6913e6870000082f49d00000000008b.
+// This is the base field of the BW6-761 curve.
+type BW6761Fp struct{}
+
+func (fp BW6761Fp) NbLimbs() uint     { return 12 }
+func (fp BW6761Fp) BitsPerLimb() uint { return 64 }
+func (fp BW6761Fp) IsPrime() bool     { return true }
+func (fp BW6761Fp) Modulus() *big.Int { return ecc.BW6_761.BaseField() }
+
+// BW6761Fr provides type parametrization for emulated field on 6 limbs of
+// width 64bits for modulus
+// 0x1ae3a4617c510eac63b05c06ca1493b1a22d9f300f5138f1ef3622fba094800170b5d44300000008508c00000000001.
+// This is the scalar field of the BW6-761 curve, and the base field of the
+// BLS12-377 curve, the same as [BLS12377Fp].
+type BW6761Fr = BLS12377Fp
+
+// Ed25519Fp provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus 2^255-19
+// (0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed). This is
+// the base field of the Ed25519 curve.
+type Ed25519Fp struct{}
+
+func (fp Ed25519Fp) NbLimbs() uint     { return 4 }
+func (fp Ed25519Fp) BitsPerLimb() uint { return 64 }
+func (fp Ed25519Fp) IsPrime() bool     { return true }
+func (fp Ed25519Fp) Modulus() *big.Int { return qEd25519 }
+
+// Ed25519Fr provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus 2^252+27742317777372353535851937790883648493
+// (0x1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed). This is
+// the order of the prime subgroup of the Ed25519 curve.
+type Ed25519Fr struct{}
+
+func (fp Ed25519Fr) NbLimbs() uint     { return 4 }
+func (fp Ed25519Fr) BitsPerLimb() uint { return 64 }
+func (fp Ed25519Fr) IsPrime() bool     { return true }
+func (fp Ed25519Fr) Modulus() *big.Int { return rEd25519 }
+
+// P256Fp provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus 2^256-2^224+2^192+2^96-1
+// (0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff). This is
+// the base field of the NIST P-256 curve (secp256r1).
+type P256Fp struct{}
+
+func (fp P256Fp) NbLimbs() uint     { return 4 }
+func (fp P256Fp) BitsPerLimb() uint { return 64 }
+func (fp P256Fp) IsPrime() bool     { return true }
+func (fp P256Fp) Modulus() *big.Int { return qP256 }
+
+// P256Fr provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus
+// 0xffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551. This is
+// the order of the NIST P-256 curve.
+type P256Fr struct{}
+
+func (fp P256Fr) NbLimbs() uint     { return 4 }
+func (fp P256Fr) BitsPerLimb() uint { return 64 }
+func (fp P256Fr) IsPrime() bool     { return true }
+func (fp P256Fr) Modulus() *big.Int { return rP256 }
+
+// STARKCurveFp provides type parametrization for emulated field on 4 limbs of
+// width 64bits for modulus 2^251+17*2^192+1
+// (0x800000000000011000000000000000000000000000000000000000000000001). This is
+// the base field of the Stark curve of StarkNet.
+type STARKCurveFp struct{}
+
+func (fp STARKCurveFp) NbLimbs() uint     { return 4 }
+func (fp STARKCurveFp) BitsPerLimb() uint { return 64 }
+func (fp STARKCurveFp) IsPrime() bool     { return true }
+func (fp STARKCurveFp) Modulus() *big.Int { return ecc.STARK_CURVE.BaseField() }
+
+// STARKCurveFr provides type parametrization for emulated field on 4 limbs of
+// width 64bits for modulus
+// 0x800000000000010ffffffffffffffffb781126dcae7b2321e66a241adc64d2f. This is the
+// order of the Stark curve.
+type STARKCurveFr struct{}
+
+func (fp STARKCurveFr) NbLimbs() uint     { return 4 }
+func (fp STARKCurveFr) BitsPerLimb() uint { return 64 }
+func (fp STARKCurveFr) IsPrime() bool     { return true }
+func (fp STARKCurveFr) Modulus() *big.Int { return ecc.STARK_CURVE.ScalarField() }
+
+// PallasFp provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus
+// 0x40000000000000000000000000000000224698fc094cf91b992d30ed00000001. This is
+// the base field of the Pallas curve, and the scalar field of the Vesta curve.
+type PallasFp struct{}
+
+func (fp PallasFp) NbLimbs() uint     { return 4 }
+func (fp PallasFp) BitsPerLimb() uint { return 64 }
+func (fp PallasFp) IsPrime() bool     { return true }
+func (fp PallasFp) Modulus() *big.Int { return qPallas }
+
+// PallasFr provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus
+// 0x40000000000000000000000000000000224698fc0994a8dd8c46eb2100000001. This is
+// the order of the Pallas curve, and the base field of the Vesta curve.
+type PallasFr struct{}
+
+func (fp PallasFr) NbLimbs() uint     { return 4 }
+func (fp PallasFr) BitsPerLimb() uint { return 64 }
+func (fp PallasFr) IsPrime() bool     { return true }
+func (fp PallasFr) Modulus() *big.Int { return qVesta }
+
+// VestaFp provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus
+// 0x40000000000000000000000000000000224698fc0994a8dd8c46eb2100000001. This is
+// the base field of the Vesta curve, the same as [PallasFr].
+type VestaFp = PallasFr
+
+// VestaFr provides type parametrization for emulated field on 4 limbs of width
+// 64bits for modulus
+// 0x40000000000000000000000000000000224698fc094cf91b992d30ed00000001. This is
+// the order of the Vesta curve, the same as [PallasFp].
+type VestaFr = PallasFp
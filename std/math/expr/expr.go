@@ -0,0 +1,229 @@
+// Package expr builds polynomial expressions of variables, as linear combinations minimized
+// before their constraints are emitted.
+//
+// Chaining the calls to the API creates an intermediate variable for each operation, and the
+// PLONK builder a constraint for each addition. An expression instead accumulates its terms
+// and folds the constants on the host, and emits the linear combination in a single call to
+// api.Add once it is needed, in which the builders merge the terms of the same variables:
+//
+//	// 3v + w - v + 2, emitted as 2v + w + 2
+//	res := expr.Poly(api).X(v).MulC(3).AddV(w).SubV(v).AddC(2).Var()
+//
+// The products of expressions are emitted as products of their linear combinations, one
+// constraint each with the R1CS builder.
+//
+// The methods modify the expression and return it, for chaining; Clone copies it.
+package expr
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// term is the variable v scaled by the constant c.
+type term struct {
+	v frontend.Variable
+	c big.Int
+}
+
+// Expr is the linear combination of variables Σ cᵢ·vᵢ + k, with constant coefficients
+// reduced modulo the field.
+type Expr struct {
+	api   frontend.API
+	terms []term
+	k     big.Int
+}
+
+// Poly returns the zero expression of the API.
+func Poly(api frontend.API) *Expr {
+	return &Expr{api: api}
+}
+
+// Clone returns a copy of the expression.
+func (e *Expr) Clone() *Expr {
+	res := &Expr{api: e.api, terms: make([]term, len(e.terms))}
+	for i := range e.terms {
+		res.terms[i].v = e.terms[i].v
+		res.terms[i].c.Set(&e.terms[i].c)
+	}
+	res.k.Set(&e.k)
+	return res
+}
+
+// X sets the expression to the variable v.
+func (e *Expr) X(v frontend.Variable) *Expr {
+	e.terms, e.k = e.terms[:0], big.Int{}
+	return e.AddV(v)
+}
+
+// C sets the expression to the constant c.
+func (e *Expr) C(c interface{}) *Expr {
+	e.terms, e.k = e.terms[:0], big.Int{}
+	return e.AddC(c)
+}
+
+// AddV adds the variable v to the expression.
+func (e *Expr) AddV(v frontend.Variable) *Expr {
+	return e.AddTerm(1, v)
+}
+
+// SubV subtracts the variable v from the expression.
+func (e *Expr) SubV(v frontend.Variable) *Expr {
+	return e.AddTerm(-1, v)
+}
+
+// AddTerm adds the variable v scaled by the constant c to the expression.
+func (e *Expr) AddTerm(c interface{}, v frontend.Variable) *Expr {
+	cc := e.constant(c)
+	if cc.Sign() == 0 {
+		return e
+	}
+	if k, ok := e.api.Compiler().ConstantValue(v); ok {
+		k.Mul(k, &cc)
+		e.k.Add(&e.k, k)
+		e.k.Mod(&e.k, e.api.Compiler().Field())
+		return e
+	}
+	e.terms = append(e.terms, term{v: v, c: cc})
+	return e
+}
+
+// AddC adds the constant c to the expression.
+func (e *Expr) AddC(c interface{}) *Expr {
+	cc := e.constant(c)
+	e.k.Add(&e.k, &cc)
+	e.k.Mod(&e.k, e.api.Compiler().Field())
+	return e
+}
+
+// SubC subtracts the constant c from the expression.
+func (e *Expr) SubC(c interface{}) *Expr {
+	cc := e.constant(c)
+	e.k.Sub(&e.k, &cc)
+	e.k.Mod(&e.k, e.api.Compiler().Field())
+	return e
+}
+
+// MulC multiplies the expression by the constant c.
+func (e *Expr) MulC(c interface{}) *Expr {
+	cc := e.constant(c)
+	modulus := e.api.Compiler().Field()
+	if cc.Sign() == 0 {
+		e.terms, e.k = e.terms[:0], big.Int{}
+		return e
+	}
+	for i := range e.terms {
+		e.terms[i].c.Mul(&e.terms[i].c, &cc).Mod(&e.terms[i].c, modulus)
+	}
+	e.k.Mul(&e.k, &cc).Mod(&e.k, modulus)
+	return e
+}
+
+// Neg negates the expression.
+func (e *Expr) Neg() *Expr {
+	return e.MulC(-1)
+}
+
+// AddE adds the expression o to the expression.
+func (e *Expr) AddE(o *Expr) *Expr {
+	for i := range o.terms {
+		e.AddTerm(&o.terms[i].c, o.terms[i].v)
+	}
+	return e.AddC(&o.k)
+}
+
+// SubE subtracts the expression o from the expression.
+func (e *Expr) SubE(o *Expr) *Expr {
+	return e.AddE(o.Clone().Neg())
+}
+
+// MulV multiplies the expression by the variable v. Unless either is constant, the linear
+// combination is emitted and multiplied by v.
+func (e *Expr) MulV(v frontend.Variable) *Expr {
+	if k, ok := e.api.Compiler().ConstantValue(v); ok {
+		return e.MulC(k)
+	}
+	if len(e.terms) == 0 {
+		k := e.k
+		e.k = big.Int{}
+		return e.AddTerm(&k, v)
+	}
+	x, c := e.scaled()
+	e.terms = append(e.terms[:0], term{v: e.api.Mul(x, v), c: c})
+	return e
+}
+
+// MulE multiplies the expression by the expression o. Unless either is constant, both linear
+// combinations are emitted and multiplied.
+func (e *Expr) MulE(o *Expr) *Expr {
+	if len(o.terms) == 0 {
+		return e.MulC(&o.k)
+	}
+	if len(e.terms) == 0 {
+		k := e.k
+		*e = *o.Clone()
+		return e.MulC(&k)
+	}
+	x, c := e.scaled()
+	y, d := o.Clone().scaled()
+	c.Mul(&c, &d).Mod(&c, e.api.Compiler().Field())
+	e.terms = append(e.terms[:0], term{v: e.api.Mul(x, y), c: c})
+	return e
+}
+
+// Square multiplies the expression by itself.
+func (e *Expr) Square() *Expr {
+	return e.MulE(e.Clone())
+}
+
+// Var emits the linear combination of the expression, and returns its variable. The
+// expression is then the variable, so that emitting it again is free.
+func (e *Expr) Var() frontend.Variable {
+	vars := make([]frontend.Variable, 0, len(e.terms)+1)
+	for i := range e.terms {
+		if e.terms[i].c.IsUint64() && e.terms[i].c.Uint64() == 1 {
+			vars = append(vars, e.terms[i].v)
+		} else {
+			vars = append(vars, e.api.Mul(e.terms[i].v, &e.terms[i].c))
+		}
+	}
+	if e.k.Sign() != 0 || len(vars) == 0 {
+		vars = append(vars, new(big.Int).Set(&e.k))
+	}
+
+	var res frontend.Variable
+	if len(vars) == 1 {
+		res = vars[0]
+	} else {
+		res = e.api.Add(vars[0], vars[1], vars[2:]...)
+	}
+	e.terms, e.k = e.terms[:0], big.Int{}
+	e.AddV(res)
+	return res
+}
+
+// AssertIsEqual asserts that the expression equals the variable v.
+func (e *Expr) AssertIsEqual(v frontend.Variable) {
+	e.api.AssertIsEqual(e.Var(), v)
+}
+
+// scaled returns the expression as a variable scaled by a constant: its term if it is a
+// single one without constant, or else its emitted variable and 1.
+func (e *Expr) scaled() (frontend.Variable, big.Int) {
+	var c big.Int
+	if len(e.terms) == 1 && e.k.Sign() == 0 {
+		c.Set(&e.terms[0].c)
+		return e.terms[0].v, c
+	}
+	c.SetUint64(1)
+	return e.Var(), c
+}
+
+// constant returns c reduced modulo the field.
+func (e *Expr) constant(c interface{}) big.Int {
+	res := utils.FromInterface(c)
+	res.Mod(&res, e.api.Compiler().Field())
+	return res
+}
@@ -0,0 +1,72 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/math/expr"
+	"github.com/consensys/gnark/test"
+)
+
+type exprCircuit struct {
+	V, W     frontend.Variable
+	Linear   frontend.Variable `gnark:",public"`
+	Product  frontend.Variable `gnark:",public"`
+	Constant frontend.Variable `gnark:",public"`
+}
+
+func (c *exprCircuit) Define(api frontend.API) error {
+	// 3v + w - v + 2 = 2v + w + 2
+	linear := expr.Poly(api).X(c.V).MulC(3).AddV(c.W).SubV(c.V).AddC(2)
+	linear.Clone().AssertIsEqual(c.Linear)
+
+	// (2v + w + 2)·w - (v - 1)² + 5
+	square := expr.Poly(api).X(c.V).SubC(1).Square()
+	linear.MulV(c.W).SubE(square).AddC(5).AssertIsEqual(c.Product)
+
+	// the constants are folded: (v + 4 - v)·3·1 = 12
+	expr.Poly(api).AddV(c.V).AddC(4).SubV(c.V).MulC(3).MulV(1).AssertIsEqual(c.Constant)
+	return nil
+}
+
+func TestExpr(t *testing.T) {
+	assert := test.NewAssert(t)
+	// v = 5, w = 7: 2v + w + 2 = 19, 19·7 - 16 + 5 = 122
+	assert.ProverSucceeded(&exprCircuit{}, &exprCircuit{V: 5, W: 7, Linear: 19, Product: 122, Constant: 12})
+	assert.ProverFailed(&exprCircuit{}, &exprCircuit{V: 5, W: 7, Linear: 20, Product: 122, Constant: 12})
+}
+
+type chainedCircuit struct {
+	V, W, Res frontend.Variable
+}
+
+func (c *chainedCircuit) Define(api frontend.API) error {
+	res := api.Add(api.Mul(c.V, 3), c.W)
+	res = api.Sub(res, c.V)
+	res = api.Add(res, 2)
+	api.AssertIsEqual(res, c.Res)
+	return nil
+}
+
+type linearCircuit chainedCircuit
+
+func (c *linearCircuit) Define(api frontend.API) error {
+	expr.Poly(api).X(c.V).MulC(3).AddV(c.W).SubV(c.V).AddC(2).AssertIsEqual(c.Res)
+	return nil
+}
+
+func TestExprConstraints(t *testing.T) {
+	chained, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &chainedCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	linear, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &linearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linear.GetNbConstraints() >= chained.GetNbConstraints() {
+		t.Fatalf("expected fewer constraints than the chained calls, got %d, chained %d", linear.GetNbConstraints(), chained.GetNbConstraints())
+	}
+}
@@ -1,9 +1,9 @@
 // Package keccakf implements the KeccakF-1600 permutation function.
 //
 // This package exposes only the permutation primitive. For SHA3, SHAKE3 etc.
-// functions it is necessary to apply the sponge construction. The constructions
-// will be implemented in future in [github.com/consensys/gnark/std/hash/sha3]
-// package.
+// functions it is necessary to apply the sponge construction. The Keccak-256
+// hash of Ethereum is implemented, with a cheaper permutation on the bits of the
+// state, in the [github.com/consensys/gnark/std/hash/keccak256] package.
 //
 // The cost for a single application of permutation is:
 //   - 193650 constraints in Groth16
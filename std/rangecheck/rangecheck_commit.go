@@ -92,6 +92,13 @@ func (c *commitChecker) commit(api frontend.API) error {
 			composed = api.Add(composed, api.Mul(limbs[j], new(big.Int).Exp(base, big.NewInt(int64(j)), nil)))
 		}
 		api.AssertIsEqual(composed, c.collected[i].v)
+		// the most significant limb is only checked to be in the table, i.e. less than
+		// 2^baseLength. If it should be smaller, we also look up the limb shifted to the top
+		// of the table range, otherwise values up to 2^(nbLimbs*baseLength) would pass.
+		if rem := c.collected[i].bits % baseLength; rem != 0 {
+			shift := new(big.Int).Lsh(big.NewInt(1), uint(baseLength-rem))
+			decomposed = append(decomposed, api.Mul(limbs[len(limbs)-1], shift))
+		}
 	}
 	nbTable := 1 << baseLength
 	return logderivarg.Build(api, logderivarg.AsTable(c.buildTable(nbTable)), logderivarg.AsTable(decomposed))
@@ -156,6 +163,9 @@ func nbR1CSConstraints(baseLength int, collected []checkedVariable) int {
 	nbDecomposed := 0
 	for i := range collected {
 		nbDecomposed += int(decompSize(collected[i].bits, baseLength))
+		if collected[i].bits%baseLength != 0 {
+			nbDecomposed++ // shifted most significant limb
+		}
 	}
 	eqs := len(collected)       // correctness of decomposition
 	nbRight := nbDecomposed     // inverse per decomposed
@@ -167,6 +177,9 @@ func nbPLONKConstraints(baseLength int, collected []checkedVariable) int {
 	nbDecomposed := 0
 	for i := range collected {
 		nbDecomposed += int(decompSize(collected[i].bits, baseLength))
+		if collected[i].bits%baseLength != 0 {
+			nbDecomposed++ // shifted most significant limb
+		}
 	}
 	eqs := nbDecomposed               // check correctness of every decomposition. this is nbDecomp adds + eq cost per collected
 	nbRight := 3 * nbDecomposed       // denominator sub, inv and large sum per table entry
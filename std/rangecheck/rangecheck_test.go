@@ -1,4 +1,4 @@
-package rangecheck
+package rangecheck_test
 
 import (
 	"crypto/rand"
@@ -8,6 +8,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/rangecheck"
 	"github.com/consensys/gnark/test"
 )
 
@@ -17,7 +18,7 @@ type CheckCircuit struct {
 }
 
 func (c *CheckCircuit) Define(api frontend.API) error {
-	r := newCommitRangechecker(api)
+	r := rangecheck.New(api)
 	for i := range c.Vals {
 		r.Check(c.Vals[i], c.bits)
 	}
@@ -41,6 +42,31 @@ func TestCheck(t *testing.T) {
 	circuit := CheckCircuit{Vals: make([]frontend.Variable, len(vals)), bits: bits}
 	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
 	assert.NoError(err)
-	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit, frontend.WithCompressThreshold(100))
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit, frontend.WithCompressThreshold(100))
 	assert.NoError(err)
+	// batched checks cost less than an eighth of the binary decomposition
+	assert.LessOrEqual(ccs.GetNbConstraints(), nbVals*bits/8)
+}
+
+func TestCheckOutOfRange(t *testing.T) {
+	assert := test.NewAssert(t)
+	// the number of bits isn't a multiple of the limb size, so that the most significant
+	// limb is smaller than the others
+	for _, bits := range []int{13, 37, 61} {
+		nbVals := 1000
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		vals := make([]frontend.Variable, nbVals)
+		for i := range vals {
+			vals[i] = i
+		}
+		circuit := CheckCircuit{Vals: make([]frontend.Variable, len(vals)), bits: bits}
+
+		vals[nbVals-1] = new(big.Int).Sub(bound, big.NewInt(1))
+		witness := CheckCircuit{Vals: vals, bits: bits}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), bits)
+
+		vals[nbVals-1] = bound
+		witness = CheckCircuit{Vals: vals, bits: bits}
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), bits)
+	}
 }
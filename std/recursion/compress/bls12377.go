@@ -0,0 +1,75 @@
+package compress
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bls12377 "github.com/consensys/gnark/backend/groth16/bls12-377"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	std_groth16 "github.com/consensys/gnark/std/groth16_bls12377"
+)
+
+// bls12377Layer verifies BLS12-377 proofs in BW6-761 circuits.
+type bls12377Layer struct{}
+
+// BLS12377ToBW6761 returns the layer verifying the BLS12-377 Groth16 proofs, without
+// commitment, in a BW6-761 circuit, with the pairing computed natively.
+func BLS12377ToBW6761() Layer {
+	return bls12377Layer{}
+}
+
+// bls12377Circuit verifies a proof of the constant verifying key InnerVk.
+type bls12377Circuit struct {
+	Proof        std_groth16.Proof
+	PublicInputs []frontend.Variable `gnark:",public"`
+
+	InnerVk groth16.VerifyingKey `gnark:"-"`
+}
+
+func (c *bls12377Circuit) Define(api frontend.API) error {
+	var vk std_groth16.VerifyingKey
+	vk.Assign(c.InnerVk)
+	std_groth16.Verify(api, vk, c.Proof, c.PublicInputs)
+	return nil
+}
+
+func (bls12377Layer) InnerCurve() ecc.ID { return ecc.BLS12_377 }
+func (bls12377Layer) Curve() ecc.ID      { return ecc.BW6_761 }
+
+func (bls12377Layer) Circuit(vk groth16.VerifyingKey, nbPublic int) (frontend.Circuit, error) {
+	_vk, ok := vk.(*groth16_bls12377.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("expected *groth16_bls12377.VerifyingKey, got %s", reflect.TypeOf(vk).String())
+	}
+	if len(_vk.G1.K) != nbPublic+1 {
+		return nil, fmt.Errorf("the verifying key has %d public inputs, expected %d", len(_vk.G1.K)-1, nbPublic)
+	}
+	if _vk.CommitmentInfo.Is() {
+		return nil, fmt.Errorf("proofs with commitments are not supported")
+	}
+	return &bls12377Circuit{PublicInputs: make([]frontend.Variable, nbPublic), InnerVk: vk}, nil
+}
+
+func (bls12377Layer) Assignment(vk groth16.VerifyingKey, proof groth16.Proof, publicWitness witness.Witness) (frontend.Circuit, error) {
+	_proof, ok := proof.(*groth16_bls12377.Proof)
+	if !ok {
+		return nil, fmt.Errorf("expected *groth16_bls12377.Proof, got %s", reflect.TypeOf(proof).String())
+	}
+	vect, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("expected fr.Vector, got %s", reflect.TypeOf(publicWitness.Vector()).String())
+	}
+	assignment := &bls12377Circuit{PublicInputs: make([]frontend.Variable, len(vect))}
+	assignment.Proof.Ar.Assign(&_proof.Ar)
+	assignment.Proof.Krs.Assign(&_proof.Krs)
+	assignment.Proof.Bs.Assign(&_proof.Bs)
+	for i := range vect {
+		assignment.PublicInputs[i] = vect[i].BigInt(new(big.Int))
+	}
+	return assignment, nil
+}
@@ -0,0 +1,143 @@
+package compress
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/backend/groth16/bw6-761"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bw6761"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// bw6761Layer verifies BW6-761 proofs in BN254 circuits.
+type bw6761Layer struct{}
+
+// BW6761ToBN254 returns the layer verifying the BW6-761 Groth16 proofs, without commitment,
+// in a BN254 circuit, with the BW6-761 pairing emulated. The public inputs of the verified
+// proofs are the public inputs of the circuit, so they must be smaller than the BN254 scalar
+// field modulus, as the ones of the proofs of BLS12377ToBW6761 are.
+func BW6761ToBN254() Layer {
+	return bw6761Layer{}
+}
+
+// bw6761Proof is a BW6-761 Groth16 proof without commitment.
+type bw6761Proof struct {
+	Ar, Krs sw_bw6761.G1Affine
+	Bs      sw_bw6761.G2Affine
+}
+
+// bw6761Circuit verifies a proof of the constant verifying key InnerVk.
+type bw6761Circuit struct {
+	Proof        bw6761Proof
+	PublicInputs []frontend.Variable `gnark:",public"`
+
+	InnerVk groth16.VerifyingKey `gnark:"-"`
+}
+
+func (c *bw6761Circuit) Define(api frontend.API) error {
+	vk, ok := c.InnerVk.(*groth16_bw6761.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("expected *groth16_bw6761.VerifyingKey, got %s", reflect.TypeOf(c.InnerVk).String())
+	}
+	curve, err := sw_emulated.New[emulated.BW6761Fp, emulated.BW6761Fr](api, sw_emulated.GetBW6761Params())
+	if err != nil {
+		return fmt.Errorf("new curve: %w", err)
+	}
+	scalarApi, err := emulated.NewField[emulated.BW6761Fr](api)
+	if err != nil {
+		return fmt.Errorf("new scalar field: %w", err)
+	}
+	pairing, err := sw_bw6761.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("new pairing: %w", err)
+	}
+
+	// compute kSum = Σx.[Kvk(t)]1
+	// kSum = Kvk[0] (assumes ONE_WIRE is at position 0)
+	k0 := sw_bw6761.NewG1Affine(vk.G1.K[0])
+	kSum := &k0
+	nbBits := emulated.BW6761Fr{}.Modulus().BitLen()
+	for i := range c.PublicInputs {
+		ki := sw_bw6761.NewG1Affine(vk.G1.K[i+1])
+		// the bits are padded to the ones of the emulated scalar field, so that
+		// the scalar has all its limbs as ScalarMul expects
+		bits := api.ToBinary(c.PublicInputs[i])
+		for len(bits) < nbBits {
+			bits = append(bits, 0)
+		}
+		xi := scalarApi.FromBits(bits...)
+		kSum = curve.AddUnified(kSum, curve.ScalarMul(&ki, xi))
+	}
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2) * e(Krs,-[δ]2) * e(Ar,Bs)
+	var gammaNeg, deltaNeg bw6761.G2Affine
+	gammaNeg.Neg(&vk.G2.Gamma)
+	deltaNeg.Neg(&vk.G2.Delta)
+	_gammaNeg, _deltaNeg := sw_bw6761.NewG2Affine(gammaNeg), sw_bw6761.NewG2Affine(deltaNeg)
+	ml, err := pairing.MillerLoop(
+		[]*sw_bw6761.G1Affine{kSum, &c.Proof.Krs, &c.Proof.Ar},
+		[]*sw_bw6761.G2Affine{&_gammaNeg, &_deltaNeg, &c.Proof.Bs},
+	)
+	if err != nil {
+		return fmt.Errorf("miller loop: %w", err)
+	}
+	pair := pairing.FinalExponentiation(ml)
+
+	// e(α, β) must be equal to the pairing
+	e, err := bw6761.Pair([]bw6761.G1Affine{vk.G1.Alpha}, []bw6761.G2Affine{vk.G2.Beta})
+	if err != nil {
+		return fmt.Errorf("pair: %w", err)
+	}
+	_e := sw_bw6761.NewGTEl(e)
+	pairing.AssertIsEqual(pair, &_e)
+	return nil
+}
+
+func (bw6761Layer) InnerCurve() ecc.ID { return ecc.BW6_761 }
+func (bw6761Layer) Curve() ecc.ID      { return ecc.BN254 }
+
+func (bw6761Layer) Circuit(vk groth16.VerifyingKey, nbPublic int) (frontend.Circuit, error) {
+	_vk, ok := vk.(*groth16_bw6761.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("expected *groth16_bw6761.VerifyingKey, got %s", reflect.TypeOf(vk).String())
+	}
+	if len(_vk.G1.K) != nbPublic+1 {
+		return nil, fmt.Errorf("the verifying key has %d public inputs, expected %d", len(_vk.G1.K)-1, nbPublic)
+	}
+	if _vk.CommitmentInfo.Is() {
+		return nil, fmt.Errorf("proofs with commitments are not supported")
+	}
+	return &bw6761Circuit{PublicInputs: make([]frontend.Variable, nbPublic), InnerVk: vk}, nil
+}
+
+func (bw6761Layer) Assignment(vk groth16.VerifyingKey, proof groth16.Proof, publicWitness witness.Witness) (frontend.Circuit, error) {
+	_proof, ok := proof.(*groth16_bw6761.Proof)
+	if !ok {
+		return nil, fmt.Errorf("expected *groth16_bw6761.Proof, got %s", reflect.TypeOf(proof).String())
+	}
+	vect, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("expected fr.Vector, got %s", reflect.TypeOf(publicWitness.Vector()).String())
+	}
+	modulus := ecc.BN254.ScalarField()
+	assignment := &bw6761Circuit{PublicInputs: make([]frontend.Variable, len(vect))}
+	assignment.Proof.Ar = sw_bw6761.NewG1Affine(_proof.Ar)
+	assignment.Proof.Krs = sw_bw6761.NewG1Affine(_proof.Krs)
+	assignment.Proof.Bs = sw_bw6761.NewG2Affine(_proof.Bs)
+	for i := range vect {
+		x := vect[i].BigInt(new(big.Int))
+		if x.Cmp(modulus) >= 0 {
+			return nil, fmt.Errorf("the public input %d doesn't fit in the BN254 scalar field", i)
+		}
+		assignment.PublicInputs[i] = x
+	}
+	return assignment, nil
+}
@@ -0,0 +1,204 @@
+// Package compress chains the recursive Groth16 provers, so that a proof on a curve is
+// wrapped into proofs on the next curves: a BLS12-377 proof is cheaply verified in a BW6-761
+// circuit, as the BLS12-377 arithmetic is native there.
+//
+// Each step is a Layer: a circuit verifying the proofs of the previous one, with their public
+// inputs as its own public inputs, so that the last proof attests the statement of the first
+// one. The verifying key of the previous step is a constant of the circuit. A Pipeline compiles
+// the circuits of its layers and runs their setups once, then compresses the proofs by proving
+// the layers one after the other, on the accelerator of WithAccelerator for the curves with a
+// GPU prover.
+//
+// The package provides the layers BLS12-377 → BW6-761, see BLS12377ToBW6761, and BW6-761 →
+// BN254, see BW6761ToBN254, which makes the proofs verifiable on Ethereum. The latter emulates
+// the BW6-761 pairing (see [github.com/consensys/gnark/std/algebra/emulated/sw_bw6761]), so
+// its circuit is much larger than the one of the former. The further layers of a Pipeline are
+// the ones of the caller.
+package compress
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/logger"
+)
+
+// Layer is a step of a Pipeline: a circuit over Curve verifying the Groth16 proofs over
+// InnerCurve.
+type Layer interface {
+	// InnerCurve returns the curve of the verified proofs, and Curve the one of the circuit.
+	InnerCurve() ecc.ID
+	Curve() ecc.ID
+
+	// Circuit returns the circuit to compile, verifying the proofs of vk with nbPublic public
+	// inputs.
+	Circuit(vk groth16.VerifyingKey, nbPublic int) (frontend.Circuit, error)
+
+	// Assignment returns the assignment of the circuit for the proof of vk and its public
+	// witness.
+	Assignment(vk groth16.VerifyingKey, proof groth16.Proof, publicWitness witness.Witness) (frontend.Circuit, error)
+}
+
+// Option configures a Pipeline.
+type Option func(*config) error
+
+type config struct {
+	accelerator string
+	proverOpts  []backend.ProverOption
+}
+
+// WithAccelerator proves the layers over the curves with a GPU prover (BN254 and BLS12-377)
+// on the named accelerator provider of the backend/accel registry, for instance "icicle". The
+// other layers are proved on the CPU.
+func WithAccelerator(name string) Option {
+	return func(c *config) error {
+		if name == "" {
+			return errors.New("empty accelerator name")
+		}
+		c.accelerator = name
+		return nil
+	}
+}
+
+// WithProverOptions passes the options to the provers of all the layers.
+func WithProverOptions(opts ...backend.ProverOption) Option {
+	return func(c *config) error {
+		c.proverOpts = append(c.proverOpts, opts...)
+		return nil
+	}
+}
+
+// step is a compiled layer, with its keys.
+type step struct {
+	layer Layer
+	ccs   constraint.ConstraintSystem
+	pk    groth16.ProvingKey
+	vk    groth16.VerifyingKey
+}
+
+// Pipeline compresses the proofs of a verifying key through its layers.
+type Pipeline struct {
+	innerVk groth16.VerifyingKey
+	steps   []step
+	cfg     config
+}
+
+// New compiles the circuits of the layers and runs their setups, to compress the proofs of
+// innerVk with nbPublic public inputs. The inner curve of each layer must be the curve of the
+// previous one, and the one of innerVk for the first layer.
+//
+// The setups are the unsafe setups of groth16.Setup: the keys of production circuits come from
+// a ceremony, see SetKeys.
+func New(innerVk groth16.VerifyingKey, nbPublic int, layers []Layer, opts ...Option) (*Pipeline, error) {
+	if len(layers) == 0 {
+		return nil, errors.New("no layers")
+	}
+	p := &Pipeline{innerVk: innerVk, steps: make([]step, len(layers))}
+	for _, opt := range opts {
+		if err := opt(&p.cfg); err != nil {
+			return nil, fmt.Errorf("option: %w", err)
+		}
+	}
+	curve := innerVk.CurveID()
+	for i, layer := range layers {
+		if layer.InnerCurve() != curve {
+			return nil, fmt.Errorf("layer %d verifies %s proofs, got %s proofs", i, layer.InnerCurve(), curve)
+		}
+		curve = layer.Curve()
+	}
+	log := logger.Logger()
+
+	vk := innerVk
+	for i, layer := range layers {
+		circuit, err := layer.Circuit(vk, nbPublic)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d circuit: %w", i, err)
+		}
+		ccs, err := frontend.Compile(layer.Curve().ScalarField(), r1cs.NewBuilder, circuit)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d compile: %w", i, err)
+		}
+		pk, lvk, err := groth16.Setup(ccs)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d setup: %w", i, err)
+		}
+		p.steps[i] = step{layer: layer, ccs: ccs}
+		if err := p.SetKeys(i, pk, lvk); err != nil {
+			return nil, err
+		}
+		log.Debug().Int("layer", i).Str("curve", layer.Curve().String()).Int("nbConstraints", ccs.GetNbConstraints()).Msg("compression layer set up")
+		vk = lvk
+	}
+	return p, nil
+}
+
+// SetKeys replaces the keys of the i-th layer, for instance with the keys of a ceremony. The
+// proving key is copied to the accelerator of WithAccelerator if its curve has a GPU prover.
+func (p *Pipeline) SetKeys(i int, pk groth16.ProvingKey, vk groth16.VerifyingKey) error {
+	if i < 0 || i >= len(p.steps) {
+		return fmt.Errorf("no layer %d", i)
+	}
+	if dpk, ok := pk.(groth16.DeviceProvingKey); ok && p.cfg.accelerator != "" {
+		if err := dpk.SetAccelerator(p.cfg.accelerator); err != nil {
+			return fmt.Errorf("layer %d accelerator: %w", i, err)
+		}
+	}
+	p.steps[i].pk, p.steps[i].vk = pk, vk
+	return nil
+}
+
+// ConstraintSystem returns the constraint system of the i-th layer.
+func (p *Pipeline) ConstraintSystem(i int) constraint.ConstraintSystem {
+	return p.steps[i].ccs
+}
+
+// VerifyingKey returns the verifying key of the last layer, which verifies the compressed
+// proofs.
+func (p *Pipeline) VerifyingKey() groth16.VerifyingKey {
+	return p.steps[len(p.steps)-1].vk
+}
+
+// Compress returns the proof of the last layer for the proof of the inner verifying key and
+// its public witness, and the public witness of the compressed proof. The proof of each layer
+// is verified before proving the next one, so that an invalid proof fails early.
+func (p *Pipeline) Compress(proof groth16.Proof, publicWitness witness.Witness) (groth16.Proof, witness.Witness, error) {
+	if err := groth16.Verify(proof, p.innerVk, publicWitness); err != nil {
+		return nil, nil, fmt.Errorf("inner proof: %w", err)
+	}
+	proverOpts := p.cfg.proverOpts
+	if p.cfg.accelerator != "" {
+		proverOpts = append(proverOpts[:len(proverOpts):len(proverOpts)], backend.WithAccelerator(p.cfg.accelerator))
+	}
+	log := logger.Logger()
+
+	vk := p.innerVk
+	for i, s := range p.steps {
+		assignment, err := s.layer.Assignment(vk, proof, publicWitness)
+		if err != nil {
+			return nil, nil, fmt.Errorf("layer %d assignment: %w", i, err)
+		}
+		w, err := frontend.NewWitness(assignment, s.layer.Curve().ScalarField())
+		if err != nil {
+			return nil, nil, fmt.Errorf("layer %d witness: %w", i, err)
+		}
+		if proof, err = groth16.Prove(s.ccs, s.pk, w, proverOpts...); err != nil {
+			return nil, nil, fmt.Errorf("layer %d prove: %w", i, err)
+		}
+		if publicWitness, err = w.Public(); err != nil {
+			return nil, nil, fmt.Errorf("layer %d public witness: %w", i, err)
+		}
+		if err := groth16.Verify(proof, s.vk, publicWitness); err != nil {
+			return nil, nil, fmt.Errorf("layer %d verify: %w", i, err)
+		}
+		log.Debug().Int("layer", i).Str("curve", s.layer.Curve().String()).Msg("compression layer proved")
+		vk = s.vk
+	}
+	return proof, publicWitness, nil
+}
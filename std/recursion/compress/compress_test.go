@@ -0,0 +1,111 @@
+package compress
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+type innerCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *innerCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func TestCompressBLS12377(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the BW6-761 setup in short mode")
+	}
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BLS12_377.ScalarField(), r1cs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&innerCircuit{X: 3, Y: 27}, ecc.BLS12_377.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	p, err := New(vk, 1, []Layer{BLS12377ToBW6761()})
+	assert.NoError(err)
+	compressed, compressedWitness, err := p.Compress(proof, publicWitness)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(compressed, p.VerifyingKey(), compressedWitness))
+
+	// the public inputs of the inner proof are the ones of the compressed proof
+	compressedPublic, ok := compressedWitness.Vector().(fr_bw6761.Vector)
+	assert.True(ok)
+	assert.Equal(1, len(compressedPublic))
+	assert.Equal(uint64(27), compressedPublic[0].Uint64())
+
+	// wrong inner public input
+	wrongWitness, err := frontend.NewWitness(&innerCircuit{Y: 28}, ecc.BLS12_377.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	_, _, err = p.Compress(proof, wrongWitness)
+	assert.Error(err)
+
+	// the layers must be chained
+	_, err = New(vk, 1, []Layer{BLS12377ToBW6761(), BLS12377ToBW6761()})
+	assert.Error(err)
+	_, err = New(vk, 2, []Layer{BLS12377ToBW6761()})
+	assert.Error(err)
+	_, err = New(vk, 1, nil)
+	assert.Error(err)
+}
+
+func TestCompressBW6761(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the emulated BW6-761 pairing in short mode")
+	}
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&innerCircuit{X: 3, Y: 27}, ecc.BW6_761.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	// the setup of the BN254 circuit is out of reach of a test, it is only solved
+	layer := BW6761ToBN254()
+	circuit, err := layer.Circuit(vk, 1)
+	assert.NoError(err)
+	assignment, err := layer.Assignment(vk, proof, publicWitness)
+	assert.NoError(err)
+	assert.NoError(test.IsSolved(circuit, assignment, layer.Curve().ScalarField()))
+
+	// wrong inner public input
+	wrongWitness, err := frontend.NewWitness(&innerCircuit{Y: 28}, ecc.BW6_761.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	assignment, err = layer.Assignment(vk, proof, wrongWitness)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, assignment, layer.Curve().ScalarField()))
+
+	// the public inputs must fit in the BN254 scalar field
+	largeWitness, err := frontend.NewWitness(&innerCircuit{Y: ecc.BN254.ScalarField()}, ecc.BW6_761.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	_, err = layer.Assignment(vk, proof, largeWitness)
+	assert.Error(err)
+
+	// the layers must be chained
+	_, err = New(vk, 1, []Layer{BLS12377ToBW6761()})
+	assert.Error(err)
+	_, err = layer.Circuit(vk, 2)
+	assert.Error(err)
+}
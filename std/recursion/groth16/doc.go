@@ -0,0 +1,15 @@
+// Package groth16 provides a ZKP-circuit function to verify BN254 Groth16 proofs, as
+// produced by backend/groth16, inside a circuit.
+//
+// The BN254 arithmetic is emulated (see [github.com/consensys/gnark/std/math/emulated]),
+// so the verifier can be used in a circuit defined over any scalar field, for example
+// BW6-761 or BN254 itself.
+//
+// Contrary to [github.com/consensys/gnark/std/groth16_bls12377], the verifier handles
+// the Pedersen commitment extension of the proof: the proof of knowledge of the
+// commitment opening is checked and the commitment wire, hashed in circuit with SHA-256 (see
+// [github.com/consensys/gnark/std/hash/sha2]), is added to the public input.
+//
+// The verifying key of the inner circuit is a constant of the outer circuit (see
+// VerifyingKey): an outer circuit verifies the proofs of a single inner circuit and setup.
+package groth16
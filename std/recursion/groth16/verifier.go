@@ -0,0 +1,231 @@
+package groth16
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion/internal/hashtofield"
+)
+
+// Proof represents a BN254 Groth16 proof, including the Pedersen commitment to the
+// private committed wires and its proof of knowledge.
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type Proof struct {
+	Ar, Krs                   sw_bn254.G1Affine
+	Bs                        sw_bn254.G2Affine
+	Commitment, CommitmentPok sw_bn254.G1Affine
+}
+
+// VerifyingKey represents a BN254 Groth16 verifying key
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+//
+// The verifying key is a constant of the outer circuit, not part of its witness: it is
+// assigned (see Assign) to the circuit before compiling it, and the outer circuit only
+// verifies the proofs of the inner circuit it was compiled for. Would the key be a witness,
+// the prover could verify its proof against a key of its choice, for instance one of a setup
+// whose toxic waste it knows.
+type VerifyingKey struct {
+	// e(α, β)
+	E sw_bn254.GTEl `gnark:"-"`
+
+	// -[γ]2, -[δ]2
+	G2 struct {
+		GammaNeg, DeltaNeg sw_bn254.G2Affine
+	} `gnark:"-"`
+
+	// [Kvk]1
+	G1 struct {
+		K []sw_bn254.G1Affine // The indexes correspond to the public wires, then the commitment wire if any
+	} `gnark:"-"`
+
+	// Pedersen commitment verifying key
+	CommitmentKey struct {
+		G, GRootSigmaNeg sw_bn254.G2Affine
+	} `gnark:"-"`
+
+	// CommitmentInfo describes which wires of the inner circuit are committed to.
+	CommitmentInfo constraint.Commitment `gnark:"-"`
+}
+
+// Verify implements the verification function of Groth16 for proofs with a commitment.
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+// publicInputs do NOT contain the ONE_WIRE nor the commitment wire.
+//
+// If the inner circuit has a commitment, Verify checks the proof of knowledge of the
+// commitment opening and adds the commitment wire to the public input. The commitment wire is
+// the hash to field of the commitment and of the public committed inputs, computed in circuit
+// with SHA-256 as backend/groth16 does. Inner circuits with several commitments are not
+// supported.
+//
+// This function doesn't check that the proof points are in the correct subgroups.
+func Verify(api frontend.API, vk VerifyingKey, proof Proof, publicInputs []emulated.Element[emulated.BN254Fr]) error {
+	if len(vk.G1.K) == 0 {
+		return errors.New("inner verifying key needs at least one point; VerifyingKey.G1 must be initialized before compiling circuit")
+	}
+	nbPublic := len(vk.G1.K) - 1
+	if vk.CommitmentInfo.Is() {
+		nbPublic--
+	}
+	if len(publicInputs) != nbPublic {
+		return fmt.Errorf("invalid number of public inputs, got %d, expected %d", len(publicInputs), nbPublic)
+	}
+
+	curve, err := sw_emulated.New[emulated.BN254Fp, emulated.BN254Fr](api, sw_emulated.GetBN254Params())
+	if err != nil {
+		return fmt.Errorf("new curve: %w", err)
+	}
+	pairing, err := sw_bn254.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("new pairing: %w", err)
+	}
+
+	// compute kSum = Σx.[Kvk(t)]1
+	// kSum = Kvk[0] (assumes ONE_WIRE is at position 0)
+	kSum := &vk.G1.K[0]
+	for i := range publicInputs {
+		ki := curve.ScalarMul(&vk.G1.K[i+1], &publicInputs[i])
+		kSum = curve.AddUnified(kSum, ki)
+	}
+
+	if vk.CommitmentInfo.Is() {
+		// e(Commitment, G) ⋅ e(CommitmentPok, GRootSigmaNeg) == 1
+		if err := pairing.PairingCheck(
+			[]*sw_bn254.G1Affine{&proof.Commitment, &proof.CommitmentPok},
+			[]*sw_bn254.G2Affine{&vk.CommitmentKey.G, &vk.CommitmentKey.GRootSigmaNeg},
+		); err != nil {
+			return fmt.Errorf("commitment proof of knowledge: %w", err)
+		}
+
+		commitmentWire, err := computeCommitmentWire(api, &vk.CommitmentInfo, &proof.Commitment, publicInputs)
+		if err != nil {
+			return err
+		}
+		ki := curve.ScalarMul(&vk.G1.K[len(vk.G1.K)-1], commitmentWire)
+		kSum = curve.AddUnified(kSum, ki)
+		kSum = curve.AddUnified(kSum, &proof.Commitment)
+	}
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2) * e(Krs,-[δ]2) * e(Ar,Bs)
+	ml, err := pairing.MillerLoop(
+		[]*sw_bn254.G1Affine{kSum, &proof.Krs, &proof.Ar},
+		[]*sw_bn254.G2Affine{&vk.G2.GammaNeg, &vk.G2.DeltaNeg, &proof.Bs},
+	)
+	if err != nil {
+		return fmt.Errorf("miller loop: %w", err)
+	}
+	res := pairing.FinalExponentiation(ml)
+
+	// vk.E must be equal to the pairing
+	pairing.AssertIsEqual(res, &vk.E)
+	return nil
+}
+
+// computeCommitmentWire returns the value of the commitment wire of the inner circuit, the hash
+// to field of the commitment and the public committed inputs as backend/groth16 computes it.
+func computeCommitmentWire(api frontend.API, info *constraint.Commitment, commitment *sw_bn254.G1Affine, publicInputs []emulated.Element[emulated.BN254Fr]) (*emulated.Element[emulated.BN254Fr], error) {
+	fpField, err := emulated.NewField[emulated.BN254Fp](api)
+	if err != nil {
+		return nil, fmt.Errorf("new base field: %w", err)
+	}
+	frField, err := emulated.NewField[emulated.BN254Fr](api)
+	if err != nil {
+		return nil, fmt.Errorf("new scalar field: %w", err)
+	}
+
+	// the uncompressed commitment, as Marshal returns it, then the public committed inputs
+	msg := hashtofield.ElementBytes(api, fpField, &commitment.X)
+	msg = append(msg, hashtofield.ElementBytes(api, fpField, &commitment.Y)...)
+	for i := 0; i < info.NbPublicCommitted(); i++ {
+		// committed wire IDs include the ONE_WIRE
+		msg = append(msg, hashtofield.ElementBytes(api, frField, &publicInputs[info.Committed[i]-1])...)
+	}
+	return hashtofield.HashToField(api, frField, msg, constraint.CommitmentDst), nil
+}
+
+// Assign values to the "in-circuit" Proof from a "out-of-circuit" Proof. Proofs with more
+// than one commitment are not supported.
+func (proof *Proof) Assign(_oproof groth16.Proof) error {
+	oproof, ok := _oproof.(*groth16_bn254.Proof)
+	if !ok {
+		return fmt.Errorf("expected *groth16_bn254.Proof, got %s", reflect.TypeOf(_oproof))
+	}
+	var commitment, commitmentPok bn254.G1Affine
+	switch len(oproof.Commitments) {
+	case 0:
+	case 1:
+		commitment, commitmentPok = oproof.Commitments[0], oproof.CommitmentPoks[0]
+	default:
+		return fmt.Errorf("%d commitments, only proofs with at most one commitment are supported", len(oproof.Commitments))
+	}
+	proof.Ar = sw_bn254.NewG1Affine(oproof.Ar)
+	proof.Krs = sw_bn254.NewG1Affine(oproof.Krs)
+	proof.Bs = sw_bn254.NewG2Affine(oproof.Bs)
+	proof.Commitment = sw_bn254.NewG1Affine(commitment)
+	proof.CommitmentPok = sw_bn254.NewG1Affine(commitmentPok)
+	return nil
+}
+
+// Assign values to the "in-circuit" VerifyingKey from a "out-of-circuit" VerifyingKey. As the
+// verifying key is a constant of the outer circuit, it is to be assigned to the circuit
+// before compiling it. Keys with more than one commitment are not supported.
+func (vk *VerifyingKey) Assign(_ovk groth16.VerifyingKey) error {
+	ovk, ok := _ovk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("expected *groth16_bn254.VerifyingKey, got %s", reflect.TypeOf(_ovk))
+	}
+	commitmentInfo, err := ovk.CommitmentInfo.Single()
+	if err != nil {
+		return err
+	}
+
+	e, err := bn254.Pair([]bn254.G1Affine{ovk.G1.Alpha}, []bn254.G2Affine{ovk.G2.Beta})
+	if err != nil {
+		return err
+	}
+	vk.E = sw_bn254.NewGTEl(e)
+
+	vk.G1.K = make([]sw_bn254.G1Affine, len(ovk.G1.K))
+	for i := 0; i < len(ovk.G1.K); i++ {
+		vk.G1.K[i] = sw_bn254.NewG1Affine(ovk.G1.K[i])
+	}
+	var deltaNeg, gammaNeg bn254.G2Affine
+	deltaNeg.Neg(&ovk.G2.Delta)
+	gammaNeg.Neg(&ovk.G2.Gamma)
+	vk.G2.DeltaNeg = sw_bn254.NewG2Affine(deltaNeg)
+	vk.G2.GammaNeg = sw_bn254.NewG2Affine(gammaNeg)
+
+	var commitmentKey pedersen.VerifyingKey
+	if len(ovk.CommitmentKeys) != 0 {
+		commitmentKey = ovk.CommitmentKeys[0]
+	}
+	vk.CommitmentKey.G = sw_bn254.NewG2Affine(commitmentKey.G)
+	vk.CommitmentKey.GRootSigmaNeg = sw_bn254.NewG2Affine(commitmentKey.GRootSigmaNeg)
+	vk.CommitmentInfo = commitmentInfo
+	return nil
+}
+
+// ValueOfPublicWitness returns the "in-circuit" public inputs corresponding to the public
+// witness of the inner circuit.
+func ValueOfPublicWitness(w witness.Witness) ([]emulated.Element[emulated.BN254Fr], error) {
+	vect, ok := w.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("expected fr.Vector, got %s", reflect.TypeOf(w.Vector()).String())
+	}
+	res := make([]emulated.Element[emulated.BN254Fr], len(vect))
+	for i := range vect {
+		res[i] = emulated.ValueOf[emulated.BN254Fr](vect[i])
+	}
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package groth16
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type innerCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *innerCircuit) Define(api frontend.API) error {
+	committer, ok := api.Compiler().(frontend.Committer)
+	if !ok {
+		return fmt.Errorf("compiler does not commit")
+	}
+	commit, err := committer.Commit(c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commit, 0)
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+type outerCircuit struct {
+	InnerProof   Proof
+	InnerVk      VerifyingKey
+	PublicInputs []emulated.Element[emulated.BN254Fr] `gnark:",public"`
+}
+
+func (c *outerCircuit) Define(api frontend.API) error {
+	return Verify(api, c.InnerVk, c.InnerProof, c.PublicInputs)
+}
+
+func TestVerifierWithCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	prove := func(x int) groth16.Proof {
+		w, err := frontend.NewWitness(&innerCircuit{X: x, Y: 9}, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		proof, err := groth16.Prove(ccs, pk, w)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		return proof
+	}
+	proof := prove(3)
+
+	publicInputs, err := ValueOfPublicWitness(mustPublic(t, &innerCircuit{Y: 9}))
+	assert.NoError(err)
+
+	// the verifying key is a constant of the outer circuit
+	var circuit outerCircuit
+	assert.NoError(circuit.InnerVk.Assign(vk))
+	circuit.PublicInputs = make([]emulated.Element[emulated.BN254Fr], len(publicInputs))
+
+	var assignment outerCircuit
+	assert.NoError(assignment.InnerProof.Assign(proof))
+	assignment.PublicInputs = publicInputs
+
+	assert.NoError(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+
+	// wrong public input
+	assignment.PublicInputs = []emulated.Element[emulated.BN254Fr]{emulated.ValueOf[emulated.BN254Fr](4)}
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+	assignment.PublicInputs = publicInputs
+
+	// the commitment of another valid proof, with a valid proof of knowledge, gives another
+	// commitment wire, which the in-circuit hash catches
+	var other Proof
+	assert.NoError(other.Assign(prove(-3)))
+	assignment.InnerProof.Commitment, assignment.InnerProof.CommitmentPok = other.Commitment, other.CommitmentPok
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+}
+
+type commitmentWireCircuit struct {
+	Commitment   sw_bn254.G1Affine
+	PublicInputs []emulated.Element[emulated.BN254Fr]
+	Wire         emulated.Element[emulated.BN254Fr]
+
+	info constraint.Commitment
+}
+
+func (c *commitmentWireCircuit) Define(api frontend.API) error {
+	wire, err := computeCommitmentWire(api, &c.info, &c.Commitment, c.PublicInputs)
+	if err != nil {
+		return err
+	}
+	f, err := emulated.NewField[emulated.BN254Fr](api)
+	if err != nil {
+		return err
+	}
+	f.AssertIsEqual(wire, &c.Wire)
+	return nil
+}
+
+func TestCommitmentWire(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&innerCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+
+	// the commitment wire as backend/groth16 computes it
+	info, err := vk.(*groth16_bn254.VerifyingKey).CommitmentInfo.Single()
+	assert.NoError(err)
+	commitment := proof.(*groth16_bn254.Proof).Commitments[0]
+	wire, err := fr.Hash(info.SerializeCommitment(commitment.Marshal(), []*big.Int{big.NewInt(9)}, fr.Bytes), []byte(constraint.CommitmentDst), 1)
+	assert.NoError(err)
+
+	circuit := commitmentWireCircuit{PublicInputs: make([]emulated.Element[emulated.BN254Fr], 1), info: info}
+	assignment := commitmentWireCircuit{
+		Commitment:   sw_bn254.NewG1Affine(commitment),
+		PublicInputs: []emulated.Element[emulated.BN254Fr]{emulated.ValueOf[emulated.BN254Fr](9)},
+		Wire:         emulated.ValueOf[emulated.BN254Fr](wire[0]),
+	}
+	assert.NoError(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+
+	// the wire is constrained to be the hash, a prover can't choose it
+	var tampered fr.Element
+	tampered.SetOne()
+	tampered.Add(&tampered, &wire[0])
+	assignment.Wire = emulated.ValueOf[emulated.BN254Fr](tampered)
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+}
+
+type twoCommitmentsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *twoCommitmentsCircuit) Define(api frontend.API) error {
+	committer := api.Compiler().(frontend.Committer)
+	for _, v := range []frontend.Variable{c.X, c.Y} {
+		commit, err := committer.Commit(v)
+		if err != nil {
+			return err
+		}
+		api.AssertIsDifferent(commit, 0)
+	}
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestAssignSeveralCommitments(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &twoCommitmentsCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&twoCommitmentsCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+
+	var circuit outerCircuit
+	assert.Error(circuit.InnerVk.Assign(vk))
+	assert.Error(circuit.InnerProof.Assign(proof))
+}
+
+func mustPublic(t *testing.T, assignment frontend.Circuit) witness.Witness {
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
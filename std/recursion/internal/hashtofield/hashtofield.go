@@ -0,0 +1,92 @@
+// Package hashtofield encodes emulated field elements and points as bytes, and hashes bytes to
+// emulated field elements with SHA-256, in circuit, as gnark-crypto does outside of it: the
+// recursive verifiers use it to derive the challenges and the commitment wires of the proofs
+// they verify.
+package hashtofield
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// ConstBytes returns the bytes of s as constant variables.
+func ConstBytes(s string) []frontend.Variable {
+	res := make([]frontend.Variable, len(s))
+	for i := range s {
+		res[i] = int(s[i])
+	}
+	return res
+}
+
+// ElementBytes returns the bytes of the canonical representation of e in big-endian, as the
+// Marshal methods of gnark-crypto return them: 32 bytes for the BN254 fields.
+func ElementBytes[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], e *emulated.Element[T]) []frontend.Variable {
+	var params T
+	nbBytes := (params.Modulus().BitLen() + 7) / 8
+
+	// Reduce keeps the elements without overflow as they are, which may be larger than the
+	// modulus: the remainder of MulMod is the canonical one, as AssertIsInRange enforces
+	r := f.MulMod(e, f.One())
+	f.AssertIsInRange(r)
+	eBits := f.ToBits(r)
+	for len(eBits) < 8*nbBytes {
+		eBits = append(eBits, 0)
+	}
+	res := make([]frontend.Variable, nbBytes)
+	for i := range res {
+		res[i] = bits.FromBinary(api, eBits[8*(nbBytes-1-i):8*(nbBytes-i)], bits.WithUnconstrainedInputs())
+	}
+	return res
+}
+
+// FromBytes returns the element of the bytes b in big-endian, not reduced. b must fit in the
+// limbs of the field.
+func FromBytes[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], b []frontend.Variable) *emulated.Element[T] {
+	res := make([]frontend.Variable, 0, 8*len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		res = append(res, bits.ToBinary(api, b[i], bits.WithNbDigits(8))...)
+	}
+	return f.FromBits(res...)
+}
+
+// HashToField returns the hash to field of msg as fr.Hash(msg, dst, 1) of gnark-crypto outside
+// of the circuit, for the 254-bit scalar fields: the 48 bytes of expand_message_xmd with
+// SHA-256 (RFC 9380), in big-endian, modulo the modulus of the field.
+func HashToField[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], msg []frontend.Variable, dst string) *emulated.Element[T] {
+	const lenInBytes = 48
+	dstPrime := append(ConstBytes(dst), len(dst))
+
+	data := make([]frontend.Variable, 64, 64+len(msg)+3+len(dstPrime))
+	for i := range data {
+		data[i] = 0
+	}
+	data = append(data, msg...)
+	data = append(data, lenInBytes>>8, lenInBytes&0xff, 0)
+	data = append(data, dstPrime...)
+	b0 := sha2.Sum256(api, data)
+
+	data = append(append(append([]frontend.Variable{}, b0[:]...), 1), dstPrime...)
+	b1 := sha2.Sum256(api, data)
+
+	data = make([]frontend.Variable, 32, 32+1+len(dstPrime))
+	for i := range data {
+		x := bits.ToBinary(api, b0[i], bits.WithNbDigits(8))
+		y := bits.ToBinary(api, b1[i], bits.WithNbDigits(8))
+		for j := range x {
+			x[j] = api.Xor(x[j], y[j])
+		}
+		data[i] = bits.FromBinary(api, x, bits.WithUnconstrainedInputs())
+	}
+	data = append(append(data, 2), dstPrime...)
+	b2 := sha2.Sum256(api, data)
+
+	// the 48 bytes are b₁ ∥ b₂[:16], of value hi·2²⁵⁶ + lo
+	hi := FromBytes(api, f, b1[:16])
+	lo := FromBytes(api, f, append(append([]frontend.Variable{}, b1[16:]...), b2[:16]...))
+	c := f.NewElement(new(big.Int).Lsh(big.NewInt(1), 256))
+	return f.Reduce(f.Add(lo, f.MulMod(hi, c)))
+}
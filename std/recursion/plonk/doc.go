@@ -0,0 +1,17 @@
+// Package plonk provides a ZKP-circuit function to verify BN254 PLONK proofs, as produced
+// by backend/plonk, inside a circuit.
+//
+// The BN254 arithmetic is emulated (see [github.com/consensys/gnark/std/math/emulated]),
+// so the verifier can be used in a circuit defined over any scalar field. In particular, a
+// PLONK proof can be wrapped into a Groth16 proof of an outer circuit, which is cheaper to
+// verify, for example on chain.
+//
+// The Fiat-Shamir challenges are derived with SHA-256 in circuit (see
+// [github.com/consensys/gnark/std/hash/sha2]), as backend/plonk derives them, and so is the
+// BSB22 commitment wire when the inner circuit has a commitment. The KZG openings are checked
+// with [github.com/consensys/gnark/std/commitments/kzg_bn254].
+//
+// The verifying key of the inner circuit, including the KZG verifying key of its SRS, is a
+// constant of the outer circuit (see VerifyingKey): an outer circuit verifies the proofs of a
+// single inner circuit and setup, and its prover only chooses the proof and the public inputs.
+package plonk
@@ -0,0 +1,46 @@
+package plonk
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/recursion/internal/hashtofield"
+)
+
+// commitmentDst is the domain separation tag of the hash to field of the BSB22 commitment.
+const commitmentDst = "BSB22-Plonk"
+
+// transcript derives the challenges of the Fiat-Shamir transcript of gnark-crypto with
+// SHA-256, in circuit: a challenge is the digest of its name, the digest of the previous
+// challenge if any, and the bytes bound to it.
+type transcript struct {
+	api      frontend.API
+	previous []frontend.Variable
+}
+
+// challenge returns the digest of the next challenge, named name, with the bindings.
+func (t *transcript) challenge(name string, bindings ...[]frontend.Variable) []frontend.Variable {
+	data := hashtofield.ConstBytes(name)
+	data = append(data, t.previous...)
+	for _, b := range bindings {
+		data = append(data, b...)
+	}
+	digest := sha2.Sum256(t.api, data)
+	t.previous = digest[:]
+	return t.previous
+}
+
+// pointBytes returns the 64 bytes of the uncompressed point p, as Marshal returns them
+// outside of the circuit. The zero point (0,0) is 64 zero bytes.
+func (v *verifier) pointBytes(p *Digest) []frontend.Variable {
+	return append(hashtofield.ElementBytes(v.api, v.fp, &p.X), hashtofield.ElementBytes(v.api, v.fp, &p.Y)...)
+}
+
+// scalarBytes returns the 32 bytes of the canonical representation of s.
+func (v *verifier) scalarBytes(s *Scalar) []frontend.Variable {
+	return hashtofield.ElementBytes(v.api, v.fr, s)
+}
+
+// fromBytes returns the scalar of at most 32 bytes in big-endian, not reduced.
+func (v *verifier) fromBytes(b []frontend.Variable) *Scalar {
+	return hashtofield.FromBytes(v.api, v.fr, b)
+}
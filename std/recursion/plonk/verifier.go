@@ -0,0 +1,334 @@
+package plonk
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/commitments/kzg_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion/internal/hashtofield"
+)
+
+// Scalar is an element of the BN254 scalar field.
+type Scalar = kzg_bn254.Scalar
+
+// Digest is a KZG commitment to a polynomial.
+type Digest = kzg_bn254.Digest
+
+// Proof represents a BN254 PLONK proof.
+type Proof struct {
+	// Commitments to the solution vectors
+	LRO [3]Digest
+
+	// Commitment to Z, the permutation polynomial
+	Z Digest
+
+	// Commitments to h1, h2, h3 such that h = h1 + Xh2 + X**2h3 is the quotient polynomial
+	H [3]Digest
+
+	// PI2, the BSB22 commitment
+	PI2 Digest
+
+	// Batch opening proof of h1 + zeta*h2 + zeta**2h3, linearizedPolynomial, l, r, o, s1, s2, qCPrime
+	BatchedProof kzg_bn254.BatchOpeningProof
+
+	// Opening proof of Z at zeta*mu
+	ZShiftedOpening kzg_bn254.OpeningProof
+}
+
+// VerifyingKey represents a BN254 PLONK verifying key.
+//
+// The verifying key, with the KZG verifying key of the SRS, is a constant of the outer
+// circuit, not part of its witness: it is assigned (see Assign) to the circuit before
+// compiling it, and the outer circuit only verifies the proofs of the inner circuit it was
+// compiled for. Would the key be a witness, the prover could verify its proof against a key of
+// its choice, for instance one of an SRS whose toxic waste it knows.
+type VerifyingKey struct {
+	// Size of the domain, the number of public inputs, the generator of the domain and the
+	// shift of its cosets, and the indexes of the commitment constraints.
+	Size                        uint64     `gnark:"-"`
+	NbPublicVariables           uint64     `gnark:"-"`
+	Generator, CosetShift       fr.Element `gnark:"-"`
+	CommitmentConstraintIndexes []uint64   `gnark:"-"`
+
+	// Commitment scheme that is used for an instantiation of PLONK
+	Kzg kzg_bn254.VK `gnark:"-"`
+
+	// S commitments to S1, S2, S3
+	S [3]Digest `gnark:"-"`
+
+	// Commitments to ql, qr, qm, qo, qk, qcp
+	Ql, Qr, Qm, Qo, Qk, Qcp Digest `gnark:"-"`
+}
+
+// nbClaimedValues is the number of polynomials of the batched opening proof.
+const nbClaimedValues = 8
+
+// verifier holds the emulated BN254 arithmetic of Verify.
+type verifier struct {
+	api   frontend.API
+	curve *sw_emulated.Curve[emulated.BN254Fp, emulated.BN254Fr]
+	fp    *emulated.Field[emulated.BN254Fp]
+	fr    *emulated.Field[emulated.BN254Fr]
+	kzg   *kzg_bn254.Verifier
+}
+
+// Verify implements the verification function of PLONK, as backend/plonk/bn254 does.
+// publicInputs are the public inputs of the inner circuit.
+//
+// The challenges are derived in circuit with SHA-256, binding the verifying key, the public
+// inputs and the proof, so that they are the ones of the prover. If the inner circuit has a
+// commitment, its wire is the hash to field of PI2, also computed in circuit. The two KZG
+// openings, at ζ and ζω, are checked by two pairing checks.
+//
+// This function doesn't check that the proof points are in the correct subgroups.
+func Verify(api frontend.API, vk VerifyingKey, proof Proof, publicInputs []emulated.Element[emulated.BN254Fr]) error {
+	if vk.Size == 0 || vk.Size&(vk.Size-1) != 0 {
+		return fmt.Errorf("inner verifying key domain size %d is not a power of two; VerifyingKey must be initialized before compiling circuit", vk.Size)
+	}
+	if uint64(len(publicInputs)) != vk.NbPublicVariables {
+		return fmt.Errorf("invalid number of public inputs, got %d, expected %d", len(publicInputs), vk.NbPublicVariables)
+	}
+	if len(proof.BatchedProof.ClaimedValues) != nbClaimedValues {
+		return fmt.Errorf("invalid number of claimed values, got %d, expected %d", len(proof.BatchedProof.ClaimedValues), nbClaimedValues)
+	}
+
+	curve, err := sw_emulated.New[emulated.BN254Fp, emulated.BN254Fr](api, sw_emulated.GetBN254Params())
+	if err != nil {
+		return fmt.Errorf("new curve: %w", err)
+	}
+	fp, err := emulated.NewField[emulated.BN254Fp](api)
+	if err != nil {
+		return fmt.Errorf("new base field: %w", err)
+	}
+	frField, err := emulated.NewField[emulated.BN254Fr](api)
+	if err != nil {
+		return fmt.Errorf("new scalar field: %w", err)
+	}
+	kzg, err := kzg_bn254.NewVerifier(api)
+	if err != nil {
+		return fmt.Errorf("new kzg verifier: %w", err)
+	}
+	v := &verifier{api: api, curve: curve, fp: fp, fr: frField, kzg: kzg}
+	return v.verify(&vk, &proof, publicInputs)
+}
+
+func (v *verifier) verify(vk *VerifyingKey, proof *Proof, publicInputs []Scalar) error {
+	// the challenges, as in the prover
+	fs := transcript{api: v.api}
+	bindings := [][]frontend.Variable{
+		v.pointBytes(&vk.S[0]), v.pointBytes(&vk.S[1]), v.pointBytes(&vk.S[2]),
+		v.pointBytes(&vk.Ql), v.pointBytes(&vk.Qr), v.pointBytes(&vk.Qm), v.pointBytes(&vk.Qo), v.pointBytes(&vk.Qk),
+	}
+	for i := range publicInputs {
+		bindings = append(bindings, v.scalarBytes(&publicInputs[i]))
+	}
+	pi2Bytes := v.pointBytes(&proof.PI2)
+	bindings = append(bindings, pi2Bytes,
+		v.pointBytes(&proof.LRO[0]), v.pointBytes(&proof.LRO[1]), v.pointBytes(&proof.LRO[2]))
+	gamma := v.fromBytes(fs.challenge("gamma", bindings...))
+	beta := v.fromBytes(fs.challenge("beta"))
+	alpha := v.fromBytes(fs.challenge("alpha", v.pointBytes(&proof.Z)))
+	zeta := v.fromBytes(fs.challenge("zeta",
+		v.pointBytes(&proof.H[0]), v.pointBytes(&proof.H[1]), v.pointBytes(&proof.H[2])))
+
+	// evaluation of Z=Xⁿ-1 at ζ
+	zetaPowerM := zeta
+	for i := 0; i < bits.TrailingZeros64(vk.Size); i++ {
+		zetaPowerM = v.fr.MulMod(zetaPowerM, zetaPowerM)
+	}
+	one := v.fr.One()
+	zzeta := v.fr.Sub(zetaPowerM, one)
+
+	// compute PI = ∑_{i<n} Lᵢ*wᵢ
+	lagrangeOne := v.lagrange(vk, zeta, zzeta, 0)
+	pi := v.fr.Zero()
+	for i := range publicInputs {
+		li := lagrangeOne
+		if i > 0 {
+			li = v.lagrange(vk, zeta, zzeta, uint64(i))
+		}
+		pi = v.fr.Add(pi, v.fr.MulMod(li, &publicInputs[i]))
+	}
+	if len(vk.CommitmentConstraintIndexes) > 0 {
+		// the commitment wires are all the hash of PI2
+		hashRes := hashtofield.HashToField(v.api, v.fr, pi2Bytes, commitmentDst)
+		for _, index := range vk.CommitmentConstraintIndexes {
+			li := v.lagrange(vk, zeta, zzeta, vk.NbPublicVariables+index)
+			pi = v.fr.Add(pi, v.fr.MulMod(li, hashRes))
+		}
+	}
+
+	claimedValues := proof.BatchedProof.ClaimedValues
+	claimedQuotient := &claimedValues[0]
+	linearizedPolynomialZeta := &claimedValues[1]
+	l, r, o := &claimedValues[2], &claimedValues[3], &claimedValues[4]
+	s1, s2 := &claimedValues[5], &claimedValues[6]
+	qC := &claimedValues[7]
+	zu := &proof.ZShiftedOpening.ClaimedValue
+
+	// α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ)
+	v1 := v.fr.Add(v.fr.Add(v.fr.MulMod(beta, s1), l), gamma) // (l(ζ)+β*s1(ζ)+γ)
+	w1 := v.fr.Add(v.fr.Add(v.fr.MulMod(beta, s2), r), gamma) // (r(ζ)+β*s2(ζ)+γ)
+	uvw := v.fr.MulMod(v.fr.MulMod(v1, w1), v.fr.Add(o, gamma))
+	alphaZu := v.fr.MulMod(alpha, zu)
+	permutation := v.fr.MulMod(uvw, alphaZu)
+
+	// α²*L₁(ζ)
+	alphaSquareLagrange := v.fr.MulMod(v.fr.MulMod(lagrangeOne, alpha), alpha)
+
+	// check that H(ζ)(ζⁿ-1) = linearizedpolynomial + pi(ζ) + α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ) - α²*L₁(ζ)
+	rhs := v.fr.Sub(v.fr.Add(v.fr.Add(linearizedPolynomialZeta, pi), permutation), alphaSquareLagrange)
+	v.fr.AssertIsEqual(v.fr.MulMod(claimedQuotient, zzeta), rhs)
+
+	// compute the folded commitment to H: Comm(h₁) + ζᵐ⁺²*Comm(h₂) + ζ²⁽ᵐ⁺²⁾*Comm(h₃)
+	zetaMPlusTwo := v.fr.MulMod(zetaPowerM, v.fr.MulMod(zeta, zeta))
+	foldedH, err := v.curve.MultiScalarMul(
+		[]*Digest{&proof.H[0], &proof.H[1], &proof.H[2]},
+		[]*Scalar{one, zetaMPlusTwo, v.fr.MulMod(zetaMPlusTwo, zetaMPlusTwo)},
+	)
+	if err != nil {
+		return fmt.Errorf("folded quotient: %w", err)
+	}
+
+	// Compute the commitment to the linearized polynomial
+	// linearizedPolynomialDigest =
+	// 		l(ζ)*ql+r(ζ)*qr+r(ζ)l(ζ)*qm+o(ζ)*qo+qk+qc*PI2 +
+	// 		α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) +
+	// 		α²*L₁(ζ)*Z
+	rl := v.fr.MulMod(l, r)
+	// α*Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β
+	_s1 := v.fr.MulMod(v.fr.MulMod(v.fr.MulMod(alphaZu, beta), v1), w1)
+	// -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
+	betaZeta := v.fr.MulMod(beta, zeta)
+	var cosetShift, cosetSquare big.Int
+	vk.CosetShift.BigInt(&cosetShift)
+	cosetSquare.Mul(&cosetShift, &cosetShift)
+	u := v.fr.Add(v.fr.Add(betaZeta, l), gamma)
+	v2 := v.fr.Add(v.fr.Add(v.fr.MulMod(betaZeta, v.fr.NewElement(&cosetShift)), r), gamma)
+	w2 := v.fr.Add(v.fr.Add(v.fr.MulMod(betaZeta, v.fr.NewElement(&cosetSquare)), o), gamma)
+	_s2 := v.fr.MulMod(v.fr.MulMod(v.fr.MulMod(u, v2), w2), alpha)
+	_s2 = v.fr.Sub(alphaSquareLagrange, _s2)
+
+	linearizedPolynomialDigest, err := v.curve.MultiScalarMul(
+		[]*Digest{&vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk, &proof.PI2, &vk.S[2], &proof.Z},
+		[]*Scalar{l, r, rl, o, one, qC, _s1, _s2},
+	)
+	if err != nil {
+		return fmt.Errorf("linearized polynomial: %w", err)
+	}
+
+	// fold the first proof, with the challenge of kzg.FoldProof
+	digests := []*Digest{foldedH, linearizedPolynomialDigest, &proof.LRO[0], &proof.LRO[1], &proof.LRO[2], &vk.S[0], &vk.S[1], &vk.Qcp}
+	bindings = [][]frontend.Variable{v.scalarBytes(zeta)}
+	for _, d := range digests {
+		bindings = append(bindings, v.pointBytes(d))
+	}
+	for i := range claimedValues {
+		bindings = append(bindings, v.scalarBytes(&claimedValues[i]))
+	}
+	foldingTranscript := transcript{api: v.api}
+	foldingGamma := v.fromBytes(foldingTranscript.challenge("gamma", bindings...))
+	gammas := make([]*Scalar, len(digests))
+	gammas[0] = one
+	folded := &claimedValues[0]
+	for i := 1; i < len(gammas); i++ {
+		gammas[i] = v.fr.MulMod(gammas[i-1], foldingGamma)
+		folded = v.fr.Add(folded, v.fr.MulMod(gammas[i], &claimedValues[i]))
+	}
+	foldedDigest, err := v.curve.MultiScalarMul(digests, gammas)
+	if err != nil {
+		return fmt.Errorf("folded digest: %w", err)
+	}
+
+	// verify the openings at ζ and ζω
+	foldedProof := kzg_bn254.OpeningProof{H: proof.BatchedProof.H, ClaimedValue: *v.fr.Reduce(folded)}
+	if err := v.kzg.AssertProof(vk.Kzg, *foldedDigest, foldedProof, zeta); err != nil {
+		return fmt.Errorf("batched opening: %w", err)
+	}
+	var generator big.Int
+	vk.Generator.BigInt(&generator)
+	shiftedZeta := v.fr.MulMod(zeta, v.fr.NewElement(&generator))
+	if err := v.kzg.AssertProof(vk.Kzg, proof.Z, proof.ZShiftedOpening, shiftedZeta); err != nil {
+		return fmt.Errorf("shifted opening: %w", err)
+	}
+	return nil
+}
+
+// lagrange returns the evaluation at ζ of the i-th Lagrange polynomial of the domain:
+// Lᵢ(ζ) = ωⁱ/n (ζⁿ-1)/(ζ-ωⁱ), with zzeta = ζⁿ-1.
+func (v *verifier) lagrange(vk *VerifyingKey, zeta, zzeta *Scalar, i uint64) *Scalar {
+	var wPowI, c fr.Element
+	wPowI.Exp(vk.Generator, new(big.Int).SetUint64(i))
+	c.SetUint64(vk.Size)
+	c.Div(&wPowI, &c)
+	var wPowIBig, cBig big.Int
+	wPowI.BigInt(&wPowIBig)
+	c.BigInt(&cBig)
+	den := v.fr.Sub(zeta, v.fr.NewElement(&wPowIBig))
+	return v.fr.Div(v.fr.MulMod(zzeta, v.fr.NewElement(&cBig)), den)
+}
+
+// Assign values to the "in-circuit" Proof from a "out-of-circuit" Proof. It also sets its
+// shape, so that it can be used to define the outer circuit.
+func (proof *Proof) Assign(_oproof plonk.Proof) {
+	oproof, ok := _oproof.(*plonk_bn254.Proof)
+	if !ok {
+		panic("expected *plonk_bn254.Proof, got " + reflect.TypeOf(_oproof).String())
+	}
+	for i := range proof.LRO {
+		proof.LRO[i] = kzg_bn254.ValueOfDigest(oproof.LRO[i])
+		proof.H[i] = kzg_bn254.ValueOfDigest(oproof.H[i])
+	}
+	proof.Z = kzg_bn254.ValueOfDigest(oproof.Z)
+	proof.PI2 = kzg_bn254.ValueOfDigest(oproof.PI2)
+	proof.BatchedProof = kzg_bn254.ValueOfBatchOpeningProof(oproof.BatchedProof)
+	proof.ZShiftedOpening = kzg_bn254.ValueOfOpeningProof(oproof.ZShiftedOpening)
+}
+
+// Assign values to the "in-circuit" VerifyingKey from a "out-of-circuit" VerifyingKey. As the
+// verifying key is a constant of the outer circuit, it is to be assigned to the circuit
+// before compiling it.
+func (vk *VerifyingKey) Assign(_ovk plonk.VerifyingKey) {
+	ovk, ok := _ovk.(*plonk_bn254.VerifyingKey)
+	if !ok {
+		panic("expected *plonk_bn254.VerifyingKey, got " + reflect.TypeOf(_ovk).String())
+	}
+	vk.Size = ovk.Size
+	vk.NbPublicVariables = ovk.NbPublicVariables
+	vk.Generator = ovk.Generator
+	vk.CosetShift = ovk.CosetShift
+	vk.CommitmentConstraintIndexes = append([]uint64{}, ovk.CommitmentConstraintIndexes...)
+	vk.Kzg = kzg_bn254.ValueOfVK(ovk.Kzg)
+	for i := range vk.S {
+		vk.S[i] = kzg_bn254.ValueOfDigest(ovk.S[i])
+	}
+	vk.Ql = kzg_bn254.ValueOfDigest(ovk.Ql)
+	vk.Qr = kzg_bn254.ValueOfDigest(ovk.Qr)
+	vk.Qm = kzg_bn254.ValueOfDigest(ovk.Qm)
+	vk.Qo = kzg_bn254.ValueOfDigest(ovk.Qo)
+	vk.Qk = kzg_bn254.ValueOfDigest(ovk.Qk)
+	vk.Qcp = kzg_bn254.ValueOfDigest(ovk.Qcp)
+}
+
+// ValueOfPublicWitness returns the "in-circuit" public inputs corresponding to the public
+// witness of the inner circuit.
+func ValueOfPublicWitness(w witness.Witness) ([]emulated.Element[emulated.BN254Fr], error) {
+	vect, ok := w.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("expected fr.Vector, got %s", reflect.TypeOf(w.Vector()).String())
+	}
+	res := make([]emulated.Element[emulated.BN254Fr], len(vect))
+	for i := range vect {
+		res[i] = emulated.ValueOf[emulated.BN254Fr](vect[i])
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+package plonk
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type innerCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *innerCircuit) Define(api frontend.API) error {
+	res := c.X
+	for i := 0; i < 5; i++ {
+		res = api.Mul(res, c.X)
+	}
+	api.AssertIsEqual(res, c.Y)
+	return nil
+}
+
+type innerCircuitWithCommitment struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *innerCircuitWithCommitment) Define(api frontend.API) error {
+	committer, ok := api.Compiler().(frontend.Committer)
+	if !ok {
+		return fmt.Errorf("compiler does not commit")
+	}
+	commit, err := committer.Commit(c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commit, 0)
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+type outerCircuit struct {
+	InnerProof   Proof
+	InnerVk      VerifyingKey
+	PublicInputs []emulated.Element[emulated.BN254Fr] `gnark:",public"`
+}
+
+func (c *outerCircuit) Define(api frontend.API) error {
+	return Verify(api, c.InnerVk, c.InnerProof, c.PublicInputs)
+}
+
+func testVerifier(t *testing.T, inner, innerAssignment frontend.Circuit) {
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, inner)
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+
+	w, err := frontend.NewWitness(innerAssignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, w)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+	publicInputs, err := ValueOfPublicWitness(publicWitness)
+	assert.NoError(err)
+
+	// the verifying key is a constant of the outer circuit, and the shape of the proof is part
+	// of its definition
+	var circuit outerCircuit
+	circuit.InnerVk.Assign(vk)
+	circuit.InnerProof.Assign(proof)
+	circuit.PublicInputs = make([]emulated.Element[emulated.BN254Fr], len(publicInputs))
+
+	var assignment outerCircuit
+	assignment.InnerProof.Assign(proof)
+	assignment.PublicInputs = publicInputs
+
+	assert.NoError(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+
+	// wrong public input
+	assignment.PublicInputs = []emulated.Element[emulated.BN254Fr]{emulated.ValueOf[emulated.BN254Fr](4)}
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+	assignment.PublicInputs = publicInputs
+
+	// a proof of another setup, assigned with its verifying key: the key of the outer circuit
+	// is the one it was defined with
+	// (the SRS of test.NewKZGSRS is cached, and so would be the key)
+	size := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+ccs.GetNbPublicVariables())) + 3
+	otherSRS, err := kzg.NewSRS(size, big.NewInt(42))
+	assert.NoError(err)
+	otherPk, otherVk, err := plonk.Setup(ccs, otherSRS)
+	assert.NoError(err)
+	otherProof, err := plonk.Prove(ccs, otherPk, w)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(otherProof, otherVk, publicWitness))
+	assignment.InnerProof.Assign(otherProof)
+	assignment.InnerVk.Assign(otherVk)
+	assert.Error(test.IsSolved(&circuit, &assignment, ecc.BW6_761.ScalarField()))
+}
+
+func TestVerifier(t *testing.T) {
+	testVerifier(t, &innerCircuit{}, &innerCircuit{X: 3, Y: 729})
+}
+
+func TestVerifierWithCommitment(t *testing.T) {
+	testVerifier(t, &innerCircuitWithCommitment{}, &innerCircuitWithCommitment{X: 3, Y: 9})
+}
@@ -51,7 +51,12 @@ func Map(api frontend.API, queryKey frontend.Variable,
 // inputs based on sel. The index of inputs starts from zero.
 //
 // sel needs to be between 0 and n - 1 (inclusive), where n is the number of inputs, otherwise the proof will fail.
+//
+// If api implements [frontend.Multiplexer], as the R1CS builder does, its multiplexer is used instead.
 func Mux(api frontend.API, sel frontend.Variable, inputs ...frontend.Variable) frontend.Variable {
+	if m, ok := api.(frontend.Multiplexer); ok && len(inputs) > 4 {
+		return m.Mux(sel, inputs...)
+	}
 	return generateSelector(api, true, sel, nil, inputs)
 }
 
@@ -0,0 +1,165 @@
+/*
+Package bls implements the verification of BLS signatures over BLS12-381 in circuit, with
+the public keys in G1 and the signatures in G2 as in the consensus layer of Ethereum, for
+instance to prove the attestations of the validators.
+
+The signatures are verified as specified by the [BLS signatures] draft: the message is
+hashed to G2 with the hash_to_curve of [RFC 9380] (expand_message_xmd with SHA-256, the
+simplified SWU map to a curve 3-isogenous to G2, and the clearing of the cofactor), and the
+signature is checked with a single pairing check
+
+	e(pk, H(m)) · e(-g1, sig) = 1.
+
+The arithmetic of BLS12-381 is emulated (see the [emulated] package) and its limbs are
+range checked by the [github.com/consensys/gnark/std/rangecheck] package, which batches the
+checks in a lookup argument against a commitment with the builders implementing
+[frontend.Committer]: most of the cost of the pairing is in these range checks. The square
+roots of the map to the curve and the divisions are computed by hints and checked with
+multiplications.
+
+The public keys are checked to be on the curve but not in G1: the keys must have been
+validated beforehand, like the keys of the validators when they are registered with their
+proof of possession. The signatures are checked to be in G2.
+
+[BLS signatures]: https://datatracker.ietf.org/doc/draft-irtf-cfrg-bls-signature/
+[RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+*/
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// DSTEthereum is the domain separation tag of the signatures of the consensus layer of
+// Ethereum, of the proof of possession scheme.
+const DSTEthereum = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+// PublicKey is a public key, a point of G1. It is assigned with [sw_bls12381.NewG1Affine].
+type PublicKey = sw_bls12381.G1Affine
+
+// Signature is a signature, a point of G2. It is assigned with [sw_bls12381.NewG2Affine].
+type Signature = sw_bls12381.G2Affine
+
+// Verifier verifies the signatures of a domain separation tag.
+type Verifier struct {
+	api     frontend.API
+	fp      *emulated.Field[emulated.BLS12381Fp]
+	ext2    *fields_bls12381.Ext2
+	pairing *sw_bls12381.Pairing
+	dst     []byte
+}
+
+// NewVerifier returns a Verifier of the signatures of the domain separation tag dst, for
+// instance DSTEthereum.
+func NewVerifier(api frontend.API, dst string) (*Verifier, error) {
+	if len(dst) == 0 || len(dst) > 255 {
+		return nil, errors.New("the domain separation tag must have 1 to 255 bytes")
+	}
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		return nil, fmt.Errorf("new base field: %w", err)
+	}
+	pairing, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		return nil, fmt.Errorf("new pairing: %w", err)
+	}
+	return &Verifier{
+		api:     api,
+		fp:      fp,
+		ext2:    fields_bls12381.NewExt2(api),
+		pairing: pairing,
+		dst:     []byte(dst),
+	}, nil
+}
+
+// Verify asserts that sig is a signature of the bytes of msg for the public key pk, and
+// constrains msg to be bytes. pk must be in G1, see the package documentation.
+func (v *Verifier) Verify(pk *PublicKey, msg []frontend.Variable, sig *Signature) error {
+	v.assertIsOnCurveG1(pk)
+	return v.verify(pk, msg, sig)
+}
+
+// FastAggregateVerify asserts that sig is the aggregate of the signatures of the bytes of
+// msg for the public keys pks, that is a signature for the sum of the keys, and constrains
+// msg to be bytes. The keys must be distinct points of G1, see the package documentation.
+func (v *Verifier) FastAggregateVerify(pks []*PublicKey, msg []frontend.Variable, sig *Signature) error {
+	if len(pks) == 0 {
+		return errors.New("no public key")
+	}
+	v.assertIsOnCurveG1(pks[0])
+	aggregate := pks[0]
+	for _, pk := range pks[1:] {
+		v.assertIsOnCurveG1(pk)
+		aggregate = v.addG1(aggregate, pk)
+	}
+	return v.verify(aggregate, msg, sig)
+}
+
+func (v *Verifier) verify(pk *PublicKey, msg []frontend.Variable, sig *Signature) error {
+	v.AssertIsOnG2(sig)
+	h := v.HashToG2(msg)
+	return v.pairing.PairingCheck([]*sw_bls12381.G1Affine{pk, &negG1}, []*sw_bls12381.G2Affine{h, sig})
+}
+
+// AssertIsOnG2 asserts that p is a point of G2, on the curve and in the subgroup of order r:
+// ψ(p) = [x]p, x being the seed of the curve.
+func (v *Verifier) AssertIsOnG2(p *sw_bls12381.G2Affine) {
+	// y² = x³ + 4(1+i)
+	yy := v.ext2.Square(&p.Y)
+	xxx := v.ext2.Mul(v.ext2.Square(&p.X), &p.X)
+	v.ext2.AssertIsEqual(yy, v.ext2.Add(xxx, &g2B))
+
+	// the seed is negative: ψ(p) = -[|x|]p
+	psi := v.psi(p)
+	xp := v.mulBySeed(p)
+	v.ext2.AssertIsEqual(&psi.X, &xp.X)
+	v.ext2.AssertIsEqual(&psi.Y, v.ext2.Neg(&xp.Y))
+}
+
+// assertIsOnCurveG1 asserts that p is on the curve y² = x³ + 4.
+func (v *Verifier) assertIsOnCurveG1(p *sw_bls12381.G1Affine) {
+	yy := v.fp.MulMod(&p.Y, &p.Y)
+	xxx := v.fp.MulMod(v.fp.MulMod(&p.X, &p.X), &p.X)
+	v.fp.AssertIsEqual(yy, v.fp.Add(xxx, v.fp.NewElement(4)))
+}
+
+// addG1 returns p + q, p and q being distinct points and not opposite.
+func (v *Verifier) addG1(p, q *sw_bls12381.G1Affine) *sw_bls12381.G1Affine {
+	// the inverse asserts that the x-coordinates are different
+	λ := v.fp.MulMod(v.fp.Sub(&q.Y, &p.Y), v.fp.Inverse(v.fp.Sub(&q.X, &p.X)))
+	x := v.fp.Sub(v.fp.MulMod(λ, λ), v.fp.Add(&p.X, &q.X))
+	y := v.fp.Sub(v.fp.MulMod(λ, v.fp.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G1Affine{X: *v.fp.Reduce(x), Y: *v.fp.Reduce(y)}
+}
+
+// negG1 is the opposite of the generator of G1.
+var negG1 sw_bls12381.G1Affine
+
+func init() {
+	_, _, g1, _ := bls12381.Generators()
+	g1.Neg(&g1)
+	negG1 = sw_bls12381.NewG1Affine(g1)
+}
+
+// e2 returns the constant of the coordinates in decimal, which may be negative.
+func e2(a0, a1 string) fields_bls12381.E2 {
+	var b0, b1 big.Int
+	if _, ok := b0.SetString(a0, 10); !ok {
+		panic("invalid constant " + a0)
+	}
+	if _, ok := b1.SetString(a1, 10); !ok {
+		panic("invalid constant " + a1)
+	}
+	return fields_bls12381.E2{
+		A0: emulated.ValueOf[emulated.BLS12381Fp](&b0),
+		A1: emulated.ValueOf[emulated.BLS12381Fp](&b1),
+	}
+}
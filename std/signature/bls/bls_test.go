@@ -0,0 +1,146 @@
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/test"
+)
+
+type hashToG2Circuit struct {
+	Msg      []frontend.Variable
+	Expected sw_bls12381.G2Affine
+}
+
+func (c *hashToG2Circuit) Define(api frontend.API) error {
+	v, err := NewVerifier(api, DSTEthereum)
+	if err != nil {
+		return err
+	}
+	h := v.HashToG2(c.Msg)
+	v.ext2.AssertIsEqual(&h.X, &c.Expected.X)
+	v.ext2.AssertIsEqual(&h.Y, &c.Expected.Y)
+	return nil
+}
+
+func TestHashToG2(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, msg := range [][]byte{[]byte("abc"), make([]byte, 32)} {
+		expected, err := bls12381.HashToG2(msg, []byte(DSTEthereum))
+		assert.NoError(err)
+		witness := hashToG2Circuit{Msg: bytesToVariables(msg), Expected: sw_bls12381.NewG2Affine(expected)}
+		err = test.IsSolved(&hashToG2Circuit{Msg: make([]frontend.Variable, len(msg))}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+}
+
+type verifyCircuit struct {
+	PublicKeys []PublicKey
+	Msg        []frontend.Variable
+	Sig        Signature
+}
+
+func (c *verifyCircuit) Define(api frontend.API) error {
+	v, err := NewVerifier(api, DSTEthereum)
+	if err != nil {
+		return err
+	}
+	if len(c.PublicKeys) == 1 {
+		return v.Verify(&c.PublicKeys[0], c.Msg, &c.Sig)
+	}
+	pks := make([]*PublicKey, len(c.PublicKeys))
+	for i := range pks {
+		pks[i] = &c.PublicKeys[i]
+	}
+	return v.FastAggregateVerify(pks, c.Msg, &c.Sig)
+}
+
+// sign returns the public keys of random secret keys and the aggregate of their signatures.
+func sign(t *testing.T, nbKeys int, msg []byte) ([]PublicKey, Signature) {
+	_, _, g1, _ := bls12381.Generators()
+	h, err := bls12381.HashToG2(msg, []byte(DSTEthereum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pks := make([]PublicKey, nbKeys)
+	var sig bls12381.G2Jac
+	for i := range pks {
+		var sk fr.Element
+		if _, err := sk.SetRandom(); err != nil {
+			t.Fatal(err)
+		}
+		var b big.Int
+		sk.BigInt(&b)
+		var pk bls12381.G1Affine
+		var s bls12381.G2Affine
+		pk.ScalarMultiplication(&g1, &b)
+		s.ScalarMultiplication(&h, &b)
+		pks[i] = sw_bls12381.NewG1Affine(pk)
+		sig.AddMixed(&s)
+	}
+	var s bls12381.G2Affine
+	s.FromJacobian(&sig)
+	return pks, sw_bls12381.NewG2Affine(s)
+}
+
+func TestVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := make([]byte, 32)
+	if _, err := rand.Read(msg); err != nil {
+		t.Fatal(err)
+	}
+	for _, nbKeys := range []int{1, 3} {
+		pks, sig := sign(t, nbKeys, msg)
+		circuit := verifyCircuit{PublicKeys: make([]PublicKey, nbKeys), Msg: make([]frontend.Variable, len(msg))}
+		witness := verifyCircuit{PublicKeys: pks, Msg: bytesToVariables(msg), Sig: sig}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "%d keys", nbKeys)
+
+		// another message
+		msg[0] ^= 1
+		witness.Msg = bytesToVariables(msg)
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), "%d keys", nbKeys)
+		msg[0] ^= 1
+	}
+}
+
+func TestSubgroupCheck(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("abc")
+	pks, _ := sign(t, 1, msg)
+
+	// a signature on the curve but not in G2
+	var p bls12381.G2Affine
+	var b bls12381.E2
+	b.A0.SetUint64(4)
+	b.A1.SetUint64(4)
+	for i := uint64(1); ; i++ {
+		p.X.A0.SetUint64(i)
+		p.X.A1.SetOne()
+		var gx bls12381.E2
+		gx.Square(&p.X).Mul(&gx, &p.X).Add(&gx, &b)
+		if gx.Legendre() == 1 {
+			p.Y.Sqrt(&gx)
+			break
+		}
+	}
+	assert.True(p.IsOnCurve())
+	assert.False(p.IsInSubGroup())
+
+	circuit := verifyCircuit{PublicKeys: make([]PublicKey, 1), Msg: make([]frontend.Variable, len(msg))}
+	witness := verifyCircuit{PublicKeys: pks, Msg: bytesToVariables(msg), Sig: sw_bls12381.NewG2Affine(p)}
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+}
+
+func bytesToVariables(b []byte) []frontend.Variable {
+	res := make([]frontend.Variable, len(b))
+	for i := range b {
+		res[i] = b[i]
+	}
+	return res
+}
@@ -0,0 +1,259 @@
+package bls
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// The constants of the hash to G2 of RFC 9380, section 8.8.2 and appendix E.3, in decimal.
+var (
+	// the curve y² = x³ + A'x + B' 3-isogenous to G2, and the non-square Z of the map
+	sswuA = e2("0", "240")
+	sswuB = e2("1012", "1012")
+	sswuZ = e2("-2", "-1")
+
+	// the coefficients of the isogeny, of the lowest degree first, the denominators being
+	// monic
+	isoXNum = []fields_bls12381.E2{
+		e2("889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542"),
+		e2("0", "2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706522"),
+		e2("2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706526", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853261"),
+		e2("3557697382419259905260257622876359250272784728834673675850718343221361467102966990615722337003569479144794908942033", "0"),
+	}
+	isoXDen = []fields_bls12381.E2{
+		e2("0", "-72"),
+		e2("12", "-12"),
+	}
+	isoYNum = []fields_bls12381.E2{
+		e2("3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558", "3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558"),
+		e2("0", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235518"),
+		e2("2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706524", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853263"),
+		e2("2816510427748580758331037284777117739799287910327449993381818688383577828123182200904113516794492504322962636245776", "0"),
+	}
+	isoYDen = []fields_bls12381.E2{
+		e2("-432", "-432"),
+		e2("0", "-216"),
+		e2("18", "-18"),
+	}
+
+	// the endomorphism ψ(x, y) = (conj(x)·ψx, conj(y)·ψy) of G2
+	psiX = e2("0", "4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939437")
+	psiY = e2("2973677408986561043442465346520108879172042883009249989176415018091420807192182638567116318576472649347015917690530", "1028732146235106349975324479215795277384839936929757896155643118032610843298655225875571310552543014690878354869257")
+
+	// the curve y² = x³ + 4(1+i) of G2
+	g2B = e2("4", "4")
+)
+
+// seed is the absolute value of the seed x = -0xd201000000010000 of BLS12-381.
+const seed uint64 = 0xd201000000010000
+
+// HashToG2 returns the hash to G2 of the bytes of msg with the domain separation tag of the
+// verifier, BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380, and constrains msg to be bytes.
+func (v *Verifier) HashToG2(msg []frontend.Variable) *sw_bls12381.G2Affine {
+	u := v.hashToField(msg)
+	q0 := v.mapToCurve(&u[0])
+	q1 := v.mapToCurve(&u[1])
+
+	// the isogeny being a morphism, the points are added on the isogenous curve
+	q := v.isogeny(v.add(q0, q1))
+	return v.clearCofactor(q)
+}
+
+// expandMessage returns the 256 bytes of expand_message_xmd with SHA-256, as their bits.
+func (v *Verifier) expandMessage(msg []frontend.Variable) [8][32][]frontend.Variable {
+	const lenInBytes = 256
+	dst := make([]frontend.Variable, 0, len(v.dst)+1)
+	for _, b := range v.dst {
+		dst = append(dst, int(b))
+	}
+	dst = append(dst, len(v.dst))
+
+	data := make([]frontend.Variable, 64, 64+len(msg)+3+len(dst))
+	for i := range data {
+		data[i] = 0
+	}
+	data = append(data, msg...)
+	data = append(data, lenInBytes>>8, lenInBytes&0xff, 0)
+	data = append(data, dst...)
+	b0 := v.toBits(sha2.Sum256(v.api, data))
+
+	var res [8][32][]frontend.Variable
+	for i := range res {
+		data = make([]frontend.Variable, 32, 32+1+len(dst))
+		for j := range data {
+			if i == 0 {
+				data[j] = bits.FromBinary(v.api, b0[j], bits.WithUnconstrainedInputs())
+			} else {
+				xor := make([]frontend.Variable, 8)
+				for k := range xor {
+					xor[k] = v.api.Xor(b0[j][k], res[i-1][j][k])
+				}
+				data[j] = bits.FromBinary(v.api, xor, bits.WithUnconstrainedInputs())
+			}
+		}
+		data = append(data, i+1)
+		data = append(data, dst...)
+		res[i] = v.toBits(sha2.Sum256(v.api, data))
+	}
+	return res
+}
+
+// hashToField returns the two elements of Fp² of hash_to_field, each coordinate being
+// reduced from 64 bytes in big-endian.
+func (v *Verifier) hashToField(msg []frontend.Variable) [2]fields_bls12381.E2 {
+	uniform := v.expandMessage(msg)
+	c := v.fp.NewElement(new(big.Int).Lsh(big.NewInt(1), 256))
+	coordinate := func(i int) emulated.Element[emulated.BLS12381Fp] {
+		// the bytes i*64 to (i+1)*64 are those of the blocks 2i and 2i+1
+		hi := v.fp.FromBits(reverseBytes(uniform[2*i])...)
+		lo := v.fp.FromBits(reverseBytes(uniform[2*i+1])...)
+		return *v.fp.Reduce(v.fp.Add(lo, v.fp.MulMod(hi, c)))
+	}
+	return [2]fields_bls12381.E2{
+		{A0: coordinate(0), A1: coordinate(1)},
+		{A0: coordinate(2), A1: coordinate(3)},
+	}
+}
+
+// reverseBytes returns the bits of the bytes in big-endian, least significant first.
+func reverseBytes(b [32][]frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, 0, 256)
+	for i := len(b) - 1; i >= 0; i-- {
+		res = append(res, b[i]...)
+	}
+	return res
+}
+
+// toBits decomposes the bytes of a digest in bits, least significant first.
+func (v *Verifier) toBits(digest [32]frontend.Variable) [32][]frontend.Variable {
+	var res [32][]frontend.Variable
+	for i := range digest {
+		res[i] = bits.ToBinary(v.api, digest[i], bits.WithNbDigits(8))
+	}
+	return res
+}
+
+// mapToCurve returns the simplified SWU map of u to the curve isogenous to G2.
+//
+// The coordinates are computed by a hint: x is one of x1 = -B'/A'(1 + 1/(Z²u⁴ + Zu²)) and
+// x2 = Zu²x1, and y a square root of g(x) = x³ + A'x + B' of the same sign as u. As
+// g(x2) = Z³u⁶g(x1) and Z is not a square, g(x1) and g(x2) are not both squares, so that x
+// is the one of the map.
+func (v *Verifier) mapToCurve(u *fields_bls12381.E2) *sw_bls12381.G2Affine {
+	tv1 := v.ext2.Mul(&sswuZ, v.ext2.Square(u))
+	tv2 := v.ext2.Add(v.ext2.Square(tv1), tv1)
+	den := v.ext2.Mul(&sswuA, v.ext2.Select(v.ext2.IsZero(tv2), &sswuZ, v.ext2.Neg(tv2)))
+	x1 := v.ext2.DivUnchecked(v.ext2.Mul(&sswuB, v.ext2.Add(tv2, v.ext2.One())), den)
+	x2 := v.ext2.Mul(tv1, x1)
+
+	res, err := v.fp.NewHint(mapToCurveHint, 4, &u.A0, &u.A1, &x1.A0, &x1.A1, &x2.A0, &x2.A1)
+	if err != nil {
+		panic(fmt.Sprintf("map to curve: %v", err))
+	}
+	x := fields_bls12381.E2{A0: *res[0], A1: *res[1]}
+	y := fields_bls12381.E2{A0: *res[2], A1: *res[3]}
+	v.ext2.AssertIsEqual(v.ext2.Mul(v.ext2.Sub(&x, x1), v.ext2.Sub(&x, x2)), v.ext2.Zero())
+	gx := v.ext2.Add(v.ext2.Mul(v.ext2.Add(v.ext2.Square(&x), &sswuA), &x), &sswuB)
+	v.ext2.AssertIsEqual(v.ext2.Square(&y), gx)
+	v.api.AssertIsEqual(v.sgn0(&y), v.sgn0(u))
+	return &sw_bls12381.G2Affine{X: x, Y: y}
+}
+
+// sgn0 returns the sign of z of RFC 9380: the parity of its first non-zero coordinate.
+func (v *Verifier) sgn0(z *fields_bls12381.E2) frontend.Variable {
+	a0 := v.fp.Reduce(&z.A0)
+	v.fp.AssertIsInRange(a0)
+	a1 := v.fp.Reduce(&z.A1)
+	v.fp.AssertIsInRange(a1)
+	s0, s1 := v.fp.ToBits(a0)[0], v.fp.ToBits(a1)[0]
+	// s0 is 0 if a0 is 0
+	return v.api.Add(s0, v.api.Mul(v.fp.IsZero(a0), s1))
+}
+
+// isogeny returns the image of p by the 3-isogeny to G2.
+func (v *Verifier) isogeny(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	xNum := v.evalPolynomial(isoXNum, false, &p.X)
+	xDen := v.evalPolynomial(isoXDen, true, &p.X)
+	yNum := v.evalPolynomial(isoYNum, false, &p.X)
+	yDen := v.evalPolynomial(isoYDen, true, &p.X)
+	return &sw_bls12381.G2Affine{
+		X: *v.ext2.DivUnchecked(xNum, xDen),
+		Y: *v.ext2.DivUnchecked(v.ext2.Mul(&p.Y, yNum), yDen),
+	}
+}
+
+// evalPolynomial returns the polynomial of the coefficients at x, with the leading
+// coefficient 1 omitted if monic.
+func (v *Verifier) evalPolynomial(coefficients []fields_bls12381.E2, monic bool, x *fields_bls12381.E2) *fields_bls12381.E2 {
+	res := &coefficients[len(coefficients)-1]
+	if monic {
+		res = v.ext2.Add(res, x)
+	}
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		res = v.ext2.Add(v.ext2.Mul(res, x), &coefficients[i])
+	}
+	return res
+}
+
+// clearCofactor returns [h_eff]p with the endomorphism ψ as in RFC 9380, appendix G.3:
+// [x² - x - 1]p + [x - 1]ψ(p) + ψ²(2p).
+func (v *Verifier) clearCofactor(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	t1 := v.neg(v.mulBySeed(p))
+	t2 := v.psi(p)
+	t3 := v.psi(v.psi(v.double(p)))
+	t3 = v.add(t3, v.neg(t2))
+	t2 = v.neg(v.mulBySeed(v.add(t1, t2)))
+	t3 = v.add(t3, t2)
+	t3 = v.add(t3, v.neg(t1))
+	return v.add(t3, v.neg(p))
+}
+
+// mulBySeed returns [|x|]p, x being the seed, with a double-and-add.
+func (v *Verifier) mulBySeed(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	res := p
+	for i := 62; i >= 0; i-- {
+		res = v.double(res)
+		if seed>>i&1 == 1 {
+			res = v.add(res, p)
+		}
+	}
+	return res
+}
+
+func (v *Verifier) psi(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return &sw_bls12381.G2Affine{
+		X: *v.ext2.Mul(v.ext2.Conjugate(&p.X), &psiX),
+		Y: *v.ext2.Mul(v.ext2.Conjugate(&p.Y), &psiY),
+	}
+}
+
+func (v *Verifier) neg(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return &sw_bls12381.G2Affine{X: p.X, Y: *v.ext2.Neg(&p.Y)}
+}
+
+// add returns p + q, p and q being distinct points and not opposite, of G2 or of the
+// isogenous curve.
+func (v *Verifier) add(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	// the inverse asserts that the x-coordinates are different, so that a point of small
+	// order can't give a free slope
+	λ := v.ext2.Mul(v.ext2.Sub(&q.Y, &p.Y), v.ext2.Inverse(v.ext2.Sub(&q.X, &p.X)))
+	x := v.ext2.Sub(v.ext2.Square(λ), v.ext2.Add(&p.X, &q.X))
+	y := v.ext2.Sub(v.ext2.Mul(λ, v.ext2.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+// double returns 2p, p being a point of G2 other than (0, 0).
+func (v *Verifier) double(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	xx := v.ext2.Square(&p.X)
+	λ := v.ext2.DivUnchecked(v.ext2.Add(v.ext2.Double(xx), xx), v.ext2.Double(&p.Y))
+	x := v.ext2.Sub(v.ext2.Square(λ), v.ext2.Double(&p.X))
+	y := v.ext2.Sub(v.ext2.Mul(λ, v.ext2.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
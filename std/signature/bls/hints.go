@@ -0,0 +1,67 @@
+package bls
+
+import (
+	"errors"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hints used in this package
+func GetHints() []solver.Hint {
+	return []solver.Hint{mapToCurveHint}
+}
+
+// mapToCurveHint returns the point of the simplified SWU map of u, given the candidates x1 and
+// x2 for its x-coordinate: x1 if g(x1) is a square and x2 otherwise, and the square root of
+// g(x) of the sign of u.
+func mapToCurveHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 6 || len(outputs) != 4 {
+			return errors.New("expecting six inputs and four outputs")
+		}
+		var u, x, a, b, gx, y bls12381.E2
+		u.A0.SetBigInt(inputs[0])
+		u.A1.SetBigInt(inputs[1])
+		a.SetString("0", "240")
+		b.SetString("1012", "1012")
+
+		g := func(x *bls12381.E2) bls12381.E2 {
+			var res bls12381.E2
+			res.Square(x).Add(&res, &a).Mul(&res, x).Add(&res, &b)
+			return res
+		}
+		x.A0.SetBigInt(inputs[2])
+		x.A1.SetBigInt(inputs[3])
+		if gx = g(&x); gx.Legendre() == -1 {
+			x.A0.SetBigInt(inputs[4])
+			x.A1.SetBigInt(inputs[5])
+			if gx = g(&x); gx.Legendre() == -1 {
+				return errors.New("no square root")
+			}
+		}
+		y.Sqrt(&gx)
+		if sgn0(&y) != sgn0(&u) {
+			y.Neg(&y)
+		}
+		x.A0.BigInt(outputs[0])
+		x.A1.BigInt(outputs[1])
+		y.A0.BigInt(outputs[2])
+		y.A1.BigInt(outputs[3])
+		return nil
+	})
+}
+
+// sgn0 returns the sign of z of RFC 9380: the parity of its first non-zero coordinate.
+func sgn0(z *bls12381.E2) uint64 {
+	if z.A0.IsZero() {
+		return z.A1.Bits()[0] & 1
+	}
+	return z.A0.Bits()[0] & 1
+}
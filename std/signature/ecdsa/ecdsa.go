@@ -3,8 +3,17 @@ Package ecdsa implements ECDSA signature verification over any elliptic curve.
 
 The package depends on the [emulated/sw_emulated] package for elliptic curve group
 operations using non-native arithmetic. Thus we can verify ECDSA signatures over
-any curve. The cost for a single secp256k1 signature verification is
-approximately 4M constraints in R1CS and 10M constraints in PLONKish.
+any curve. The limbs of the non-native elements are range checked by the
+[github.com/consensys/gnark/std/rangecheck] package, which batches the checks
+in a lookup argument against a commitment with the builders implementing
+[frontend.Committer]. The cost for a single secp256k1 signature verification
+over BN254 is then approximately 420k constraints in R1CS and 2M constraints in
+PLONKish.
+
+[RecoverPublicKey] recovers the public key of a signature given its recovery
+identifier, as returned by SignForRecover of gnark-crypto, for the same cost as
+the verification. [RecoverEthereumPublicKey] does the same for the recovery
+identifiers of Ethereum, as the ECRECOVER precompile.
 
 See [ECDSA] for the signature verification algorithm.
 
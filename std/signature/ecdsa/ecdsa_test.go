@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
 	"github.com/consensys/gnark-crypto/ecc/secp256k1/ecdsa"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
 	"github.com/consensys/gnark/std/math/emulated"
@@ -116,6 +119,239 @@ func TestEcdsaSHA256(t *testing.T) {
 	assert.NoError(err)
 }
 
+type RecoverCircuit[T, S emulated.FieldParams] struct {
+	Sig Signature[S]
+	Msg emulated.Element[S]
+	V   frontend.Variable
+	Pub PublicKey[T, S]
+}
+
+func (c *RecoverCircuit[T, S]) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[T, S](api, sw_emulated.GetCurveParams[T]())
+	if err != nil {
+		return err
+	}
+	pk := RecoverPublicKey[T, S](api, sw_emulated.GetCurveParams[T](), &c.Msg, c.V, &c.Sig)
+	expected := sw_emulated.AffinePoint[T](c.Pub)
+	curve.AssertIsEqual((*sw_emulated.AffinePoint[T])(pk), &expected)
+	return nil
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	assert := test.NewAssert(t)
+	privKey, _ := ecdsa.GenerateKey(rand.Reader)
+
+	msg := []byte("testing ECDSA (recovery)")
+	md := sha256.New()
+	v, r, s, err := privKey.SignForRecover(msg, md)
+	assert.NoError(err)
+	md.Reset()
+	md.Write(msg)
+	hash := ecdsa.HashToInt(md.Sum(nil))
+
+	witness := func(v uint) *RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr] {
+		return &RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			Sig: Signature[emulated.Secp256k1Fr]{
+				R: emulated.ValueOf[emulated.Secp256k1Fr](r),
+				S: emulated.ValueOf[emulated.Secp256k1Fr](s),
+			},
+			Msg: emulated.ValueOf[emulated.Secp256k1Fr](hash),
+			V:   v,
+			Pub: PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+				X: emulated.ValueOf[emulated.Secp256k1Fp](privKey.PublicKey.A.X),
+				Y: emulated.ValueOf[emulated.Secp256k1Fp](privKey.PublicKey.A.Y),
+			},
+		}
+	}
+	circuit := RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}
+	assert.NoError(test.IsSolved(&circuit, witness(v), ecc.BN254.ScalarField()))
+
+	// the other root of R recovers another key
+	assert.Error(test.IsSolved(&circuit, witness(v^1), ecc.BN254.ScalarField()))
+}
+
+func TestRecoverPublicKeyRejects(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, n := fp.Modulus(), fr.Modulus()
+	msg := big.NewInt(42)
+
+	// unreduced returns the element of v, without reducing it modulo n as ValueOf does
+	unreduced := func(v *big.Int) emulated.Element[emulated.Secp256k1Fr] {
+		var fr emulated.Secp256k1Fr
+		limbs := make([]frontend.Variable, fr.NbLimbs())
+		mask := new(big.Int).Lsh(big.NewInt(1), fr.BitsPerLimb())
+		mask.Sub(mask, big.NewInt(1))
+		for i := range limbs {
+			limbs[i] = new(big.Int).And(new(big.Int).Rsh(v, uint(i)*fr.BitsPerLimb()), mask)
+		}
+		return emulated.Element[emulated.Secp256k1Fr]{Limbs: limbs}
+	}
+	// witness returns the witness of the signature (r, s) of msg, whose point R has the
+	// x-coordinate x and the smallest y-coordinate, with the key r⁻¹(sR - mG) it recovers
+	witness := func(x, r, s *big.Int, v int) *RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr] {
+		var R secp256k1.G1Affine
+		y := new(big.Int).Exp(x, big.NewInt(3), p)
+		y.Add(y, big.NewInt(7))
+		if y.ModSqrt(y, p) == nil {
+			t.Fatalf("%s isn't the x-coordinate of a point", x)
+		}
+		if y.Cmp(new(big.Int).Rsh(p, 1)) > 0 {
+			y.Sub(p, y)
+		}
+		R.X.SetBigInt(x)
+		R.Y.SetBigInt(y)
+
+		var pub secp256k1.G1Affine
+		if r.Sign() != 0 {
+			rInv := new(big.Int).ModInverse(r, n)
+			u1 := new(big.Int).Mul(msg, rInv)
+			u1.Neg(u1).Mod(u1, n)
+			u2 := new(big.Int).Mul(s, rInv)
+			u2.Mod(u2, n)
+			var sR secp256k1.G1Affine
+			pub.ScalarMultiplicationBase(u1)
+			sR.ScalarMultiplication(&R, u2)
+			pub.Add(&pub, &sR)
+		}
+		return &RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			Sig: Signature[emulated.Secp256k1Fr]{
+				R: unreduced(r),
+				S: unreduced(s),
+			},
+			Msg: emulated.ValueOf[emulated.Secp256k1Fr](msg),
+			V:   v,
+			Pub: PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+				X: emulated.ValueOf[emulated.Secp256k1Fp](pub.X.BigInt(new(big.Int))),
+				Y: emulated.ValueOf[emulated.Secp256k1Fp](pub.Y.BigInt(new(big.Int))),
+			},
+		}
+	}
+	// onCurve returns the smallest x-coordinate of a point from x, plus offset
+	onCurve := func(x, offset *big.Int) *big.Int {
+		x = new(big.Int).Set(x)
+		for {
+			rhs := new(big.Int).Add(x, offset)
+			rhs.Exp(rhs, big.NewInt(3), p).Add(rhs, big.NewInt(7))
+			if big.Jacobi(rhs, p) == 1 {
+				return x
+			}
+			x.Add(x, big.NewInt(1))
+		}
+	}
+	circuit := RecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}
+	one, zero := big.NewInt(1), big.NewInt(0)
+
+	r := onCurve(one, zero)
+	assert.NoError(test.IsSolved(&circuit, witness(r, r, one, 0), ecc.BN254.ScalarField()))
+
+	// s + n is equal to s modulo n, but out of range
+	assert.Error(test.IsSolved(&circuit, witness(r, r, new(big.Int).Add(one, n), 0), ecc.BN254.ScalarField()))
+
+	// s and r are not zero
+	assert.Error(test.IsSolved(&circuit, witness(r, r, zero, 0), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(&circuit, witness(r, zero, one, 0), ecc.BN254.ScalarField()))
+
+	// the x-coordinate of R is r + n, below p
+	rOverflow := onCurve(one, n)
+	x := new(big.Int).Add(rOverflow, n)
+	assert.NoError(test.IsSolved(&circuit, witness(x, rOverflow, one, 2), ecc.BN254.ScalarField()))
+
+	// r + n is at least p: it would be reduced to the x-coordinate of another point
+	rOverflow = new(big.Int).Sub(p, n)
+	rOverflow.Add(rOverflow, r)
+	assert.Error(test.IsSolved(&circuit, witness(r, rOverflow, one, 2), ecc.BN254.ScalarField()))
+}
+
+type EthereumRecoverCircuit[T, S emulated.FieldParams] struct {
+	Sig Signature[S]
+	Msg emulated.Element[S]
+	V   frontend.Variable
+	Pub PublicKey[T, S]
+}
+
+func (c *EthereumRecoverCircuit[T, S]) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[T, S](api, sw_emulated.GetCurveParams[T]())
+	if err != nil {
+		return err
+	}
+	pk := RecoverEthereumPublicKey[T, S](api, sw_emulated.GetCurveParams[T](), &c.Msg, c.V, &c.Sig)
+	expected := sw_emulated.AffinePoint[T](c.Pub)
+	curve.AssertIsEqual((*sw_emulated.AffinePoint[T])(pk), &expected)
+	return nil
+}
+
+func TestRecoverEthereumPublicKey(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// signatures of keccak256("gnark ECDSA recovery") by crypto.Sign of go-ethereum, with
+	// v = 27 + the last byte of the signature; the last key is the one of the address
+	// 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266
+	const hash = "bfff738d4639034cee96364ce045f2a299c6c923058cdc221ee95636f1f98293"
+	vectors := []struct {
+		r, s string
+		v    int
+		x, y string
+	}{
+		{
+			r: "bb1a63134bd7caf9af53260d372c8addfb471c3d40ba86901489fb47083752ac",
+			s: "651bc682ed9b70945de585ee4a2d62aa72c3d8b84c30afe8316b6954e5525ffd",
+			v: 28,
+			x: "4e3b81af9c2234cad09d679ce6035ed1392347ce64ce405f5dcd36228a25de6e",
+			y: "47fd35c4215d1edf53e6f83de344615ce719bdb0fd878f6ed76f06dd277956de",
+		},
+		{
+			r: "753ba7c8d19c0adc517b7c8d493adabc175e675334032e28cf5ebf8b95e00508",
+			s: "0a2f6e87b08f2d63621620d34df6c5582bd66066b1a388c524d979756ae9e52d",
+			v: 28,
+			x: "ca634cae0d49acb401d8a4c6b6fe8c55b70d115bf400769cc1400f3258cd3138",
+			y: "7574077f301b421bc84df7266c44e9e6d569fc56be00812904767bf5ccd1fc7f",
+		},
+		{
+			r: "f30122c924da492ba9a1417448fb70c215c1f4ed5aaec36da8d9420801204018",
+			s: "3b3ab89a51c4dca69835fce2accbfa02b56d387b3429d99665e714c02e148de8",
+			v: 27,
+			x: "7db227d7094ce215c3a0f57e1bcc732551fe351f94249471934567e0f5dc1bf7",
+			y: "95962b8cccb87a2eb56b29fbe37d614e2f4c3c45b789ae4f1f51f4cb21972ffd",
+		},
+		{
+			r: "ccd624a1108f8425225bf2f178f3697a61d4b229401207be2defc44d843d7c88",
+			s: "474771c9cfd11916acde731ded6280ff1167c4dadc078c5b574fe7d3249b1bd4",
+			v: 27,
+			x: "8318535b54105d4a7aae60c08fc45f9687181b4fdfc625bd1a753fa7397fed75",
+			y: "3547f11ca8696646f2f3acb08e31016afac23e630c5d11f59f61fef57b0d2aa5",
+		},
+	}
+	hex := func(s string) *big.Int {
+		res, ok := new(big.Int).SetString(s, 16)
+		if !ok {
+			t.Fatalf("invalid hex %q", s)
+		}
+		return res
+	}
+	circuit := EthereumRecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}
+	for _, vec := range vectors {
+		witness := func(v int) *EthereumRecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr] {
+			return &EthereumRecoverCircuit[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+				Sig: Signature[emulated.Secp256k1Fr]{
+					R: emulated.ValueOf[emulated.Secp256k1Fr](hex(vec.r)),
+					S: emulated.ValueOf[emulated.Secp256k1Fr](hex(vec.s)),
+				},
+				Msg: emulated.ValueOf[emulated.Secp256k1Fr](hex(hash)),
+				V:   v,
+				Pub: PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+					X: emulated.ValueOf[emulated.Secp256k1Fp](hex(vec.x)),
+					Y: emulated.ValueOf[emulated.Secp256k1Fp](hex(vec.y)),
+				},
+			}
+		}
+		assert.NoError(test.IsSolved(&circuit, witness(vec.v), ecc.BN254.ScalarField()))
+
+		// the other parity recovers another key, and v is 27 or 28
+		assert.Error(test.IsSolved(&circuit, witness(55-vec.v), ecc.BN254.ScalarField()))
+		assert.Error(test.IsSolved(&circuit, witness(vec.v-27), ecc.BN254.ScalarField()))
+	}
+}
+
 // Example how to verify the signature inside the circuit.
 func ExamplePublicKey_Verify() {
 	api := frontend.API(nil) // provider by the builder
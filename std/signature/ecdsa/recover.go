@@ -0,0 +1,138 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hints used in this package
+func GetHints() []solver.Hint {
+	return []solver.Hint{lowSqrtHint}
+}
+
+// RecoverPublicKey returns the public key for which the signature sig of the message msg
+// verifies, given the recovery identifier v of the signature, in [0, 3]. The curve parameters
+// params define the elliptic curve.
+//
+// The identifier has the convention of SignForRecover of gnark-crypto: its bit 1 is set if the
+// x-coordinate of the point R of the signature is r + n instead of r, n being the order of the
+// curve, and its bit 0 is set if the y-coordinate of R is the larger of the two square roots.
+// Ethereum sets bit 0 to the parity of the y-coordinate instead: see RecoverEthereumPublicKey.
+//
+// The public key is computed as r⁻¹(sR - mG) in the circuit, so the recovery costs the same
+// as Verify: two scalar multiplications. As for Verify, we assume that the message msg is
+// already hashed to the scalar field.
+func RecoverPublicKey[T, S emulated.FieldParams](api frontend.API, params sw_emulated.CurveParams, msg *emulated.Element[S], v frontend.Variable, sig *Signature[S]) *PublicKey[T, S] {
+	vBits := bits.ToBinary(api, v, bits.WithNbDigits(2))
+	return recoverPublicKey[T, S](api, params, msg, sig, vBits[1], func(baseApi *emulated.Field[T], y *emulated.Element[T]) frontend.Variable {
+		return vBits[0]
+	})
+}
+
+// RecoverEthereumPublicKey returns the public key for which the signature sig of the message
+// msg verifies, given the recovery identifier v of the signature as Ethereum defines it (the
+// one of ecrecover), in {27, 28}: v - 27 is the parity of the y-coordinate of the point R of
+// the signature, whose x-coordinate is r. The curve parameters params define the elliptic
+// curve, secp256k1 for Ethereum.
+//
+// As for RecoverPublicKey, we assume that the message msg is already hashed to the scalar
+// field, with Keccak-256 for Ethereum.
+func RecoverEthereumPublicKey[T, S emulated.FieldParams](api frontend.API, params sw_emulated.CurveParams, msg *emulated.Element[S], v frontend.Variable, sig *Signature[S]) *PublicKey[T, S] {
+	parity := api.Sub(v, 27)
+	api.AssertIsBoolean(parity)
+	return recoverPublicKey[T, S](api, params, msg, sig, 0, func(baseApi *emulated.Field[T], y *emulated.Element[T]) frontend.Variable {
+		// p being odd, -y = p - y has the other parity: y is canonical, at most (p-1)/2
+		return api.Xor(baseApi.ToBits(y)[0], parity)
+	})
+}
+
+// recoverPublicKey returns r⁻¹(sR - mG), where the x-coordinate of R is r + xOverflow·n and
+// its y-coordinate is -y if negY returns 1 for the square root y at most (p-1)/2, y otherwise.
+func recoverPublicKey[T, S emulated.FieldParams](api frontend.API, params sw_emulated.CurveParams, msg *emulated.Element[S], sig *Signature[S], xOverflow frontend.Variable, negY func(*emulated.Field[T], *emulated.Element[T]) frontend.Variable) *PublicKey[T, S] {
+	cr, err := sw_emulated.New[T, S](api, params)
+	if err != nil {
+		panic(err)
+	}
+	scalarApi, err := emulated.NewField[S](api)
+	if err != nil {
+		panic(err)
+	}
+	baseApi, err := emulated.NewField[T](api)
+	if err != nil {
+		panic(err)
+	}
+	var fr S
+	var fp T
+
+	// r and s are in [1, n-1], as Verify of gnark-crypto requires: s + n would otherwise
+	// recover the key of s, and s = 0 the key -m·r⁻¹·G of no signature. r = 0 has no inverse.
+	scalarApi.AssertIsInRange(&sig.R)
+	scalarApi.AssertIsInRange(&sig.S)
+	scalarApi.Inverse(&sig.S)
+
+	// R.X = r + xOverflow·n, with r < n so that the x-coordinates of R are distinct, and
+	// r + n < p when xOverflow is set, so that R.X isn't reduced to another x-coordinate
+	r := baseApi.FromBits(scalarApi.ToBits(&sig.R)...)
+	maxR := new(big.Int).Sub(fp.Modulus(), fr.Modulus())
+	if maxR.Sign() <= 0 {
+		api.AssertIsEqual(xOverflow, 0)
+	} else {
+		maxR.Sub(maxR, big.NewInt(1))
+		baseApi.AssertIsLessOrEqual(r, baseApi.Select(xOverflow, baseApi.NewElement(maxR), baseApi.NewElement(new(big.Int).Sub(fp.Modulus(), big.NewInt(1)))))
+	}
+	rx := baseApi.Add(r, baseApi.Select(xOverflow, baseApi.NewElement(fr.Modulus()), baseApi.Zero()))
+
+	// R.Y is a square root of rx³ + a·rx + b, the smallest one y (at most (p-1)/2) or -y
+	rhs := baseApi.MulMod(baseApi.MulMod(rx, rx), rx)
+	if params.A.Sign() != 0 {
+		rhs = baseApi.Add(rhs, baseApi.MulMod(rx, baseApi.NewElement(params.A)))
+	}
+	rhs = baseApi.Add(rhs, baseApi.NewElement(params.B))
+	res, err := baseApi.NewHint(lowSqrtHint, 1, rhs)
+	if err != nil {
+		panic(fmt.Sprintf("square root: %v", err))
+	}
+	y := res[0]
+	baseApi.AssertIsEqual(baseApi.Mul(y, y), rhs)
+	y = baseApi.Reduce(y)
+	halfP := new(big.Int).Rsh(fp.Modulus(), 1)
+	baseApi.AssertIsLessOrEqual(y, baseApi.NewElement(halfP))
+	R := sw_emulated.AffinePoint[T]{
+		X: *baseApi.Reduce(rx),
+		Y: *baseApi.Reduce(baseApi.Select(negY(baseApi, y), baseApi.Neg(y), y)),
+	}
+
+	// P = -m·r⁻¹·G + s·r⁻¹·R
+	rInv := scalarApi.Inverse(&sig.R)
+	u1 := scalarApi.Neg(scalarApi.MulMod(msg, rInv))
+	u2 := scalarApi.MulMod(&sig.S, rInv)
+	P := cr.AddUnified(cr.ScalarMulBase(u1), cr.ScalarMul(&R, u2))
+	pk := PublicKey[T, S](*P)
+	return &pk
+}
+
+// lowSqrtHint returns the square root of its input at most (p-1)/2.
+func lowSqrtHint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 1 || len(outputs) != 1 {
+			return fmt.Errorf("expecting single input and output")
+		}
+		if outputs[0].ModSqrt(inputs[0], field) == nil {
+			return fmt.Errorf("no square root")
+		}
+		if outputs[0].Cmp(new(big.Int).Rsh(field, 1)) > 0 {
+			outputs[0].Sub(field, outputs[0])
+		}
+		return nil
+	})
+}
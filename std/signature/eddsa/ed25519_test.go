@@ -0,0 +1,90 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type ed25519Circuit struct {
+	PublicKey Ed25519PublicKey
+	Signature Ed25519Signature
+	Message   []frontend.Variable
+}
+
+func (c *ed25519Circuit) Define(api frontend.API) error {
+	return VerifyEd25519(api, c.Signature, c.Message, c.PublicKey)
+}
+
+func ed25519Witness(t *testing.T, pk, msg, sig []byte) (*ed25519Circuit, *ed25519Circuit) {
+	var witness ed25519Circuit
+	witness.PublicKey.Assign(pk)
+	witness.Signature.Assign(sig)
+	witness.Message = Ed25519Message(msg)
+	return &ed25519Circuit{Message: make([]frontend.Variable, len(msg))}, &witness
+}
+
+func TestEd25519(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the test vectors of section 7.1 of RFC 8032
+	for _, v := range []struct{ sk, pk, msg, sig string }{
+		{
+			"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"",
+			"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		},
+		{
+			"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			"72",
+			"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00",
+		},
+		{
+			"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			"af82",
+			"6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac18ff9b538d16f290ae67f760984dc6594a7c15e9716ed28dc027beceea1ec40a",
+		},
+	} {
+		sk, _ := hex.DecodeString(v.sk)
+		pk, _ := hex.DecodeString(v.pk)
+		msg, _ := hex.DecodeString(v.msg)
+		sig, _ := hex.DecodeString(v.sig)
+		assert.Equal(ed25519.PublicKey(pk), ed25519.NewKeyFromSeed(sk).Public())
+		assert.Equal(sig, ed25519.Sign(ed25519.NewKeyFromSeed(sk), msg))
+
+		circuit, witness := ed25519Witness(t, pk, msg, sig)
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "message %q", v.msg)
+	}
+}
+
+func TestEd25519Invalid(t *testing.T) {
+	assert := test.NewAssert(t)
+	sk := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pk := sk.Public().(ed25519.PublicKey)
+	msg := []byte("gnark")
+	sig := ed25519.Sign(sk, msg)
+
+	circuit, witness := ed25519Witness(t, pk, msg, sig)
+	assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// another message
+	_, witness = ed25519Witness(t, pk, []byte("gnarl"), sig)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// S + l, accepted by the group equation but not reduced
+	malleable := append([]byte{}, sig...)
+	var carry int
+	for i, b := range []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10} {
+		s := int(malleable[32+i]) + int(b) + carry
+		malleable[32+i], carry = byte(s), s>>8
+	}
+	_, witness = ed25519Witness(t, pk, msg, malleable)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
@@ -15,6 +15,9 @@ limitations under the License.
 */
 
 // Package eddsa provides a ZKP-circuit function to verify a EdDSA signature.
+//
+// Verify verifies the signatures over the twisted Edwards curves defined over the native
+// field, and VerifyEd25519 the Ed25519 signatures of RFC 8032 with emulated arithmetic.
 package eddsa
 
 import (
@@ -0,0 +1,14 @@
+// Package testvectors bundles the published test vectors of the primitives implemented by the
+// gadgets of the standard library, and runners checking a gadget against them.
+//
+// The vectors are the ones of the specifications: FIPS 180-4 examples for SHA-256, the
+// Keccak-256 of Ethereum, RFC 9380 for expand_message_xmd, RFC 8032 for Ed25519 and BIP-340
+// for the Schnorr signatures of secp256k1. The Keccak-256 vectors also hash messages around the
+// 136-byte rate, where the padding spans blocks.
+//
+// A runner solves the circuit of the gadget with the test engine for every vector, checks
+// that a wrong expected output is rejected, and proves a sample of the vectors with Groth16 on
+// BN254, the prover running on the accelerator (see
+// [github.com/consensys/gnark/backend/accel]). The gadgets take and return byte strings, as
+// slices of variables holding one byte each, whatever the field.
+package testvectors
@@ -0,0 +1,218 @@
+package testvectors
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// HashFunc is a hash gadget, returning the bytes of the hash of the first length bytes of msg.
+type HashFunc func(api frontend.API, msg []frontend.Variable, length frontend.Variable) []frontend.Variable
+
+// ExpandMsgFunc is a message expansion gadget, returning the lenInBytes bytes of the expansion
+// of the first length bytes of msg with the domain separation tag dst.
+type ExpandMsgFunc func(api frontend.API, msg []frontend.Variable, length frontend.Variable, dst []byte, lenInBytes int) []frontend.Variable
+
+// VerifyFunc is a signature verification gadget, returning 1 if signature is a valid signature
+// of the first length bytes of msg for the public key, and 0 otherwise.
+type VerifyFunc func(api frontend.API, publicKey, msg []frontend.Variable, length frontend.Variable, signature []frontend.Variable) frontend.Variable
+
+// Option configures a runner.
+type Option func(*config)
+
+type config struct {
+	sample      int
+	provingOpts []test.TestingOption
+}
+
+// WithSample sets the number of vectors, the first ones, proven with Groth16 on BN254. It is 1
+// by default, and 0 only checks the vectors with the test engine.
+func WithSample(n int) Option {
+	return func(cfg *config) {
+		cfg.sample = n
+	}
+}
+
+// WithProvingOptions adds testing options to the proofs of the sample, for instance to prove
+// with other backends or on other curves.
+func WithProvingOptions(opts ...test.TestingOption) Option {
+	return func(cfg *config) {
+		cfg.provingOpts = append(cfg.provingOpts, opts...)
+	}
+}
+
+// RunHash checks the hash gadget against the vectors. The messages are given to the gadget in
+// a slice of the length of the longest one, followed by zeros.
+func RunHash(assert *test.Assert, hash HashFunc, vectors []Hash, opts ...Option) {
+	maxLen := 0
+	for _, v := range vectors {
+		if len(v.Msg) > maxLen {
+			maxLen = len(v.Msg)
+		}
+	}
+
+	cases := make([]testCase, len(vectors))
+	for i, v := range vectors {
+		cases[i] = bytesCase(v.Name, hash, v.Msg, maxLen, v.Digest)
+	}
+	run(assert, cases, opts)
+}
+
+// RunExpandMsg checks the message expansion gadget against the vectors. The messages are
+// given to the gadget in a slice of the length of the longest one, followed by zeros.
+func RunExpandMsg(assert *test.Assert, expand ExpandMsgFunc, vectors []ExpandMsg, opts ...Option) {
+	maxLen := 0
+	for _, v := range vectors {
+		if len(v.Msg) > maxLen {
+			maxLen = len(v.Msg)
+		}
+	}
+
+	cases := make([]testCase, len(vectors))
+	for i, v := range vectors {
+		dst, lenInBytes := v.DST, len(v.Uniform)
+		f := func(api frontend.API, msg []frontend.Variable, length frontend.Variable) []frontend.Variable {
+			return expand(api, msg, length, dst, lenInBytes)
+		}
+		cases[i] = bytesCase(v.Name, f, v.Msg, maxLen, v.Uniform)
+	}
+	run(assert, cases, opts)
+}
+
+// RunSignature checks the signature verification gadget against the vectors, valid or not. The
+// messages are given to the gadget in a slice of the length of the longest one, followed by
+// zeros.
+func RunSignature(assert *test.Assert, verify VerifyFunc, vectors []Signature, opts ...Option) {
+	maxLen := 0
+	for _, v := range vectors {
+		if len(v.Msg) > maxLen {
+			maxLen = len(v.Msg)
+		}
+	}
+
+	cases := make([]testCase, len(vectors))
+	for i, v := range vectors {
+		circuit := &signatureCircuit{
+			PublicKey: make([]frontend.Variable, len(v.PublicKey)),
+			Msg:       make([]frontend.Variable, maxLen),
+			Signature: make([]frontend.Variable, len(v.Signature)),
+			verify:    &verify,
+		}
+		assignment := func(valid bool) *signatureCircuit {
+			return &signatureCircuit{
+				PublicKey: toVariables(v.PublicKey, len(v.PublicKey)),
+				Msg:       toVariables(v.Msg, maxLen),
+				Length:    len(v.Msg),
+				Signature: toVariables(v.Signature, len(v.Signature)),
+				Valid:     toBit(valid),
+			}
+		}
+		cases[i] = testCase{v.Name, circuit, assignment(v.Valid), assignment(!v.Valid)}
+	}
+	run(assert, cases, opts)
+}
+
+// testCase is the circuit of a vector, with the assignment of the vector, and one with a wrong
+// output.
+type testCase struct {
+	name           string
+	circuit        frontend.Circuit
+	valid, invalid frontend.Circuit
+}
+
+func run(assert *test.Assert, cases []testCase, opts []Option) {
+	cfg := config{sample: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	provingOpts := append([]test.TestingOption{
+		test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16), test.NoFuzzing(), test.NoSerialization(),
+	}, cfg.provingOpts...)
+
+	for i, c := range cases {
+		assert.Run(func(assert *test.Assert) {
+			field := ecc.BN254.ScalarField()
+			assert.NoError(test.IsSolved(c.circuit, c.valid, field), "the vector isn't satisfied")
+			assert.Error(test.IsSolved(c.circuit, c.invalid, field), "a wrong output is satisfied")
+			if i < cfg.sample {
+				assert.ProverSucceeded(c.circuit, c.valid, provingOpts...)
+			}
+		}, c.name)
+	}
+}
+
+// bytesCase returns the case of a gadget mapping the first bytes of in, given in inLen
+// variables, to out.
+func bytesCase(name string, f HashFunc, in []byte, inLen int, out []byte) testCase {
+	circuit := &bytesCircuit{
+		In:  make([]frontend.Variable, inLen),
+		Out: make([]frontend.Variable, len(out)),
+		f:   &f,
+	}
+	wrong := append([]byte{}, out...)
+	if len(wrong) != 0 {
+		wrong[0] ^= 1
+	}
+	assignment := func(out []byte) *bytesCircuit {
+		return &bytesCircuit{In: toVariables(in, inLen), Length: len(in), Out: toVariables(out, len(out))}
+	}
+	return testCase{name, circuit, assignment(out), assignment(wrong)}
+}
+
+type bytesCircuit struct {
+	In     []frontend.Variable
+	Length frontend.Variable
+	Out    []frontend.Variable `gnark:",public"`
+
+	// the gadget is referenced by a pointer, as the test engine checks its clones of the
+	// circuit are deeply equal, which func values never are
+	f *HashFunc
+}
+
+func (c *bytesCircuit) Define(api frontend.API) error {
+	out := (*c.f)(api, c.In, c.Length)
+	if len(out) != len(c.Out) {
+		return fmt.Errorf("the gadget returns %d bytes, the vector has %d", len(out), len(c.Out))
+	}
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Out[i])
+	}
+	return nil
+}
+
+type signatureCircuit struct {
+	PublicKey []frontend.Variable
+	Msg       []frontend.Variable
+	Length    frontend.Variable
+	Signature []frontend.Variable
+	Valid     frontend.Variable `gnark:",public"`
+
+	verify *VerifyFunc
+}
+
+func (c *signatureCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual((*c.verify)(api, c.PublicKey, c.Msg, c.Length, c.Signature), c.Valid)
+	return nil
+}
+
+// toVariables returns the bytes of b as n variables, followed by zeros.
+func toVariables(b []byte, n int) []frontend.Variable {
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = 0
+		if i < len(b) {
+			res[i] = b[i]
+		}
+	}
+	return res
+}
+
+func toBit(b bool) frontend.Variable {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+package testvectors
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/evm"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestSHA256(t *testing.T) {
+	for _, v := range SHA256 {
+		digest := sha256.Sum256(v.Msg)
+		assert.Equal(t, v.Digest, digest[:], v.Name)
+	}
+}
+
+func TestKeccak256(t *testing.T) {
+	for _, v := range Keccak256 {
+		h := sha3.NewLegacyKeccak256()
+		h.Write(v.Msg)
+		assert.Equal(t, v.Digest, h.Sum(nil), v.Name)
+	}
+}
+
+func TestExpandMsgXMDSHA256(t *testing.T) {
+	for _, v := range ExpandMsgXMDSHA256 {
+		assert.Equal(t, v.Uniform, expandMsgXMD(v.Msg, v.DST, len(v.Uniform)), v.Name)
+	}
+}
+
+func TestEd25519(t *testing.T) {
+	for _, v := range Ed25519 {
+		sk := ed25519.NewKeyFromSeed(v.SecretKey)
+		assert.Equal(t, v.PublicKey, []byte(sk.Public().(ed25519.PublicKey)), v.Name)
+		assert.Equal(t, v.Signature, ed25519.Sign(sk, v.Msg), v.Name)
+		assert.Equal(t, v.Valid, ed25519.Verify(v.PublicKey, v.Msg, v.Signature), v.Name)
+	}
+}
+
+func TestBIP340(t *testing.T) {
+	for _, v := range BIP340 {
+		if len(v.SecretKey) != 0 {
+			pk, sig := signBIP340(v.SecretKey, v.Msg, v.AuxRand)
+			assert.Equal(t, v.PublicKey, pk, v.Name)
+			assert.Equal(t, v.Signature, sig, v.Name)
+		}
+		assert.Equal(t, v.Valid, verifyBIP340(v.PublicKey, v.Msg, v.Signature), v.Name)
+	}
+}
+
+func TestKeccak256Gadget(t *testing.T) {
+	var opts []Option
+	if testing.Short() {
+		opts = append(opts, WithSample(0))
+	}
+	RunHash(test.NewAssert(t), func(api frontend.API, msg []frontend.Variable, length frontend.Variable) []frontend.Variable {
+		digest := evm.Keccak256(api, msg, length)
+		return digest[:]
+	}, Keccak256, opts...)
+}
+
+// expandMsgXMD is expand_message_xmd with SHA-256 of RFC 9380, section 5.3.1.
+func expandMsgXMD(msg, dst []byte, lenInBytes int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	var res, bi []byte
+	for i := 1; len(res) < lenInBytes; i++ {
+		h.Reset()
+		if i == 1 {
+			h.Write(b0)
+		} else {
+			for j := range bi {
+				bi[j] ^= b0[j]
+			}
+			h.Write(bi)
+		}
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		res = append(res, bi...)
+	}
+	return res[:lenInBytes]
+}
+
+// the reference implementation of BIP-340, on secp256k1 in affine coordinates, nil being the
+// point at infinity.
+
+var (
+	secpP  = hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	secpN  = hexInt("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	secpGx = hexInt("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	secpGy = hexInt("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+)
+
+type point struct{ x, y *big.Int }
+
+func signBIP340(sk, msg, auxRand []byte) (pk, sig []byte) {
+	d := new(big.Int).SetBytes(sk)
+	p := mul(&point{secpGx, secpGy}, d)
+	if p.y.Bit(0) == 1 {
+		d.Sub(secpN, d)
+	}
+	t := taggedHash("BIP0340/aux", auxRand)
+	for i, b := range bytes32(d) {
+		t[i] ^= b
+	}
+	k := new(big.Int).SetBytes(taggedHash("BIP0340/nonce", t, bytes32(p.x), msg))
+	k.Mod(k, secpN)
+	r := mul(&point{secpGx, secpGy}, k)
+	if r.y.Bit(0) == 1 {
+		k.Sub(secpN, k)
+	}
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", bytes32(r.x), bytes32(p.x), msg))
+	e.Mul(e, d).Add(e, k).Mod(e, secpN)
+	return bytes32(p.x), append(bytes32(r.x), bytes32(e)...)
+}
+
+func verifyBIP340(pk, msg, sig []byte) bool {
+	p := liftX(new(big.Int).SetBytes(pk))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if p == nil || r.Cmp(secpP) >= 0 || s.Cmp(secpN) >= 0 {
+		return false
+	}
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], pk, msg))
+	e.Mod(e, secpN).Sub(secpN, e)
+	rr := add(mul(&point{secpGx, secpGy}, s), mul(p, e))
+	return rr != nil && rr.y.Bit(0) == 0 && rr.x.Cmp(r) == 0
+}
+
+func taggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func liftX(x *big.Int) *point {
+	if x.Cmp(secpP) >= 0 {
+		return nil
+	}
+	c := new(big.Int).Exp(x, big.NewInt(3), secpP)
+	c.Add(c, big.NewInt(7)).Mod(c, secpP)
+	y := new(big.Int).ModSqrt(c, secpP)
+	if y == nil {
+		return nil
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(secpP, y)
+	}
+	return &point{x, y}
+}
+
+func add(p, q *point) *point {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+	l := new(big.Int)
+	if p.x.Cmp(q.x) == 0 {
+		if p.y.Cmp(q.y) != 0 {
+			return nil
+		}
+		l.Mul(p.x, p.x).Mul(l, big.NewInt(3))
+		l.Mul(l, new(big.Int).ModInverse(new(big.Int).Lsh(p.y, 1), secpP))
+	} else {
+		l.Sub(q.y, p.y)
+		l.Mul(l, new(big.Int).ModInverse(new(big.Int).Sub(q.x, p.x), secpP))
+	}
+	l.Mod(l, secpP)
+	x := new(big.Int).Mul(l, l)
+	x.Sub(x, p.x).Sub(x, q.x).Mod(x, secpP)
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, l).Sub(y, p.y).Mod(y, secpP)
+	return &point{x, y}
+}
+
+func mul(p *point, k *big.Int) *point {
+	var res *point
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		res = add(res, res)
+		if k.Bit(i) == 1 {
+			res = add(res, p)
+		}
+	}
+	return res
+}
+
+func bytes32(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, 32))
+}
+
+func hexInt(s string) *big.Int {
+	x, _ := new(big.Int).SetString(s, 16)
+	return x
+}
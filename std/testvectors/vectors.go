@@ -0,0 +1,191 @@
+package testvectors
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// Hash is a test vector of a hash function.
+type Hash struct {
+	Name   string
+	Msg    []byte
+	Digest []byte
+}
+
+// ExpandMsg is a test vector of a message expansion of hash-to-curve, the length of the
+// expansion being len(Uniform).
+type ExpandMsg struct {
+	Name    string
+	DST     []byte
+	Msg     []byte
+	Uniform []byte
+}
+
+// Signature is a test vector of a signature scheme, valid or not. SecretKey (the seed of
+// Ed25519) and AuxRand (the auxiliary randomness of BIP-340) are empty for the vectors which
+// only test the verification.
+type Signature struct {
+	Name      string
+	SecretKey []byte
+	AuxRand   []byte
+	PublicKey []byte
+	Msg       []byte
+	Signature []byte
+	Valid     bool
+}
+
+// SHA256 are the examples of FIPS 180-4 for SHA-256: the one-block and two-block messages
+// and the empty message.
+var SHA256 = []Hash{
+	{"empty", nil, h("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")},
+	{"abc", []byte("abc"), h("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")},
+	{"448 bits", []byte(msg448), h("248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1")},
+	{"896 bits", []byte(msg896), h("cf5b16a778af8380036ce59e7b0492370b249b11e8f07a51afac45037afee9d1")},
+}
+
+// Keccak256 are vectors of Keccak-256 with the original padding, as used by Ethereum: the
+// messages of the SHA-256 examples, and the bytes 0, 1, ... n-1 for lengths around the rate of
+// 136 bytes.
+var Keccak256 = []Hash{
+	{"empty", nil, h("c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")},
+	{"abc", []byte("abc"), h("4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45")},
+	{"448 bits", []byte(msg448), h("45d3b367a6904e6e8d502ee04999a7c27647f91fa845d456525fd352ae3d7371")},
+	{"896 bits", []byte(msg896), h("f519747ed599024f3882238e5ab43960132572b7345fbeb9a90769dafd21ad67")},
+	{"rate-1", sequence(135), h("cbdfd9dee5faad3818d6b06f95a219fd290b0e1706f6a82e5a595b9ce9faca62")},
+	{"rate", sequence(136), h("7ce759f1ab7f9ce437719970c26b0a66ff11fe3e38e17df89cf5d29c7d7f807e")},
+	{"rate+1", sequence(137), h("ac73d4fae68b8453f764007c1a20ce95994187861f0c3227a3a8e99a73a3b1db")},
+	{"200 bytes", sequence(200), h("bfb0aa97863e797943cf7c33bb7e880bb4543f3d2703c0923c6901c2af57b890")},
+}
+
+// ExpandMsgXMDSHA256 are the vectors of expand_message_xmd with SHA-256 of RFC 9380, appendix
+// K.1.
+var ExpandMsgXMDSHA256 = []ExpandMsg{
+	{"empty/32", []byte(dstXMD), nil, h("68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235")},
+	{"abc/32", []byte(dstXMD), []byte("abc"), h("d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615")},
+	{"abcdef0123456789/32", []byte(dstXMD), []byte("abcdef0123456789"), h("eff31487c770a893cfb36f912fbfcbff40d5661771ca4b2cb4eafe524333f5c1")},
+	{"q128/32", []byte(dstXMD), []byte("q128_" + strings.Repeat("q", 128)), h("b23a1d2b4d97b2ef7785562a7e8bac7eed54ed6e97e29aa51bfe3f12ddad1ff9")},
+	{"a512/32", []byte(dstXMD), []byte("a512_" + strings.Repeat("a", 512)), h("4623227bcc01293b8c130bf771da8c298dede7383243dc0993d2d94823958c4c")},
+	{"empty/128", []byte(dstXMD), nil, h("af84c27ccfd45d41914fdff5df25293e221afc53d8ad2ac06d5e3e29485dadbee0d121587713a3e0dd4d5e69e93eb7cd4f5df4cd103e188cf60cb02edc3edf18eda8576c412b18ffb658e3dd6ec849469b979d444cf7b26911a08e63cf31f9dcc541708d3491184472c2c29bb749d4286b004ceb5ee6b9a7fa5b646c993f0ced")},
+	{"abc/128", []byte(dstXMD), []byte("abc"), h("abba86a6129e366fc877aab32fc4ffc70120d8996c88aee2fe4b32d6c7b6437a647e6c3163d40b76a73cf6a5674ef1d890f95b664ee0afa5359a5c4e07985635bbecbac65d747d3d2da7ec2b8221b17b0ca9dc8a1ac1c07ea6a1e60583e2cb00058e77b7b72a298425cd1b941ad4ec65e8afc50303a22c0f99b0509b4c895f40")},
+}
+
+// Ed25519 are the vectors of Ed25519 of RFC 8032, section 7.1, tests 1 to 3.
+var Ed25519 = []Signature{
+	{
+		Name:      "test 1",
+		SecretKey: h("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"),
+		PublicKey: h("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"),
+		Msg:       nil,
+		Signature: h("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"),
+		Valid:     true,
+	},
+	{
+		Name:      "test 2",
+		SecretKey: h("4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb"),
+		PublicKey: h("3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c"),
+		Msg:       h("72"),
+		Signature: h("92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00"),
+		Valid:     true,
+	},
+	{
+		Name:      "test 3",
+		SecretKey: h("c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7"),
+		PublicKey: h("fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025"),
+		Msg:       h("af82"),
+		Signature: h("6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac18ff9b538d16f290ae67f760984dc6594a7c15e9716ed28dc027beceea1ec40a"),
+		Valid:     true,
+	},
+}
+
+// BIP340 are the vectors of the Schnorr signatures of BIP-340 (test-vectors.csv): the signing
+// vectors 0 to 3, the verification vector 4, and the invalid vectors 5 (public key not on the
+// curve), 6 (R with an odd y) and 7 (negated message).
+var BIP340 = []Signature{
+	{
+		Name:      "0",
+		SecretKey: h("0000000000000000000000000000000000000000000000000000000000000003"),
+		AuxRand:   h("0000000000000000000000000000000000000000000000000000000000000000"),
+		PublicKey: h("f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"),
+		Msg:       h("0000000000000000000000000000000000000000000000000000000000000000"),
+		Signature: h("e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0"),
+		Valid:     true,
+	},
+	{
+		Name:      "1",
+		SecretKey: h("b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfef"),
+		AuxRand:   h("0000000000000000000000000000000000000000000000000000000000000001"),
+		PublicKey: h("dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659"),
+		Msg:       h("243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89"),
+		Signature: h("6896bd60eeae296db48a229ff71dfe071bde413e6d43f917dc8dcf8c78de33418906d11ac976abccb20b091292bff4ea897efcb639ea871cfa95f6de339e4b0a"),
+		Valid:     true,
+	},
+	{
+		Name:      "2",
+		SecretKey: h("c90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b14e5c9"),
+		AuxRand:   h("c87aa53824b4d7ae2eb035a2b5bbbccc080e76cdc6d1692c4b0b62d798e6d906"),
+		PublicKey: h("dd308afec5777e13121fa72b9cc1b7cc0139715309b086c960e18fd969774eb8"),
+		Msg:       h("7e2d58d8b3bcdf1abadec7829054f90dda9805aab56c77333024b9d0a508b75c"),
+		Signature: h("5831aaeed7b44bb74e5eab94ba9d4294c49bcf2a60728d8b4c200f50dd313c1bab745879a5ad954a72c45a91c3a51d3c7adea98d82f8481e0e1e03674a6f3fb7"),
+		Valid:     true,
+	},
+	{
+		Name:      "3",
+		SecretKey: h("0b432b2677937381aef05bb02a66ecd012773062cf3fa2549e44f58ed2401710"),
+		AuxRand:   h("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		PublicKey: h("25d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517"),
+		Msg:       h("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		Signature: h("7eb0509757e246f19449885651611cb965ecc1a187dd51b64fda1edc9637d5ec97582b9cb13db3933705b32ba982af5af25fd78881ebb32771fc5922efc66ea3"),
+		Valid:     true,
+	},
+	{
+		Name:      "4",
+		PublicKey: h("d69c3509bb99e412e68b0fe8544e72837dfa30746d8be2aa65975f29d22dc7b9"),
+		Msg:       h("4df3c3f68fcc83b27e9d42c90431a72499f17875c81a599b566c9889b9696703"),
+		Signature: h("00000000000000000000003b78ce563f89a0ed9414f5aa28ad0d96d6795f9c6376afb1548af603b3eb45c9f8207dee1060cb71c04e80f593060b07d28308d7f4"),
+		Valid:     true,
+	},
+	{
+		Name:      "5",
+		PublicKey: h("eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34"),
+		Msg:       h("243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89"),
+		Signature: h("6cff5c3ba86c69ea4b7376f31a9bcb4f74c1976089b2d9963da2e5543e17776969e89b4c5564d00349106b8497785dd7d1d713a8ae82b32fa79d5f7fc407d39b"),
+		Valid:     false,
+	},
+	{
+		Name:      "6",
+		PublicKey: h("dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659"),
+		Msg:       h("243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89"),
+		Signature: h("fff97bd5755eeea420453a14355235d382f6472f8568a18b2f057a14602975563cc27944640ac607cd107ae10923d9ef7a73c643e166be5ebeafa34b1ac553e2"),
+		Valid:     false,
+	},
+	{
+		Name:      "7",
+		PublicKey: h("dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659"),
+		Msg:       h("243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89"),
+		Signature: h("1fa62e331edbc21c394792d2ab1100a7b432b013df3f6ff4f99fcb33e0e1515f28890b3edb6e7189b630448b515ce4f8622a954cfe545735aaea5134fccdb2bd"),
+		Valid:     false,
+	},
+}
+
+const (
+	msg448 = "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq"
+	msg896 = "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu"
+	dstXMD = "QUUX-V01-CS02-with-expander-SHA256-128"
+)
+
+func h(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// sequence returns the bytes 0, 1, ... n-1.
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
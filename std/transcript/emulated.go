@@ -0,0 +1,86 @@
+package transcript
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// AbsorbElements absorbs the elements of the emulated field f under the label, as the limbs
+// of their canonical representations: the non-reduced representations of an element can't
+// lead to different challenges. It costs a binary decomposition per element. See
+// AbsorbNativeElements for its native counterpart.
+func AbsorbElements[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], t *Transcript, label string, values ...*emulated.Element[T]) {
+	var fp T
+	nbBits, bitsPerLimb := fp.Modulus().BitLen(), int(fp.BitsPerLimb())
+	limbs := make([]frontend.Variable, 0, len(values)*int(fp.NbLimbs()))
+	for _, v := range values {
+		vBits := f.ToBits(f.Reduce(v))
+		for _, b := range vBits[nbBits:] {
+			api.AssertIsEqual(b, 0)
+		}
+		vBits = vBits[:nbBits]
+		assertCanonical(api, vBits, fp.Modulus())
+		for i := 0; i < int(fp.NbLimbs()); i++ {
+			if i*bitsPerLimb >= nbBits {
+				limbs = append(limbs, 0)
+				continue
+			}
+			end := (i + 1) * bitsPerLimb
+			if end > nbBits {
+				end = nbBits
+			}
+			limbs = append(limbs, bits.FromBinary(api, vBits[i*bitsPerLimb:end], bits.WithUnconstrainedInputs()))
+		}
+	}
+	t.Absorb(label, limbs...)
+}
+
+// SqueezeElement returns the challenge of the label as an element of the emulated field f:
+// its low bits, less than the bits of the modulus of f, so that the element is reduced. See
+// SqueezeNativeElement for its native counterpart.
+func SqueezeElement[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], t *Transcript, label string) *emulated.Element[T] {
+	c := t.Squeeze(label)
+	nbBits := api.Compiler().FieldBitLen()
+	cBits := bits.ToBinary(api, c, bits.WithNbDigits(nbBits))
+	// the canonical bits, whose low bits are the ones of the native challenge
+	assertCanonical(api, cBits, api.Compiler().Field())
+	if m := challengeBits[T](); m < nbBits {
+		cBits = cBits[:m]
+	}
+	return f.FromBits(cBits...)
+}
+
+// AbsorbNativeElements absorbs the elements of the emulated field T, as AbsorbElements.
+func AbsorbNativeElements[T emulated.FieldParams](t *NativeTranscript, label string, values ...*big.Int) {
+	var fp T
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), fp.BitsPerLimb()), big.NewInt(1))
+	limbs := make([]*big.Int, 0, len(values)*int(fp.NbLimbs()))
+	for _, v := range values {
+		r := new(big.Int).Mod(v, fp.Modulus())
+		for i := 0; i < int(fp.NbLimbs()); i++ {
+			limbs = append(limbs, new(big.Int).And(new(big.Int).Rsh(r, uint(i)*fp.BitsPerLimb()), mask))
+		}
+	}
+	t.Absorb(label, limbs...)
+}
+
+// SqueezeNativeElement returns the challenge of the label as an element of the emulated
+// field T, as SqueezeElement.
+func SqueezeNativeElement[T emulated.FieldParams](t *NativeTranscript, label string) (*big.Int, error) {
+	c, err := t.Squeeze(label)
+	if err != nil {
+		return nil, err
+	}
+	// the challenge has less bits than the native field
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(challengeBits[T]())), big.NewInt(1))
+	return c.And(c, mask), nil
+}
+
+// challengeBits returns the number of bits of the challenges in the emulated field T.
+func challengeBits[T emulated.FieldParams]() int {
+	var fp T
+	return fp.Modulus().BitLen() - 1
+}
@@ -0,0 +1,74 @@
+package transcript
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type emulatedTranscriptCircuit struct {
+	X, Y     emulated.Element[emulated.Secp256k1Fr]
+	Expected [2]emulated.Element[emulated.Secp256k1Fr]
+}
+
+func (c *emulatedTranscriptCircuit) Define(api frontend.API) error {
+	f, err := emulated.NewField[emulated.Secp256k1Fr](api)
+	if err != nil {
+		return err
+	}
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	t := New(NewHashSponge(&h), "test")
+	AbsorbElements(api, f, t, "x", &c.X)
+	alpha := SqueezeElement(api, f, t, "alpha")
+	AbsorbElements(api, f, t, "y", &c.Y, alpha)
+	beta := SqueezeElement(api, f, t, "beta")
+	f.AssertIsEqual(alpha, &c.Expected[0])
+	f.AssertIsEqual(beta, &c.Expected[1])
+	return nil
+}
+
+func TestEmulatedTranscript(t *testing.T) {
+	assert := test.NewAssert(t)
+	r := emulated.Secp256k1Fr{}.Modulus()
+	x, err := rand.Int(rand.Reader, r)
+	assert.NoError(err)
+	y := big.NewInt(1)
+
+	tr := NewNative(NewNativeHashSponge(hash.MIMC_BN254.New()), "test")
+	AbsorbNativeElements[emulated.Secp256k1Fr](tr, "x", x)
+	alpha, err := SqueezeNativeElement[emulated.Secp256k1Fr](tr, "alpha")
+	assert.NoError(err)
+	AbsorbNativeElements[emulated.Secp256k1Fr](tr, "y", y, alpha)
+	beta, err := SqueezeNativeElement[emulated.Secp256k1Fr](tr, "beta")
+	assert.NoError(err)
+
+	witness := emulatedTranscriptCircuit{
+		X: emulated.ValueOf[emulated.Secp256k1Fr](x),
+		Y: emulated.ValueOf[emulated.Secp256k1Fr](y),
+		Expected: [2]emulated.Element[emulated.Secp256k1Fr]{
+			emulated.ValueOf[emulated.Secp256k1Fr](alpha),
+			emulated.ValueOf[emulated.Secp256k1Fr](beta),
+		},
+	}
+	var circuit emulatedTranscriptCircuit
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+	// the non-reduced representation of y, on 4 limbs of 64 bits
+	yr := new(big.Int).Add(y, r)
+	witness.Y.Limbs = make([]frontend.Variable, 4)
+	for i := range witness.Y.Limbs {
+		witness.Y.Limbs[i] = new(big.Int).And(new(big.Int).Rsh(yr, uint(64*i)), new(big.Int).SetUint64(^uint64(0)))
+	}
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
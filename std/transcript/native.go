@@ -0,0 +1,117 @@
+package transcript
+
+import (
+	"hash"
+	"math/big"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// NativeSponge is the native counterpart of Sponge. The values are field elements, reduced
+// modulo the field.
+type NativeSponge interface {
+	Absorb(values ...*big.Int)
+	Squeeze() (*big.Int, error)
+}
+
+// NativeTranscript is the native counterpart of Transcript.
+type NativeTranscript struct {
+	sponge NativeSponge
+}
+
+// NewNative returns a transcript over the sponge, as New.
+func NewNative(sponge NativeSponge, domain string) *NativeTranscript {
+	sponge.Absorb(encodeLabel(domain))
+	return &NativeTranscript{sponge: sponge}
+}
+
+// Absorb absorbs the values under the label, as Transcript.Absorb.
+func (t *NativeTranscript) Absorb(label string, values ...*big.Int) {
+	t.sponge.Absorb(big.NewInt(opAbsorb), encodeLabel(label), big.NewInt(int64(len(values))))
+	t.sponge.Absorb(values...)
+}
+
+// Squeeze returns the challenge of the label, as Transcript.Squeeze.
+func (t *NativeTranscript) Squeeze(label string) (*big.Int, error) {
+	res, err := t.SqueezeN(label, 1)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// SqueezeN returns n challenges under the label, as Transcript.SqueezeN.
+func (t *NativeTranscript) SqueezeN(label string, n int) ([]*big.Int, error) {
+	t.sponge.Absorb(big.NewInt(opSqueeze), encodeLabel(label))
+	res := make([]*big.Int, n)
+	for i := range res {
+		var err error
+		if res[i], err = t.sponge.Squeeze(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// nativeHashSponge is the native counterpart of hashSponge.
+type nativeHashSponge struct {
+	h      hash.Hash
+	state  []byte
+	buffer []byte
+}
+
+// NewNativeHashSponge returns the native counterpart of NewHashSponge, over a field hash
+// function such as the MiMC of gnark-crypto. The elements are written to h as their
+// big-endian encodings on h.Size() bytes.
+func NewNativeHashSponge(h hash.Hash) NativeSponge {
+	return &nativeHashSponge{h: h, state: make([]byte, h.Size())}
+}
+
+func (s *nativeHashSponge) Absorb(values ...*big.Int) {
+	for _, v := range values {
+		s.buffer = append(s.buffer, v.FillBytes(make([]byte, s.h.Size()))...)
+	}
+}
+
+func (s *nativeHashSponge) Squeeze() (*big.Int, error) {
+	s.h.Reset()
+	if _, err := s.h.Write(s.state); err != nil {
+		return nil, err
+	}
+	if _, err := s.h.Write(s.buffer); err != nil {
+		return nil, err
+	}
+	s.state = s.h.Sum(s.state[:0])
+	s.buffer = s.buffer[:0]
+	return new(big.Int).SetBytes(s.state), nil
+}
+
+// nativeKeccakSponge is the native counterpart of keccakSponge.
+type nativeKeccakSponge struct {
+	modulus *big.Int
+	nbBytes int
+	state   []byte
+	buffer  []byte
+}
+
+// NewNativeKeccakSponge returns the native counterpart of NewKeccakSponge, for the field of
+// the given modulus.
+func NewNativeKeccakSponge(modulus *big.Int) NativeSponge {
+	return &nativeKeccakSponge{modulus: modulus, nbBytes: (modulus.BitLen() + 7) / 8, state: make([]byte, 32)}
+}
+
+func (s *nativeKeccakSponge) Absorb(values ...*big.Int) {
+	for _, v := range values {
+		s.buffer = append(s.buffer, v.FillBytes(make([]byte, s.nbBytes))...)
+	}
+}
+
+func (s *nativeKeccakSponge) Squeeze() (*big.Int, error) {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(s.state)
+	h.Write(s.buffer)
+	s.state = h.Sum(s.state[:0])
+	s.buffer = s.buffer[:0]
+	res := new(big.Int).SetBytes(s.state)
+	return res.Mod(res, s.modulus), nil
+}
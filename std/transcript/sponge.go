@@ -0,0 +1,115 @@
+package transcript
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/evm"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+// hashSponge is a sponge over a field hash function: the state is the hash of the previous
+// state and of the values absorbed since.
+type hashSponge struct {
+	h      hash.Hash
+	state  frontend.Variable
+	buffer []frontend.Variable
+}
+
+// NewHashSponge returns a sponge over the field hash function h, such as MiMC. Squeezing
+// returns H(state ∥ values absorbed since the last squeeze), which becomes the state; the
+// initial state is 0. See NewNativeHashSponge for its native counterpart.
+func NewHashSponge(h hash.Hash) Sponge {
+	return &hashSponge{h: h, state: 0}
+}
+
+func (s *hashSponge) Absorb(values ...frontend.Variable) {
+	s.buffer = append(s.buffer, values...)
+}
+
+func (s *hashSponge) Squeeze() frontend.Variable {
+	s.h.Reset()
+	s.h.Write(s.state)
+	s.h.Write(s.buffer...)
+	s.state = s.h.Sum()
+	s.buffer = s.buffer[:0]
+	return s.state
+}
+
+// keccakSponge is a sponge over Keccak-256, absorbing the big-endian encodings of the
+// elements.
+type keccakSponge struct {
+	api    frontend.API
+	state  [32]frontend.Variable
+	buffer []frontend.Variable // bytes
+}
+
+// NewKeccakSponge returns a sponge over Keccak-256. The elements are absorbed as their
+// canonical big-endian encodings on the byte length of the field. Squeezing returns
+// keccak256(state ∥ bytes absorbed since the last squeeze), which becomes the state, reduced
+// modulo the field; the initial state is 32 zero bytes. See NewNativeKeccakSponge for its
+// native counterpart.
+//
+// Absorbing an element costs a canonical binary decomposition, and squeezing a Keccak-f
+// permutation per 136 bytes.
+func NewKeccakSponge(api frontend.API) Sponge {
+	s := &keccakSponge{api: api}
+	for i := range s.state {
+		s.state[i] = 0
+	}
+	return s
+}
+
+func (s *keccakSponge) Absorb(values ...frontend.Variable) {
+	api := s.api
+	nbBits := api.Compiler().FieldBitLen()
+	nbBytes := (nbBits + 7) / 8
+	for _, v := range values {
+		if c, ok := api.Compiler().ConstantValue(v); ok {
+			for _, b := range c.FillBytes(make([]byte, nbBytes)) {
+				s.buffer = append(s.buffer, int(b))
+			}
+			continue
+		}
+		vBits := bits.ToBinary(api, v, bits.WithNbDigits(nbBits))
+		assertCanonical(api, vBits, api.Compiler().Field())
+		for len(vBits) < 8*nbBytes {
+			vBits = append(vBits, 0)
+		}
+		// big-endian
+		for i := nbBytes - 1; i >= 0; i-- {
+			s.buffer = append(s.buffer, bits.FromBinary(api, vBits[8*i:8*i+8], bits.WithUnconstrainedInputs()))
+		}
+	}
+}
+
+func (s *keccakSponge) Squeeze() frontend.Variable {
+	api := s.api
+	data := append(s.state[:], s.buffer...)
+	s.state = evm.Keccak256(api, data, len(data))
+	s.buffer = s.buffer[:0]
+
+	// the big-endian integer of the hash, reduced by the field arithmetic
+	res := frontend.Variable(0)
+	for i := range s.state {
+		res = api.Add(api.Mul(res, 256), s.state[i])
+	}
+	return res
+}
+
+// assertCanonical asserts that Σ 2ⁱ·bits[i] is less than the modulus, where bits are
+// boolean constrained and as many as the bits of the modulus.
+func assertCanonical(api frontend.API, bits []frontend.Variable, modulus *big.Int) {
+	bound := new(big.Int).Sub(modulus, big.NewInt(1))
+
+	// prefixEqual is 1 as long as the bits are the ones of the bound, from the top
+	prefixEqual := frontend.Variable(1)
+	for i := len(bits) - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			prefixEqual = api.Mul(prefixEqual, bits[i])
+		} else {
+			api.AssertIsEqual(api.Mul(prefixEqual, bits[i]), 0)
+		}
+	}
+}
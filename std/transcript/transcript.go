@@ -0,0 +1,86 @@
+// Package transcript implements Fiat–Shamir transcripts over cryptographic sponges, in
+// circuits and natively.
+//
+// A Transcript absorbs labelled values and squeezes labelled challenges. Each operation first
+// absorbs its kind, its label and, for absorptions, the number of values, so that transcripts
+// with different structures never produce the same challenges, and the transcript starts with
+// a domain separator. NativeTranscript is the native counterpart of Transcript: given the same
+// operations and a matching sponge, they produce the same challenges, so that in-circuit
+// verifiers derive their challenges as the native provers and verifiers do.
+//
+// The sponges are pluggable. NewHashSponge turns a field hash function, such as MiMC, into a
+// sponge by chaining; NewKeccakSponge uses Keccak-256 over the big-endian encodings of the
+// field elements, as the Solidity verifiers do.
+//
+// The elements of emulated fields, such as the scalars and the coordinates of the curves of
+// recursive verifiers, are absorbed and squeezed with AbsorbElements and SqueezeElement, and
+// their native counterparts AbsorbNativeElements and SqueezeNativeElement.
+package transcript
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Sponge absorbs field elements and squeezes field elements derived from all the elements
+// absorbed before.
+type Sponge interface {
+	// Absorb absorbs the values.
+	Absorb(values ...frontend.Variable)
+
+	// Squeeze returns an element derived from the state of the sponge, and updates it.
+	Squeeze() frontend.Variable
+}
+
+// operations absorbed before their arguments
+const (
+	opAbsorb  = 1
+	opSqueeze = 2
+)
+
+// maxLabelLen is the maximal length of the labels, which are encoded as field elements.
+const maxLabelLen = 30
+
+// Transcript is an in-circuit Fiat–Shamir transcript.
+type Transcript struct {
+	sponge Sponge
+}
+
+// New returns a transcript over the sponge, separated from the transcripts of other domains by
+// the domain label.
+func New(sponge Sponge, domain string) *Transcript {
+	sponge.Absorb(encodeLabel(domain))
+	return &Transcript{sponge: sponge}
+}
+
+// Absorb absorbs the values under the label.
+func (t *Transcript) Absorb(label string, values ...frontend.Variable) {
+	t.sponge.Absorb(opAbsorb, encodeLabel(label), len(values))
+	t.sponge.Absorb(values...)
+}
+
+// Squeeze returns the challenge of the label, derived from everything absorbed before.
+func (t *Transcript) Squeeze(label string) frontend.Variable {
+	return t.SqueezeN(label, 1)[0]
+}
+
+// SqueezeN returns n challenges under the label, derived from everything absorbed before.
+func (t *Transcript) SqueezeN(label string, n int) []frontend.Variable {
+	t.sponge.Absorb(opSqueeze, encodeLabel(label))
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = t.sponge.Squeeze()
+	}
+	return res
+}
+
+// encodeLabel returns the field element encoding a label: its bytes as a big-endian integer,
+// plus its length times 2²⁴⁰ to distinguish the labels with leading zeros.
+func encodeLabel(label string) *big.Int {
+	if len(label) > maxLabelLen {
+		panic("transcript: label too long")
+	}
+	res := new(big.Int).SetBytes([]byte(label))
+	return res.Add(res, new(big.Int).Lsh(big.NewInt(int64(len(label))), 240))
+}
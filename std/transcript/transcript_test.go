@@ -0,0 +1,78 @@
+package transcript
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+type transcriptCircuit struct {
+	X, Y     frontend.Variable
+	Expected [3]frontend.Variable
+	keccak   bool
+}
+
+func (c *transcriptCircuit) Define(api frontend.API) error {
+	var sponge Sponge
+	if c.keccak {
+		sponge = NewKeccakSponge(api)
+	} else {
+		h, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		sponge = NewHashSponge(&h)
+	}
+	t := New(sponge, "test")
+	t.Absorb("x", c.X)
+	alpha := t.Squeeze("alpha")
+	t.Absorb("y", c.Y, alpha)
+	beta := t.SqueezeN("beta", 2)
+	for i, v := range []frontend.Variable{alpha, beta[0], beta[1]} {
+		api.AssertIsEqual(v, c.Expected[i])
+	}
+	return nil
+}
+
+// nativeChallenges returns the challenges of transcriptCircuit.
+func nativeChallenges(t *testing.T, sponge NativeSponge, x, y *big.Int) (res [3]frontend.Variable) {
+	tr := NewNative(sponge, "test")
+	tr.Absorb("x", x)
+	alpha, err := tr.Squeeze("alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Absorb("y", y, alpha)
+	beta, err := tr.SqueezeN("beta", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return [3]frontend.Variable{alpha, beta[0], beta[1]}
+}
+
+func TestTranscript(t *testing.T) {
+	assert := test.NewAssert(t)
+	modulus := ecc.BN254.ScalarField()
+	x, y := big.NewInt(42), new(big.Int).Sub(modulus, big.NewInt(1))
+
+	for _, keccak := range []bool{false, true} {
+		var sponge NativeSponge
+		if keccak {
+			sponge = NewNativeKeccakSponge(modulus)
+		} else {
+			sponge = NewNativeHashSponge(hash.MIMC_BN254.New())
+		}
+		witness := transcriptCircuit{X: x, Y: y, Expected: nativeChallenges(t, sponge, x, y)}
+		circuit := transcriptCircuit{keccak: keccak}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+		witness.X = 43
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+}
@@ -454,6 +454,14 @@ func (e *engine) print(sbb *strings.Builder, x interface{}) {
 	}
 }
 
+func (e *engine) NewHintForId(id solver.HintID, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	f := solver.GetRegisteredHint(id)
+	if f == nil {
+		return nil, fmt.Errorf("no hint registered with ID %d", id)
+	}
+	return e.NewHint(f, nbOutputs, inputs...)
+}
+
 func (e *engine) NewHint(f solver.Hint, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
 
 	if nbOutputs <= 0 {